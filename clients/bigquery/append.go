@@ -7,7 +7,8 @@ import (
 )
 
 func (s *Store) Append(tableData *optimization.TableData) error {
-	return shared.Append(s, tableData, s.config, types.AppendOpts{
+	err := shared.Append(s, tableData, s.config, types.AppendOpts{
 		TempTableName: s.ToFullyQualifiedName(tableData, true),
 	})
+	return s.classifyError(err)
 }
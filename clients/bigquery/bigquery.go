@@ -2,10 +2,12 @@ package bigquery
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"strings"
+	"sync"
 
 	"cloud.google.com/go/bigquery"
 	_ "github.com/viant/bigquery"
@@ -33,20 +35,24 @@ type Store struct {
 	batchSize int
 	config    config.Config
 
+	// client is lazily created and reused across flushes.
+	client   *bigquery.Client
+	clientMu sync.Mutex
+
 	db.Store
 }
 
 func (s *Store) PrepareTemporaryTable(tableData *optimization.TableData, tableConfig *types.DwhTableConfig, tempTableName string, _ types.AdditionalSettings, createTempTable bool) error {
 	if createTempTable {
 		tempAlterTableArgs := ddl.AlterTableArgs{
-			Dwh:               s,
-			Tc:                tableConfig,
-			FqTableName:       tempTableName,
-			CreateTable:       true,
-			TemporaryTable:    true,
-			ColumnOp:          constants.Add,
-			UppercaseEscNames: &s.config.SharedDestinationConfig.UppercaseEscapedNames,
-			Mode:              tableData.Mode(),
+			Dwh:              s,
+			Tc:               tableConfig,
+			FqTableName:      tempTableName,
+			CreateTable:      true,
+			TemporaryTable:   true,
+			ColumnOp:         constants.Add,
+			IdentifierCasing: s.config.SharedDestinationConfig.GetIdentifierCasing(),
+			Mode:             tableData.Mode(),
 		}
 
 		if err := tempAlterTableArgs.AlterTable(tableData.ReadOnlyInMemoryCols().GetColumns()...); err != nil {
@@ -59,7 +65,7 @@ func (s *Store) PrepareTemporaryTable(tableData *optimization.TableData, tableCo
 	additionalDateFmts := s.config.SharedTransferConfig.TypingSettings.AdditionalDateFormats
 	for _, value := range tableData.Rows() {
 		data := make(map[string]bigquery.Value)
-		for _, col := range tableData.ReadOnlyInMemoryCols().GetColumnsToUpdate(s.config.SharedDestinationConfig.UppercaseEscapedNames, nil) {
+		for _, col := range tableData.ReadOnlyInMemoryCols().GetColumnsToUpdate(s.config.SharedDestinationConfig.GetIdentifierCasing(), nil) {
 			colKind, _ := tableData.ReadOnlyInMemoryCols().GetColumn(col)
 			colVal, err := castColVal(value[col], colKind, additionalDateFmts)
 			if err != nil {
@@ -75,27 +81,34 @@ func (s *Store) PrepareTemporaryTable(tableData *optimization.TableData, tableCo
 	}
 
 	// Load the data
-	return s.putTable(context.Background(), tableData.TopicConfig.Database, tempTableName, rows)
+	return s.putTable(context.Background(), tableData.TopicConfig.BigQueryDataset(), tempTableName, rows)
 }
 
 func (s *Store) ToFullyQualifiedName(tableData *optimization.TableData, escape bool) string {
-	return tableData.ToFqName(s.Label(), escape, s.config.SharedDestinationConfig.UppercaseEscapedNames,
+	return tableData.ToFqName(s.Label(), escape, s.config.SharedDestinationConfig.GetIdentifierCasing(),
 		optimization.FqNameOpts{BigQueryProjectID: s.config.BigQuery.ProjectID})
 }
 
+// TemporaryTableName returns the name of the temporary table for `tableData`, BigQuery does not support a staging schema so this is created alongside the target table.
+func (s *Store) TemporaryTableName(tableData *optimization.TableData) string {
+	return tableData.TemporaryTableName(s.Label(), optimization.FqNameOpts{BigQueryProjectID: s.config.BigQuery.ProjectID})
+}
+
 func (s *Store) GetTableConfig(tableData *optimization.TableData) (*types.DwhTableConfig, error) {
-	query := fmt.Sprintf("SELECT column_name, data_type, description FROM `%s.INFORMATION_SCHEMA.COLUMN_FIELD_PATHS` WHERE table_name = ?;", tableData.TopicConfig.Database)
+	query := fmt.Sprintf("SELECT column_name, data_type, description FROM `%s.INFORMATION_SCHEMA.COLUMN_FIELD_PATHS` WHERE table_name = ?;", tableData.TopicConfig.BigQueryDataset())
 	return shared.GetTableCfgArgs{
 		Dwh:                s,
 		FqName:             s.ToFullyQualifiedName(tableData, true),
 		ConfigMap:          s.configMap,
 		Query:              query,
-		Args:               []any{tableData.RawName()},
+		Args:               []any{tableData.TruncatedName(s.Label())},
 		ColumnNameLabel:    describeNameCol,
 		ColumnTypeLabel:    describeTypeCol,
 		ColumnDescLabel:    describeCommentCol,
 		EmptyCommentValue:  ptr.ToString(""),
 		DropDeletedColumns: tableData.TopicConfig.DropDeletedColumns,
+
+		CaseInsensitiveColumns: s.config.SharedDestinationConfig.CaseInsensitiveColumns,
 	}.GetTableConfig()
 }
 
@@ -111,13 +124,22 @@ func (s *Store) Label() constants.DestinationKind {
 	return constants.BigQuery
 }
 
+// GetClient returns a shared BigQuery client, the client is safe for concurrent use and should not be closed by the caller.
 func (s *Store) GetClient(ctx context.Context) *bigquery.Client {
+	s.clientMu.Lock()
+	defer s.clientMu.Unlock()
+
+	if s.client != nil {
+		return s.client
+	}
+
 	client, err := bigquery.NewClient(ctx, s.config.BigQuery.ProjectID)
 	if err != nil {
 		logger.Panic("Failed to get bigquery client", slog.Any("err", err))
 	}
 
-	return client
+	s.client = client
+	return s.client
 }
 
 func tableRelName(fqName string) (string, error) {
@@ -136,17 +158,73 @@ func (s *Store) putTable(ctx context.Context, dataset, tableName string, rows []
 	}
 
 	client := s.GetClient(ctx)
-	defer client.Close()
-
-	batch := NewBatch(rows, s.batchSize)
 	inserter := client.Dataset(dataset).Table(relTableName).Inserter()
+	inserter.SkipInvalidRows = s.config.BigQuery.SkipInvalidRows
+	skippedRows, err := insertRows(ctx, inserter, rows, s.batchSize, s.config.BigQuery.SkipInvalidRows)
+	if err != nil {
+		return err
+	}
+
+	if len(skippedRows) > 0 {
+		slog.Warn("Skipped rows that BigQuery rejected", slog.String("table", tableName), slog.Int("skippedRows", len(skippedRows)), slog.Int("totalRows", len(rows)))
+	}
+
+	return nil
+}
+
+// rowInserter is implemented by [bigquery.Inserter].
+type rowInserter interface {
+	Put(ctx context.Context, src any) error
+}
+
+type rejectedRow struct {
+	row *Row
+	err error
+}
+
+// insertRows will insert `rows` in chunks of `batchSize`.
+// If `skipInvalidRows` is true, rows that BigQuery rejected will be logged and skipped, the rest of the rows will still be inserted.
+func insertRows(ctx context.Context, inserter rowInserter, rows []*Row, batchSize int, skipInvalidRows bool) ([]rejectedRow, error) {
+	var skippedRows []rejectedRow
+	batch := NewBatch(rows, batchSize)
 	for batch.HasNext() {
-		if err = inserter.Put(ctx, batch.NextChunk()); err != nil {
-			return fmt.Errorf("failed to insert rows: %w", err)
+		chunk := batch.NextChunk()
+		err := inserter.Put(ctx, chunk)
+		if err == nil {
+			continue
+		}
+
+		var putMultiErr bigquery.PutMultiError
+		if !errors.As(err, &putMultiErr) {
+			return nil, fmt.Errorf("failed to insert rows: %w", err)
+		}
+
+		rejectedRows := rejectedRowsFromError(chunk, putMultiErr)
+		for _, rejected := range rejectedRows {
+			slog.Warn("BigQuery rejected row", slog.Any("err", rejected.err), slog.Any("row", rejected.row.data))
+		}
+
+		if !skipInvalidRows {
+			return nil, fmt.Errorf("failed to insert rows, %d row(s) were rejected: %w", len(rejectedRows), err)
 		}
+
+		skippedRows = append(skippedRows, rejectedRows...)
 	}
 
-	return nil
+	return skippedRows, nil
+}
+
+func rejectedRowsFromError(chunk []*Row, putMultiErr bigquery.PutMultiError) []rejectedRow {
+	var rejectedRows []rejectedRow
+	for _, rowErr := range putMultiErr {
+		if rowErr.RowIndex < 0 || rowErr.RowIndex >= len(chunk) {
+			continue
+		}
+
+		rejectedRows = append(rejectedRows, rejectedRow{row: chunk[rowErr.RowIndex], err: rowErr.Errors})
+	}
+
+	return rejectedRows
 }
 
 func (s *Store) Dedupe(fqTableName string) error {
@@ -160,7 +238,7 @@ func LoadBigQuery(cfg config.Config, _store *db.Store) *Store {
 		return &Store{
 			Store: *_store,
 
-			configMap: &types.DwhToTablesConfigMap{},
+			configMap: types.NewDwhToTablesConfigMap(cfg.SchemaCachePath),
 			config:    cfg,
 		}
 	}
@@ -175,8 +253,8 @@ func LoadBigQuery(cfg config.Config, _store *db.Store) *Store {
 	}
 
 	return &Store{
-		Store:     db.Open("bigquery", cfg.BigQuery.DSN()),
-		configMap: &types.DwhToTablesConfigMap{},
+		Store:     db.Open("bigquery", cfg.BigQuery.DSN(), cfg.BigQuery.ConnectionPool),
+		configMap: types.NewDwhToTablesConfigMap(cfg.SchemaCachePath),
 		batchSize: cfg.BigQuery.BatchSize,
 		config:    cfg,
 	}
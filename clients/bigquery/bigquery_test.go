@@ -1,6 +1,12 @@
 package bigquery
 
-import "github.com/stretchr/testify/assert"
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/assert"
+)
 
 func (b *BigQueryTestSuite) TestTableRelName() {
 	{
@@ -22,3 +28,72 @@ func (b *BigQueryTestSuite) TestTableRelName() {
 		assert.ErrorContains(b.T(), err, "invalid fully qualified name: project")
 	}
 }
+
+type fakeInserter struct {
+	// rejectedValues are rows that will be rejected, keyed by the `id` column.
+	rejectedValues map[any]bool
+	err            error
+	inserted       []*Row
+}
+
+func (f *fakeInserter) Put(_ context.Context, src any) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	var putMultiErr bigquery.PutMultiError
+	for idx, row := range src.([]*Row) {
+		if f.rejectedValues[row.data["id"]] {
+			putMultiErr = append(putMultiErr, bigquery.RowInsertionError{
+				RowIndex: idx,
+				Errors:   bigquery.MultiError{fmt.Errorf("invalid value for id")},
+			})
+		} else {
+			f.inserted = append(f.inserted, row)
+		}
+	}
+
+	if len(putMultiErr) > 0 {
+		return putMultiErr
+	}
+
+	return nil
+}
+
+func (b *BigQueryTestSuite) TestInsertRows() {
+	var rows []*Row
+	for i := range 5 {
+		rows = append(rows, NewRow(map[string]bigquery.Value{"id": i}))
+	}
+
+	{
+		// All the rows are valid
+		inserter := &fakeInserter{}
+		skippedRows, err := insertRows(context.Background(), inserter, rows, 2, false)
+		assert.NoError(b.T(), err)
+		assert.Empty(b.T(), skippedRows)
+		assert.Equal(b.T(), rows, inserter.inserted)
+	}
+	{
+		// One bad row and we are not skipping invalid rows
+		inserter := &fakeInserter{rejectedValues: map[any]bool{3: true}}
+		_, err := insertRows(context.Background(), inserter, rows, 2, false)
+		assert.ErrorContains(b.T(), err, "failed to insert rows, 1 row(s) were rejected")
+	}
+	{
+		// One bad row and we are skipping invalid rows, the bad row should be reported and the rest should be inserted.
+		inserter := &fakeInserter{rejectedValues: map[any]bool{3: true}}
+		skippedRows, err := insertRows(context.Background(), inserter, rows, 2, true)
+		assert.NoError(b.T(), err)
+		assert.Len(b.T(), skippedRows, 1)
+		assert.Equal(b.T(), rows[3], skippedRows[0].row)
+		assert.ErrorContains(b.T(), skippedRows[0].err, "invalid value for id")
+		assert.Equal(b.T(), []*Row{rows[0], rows[1], rows[2], rows[4]}, inserter.inserted)
+	}
+	{
+		// Other errors should not be skipped
+		inserter := &fakeInserter{err: fmt.Errorf("connection reset")}
+		_, err := insertRows(context.Background(), inserter, rows, 2, true)
+		assert.ErrorContains(b.T(), err, "failed to insert rows: connection reset")
+	}
+}
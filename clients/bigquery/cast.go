@@ -2,6 +2,7 @@ package bigquery
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/artie-labs/transfer/lib/typing/decimal"
@@ -53,6 +54,13 @@ func castColVal(colVal any, colKind columns.Column, additionalDateFmts []string)
 			case ext.TimeKindType:
 				colVal = extTime.String(typing.StreamingTimeFormat)
 			}
+		case typing.Geography.Kind:
+			return toGeography(colVal)
+		case typing.Bytes.Kind:
+			// The BigQuery client will encode []byte values for `BYTES` columns.
+			if castedColVal, isOk := colVal.([]byte); isOk {
+				return castedColVal, nil
+			}
 		case typing.Struct.Kind:
 			if colKind.KindDetails == typing.Struct {
 				if strings.Contains(fmt.Sprint(colVal), constants.ToastUnavailableValuePlaceholder) {
@@ -60,6 +68,20 @@ func castColVal(colVal any, colKind columns.Column, additionalDateFmts []string)
 				}
 			}
 		case typing.Array.Kind:
+			if elementKind := colKind.KindDetails.OptionalArrayElementKind; elementKind != nil {
+				switch elementKind.Kind {
+				case typing.Integer.Kind, typing.Float.Kind, typing.Boolean.Kind:
+					// These are natively typed arrays, so we can pass the elements through as is.
+					// Strings are not included, existing `ARRAY<STRING>` columns may contain objects and numbers which need to be stringified below.
+					vals := toArray(colVal)
+					if len(vals) == 0 {
+						return nil, nil
+					}
+
+					return vals, nil
+				}
+			}
+
 			var err error
 			arrayString, err := array.InterfaceToArrayString(colVal, true)
 			if err != nil {
@@ -78,3 +100,18 @@ func castColVal(colVal any, colKind columns.Column, additionalDateFmts []string)
 
 	return nil, nil
 }
+
+func toArray(colVal any) []any {
+	list := reflect.ValueOf(colVal)
+	if list.Kind() != reflect.Slice {
+		// Same as `InterfaceToArrayString`, we'll wrap a single value as an array.
+		return []any{colVal}
+	}
+
+	vals := make([]any, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		vals[i] = list.Index(i).Interface()
+	}
+
+	return vals
+}
@@ -38,6 +38,10 @@ func (b *BigQueryTestSuite) TestCastColVal() {
 
 	timeKind := typing.ETime
 	timeKind.ExtendedTimeDetails = &ext.Time
+
+	// This is how an existing `ARRAY<STRING>` column is described.
+	stringArrayKind, err := typing.DwhTypeToKind(constants.BigQuery, "array<string>", "")
+	assert.NoError(b.T(), err)
 	birthdayTimeExt := ext.NewExtendedTime(birthday, timeKind.ExtendedTimeDetails.Type, "")
 
 	invalidDate := time.Date(0, time.September, 6, 3, 19, 24, 942000000, time.UTC)
@@ -74,6 +78,42 @@ func (b *BigQueryTestSuite) TestCastColVal() {
 			colKind:       columns.Column{KindDetails: typing.Array},
 			expectedValue: []string{"1", "2", "3", "4", "5"},
 		},
+		{
+			name:          "array of int64",
+			colVal:        []any{float64(1), float64(2), float64(3)},
+			colKind:       columns.Column{KindDetails: typing.NewArrayKindDetails(typing.Integer)},
+			expectedValue: []any{float64(1), float64(2), float64(3)},
+		},
+		{
+			name:          "array of booleans",
+			colVal:        []bool{true, false},
+			colKind:       columns.Column{KindDetails: typing.NewArrayKindDetails(typing.Boolean)},
+			expectedValue: []any{true, false},
+		},
+		{
+			name:          "empty array of int64",
+			colVal:        []any{},
+			colKind:       columns.Column{KindDetails: typing.NewArrayKindDetails(typing.Integer)},
+			expectedValue: nil,
+		},
+		{
+			name:          "array of strings",
+			colVal:        []string{"foo", "bar"},
+			colKind:       columns.Column{KindDetails: typing.NewArrayKindDetails(typing.String)},
+			expectedValue: []string{"foo", "bar"},
+		},
+		{
+			name:          "array of strings (existing column with objects and numbers)",
+			colVal:        []any{map[string]any{"foo": "bar"}, 1},
+			colKind:       columns.Column{KindDetails: stringArrayKind},
+			expectedValue: []string{`{"foo":"bar"}`, "1"},
+		},
+		{
+			name:          "array of structs",
+			colVal:        []any{map[string]any{"foo": "bar"}, map[string]any{"hello": 1}},
+			colKind:       columns.Column{KindDetails: typing.NewArrayKindDetails(typing.Struct)},
+			expectedValue: []string{`{"foo":"bar"}`, `{"hello":1}`},
+		},
 		{
 			name:          "empty array",
 			colVal:        []int{},
@@ -108,7 +148,7 @@ func (b *BigQueryTestSuite) TestCastColVal() {
 			name:          "time",
 			colVal:        birthdayTimeExt,
 			colKind:       columns.Column{KindDetails: timeKind},
-			expectedValue: "03:19:24",
+			expectedValue: "03:19:24.942",
 		},
 		{
 			name:    "date (column is a date, but value is invalid)",
@@ -120,6 +160,42 @@ func (b *BigQueryTestSuite) TestCastColVal() {
 			colVal:  invalidDateTsExt,
 			colKind: columns.Column{KindDetails: tsKind},
 		},
+		{
+			name:          "geography - point wkb",
+			colVal:        map[string]any{"srid": 4326, "wkb": "AQEAACDmEAAAAAAAAADAXkAAAAAAAIBDwA=="},
+			colKind:       columns.Column{KindDetails: typing.Geography},
+			expectedValue: "POINT (123 -39)",
+		},
+		{
+			name:          "geography - polygon wkb",
+			colVal:        map[string]any{"wkb": "AQMAAAABAAAABQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQQAAAAAAAAAAAAAAAAAAAEEAAAAAAAAAQQAAAAAAAAAAAAAAAAAAAEEAAAAAAAAAAAAAAAAAAAAAA"},
+			colKind:       columns.Column{KindDetails: typing.Geography},
+			expectedValue: "POLYGON ((0 0, 4 0, 4 4, 0 4, 0 0))",
+		},
+		{
+			name:          "geography - GeoJSON feature",
+			colVal:        `{"type":"Feature","geometry":{"type":"Point","coordinates":[1,5]},"properties":null}`,
+			colKind:       columns.Column{KindDetails: typing.Geography},
+			expectedValue: "POINT (1 5)",
+		},
+		{
+			name:          "geography - GeoJSON geometry",
+			colVal:        `{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]]]}`,
+			colKind:       columns.Column{KindDetails: typing.Geography},
+			expectedValue: "POLYGON ((0 0, 4 0, 4 4, 0 4, 0 0))",
+		},
+		{
+			name:          "geography - WKT",
+			colVal:        "POINT (1 5)",
+			colKind:       columns.Column{KindDetails: typing.Geography},
+			expectedValue: "POINT (1 5)",
+		},
+		{
+			name:          "bytes",
+			colVal:        []byte("hello"),
+			colKind:       columns.Column{KindDetails: typing.Bytes},
+			expectedValue: []byte("hello"),
+		},
 	}
 
 	for _, testCase := range testCases {
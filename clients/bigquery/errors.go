@@ -1,6 +1,14 @@
 package bigquery
 
-import "strings"
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"google.golang.org/api/googleapi"
+
+	"github.com/artie-labs/transfer/lib/destination"
+)
 
 func isTableQuotaError(err error) bool {
 	return strings.Contains(err.Error(), "Exceeded rate limits: too many table update operations for this table")
@@ -13,3 +21,59 @@ func (s *Store) IsRetryableError(err error) bool {
 
 	return s.Store.IsRetryableError(err)
 }
+
+// schemaErrorMessages are returned by BigQuery when the data does not fit the destination table.
+var schemaErrorMessages = []string{
+	"No such field",
+	"Unrecognized name",
+	"Could not parse",
+	"Cannot convert",
+	"Invalid NUMERIC value",
+	"Invalid BIGNUMERIC value",
+}
+
+func isSchemaError(err error) bool {
+	for _, msg := range schemaErrorMessages {
+		if strings.Contains(err.Error(), msg) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isRateLimitError(apiErr *googleapi.Error) bool {
+	for _, item := range apiErr.Errors {
+		if item.Reason == "rateLimitExceeded" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// classifyError maps errors returned by BigQuery to one of the destination error types.
+func (s *Store) classifyError(err error) error {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case http.StatusForbidden:
+			// BigQuery also returns a 403 when we are being rate limited.
+			if isRateLimitError(apiErr) {
+				return destination.NewRetryableError(err)
+			}
+
+			return destination.NewAuthError(err)
+		case http.StatusUnauthorized:
+			return destination.NewAuthError(err)
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return destination.NewRetryableError(err)
+		}
+	}
+
+	if err != nil && isSchemaError(err) {
+		return destination.NewSchemaError(err)
+	}
+
+	return destination.ClassifyError(err, s.IsRetryableError)
+}
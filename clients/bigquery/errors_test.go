@@ -0,0 +1,63 @@
+package bigquery
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/googleapi"
+
+	"github.com/artie-labs/transfer/lib/destination"
+)
+
+func (b *BigQueryTestSuite) TestClassifyError() {
+	{
+		// nil
+		assert.NoError(b.T(), b.store.classifyError(nil))
+	}
+	{
+		// Service unavailable
+		err := b.store.classifyError(fmt.Errorf("failed to run merge: %w", &googleapi.Error{Code: http.StatusServiceUnavailable, Message: "Service is unavailable"}))
+		assert.True(b.T(), destination.IsRetryable(err))
+		assert.False(b.T(), destination.IsPermanent(err))
+		assert.ErrorContains(b.T(), err, "Service is unavailable")
+
+		var apiErr *googleapi.Error
+		assert.ErrorAs(b.T(), err, &apiErr)
+	}
+	{
+		// Permission denied
+		err := b.store.classifyError(&googleapi.Error{Code: http.StatusForbidden, Message: "Access Denied: Table artie:foo.bar: Permission bigquery.tables.updateData denied"})
+		assert.False(b.T(), destination.IsRetryable(err))
+		assert.True(b.T(), destination.IsPermanent(err))
+
+		var authErr *destination.AuthError
+		assert.ErrorAs(b.T(), err, &authErr)
+	}
+	{
+		// Rate limited
+		err := b.store.classifyError(&googleapi.Error{Code: http.StatusForbidden, Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}})
+		assert.True(b.T(), destination.IsRetryable(err))
+	}
+	{
+		// Table quota error
+		err := b.store.classifyError(fmt.Errorf("Exceeded rate limits: too many table update operations for this table"))
+		assert.True(b.T(), destination.IsRetryable(err))
+	}
+	{
+		// Missing column
+		err := b.store.classifyError(&googleapi.Error{Code: http.StatusBadRequest, Message: "Query error: Unrecognized name: foo at [1:8]"})
+		assert.Equal(b.T(), "schema", destination.ErrorType(err))
+	}
+	{
+		// Type mismatch
+		err := b.store.classifyError(fmt.Errorf("failed to run merge: Could not parse 'abc' as INT64 for field id"))
+		assert.Equal(b.T(), "schema", destination.ErrorType(err))
+	}
+	{
+		// Anything else
+		err := b.store.classifyError(fmt.Errorf("some random error"))
+		assert.True(b.T(), destination.IsPermanent(err))
+		assert.Equal(b.T(), "permanent", destination.ErrorType(err))
+	}
+}
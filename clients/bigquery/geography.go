@@ -0,0 +1,93 @@
+package bigquery
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/ewkb"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/twpayne/go-geom/encoding/wkt"
+)
+
+// toGeography will convert `colVal` into WKT, which is what BigQuery expects when streaming into a `GEOGRAPHY` column.
+// https://cloud.google.com/bigquery/docs/geospatial-data#loading_wkt_or_wkb_data
+// `colVal` can be any of the following:
+// 1. A GeoJSON string (either a Feature or a Geometry), this is what we'll have after parsing Debezium's geometry types.
+// 2. A Debezium geometry object, e.g. {"wkb": "<base64 encoded (E)WKB>", "srid": 4326}
+// 3. A WKT string, this will be passed through as is.
+// The SRID is dropped since BigQuery's `GEOGRAPHY` is always WGS84.
+func toGeography(colVal any) (string, error) {
+	switch castedVal := colVal.(type) {
+	case string:
+		trimmed := strings.TrimSpace(castedVal)
+		if !strings.HasPrefix(trimmed, "{") {
+			return trimmed, nil
+		}
+
+		geometry, err := parseGeoJSON([]byte(trimmed))
+		if err != nil {
+			return "", err
+		}
+
+		return wkt.Marshal(geometry)
+	case map[string]any:
+		wkbVal, isOk := castedVal["wkb"]
+		if !isOk {
+			return "", fmt.Errorf("wkb does not exist")
+		}
+
+		wkbBytes, err := base64.StdEncoding.DecodeString(fmt.Sprint(wkbVal))
+		if err != nil {
+			return "", fmt.Errorf("failed to decode base64: %w", err)
+		}
+
+		return wkbToWKT(wkbBytes)
+	case []byte:
+		return wkbToWKT(castedVal)
+	default:
+		return "", fmt.Errorf("unexpected type %T for geography", colVal)
+	}
+}
+
+func wkbToWKT(wkbBytes []byte) (string, error) {
+	// EWKB is a superset of WKB, so this will also work for WKB without a SRID.
+	geometry, err := ewkb.Unmarshal(wkbBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal WKB: %w", err)
+	}
+
+	return wkt.Marshal(geometry)
+}
+
+func parseGeoJSON(bytes []byte) (geom.T, error) {
+	var object struct {
+		Type string `json:"type"`
+	}
+
+	if err := json.Unmarshal(bytes, &object); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GeoJSON: %w", err)
+	}
+
+	if object.Type == "Feature" {
+		var feature geojson.Feature
+		if err := feature.UnmarshalJSON(bytes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal GeoJSON feature: %w", err)
+		}
+
+		if feature.Geometry == nil {
+			return nil, fmt.Errorf("GeoJSON feature does not have a geometry")
+		}
+
+		return feature.Geometry, nil
+	}
+
+	var geometry geom.T
+	if err := geojson.Unmarshal(bytes, &geometry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal GeoJSON geometry: %w", err)
+	}
+
+	return geometry, nil
+}
@@ -26,8 +26,13 @@ func (r *Row) Save() (map[string]bigquery.Value, string, error) {
 }
 
 func (s *Store) Merge(tableData *optimization.TableData) error {
+	return s.classifyError(s.merge(tableData))
+}
+
+func (s *Store) merge(tableData *optimization.TableData) error {
 	var additionalEqualityStrings []string
-	if tableData.TopicConfig.BigQueryPartitionSettings != nil {
+	// Ingestion time partitions are not based on the data, so they cannot be used to prune the merge.
+	if tableData.TopicConfig.BigQueryPartitionSettings.HasPartitionField() {
 		additionalDateFmts := s.config.SharedTransferConfig.TypingSettings.AdditionalDateFormats
 		distinctDates, err := tableData.DistinctDates(tableData.TopicConfig.BigQueryPartitionSettings.PartitionField, additionalDateFmts)
 		if err != nil {
@@ -0,0 +1,29 @@
+package databricks
+
+import (
+	"fmt"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+func describeTableQuery(catalog, schema, rawTableName string) (string, []any) {
+	return fmt.Sprintf(`
+SELECT
+    column_name, full_data_type, comment
+FROM
+    %s.information_schema.columns
+WHERE
+    LOWER(table_name) = LOWER(?) AND LOWER(table_schema) = LOWER(?)
+ORDER BY
+    ordinal_position`, catalog), []any{rawTableName, schema}
+}
+
+func sweepQuery(catalog, schema string) (string, []any) {
+	return fmt.Sprintf(`
+SELECT
+    table_schema, table_name
+FROM
+    %s.information_schema.tables
+WHERE
+    LOWER(table_name) LIKE ? AND LOWER(table_schema) = LOWER(?)`, catalog), []any{"%" + constants.ArtiePrefix + "%", schema}
+}
@@ -0,0 +1,133 @@
+package databricks
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination/ddl"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/values"
+)
+
+// nullValue needs to match `nullValue` from the COPY INTO statement.
+const nullValue = `\N`
+
+// castColValStaging - takes `colVal` any and `colKind` typing.Column and converts the value into a string value
+// This is necessary because CSV writers require values to in `string`.
+func castColValStaging(colVal any, colKind columns.Column, additionalDateFmts []string) (string, error) {
+	if colVal == nil {
+		return nullValue, nil
+	}
+
+	return values.ToString(colVal, colKind, additionalDateFmts)
+}
+
+func (s *Store) PrepareTemporaryTable(tableData *optimization.TableData, tableConfig *types.DwhTableConfig, tempTableName string, _ types.AdditionalSettings, createTempTable bool) error {
+	if createTempTable {
+		tempAlterTableArgs := ddl.AlterTableArgs{
+			Dwh:              s,
+			Tc:               tableConfig,
+			FqTableName:      tempTableName,
+			CreateTable:      true,
+			TemporaryTable:   true,
+			ColumnOp:         constants.Add,
+			IdentifierCasing: s.config.SharedDestinationConfig.GetIdentifierCasing(),
+			Mode:             tableData.Mode(),
+		}
+
+		if err := tempAlterTableArgs.AlterTable(tableData.ReadOnlyInMemoryCols().GetColumns()...); err != nil {
+			return fmt.Errorf("failed to create temp table: %w", err)
+		}
+	}
+
+	fp, err := s.writeTemporaryTableFile(tableData, tempTableName)
+	if err != nil {
+		return fmt.Errorf("failed to load temporary table: %w", err)
+	}
+
+	defer func() {
+		// In the case where PUT or COPY fails, we'll at least delete the temporary file.
+		if deleteErr := os.RemoveAll(fp); deleteErr != nil {
+			slog.Warn("Failed to delete temp file", slog.Any("err", deleteErr), slog.String("filePath", fp))
+		}
+	}()
+
+	// Upload the CSV file into the volume
+	volumeFilePath := fmt.Sprintf("%s/%s", s.volumePath, filepath.Base(fp))
+	if err = s.execStaging(fmt.Sprintf("PUT '%s' INTO '%s' OVERWRITE", fp, volumeFilePath), filepath.Dir(fp)); err != nil {
+		return fmt.Errorf("failed to run PUT for temporary table: %w", err)
+	}
+
+	defer func() {
+		if removeErr := s.execStaging(fmt.Sprintf("REMOVE '%s'", volumeFilePath), filepath.Dir(fp)); removeErr != nil {
+			slog.Warn("Failed to delete staged file", slog.Any("err", removeErr), slog.String("filePath", volumeFilePath))
+		}
+	}()
+
+	if _, err = s.Exec(s.copyStatement(tableData.ReadOnlyInMemoryCols(), tempTableName, volumeFilePath)); err != nil {
+		return fmt.Errorf("failed to run copy into temporary table: %w", err)
+	}
+
+	return nil
+}
+
+// copyStatement returns the COPY INTO command to load the staged CSV file at `filePath` into `tempTableName`.
+// CSV columns are read as strings (_c0, _c1, ...) so we'll cast each column into the target type.
+func (s *Store) copyStatement(cols *columns.Columns, tempTableName string, filePath string) string {
+	var selectParts []string
+	for idx, col := range cols.GetColumnsToUpdate(s.config.SharedDestinationConfig.GetIdentifierCasing(), nil) {
+		column, _ := cols.GetColumn(col)
+		selectParts = append(selectParts, fmt.Sprintf("CAST(_c%d AS %s) AS %s", idx,
+			typing.KindToDWHType(column.KindDetails, s.Label(), false),
+			column.Name(s.config.SharedDestinationConfig.GetIdentifierCasing(), &sql.NameArgs{Escape: true, DestKind: s.Label()}),
+		))
+	}
+
+	// escape is set to `"` because Go's CSV writer escapes quotes by doubling them.
+	return fmt.Sprintf(`COPY INTO %s FROM (SELECT %s FROM '%s') FILEFORMAT = CSV FORMAT_OPTIONS ('sep' = '\t', 'nullValue' = '\\N', 'escape' = '"', 'multiLine' = 'true')`,
+		tempTableName, strings.Join(selectParts, ", "), filePath)
+}
+
+func (s *Store) writeTemporaryTableFile(tableData *optimization.TableData, newTableName string) (string, error) {
+	// The temporary table name is fully qualified and escaped, so we'll strip it down for the file name.
+	fileName := strings.NewReplacer(".", "_", "`", "").Replace(newTableName)
+	fp := filepath.Join(os.TempDir(), fmt.Sprintf("%s.csv", fileName))
+	file, err := os.Create(fp)
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	writer.Comma = '\t'
+
+	additionalDateFmts := s.config.SharedTransferConfig.TypingSettings.AdditionalDateFormats
+	for _, value := range tableData.Rows() {
+		var row []string
+		for _, col := range tableData.ReadOnlyInMemoryCols().GetColumnsToUpdate(s.config.SharedDestinationConfig.GetIdentifierCasing(), nil) {
+			column, _ := tableData.ReadOnlyInMemoryCols().GetColumn(col)
+			castedValue, castErr := castColValStaging(value[col], column, additionalDateFmts)
+			if castErr != nil {
+				return "", castErr
+			}
+
+			row = append(row, castedValue)
+		}
+
+		if err = writer.Write(row); err != nil {
+			return "", fmt.Errorf("failed to write to csv: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return fp, writer.Error()
+}
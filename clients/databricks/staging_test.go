@@ -0,0 +1,39 @@
+package databricks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+func TestCopyStatement(t *testing.T) {
+	var cols columns.Columns
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("group", typing.String))
+	cols.AddColumn(columns.NewColumn("created_at", typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateTimeKindType)))
+
+	store := &Store{}
+	assert.Equal(t,
+		"COPY INTO main.public.orders___artie_abcde FROM (SELECT CAST(_c0 AS BIGINT) AS id, CAST(_c1 AS STRING) AS `group`, CAST(_c2 AS TIMESTAMP) AS created_at FROM '/Volumes/main/public/staging/orders.csv') FILEFORMAT = CSV FORMAT_OPTIONS ('sep' = '\\t', 'nullValue' = '\\\\N', 'escape' = '\"', 'multiLine' = 'true')",
+		store.copyStatement(&cols, "main.public.orders___artie_abcde", "/Volumes/main/public/staging/orders.csv"),
+	)
+}
+
+func TestCastColValStaging(t *testing.T) {
+	{
+		// Null
+		value, err := castColValStaging(nil, columns.NewColumn("foo", typing.String), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, `\N`, value)
+	}
+	{
+		// String
+		value, err := castColValStaging("hello", columns.NewColumn("foo", typing.String), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", value)
+	}
+}
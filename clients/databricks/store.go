@@ -0,0 +1,156 @@
+package databricks
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	_ "github.com/databricks/databricks-sql-go"
+	"github.com/databricks/databricks-sql-go/driverctx"
+
+	"github.com/artie-labs/transfer/clients/shared"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/db"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/destination/ddl"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/logger"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/ptr"
+)
+
+type Store struct {
+	catalog    string
+	volumePath string
+	// stagingDB is used for PUT and REMOVE, these staging operations require a context that allow-lists the local paths.
+	stagingDB *sql.DB
+	configMap *types.DwhToTablesConfigMap
+	config    config.Config
+	db.Store
+}
+
+func (s *Store) Label() constants.DestinationKind {
+	return constants.Databricks
+}
+
+func (s *Store) Merge(tableData *optimization.TableData) error {
+	err := shared.Merge(s, tableData, s.config, types.MergeOpts{})
+	return destination.ClassifyError(err, s.IsRetryableError)
+}
+
+func (s *Store) Append(tableData *optimization.TableData) error {
+	err := shared.Append(s, tableData, s.config, types.AppendOpts{
+		TempTableName: s.ToFullyQualifiedName(tableData, true),
+	})
+	return destination.ClassifyError(err, s.IsRetryableError)
+}
+
+func (s *Store) ToFullyQualifiedName(tableData *optimization.TableData, escape bool) string {
+	return tableData.ToFqName(s.Label(), escape, s.config.SharedDestinationConfig.GetIdentifierCasing(), optimization.FqNameOpts{
+		DatabricksCatalog: s.catalog,
+	})
+}
+
+// stagingSchema returns the schema that temporary tables are created in, this is empty if they are created in the topic's schema.
+func (s *Store) stagingSchema() string {
+	if s.config.Databricks == nil {
+		return ""
+	}
+
+	return s.config.Databricks.StagingSchema
+}
+
+// TemporaryTableName returns the name of the temporary table for `tableData`, this will be in the staging schema if one is configured.
+func (s *Store) TemporaryTableName(tableData *optimization.TableData) string {
+	return tableData.TemporaryTableName(s.Label(), optimization.FqNameOpts{
+		DatabricksCatalog: s.catalog,
+		StagingSchema:     s.stagingSchema(),
+	})
+}
+
+func (s *Store) Dedupe(fqTableName string) error {
+	return nil // dedupe is not necessary for Databricks
+}
+
+func (s *Store) GetTableConfig(tableData *optimization.TableData) (*types.DwhTableConfig, error) {
+	const (
+		describeNameCol        = "column_name"
+		describeTypeCol        = "full_data_type"
+		describeDescriptionCol = "comment"
+	)
+
+	query, args := describeTableQuery(s.catalog, tableData.TopicConfig.Schema, tableData.TruncatedName(s.Label()))
+	return shared.GetTableCfgArgs{
+		Dwh:                s,
+		FqName:             s.ToFullyQualifiedName(tableData, true),
+		ConfigMap:          s.configMap,
+		Query:              query,
+		Args:               args,
+		ColumnNameLabel:    describeNameCol,
+		ColumnTypeLabel:    describeTypeCol,
+		ColumnDescLabel:    describeDescriptionCol,
+		EmptyCommentValue:  ptr.ToString("<nil>"),
+		DropDeletedColumns: tableData.TopicConfig.DropDeletedColumns,
+
+		CaseInsensitiveColumns: s.config.SharedDestinationConfig.CaseInsensitiveColumns,
+	}.GetTableConfig()
+}
+
+// Sweep will drop any dangling artie temporary tables.
+// We are not using [shared.Sweep] because tables are written into the configured catalog, not the topic's database.
+func (s *Store) Sweep() error {
+	tcs, err := s.config.TopicConfigs()
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Looking to see if there are any dangling artie temporary tables to delete...")
+	for _, dbAndSchemaPair := range kafkalib.GetUniqueStagingDatabaseAndSchema(tcs, s.stagingSchema()) {
+		query, args := sweepQuery(s.catalog, dbAndSchemaPair.Schema)
+		rows, err := s.Query(query, args...)
+		if err != nil {
+			return err
+		}
+
+		for rows != nil && rows.Next() {
+			var tableSchema, tableName string
+			if err = rows.Scan(&tableSchema, &tableName); err != nil {
+				return err
+			}
+
+			if ddl.ShouldDeleteFromName(tableName) {
+				if err = ddl.DropTemporaryTable(s, fmt.Sprintf("%s.%s.%s", s.catalog, tableSchema, tableName), true); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// execStaging runs a staging operation (PUT or REMOVE), `localDir` is the local directory that the operation is allowed to read from.
+func (s *Store) execStaging(query string, localDir string) error {
+	ctx := driverctx.NewContextWithStagingInfo(context.Background(), []string{localDir})
+	_, err := s.stagingDB.ExecContext(ctx, query)
+	return err
+}
+
+func LoadStore(cfg config.Config) *Store {
+	stagingDB, err := sql.Open("databricks", cfg.Databricks.DSN())
+	if err != nil {
+		logger.Panic("Failed to open staging connection to databricks", slog.Any("err", err))
+	}
+
+	return &Store{
+		catalog:    cfg.Databricks.Catalog,
+		volumePath: cfg.Databricks.VolumePath(),
+		stagingDB:  stagingDB,
+		Store:      db.Open("databricks", cfg.Databricks.DSN(), cfg.Databricks.ConnectionPool),
+		configMap:  types.NewDwhToTablesConfigMap(cfg.SchemaCachePath),
+		config:     cfg,
+	}
+}
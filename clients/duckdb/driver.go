@@ -0,0 +1,10 @@
+//go:build cgo
+
+package duckdb
+
+import (
+	_ "github.com/marcboeker/go-duckdb"
+)
+
+// driverAvailable is whether the DuckDB driver was compiled in, the driver is only available when Transfer is built with cgo.
+const driverAvailable = true
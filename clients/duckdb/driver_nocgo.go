@@ -0,0 +1,6 @@
+//go:build !cgo
+
+package duckdb
+
+// driverAvailable is whether the DuckDB driver was compiled in, the driver is only available when Transfer is built with cgo.
+const driverAvailable = false
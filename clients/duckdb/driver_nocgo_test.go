@@ -0,0 +1,17 @@
+//go:build !cgo
+
+package duckdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+func TestLoadStore_WithoutCgo(t *testing.T) {
+	_, err := LoadStore(config.Config{Output: constants.DuckDB, DuckDB: &config.DuckDB{Path: "transfer.duckdb"}})
+	assert.ErrorContains(t, err, "the duckdb destination requires transfer to be built with cgo")
+}
@@ -0,0 +1,27 @@
+package duckdb
+
+import (
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+func describeTableQuery(schema, rawTableName string) (string, []any) {
+	return `
+SELECT
+    column_name, data_type
+FROM
+    information_schema.columns
+WHERE
+    LOWER(table_name) = LOWER(?) AND LOWER(table_schema) = LOWER(?)
+ORDER BY
+    ordinal_position`, []any{rawTableName, schema}
+}
+
+func sweepQuery(schema string) (string, []any) {
+	return `
+SELECT
+    table_schema, table_name
+FROM
+    information_schema.tables
+WHERE
+    LOWER(table_name) LIKE ? AND LOWER(table_schema) = LOWER(?)`, []any{"%" + constants.ArtiePrefix + "%", schema}
+}
@@ -0,0 +1,125 @@
+package duckdb
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination/ddl"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/values"
+)
+
+// nullValue needs to match the `NULL` option from the COPY statement.
+const nullValue = `\N`
+
+// castColValStaging - takes `colVal` any and `colKind` typing.Column and converts the value into a string value
+// This is necessary because CSV writers require values to in `string`.
+func castColValStaging(colVal any, colKind columns.Column, additionalDateFmts []string) (string, error) {
+	if colVal == nil {
+		return nullValue, nil
+	}
+
+	if colKind.KindDetails.Kind == typing.Bytes.Kind {
+		if castedColVal, isOk := colVal.([]byte); isOk {
+			// DuckDB will cast a string into a BLOB by reading each `\xHH` escape sequence as a byte.
+			var sb strings.Builder
+			for _, b := range castedColVal {
+				fmt.Fprintf(&sb, `\x%02X`, b)
+			}
+
+			return sb.String(), nil
+		}
+	}
+
+	return values.ToString(colVal, colKind, additionalDateFmts)
+}
+
+func (s *Store) PrepareTemporaryTable(tableData *optimization.TableData, tableConfig *types.DwhTableConfig, tempTableName string, _ types.AdditionalSettings, createTempTable bool) error {
+	if createTempTable {
+		tempAlterTableArgs := ddl.AlterTableArgs{
+			Dwh:              s,
+			Tc:               tableConfig,
+			FqTableName:      tempTableName,
+			CreateTable:      true,
+			TemporaryTable:   true,
+			ColumnOp:         constants.Add,
+			IdentifierCasing: s.config.SharedDestinationConfig.GetIdentifierCasing(),
+			Mode:             tableData.Mode(),
+		}
+
+		if err := tempAlterTableArgs.AlterTable(tableData.ReadOnlyInMemoryCols().GetColumns()...); err != nil {
+			return fmt.Errorf("failed to create temp table: %w", err)
+		}
+	}
+
+	fp, err := s.writeTemporaryTableFile(tableData, tempTableName)
+	if err != nil {
+		return fmt.Errorf("failed to load temporary table: %w", err)
+	}
+
+	defer func() {
+		if deleteErr := os.RemoveAll(fp); deleteErr != nil {
+			slog.Warn("Failed to delete temp file", slog.Any("err", deleteErr), slog.String("filePath", fp))
+		}
+	}()
+
+	if _, err = s.Exec(s.copyStatement(tableData.ReadOnlyInMemoryCols(), tempTableName, fp)); err != nil {
+		return fmt.Errorf("failed to run copy into temporary table: %w", err)
+	}
+
+	return nil
+}
+
+// copyStatement returns the COPY command to load the CSV file at `filePath` into `tempTableName`.
+func (s *Store) copyStatement(cols *columns.Columns, tempTableName string, filePath string) string {
+	casing := s.config.SharedDestinationConfig.GetIdentifierCasing()
+	colNames := cols.GetColumnsToUpdate(casing, &sql.NameArgs{Escape: true, DestKind: s.Label()})
+
+	// ESCAPE is set to `"` because Go's CSV writer escapes quotes by doubling them.
+	return fmt.Sprintf(`COPY %s (%s) FROM '%s' (FORMAT CSV, DELIMITER '\t', NULL '\N', QUOTE '"', ESCAPE '"', HEADER false)`,
+		tempTableName, strings.Join(colNames, ","), strings.ReplaceAll(filePath, "'", "''"))
+}
+
+func (s *Store) writeTemporaryTableFile(tableData *optimization.TableData, newTableName string) (string, error) {
+	// The table name is fully qualified and may be escaped, so we'll strip it down for the file name.
+	fileName := strings.NewReplacer(".", "_", `"`, "").Replace(newTableName)
+	fp := filepath.Join(os.TempDir(), fmt.Sprintf("%s.csv", fileName))
+	file, err := os.Create(fp)
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	writer.Comma = '\t'
+
+	additionalDateFmts := s.config.SharedTransferConfig.TypingSettings.AdditionalDateFormats
+	for _, value := range tableData.Rows() {
+		var row []string
+		for _, col := range tableData.ReadOnlyInMemoryCols().GetColumnsToUpdate(s.config.SharedDestinationConfig.GetIdentifierCasing(), nil) {
+			column, _ := tableData.ReadOnlyInMemoryCols().GetColumn(col)
+			castedValue, castErr := castColValStaging(value[col], column, additionalDateFmts)
+			if castErr != nil {
+				return "", castErr
+			}
+
+			row = append(row, castedValue)
+		}
+
+		if err = writer.Write(row); err != nil {
+			return "", fmt.Errorf("failed to write to csv: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return fp, writer.Error()
+}
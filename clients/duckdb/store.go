@@ -0,0 +1,144 @@
+package duckdb
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/artie-labs/transfer/clients/shared"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/db"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/destination/ddl"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/sql"
+)
+
+type Store struct {
+	configMap *types.DwhToTablesConfigMap
+	config    config.Config
+	db.Store
+}
+
+func (s *Store) Label() constants.DestinationKind {
+	return constants.DuckDB
+}
+
+func (s *Store) Merge(tableData *optimization.TableData) error {
+	if err := s.createSchema(tableData.TopicConfig.Schema); err != nil {
+		return err
+	}
+
+	err := shared.Merge(s, tableData, s.config, types.MergeOpts{
+		// DuckDB 0.9 does not support MERGE, so we'll issue an INSERT, UPDATE and DELETE within a transaction.
+		UseMergeParts: true,
+	})
+	return destination.ClassifyError(err, s.IsRetryableError)
+}
+
+func (s *Store) Append(tableData *optimization.TableData) error {
+	if err := s.createSchema(tableData.TopicConfig.Schema); err != nil {
+		return err
+	}
+
+	err := shared.Append(s, tableData, s.config, types.AppendOpts{
+		TempTableName: s.ToFullyQualifiedName(tableData, true),
+	})
+	return destination.ClassifyError(err, s.IsRetryableError)
+}
+
+// createSchema will create `schema` if it does not exist yet, DuckDB only comes with the `main` schema.
+func (s *Store) createSchema(schema string) error {
+	query := fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", sql.EscapeName(schema, s.config.SharedDestinationConfig.GetIdentifierCasing(), &sql.NameArgs{
+		Escape:   true,
+		DestKind: s.Label(),
+	}))
+
+	if _, err := s.Exec(query); err != nil {
+		return fmt.Errorf("failed to create schema %q: %w", schema, err)
+	}
+
+	return nil
+}
+
+func (s *Store) ToFullyQualifiedName(tableData *optimization.TableData, escape bool) string {
+	return tableData.ToFqName(s.Label(), escape, s.config.SharedDestinationConfig.GetIdentifierCasing(), optimization.FqNameOpts{})
+}
+
+func (s *Store) TemporaryTableName(tableData *optimization.TableData) string {
+	return tableData.TemporaryTableName(s.Label(), optimization.FqNameOpts{})
+}
+
+func (s *Store) Dedupe(fqTableName string) error {
+	return nil // dedupe is not necessary for DuckDB
+}
+
+func (s *Store) GetTableConfig(tableData *optimization.TableData) (*types.DwhTableConfig, error) {
+	const (
+		describeNameCol = "column_name"
+		describeTypeCol = "data_type"
+	)
+
+	query, args := describeTableQuery(tableData.TopicConfig.Schema, tableData.TruncatedName(s.Label()))
+	return shared.GetTableCfgArgs{
+		Dwh:             s,
+		FqName:          s.ToFullyQualifiedName(tableData, true),
+		ConfigMap:       s.configMap,
+		Query:           query,
+		Args:            args,
+		ColumnNameLabel: describeNameCol,
+		ColumnTypeLabel: describeTypeCol,
+		// DuckDB does not support column comments, so there is no description column.
+		DropDeletedColumns: tableData.TopicConfig.DropDeletedColumns,
+
+		CaseInsensitiveColumns: s.config.SharedDestinationConfig.CaseInsensitiveColumns,
+	}.GetTableConfig()
+}
+
+// Sweep will drop any dangling artie temporary tables.
+// We are not using [shared.Sweep] because the tables are not qualified with the topic's database, they are all within the same file.
+func (s *Store) Sweep() error {
+	tcs, err := s.config.TopicConfigs()
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Looking to see if there are any dangling artie temporary tables to delete...")
+	for _, dbAndSchemaPair := range kafkalib.GetUniqueDatabaseAndSchema(tcs) {
+		query, args := sweepQuery(dbAndSchemaPair.Schema)
+		rows, err := s.Query(query, args...)
+		if err != nil {
+			return err
+		}
+
+		for rows != nil && rows.Next() {
+			var tableSchema, tableName string
+			if err = rows.Scan(&tableSchema, &tableName); err != nil {
+				return err
+			}
+
+			if ddl.ShouldDeleteFromName(tableName) {
+				if err = ddl.DropTemporaryTable(s, fmt.Sprintf("%s.%s", tableSchema, tableName), true); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func LoadStore(cfg config.Config) (*Store, error) {
+	if !driverAvailable {
+		// The DuckDB driver requires cgo, our release builds are compiled without it.
+		return nil, fmt.Errorf("the duckdb destination requires transfer to be built with cgo (CGO_ENABLED=1)")
+	}
+
+	return &Store{
+		Store:     db.Open("duckdb", cfg.DuckDB.Path, nil),
+		configMap: types.NewDwhToTablesConfigMap(cfg.SchemaCachePath),
+		config:    cfg,
+	}, nil
+}
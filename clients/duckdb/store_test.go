@@ -0,0 +1,220 @@
+//go:build cgo
+
+package duckdb
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+func newTestStore(t *testing.T) *Store {
+	store, err := LoadStore(config.Config{
+		Output: constants.DuckDB,
+		DuckDB: &config.DuckDB{Path: filepath.Join(t.TempDir(), "transfer.duckdb")},
+	})
+	assert.NoError(t, err)
+	return store
+}
+
+type orderRow struct {
+	ID        int64
+	Name      *string
+	Price     *string
+	UpdatedAt *time.Time
+	Paid      *bool
+	Metadata  *string
+}
+
+func readOrders(t *testing.T, store *Store, fqName string) []orderRow {
+	rows, err := store.Query("SELECT id, name, price::VARCHAR, updated_at, paid, metadata FROM " + fqName + " ORDER BY id")
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	var orders []orderRow
+	for rows.Next() {
+		var order orderRow
+		assert.NoError(t, rows.Scan(&order.ID, &order.Name, &order.Price, &order.UpdatedAt, &order.Paid, &order.Metadata))
+		orders = append(orders, order)
+	}
+
+	assert.NoError(t, rows.Err())
+	return orders
+}
+
+func TestStore_Merge(t *testing.T) {
+	store := newTestStore(t)
+	tc := kafkalib.TopicConfig{Database: "shop", Schema: "public"}
+
+	priceKind := typing.EDecimal
+	priceKind.ExtendedDecimalDetails = decimal.NewDecimal(ptr.ToInt(10), 2, nil)
+
+	cols := &columns.Columns{}
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("name", typing.String))
+	cols.AddColumn(columns.NewColumn("price", priceKind))
+	cols.AddColumn(columns.NewColumn("updated_at", typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateTimeKindType)))
+	cols.AddColumn(columns.NewColumn("paid", typing.Boolean))
+	cols.AddColumn(columns.NewColumn("metadata", typing.Struct))
+	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+
+	updatedAt := time.Date(2023, 3, 13, 19, 31, 35, 123456000, time.UTC)
+	price := func(value float64) *decimal.Decimal {
+		return decimal.NewDecimal(ptr.ToInt(10), 2, big.NewFloat(value))
+	}
+
+	{
+		// The schema and table do not exist yet, so they will be created.
+		tableData := optimization.NewTableData(cols, config.Replication, []string{"id"}, tc, "orders")
+		tableData.ResetTempTableSuffix()
+		tableData.InsertRow("1", map[string]any{
+			"id":                         1,
+			"name":                       "foo",
+			"price":                      price(12.34),
+			"updated_at":                 ext.NewExtendedTime(updatedAt, ext.DateTimeKindType, ""),
+			"paid":                       true,
+			"metadata":                   map[string]any{"tags": []any{"a", "b"}},
+			constants.DeleteColumnMarker: false,
+		}, false)
+		tableData.InsertRow("2", map[string]any{
+			"id":                         2,
+			"name":                       `bar "quoted"	with a tab`,
+			"price":                      price(0.5),
+			"updated_at":                 nil,
+			"paid":                       false,
+			"metadata":                   nil,
+			constants.DeleteColumnMarker: false,
+		}, false)
+		assert.NoError(t, store.Merge(tableData))
+
+		orders := readOrders(t, store, store.ToFullyQualifiedName(tableData, true))
+		assert.Len(t, orders, 2)
+		assert.Equal(t, orderRow{
+			ID:        1,
+			Name:      ptr.ToString("foo"),
+			Price:     ptr.ToString("12.34"),
+			UpdatedAt: &updatedAt,
+			Paid:      ptr.ToBool(true),
+			Metadata:  ptr.ToString(`{"tags":["a","b"]}`),
+		}, orders[0])
+		assert.Equal(t, orderRow{
+			ID:    2,
+			Name:  ptr.ToString(`bar "quoted"	with a tab`),
+			Price: ptr.ToString("0.50"),
+			Paid:  ptr.ToBool(false),
+		}, orders[1])
+
+		// The table should have been created with the DuckDB types.
+		tableConfig, err := store.GetTableConfig(tableData)
+		assert.NoError(t, err)
+		assert.False(t, tableConfig.CreateTable())
+		priceCol, isOk := tableConfig.Columns().GetColumn("price")
+		assert.True(t, isOk)
+		assert.Equal(t, "DECIMAL(10, 2)", typing.KindToDWHType(priceCol.KindDetails, constants.DuckDB, false))
+		updatedAtCol, isOk := tableConfig.Columns().GetColumn("updated_at")
+		assert.True(t, isOk)
+		assert.Equal(t, ext.DateTimeKindType, updatedAtCol.KindDetails.ExtendedTimeDetails.Type)
+	}
+	{
+		// Row 1 is updated, row 2 is deleted and row 3 is inserted.
+		tableData := optimization.NewTableData(cols, config.Replication, []string{"id"}, tc, "orders")
+		tableData.ResetTempTableSuffix()
+		tableData.InsertRow("1", map[string]any{
+			"id":                         1,
+			"name":                       "foo2",
+			"price":                      price(99.99),
+			"updated_at":                 ext.NewExtendedTime(updatedAt.Add(time.Hour), ext.DateTimeKindType, ""),
+			"paid":                       false,
+			"metadata":                   nil,
+			constants.DeleteColumnMarker: false,
+		}, false)
+		tableData.InsertRow("2", map[string]any{"id": 2, constants.DeleteColumnMarker: true}, true)
+		tableData.InsertRow("3", map[string]any{"id": 3, "name": "baz", constants.DeleteColumnMarker: false}, false)
+		assert.NoError(t, store.Merge(tableData))
+
+		orders := readOrders(t, store, store.ToFullyQualifiedName(tableData, true))
+		assert.Len(t, orders, 2)
+		updatedAt = updatedAt.Add(time.Hour)
+		assert.Equal(t, orderRow{
+			ID:        1,
+			Name:      ptr.ToString("foo2"),
+			Price:     ptr.ToString("99.99"),
+			UpdatedAt: &updatedAt,
+			Paid:      ptr.ToBool(false),
+		}, orders[0])
+		assert.Equal(t, orderRow{ID: 3, Name: ptr.ToString("baz")}, orders[1])
+	}
+	{
+		// The temporary tables should have been dropped.
+		rows, err := store.Query("SELECT COUNT(*) FROM information_schema.tables WHERE table_name LIKE ?", "%"+constants.ArtiePrefix+"%")
+		assert.NoError(t, err)
+		assert.True(t, rows.Next())
+		var count int
+		assert.NoError(t, rows.Scan(&count))
+		assert.NoError(t, rows.Close())
+		assert.Zero(t, count)
+	}
+}
+
+func TestStore_Append(t *testing.T) {
+	store := newTestStore(t)
+	tc := kafkalib.TopicConfig{Database: "shop", Schema: "history"}
+
+	cols := &columns.Columns{}
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("payload", typing.Bytes))
+	cols.AddColumn(columns.NewColumn(constants.OperationColumnMarker, typing.String))
+
+	tableData := optimization.NewTableData(cols, config.History, []string{"id"}, tc, "events__history")
+	tableData.InsertRow("1", map[string]any{"id": 1, "payload": []byte{0xde, 0xad, 0xbe, 0xef}, constants.OperationColumnMarker: "c"}, false)
+	tableData.InsertRow("1", map[string]any{"id": 1, "payload": nil, constants.OperationColumnMarker: "d"}, true)
+	assert.NoError(t, store.Append(tableData))
+
+	rows, err := store.Query("SELECT id, payload, __artie_operation FROM history.events__history ORDER BY __artie_operation")
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	type event struct {
+		ID        int64
+		Payload   []byte
+		Operation string
+	}
+
+	var events []event
+	for rows.Next() {
+		var e event
+		assert.NoError(t, rows.Scan(&e.ID, &e.Payload, &e.Operation))
+		events = append(events, e)
+	}
+
+	// Both rows are kept when appending, even though they have the same primary key.
+	assert.Equal(t, []event{
+		{ID: 1, Payload: []byte{0xde, 0xad, 0xbe, 0xef}, Operation: "c"},
+		{ID: 1, Operation: "d"},
+	}, events)
+}
+
+func TestCopyStatement(t *testing.T) {
+	store := &Store{config: config.Config{Output: constants.DuckDB}}
+	cols := &columns.Columns{}
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("group", typing.String))
+
+	assert.Equal(t,
+		`COPY public.orders (id,"group") FROM '/tmp/it''s.csv' (FORMAT CSV, DELIMITER '\t', NULL '\N', QUOTE '"', ESCAPE '"', HEADER false)`,
+		store.copyStatement(cols, "public.orders", "/tmp/it's.csv"),
+	)
+}
@@ -0,0 +1,203 @@
+package iceberg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/artie-labs/transfer/lib/config"
+)
+
+var (
+	errNotFound = errors.New("not found")
+	// errConflict is returned when the namespace already exists or when the table was updated by someone else since we loaded it.
+	errConflict = errors.New("conflict")
+)
+
+type catalogError struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
+
+type loadTableResponse struct {
+	MetadataLocation string        `json:"metadata-location"`
+	Metadata         tableMetadata `json:"metadata"`
+}
+
+type createTableRequest struct {
+	Name       string            `json:"name"`
+	Schema     schema            `json:"schema"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type commitTableRequest struct {
+	Requirements []map[string]any `json:"requirements"`
+	Updates      []map[string]any `json:"updates"`
+}
+
+// catalogClient is a client for the Iceberg REST catalog, spec: https://github.com/apache/iceberg/blob/main/open-api/rest-catalog-open-api.yaml
+type catalogClient struct {
+	baseURL    string
+	prefix     string
+	token      string
+	namespace  []string
+	httpClient *http.Client
+}
+
+// newCatalogClient will fetch the catalog's config for our warehouse, this tells us which prefix (if any) the catalog expects in the routes.
+func newCatalogClient(cfg config.Iceberg) (*catalogClient, error) {
+	client := &catalogClient{
+		baseURL:    strings.TrimSuffix(cfg.CatalogURI, "/"),
+		token:      cfg.Token,
+		namespace:  cfg.NamespaceLevels(),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+
+	var resp struct {
+		Defaults  map[string]string `json:"defaults"`
+		Overrides map[string]string `json:"overrides"`
+	}
+
+	query := url.Values{}
+	query.Set("warehouse", cfg.Warehouse)
+	if err := client.do(http.MethodGet, "/v1/config?"+query.Encode(), nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch catalog config: %w", err)
+	}
+
+	if prefix, isOk := resp.Overrides["prefix"]; isOk {
+		client.prefix = prefix
+	} else {
+		client.prefix = resp.Defaults["prefix"]
+	}
+
+	return client, nil
+}
+
+func (c *catalogClient) namespacesPath() string {
+	if c.prefix == "" {
+		return "/v1/namespaces"
+	}
+
+	return fmt.Sprintf("/v1/%s/namespaces", url.PathEscape(c.prefix))
+}
+
+func (c *catalogClient) tablesPath() string {
+	// Namespace levels are separated by the unit separator character.
+	return fmt.Sprintf("%s/%s/tables", c.namespacesPath(), url.PathEscape(strings.Join(c.namespace, "\x1f")))
+}
+
+func (c *catalogClient) tablePath(tableName string) string {
+	return fmt.Sprintf("%s/%s", c.tablesPath(), url.PathEscape(tableName))
+}
+
+// ensureNamespace will create the namespace if it does not exist yet.
+func (c *catalogClient) ensureNamespace() error {
+	body := map[string]any{"namespace": c.namespace}
+	if err := c.do(http.MethodPost, c.namespacesPath(), body, nil); err != nil && !errors.Is(err, errConflict) {
+		return fmt.Errorf("failed to create namespace: %w", err)
+	}
+
+	return nil
+}
+
+func (c *catalogClient) loadTable(tableName string) (*loadTableResponse, error) {
+	var resp loadTableResponse
+	if err := c.do(http.MethodGet, c.tablePath(tableName), nil, &resp); err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+func (c *catalogClient) createTable(tableName string, tableSchema schema) (*loadTableResponse, error) {
+	var resp loadTableResponse
+	body := createTableRequest{
+		Name:       tableName,
+		Schema:     tableSchema,
+		Properties: map[string]string{"format-version": "2"},
+	}
+
+	if err := c.do(http.MethodPost, c.tablesPath(), body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to create table: %w", err)
+	}
+
+	return &resp, nil
+}
+
+func (c *catalogClient) commitTable(tableName string, request commitTableRequest) (*loadTableResponse, error) {
+	var resp loadTableResponse
+	if err := c.do(http.MethodPost, c.tablePath(tableName), request, &resp); err != nil {
+		return nil, fmt.Errorf("failed to commit table: %w", err)
+	}
+
+	return &resp, nil
+}
+
+func (c *catalogClient) do(method, path string, body any, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		reqBody = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var catalogErr catalogError
+		message := string(respBytes)
+		if json.Unmarshal(respBytes, &catalogErr) == nil && catalogErr.Error.Message != "" {
+			message = fmt.Sprintf("%s: %s", catalogErr.Error.Type, catalogErr.Error.Message)
+		}
+
+		switch resp.StatusCode {
+		case http.StatusNotFound:
+			return fmt.Errorf("%w: %s", errNotFound, message)
+		case http.StatusConflict:
+			return fmt.Errorf("%w: %s", errConflict, message)
+		}
+
+		return fmt.Errorf("catalog returned status code: %d, message: %s", resp.StatusCode, message)
+	}
+
+	if out == nil || len(respBytes) == 0 {
+		return nil
+	}
+
+	if err = json.Unmarshal(respBytes, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,266 @@
+package iceberg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/s3lib"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+	"github.com/artie-labs/transfer/lib/typing/values"
+)
+
+// fileIO is used to read and write the data and metadata files that live in the table's location.
+type fileIO interface {
+	// upload will upload the local file into the `location` directory and return the file's URI.
+	upload(localPath, location string) (string, error)
+	read(uri string) ([]byte, error)
+}
+
+type s3FileIO struct {
+	awsAccessKeyID     *string
+	awsSecretAccessKey *string
+}
+
+func (s s3FileIO) upload(localPath, location string) (string, error) {
+	bucket, prefix, err := s3lib.ParseURI(location)
+	if err != nil {
+		return "", err
+	}
+
+	return s3lib.UploadLocalFileToS3(context.Background(), s3lib.UploadArgs{
+		Bucket:                     bucket,
+		OptionalS3Prefix:           strings.TrimSuffix(prefix, "/"),
+		FilePath:                   localPath,
+		OverrideAWSAccessKeyID:     s.awsAccessKeyID,
+		OverrideAWSAccessKeySecret: s.awsSecretAccessKey,
+	})
+}
+
+func (s s3FileIO) read(uri string) ([]byte, error) {
+	bucket, key, err := s3lib.ParseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return s3lib.ReadObject(context.Background(), s3lib.ReadArgs{
+		Bucket:                     bucket,
+		Key:                        key,
+		OverrideAWSAccessKeyID:     s.awsAccessKeyID,
+		OverrideAWSAccessKeySecret: s.awsSecretAccessKey,
+	})
+}
+
+// dataColumn maps an in-memory column to the table's field.
+type dataColumn struct {
+	column columns.Column
+	field  field
+}
+
+// decimalRequiredBytes returns the number of bytes needed to store an unscaled decimal value with `precision` digits.
+func decimalRequiredBytes(precision int) int {
+	return int(math.Ceil((math.Log2(math.Pow(10, float64(precision))-1) + 1) / 8))
+}
+
+// parquetField returns the Parquet annotation for the field, following: https://iceberg.apache.org/spec/#parquet
+func parquetField(f field) (typing.Field, error) {
+	tag := typing.FieldTag{
+		Name:    f.Name,
+		InName:  ptr.ToString(f.Name),
+		FieldID: ptr.ToInt(f.ID),
+	}
+
+	if f.Required {
+		tag.RepetitionType = ptr.ToString("REQUIRED")
+	}
+
+	switch typeName := f.typeName(); typeName {
+	case "boolean":
+		tag.Type = ptr.ToString("BOOLEAN")
+	case "int":
+		tag.Type = ptr.ToString("INT32")
+	case "long":
+		tag.Type = ptr.ToString("INT64")
+	case "float":
+		tag.Type = ptr.ToString("FLOAT")
+	case "double":
+		tag.Type = ptr.ToString("DOUBLE")
+	case "date":
+		tag.Type = ptr.ToString("INT32")
+		tag.ConvertedType = ptr.ToString("DATE")
+	case "time":
+		tag.Type = ptr.ToString("INT64")
+		tag.ConvertedType = ptr.ToString("TIME_MICROS")
+	case "timestamp", "timestamptz":
+		tag.Type = ptr.ToString("INT64")
+		tag.ConvertedType = ptr.ToString("TIMESTAMP_MICROS")
+	case "string":
+		tag.Type = ptr.ToString("BYTE_ARRAY")
+		tag.ConvertedType = ptr.ToString("UTF8")
+	default:
+		var precision, scale int
+		if _, err := fmt.Sscanf(strings.ReplaceAll(typeName, " ", ""), "decimal(%d,%d)", &precision, &scale); err != nil {
+			return typing.Field{}, fmt.Errorf("unsupported iceberg type: %q for column: %q", typeName, f.Name)
+		}
+
+		tag.Type = ptr.ToString("FIXED_LEN_BYTE_ARRAY")
+		tag.ConvertedType = ptr.ToString("DECIMAL")
+		tag.Precision = ptr.ToInt(precision)
+		tag.Scale = ptr.ToInt(scale)
+		tag.Length = ptr.ToInt(decimalRequiredBytes(precision))
+	}
+
+	return typing.Field{Tag: tag.String()}, nil
+}
+
+// toParquetValue will convert the value so the Parquet writer can encode it as the field's type.
+func toParquetValue(value any, col dataColumn, additionalDateFmts []string) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch typeName := col.field.typeName(); typeName {
+	case "string":
+		switch castedValue := value.(type) {
+		case string:
+			return castedValue, nil
+		case *decimal.Decimal:
+			return castedValue.String(), nil
+		}
+
+		if kind := reflect.ValueOf(value).Kind(); kind == reflect.Map || kind == reflect.Slice {
+			valueBytes, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+
+			return string(valueBytes), nil
+		}
+
+		return values.ToString(value, col.column, additionalDateFmts)
+	case "date", "time", "timestamp", "timestamptz":
+		extTime, err := ext.ParseFromInterface(value, additionalDateFmts)
+		if err != nil {
+			return nil, err
+		}
+
+		ts := extTime.Time
+		switch typeName {
+		case "date":
+			// Days since the epoch.
+			return time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60), nil
+		case "time":
+			// Microseconds since midnight.
+			return (time.Duration(ts.Hour())*time.Hour + time.Duration(ts.Minute())*time.Minute +
+				time.Duration(ts.Second())*time.Second + time.Duration(ts.Nanosecond())).Microseconds(), nil
+		}
+
+		return ts.UnixMicro(), nil
+	case "float", "double":
+		if castedValue, isOk := value.(*decimal.Decimal); isOk {
+			return castedValue.Float64(), nil
+		}
+	case "boolean":
+		if castedValue, isOk := value.(string); isOk {
+			return strconv.ParseBool(castedValue)
+		}
+	default:
+		if strings.HasPrefix(typeName, "decimal") {
+			return fmt.Sprint(value), nil
+		}
+	}
+
+	return value, nil
+}
+
+// writeParquetFile will write the rows into a Parquet file at `fp` and return the number of rows written.
+func writeParquetFile(fp string, cols []dataColumn, rows []map[string]any, additionalDateFmts []string) (int64, error) {
+	var fields []typing.Field
+	for _, col := range cols {
+		f, err := parquetField(col.field)
+		if err != nil {
+			return 0, err
+		}
+
+		fields = append(fields, f)
+	}
+
+	schemaBytes, err := json.Marshal(typing.Field{
+		Tag: typing.FieldTag{
+			Name:           "parquet-go-root",
+			RepetitionType: ptr.ToString("REQUIRED"),
+		}.String(),
+		Fields: fields,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal parquet schema: %w", err)
+	}
+
+	fw, err := local.NewLocalFileWriter(fp)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create a local parquet file: %w", err)
+	}
+
+	defer fw.Close()
+	pw, err := writer.NewJSONWriter(string(schemaBytes), fw, 4)
+	if err != nil {
+		return 0, fmt.Errorf("failed to instantiate parquet writer: %w", err)
+	}
+
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+	for _, row := range rows {
+		parquetRow := make(map[string]any)
+		for _, col := range cols {
+			value, err := toParquetValue(row[col.column.RawName()], col, additionalDateFmts)
+			if err != nil {
+				return 0, fmt.Errorf("failed to parse value, err: %w, value: %v, column: %v", err, row[col.column.RawName()], col.column.RawName())
+			}
+
+			parquetRow[col.field.Name] = value
+		}
+
+		rowBytes, err := json.Marshal(parquetRow)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal row: %w", err)
+		}
+
+		if err = pw.Write(string(rowBytes)); err != nil {
+			return 0, fmt.Errorf("failed to write row: %w", err)
+		}
+	}
+
+	if err = pw.WriteStop(); err != nil {
+		return 0, fmt.Errorf("failed to write stop: %w", err)
+	}
+
+	return int64(len(rows)), nil
+}
+
+// fileSize returns the size of the local file in bytes.
+func fileSize(fp string) (int64, error) {
+	info, err := os.Stat(fp)
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
+
+func tempFilePath(name string) string {
+	return filepath.Join(os.TempDir(), name)
+}
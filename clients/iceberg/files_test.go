@@ -0,0 +1,128 @@
+package iceberg
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+func TestDecimalRequiredBytes(t *testing.T) {
+	assert.Equal(t, 1, decimalRequiredBytes(2))
+	assert.Equal(t, 4, decimalRequiredBytes(9))
+	assert.Equal(t, 5, decimalRequiredBytes(10))
+	assert.Equal(t, 8, decimalRequiredBytes(18))
+	assert.Equal(t, 16, decimalRequiredBytes(38))
+}
+
+func TestParquetField(t *testing.T) {
+	{
+		// Required fields
+		f, err := parquetField(field{ID: 1, Name: "id", Required: true, Type: primitiveType("long")})
+		assert.NoError(t, err)
+		assert.Equal(t, "name=id, inname=id, type=INT64, repetitiontype=REQUIRED, fieldid=1", f.Tag)
+	}
+	{
+		// Optional fields
+		f, err := parquetField(field{ID: 2, Name: "created_at", Type: primitiveType("timestamptz")})
+		assert.NoError(t, err)
+		assert.Equal(t, "name=created_at, inname=created_at, type=INT64, convertedtype=TIMESTAMP_MICROS, repetitiontype=OPTIONAL, fieldid=2", f.Tag)
+	}
+	{
+		// Decimals
+		f, err := parquetField(field{ID: 3, Name: "price", Type: primitiveType("decimal(10, 2)")})
+		assert.NoError(t, err)
+		assert.Equal(t, "name=price, inname=price, type=FIXED_LEN_BYTE_ARRAY, convertedtype=DECIMAL, repetitiontype=OPTIONAL, scale=2, precision=10, length=5, fieldid=3", f.Tag)
+	}
+	{
+		// Unsupported types
+		_, err := parquetField(field{ID: 4, Name: "tags", Type: []byte(`{"type": "list", "element-id": 5, "element": "string", "element-required": false}`)})
+		assert.ErrorContains(t, err, `unsupported iceberg type: "list" for column: "tags"`)
+
+		_, err = parquetField(field{ID: 5, Name: "payload", Type: primitiveType("binary")})
+		assert.ErrorContains(t, err, `unsupported iceberg type: "binary" for column: "payload"`)
+	}
+}
+
+func TestToParquetValue(t *testing.T) {
+	newCol := func(typeName string, kd typing.KindDetails) dataColumn {
+		return dataColumn{column: columns.NewColumn("col", kd), field: field{ID: 1, Name: "col", Type: primitiveType(typeName)}}
+	}
+
+	{
+		// Nil values
+		value, err := toParquetValue(nil, newCol("string", typing.String), nil)
+		assert.NoError(t, err)
+		assert.Nil(t, value)
+	}
+	{
+		// Strings
+		value, err := toParquetValue(`C:\foo`, newCol("string", typing.String), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, `C:\foo`, value)
+
+		value, err = toParquetValue(map[string]any{"foo": "bar"}, newCol("string", typing.Struct), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"foo":"bar"}`, value)
+
+		value, err = toParquetValue([]any{"foo", "bar"}, newCol("string", typing.Array), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, `["foo","bar"]`, value)
+
+		value, err = toParquetValue(123, newCol("string", typing.Integer), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "123", value)
+	}
+	{
+		// Time types
+		value, err := toParquetValue("2023-03-13T19:15:42.123456Z", newCol("timestamptz", typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateTimeKindType)), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1678734942123456), value)
+
+		value, err = toParquetValue("2023-03-13", newCol("date", typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateKindType)), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(19429), value)
+
+		value, err = toParquetValue("1965-01-01", newCol("date", typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateKindType)), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(-1826), value)
+
+		value, err = toParquetValue("01:02:03.5", newCol("time", typing.NewKindDetailsFromTemplate(typing.ETime, ext.TimeKindType)), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3723500000), value)
+
+		_, err = toParquetValue("foo", newCol("timestamptz", typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateTimeKindType)), nil)
+		assert.ErrorContains(t, err, "failed to cast colVal as time.Time")
+	}
+	{
+		// Numbers
+		dec := decimal.NewDecimal(ptr.ToInt(10), 2, big.NewFloat(123.45))
+		value, err := toParquetValue(dec, newCol("decimal(10, 2)", typing.EDecimal), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "123.45", value)
+
+		value, err = toParquetValue(dec, newCol("double", typing.Float), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 123.45, value)
+
+		value, err = toParquetValue(5, newCol("long", typing.Integer), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, value)
+	}
+	{
+		// Booleans
+		value, err := toParquetValue("true", newCol("boolean", typing.Boolean), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, true, value)
+
+		value, err = toParquetValue(false, newCol("boolean", typing.Boolean), nil)
+		assert.NoError(t, err)
+		assert.Equal(t, false, value)
+	}
+}
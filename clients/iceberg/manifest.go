@@ -0,0 +1,237 @@
+package iceberg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// The Avro schemas for the manifest and manifest list files (format version 2), spec: https://iceberg.apache.org/spec/#manifests
+// Iceberg readers match the fields by their `field-id`, so optional fields that we don't populate (e.g. column stats) are left out.
+const (
+	manifestEntrySchema = `{
+  "type": "record",
+  "name": "manifest_entry",
+  "fields": [
+    {"name": "status", "type": "int", "field-id": 0},
+    {"name": "snapshot_id", "type": ["null", "long"], "default": null, "field-id": 1},
+    {"name": "sequence_number", "type": ["null", "long"], "default": null, "field-id": 3},
+    {"name": "file_sequence_number", "type": ["null", "long"], "default": null, "field-id": 4},
+    {"name": "data_file", "field-id": 2, "type": {
+      "type": "record",
+      "name": "r2",
+      "fields": [
+        {"name": "content", "type": "int", "field-id": 134},
+        {"name": "file_path", "type": "string", "field-id": 100},
+        {"name": "file_format", "type": "string", "field-id": 101},
+        {"name": "partition", "type": {"type": "record", "name": "r102", "fields": []}, "field-id": 102},
+        {"name": "record_count", "type": "long", "field-id": 103},
+        {"name": "file_size_in_bytes", "type": "long", "field-id": 104},
+        {"name": "equality_ids", "type": ["null", {"type": "array", "items": "int", "element-id": 136}], "default": null, "field-id": 135}
+      ]
+    }}
+  ]
+}`
+
+	manifestListSchema = `{
+  "type": "record",
+  "name": "manifest_file",
+  "fields": [
+    {"name": "manifest_path", "type": "string", "field-id": 500},
+    {"name": "manifest_length", "type": "long", "field-id": 501},
+    {"name": "partition_spec_id", "type": "int", "field-id": 502},
+    {"name": "content", "type": "int", "field-id": 517},
+    {"name": "sequence_number", "type": "long", "field-id": 515},
+    {"name": "min_sequence_number", "type": "long", "field-id": 516},
+    {"name": "added_snapshot_id", "type": "long", "field-id": 503},
+    {"name": "added_files_count", "type": "int", "field-id": 504},
+    {"name": "existing_files_count", "type": "int", "field-id": 505},
+    {"name": "deleted_files_count", "type": "int", "field-id": 506},
+    {"name": "added_rows_count", "type": "long", "field-id": 512},
+    {"name": "existing_rows_count", "type": "long", "field-id": 513},
+    {"name": "deleted_rows_count", "type": "long", "field-id": 514}
+  ]
+}`
+)
+
+type fileContent int
+
+const (
+	fileContentData           fileContent = 0
+	fileContentEqualityDelete fileContent = 2
+)
+
+type manifestContent int
+
+const (
+	manifestContentData    manifestContent = 0
+	manifestContentDeletes manifestContent = 1
+)
+
+// manifestEntryStatusAdded is the status for files that were added in the snapshot.
+const manifestEntryStatusAdded = 1
+
+type dataFile struct {
+	content     fileContent
+	path        string
+	recordCount int64
+	sizeInBytes int64
+	// equalityIDs are the field IDs used to match rows for equality deletes.
+	equalityIDs []int
+}
+
+type manifest struct {
+	path    string
+	length  int64
+	content manifestContent
+	files   []dataFile
+}
+
+// writeManifest will write an Avro manifest file for `files` to `fp`, all the files must have the same manifest content.
+func writeManifest(fp string, tableSchema schema, specID int, snapshotID int64, content manifestContent, files []dataFile) error {
+	schemaBytes, err := json.Marshal(tableSchema)
+	if err != nil {
+		return fmt.Errorf("failed to marshal table schema: %w", err)
+	}
+
+	contentName := "data"
+	if content == manifestContentDeletes {
+		contentName = "deletes"
+	}
+
+	var entries []any
+	for _, file := range files {
+		var equalityIDs any
+		if len(file.equalityIDs) > 0 {
+			var ids []any
+			for _, id := range file.equalityIDs {
+				ids = append(ids, int32(id))
+			}
+
+			equalityIDs = goavro.Union("array", ids)
+		}
+
+		entries = append(entries, map[string]any{
+			"status":      manifestEntryStatusAdded,
+			"snapshot_id": goavro.Union("long", snapshotID),
+			// Sequence numbers for added files are inherited from the manifest list.
+			"sequence_number":      nil,
+			"file_sequence_number": nil,
+			"data_file": map[string]any{
+				"content":            int32(file.content),
+				"file_path":          file.path,
+				"file_format":        "PARQUET",
+				"partition":          map[string]any{},
+				"record_count":       file.recordCount,
+				"file_size_in_bytes": file.sizeInBytes,
+				"equality_ids":       equalityIDs,
+			},
+		})
+	}
+
+	return writeAvroFile(fp, manifestEntrySchema, map[string][]byte{
+		"schema":            schemaBytes,
+		"schema-id":         []byte(strconv.Itoa(tableSchema.SchemaID)),
+		"partition-spec":    []byte("[]"),
+		"partition-spec-id": []byte(strconv.Itoa(specID)),
+		"format-version":    []byte("2"),
+		"content":           []byte(contentName),
+	}, entries)
+}
+
+// manifestListEntry returns the manifest list entry for a manifest that was added in this snapshot.
+func manifestListEntry(m manifest, specID int, snapshotID, sequenceNumber int64) map[string]any {
+	var addedRows int64
+	for _, file := range m.files {
+		addedRows += file.recordCount
+	}
+
+	return map[string]any{
+		"manifest_path":        m.path,
+		"manifest_length":      m.length,
+		"partition_spec_id":    int32(specID),
+		"content":              int32(m.content),
+		"sequence_number":      sequenceNumber,
+		"min_sequence_number":  sequenceNumber,
+		"added_snapshot_id":    snapshotID,
+		"added_files_count":    int32(len(m.files)),
+		"existing_files_count": int32(0),
+		"deleted_files_count":  int32(0),
+		"added_rows_count":     addedRows,
+		"existing_rows_count":  int64(0),
+		"deleted_rows_count":   int64(0),
+	}
+}
+
+// writeManifestList will write the manifest list for a snapshot, `entries` should include the manifests from the parent snapshot.
+func writeManifestList(fp string, snapshotID int64, parentSnapshotID *int64, sequenceNumber int64, entries []map[string]any) error {
+	metadata := map[string][]byte{
+		"snapshot-id":     []byte(strconv.FormatInt(snapshotID, 10)),
+		"sequence-number": []byte(strconv.FormatInt(sequenceNumber, 10)),
+		"format-version":  []byte("2"),
+	}
+
+	if parentSnapshotID != nil {
+		metadata["parent-snapshot-id"] = []byte(strconv.FormatInt(*parentSnapshotID, 10))
+	}
+
+	var records []any
+	for _, entry := range entries {
+		records = append(records, entry)
+	}
+
+	return writeAvroFile(fp, manifestListSchema, metadata, records)
+}
+
+// readManifestList will return the entries of an existing manifest list so they can be carried over to the next snapshot.
+func readManifestList(data []byte) ([]map[string]any, error) {
+	reader, err := goavro.NewOCFReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest list: %w", err)
+	}
+
+	var entries []map[string]any
+	for reader.Scan() {
+		record, err := reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest list entry: %w", err)
+		}
+
+		entry, isOk := record.(map[string]any)
+		if !isOk {
+			return nil, fmt.Errorf("unexpected manifest list entry type: %T", record)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, reader.Err()
+}
+
+func writeAvroFile(fp string, schema string, metadata map[string][]byte, records []any) error {
+	file, err := os.Create(fp)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	defer file.Close()
+	ocfWriter, err := goavro.NewOCFWriter(goavro.OCFConfig{
+		W:               file,
+		Schema:          schema,
+		CompressionName: goavro.CompressionDeflateLabel,
+		MetaData:        metadata,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create avro writer: %w", err)
+	}
+
+	if err = ocfWriter.Append(records); err != nil {
+		return fmt.Errorf("failed to write avro records: %w", err)
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,100 @@
+package iceberg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/ptr"
+)
+
+func readAvroFile(t *testing.T, fp string) (map[string][]byte, []map[string]any) {
+	data, err := os.ReadFile(fp)
+	assert.NoError(t, err)
+
+	reader, err := goavro.NewOCFReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	var records []map[string]any
+	for reader.Scan() {
+		record, err := reader.Read()
+		assert.NoError(t, err)
+		records = append(records, record.(map[string]any))
+	}
+
+	assert.NoError(t, reader.Err())
+	return reader.MetaData(), records
+}
+
+func TestWriteManifest(t *testing.T) {
+	tableSchema := schema{Type: "struct", SchemaID: 1, Fields: []field{{ID: 1, Name: "id", Required: true, Type: primitiveType("long")}}}
+	fp := filepath.Join(t.TempDir(), "manifest.avro")
+	assert.NoError(t, writeManifest(fp, tableSchema, 0, 123, manifestContentDeletes, []dataFile{
+		{content: fileContentEqualityDelete, path: "s3://bucket/table/data/foo.parquet", recordCount: 2, sizeInBytes: 100, equalityIDs: []int{1}},
+	}))
+
+	metadata, records := readAvroFile(t, fp)
+	assert.Equal(t, `{"type":"struct","schema-id":1,"fields":[{"id":1,"name":"id","required":true,"type":"long"}]}`, string(metadata["schema"]))
+	assert.Equal(t, "1", string(metadata["schema-id"]))
+	assert.Equal(t, "0", string(metadata["partition-spec-id"]))
+	assert.Equal(t, "2", string(metadata["format-version"]))
+	assert.Equal(t, "deletes", string(metadata["content"]))
+
+	assert.Len(t, records, 1)
+	assert.Equal(t, int32(manifestEntryStatusAdded), records[0]["status"])
+	assert.Equal(t, map[string]any{"long": int64(123)}, records[0]["snapshot_id"])
+	assert.Nil(t, records[0]["sequence_number"])
+	assert.Equal(t, map[string]any{
+		"content":            int32(2),
+		"file_path":          "s3://bucket/table/data/foo.parquet",
+		"file_format":        "PARQUET",
+		"partition":          map[string]any{},
+		"record_count":       int64(2),
+		"file_size_in_bytes": int64(100),
+		"equality_ids":       map[string]any{"array": []any{int32(1)}},
+	}, records[0]["data_file"])
+}
+
+func TestManifestList(t *testing.T) {
+	dir := t.TempDir()
+	m := manifest{
+		path:    "s3://bucket/table/metadata/foo-m0.avro",
+		length:  500,
+		content: manifestContentData,
+		files:   []dataFile{{recordCount: 5}, {recordCount: 3}},
+	}
+
+	// The first snapshot does not have a parent.
+	first := filepath.Join(dir, "snap-1.avro")
+	assert.NoError(t, writeManifestList(first, 1, nil, 1, []map[string]any{manifestListEntry(m, 0, 1, 1)}))
+	metadata, records := readAvroFile(t, first)
+	assert.Equal(t, "1", string(metadata["snapshot-id"]))
+	assert.Equal(t, "1", string(metadata["sequence-number"]))
+	assert.NotContains(t, metadata, "parent-snapshot-id")
+	assert.Len(t, records, 1)
+	assert.Equal(t, "s3://bucket/table/metadata/foo-m0.avro", records[0]["manifest_path"])
+	assert.Equal(t, int32(2), records[0]["added_files_count"])
+	assert.Equal(t, int64(8), records[0]["added_rows_count"])
+
+	// The next snapshot should carry over the existing manifests.
+	data, err := os.ReadFile(first)
+	assert.NoError(t, err)
+	entries, err := readManifestList(data)
+	assert.NoError(t, err)
+
+	m.path = "s3://bucket/table/metadata/bar-m0.avro"
+	m.content = manifestContentDeletes
+	second := filepath.Join(dir, "snap-2.avro")
+	assert.NoError(t, writeManifestList(second, 2, ptr.ToInt64(1), 2, append(entries, manifestListEntry(m, 0, 2, 2))))
+	metadata, records = readAvroFile(t, second)
+	assert.Equal(t, "1", string(metadata["parent-snapshot-id"]))
+	assert.Len(t, records, 2)
+	assert.Equal(t, int64(1), records[0]["sequence_number"])
+	assert.Equal(t, int32(0), records[0]["content"])
+	assert.Equal(t, int64(2), records[1]["sequence_number"])
+	assert.Equal(t, int32(1), records[1]["content"])
+}
@@ -0,0 +1,212 @@
+package iceberg
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+// mainBranch is the branch that we'll commit snapshots to.
+const mainBranch = "main"
+
+type field struct {
+	ID       int             `json:"id"`
+	Name     string          `json:"name"`
+	Required bool            `json:"required"`
+	Type     json.RawMessage `json:"type"`
+}
+
+// typeName returns the field's type, nested types are JSON objects so we'll return their type instead (e.g. struct, list or map).
+func (f field) typeName() string {
+	var primitive string
+	if err := json.Unmarshal(f.Type, &primitive); err == nil {
+		return primitive
+	}
+
+	var nested struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(f.Type, &nested); err == nil {
+		return nested.Type
+	}
+
+	return ""
+}
+
+type schema struct {
+	Type               string  `json:"type"`
+	SchemaID           int     `json:"schema-id"`
+	IdentifierFieldIDs []int   `json:"identifier-field-ids,omitempty"`
+	Fields             []field `json:"fields"`
+}
+
+// findField will look up the field by name, Iceberg is case-sensitive but catalogs may lowercase the names.
+func (s schema) findField(name string) (field, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+
+	for _, f := range s.Fields {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+
+	return field{}, false
+}
+
+type partitionSpec struct {
+	SpecID int               `json:"spec-id"`
+	Fields []json.RawMessage `json:"fields"`
+}
+
+type snapshotRef struct {
+	SnapshotID int64  `json:"snapshot-id"`
+	Type       string `json:"type"`
+}
+
+type snapshot struct {
+	SnapshotID       int64             `json:"snapshot-id"`
+	ParentSnapshotID *int64            `json:"parent-snapshot-id,omitempty"`
+	SequenceNumber   int64             `json:"sequence-number"`
+	TimestampMs      int64             `json:"timestamp-ms"`
+	ManifestList     string            `json:"manifest-list"`
+	Summary          map[string]string `json:"summary"`
+	SchemaID         *int              `json:"schema-id,omitempty"`
+}
+
+// tableMetadata contains the parts of the table metadata that we need, spec: https://iceberg.apache.org/spec/#table-metadata-fields
+type tableMetadata struct {
+	FormatVersion      int                    `json:"format-version"`
+	TableUUID          string                 `json:"table-uuid"`
+	Location           string                 `json:"location"`
+	LastSequenceNumber int64                  `json:"last-sequence-number"`
+	LastColumnID       int                    `json:"last-column-id"`
+	CurrentSchemaID    int                    `json:"current-schema-id"`
+	Schemas            []schema               `json:"schemas"`
+	DefaultSpecID      int                    `json:"default-spec-id"`
+	PartitionSpecs     []partitionSpec        `json:"partition-specs"`
+	CurrentSnapshotID  *int64                 `json:"current-snapshot-id,omitempty"`
+	Snapshots          []snapshot             `json:"snapshots,omitempty"`
+	Refs               map[string]snapshotRef `json:"refs,omitempty"`
+}
+
+func (t tableMetadata) currentSchema() (schema, error) {
+	for _, s := range t.Schemas {
+		if s.SchemaID == t.CurrentSchemaID {
+			return s, nil
+		}
+	}
+
+	return schema{}, fmt.Errorf("current schema: %d does not exist", t.CurrentSchemaID)
+}
+
+// currentSnapshot returns the snapshot at the head of the main branch, this will be nil if the table does not have any data yet.
+func (t tableMetadata) currentSnapshot() *snapshot {
+	snapshotID := t.CurrentSnapshotID
+	if ref, isOk := t.Refs[mainBranch]; isOk {
+		snapshotID = &ref.SnapshotID
+	}
+
+	// -1 is used by older catalogs to indicate that there is no current snapshot.
+	if snapshotID == nil || *snapshotID == -1 {
+		return nil
+	}
+
+	for _, s := range t.Snapshots {
+		if s.SnapshotID == *snapshotID {
+			return &s
+		}
+	}
+
+	return nil
+}
+
+// validate checks that we are able to write to this table.
+func (t tableMetadata) validate() error {
+	// Equality deletes were introduced in v2.
+	if t.FormatVersion != 2 {
+		return fmt.Errorf("only iceberg format version 2 is supported, table format version: %d", t.FormatVersion)
+	}
+
+	for _, spec := range t.PartitionSpecs {
+		if spec.SpecID == t.DefaultSpecID && len(spec.Fields) > 0 {
+			return fmt.Errorf("partitioned iceberg tables are not supported")
+		}
+	}
+
+	return nil
+}
+
+func primitiveType(typeName string) json.RawMessage {
+	typeBytes, _ := json.Marshal(typeName)
+	return typeBytes
+}
+
+// newSchema returns the schema for a new table, the primary keys will be the table's identifier fields.
+func newSchema(cols []columns.Column, primaryKeys []string) schema {
+	s := schema{Type: "struct"}
+	for i, col := range cols {
+		isPk := slices.Contains(primaryKeys, col.RawName())
+		f := field{
+			ID:       i + 1,
+			Name:     col.RawName(),
+			Required: isPk,
+			Type:     primitiveType(typing.KindToDWHType(col.KindDetails, constants.Iceberg, isPk)),
+		}
+
+		if isPk {
+			s.IdentifierFieldIDs = append(s.IdentifierFieldIDs, f.ID)
+		}
+
+		s.Fields = append(s.Fields, f)
+	}
+
+	return s
+}
+
+// addColumns returns a new schema that includes the columns that are missing from the current schema and the table's new last column ID.
+// It'll return false if there are no columns to add.
+func addColumns(metadata tableMetadata, cols []columns.Column) (schema, int, bool, error) {
+	current, err := metadata.currentSchema()
+	if err != nil {
+		return schema{}, 0, false, err
+	}
+
+	newSchemaID := 0
+	for _, s := range metadata.Schemas {
+		newSchemaID = max(newSchemaID, s.SchemaID+1)
+	}
+
+	updated := schema{
+		Type:               "struct",
+		SchemaID:           newSchemaID,
+		IdentifierFieldIDs: current.IdentifierFieldIDs,
+		Fields:             slices.Clone(current.Fields),
+	}
+
+	lastColumnID := metadata.LastColumnID
+	for _, col := range cols {
+		if _, isOk := current.findField(col.RawName()); isOk {
+			continue
+		}
+
+		lastColumnID++
+		updated.Fields = append(updated.Fields, field{
+			ID:   lastColumnID,
+			Name: col.RawName(),
+			// New columns have to be optional since the existing rows will not have a value.
+			Required: false,
+			Type:     primitiveType(typing.KindToDWHType(col.KindDetails, constants.Iceberg, false)),
+		})
+	}
+
+	return updated, lastColumnID, lastColumnID != metadata.LastColumnID, nil
+}
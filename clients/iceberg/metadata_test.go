@@ -0,0 +1,64 @@
+package iceberg
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/ptr"
+)
+
+func TestField_TypeName(t *testing.T) {
+	assert.Equal(t, "long", field{Type: primitiveType("long")}.typeName())
+	assert.Equal(t, "decimal(10, 2)", field{Type: primitiveType("decimal(10, 2)")}.typeName())
+	assert.Equal(t, "list", field{Type: json.RawMessage(`{"type": "list", "element-id": 5, "element": "string", "element-required": false}`)}.typeName())
+}
+
+func TestSchema_FindField(t *testing.T) {
+	s := schema{Fields: []field{{ID: 1, Name: "ID"}, {ID: 2, Name: "id"}, {ID: 3, Name: "Name"}}}
+	{
+		// Exact matches take precedence.
+		f, isOk := s.findField("id")
+		assert.True(t, isOk)
+		assert.Equal(t, 2, f.ID)
+	}
+	{
+		f, isOk := s.findField("name")
+		assert.True(t, isOk)
+		assert.Equal(t, 3, f.ID)
+	}
+	{
+		_, isOk := s.findField("email")
+		assert.False(t, isOk)
+	}
+}
+
+func TestTableMetadata_CurrentSnapshot(t *testing.T) {
+	metadata := tableMetadata{Snapshots: []snapshot{{SnapshotID: 1}, {SnapshotID: 2}}}
+	assert.Nil(t, metadata.currentSnapshot())
+
+	metadata.CurrentSnapshotID = ptr.ToInt64(-1)
+	assert.Nil(t, metadata.currentSnapshot())
+
+	metadata.CurrentSnapshotID = ptr.ToInt64(1)
+	assert.Equal(t, int64(1), metadata.currentSnapshot().SnapshotID)
+
+	// The main branch takes precedence.
+	metadata.Refs = map[string]snapshotRef{mainBranch: {SnapshotID: 2, Type: "branch"}}
+	assert.Equal(t, int64(2), metadata.currentSnapshot().SnapshotID)
+}
+
+func TestTableMetadata_Validate(t *testing.T) {
+	metadata := tableMetadata{FormatVersion: 1, PartitionSpecs: []partitionSpec{{SpecID: 0}}}
+	assert.ErrorContains(t, metadata.validate(), "only iceberg format version 2 is supported, table format version: 1")
+
+	metadata.FormatVersion = 2
+	assert.NoError(t, metadata.validate())
+
+	metadata.PartitionSpecs = append(metadata.PartitionSpecs, partitionSpec{SpecID: 1, Fields: []json.RawMessage{[]byte(`{"source-id": 1, "field-id": 1000, "name": "id_bucket", "transform": "bucket[16]"}`)}})
+	assert.NoError(t, metadata.validate())
+
+	metadata.DefaultSpecID = 1
+	assert.ErrorContains(t, metadata.validate(), "partitioned iceberg tables are not supported")
+}
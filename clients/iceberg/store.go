@@ -0,0 +1,429 @@
+package iceberg
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+type Store struct {
+	config  config.Config
+	catalog *catalogClient
+	fileIO  fileIO
+}
+
+func (s *Store) Label() constants.DestinationKind {
+	return constants.Iceberg
+}
+
+// Append will add a data file with all the rows in a new snapshot.
+func (s *Store) Append(tableData *optimization.TableData) error {
+	return destination.ClassifyError(s.write(tableData, false), s.IsRetryableError)
+}
+
+// Merge - Iceberg data files are immutable, so we upsert by committing an equality delete file (keyed by the primary keys) along with the new data file.
+// Both files are added in the same snapshot and equality deletes only apply to data files from earlier snapshots, so the new rows will replace the existing ones.
+// Rows are replaced as a whole, so rows that still contain the TOAST unavailable value placeholder are left out instead of overwriting the existing value with it.
+// These rows are returned with a [destination.RejectedRowsError], so they can be routed to the dead letter topic.
+func (s *Store) Merge(tableData *optimization.TableData) error {
+	return destination.ClassifyError(s.write(tableData, true), s.IsRetryableError)
+}
+
+// IsRetryableError returns true if the commit failed because the table was updated since we loaded it.
+func (s *Store) IsRetryableError(err error) bool {
+	return errors.Is(err, errConflict)
+}
+
+// columnsToWrite returns the in-memory columns, the delete marker is only kept for soft deletes or when we're appending.
+func columnsToWrite(tableData *optimization.TableData, upsert bool) []columns.Column {
+	var cols []columns.Column
+	for _, col := range tableData.ReadOnlyInMemoryCols().GetColumns() {
+		if col.KindDetails == typing.Invalid {
+			continue
+		}
+
+		if upsert && !tableData.TopicConfig.SoftDelete && col.RawName() == constants.DeleteColumnMarker {
+			continue
+		}
+
+		cols = append(cols, col)
+	}
+
+	return cols
+}
+
+func primaryKeys(tableData *optimization.TableData) []string {
+	var pks []string
+	for _, pk := range tableData.PrimaryKeys(config.PreserveCasing, nil) {
+		pks = append(pks, pk.RawName())
+	}
+
+	return pks
+}
+
+// findToastPlaceholder returns the first column in `row` that contains the TOAST unavailable value placeholder.
+// The placeholder is only replaced when the previous value for the row is buffered in memory, see [optimization.TableData.InsertRow].
+func findToastPlaceholder(row map[string]any) (string, bool) {
+	for colName, val := range row {
+		switch castedVal := val.(type) {
+		case string:
+			if castedVal == constants.ToastUnavailableValuePlaceholder {
+				return colName, true
+			}
+		case map[string]any:
+			if strings.Contains(fmt.Sprint(castedVal), constants.ToastUnavailableValuePlaceholder) {
+				return colName, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// splitToastRows returns the rows that can be merged and the rows that contain the TOAST unavailable value placeholder, along with the first column that contains it.
+func splitToastRows(rows []map[string]any) ([]map[string]any, []map[string]any, string) {
+	var keep, rejected []map[string]any
+	var toastCol string
+	for _, row := range rows {
+		if colName, isOk := findToastPlaceholder(row); isOk {
+			if toastCol == "" {
+				toastCol = colName
+			}
+
+			rejected = append(rejected, row)
+			continue
+		}
+
+		keep = append(keep, row)
+	}
+
+	return keep, rejected, toastCol
+}
+
+func (s *Store) write(tableData *optimization.TableData, upsert bool) error {
+	if tableData.ShouldSkipUpdate() {
+		return nil
+	}
+
+	rows := tableData.Rows()
+	var rejectedErr error
+	if upsert {
+		var rejected []map[string]any
+		var toastCol string
+		if rows, rejected, toastCol = splitToastRows(rows); len(rejected) > 0 {
+			rejectedErr = destination.NewRejectedRowsError(rejected,
+				fmt.Errorf("column: %q contains an unavailable TOAST value, Iceberg merges replace the whole row so this would overwrite the existing value", toastCol))
+		}
+	}
+
+	if err := s.writeRows(tableData, rows, upsert); err != nil {
+		return err
+	}
+
+	return rejectedErr
+}
+
+func (s *Store) writeRows(tableData *optimization.TableData, rows []map[string]any, upsert bool) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	tableName := tableData.RawName()
+	cols := columnsToWrite(tableData, upsert)
+	pks := primaryKeys(tableData)
+	table, err := s.loadOrCreateTable(tableName, cols, pks)
+	if err != nil {
+		return err
+	}
+
+	if err = table.Metadata.validate(); err != nil {
+		return err
+	}
+
+	table, err = s.evolveSchema(tableName, table, cols)
+	if err != nil {
+		return err
+	}
+
+	tableSchema, err := table.Metadata.currentSchema()
+	if err != nil {
+		return err
+	}
+
+	var dataCols, pkCols []dataColumn
+	for _, col := range cols {
+		f, isOk := tableSchema.findField(col.RawName())
+		if !isOk {
+			return fmt.Errorf("column: %q does not exist in the table schema", col.RawName())
+		}
+
+		dataCols = append(dataCols, dataColumn{column: col, field: f})
+		if slices.Contains(pks, col.RawName()) {
+			pkCols = append(pkCols, dataColumn{column: col, field: f})
+		}
+	}
+
+	var dataRows []map[string]any
+	for _, row := range rows {
+		if isDelete, _ := row[constants.DeleteColumnMarker].(bool); isDelete && upsert && !tableData.TopicConfig.SoftDelete {
+			continue
+		}
+
+		dataRows = append(dataRows, row)
+	}
+
+	var files []dataFile
+	if len(dataRows) > 0 {
+		file, err := s.writeFile(table.Metadata.Location, dataCols, dataRows, fileContentData)
+		if err != nil {
+			return fmt.Errorf("failed to write data file: %w", err)
+		}
+
+		files = append(files, file)
+	}
+
+	// There's nothing to delete if the table does not have any data yet.
+	if upsert && table.Metadata.currentSnapshot() != nil {
+		if len(pkCols) != len(pks) {
+			return fmt.Errorf("primary keys: %v are not all in the table schema", pks)
+		}
+
+		file, err := s.writeFile(table.Metadata.Location, pkCols, rows, fileContentEqualityDelete)
+		if err != nil {
+			return fmt.Errorf("failed to write equality delete file: %w", err)
+		}
+
+		files = append(files, file)
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+
+	return s.commitSnapshot(tableName, table.Metadata, tableSchema, files)
+}
+
+func (s *Store) loadOrCreateTable(tableName string, cols []columns.Column, pks []string) (*loadTableResponse, error) {
+	table, err := s.catalog.loadTable(tableName)
+	if err == nil {
+		return table, nil
+	}
+
+	if !errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("failed to load table: %w", err)
+	}
+
+	slog.Info("Iceberg table does not exist, creating it", slog.String("table", tableName))
+	if err = s.catalog.ensureNamespace(); err != nil {
+		return nil, err
+	}
+
+	return s.catalog.createTable(tableName, newSchema(cols, pks))
+}
+
+// evolveSchema will add any columns that are missing from the table's current schema.
+func (s *Store) evolveSchema(tableName string, table *loadTableResponse, cols []columns.Column) (*loadTableResponse, error) {
+	updated, lastColumnID, changed, err := addColumns(table.Metadata, cols)
+	if err != nil || !changed {
+		return table, err
+	}
+
+	slog.Info("Adding columns to the iceberg table", slog.String("table", tableName), slog.Int("schemaID", updated.SchemaID))
+	return s.catalog.commitTable(tableName, commitTableRequest{
+		Requirements: []map[string]any{
+			{"type": "assert-table-uuid", "uuid": table.Metadata.TableUUID},
+			{"type": "assert-current-schema-id", "current-schema-id": table.Metadata.CurrentSchemaID},
+		},
+		Updates: []map[string]any{
+			{"action": "add-schema", "schema": updated, "last-column-id": lastColumnID},
+			// -1 is the last added schema.
+			{"action": "set-current-schema", "schema-id": -1},
+		},
+	})
+}
+
+// writeFile will write the rows into a Parquet file and upload it to the table's data directory.
+func (s *Store) writeFile(location string, cols []dataColumn, rows []map[string]any, content fileContent) (dataFile, error) {
+	fp := tempFilePath(fmt.Sprintf("%s.parquet", uuid.NewString()))
+	defer func() {
+		// Delete the file regardless of outcome to avoid fs build up.
+		if removeErr := os.RemoveAll(fp); removeErr != nil {
+			slog.Warn("Failed to delete temp file", slog.Any("err", removeErr), slog.String("filePath", fp))
+		}
+	}()
+
+	recordCount, err := writeParquetFile(fp, cols, rows, s.config.SharedTransferConfig.TypingSettings.AdditionalDateFormats)
+	if err != nil {
+		return dataFile{}, err
+	}
+
+	size, err := fileSize(fp)
+	if err != nil {
+		return dataFile{}, err
+	}
+
+	path, err := s.fileIO.upload(fp, strings.TrimSuffix(location, "/")+"/data")
+	if err != nil {
+		return dataFile{}, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	file := dataFile{content: content, path: path, recordCount: recordCount, sizeInBytes: size}
+	if content == fileContentEqualityDelete {
+		for _, col := range cols {
+			file.equalityIDs = append(file.equalityIDs, col.field.ID)
+		}
+	}
+
+	return file, nil
+}
+
+// uploadMetadataFile will upload the local file to the table's metadata directory and then delete it.
+func (s *Store) uploadMetadataFile(location, fp string) (string, int64, error) {
+	defer func() {
+		if removeErr := os.RemoveAll(fp); removeErr != nil {
+			slog.Warn("Failed to delete temp file", slog.Any("err", removeErr), slog.String("filePath", fp))
+		}
+	}()
+
+	size, err := fileSize(fp)
+	if err != nil {
+		return "", 0, err
+	}
+
+	path, err := s.fileIO.upload(fp, strings.TrimSuffix(location, "/")+"/metadata")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	return path, size, nil
+}
+
+// commitSnapshot will write the manifests and the manifest list for `files` and commit a new snapshot to the main branch.
+// If the commit fails, the files that were uploaded will not be referenced by the table and can be cleaned up by the table's orphan file removal.
+func (s *Store) commitSnapshot(tableName string, metadata tableMetadata, tableSchema schema, files []dataFile) error {
+	snapshotID := rand.Int63()
+	sequenceNumber := metadata.LastSequenceNumber + 1
+	parent := metadata.currentSnapshot()
+
+	var entries []map[string]any
+	var parentSnapshotID *int64
+	if parent != nil {
+		parentSnapshotID = ptr.ToInt64(parent.SnapshotID)
+		manifestListBytes, err := s.fileIO.read(parent.ManifestList)
+		if err != nil {
+			return fmt.Errorf("failed to read manifest list: %w", err)
+		}
+
+		if entries, err = readManifestList(manifestListBytes); err != nil {
+			return err
+		}
+	}
+
+	summary := map[string]string{"operation": "append"}
+	for _, content := range []manifestContent{manifestContentData, manifestContentDeletes} {
+		var manifestFiles []dataFile
+		for _, file := range files {
+			if (file.content == fileContentData) == (content == manifestContentData) {
+				manifestFiles = append(manifestFiles, file)
+			}
+		}
+
+		if len(manifestFiles) == 0 {
+			continue
+		}
+
+		fp := tempFilePath(fmt.Sprintf("%s-m0.avro", uuid.NewString()))
+		if err := writeManifest(fp, tableSchema, metadata.DefaultSpecID, snapshotID, content, manifestFiles); err != nil {
+			return fmt.Errorf("failed to write manifest: %w", err)
+		}
+
+		path, length, err := s.uploadMetadataFile(metadata.Location, fp)
+		if err != nil {
+			return err
+		}
+
+		m := manifest{path: path, length: length, content: content, files: manifestFiles}
+		entries = append(entries, manifestListEntry(m, metadata.DefaultSpecID, snapshotID, sequenceNumber))
+		if content == manifestContentDeletes {
+			// Snapshots with deletes are row level changes.
+			summary["operation"] = "overwrite"
+			summary["added-delete-files"] = fmt.Sprint(len(manifestFiles))
+		} else {
+			summary["added-data-files"] = fmt.Sprint(len(manifestFiles))
+		}
+	}
+
+	fp := tempFilePath(fmt.Sprintf("snap-%d-1-%s.avro", snapshotID, uuid.NewString()))
+	if err := writeManifestList(fp, snapshotID, parentSnapshotID, sequenceNumber, entries); err != nil {
+		return fmt.Errorf("failed to write manifest list: %w", err)
+	}
+
+	manifestListPath, _, err := s.uploadMetadataFile(metadata.Location, fp)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.catalog.commitTable(tableName, commitTableRequest{
+		Requirements: []map[string]any{
+			{"type": "assert-table-uuid", "uuid": metadata.TableUUID},
+			{"type": "assert-ref-snapshot-id", "ref": mainBranch, "snapshot-id": parentSnapshotID},
+		},
+		Updates: []map[string]any{
+			{
+				"action": "add-snapshot",
+				"snapshot": snapshot{
+					SnapshotID:       snapshotID,
+					ParentSnapshotID: parentSnapshotID,
+					SequenceNumber:   sequenceNumber,
+					TimestampMs:      time.Now().UnixMilli(),
+					ManifestList:     manifestListPath,
+					Summary:          summary,
+					SchemaID:         ptr.ToInt(tableSchema.SchemaID),
+				},
+			},
+			{"action": "set-snapshot-ref", "ref-name": mainBranch, "type": "branch", "snapshot-id": snapshotID},
+		},
+	})
+	return err
+}
+
+func LoadStore(cfg config.Config) (*Store, error) {
+	if err := cfg.ValidateIceberg(); err != nil {
+		return nil, err
+	}
+
+	catalog, err := newCatalogClient(*cfg.Iceberg)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &Store{
+		config:  cfg,
+		catalog: catalog,
+		fileIO:  s3FileIO{},
+	}
+
+	if cfg.Iceberg.AwsAccessKeyID != "" {
+		store.fileIO = s3FileIO{
+			awsAccessKeyID:     ptr.ToString(cfg.Iceberg.AwsAccessKeyID),
+			awsSecretAccessKey: ptr.ToString(cfg.Iceberg.AwsSecretAccessKey),
+		}
+	}
+
+	return store, nil
+}
@@ -0,0 +1,385 @@
+package iceberg
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+type fakeFileIO struct {
+	files map[string][]byte
+}
+
+func (f *fakeFileIO) upload(localPath, location string) (string, error) {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return "", err
+	}
+
+	uri := fmt.Sprintf("%s/%s", location, filepath.Base(localPath))
+	f.files[uri] = data
+	return uri, nil
+}
+
+func (f *fakeFileIO) read(uri string) ([]byte, error) {
+	data, isOk := f.files[uri]
+	if !isOk {
+		return nil, fmt.Errorf("file does not exist: %q", uri)
+	}
+
+	return data, nil
+}
+
+// fakeCatalog is a minimal REST catalog that supports the requests and updates that the store makes.
+type fakeCatalog struct {
+	mu       sync.Mutex
+	table    *loadTableResponse
+	requests []commitTableRequest
+	// conflict will fail the next commit with a 409.
+	conflict bool
+}
+
+func (f *fakeCatalog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	writeJSON := func(status int, body any) {
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+
+	switch {
+	case r.URL.Path == "/v1/config":
+		writeJSON(http.StatusOK, map[string]any{"overrides": map[string]string{"prefix": "warehouse"}})
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/warehouse/namespaces":
+		writeJSON(http.StatusOK, map[string]any{})
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/warehouse/namespaces/analytics\x1fartie/tables/orders":
+		if f.table == nil {
+			writeJSON(http.StatusNotFound, map[string]any{"error": map[string]any{"message": "Table does not exist: orders", "type": "NoSuchTableException", "code": 404}})
+			return
+		}
+
+		writeJSON(http.StatusOK, f.table)
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/warehouse/namespaces/analytics\x1fartie/tables":
+		var req createTableRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		req.Schema.SchemaID = 0
+		f.table = &loadTableResponse{
+			Metadata: tableMetadata{
+				FormatVersion:  2,
+				TableUUID:      "uuid",
+				Location:       "s3://bucket/warehouse/analytics/artie/" + req.Name,
+				LastColumnID:   len(req.Schema.Fields),
+				Schemas:        []schema{req.Schema},
+				PartitionSpecs: []partitionSpec{{SpecID: 0}},
+			},
+		}
+		writeJSON(http.StatusOK, f.table)
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/warehouse/namespaces/analytics\x1fartie/tables/orders":
+		var req commitTableRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if f.conflict {
+			f.conflict = false
+			writeJSON(http.StatusConflict, map[string]any{"error": map[string]any{"message": "Requirement failed", "type": "CommitFailedException", "code": 409}})
+			return
+		}
+
+		f.requests = append(f.requests, req)
+		if err := f.apply(req); err != nil {
+			writeJSON(http.StatusBadRequest, map[string]any{"error": map[string]any{"message": err.Error(), "type": "BadRequestException", "code": 400}})
+			return
+		}
+
+		writeJSON(http.StatusOK, f.table)
+	default:
+		writeJSON(http.StatusBadRequest, map[string]any{"error": map[string]any{"message": "unexpected request: " + r.URL.Path}})
+	}
+}
+
+func (f *fakeCatalog) apply(req commitTableRequest) error {
+	// Round trip the updates so they are decoded the same way that a catalog would.
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	var decoded struct {
+		Requirements []struct {
+			Type       string `json:"type"`
+			SnapshotID *int64 `json:"snapshot-id"`
+			SchemaID   *int   `json:"current-schema-id"`
+		} `json:"requirements"`
+		Updates []struct {
+			Action       string   `json:"action"`
+			Schema       schema   `json:"schema"`
+			LastColumnID int      `json:"last-column-id"`
+			Snapshot     snapshot `json:"snapshot"`
+			SnapshotID   int64    `json:"snapshot-id"`
+		} `json:"updates"`
+	}
+
+	if err = json.Unmarshal(reqBytes, &decoded); err != nil {
+		return err
+	}
+
+	metadata := &f.table.Metadata
+	for _, requirement := range decoded.Requirements {
+		switch requirement.Type {
+		case "assert-ref-snapshot-id":
+			current := metadata.currentSnapshot()
+			if (current == nil) != (requirement.SnapshotID == nil) || (current != nil && current.SnapshotID != *requirement.SnapshotID) {
+				return fmt.Errorf("branch main has changed")
+			}
+		case "assert-current-schema-id":
+			if *requirement.SchemaID != metadata.CurrentSchemaID {
+				return fmt.Errorf("schema has changed")
+			}
+		}
+	}
+
+	for _, update := range decoded.Updates {
+		switch update.Action {
+		case "add-schema":
+			metadata.Schemas = append(metadata.Schemas, update.Schema)
+			metadata.LastColumnID = update.LastColumnID
+		case "set-current-schema":
+			metadata.CurrentSchemaID = metadata.Schemas[len(metadata.Schemas)-1].SchemaID
+		case "add-snapshot":
+			metadata.Snapshots = append(metadata.Snapshots, update.Snapshot)
+			metadata.LastSequenceNumber = update.Snapshot.SequenceNumber
+		case "set-snapshot-ref":
+			metadata.Refs = map[string]snapshotRef{mainBranch: {SnapshotID: update.SnapshotID, Type: "branch"}}
+		}
+	}
+
+	return nil
+}
+
+func newTestStore(t *testing.T) (*Store, *fakeCatalog, *fakeFileIO) {
+	catalog := &fakeCatalog{}
+	server := httptest.NewServer(catalog)
+	t.Cleanup(server.Close)
+
+	cfg := config.Iceberg{CatalogType: config.IcebergCatalogREST, CatalogURI: server.URL, Warehouse: "s3://bucket/warehouse", Namespace: "analytics.artie"}
+	client, err := newCatalogClient(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "warehouse", client.prefix)
+
+	fileIO := &fakeFileIO{files: map[string][]byte{}}
+	return &Store{config: config.Config{Output: constants.Iceberg, Iceberg: &cfg}, catalog: client, fileIO: fileIO}, catalog, fileIO
+}
+
+// readManifests returns the manifest list entries and the data files in each of the manifests.
+func readManifests(t *testing.T, fileIO *fakeFileIO, manifestList string) ([]map[string]any, [][]map[string]any) {
+	entries, err := readManifestList(fileIO.files[manifestList])
+	assert.NoError(t, err)
+
+	var files [][]map[string]any
+	for _, entry := range entries {
+		fp := filepath.Join(t.TempDir(), "manifest.avro")
+		assert.NoError(t, os.WriteFile(fp, fileIO.files[entry["manifest_path"].(string)], 0644))
+		_, records := readAvroFile(t, fp)
+
+		var manifestFiles []map[string]any
+		for _, record := range records {
+			manifestFiles = append(manifestFiles, record["data_file"].(map[string]any))
+		}
+
+		files = append(files, manifestFiles)
+	}
+
+	return entries, files
+}
+
+// parquetFieldIDs returns the field IDs of the columns in the Parquet file.
+func parquetFieldIDs(t *testing.T, data []byte) map[string]int32 {
+	fp := filepath.Join(t.TempDir(), "file.parquet")
+	assert.NoError(t, os.WriteFile(fp, data, 0644))
+	fr, err := local.NewLocalFileReader(fp)
+	assert.NoError(t, err)
+	defer fr.Close()
+
+	// Only read the footer, the reader will otherwise rename the columns.
+	pr := &reader.ParquetReader{PFile: fr}
+	assert.NoError(t, pr.ReadFooter())
+
+	fieldIDs := make(map[string]int32)
+	for _, element := range pr.Footer.Schema[1:] {
+		fieldIDs[element.Name] = element.GetFieldID()
+	}
+
+	return fieldIDs
+}
+
+func TestStore_Merge(t *testing.T) {
+	store, catalog, fileIO := newTestStore(t)
+	tc := kafkalib.TopicConfig{Database: "shop", Schema: "public"}
+
+	cols := &columns.Columns{}
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("name", typing.String))
+	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+
+	{
+		// The table does not exist, so it will be created and the rows will be appended.
+		tableData := optimization.NewTableData(cols, config.Replication, []string{"id"}, tc, "orders")
+		tableData.InsertRow("1", map[string]any{"id": 1, "name": "foo", constants.DeleteColumnMarker: false}, false)
+		tableData.InsertRow("2", map[string]any{"id": 2, "name": "bar", constants.DeleteColumnMarker: false}, false)
+		assert.NoError(t, store.Merge(tableData))
+
+		tableSchema, err := catalog.table.Metadata.currentSchema()
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1}, tableSchema.IdentifierFieldIDs)
+		assert.Equal(t, []field{
+			{ID: 1, Name: "id", Required: true, Type: primitiveType("long")},
+			{ID: 2, Name: "name", Type: primitiveType("string")},
+		}, tableSchema.Fields)
+
+		current := catalog.table.Metadata.currentSnapshot()
+		assert.NotNil(t, current)
+		assert.Nil(t, current.ParentSnapshotID)
+		assert.Equal(t, int64(1), current.SequenceNumber)
+		assert.Equal(t, "append", current.Summary["operation"])
+
+		entries, files := readManifests(t, fileIO, current.ManifestList)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, int32(manifestContentData), entries[0]["content"])
+		assert.Len(t, files[0], 1)
+		assert.Equal(t, int32(fileContentData), files[0][0]["content"])
+		assert.Equal(t, int64(2), files[0][0]["record_count"])
+
+		// The Parquet columns need to have the field IDs.
+		assert.Equal(t, map[string]int32{"id": 1, "name": 2}, parquetFieldIDs(t, fileIO.files[files[0][0]["file_path"].(string)]))
+	}
+	{
+		// New columns will be added and existing rows will be replaced through equality deletes.
+		cols.AddColumn(columns.NewColumn("email", typing.String))
+		tableData := optimization.NewTableData(cols, config.Replication, []string{"id"}, tc, "orders")
+		tableData.InsertRow("1", map[string]any{"id": 1, "name": "foo", "email": "foo@example.com", constants.DeleteColumnMarker: false}, false)
+		tableData.InsertRow("2", map[string]any{"id": 2, constants.DeleteColumnMarker: true}, true)
+		parent := catalog.table.Metadata.currentSnapshot()
+		assert.NoError(t, store.Merge(tableData))
+		// The schema is updated in a separate commit before the snapshot is committed.
+		assert.Len(t, catalog.requests, 3)
+		assert.Equal(t, "add-schema", catalog.requests[1].Updates[0]["action"])
+
+		tableSchema, err := catalog.table.Metadata.currentSchema()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, tableSchema.SchemaID)
+		assert.Equal(t, field{ID: 3, Name: "email", Type: primitiveType("string")}, tableSchema.Fields[2])
+
+		current := catalog.table.Metadata.currentSnapshot()
+		assert.Equal(t, parent.SnapshotID, *current.ParentSnapshotID)
+		assert.Equal(t, int64(2), current.SequenceNumber)
+		assert.Equal(t, "overwrite", current.Summary["operation"])
+		assert.Equal(t, 1, *current.SchemaID)
+
+		entries, files := readManifests(t, fileIO, current.ManifestList)
+		assert.Len(t, entries, 3)
+		// The manifest from the first snapshot is carried over.
+		assert.Equal(t, int64(1), entries[0]["sequence_number"])
+		assert.Equal(t, int64(2), entries[1]["sequence_number"])
+		assert.Equal(t, int32(manifestContentData), entries[1]["content"])
+		// The deleted row is not written to the data file.
+		assert.Equal(t, int64(1), files[1][0]["record_count"])
+		assert.Equal(t, map[string]int32{"id": 1, "name": 2, "email": 3}, parquetFieldIDs(t, fileIO.files[files[1][0]["file_path"].(string)]))
+
+		assert.Equal(t, int32(manifestContentDeletes), entries[2]["content"])
+		assert.Equal(t, int32(fileContentEqualityDelete), files[2][0]["content"])
+		assert.Equal(t, int64(2), files[2][0]["record_count"])
+		assert.Equal(t, map[string]any{"array": []any{int32(1)}}, files[2][0]["equality_ids"])
+		assert.Equal(t, map[string]int32{"id": 1}, parquetFieldIDs(t, fileIO.files[files[2][0]["file_path"].(string)]))
+	}
+	{
+		// Rows with an unavailable TOAST value should not be written.
+		requests := len(catalog.requests)
+		tableData := optimization.NewTableData(cols, config.Replication, []string{"id"}, tc, "orders")
+		tableData.InsertRow("1", map[string]any{"id": 1, "name": constants.ToastUnavailableValuePlaceholder, constants.DeleteColumnMarker: false}, false)
+		err := store.Merge(tableData)
+		assert.ErrorContains(t, err, `column: "name" contains an unavailable TOAST value`)
+		assert.True(t, destination.IsPermanent(err))
+		assert.Len(t, catalog.requests, requests)
+
+		var rejectedErr *destination.RejectedRowsError
+		assert.ErrorAs(t, err, &rejectedErr)
+		assert.Len(t, rejectedErr.Rows(), 1)
+	}
+	{
+		// The rest of the batch should still be written.
+		requests := len(catalog.requests)
+		tableData := optimization.NewTableData(cols, config.Replication, []string{"id"}, tc, "orders")
+		tableData.InsertRow("1", map[string]any{"id": 1, "name": constants.ToastUnavailableValuePlaceholder, constants.DeleteColumnMarker: false}, false)
+		tableData.InsertRow("4", map[string]any{"id": 4, "name": "qux", constants.DeleteColumnMarker: false}, false)
+		err := store.Merge(tableData)
+		var rejectedErr *destination.RejectedRowsError
+		assert.ErrorAs(t, err, &rejectedErr)
+		assert.Equal(t, []map[string]any{{"id": 1, "name": constants.ToastUnavailableValuePlaceholder, constants.DeleteColumnMarker: false}}, rejectedErr.Rows())
+		assert.Len(t, catalog.requests, requests+1)
+
+		_, files := readManifests(t, fileIO, catalog.table.Metadata.currentSnapshot().ManifestList)
+		// Only the row without the TOAST value was written and deleted.
+		assert.Equal(t, int64(1), files[len(files)-2][0]["record_count"])
+		assert.Equal(t, int64(1), files[len(files)-1][0]["record_count"])
+	}
+	{
+		// Commit conflicts are retryable.
+		catalog.conflict = true
+		tableData := optimization.NewTableData(cols, config.Replication, []string{"id"}, tc, "orders")
+		tableData.InsertRow("3", map[string]any{"id": 3, "name": "baz", constants.DeleteColumnMarker: false}, false)
+		err := store.Merge(tableData)
+		assert.ErrorContains(t, err, "failed to commit table: conflict: CommitFailedException: Requirement failed")
+		assert.True(t, destination.IsRetryable(err))
+	}
+}
+
+func TestStore_Append(t *testing.T) {
+	store, catalog, fileIO := newTestStore(t)
+	tc := kafkalib.TopicConfig{Database: "shop", Schema: "public"}
+
+	cols := &columns.Columns{}
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+
+	for i := range 2 {
+		tableData := optimization.NewTableData(cols, config.History, []string{"id"}, tc, "orders")
+		tableData.InsertRow("1", map[string]any{"id": 1, constants.DeleteColumnMarker: false}, false)
+		tableData.InsertRow("1", map[string]any{"id": 1, constants.DeleteColumnMarker: true}, true)
+		assert.NoError(t, store.Append(tableData))
+
+		current := catalog.table.Metadata.currentSnapshot()
+		assert.Equal(t, "append", current.Summary["operation"])
+		entries, files := readManifests(t, fileIO, current.ManifestList)
+		assert.Len(t, entries, i+1)
+		for _, manifestFiles := range files {
+			assert.Equal(t, int32(fileContentData), manifestFiles[0]["content"])
+			assert.Equal(t, int64(2), manifestFiles[0]["record_count"])
+		}
+	}
+
+	// Every column is kept when appending, including the delete marker.
+	tableSchema, err := catalog.table.Metadata.currentSchema()
+	assert.NoError(t, err)
+	var names []string
+	for _, f := range tableSchema.Fields {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, "id,__artie_delete", strings.Join(names, ","))
+}
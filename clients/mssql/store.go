@@ -9,6 +9,7 @@ import (
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/db"
+	"github.com/artie-labs/transfer/lib/destination"
 	"github.com/artie-labs/transfer/lib/destination/types"
 	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/optimization"
@@ -39,21 +40,39 @@ func (s *Store) Label() constants.DestinationKind {
 }
 
 func (s *Store) Merge(tableData *optimization.TableData) error {
-	return shared.Merge(s, tableData, s.config, types.MergeOpts{})
+	err := shared.Merge(s, tableData, s.config, types.MergeOpts{})
+	return destination.ClassifyError(err, s.IsRetryableError)
 }
 
 func (s *Store) Append(tableData *optimization.TableData) error {
-	return shared.Append(s, tableData, s.config, types.AppendOpts{
+	err := shared.Append(s, tableData, s.config, types.AppendOpts{
 		TempTableName: s.ToFullyQualifiedName(tableData, true),
 	})
+	return destination.ClassifyError(err, s.IsRetryableError)
 }
 
 func (s *Store) ToFullyQualifiedName(tableData *optimization.TableData, escape bool) string {
-	return tableData.ToFqName(s.Label(), escape, s.config.SharedDestinationConfig.UppercaseEscapedNames, optimization.FqNameOpts{
+	return tableData.ToFqName(s.Label(), escape, s.config.SharedDestinationConfig.GetIdentifierCasing(), optimization.FqNameOpts{
 		MsSQLSchemaOverride: s.Schema(tableData),
 	})
 }
 
+// TemporaryTableName returns the name of the temporary table for `tableData`, this will be in the staging schema if one is configured.
+func (s *Store) TemporaryTableName(tableData *optimization.TableData) string {
+	return tableData.TemporaryTableName(s.Label(), optimization.FqNameOpts{
+		MsSQLSchemaOverride: s.Schema(tableData),
+		StagingSchema:       s.stagingSchema(),
+	})
+}
+
+func (s *Store) stagingSchema() string {
+	if s.config.MSSQL == nil {
+		return ""
+	}
+
+	return s.config.MSSQL.StagingSchema
+}
+
 func (s *Store) Sweep() error {
 	tcs, err := s.config.TopicConfigs()
 	if err != nil {
@@ -64,7 +83,7 @@ func (s *Store) Sweep() error {
 		return sweepQuery(getSchema(dbAndSchemaPair.Schema))
 	}
 
-	return shared.Sweep(s, tcs, queryFunc)
+	return shared.Sweep(s, tcs, s.stagingSchema(), queryFunc)
 }
 
 func (s *Store) Dedupe(fqTableName string) error {
@@ -79,7 +98,7 @@ func (s *Store) GetTableConfig(tableData *optimization.TableData) (*types.DwhTab
 		describeDescriptionCol = "description"
 	)
 
-	query, args := describeTableQuery(s.Schema(tableData), tableData.RawName())
+	query, args := describeTableQuery(s.Schema(tableData), tableData.TruncatedName(s.Label()))
 	return shared.GetTableCfgArgs{
 		Dwh:                s,
 		FqName:             s.ToFullyQualifiedName(tableData, true),
@@ -91,13 +110,15 @@ func (s *Store) GetTableConfig(tableData *optimization.TableData) (*types.DwhTab
 		ColumnDescLabel:    describeDescriptionCol,
 		EmptyCommentValue:  ptr.ToString("<nil>"),
 		DropDeletedColumns: tableData.TopicConfig.DropDeletedColumns,
+
+		CaseInsensitiveColumns: s.config.SharedDestinationConfig.CaseInsensitiveColumns,
 	}.GetTableConfig()
 }
 
 func LoadStore(cfg config.Config) *Store {
 	return &Store{
-		Store:     db.Open("mssql", cfg.MSSQL.DSN()),
-		configMap: &types.DwhToTablesConfigMap{},
+		Store:     db.Open("mssql", cfg.MSSQL.DSN(), cfg.MSSQL.ConnectionPool),
+		configMap: types.NewDwhToTablesConfigMap(cfg.SchemaCachePath),
 		config:    cfg,
 	}
 }
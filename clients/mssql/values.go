@@ -83,8 +83,18 @@ func parseValue(colVal any, colKind columns.Column, additionalDateFmts []string)
 		_, isString := colVal.(string)
 		if isString {
 			// If the value is a string, convert it back into a number
-			return strconv.Atoi(colValString)
+			parsedVal, err := strconv.Atoi(colValString)
+			if err != nil {
+				return nil, err
+			}
+
+			colVal = parsedVal
 		}
+
+		if err := typing.CheckIntegerRange(colKind.KindDetails, colVal); err != nil {
+			return nil, err
+		}
+
 		return colVal, nil
 	case typing.Float.Kind:
 		_, isString := colVal.(string)
@@ -0,0 +1,41 @@
+package redshift
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/artie-labs/transfer/lib/destination"
+)
+
+// schemaErrorCodes are the SQLSTATE codes that mean the data does not fit the destination table.
+var schemaErrorCodes = []pq.ErrorCode{
+	"42703", // undefined_column
+	"42804", // datatype_mismatch
+	"22P02", // invalid_text_representation
+	"22001", // string_data_right_truncation
+	"22003", // numeric_value_out_of_range
+	"22007", // invalid_datetime_format
+	"22008", // datetime_field_overflow
+}
+
+func isSchemaError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && slices.Contains(schemaErrorCodes, pqErr.Code) {
+		return true
+	}
+
+	// COPY does not return a specific code, the rows that it rejected are written to `stl_load_errors` instead.
+	return strings.Contains(err.Error(), "Check 'stl_load_errors' system table for details")
+}
+
+// classifyError maps errors returned by Redshift to one of the destination error types.
+func (s *Store) classifyError(err error) error {
+	if err != nil && isSchemaError(err) {
+		return destination.NewSchemaError(err)
+	}
+
+	return destination.ClassifyError(err, s.IsRetryableError)
+}
@@ -0,0 +1,40 @@
+package redshift
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/destination"
+)
+
+func (r *RedshiftTestSuite) TestClassifyError() {
+	{
+		// nil
+		assert.NoError(r.T(), r.store.classifyError(nil))
+	}
+	{
+		// Missing column
+		err := r.store.classifyError(fmt.Errorf("failed to merge: %w", &pq.Error{Code: "42703", Message: `column "foo" of relation "orders" does not exist`}))
+		assert.Equal(r.T(), "schema", destination.ErrorType(err))
+
+		var pqErr *pq.Error
+		assert.ErrorAs(r.T(), err, &pqErr)
+	}
+	{
+		// Type mismatch
+		err := r.store.classifyError(&pq.Error{Code: "22P02", Message: `invalid input syntax for type integer: "abc"`})
+		assert.Equal(r.T(), "schema", destination.ErrorType(err))
+	}
+	{
+		// COPY rejected the rows
+		err := r.store.classifyError(fmt.Errorf("failed to run COPY for temporary table: %w", &pq.Error{Code: "XX000", Message: "Load into table 'orders___artie_abcde' failed.  Check 'stl_load_errors' system table for details."}))
+		assert.Equal(r.T(), "schema", destination.ErrorType(err))
+	}
+	{
+		// Anything else
+		err := r.store.classifyError(&pq.Error{Code: "42P01", Message: `relation "orders" does not exist`})
+		assert.Equal(r.T(), "permanent", destination.ErrorType(err))
+	}
+}
@@ -2,6 +2,7 @@ package redshift
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/artie-labs/transfer/lib/config/constants"
 )
@@ -36,3 +37,43 @@ WHERE
     LOWER(c.table_name) = LOWER($1) AND LOWER(c.table_schema) = LOWER($2);
 `, constants.StrPrecisionCol), []any{args.RawTableName, args.Schema}
 }
+
+// loadErrorsQuery returns the rows that were rejected when loading `s3Uri`.
+// We are filtering by the file name instead of using `pg_last_copy_id()` since the COPY may have run on a different connection.
+// If the staging data was split into multiple files, `s3Uri` is their common prefix and will end with a slash.
+func loadErrorsQuery(s3Uri string) (string, []any) {
+	if strings.HasSuffix(s3Uri, "/") {
+		return `SELECT line_number, TRIM(colname), TRIM(err_reason), TRIM(raw_line) FROM stl_load_errors WHERE TRIM(filename) LIKE $1 ORDER BY line_number;`, []any{s3Uri + "%"}
+	}
+
+	return `SELECT line_number, TRIM(colname), TRIM(err_reason), TRIM(raw_line) FROM stl_load_errors WHERE TRIM(filename) = $1 ORDER BY line_number;`, []any{s3Uri}
+}
+
+type dedupeArgs struct {
+	FqTableName      string
+	StagingTableName string
+	// PrimaryKeys and OrderColumns are expected to be escaped already.
+	PrimaryKeys []string
+	// OrderColumns is used to pick the latest row for each primary key, rows are ordered by these columns in descending order.
+	OrderColumns []string
+}
+
+// dedupeQueries - Redshift does not support deleting duplicates in place, so we will copy the latest row for each primary key into a staging table
+// and then swap the contents of the target table with the staging table.
+func dedupeQueries(args dedupeArgs) []string {
+	var orderBy []string
+	for _, col := range args.OrderColumns {
+		orderBy = append(orderBy, fmt.Sprintf("%s DESC", col))
+	}
+
+	const rowNumberCol = constants.ArtiePrefix + "_row_number"
+	return []string{
+		fmt.Sprintf(`CREATE TEMP TABLE %s AS (SELECT * FROM (SELECT *, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) AS %s FROM %s) WHERE %s = 1);`,
+			args.StagingTableName, strings.Join(args.PrimaryKeys, ", "), strings.Join(orderBy, ", "), rowNumberCol, args.FqTableName, rowNumberCol),
+		fmt.Sprintf(`ALTER TABLE %s DROP COLUMN %s;`, args.StagingTableName, rowNumberCol),
+		// We are using DELETE instead of TRUNCATE because TRUNCATE will implicitly commit the transaction.
+		fmt.Sprintf(`DELETE FROM %s;`, args.FqTableName),
+		fmt.Sprintf(`INSERT INTO %s SELECT * FROM %s;`, args.FqTableName, args.StagingTableName),
+		fmt.Sprintf(`DROP TABLE %s;`, args.StagingTableName),
+	}
+}
@@ -0,0 +1,52 @@
+package redshift
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupeQueries(t *testing.T) {
+	{
+		// Single primary key
+		queries := dedupeQueries(dedupeArgs{
+			FqTableName:      "public.customers",
+			StagingTableName: "__artie_dedupe_abcde",
+			PrimaryKeys:      []string{"id"},
+			OrderColumns:     []string{"__artie_db_updated_at"},
+		})
+
+		assert.Equal(t, []string{
+			"CREATE TEMP TABLE __artie_dedupe_abcde AS (SELECT * FROM (SELECT *, ROW_NUMBER() OVER (PARTITION BY id ORDER BY __artie_db_updated_at DESC) AS __artie_row_number FROM public.customers) WHERE __artie_row_number = 1);",
+			"ALTER TABLE __artie_dedupe_abcde DROP COLUMN __artie_row_number;",
+			"DELETE FROM public.customers;",
+			"INSERT INTO public.customers SELECT * FROM __artie_dedupe_abcde;",
+			"DROP TABLE __artie_dedupe_abcde;",
+		}, queries)
+	}
+	{
+		// Composite primary keys
+		queries := dedupeQueries(dedupeArgs{
+			FqTableName:      "public.orders",
+			StagingTableName: "__artie_dedupe_abcde",
+			PrimaryKeys:      []string{"id", `"group"`},
+			OrderColumns:     []string{"__artie_db_updated_at", "__artie_updated_at"},
+		})
+
+		assert.Len(t, queries, 5)
+		assert.Equal(t, `CREATE TEMP TABLE __artie_dedupe_abcde AS (SELECT * FROM (SELECT *, ROW_NUMBER() OVER (PARTITION BY id, "group" ORDER BY __artie_db_updated_at DESC, __artie_updated_at DESC) AS __artie_row_number FROM public.orders) WHERE __artie_row_number = 1);`, queries[0])
+		assert.Equal(t, "DELETE FROM public.orders;", queries[2])
+		assert.Equal(t, "INSERT INTO public.orders SELECT * FROM __artie_dedupe_abcde;", queries[3])
+	}
+}
+
+func TestLoadErrorsQuery(t *testing.T) {
+	query, args := loadErrorsQuery("s3://bucket/file.csv.gz")
+	assert.Equal(t, `SELECT line_number, TRIM(colname), TRIM(err_reason), TRIM(raw_line) FROM stl_load_errors WHERE TRIM(filename) = $1 ORDER BY line_number;`, query)
+	assert.Equal(t, []any{"s3://bucket/file.csv.gz"}, args)
+
+	// Staging data that was split into multiple files
+	query, args = loadErrorsQuery("s3://bucket/prefix/table/")
+	assert.Equal(t, `SELECT line_number, TRIM(colname), TRIM(err_reason), TRIM(raw_line) FROM stl_load_errors WHERE TRIM(filename) LIKE $1 ORDER BY line_number;`, query)
+	assert.Equal(t, []any{"s3://bucket/prefix/table/%"}, args)
+}
@@ -2,6 +2,8 @@ package redshift
 
 import (
 	"fmt"
+	"log/slog"
+	"strings"
 
 	_ "github.com/lib/pq"
 
@@ -13,6 +15,8 @@ import (
 	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/optimization"
 	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/stringutil"
 )
 
 type Store struct {
@@ -21,13 +25,27 @@ type Store struct {
 	optionalS3Prefix  string
 	configMap         *types.DwhToTablesConfigMap
 	skipLgCols        bool
+	compressStaging   bool
+	kmsKeyARN         string
+	maxCopyErrors     int
+	stagingFileCount  int
+	autoWidenVarchar  bool
+	spectrum          *config.RedshiftSpectrum
+	stagingSchema     string
 	config            config.Config
 
 	db.Store
 }
 
 func (s *Store) ToFullyQualifiedName(tableData *optimization.TableData, escape bool) string {
-	return tableData.ToFqName(s.Label(), escape, s.config.SharedDestinationConfig.UppercaseEscapedNames, optimization.FqNameOpts{})
+	return tableData.ToFqName(s.Label(), escape, s.config.SharedDestinationConfig.GetIdentifierCasing(), optimization.FqNameOpts{})
+}
+
+// TemporaryTableName returns the name of the temporary table for `tableData`, this will be in the staging schema if one is configured.
+func (s *Store) TemporaryTableName(tableData *optimization.TableData) string {
+	return tableData.TemporaryTableName(s.Label(), optimization.FqNameOpts{
+		StagingSchema: s.stagingSchema,
+	})
 }
 
 func (s *Store) GetConfigMap() *types.DwhToTablesConfigMap {
@@ -50,7 +68,7 @@ func (s *Store) GetTableConfig(tableData *optimization.TableData) (*types.DwhTab
 	)
 
 	query, args := describeTableQuery(describeArgs{
-		RawTableName: tableData.RawName(),
+		RawTableName: tableData.TruncatedName(s.Label()),
 		Schema:       tableData.TopicConfig.Schema,
 	})
 
@@ -65,6 +83,8 @@ func (s *Store) GetTableConfig(tableData *optimization.TableData) (*types.DwhTab
 		ColumnDescLabel:    describeDescriptionCol,
 		EmptyCommentValue:  ptr.ToString("<nil>"),
 		DropDeletedColumns: tableData.TopicConfig.DropDeletedColumns,
+
+		CaseInsensitiveColumns: s.config.SharedDestinationConfig.CaseInsensitiveColumns,
 	}.GetTableConfig()
 }
 
@@ -87,37 +107,123 @@ WHERE
     n.nspname = $1 AND c.relname ILIKE $2 AND c.relkind = 'r';`, []any{dbAndSchemaPair.Schema, "%" + constants.ArtiePrefix + "%"}
 	}
 
-	return shared.Sweep(s, tcs, queryFunc)
+	return shared.Sweep(s, tcs, s.stagingSchema, queryFunc)
 }
 
 func (s *Store) Dedupe(fqTableName string) error {
-	return fmt.Errorf("dedupe is not yet implemented")
+	tableConfig := s.configMap.TableConfig(fqTableName)
+	if tableConfig == nil {
+		return fmt.Errorf("table config does not exist for table: %s", fqTableName)
+	}
+
+	casing := s.config.SharedDestinationConfig.GetIdentifierCasing()
+	nameArgs := &sql.NameArgs{Escape: true, DestKind: s.Label()}
+	var primaryKeys []string
+	for _, col := range tableConfig.Columns().GetColumns() {
+		if col.PrimaryKey() {
+			primaryKeys = append(primaryKeys, col.Name(casing, nameArgs))
+		}
+	}
+
+	if len(primaryKeys) == 0 {
+		return fmt.Errorf("cannot dedupe table: %s, no primary keys found", fqTableName)
+	}
+
+	// Prefer the database's timestamp over Artie's, since that reflects the CDC position.
+	var orderColumns []string
+	for _, colName := range []string{constants.DatabaseUpdatedColumnMarker, constants.UpdateColumnMarker} {
+		if col, isOk := tableConfig.Columns().GetColumn(colName); isOk {
+			orderColumns = append(orderColumns, col.Name(casing, nameArgs))
+		}
+	}
+
+	// Without a column to order by, the row that we keep for each primary key would be arbitrary.
+	if len(orderColumns) == 0 {
+		return fmt.Errorf("cannot dedupe table: %s, it does not have %s or %s to pick the latest row", fqTableName, constants.DatabaseUpdatedColumnMarker, constants.UpdateColumnMarker)
+	}
+
+	tx, err := s.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start a transaction: %w", err)
+	}
+
+	queries := dedupeQueries(dedupeArgs{
+		FqTableName:      fqTableName,
+		StagingTableName: fmt.Sprintf("%s_dedupe_%s", constants.ArtiePrefix, strings.ToLower(stringutil.Random(5))),
+		PrimaryKeys:      primaryKeys,
+		OrderColumns:     orderColumns,
+	})
+
+	for _, query := range queries {
+		if _, err = tx.Exec(query); err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				slog.Warn("Failed to rollback transaction", slog.Any("err", rollbackErr))
+			}
+
+			return fmt.Errorf("failed to dedupe table: %s, query: %q: %w", fqTableName, query, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func connectionString(cfg config.Redshift) string {
+	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=require",
+		cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.Database)
+
+	// The Postgres driver will load these files when it connects. If a CA bundle is provided, `sslmode=require` will also verify the server's certificate against it.
+	for _, param := range [][2]string{
+		{"sslrootcert", cfg.CABundlePath},
+		{"sslcert", cfg.ClientCertPath},
+		{"sslkey", cfg.ClientKeyPath},
+	} {
+		if param[1] != "" {
+			connStr += fmt.Sprintf(" %s=%s", param[0], quoteConnValue(param[1]))
+		}
+	}
+
+	return connStr
+}
+
+// quoteConnValue will quote `value` so that it can be used within a connection string, this is needed for file paths that contain spaces.
+func quoteConnValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
 }
 
 func LoadRedshift(cfg config.Config, _store *db.Store) *Store {
 	if _store != nil {
 		// Used for tests.
 		return &Store{
-			configMap:  &types.DwhToTablesConfigMap{},
-			skipLgCols: cfg.Redshift.SkipLgCols,
-			config:     cfg,
+			configMap:        types.NewDwhToTablesConfigMap(cfg.SchemaCachePath),
+			skipLgCols:       cfg.Redshift.SkipLgCols,
+			compressStaging:  cfg.Redshift.ShouldCompressStaging(),
+			kmsKeyARN:        cfg.Redshift.KMSKeyARN,
+			maxCopyErrors:    cfg.Redshift.MaxCopyErrors,
+			stagingFileCount: cfg.Redshift.StagingFileCount,
+			autoWidenVarchar: cfg.Redshift.AutoWidenVarchar,
+			spectrum:         cfg.Redshift.Spectrum,
+			stagingSchema:    cfg.Redshift.StagingSchema,
+			config:           cfg,
 
 			Store: *_store,
 		}
 	}
 
-	connStr := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=require",
-		cfg.Redshift.Host, cfg.Redshift.Port, cfg.Redshift.Username,
-		cfg.Redshift.Password, cfg.Redshift.Database)
-
 	return &Store{
 		credentialsClause: cfg.Redshift.CredentialsClause,
 		bucket:            cfg.Redshift.Bucket,
 		optionalS3Prefix:  cfg.Redshift.OptionalS3Prefix,
 		skipLgCols:        cfg.Redshift.SkipLgCols,
-		configMap:         &types.DwhToTablesConfigMap{},
+		compressStaging:   cfg.Redshift.ShouldCompressStaging(),
+		kmsKeyARN:         cfg.Redshift.KMSKeyARN,
+		maxCopyErrors:     cfg.Redshift.MaxCopyErrors,
+		stagingFileCount:  cfg.Redshift.StagingFileCount,
+		autoWidenVarchar:  cfg.Redshift.AutoWidenVarchar,
+		spectrum:          cfg.Redshift.Spectrum,
+		stagingSchema:     cfg.Redshift.StagingSchema,
+		configMap:         types.NewDwhToTablesConfigMap(cfg.SchemaCachePath),
 		config:            cfg,
 
-		Store: db.Open("postgres", connStr),
+		Store: db.Open("postgres", connectionString(*cfg.Redshift), cfg.Redshift.ConnectionPool),
 	}
 }
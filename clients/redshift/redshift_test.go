@@ -0,0 +1,121 @@
+package redshift
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/mocks/fakedriver"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func (r *RedshiftTestSuite) TestDedupe() {
+	{
+		// Table config does not exist
+		assert.ErrorContains(r.T(), r.store.Dedupe("public.does_not_exist"), "table config does not exist for table: public.does_not_exist")
+	}
+	{
+		// No primary keys
+		var cols columns.Columns
+		cols.AddColumn(columns.NewColumn("id", typing.Integer))
+		cols.AddColumn(columns.NewColumn(constants.DatabaseUpdatedColumnMarker, typing.String))
+		r.store.configMap.AddTableToConfig("public.no_pks", types.NewDwhTableConfig(&cols, nil, false, false))
+		assert.ErrorContains(r.T(), r.store.Dedupe("public.no_pks"), "cannot dedupe table: public.no_pks, no primary keys found")
+	}
+	{
+		// No columns to order by, so we cannot tell which row is the latest.
+		var cols columns.Columns
+		cols.AddColumn(columns.NewColumn("id", typing.Integer))
+		cols.UpsertColumn("id", columns.UpsertColumnArg{PrimaryKey: ptr.ToBool(true)})
+		r.store.configMap.AddTableToConfig("public.no_order", types.NewDwhTableConfig(&cols, nil, false, false))
+		assert.ErrorContains(r.T(), r.store.Dedupe("public.no_order"), "cannot dedupe table: public.no_order, it does not have __artie_db_updated_at or __artie_updated_at to pick the latest row")
+	}
+	{
+		// Composite primary keys
+		var cols columns.Columns
+		for _, colName := range []string{"id", "group", "name", constants.DatabaseUpdatedColumnMarker} {
+			cols.AddColumn(columns.NewColumn(colName, typing.String))
+		}
+
+		cols.UpsertColumn("id", columns.UpsertColumnArg{PrimaryKey: ptr.ToBool(true)})
+		cols.UpsertColumn("group", columns.UpsertColumnArg{PrimaryKey: ptr.ToBool(true)})
+		r.store.configMap.AddTableToConfig(`public."orders"`, types.NewDwhTableConfig(&cols, nil, false, false))
+
+		fakeDriver := &fakedriver.Driver{}
+		db, err := fakeDriver.DB()
+		assert.NoError(r.T(), err)
+		tx, err := db.Begin()
+		assert.NoError(r.T(), err)
+		r.fakeStore.BeginReturns(tx, nil)
+
+		assert.NoError(r.T(), r.store.Dedupe(`public."orders"`))
+		assert.Equal(r.T(), 0, r.fakeStore.QueryCallCount())
+		assert.Len(r.T(), fakeDriver.Execs(), 5)
+		assert.Contains(r.T(), fakeDriver.Execs()[0], `PARTITION BY id, "group" ORDER BY __artie_db_updated_at DESC`)
+		assert.Equal(r.T(), `DELETE FROM public."orders";`, fakeDriver.Execs()[2])
+	}
+}
+
+func (r *RedshiftTestSuite) TestCopyStatement() {
+	{
+		// Compressed (default)
+		store := LoadRedshift(config.Config{Redshift: &config.Redshift{}}, &r.store.Store)
+		store.credentialsClause = "IAM_ROLE 'role'"
+		assert.Equal(r.T(), `COPY public.tableName FROM 's3://bucket/file.csv.gz' DELIMITER '\t' NULL AS '\\N' GZIP FORMAT CSV IAM_ROLE 'role' dateformat 'auto' timeformat 'auto';`, store.copyStatement("public.tableName", "s3://bucket/file.csv.gz"))
+		assert.NotContains(r.T(), store.copyStatement("public.tableName", "s3://bucket/file.csv.gz"), "MAXERROR")
+	}
+	{
+		// Uncompressed
+		store := LoadRedshift(config.Config{Redshift: &config.Redshift{CompressStaging: ptr.ToBool(false)}}, &r.store.Store)
+		store.credentialsClause = "IAM_ROLE 'role'"
+		assert.Equal(r.T(), `COPY public.tableName FROM 's3://bucket/file.csv' DELIMITER '\t' NULL AS '\\N' FORMAT CSV IAM_ROLE 'role' dateformat 'auto' timeformat 'auto';`, store.copyStatement("public.tableName", "s3://bucket/file.csv"))
+	}
+	{
+		// Encrypted with SSE-KMS, Redshift will decrypt this transparently so the COPY statement should not change.
+		store := LoadRedshift(config.Config{Redshift: &config.Redshift{KMSKeyARN: "arn:aws:kms:us-east-1:123456789012:key/abcd"}}, &r.store.Store)
+		store.credentialsClause = "IAM_ROLE 'role'"
+		assert.Equal(r.T(), "arn:aws:kms:us-east-1:123456789012:key/abcd", store.kmsKeyARN)
+		assert.Equal(r.T(), `COPY public.tableName FROM 's3://bucket/file.csv.gz' DELIMITER '\t' NULL AS '\\N' GZIP FORMAT CSV IAM_ROLE 'role' dateformat 'auto' timeformat 'auto';`, store.copyStatement("public.tableName", "s3://bucket/file.csv.gz"))
+	}
+	{
+		// Max copy errors
+		store := LoadRedshift(config.Config{Redshift: &config.Redshift{MaxCopyErrors: 10}}, &r.store.Store)
+		store.credentialsClause = "IAM_ROLE 'role'"
+		assert.Equal(r.T(), `COPY public.tableName FROM 's3://bucket/file.csv.gz' DELIMITER '\t' NULL AS '\\N' GZIP FORMAT CSV IAM_ROLE 'role' MAXERROR 10 dateformat 'auto' timeformat 'auto';`, store.copyStatement("public.tableName", "s3://bucket/file.csv.gz"))
+	}
+}
+
+func (r *RedshiftTestSuite) TestConnectionString() {
+	cfg := config.Redshift{Host: "host", Port: 5439, Username: "user", Password: "pw", Database: "db"}
+	{
+		// No TLS settings
+		assert.Equal(r.T(), "host=host port=5439 user=user password=pw dbname=db sslmode=require", connectionString(cfg))
+	}
+	{
+		// CA bundle and client certificate
+		cfg.DriverTLS = config.DriverTLS{CABundlePath: "/etc/ssl/ca.pem", ClientCertPath: "/etc/ssl/client.pem", ClientKeyPath: "/etc/ssl/private/client's key.pem"}
+		assert.Equal(r.T(), `host=host port=5439 user=user password=pw dbname=db sslmode=require sslrootcert='/etc/ssl/ca.pem' sslcert='/etc/ssl/client.pem' sslkey='/etc/ssl/private/client\'s key.pem'`, connectionString(cfg))
+	}
+}
+
+func (r *RedshiftTestSuite) TestGetTableConfig_TruncatedName() {
+	// Tables are created with the truncated name, so that's what we need to look up.
+	tableData := optimization.NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{Database: "db", Schema: "public"}, strings.Repeat("a", 200))
+	r.fakeStore.QueryReturns(nil, fmt.Errorf("failed to describe table"))
+	_, err := r.store.GetTableConfig(tableData)
+	assert.ErrorContains(r.T(), err, "failed to describe table")
+
+	_, args := r.fakeStore.QueryArgsForCall(r.fakeStore.QueryCallCount() - 1)
+	assert.Len(r.T(), args, 2)
+	assert.Len(r.T(), args[0], constants.RedshiftMaxIdentifierLength)
+	assert.Equal(r.T(), tableData.TruncatedName(constants.Redshift), args[0])
+	assert.Equal(r.T(), "public", args[1])
+}
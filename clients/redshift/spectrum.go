@@ -0,0 +1,277 @@
+package redshift
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/parquetutil"
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/s3lib"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/stringutil"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+const (
+	// spectrumPartitionCol is the partition column that every Spectrum table is created with, it's the date of the latest CDC event in the batch.
+	spectrumPartitionCol = "dt"
+	// spectrumMaxVarchar is the largest VARCHAR that Redshift Spectrum supports.
+	spectrumMaxVarchar = "VARCHAR(65535)"
+)
+
+// spectrumColumns returns the columns that will be written into the Parquet file and declared on the external table.
+func spectrumColumns(tableData *optimization.TableData) []columns.Column {
+	var cols []columns.Column
+	for _, col := range tableData.ReadOnlyInMemoryCols().GetColumns() {
+		if col.KindDetails == typing.Invalid {
+			continue
+		}
+
+		cols = append(cols, col)
+	}
+
+	return cols
+}
+
+func isSpectrumDecimal(kd typing.KindDetails) bool {
+	if kd.ExtendedDecimalDetails == nil {
+		return false
+	}
+
+	precision := kd.ExtendedDecimalDetails.Precision()
+	return precision != nil && *precision != decimal.PrecisionNotSpecified && *precision <= decimal.MaxPrecisionBeforeString
+}
+
+// spectrumParquetField returns the Parquet field for `col`, this differs from the S3 destination in that:
+// 1. Timestamps are annotated as TIMESTAMP_MILLIS, so they can be read as a TIMESTAMP column.
+// 2. Decimals that exceed Spectrum's max precision of 38 are written as strings.
+func spectrumParquetField(col columns.Column) (*typing.Field, error) {
+	colName := col.RawName()
+	kd := col.KindDetails
+	switch kd.Kind {
+	case typing.ETime.Kind:
+		if kd.ExtendedTimeDetails != nil && kd.ExtendedTimeDetails.Type == ext.DateTimeKindType {
+			return &typing.Field{
+				Tag: typing.FieldTag{
+					Name:          colName,
+					InName:        &colName,
+					Type:          ptr.ToString("INT64"),
+					ConvertedType: ptr.ToString("TIMESTAMP_MILLIS"),
+				}.String(),
+			}, nil
+		}
+	case typing.EDecimal.Kind:
+		if !isSpectrumDecimal(kd) {
+			return &typing.Field{
+				Tag: typing.FieldTag{
+					Name:          colName,
+					InName:        &colName,
+					Type:          ptr.ToString("BYTE_ARRAY"),
+					ConvertedType: ptr.ToString("UTF8"),
+				}.String(),
+			}, nil
+		}
+	}
+
+	return kd.ParquetAnnotation(colName)
+}
+
+func spectrumParquetSchema(cols []columns.Column) (string, error) {
+	var fields []typing.Field
+	for _, col := range cols {
+		field, err := spectrumParquetField(col)
+		if err != nil {
+			return "", err
+		}
+
+		if field == nil {
+			return "", fmt.Errorf("unsupported column kind: %q for column: %q", col.KindDetails.Kind, col.RawName())
+		}
+
+		fields = append(fields, *field)
+	}
+
+	schemaBytes, err := json.Marshal(typing.Field{
+		Tag: typing.FieldTag{
+			Name:           "parquet-go-root",
+			RepetitionType: ptr.ToString("REQUIRED"),
+		}.String(),
+		Fields: fields,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(schemaBytes), nil
+}
+
+// kindToSpectrum returns the external table column type, this needs to line up with the Parquet type from `spectrumParquetField`.
+func kindToSpectrum(kd typing.KindDetails) string {
+	switch kd.Kind {
+	case typing.Integer.Kind:
+		return "BIGINT"
+	case typing.Float.Kind:
+		// Parquet FLOAT is 32-bit.
+		return "REAL"
+	case typing.Boolean.Kind:
+		return "BOOLEAN"
+	case typing.Array.Kind:
+		return fmt.Sprintf("ARRAY<%s>", spectrumMaxVarchar)
+	case typing.ETime.Kind:
+		if kd.ExtendedTimeDetails != nil && kd.ExtendedTimeDetails.Type == ext.DateTimeKindType {
+			return "TIMESTAMP"
+		}
+	case typing.EDecimal.Kind:
+		if isSpectrumDecimal(kd) {
+			return fmt.Sprintf("DECIMAL(%d, %d)", *kd.ExtendedDecimalDetails.Precision(), kd.ExtendedDecimalDetails.Scale())
+		}
+	}
+
+	// Strings, structs, dates and times are all written as strings.
+	return spectrumMaxVarchar
+}
+
+func (s *Store) spectrumTableName(tableData *optimization.TableData) string {
+	casing := s.config.SharedDestinationConfig.GetIdentifierCasing()
+	// External tables are stored in the data catalog, which only supports lowercase names.
+	return fmt.Sprintf("%s.%s", s.spectrum.ExternalSchema, strings.ToLower(tableData.Name(casing, &sql.NameArgs{
+		Escape:   true,
+		DestKind: s.Label(),
+	})))
+}
+
+// spectrumTablePrefix returns the S3 prefix for the table, which will look like: optionalS3Prefix/externalSchema/tableName
+func (s *Store) spectrumTablePrefix(tableData *optimization.TableData) string {
+	parts := []string{s.spectrum.ExternalSchema, strings.ToLower(tableData.RawName())}
+	if s.optionalS3Prefix != "" {
+		parts = append([]string{s.optionalS3Prefix}, parts...)
+	}
+
+	return strings.Join(parts, "/")
+}
+
+func (s *Store) spectrumPartition(tableData *optimization.TableData) string {
+	return tableData.LatestCDCTs.Format(ext.PostgresDateFormat)
+}
+
+func (s *Store) createExternalTableQuery(tableData *optimization.TableData, cols []columns.Column) string {
+	casing := s.config.SharedDestinationConfig.GetIdentifierCasing()
+	var colParts []string
+	for _, col := range cols {
+		colParts = append(colParts, fmt.Sprintf("%s %s", col.Name(casing, &sql.NameArgs{Escape: true, DestKind: s.Label()}), kindToSpectrum(col.KindDetails)))
+	}
+
+	return fmt.Sprintf(`CREATE EXTERNAL TABLE IF NOT EXISTS %s (%s) PARTITIONED BY (%s VARCHAR(10)) STORED AS PARQUET LOCATION 's3://%s/%s/';`,
+		s.spectrumTableName(tableData), strings.Join(colParts, ","), spectrumPartitionCol, s.bucket, s.spectrumTablePrefix(tableData))
+}
+
+func (s *Store) addPartitionQuery(tableData *optimization.TableData) string {
+	partition := s.spectrumPartition(tableData)
+	return fmt.Sprintf(`ALTER TABLE %s ADD IF NOT EXISTS PARTITION (%s='%s') LOCATION 's3://%s/%s/%s=%s/';`,
+		s.spectrumTableName(tableData), spectrumPartitionCol, partition, s.bucket, s.spectrumTablePrefix(tableData), spectrumPartitionCol, partition)
+}
+
+// writeSpectrum - will write tableData as a Parquet file into S3 and register it against the external table, in these steps:
+// 1. Create the external table if it does not exist yet.
+// 2. Write the Parquet file and upload it to: s3://bucket/optionalS3Prefix/externalSchema/tableName/dt=YYYY-MM-DD/{{unix_timestamp}}.parquet.gz
+// 3. Add the partition if it does not exist yet.
+// Note: External tables are append-only, so deletes are written as rows (with the soft delete column) and new columns are not added to existing tables.
+func (s *Store) writeSpectrum(tableData *optimization.TableData) error {
+	if tableData.ShouldSkipUpdate() {
+		return nil
+	}
+
+	cols := spectrumColumns(tableData)
+	schema, err := spectrumParquetSchema(cols)
+	if err != nil {
+		return fmt.Errorf("failed to generate parquet schema: %w", err)
+	}
+
+	// Spectrum DDL cannot be run within a transaction block, so these are executed individually.
+	if _, err = s.Exec(s.createExternalTableQuery(tableData, cols)); err != nil {
+		return fmt.Errorf("failed to create external table: %w", err)
+	}
+
+	fp := fmt.Sprintf("/tmp/%v_%s.parquet.gz", tableData.LatestCDCTs.UnixMilli(), stringutil.Random(4))
+	defer func() {
+		// Delete the file regardless of outcome to avoid fs build up.
+		if removeErr := os.RemoveAll(fp); removeErr != nil {
+			slog.Warn("Failed to delete temp file", slog.Any("err", removeErr), slog.String("filePath", fp))
+		}
+	}()
+
+	if err = s.writeParquetFile(tableData, cols, schema, fp); err != nil {
+		return err
+	}
+
+	if _, err = s3lib.UploadLocalFileToS3(context.Background(), s3lib.UploadArgs{
+		Bucket:           s.bucket,
+		OptionalS3Prefix: fmt.Sprintf("%s/%s=%s", s.spectrumTablePrefix(tableData), spectrumPartitionCol, s.spectrumPartition(tableData)),
+		FilePath:         fp,
+	}); err != nil {
+		return fmt.Errorf("failed to upload file to s3: %w", err)
+	}
+
+	if _, err = s.Exec(s.addPartitionQuery(tableData)); err != nil {
+		return fmt.Errorf("failed to add partition: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) writeParquetFile(tableData *optimization.TableData, cols []columns.Column, schema string, fp string) error {
+	fw, err := local.NewLocalFileWriter(fp)
+	if err != nil {
+		return fmt.Errorf("failed to create a local parquet file: %w", err)
+	}
+
+	pw, err := writer.NewJSONWriter(schema, fw, 4)
+	if err != nil {
+		return fmt.Errorf("failed to instantiate parquet writer: %w", err)
+	}
+
+	additionalDateFmts := s.config.SharedTransferConfig.TypingSettings.AdditionalDateFormats
+	pw.CompressionType = parquet.CompressionCodec_GZIP
+	for _, val := range tableData.Rows() {
+		row := make(map[string]any)
+		for _, col := range cols {
+			value, err := parquetutil.ParseValue(val[col.RawName()], col, additionalDateFmts)
+			if err != nil {
+				return fmt.Errorf("failed to parse value, err: %w, value: %v, column: %v", err, val[col.RawName()], col.RawName())
+			}
+
+			row[col.RawName()] = value
+		}
+
+		rowBytes, err := json.Marshal(row)
+		if err != nil {
+			return fmt.Errorf("failed to marshal row: %w", err)
+		}
+
+		if err = pw.Write(string(rowBytes)); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+	}
+
+	if err = pw.WriteStop(); err != nil {
+		return fmt.Errorf("failed to write stop: %w", err)
+	}
+
+	if err = fw.Close(); err != nil {
+		return fmt.Errorf("failed to close filewriter: %w", err)
+	}
+
+	return nil
+}
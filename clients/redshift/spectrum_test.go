@@ -0,0 +1,120 @@
+package redshift
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+func newDecimalKind(precision int, scale int) typing.KindDetails {
+	kd := typing.EDecimal
+	kd.ExtendedDecimalDetails = decimal.NewDecimal(ptr.ToInt(precision), scale, nil)
+	return kd
+}
+
+func TestSpectrumParquetSchema(t *testing.T) {
+	cols := []columns.Column{
+		columns.NewColumn("id", typing.Integer),
+		columns.NewColumn("score", typing.Float),
+		columns.NewColumn("active", typing.Boolean),
+		columns.NewColumn("name", typing.String),
+		columns.NewColumn("payload", typing.Struct),
+		columns.NewColumn("tags", typing.Array),
+		columns.NewColumn("created_at", typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateTimeKindType)),
+		columns.NewColumn("birthday", typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateKindType)),
+		columns.NewColumn("price", newDecimalKind(10, 2)),
+		columns.NewColumn("variable_numeric", newDecimalKind(decimal.PrecisionNotSpecified, decimal.DefaultScale)),
+		columns.NewColumn("large_numeric", newDecimalKind(50, 2)),
+	}
+
+	schema, err := spectrumParquetSchema(cols)
+	assert.NoError(t, err)
+
+	var root typing.Field
+	assert.NoError(t, json.Unmarshal([]byte(schema), &root))
+	assert.Equal(t, "name=parquet-go-root, repetitiontype=REQUIRED", root.Tag)
+
+	expectedTags := []string{
+		"name=id, inname=id, type=INT64, repetitiontype=OPTIONAL",
+		"name=score, inname=score, type=FLOAT, repetitiontype=OPTIONAL",
+		"name=active, inname=active, type=BOOLEAN, repetitiontype=OPTIONAL",
+		"name=name, inname=name, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL",
+		"name=payload, inname=payload, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL",
+		"name=tags, inname=tags, type=LIST, repetitiontype=REQUIRED",
+		"name=created_at, inname=created_at, type=INT64, convertedtype=TIMESTAMP_MILLIS, repetitiontype=OPTIONAL",
+		"name=birthday, inname=birthday, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL",
+		"name=price, inname=price, type=BYTE_ARRAY, convertedtype=DECIMAL, repetitiontype=OPTIONAL, scale=2, precision=10",
+		"name=variable_numeric, inname=variable_numeric, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL",
+		"name=large_numeric, inname=large_numeric, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL",
+	}
+
+	assert.Len(t, root.Fields, len(expectedTags))
+	for idx, expectedTag := range expectedTags {
+		assert.Equal(t, expectedTag, root.Fields[idx].Tag, idx)
+	}
+
+	// Arrays should have a single string element.
+	assert.Len(t, root.Fields[5].Fields, 1)
+	assert.Equal(t, "name=element, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=REQUIRED", root.Fields[5].Fields[0].Tag)
+}
+
+func TestKindToSpectrum(t *testing.T) {
+	testCases := []struct {
+		kd       typing.KindDetails
+		expected string
+	}{
+		{kd: typing.Integer, expected: "BIGINT"},
+		{kd: typing.Float, expected: "REAL"},
+		{kd: typing.Boolean, expected: "BOOLEAN"},
+		{kd: typing.String, expected: "VARCHAR(65535)"},
+		{kd: typing.Struct, expected: "VARCHAR(65535)"},
+		{kd: typing.Array, expected: "ARRAY<VARCHAR(65535)>"},
+		{kd: typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateTimeKindType), expected: "TIMESTAMP"},
+		{kd: typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateKindType), expected: "VARCHAR(65535)"},
+		{kd: typing.NewKindDetailsFromTemplate(typing.ETime, ext.TimeKindType), expected: "VARCHAR(65535)"},
+		{kd: newDecimalKind(10, 2), expected: "DECIMAL(10, 2)"},
+		{kd: newDecimalKind(decimal.PrecisionNotSpecified, decimal.DefaultScale), expected: "VARCHAR(65535)"},
+		{kd: newDecimalKind(50, 2), expected: "VARCHAR(65535)"},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expected, kindToSpectrum(testCase.kd), testCase.kd.Kind)
+	}
+}
+
+func (r *RedshiftTestSuite) TestSpectrumQueries() {
+	r.store.spectrum = &config.RedshiftSpectrum{ExternalSchema: "spectrum"}
+	r.store.bucket = "bucket"
+	r.store.optionalS3Prefix = "prefix"
+
+	var cols columns.Columns
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("created_at", typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateTimeKindType)))
+
+	tableData := optimization.NewTableData(&cols, config.Replication, []string{"id"}, kafkalib.TopicConfig{Database: "db", Schema: "public"}, "Orders")
+	tableData.LatestCDCTs = time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+
+	assert.Equal(r.T(), "spectrum.orders", r.store.spectrumTableName(tableData))
+	assert.Equal(r.T(), "prefix/spectrum/orders", r.store.spectrumTablePrefix(tableData))
+	assert.Equal(r.T(),
+		`CREATE EXTERNAL TABLE IF NOT EXISTS spectrum.orders (id BIGINT,created_at TIMESTAMP) PARTITIONED BY (dt VARCHAR(10)) STORED AS PARQUET LOCATION 's3://bucket/prefix/spectrum/orders/';`,
+		r.store.createExternalTableQuery(tableData, spectrumColumns(tableData)))
+	assert.Equal(r.T(),
+		`ALTER TABLE spectrum.orders ADD IF NOT EXISTS PARTITION (dt='2023-04-05') LOCATION 's3://bucket/prefix/spectrum/orders/dt=2023-04-05/';`,
+		r.store.addPartitionQuery(tableData))
+
+	// Without a prefix
+	r.store.optionalS3Prefix = ""
+	assert.Equal(r.T(), "spectrum/orders", r.store.spectrumTablePrefix(tableData))
+}
@@ -5,8 +5,11 @@ import (
 	"context"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"path"
+	"path/filepath"
 
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/destination/ddl"
@@ -18,91 +21,239 @@ import (
 func (s *Store) PrepareTemporaryTable(tableData *optimization.TableData, tableConfig *types.DwhTableConfig, tempTableName string, _ types.AdditionalSettings, _ bool) error {
 	// Redshift always creates a temporary table.
 	tempAlterTableArgs := ddl.AlterTableArgs{
-		Dwh:               s,
-		Tc:                tableConfig,
-		FqTableName:       tempTableName,
-		CreateTable:       true,
-		TemporaryTable:    true,
-		ColumnOp:          constants.Add,
-		UppercaseEscNames: &s.config.SharedDestinationConfig.UppercaseEscapedNames,
-		Mode:              tableData.Mode(),
+		Dwh:              s,
+		Tc:               tableConfig,
+		FqTableName:      tempTableName,
+		CreateTable:      true,
+		TemporaryTable:   true,
+		ColumnOp:         constants.Add,
+		IdentifierCasing: s.config.SharedDestinationConfig.GetIdentifierCasing(),
+		Mode:             tableData.Mode(),
 	}
 
 	if err := tempAlterTableArgs.AlterTable(tableData.ReadOnlyInMemoryCols().GetColumns()...); err != nil {
 		return fmt.Errorf("failed to create temp table: %w", err)
 	}
 
-	fp, err := s.loadTemporaryTable(tableData, tempTableName)
+	fps, err := s.loadTemporaryTable(tableData, tempTableName)
 	if err != nil {
 		return fmt.Errorf("failed to load temporary table: %w", err)
 	}
 
 	defer func() {
-		// Remove file regardless of outcome to avoid fs build up.
-		if removeErr := os.RemoveAll(fp); removeErr != nil {
-			slog.Warn("Failed to delete temp file", slog.Any("err", removeErr), slog.String("filePath", fp))
+		// Remove files regardless of outcome to avoid fs build up.
+		for _, fp := range fps {
+			if removeErr := os.RemoveAll(fp); removeErr != nil {
+				slog.Warn("Failed to delete temp file", slog.Any("err", removeErr), slog.String("filePath", fp))
+			}
+		}
+
+		if len(fps) > 1 {
+			if removeErr := os.RemoveAll(stagingDir(tempTableName)); removeErr != nil {
+				slog.Warn("Failed to delete temp directory", slog.Any("err", removeErr), slog.String("dir", stagingDir(tempTableName)))
+			}
 		}
 	}()
 
-	// Load fp into s3, get S3 URI and pass it down.
-	s3Uri, err := s3lib.UploadLocalFileToS3(context.Background(), s3lib.UploadArgs{
-		OptionalS3Prefix: s.optionalS3Prefix,
-		Bucket:           s.bucket,
-		FilePath:         fp,
-	})
+	// Load the file(s) into s3, get S3 URI and pass it down.
+	// If the data was split, every file is uploaded under a common prefix and COPY will load all of them in parallel.
+	s3Prefix := s.optionalS3Prefix
+	if len(fps) > 1 {
+		s3Prefix = path.Join(s.optionalS3Prefix, filepath.Base(stagingDir(tempTableName)))
+	}
+
+	var s3Uri string
+	for _, fp := range fps {
+		s3Uri, err = s3lib.UploadLocalFileToS3(context.Background(), s3lib.UploadArgs{
+			OptionalS3Prefix:  s3Prefix,
+			Bucket:            s.bucket,
+			FilePath:          fp,
+			OptionalKMSKeyARN: s.kmsKeyARN,
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to upload %s to s3: %w", fp, err)
+		}
+	}
+
+	if len(fps) > 1 {
+		s3Uri = stagingS3Prefix(s.bucket, s3Prefix)
+	}
+
+	if _, err = s.Exec(s.copyStatement(tempTableName, s3Uri)); err != nil {
+		return fmt.Errorf("failed to run COPY for temporary table: %w", err)
+	}
+
+	if s.maxCopyErrors > 0 {
+		s.logLoadErrors(tempTableName, s3Uri)
+	}
+
+	return nil
+}
 
+// logLoadErrors will log the rows that were rejected by COPY, this is only best effort and will not fail the flush.
+func (s *Store) logLoadErrors(tempTableName string, s3Uri string) {
+	query, args := loadErrorsQuery(s3Uri)
+	rows, err := s.Query(query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to upload %s to s3: %w", fp, err)
+		slog.Warn("Failed to query stl_load_errors", slog.Any("err", err), slog.String("tableName", tempTableName))
+		return
+	}
+
+	if rows == nil {
+		return
+	}
+
+	defer rows.Close()
+
+	var rejectedRows int
+	for rows.Next() {
+		var lineNumber int64
+		var colName, errReason, rawLine string
+		if err = rows.Scan(&lineNumber, &colName, &errReason, &rawLine); err != nil {
+			slog.Warn("Failed to scan stl_load_errors", slog.Any("err", err), slog.String("tableName", tempTableName))
+			return
+		}
+
+		rejectedRows++
+		slog.Warn("Redshift COPY rejected row",
+			slog.String("tableName", tempTableName),
+			slog.Int64("lineNumber", lineNumber),
+			slog.String("column", colName),
+			slog.String("reason", errReason),
+			slog.String("rawLine", rawLine),
+		)
+	}
+
+	if err = rows.Err(); err != nil {
+		slog.Warn("Failed to iterate over stl_load_errors", slog.Any("err", err), slog.String("tableName", tempTableName))
+	}
+
+	if rejectedRows > 0 {
+		slog.Warn("Skipped rows that Redshift COPY rejected", slog.String("tableName", tempTableName), slog.Int("rejectedRows", rejectedRows), slog.Int("maxCopyErrors", s.maxCopyErrors))
+	}
+}
+
+// copyStatement returns the COPY command to load the staging file at `s3Uri` into `tempTableName`.
+// Files that are encrypted with SSE-KMS are decrypted transparently by Redshift, so we don't need the `ENCRYPTED` clause (that's only for client-side encryption).
+func (s *Store) copyStatement(tempTableName string, s3Uri string) string {
+	var compressionClause string
+	if s.compressStaging {
+		compressionClause = "GZIP "
 	}
 
 	// COPY table_name FROM '/path/to/local/file' DELIMITER '\t' NULL '\\N' FORMAT csv;
 	// Note, we need to specify `\\N` here and in `CastColVal(..)` we are only doing `\N`, this is because Redshift treats backslashes as an escape character.
 	// So, it'll convert `\N` => `\\N` during COPY.
-	copyStmt := fmt.Sprintf(`COPY %s FROM '%s' DELIMITER '\t' NULL AS '\\N' GZIP FORMAT CSV %s dateformat 'auto' timeformat 'auto';`, tempTableName, s3Uri, s.credentialsClause)
-	if _, err = s.Exec(copyStmt); err != nil {
-		return fmt.Errorf("failed to run COPY for temporary table: %w", err)
+	var maxErrorClause string
+	if s.maxCopyErrors > 0 {
+		maxErrorClause = fmt.Sprintf(" MAXERROR %d", s.maxCopyErrors)
 	}
 
-	return nil
+	return fmt.Sprintf(`COPY %s FROM '%s' DELIMITER '\t' NULL AS '\\N' %sFORMAT CSV %s%s dateformat 'auto' timeformat 'auto';`, tempTableName, s3Uri, compressionClause, s.credentialsClause, maxErrorClause)
+}
+
+// stagingDir is the local directory that holds the staging files for `tempTableName` when the data is split into multiple files.
+func stagingDir(tempTableName string) string {
+	return fmt.Sprintf("/tmp/%s", tempTableName)
 }
 
-func (s *Store) loadTemporaryTable(tableData *optimization.TableData, newTableName string) (string, error) {
-	filePath := fmt.Sprintf("/tmp/%s.csv.gz", newTableName)
+// stagingS3Prefix returns the S3 URI of the folder that holds all the staging files, the trailing slash is important so that
+// COPY does not pick up objects from another table that happens to share the same name prefix.
+func stagingS3Prefix(bucket string, prefix string) string {
+	return fmt.Sprintf("s3://%s/%s/", bucket, prefix)
+}
+
+// numStagingFiles returns how many files the staging data should be split into, we never create more files than rows.
+func (s *Store) numStagingFiles(numRows int) int {
+	return max(min(s.stagingFileCount, numRows), 1)
+}
+
+// loadTemporaryTable writes the table data into one or more local CSV files and returns their file paths.
+func (s *Store) loadTemporaryTable(tableData *optimization.TableData, newTableName string) ([]string, error) {
+	extension := ".csv"
+	if s.compressStaging {
+		extension += ".gz"
+	}
+
+	rows := tableData.Rows()
+	fileCount := s.numStagingFiles(len(rows))
+	if fileCount == 1 {
+		filePath := fmt.Sprintf("/tmp/%s%s", newTableName, extension)
+		if err := s.writeStagingFile(filePath, tableData, rows); err != nil {
+			return nil, err
+		}
+
+		return []string{filePath}, nil
+	}
+
+	dir := stagingDir(newTableName)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	// Split the rows into contiguous chunks that differ in size by at most one row.
+	filePaths := make([]string, 0, fileCount)
+	chunkSize, remainder := len(rows)/fileCount, len(rows)%fileCount
+	var start int
+	for i := range fileCount {
+		end := start + chunkSize
+		if i < remainder {
+			end++
+		}
+
+		filePath := filepath.Join(dir, fmt.Sprintf("part_%03d%s", i, extension))
+		filePaths = append(filePaths, filePath)
+		if err := s.writeStagingFile(filePath, tableData, rows[start:end]); err != nil {
+			return filePaths, err
+		}
+
+		start = end
+	}
+
+	return filePaths, nil
+}
+
+func (s *Store) writeStagingFile(filePath string, tableData *optimization.TableData, rows []map[string]any) error {
 	file, err := os.Create(filePath)
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	defer file.Close()
 
-	gzipWriter := gzip.NewWriter(file) // Create a new gzip writer
-	defer gzipWriter.Close()           // Ensure to close the gzip writer after writing
+	var fileWriter io.Writer = file
+	if s.compressStaging {
+		gzipWriter := gzip.NewWriter(file) // Create a new gzip writer
+		defer gzipWriter.Close()           // Ensure to close the gzip writer after writing
+		fileWriter = gzipWriter
+	}
 
-	writer := csv.NewWriter(gzipWriter) // Create a CSV writer on top of the gzip writer
+	writer := csv.NewWriter(fileWriter)
 	writer.Comma = '\t'
 
 	additionalDateFmts := s.config.SharedTransferConfig.TypingSettings.AdditionalDateFormats
-	for _, value := range tableData.Rows() {
+	for _, value := range rows {
 		var row []string
-		for _, col := range tableData.ReadOnlyInMemoryCols().GetColumnsToUpdate(s.config.SharedDestinationConfig.UppercaseEscapedNames, nil) {
+		for _, col := range tableData.ReadOnlyInMemoryCols().GetColumnsToUpdate(s.config.SharedDestinationConfig.GetIdentifierCasing(), nil) {
 			colKind, _ := tableData.ReadOnlyInMemoryCols().GetColumn(col)
 			castedValue, castErr := s.CastColValStaging(value[col], colKind, additionalDateFmts)
 			if castErr != nil {
-				return "", castErr
+				return castErr
 			}
 
 			row = append(row, castedValue)
 		}
 
 		if err = writer.Write(row); err != nil {
-			return "", fmt.Errorf("failed to write to csv: %w", err)
+			return fmt.Errorf("failed to write to csv: %w", err)
 		}
 	}
 
 	writer.Flush()
 	if err = writer.Error(); err != nil {
-		return "", fmt.Errorf("failed to flush csv writer: %w", err)
+		return fmt.Errorf("failed to flush csv writer: %w", err)
 	}
 
-	return filePath, nil
+	return nil
 }
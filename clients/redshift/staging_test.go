@@ -0,0 +1,99 @@
+package redshift
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/stringutil"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func countStagingRows(t assert.TestingT, filePath string) int {
+	file, err := os.Open(filePath)
+	if !assert.NoError(t, err) {
+		return 0
+	}
+
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	if !assert.NoError(t, err) {
+		return 0
+	}
+
+	reader := csv.NewReader(gzipReader)
+	reader.Comma = '\t'
+	records, err := reader.ReadAll()
+	assert.NoError(t, err)
+	return len(records)
+}
+
+func (r *RedshiftTestSuite) TestLoadTemporaryTable_StagingFileCount() {
+	var cols columns.Columns
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("name", typing.String))
+
+	tableData := optimization.NewTableData(&cols, config.Replication, []string{"id"}, kafkalib.TopicConfig{Database: "db", Schema: "public", TableName: "users"}, "users")
+	for i := range 10 {
+		tableData.InsertRow(fmt.Sprint(i), map[string]any{"id": i, "name": fmt.Sprintf("name-%d", i)}, false)
+	}
+
+	{
+		// Not set, a single file
+		tempTableName := "users_" + stringutil.Random(5)
+		filePaths, err := r.store.loadTemporaryTable(tableData, tempTableName)
+		assert.NoError(r.T(), err)
+		assert.Equal(r.T(), []string{fmt.Sprintf("/tmp/%s.csv.gz", tempTableName)}, filePaths)
+		assert.Equal(r.T(), 10, countStagingRows(r.T(), filePaths[0]))
+		assert.NoError(r.T(), os.RemoveAll(filePaths[0]))
+	}
+	{
+		// Split into 3 files
+		store := LoadRedshift(config.Config{Redshift: &config.Redshift{StagingFileCount: 3}}, &r.store.Store)
+		tempTableName := "users_" + stringutil.Random(5)
+		filePaths, err := store.loadTemporaryTable(tableData, tempTableName)
+		assert.NoError(r.T(), err)
+		defer os.RemoveAll(stagingDir(tempTableName))
+
+		assert.Equal(r.T(), []string{
+			filepath.Join(stagingDir(tempTableName), "part_000.csv.gz"),
+			filepath.Join(stagingDir(tempTableName), "part_001.csv.gz"),
+			filepath.Join(stagingDir(tempTableName), "part_002.csv.gz"),
+		}, filePaths)
+
+		var totalRows int
+		for i, expectedRows := range []int{4, 3, 3} {
+			rows := countStagingRows(r.T(), filePaths[i])
+			assert.Equal(r.T(), expectedRows, rows, filePaths[i])
+			totalRows += rows
+		}
+
+		assert.Equal(r.T(), 10, totalRows)
+	}
+	{
+		// More files than rows, we should only create one file per row
+		store := LoadRedshift(config.Config{Redshift: &config.Redshift{StagingFileCount: 32}}, &r.store.Store)
+		tempTableName := "users_" + stringutil.Random(5)
+		filePaths, err := store.loadTemporaryTable(tableData, tempTableName)
+		assert.NoError(r.T(), err)
+		defer os.RemoveAll(stagingDir(tempTableName))
+		assert.Len(r.T(), filePaths, 10)
+	}
+}
+
+func (r *RedshiftTestSuite) TestCopyStatement_StagingPrefix() {
+	store := LoadRedshift(config.Config{Redshift: &config.Redshift{StagingFileCount: 4}}, &r.store.Store)
+	store.credentialsClause = "IAM_ROLE 'role'"
+
+	s3Uri := stagingS3Prefix("bucket", "prefix/public.users_abc")
+	assert.Equal(r.T(), "s3://bucket/prefix/public.users_abc/", s3Uri)
+	assert.Equal(r.T(), `COPY public.users_abc FROM 's3://bucket/prefix/public.users_abc/' DELIMITER '\t' NULL AS '\\N' GZIP FORMAT CSV IAM_ROLE 'role' dateformat 'auto' timeformat 'auto';`, store.copyStatement("public.users_abc", s3Uri))
+}
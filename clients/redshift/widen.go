@@ -0,0 +1,64 @@
+package redshift
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/typing"
+)
+
+// widenedVarcharLength returns the new length for a VARCHAR(currentLength) column that needs to fit `observedLength` bytes.
+// We'll at least double the length so that a table with growing values does not need to be altered on every flush.
+func widenedVarcharLength(currentLength int, observedLength int) (int, bool) {
+	if observedLength <= currentLength || currentLength >= maxRedshiftLength {
+		return 0, false
+	}
+
+	return min(maxRedshiftLength, max(observedLength, currentLength*2)), true
+}
+
+// widenVarcharColumns will widen any VARCHAR columns in the destination that are too narrow for the strings in `tableData`.
+// Otherwise, the COPY would fail since Redshift does not truncate strings that are too long.
+func (s *Store) widenVarcharColumns(tableData *optimization.TableData) error {
+	if !s.autoWidenVarchar || tableData.ShouldSkipUpdate() {
+		return nil
+	}
+
+	tableConfig, err := s.GetTableConfig(tableData)
+	if err != nil {
+		return fmt.Errorf("failed to get table config: %w", err)
+	}
+
+	if tableConfig.CreateTable() {
+		return nil
+	}
+
+	fqName := s.ToFullyQualifiedName(tableData, true)
+	nameArgs := &sql.NameArgs{Escape: true, DestKind: s.Label()}
+	for _, col := range tableConfig.Columns().GetColumns() {
+		if col.KindDetails.Kind != typing.String.Kind || col.KindDetails.OptionalStringPrecision == nil {
+			continue
+		}
+
+		newLength, shouldWiden := widenedVarcharLength(*col.KindDetails.OptionalStringPrecision, tableData.MaxStringLength(col.RawName()))
+		if !shouldWiden {
+			continue
+		}
+
+		slog.Info("Widening VARCHAR column", slog.String("table", fqName), slog.String("column", col.RawName()),
+			slog.Int("currentLength", *col.KindDetails.OptionalStringPrecision), slog.Int("newLength", newLength))
+		query := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE VARCHAR(%d);", fqName,
+			col.Name(s.config.SharedDestinationConfig.GetIdentifierCasing(), nameArgs), newLength)
+		if _, err = s.Exec(query); err != nil {
+			return fmt.Errorf("failed to widen column: %q, query: %q: %w", col.RawName(), query, err)
+		}
+
+		col.KindDetails.OptionalStringPrecision = &newLength
+		tableConfig.Columns().UpdateColumn(col)
+		tableConfig.SchemaChanged()
+	}
+
+	return nil
+}
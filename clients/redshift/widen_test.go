@@ -0,0 +1,103 @@
+package redshift
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func (r *RedshiftTestSuite) TestWidenedVarcharLength() {
+	{
+		// Fits
+		_, shouldWiden := widenedVarcharLength(10, 10)
+		assert.False(r.T(), shouldWiden)
+		_, shouldWiden = widenedVarcharLength(10, 0)
+		assert.False(r.T(), shouldWiden)
+	}
+	{
+		// Doubled
+		newLength, shouldWiden := widenedVarcharLength(10, 11)
+		assert.True(r.T(), shouldWiden)
+		assert.Equal(r.T(), 20, newLength)
+	}
+	{
+		// Observed length is more than double
+		newLength, shouldWiden := widenedVarcharLength(10, 500)
+		assert.True(r.T(), shouldWiden)
+		assert.Equal(r.T(), 500, newLength)
+	}
+	{
+		// Capped at the max
+		newLength, shouldWiden := widenedVarcharLength(40000, 40001)
+		assert.True(r.T(), shouldWiden)
+		assert.Equal(r.T(), maxRedshiftLength, newLength)
+
+		newLength, shouldWiden = widenedVarcharLength(10, 100000)
+		assert.True(r.T(), shouldWiden)
+		assert.Equal(r.T(), maxRedshiftLength, newLength)
+	}
+	{
+		// Already at the max
+		_, shouldWiden := widenedVarcharLength(maxRedshiftLength, 100000)
+		assert.False(r.T(), shouldWiden)
+	}
+}
+
+func (r *RedshiftTestSuite) TestWidenVarcharColumns() {
+	newTableData := func(name string) *optimization.TableData {
+		var cols columns.Columns
+		cols.AddColumn(columns.NewColumn("id", typing.Integer))
+		cols.AddColumn(columns.NewColumn("name", typing.String))
+
+		tableData := optimization.NewTableData(&cols, config.Replication, []string{"id"}, kafkalib.TopicConfig{Database: "db", Schema: "public", TableName: "users"}, "users")
+		tableData.InsertRow("1", map[string]any{"id": 1, "name": name}, false)
+
+		ten := 10
+		var destCols columns.Columns
+		destCols.AddColumn(columns.NewColumn("id", typing.Integer))
+		destCols.AddColumn(columns.NewColumn("name", typing.KindDetails{Kind: typing.String.Kind, OptionalStringPrecision: &ten}))
+		r.store.configMap.AddTableToConfig(r.store.ToFullyQualifiedName(tableData, true), types.NewDwhTableConfig(&destCols, nil, false, false))
+		return tableData
+	}
+
+	{
+		// Not enabled
+		assert.NoError(r.T(), r.store.widenVarcharColumns(newTableData(strings.Repeat("a", 25))))
+		assert.Equal(r.T(), 0, r.fakeStore.ExecCallCount())
+	}
+
+	r.store.autoWidenVarchar = true
+	{
+		// Within the width
+		assert.NoError(r.T(), r.store.widenVarcharColumns(newTableData("dusty")))
+		assert.Equal(r.T(), 0, r.fakeStore.ExecCallCount())
+	}
+	{
+		// Over the width
+		tableData := newTableData(strings.Repeat("a", 25))
+		assert.NoError(r.T(), r.store.widenVarcharColumns(tableData))
+		assert.Equal(r.T(), 1, r.fakeStore.ExecCallCount())
+		query, _ := r.fakeStore.ExecArgsForCall(0)
+		assert.Equal(r.T(), "ALTER TABLE public.users ALTER COLUMN name TYPE VARCHAR(25);", query)
+
+		// The table config should have the new width, so we won't widen again.
+		col, isOk := r.store.configMap.TableConfig(r.store.ToFullyQualifiedName(tableData, true)).Columns().GetColumn("name")
+		assert.True(r.T(), isOk)
+		assert.Equal(r.T(), 25, *col.KindDetails.OptionalStringPrecision)
+		assert.NoError(r.T(), r.store.widenVarcharColumns(tableData))
+		assert.Equal(r.T(), 1, r.fakeStore.ExecCallCount())
+	}
+	{
+		// Failed to widen
+		r.fakeStore.ExecReturns(nil, fmt.Errorf("column has an incompatible encoding"))
+		assert.ErrorContains(r.T(), r.store.widenVarcharColumns(newTableData(strings.Repeat("a", 25))), `failed to widen column: "name"`)
+	}
+}
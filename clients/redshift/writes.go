@@ -9,9 +9,21 @@ import (
 )
 
 func (s *Store) Append(tableData *optimization.TableData) error {
+	return s.classifyError(s.append(tableData))
+}
+
+func (s *Store) append(tableData *optimization.TableData) error {
+	if s.spectrum != nil {
+		return s.writeSpectrum(tableData)
+	}
+
+	if err := s.widenVarcharColumns(tableData); err != nil {
+		return err
+	}
+
 	// Redshift is slightly different, we'll load and create the temporary table via shared.Append
 	// Then, we'll invoke `ALTER TABLE target APPEND FROM staging` to combine the diffs.
-	temporaryTableName := fmt.Sprintf("%s_%s", s.ToFullyQualifiedName(tableData, false), tableData.TempTableSuffix())
+	temporaryTableName := s.TemporaryTableName(tableData)
 	if err := shared.Append(s, tableData, s.config, types.AppendOpts{TempTableName: temporaryTableName}); err != nil {
 		return err
 	}
@@ -21,6 +33,19 @@ func (s *Store) Append(tableData *optimization.TableData) error {
 }
 
 func (s *Store) Merge(tableData *optimization.TableData) error {
+	return s.classifyError(s.merge(tableData))
+}
+
+func (s *Store) merge(tableData *optimization.TableData) error {
+	if s.spectrum != nil {
+		// External tables do not support MERGE, so every flush is appended as a new Parquet file.
+		return s.writeSpectrum(tableData)
+	}
+
+	if err := s.widenVarcharColumns(tableData); err != nil {
+		return err
+	}
+
 	return shared.Merge(s, tableData, s.config, types.MergeOpts{
 		UseMergeParts: true,
 		// We are adding SELECT DISTINCT here for the temporary table as an extra guardrail.
@@ -0,0 +1,80 @@
+package s3
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/parquetutil"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+// writeParquetFile will write `rows` into a GZIP compressed Parquet file at `fp`, the schema is generated from the table's columns.
+func writeParquetFile(fp string, tableData *optimization.TableData, rows []map[string]any, casing config.IdentifierCasing, additionalDateFmts []string) error {
+	var cols []columns.Column
+	for _, col := range tableData.ReadOnlyInMemoryCols().GetColumns() {
+		if col.KindDetails == typing.Invalid {
+			continue
+		}
+
+		cols = append(cols, col)
+	}
+
+	schema, err := parquetutil.GenerateJSONSchema(cols)
+	if err != nil {
+		return fmt.Errorf("failed to generate parquet schema: %w", err)
+	}
+
+	fw, err := local.NewLocalFileWriter(fp)
+	if err != nil {
+		return fmt.Errorf("failed to create a local parquet file: %w", err)
+	}
+
+	pw, err := writer.NewJSONWriter(schema, fw, 4)
+	if err != nil {
+		return fmt.Errorf("failed to instantiate parquet writer: %w", err)
+	}
+
+	pw.CompressionType = parquet.CompressionCodec_GZIP
+	for _, val := range rows {
+		row := make(map[string]any)
+		for _, col := range tableData.ReadOnlyInMemoryCols().GetColumnsToUpdate(casing, nil) {
+			colKind, isOk := tableData.ReadOnlyInMemoryCols().GetColumn(col)
+			if !isOk {
+				return fmt.Errorf("expected column: %v to exist in readOnlyInMemoryCols(...) but it does not", col)
+			}
+
+			value, err := parquetutil.ParseValue(val[col], colKind, additionalDateFmts)
+			if err != nil {
+				return fmt.Errorf("failed to parse value, err: %w, value: %v, column: %v", err, val[col], col)
+			}
+
+			row[col] = value
+		}
+
+		rowBytes, err := json.Marshal(row)
+		if err != nil {
+			return fmt.Errorf("failed to marshal row: %w", err)
+		}
+
+		if err = pw.Write(string(rowBytes)); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
+		}
+	}
+
+	if err = pw.WriteStop(); err != nil {
+		return fmt.Errorf("failed to write stop: %w", err)
+	}
+
+	if err = fw.Close(); err != nil {
+		return fmt.Errorf("failed to close filewriter: %w", err)
+	}
+
+	return nil
+}
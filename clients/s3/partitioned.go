@@ -0,0 +1,199 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/s3lib"
+	"github.com/artie-labs/transfer/lib/stringutil"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+// hiveDefaultPartition is what Hive (and Athena/Spark) use for rows where the partition value is NULL or empty.
+const hiveDefaultPartition = "__HIVE_DEFAULT_PARTITION__"
+
+var partitionTimeLayouts = map[config.S3PartitionGranularity]string{
+	config.S3PartitionHour:  "2006-01-02-15",
+	config.S3PartitionDay:   "2006-01-02",
+	config.S3PartitionMonth: "2006-01",
+	config.S3PartitionYear:  "2006",
+}
+
+// PartitionedStore writes a Parquet file for every Hive-style partition (`name=value/`) that the rows in a flush belong to.
+type PartitionedStore struct {
+	config           config.Config
+	identifierCasing config.IdentifierCasing
+	// upload is a variable so that tests can capture the files instead of writing them to S3.
+	upload func(ctx context.Context, args s3lib.UploadArgs) (string, error)
+}
+
+func (p *PartitionedStore) Validate() error {
+	if p == nil {
+		return fmt.Errorf("s3parquet store is nil")
+	}
+
+	if err := p.config.ValidateS3Parquet(); err != nil {
+		return fmt.Errorf("failed to validate settings: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PartitionedStore) Label() constants.DestinationKind {
+	return constants.S3Parquet
+}
+
+// TablePrefix is the table's location in the bucket, the partitions are written underneath it: optionalPrefix/db.schema.tableName
+func (p *PartitionedStore) TablePrefix(tableData *optimization.TableData) string {
+	fqTableName := tableData.ToFqName(p.Label(), false, p.identifierCasing, optimization.FqNameOpts{})
+	if p.config.S3Parquet.Prefix != "" {
+		return strings.Join([]string{strings.TrimSuffix(p.config.S3Parquet.Prefix, "/"), fqTableName}, "/")
+	}
+
+	return fqTableName
+}
+
+// partitionValue returns the escaped value of the partition column for `row`, this is what goes after `name=` in the path.
+func (p *PartitionedStore) partitionValue(row map[string]any) (string, error) {
+	partitionBy := p.config.S3Parquet.PartitionBy
+	value, isOk := row[strings.ToLower(partitionBy.Column)]
+	if !isOk || value == nil || value == constants.ToastUnavailableValuePlaceholder {
+		return hiveDefaultPartition, nil
+	}
+
+	var partition string
+	if partitionBy.Granularity != "" {
+		extTime, err := ext.ParseFromInterface(value, p.config.SharedTransferConfig.TypingSettings.AdditionalDateFormats)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse partition column %q as a timestamp: %w", partitionBy.Column, err)
+		}
+
+		partition = extTime.StringUTC(partitionTimeLayouts[partitionBy.Granularity])
+	} else if extTime, isOk := value.(*ext.ExtendedTime); isOk {
+		partition = extTime.String("")
+	} else {
+		partition = fmt.Sprint(value)
+	}
+
+	if partition == "" {
+		return hiveDefaultPartition, nil
+	}
+
+	return escapePartitionValue(partition), nil
+}
+
+// escapePartitionValue will percent-encode the characters that Hive escapes in partition paths.
+// Spec: https://github.com/apache/hive/blob/master/common/src/java/org/apache/hadoop/hive/common/FileUtils.java
+func escapePartitionValue(value string) string {
+	var sb strings.Builder
+	for _, char := range []byte(value) {
+		if char < 0x20 || char == 0x7F || strings.IndexByte("\"#%'*/:=?\\{[]^", char) >= 0 {
+			sb.WriteString(fmt.Sprintf("%%%02X", char))
+			continue
+		}
+
+		sb.WriteByte(char)
+	}
+
+	return sb.String()
+}
+
+func (p *PartitionedStore) Append(tableData *optimization.TableData) error {
+	// Same as S3, every flush is written as new files.
+	return p.Merge(tableData)
+}
+
+// Merge will group the rows by their partition and then write and upload one Parquet file per partition to:
+// s3://bucket/optionalPrefix/db.schema.tableName/name=value/{{batch_id}}.parquet.gz
+// The object names are derived from [optimization.TableData.BatchID], so if a flush fails part of the way through, the retry will overwrite the partitions that were already uploaded instead of duplicating them.
+// The rows are grouped before writing, so only one Parquet writer (and local file) is open at a time regardless of how many partitions a flush touches.
+func (p *PartitionedStore) Merge(tableData *optimization.TableData) error {
+	if tableData.ShouldSkipUpdate() {
+		return nil
+	}
+
+	partitions := make(map[string][]map[string]any)
+	for _, row := range tableData.Rows() {
+		partition, err := p.partitionValue(row)
+		if err != nil {
+			return err
+		}
+
+		partitions[partition] = append(partitions[partition], row)
+	}
+
+	// Sorting so that the files are written in the same order every time.
+	keys := make([]string, 0, len(partitions))
+	for key := range partitions {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+	tablePrefix := p.TablePrefix(tableData)
+	for _, key := range keys {
+		objectPrefix := fmt.Sprintf("%s/%s=%s", tablePrefix, p.config.S3Parquet.PartitionBy.PartitionName(), key)
+		if err := p.writePartition(tableData, partitions[key], objectPrefix); err != nil {
+			return fmt.Errorf("failed to write partition %q: %w", objectPrefix, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *PartitionedStore) writePartition(tableData *optimization.TableData, rows []map[string]any, objectPrefix string) error {
+	fp := fmt.Sprintf("/tmp/%v_%s.parquet.gz", tableData.LatestCDCTs.UnixMilli(), stringutil.Random(4))
+	defer func() {
+		// Delete the file regardless of outcome to avoid fs build up.
+		if removeErr := os.RemoveAll(fp); removeErr != nil {
+			slog.Warn("Failed to delete temp file", slog.Any("err", removeErr), slog.String("filePath", fp))
+		}
+	}()
+
+	if err := writeParquetFile(fp, tableData, rows, p.identifierCasing, p.config.SharedTransferConfig.TypingSettings.AdditionalDateFormats); err != nil {
+		return err
+	}
+
+	args := s3lib.UploadArgs{
+		Bucket:             p.config.S3Parquet.Bucket,
+		OptionalS3Prefix:   objectPrefix,
+		FilePath:           fp,
+		OptionalObjectName: fmt.Sprintf("%s.parquet.gz", tableData.BatchID()),
+	}
+
+	if p.config.S3Parquet.AwsAccessKeyID != "" {
+		args.OverrideAWSAccessKeyID = ptr.ToString(p.config.S3Parquet.AwsAccessKeyID)
+		args.OverrideAWSAccessKeySecret = ptr.ToString(p.config.S3Parquet.AwsSecretAccessKey)
+	}
+
+	if _, err := p.upload(context.Background(), args); err != nil {
+		return fmt.Errorf("failed to upload file to s3: %w", err)
+	}
+
+	return nil
+}
+
+func (p *PartitionedStore) IsRetryableError(_ error) bool {
+	return false // not supported for S3
+}
+
+func LoadPartitionedStore(cfg config.Config) (*PartitionedStore, error) {
+	store := &PartitionedStore{
+		config:           cfg,
+		identifierCasing: config.PreserveCasing,
+		upload:           s3lib.UploadLocalFileToS3,
+	}
+
+	if err := store.Validate(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
@@ -0,0 +1,171 @@
+package s3
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/s3lib"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+type uploadedFile struct {
+	args    s3lib.UploadArgs
+	numRows int64
+}
+
+func newPartitionedStore(t *testing.T, settings config.S3Parquet) (*PartitionedStore, *[]uploadedFile) {
+	store, err := LoadPartitionedStore(config.Config{Output: constants.S3Parquet, S3Parquet: &settings})
+	assert.NoError(t, err)
+
+	var uploaded []uploadedFile
+	store.upload = func(_ context.Context, args s3lib.UploadArgs) (string, error) {
+		// Copy the file since it'll be deleted once the upload returns.
+		fp := filepath.Join(t.TempDir(), filepath.Base(args.FilePath))
+		data, err := os.ReadFile(args.FilePath)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(fp, data, 0644))
+
+		fr, err := local.NewLocalFileReader(fp)
+		assert.NoError(t, err)
+		defer fr.Close()
+
+		pr := &reader.ParquetReader{PFile: fr}
+		assert.NoError(t, pr.ReadFooter())
+		uploaded = append(uploaded, uploadedFile{args: args, numRows: pr.Footer.NumRows})
+		return "s3://" + args.Bucket + "/" + args.OptionalS3Prefix, nil
+	}
+
+	return store, &uploaded
+}
+
+func newOrdersTableData() *optimization.TableData {
+	cols := &columns.Columns{}
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("region", typing.String))
+	cols.AddColumn(columns.NewColumn("created_at", typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateTimeKindType)))
+	td := optimization.NewTableData(cols, config.Replication, []string{"id"}, kafkalib.TopicConfig{Database: "shop", Schema: "public"}, "orders")
+	td.LatestCDCTs = time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	return td
+}
+
+func TestLoadPartitionedStore(t *testing.T) {
+	_, err := LoadPartitionedStore(config.Config{Output: constants.S3Parquet})
+	assert.ErrorContains(t, err, "failed to validate settings: s3parquet config is nil")
+}
+
+func TestPartitionedStore_TablePrefix(t *testing.T) {
+	td := newOrdersTableData()
+	{
+		store, _ := newPartitionedStore(t, config.S3Parquet{Bucket: "bucket", PartitionBy: config.S3PartitionBy{Column: "region"}})
+		assert.Equal(t, "shop.public.orders", store.TablePrefix(td))
+	}
+	{
+		store, _ := newPartitionedStore(t, config.S3Parquet{Bucket: "bucket", Prefix: "lake/", PartitionBy: config.S3PartitionBy{Column: "region"}})
+		assert.Equal(t, "lake/shop.public.orders", store.TablePrefix(td))
+	}
+}
+
+func TestPartitionedStore_Merge(t *testing.T) {
+	{
+		// Partitioned by the value
+		store, uploaded := newPartitionedStore(t, config.S3Parquet{Bucket: "bucket", Prefix: "lake", PartitionBy: config.S3PartitionBy{Column: "region"}})
+		td := newOrdersTableData()
+		td.InsertRow("1", map[string]any{"id": 1, "region": "us-east", "created_at": "2024-01-01T10:00:00Z"}, false)
+		td.InsertRow("2", map[string]any{"id": 2, "region": "eu/west", "created_at": "2024-01-01T11:00:00Z"}, false)
+		td.InsertRow("3", map[string]any{"id": 3, "region": "us-east", "created_at": "2024-01-02T10:00:00Z"}, false)
+		td.InsertRow("4", map[string]any{"id": 4, "created_at": "2024-01-02T11:00:00Z"}, false)
+		assert.NoError(t, store.Merge(td))
+
+		assert.Len(t, *uploaded, 3)
+		assert.Equal(t, "lake/shop.public.orders/region=__HIVE_DEFAULT_PARTITION__", (*uploaded)[0].args.OptionalS3Prefix)
+		assert.Equal(t, int64(1), (*uploaded)[0].numRows)
+		assert.Equal(t, "lake/shop.public.orders/region=eu%2Fwest", (*uploaded)[1].args.OptionalS3Prefix)
+		assert.Equal(t, int64(1), (*uploaded)[1].numRows)
+		assert.Equal(t, "lake/shop.public.orders/region=us-east", (*uploaded)[2].args.OptionalS3Prefix)
+		assert.Equal(t, int64(2), (*uploaded)[2].numRows)
+		for _, file := range *uploaded {
+			assert.Equal(t, "bucket", file.args.Bucket)
+			// The default credentials chain is used.
+			assert.Nil(t, file.args.OverrideAWSAccessKeyID)
+			// The local files are cleaned up after uploading.
+			_, err := os.Stat(file.args.FilePath)
+			assert.True(t, os.IsNotExist(err))
+		}
+	}
+	{
+		// Partitioned by the day of a timestamp column
+		store, uploaded := newPartitionedStore(t, config.S3Parquet{
+			Bucket:             "bucket",
+			PartitionBy:        config.S3PartitionBy{Column: "created_at", Granularity: config.S3PartitionDay, Name: "dt"},
+			AwsAccessKeyID:     "foo",
+			AwsSecretAccessKey: "bar",
+		})
+		td := newOrdersTableData()
+		td.InsertRow("1", map[string]any{"id": 1, "region": "us-east", "created_at": "2024-01-01T10:00:00Z"}, false)
+		td.InsertRow("2", map[string]any{"id": 2, "region": "eu-west", "created_at": "2024-01-01T23:00:00-02:00"}, false)
+		td.InsertRow("3", map[string]any{"id": 3, "region": "us-east", "created_at": "2024-01-02T10:00:00Z"}, false)
+		assert.NoError(t, store.Merge(td))
+
+		assert.Len(t, *uploaded, 2)
+		assert.Equal(t, "shop.public.orders/dt=2024-01-01", (*uploaded)[0].args.OptionalS3Prefix)
+		assert.Equal(t, int64(1), (*uploaded)[0].numRows)
+		// 23:00 at -02:00 is the next day in UTC.
+		assert.Equal(t, "shop.public.orders/dt=2024-01-02", (*uploaded)[1].args.OptionalS3Prefix)
+		assert.Equal(t, int64(2), (*uploaded)[1].numRows)
+		assert.Equal(t, "foo", *(*uploaded)[0].args.OverrideAWSAccessKeyID)
+		assert.Equal(t, "bar", *(*uploaded)[0].args.OverrideAWSAccessKeySecret)
+	}
+	{
+		// The partition column cannot be parsed as a timestamp
+		store, uploaded := newPartitionedStore(t, config.S3Parquet{Bucket: "bucket", PartitionBy: config.S3PartitionBy{Column: "region", Granularity: config.S3PartitionMonth}})
+		td := newOrdersTableData()
+		td.InsertRow("1", map[string]any{"id": 1, "region": "us-east", "created_at": "2024-01-01T10:00:00Z"}, false)
+		assert.ErrorContains(t, store.Merge(td), `failed to parse partition column "region" as a timestamp`)
+		assert.Empty(t, *uploaded)
+	}
+	{
+		// Retrying a flush should overwrite the objects that were uploaded by the failed attempt
+		store, uploaded := newPartitionedStore(t, config.S3Parquet{Bucket: "bucket", PartitionBy: config.S3PartitionBy{Column: "region"}})
+		td := newOrdersTableData()
+		td.RecordOffset("0", 100)
+		td.InsertRow("1", map[string]any{"id": 1, "region": "us-east", "created_at": "2024-01-01T10:00:00Z"}, false)
+		td.InsertRow("2", map[string]any{"id": 2, "region": "eu-west", "created_at": "2024-01-01T11:00:00Z"}, false)
+		assert.NoError(t, store.Merge(td))
+
+		// More rows were buffered before the retry.
+		td.RecordOffset("0", 102)
+		td.InsertRow("3", map[string]any{"id": 3, "region": "us-east", "created_at": "2024-01-02T10:00:00Z"}, false)
+		assert.NoError(t, store.Merge(td))
+
+		assert.Len(t, *uploaded, 4)
+		objectName := td.BatchID() + ".parquet.gz"
+		for _, file := range *uploaded {
+			assert.Equal(t, objectName, file.args.OptionalObjectName)
+		}
+
+		// The next batch should be written to a new object.
+		nextTd := newOrdersTableData()
+		nextTd.RecordOffset("0", 103)
+		assert.NotEqual(t, td.BatchID(), nextTd.BatchID())
+	}
+}
+
+func TestEscapePartitionValue(t *testing.T) {
+	assert.Equal(t, "us-east-1", escapePartitionValue("us-east-1"))
+	assert.Equal(t, "a%2Fb%3Dc%3Ad", escapePartitionValue("a/b=c:d"))
+	assert.Equal(t, "100%25", escapePartitionValue("100%"))
+	assert.Equal(t, "hello world", escapePartitionValue("hello world"))
+}
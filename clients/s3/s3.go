@@ -2,7 +2,6 @@ package s3
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
@@ -16,21 +15,14 @@ import (
 
 	"github.com/artie-labs/transfer/lib/s3lib"
 
-	"github.com/xitongsys/parquet-go/parquet"
-
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/optimization"
-	"github.com/artie-labs/transfer/lib/parquetutil"
-	"github.com/artie-labs/transfer/lib/typing"
-	"github.com/artie-labs/transfer/lib/typing/columns"
-	"github.com/xitongsys/parquet-go-source/local"
-	"github.com/xitongsys/parquet-go/writer"
 )
 
 type Store struct {
-	config            config.Config
-	uppercaseEscNames bool
+	config           config.Config
+	identifierCasing config.IdentifierCasing
 }
 
 func (s *Store) Validate() error {
@@ -53,7 +45,7 @@ func (s *Store) Label() constants.DestinationKind {
 // It will look like something like this:
 // > optionalPrefix/fullyQualifiedTableName/YYYY-MM-DD
 func (s *Store) ObjectPrefix(tableData *optimization.TableData) string {
-	fqTableName := tableData.ToFqName(s.Label(), false, s.uppercaseEscNames, optimization.FqNameOpts{})
+	fqTableName := tableData.ToFqName(s.Label(), false, s.identifierCasing, optimization.FqNameOpts{})
 	yyyyMMDDFormat := tableData.LatestCDCTs.Format(ext.PostgresDateFormat)
 
 	if len(s.config.S3.OptionalPrefix) > 0 {
@@ -78,65 +70,9 @@ func (s *Store) Merge(tableData *optimization.TableData) error {
 		return nil
 	}
 
-	var cols []columns.Column
-	for _, col := range tableData.ReadOnlyInMemoryCols().GetColumns() {
-		if col.KindDetails == typing.Invalid {
-			continue
-		}
-
-		cols = append(cols, col)
-	}
-
-	schema, err := parquetutil.GenerateJSONSchema(cols)
-	if err != nil {
-		return fmt.Errorf("failed to generate parquet schema: %w", err)
-	}
-
 	fp := fmt.Sprintf("/tmp/%v_%s.parquet.gz", tableData.LatestCDCTs.UnixMilli(), stringutil.Random(4))
-	fw, err := local.NewLocalFileWriter(fp)
-	if err != nil {
-		return fmt.Errorf("failed to create a local parquet file: %w", err)
-	}
-
-	pw, err := writer.NewJSONWriter(schema, fw, 4)
-	if err != nil {
-		return fmt.Errorf("failed to instantiate parquet writer: %w", err)
-	}
-
-	additionalDateFmts := s.config.SharedTransferConfig.TypingSettings.AdditionalDateFormats
-	pw.CompressionType = parquet.CompressionCodec_GZIP
-	for _, val := range tableData.Rows() {
-		row := make(map[string]any)
-		for _, col := range tableData.ReadOnlyInMemoryCols().GetColumnsToUpdate(s.uppercaseEscNames, nil) {
-			colKind, isOk := tableData.ReadOnlyInMemoryCols().GetColumn(col)
-			if !isOk {
-				return fmt.Errorf("expected column: %v to exist in readOnlyInMemoryCols(...) but it does not", col)
-			}
-
-			value, err := parquetutil.ParseValue(val[col], colKind, additionalDateFmts)
-			if err != nil {
-				return fmt.Errorf("failed to parse value, err: %w, value: %v, column: %v", err, val[col], col)
-			}
-
-			row[col] = value
-		}
-
-		rowBytes, err := json.Marshal(row)
-		if err != nil {
-			return fmt.Errorf("failed to marshal row: %w", err)
-		}
-
-		if err = pw.Write(string(rowBytes)); err != nil {
-			return fmt.Errorf("failed to write row: %w", err)
-		}
-	}
-
-	if err = pw.WriteStop(); err != nil {
-		return fmt.Errorf("failed to write stop: %w", err)
-	}
-
-	if err = fw.Close(); err != nil {
-		return fmt.Errorf("failed to close filewriter: %w", err)
+	if err := writeParquetFile(fp, tableData, tableData.Rows(), s.identifierCasing, s.config.SharedTransferConfig.TypingSettings.AdditionalDateFormats); err != nil {
+		return err
 	}
 
 	defer func() {
@@ -146,7 +82,7 @@ func (s *Store) Merge(tableData *optimization.TableData) error {
 		}
 	}()
 
-	if _, err = s3lib.UploadLocalFileToS3(context.Background(), s3lib.UploadArgs{
+	if _, err := s3lib.UploadLocalFileToS3(context.Background(), s3lib.UploadArgs{
 		Bucket:                     s.config.S3.Bucket,
 		OptionalS3Prefix:           s.ObjectPrefix(tableData),
 		FilePath:                   fp,
@@ -165,8 +101,8 @@ func (s *Store) IsRetryableError(_ error) bool {
 
 func LoadStore(cfg config.Config) (*Store, error) {
 	store := &Store{
-		config:            cfg,
-		uppercaseEscNames: false,
+		config:           cfg,
+		identifierCasing: config.PreserveCasing,
 	}
 
 	if err := store.Validate(); err != nil {
@@ -13,7 +13,7 @@ import (
 	"github.com/artie-labs/transfer/lib/typing/columns"
 )
 
-func Append(dwh destination.DataWarehouse, tableData *optimization.TableData, cfg config.Config, opts types.AppendOpts) error {
+func Append(dwh destination.DataWarehouse, tableData *optimization.TableData, cfg config.Config, opts types.AppendOpts) (err error) {
 	if err := opts.Validate(); err != nil {
 		return fmt.Errorf("failed to validate append options: %w", err)
 	}
@@ -28,20 +28,31 @@ func Append(dwh destination.DataWarehouse, tableData *optimization.TableData, cf
 		return err
 	}
 
+	defer func() {
+		if err != nil {
+			tableConfig.WriteFailed()
+		}
+	}()
+
+	// Columns over the topic's column limit need to be folded before we figure out which columns to add.
+	tableData.FoldOverflowColumns(tableConfig.Columns())
 	// We don't care about srcKeysMissing because we don't drop columns when we append.
 	_, targetKeysMissing := columns.Diff(tableData.ReadOnlyInMemoryCols(), tableConfig.Columns(),
-		tableData.TopicConfig.SoftDelete, tableData.TopicConfig.IncludeArtieUpdatedAt,
+		tableData.TopicConfig.IncludeDeleteColumn(), tableData.TopicConfig.IncludeArtieUpdatedAt,
 		tableData.TopicConfig.IncludeDatabaseUpdatedAt, tableData.Mode())
 
 	createAlterTableArgs := ddl.AlterTableArgs{
-		Dwh:               dwh,
-		Tc:                tableConfig,
-		FqTableName:       fqName,
-		CreateTable:       tableConfig.CreateTable(),
-		ColumnOp:          constants.Add,
-		CdcTime:           tableData.LatestCDCTs,
-		UppercaseEscNames: &cfg.SharedDestinationConfig.UppercaseEscapedNames,
-		Mode:              tableData.Mode(),
+		Dwh:              dwh,
+		Tc:               tableConfig,
+		FqTableName:      fqName,
+		CreateTable:      tableConfig.CreateTable(),
+		ColumnOp:         constants.Add,
+		CdcTime:          tableData.LatestCDCTs,
+		IdentifierCasing: cfg.SharedDestinationConfig.GetIdentifierCasing(),
+		Mode:             tableData.Mode(),
+
+		BigQueryPartitionSettings: tableData.TopicConfig.BigQueryPartitionSettings,
+		BigQueryClusterFields:     tableData.TopicConfig.BigQueryClusterFields,
 	}
 
 	// Keys that exist in CDC stream, but not in DWH
@@ -52,6 +63,9 @@ func Append(dwh destination.DataWarehouse, tableData *optimization.TableData, cf
 	}
 
 	tableData.MergeColumnsFromDestination(tableConfig.Columns().GetColumns()...)
+	if err = UpdateColumnComments(cfg, dwh, tableData, tableConfig, fqName); err != nil {
+		return fmt.Errorf("failed to update column comments: %w", err)
+	}
 
 	additionalSettings := types.AdditionalSettings{
 		AdditionalCopyClause: opts.AdditionalCopyClause,
@@ -3,6 +3,7 @@ package shared
 import (
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/artie-labs/transfer/lib/config"
@@ -20,7 +21,7 @@ import (
 
 const backfillMaxRetries = 1000
 
-func Merge(dwh destination.DataWarehouse, tableData *optimization.TableData, cfg config.Config, opts types.MergeOpts) error {
+func Merge(dwh destination.DataWarehouse, tableData *optimization.TableData, cfg config.Config, opts types.MergeOpts) (err error) {
 	if tableData.ShouldSkipUpdate() {
 		return nil
 	}
@@ -30,20 +31,31 @@ func Merge(dwh destination.DataWarehouse, tableData *optimization.TableData, cfg
 		return fmt.Errorf("failed to get table config: %w", err)
 	}
 
+	defer func() {
+		if err != nil {
+			tableConfig.WriteFailed()
+		}
+	}()
+
+	// Columns over the topic's column limit need to be folded before we figure out which columns to add.
+	tableData.FoldOverflowColumns(tableConfig.Columns())
 	srcKeysMissing, targetKeysMissing := columns.Diff(tableData.ReadOnlyInMemoryCols(), tableConfig.Columns(),
-		tableData.TopicConfig.SoftDelete, tableData.TopicConfig.IncludeArtieUpdatedAt,
+		tableData.TopicConfig.IncludeDeleteColumn(), tableData.TopicConfig.IncludeArtieUpdatedAt,
 		tableData.TopicConfig.IncludeDatabaseUpdatedAt, tableData.Mode())
 
 	fqName := dwh.ToFullyQualifiedName(tableData, true)
 	createAlterTableArgs := ddl.AlterTableArgs{
-		Dwh:               dwh,
-		Tc:                tableConfig,
-		FqTableName:       fqName,
-		CreateTable:       tableConfig.CreateTable(),
-		ColumnOp:          constants.Add,
-		CdcTime:           tableData.LatestCDCTs,
-		UppercaseEscNames: &cfg.SharedDestinationConfig.UppercaseEscapedNames,
-		Mode:              tableData.Mode(),
+		Dwh:              dwh,
+		Tc:               tableConfig,
+		FqTableName:      fqName,
+		CreateTable:      tableConfig.CreateTable(),
+		ColumnOp:         constants.Add,
+		CdcTime:          tableData.LatestCDCTs,
+		IdentifierCasing: cfg.SharedDestinationConfig.GetIdentifierCasing(),
+		Mode:             tableData.Mode(),
+
+		BigQueryPartitionSettings: tableData.TopicConfig.BigQueryPartitionSettings,
+		BigQueryClusterFields:     tableData.TopicConfig.BigQueryClusterFields,
 	}
 
 	// Columns that are missing in DWH, but exist in our CDC stream.
@@ -61,7 +73,7 @@ func Merge(dwh destination.DataWarehouse, tableData *optimization.TableData, cfg
 		ColumnOp:               constants.Delete,
 		ContainOtherOperations: tableData.ContainOtherOperations(),
 		CdcTime:                tableData.LatestCDCTs,
-		UppercaseEscNames:      &cfg.SharedDestinationConfig.UppercaseEscapedNames,
+		IdentifierCasing:       cfg.SharedDestinationConfig.GetIdentifierCasing(),
 		Mode:                   tableData.Mode(),
 	}
 
@@ -71,8 +83,42 @@ func Merge(dwh destination.DataWarehouse, tableData *optimization.TableData, cfg
 
 	tableConfig.AuditColumnsToDelete(srcKeysMissing)
 	tableData.MergeColumnsFromDestination(tableConfig.Columns().GetColumns()...)
-	temporaryTableName := fmt.Sprintf("%s_%s", dwh.ToFullyQualifiedName(tableData, false), tableData.TempTableSuffix())
-	if err = dwh.PrepareTemporaryTable(tableData, tableConfig, temporaryTableName, types.AdditionalSettings{}, true); err != nil {
+	for _, col := range tableData.TopicConfig.ImmutableColumns {
+		if _, isOk := tableConfig.Columns().GetColumn(strings.ToLower(col)); !isOk {
+			return fmt.Errorf("immutable column: %q does not exist in table: %s", col, fqName)
+		}
+	}
+
+	// This is done before the backfill, so that the backfilled marker is persisted alongside the description.
+	if err = UpdateColumnComments(cfg, dwh, tableData, tableConfig, fqName); err != nil {
+		return fmt.Errorf("failed to update column comments: %w", err)
+	}
+
+	temporaryTableName := dwh.TemporaryTableName(tableData)
+	if tableData.TopicConfig.PartialUpdate {
+		if err = backfillColumns(cfg, dwh, tableData, tableConfig, fqName, opts); err != nil {
+			return err
+		}
+
+		// Each batch has its own temporary table and merge, so that we only update the columns that the rows carried.
+		batches := tableData.PartialUpdateBatches()
+		for idx, batch := range batches {
+			batchOpts := opts
+			if idx < len(batches)-1 {
+				// The transaction queries should only run once all the batches have been merged.
+				batchOpts.TransactionQueries = nil
+			}
+
+			if err = mergeTemporaryTable(cfg, dwh, batch.TableData, tableConfig, fqName, dwh.TemporaryTableName(batch.TableData), batch.Columns, batchOpts); err != nil {
+				return fmt.Errorf("failed to merge partial update batch: %w", err)
+			}
+		}
+
+		return nil
+	}
+
+	opts, err = prepareTemporaryTable(dwh, tableData, tableConfig, temporaryTableName, opts)
+	if err != nil {
 		return fmt.Errorf("failed to prepare temporary table: %w", err)
 	}
 
@@ -82,7 +128,15 @@ func Merge(dwh destination.DataWarehouse, tableData *optimization.TableData, cfg
 		}
 	}()
 
-	// Now iterate over all the in-memory cols and see which ones require a backfill.
+	if err = backfillColumns(cfg, dwh, tableData, tableConfig, fqName, opts); err != nil {
+		return err
+	}
+
+	return executeMerge(cfg, dwh, tableData, fqName, temporaryTableName, nil, opts)
+}
+
+// backfillColumns will iterate over all the in-memory columns and backfill the ones that require it.
+func backfillColumns(cfg config.Config, dwh destination.DataWarehouse, tableData *optimization.TableData, tableConfig *types.DwhTableConfig, fqName string, opts types.MergeOpts) error {
 	for _, col := range tableData.ReadOnlyInMemoryCols().GetColumns() {
 		if col.ShouldSkip() {
 			continue
@@ -113,21 +167,61 @@ func Merge(dwh destination.DataWarehouse, tableData *optimization.TableData, cfg
 		}
 	}
 
+	return nil
+}
+
+// mergeTemporaryTable will load `tableData` into a temporary table, merge it into the target table and then drop the temporary table.
+func mergeTemporaryTable(cfg config.Config, dwh destination.DataWarehouse, tableData *optimization.TableData, tableConfig *types.DwhTableConfig, fqName string, temporaryTableName string, partialUpdateColumns []string, opts types.MergeOpts) error {
+	opts, err := prepareTemporaryTable(dwh, tableData, tableConfig, temporaryTableName, opts)
+	if err != nil {
+		return fmt.Errorf("failed to prepare temporary table: %w", err)
+	}
+
+	defer func() {
+		if dropErr := ddl.DropTemporaryTable(dwh, temporaryTableName, false); dropErr != nil {
+			slog.Warn("Failed to drop temporary table", slog.Any("err", dropErr), slog.String("tableName", temporaryTableName))
+		}
+	}()
+
+	return executeMerge(cfg, dwh, tableData, fqName, temporaryTableName, partialUpdateColumns, opts)
+}
+
+// prepareTemporaryTable will create and load the temporary table. If the merge is run within a transaction, the destination may defer loading the temporary table
+// so that it's part of the same transaction, the deferred queries are returned in `opts.LoadQueries`.
+func prepareTemporaryTable(dwh destination.DataWarehouse, tableData *optimization.TableData, tableConfig *types.DwhTableConfig, temporaryTableName string, opts types.MergeOpts) (types.MergeOpts, error) {
+	var additionalSettings types.AdditionalSettings
+	var deferredQueries []string
+	if opts.UseTransaction {
+		additionalSettings.DeferredQueries = &deferredQueries
+	}
+
+	if err := dwh.PrepareTemporaryTable(tableData, tableConfig, temporaryTableName, additionalSettings, true); err != nil {
+		return opts, err
+	}
+
+	opts.LoadQueries = deferredQueries
+	return opts, nil
+}
+
+func executeMerge(cfg config.Config, dwh destination.DataWarehouse, tableData *optimization.TableData, fqName string, temporaryTableName string, partialUpdateColumns []string, opts types.MergeOpts) error {
 	subQuery := temporaryTableName
 	if opts.SubQueryDedupe {
 		subQuery = fmt.Sprintf(`( SELECT DISTINCT * FROM %s )`, temporaryTableName)
 	}
 
 	mergeArg := dml.MergeArgument{
-		FqTableName:         fqName,
-		SubQuery:            subQuery,
-		IdempotentKey:       tableData.TopicConfig.IdempotentKey,
-		PrimaryKeys:         tableData.PrimaryKeys(cfg.SharedDestinationConfig.UppercaseEscapedNames, &sql.NameArgs{Escape: true, DestKind: dwh.Label()}),
-		Columns:             tableData.ReadOnlyInMemoryCols(),
-		SoftDelete:          tableData.TopicConfig.SoftDelete,
-		DestKind:            dwh.Label(),
-		UppercaseEscNames:   &cfg.SharedDestinationConfig.UppercaseEscapedNames,
-		ContainsHardDeletes: ptr.ToBool(tableData.ContainsHardDeletes()),
+		FqTableName:          fqName,
+		SubQuery:             subQuery,
+		IdempotentKey:        tableData.TopicConfig.IdempotentKey,
+		PrimaryKeys:          tableData.PrimaryKeys(cfg.SharedDestinationConfig.GetIdentifierCasing(), &sql.NameArgs{Escape: true, DestKind: dwh.Label()}),
+		Columns:              tableData.ReadOnlyInMemoryCols(),
+		SoftDelete:           tableData.TopicConfig.SoftDelete,
+		OmitDeleteColumn:     tableData.TopicConfig.SoftDelete && !tableData.TopicConfig.IncludeDeleteColumn(),
+		DestKind:             dwh.Label(),
+		IdentifierCasing:     cfg.SharedDestinationConfig.GetIdentifierCasing(),
+		ContainsHardDeletes:  ptr.ToBool(tableData.ContainsHardDeletes()),
+		ImmutableColumns:     tableData.TopicConfig.ImmutableColumns,
+		PartialUpdateColumns: partialUpdateColumns,
 	}
 
 	if len(opts.AdditionalEqualityStrings) > 0 {
@@ -140,28 +234,19 @@ func Merge(dwh destination.DataWarehouse, tableData *optimization.TableData, cfg
 			return fmt.Errorf("failed to generate merge statement: %w", err)
 		}
 
-		tx, err := dwh.Begin()
-		if err != nil {
-			return fmt.Errorf("failed to start tx: %w", err)
-		}
-
-		for _, mergeQuery := range mergeParts {
-			if _, err = tx.Exec(mergeQuery); err != nil {
-				return fmt.Errorf("failed to merge, query: %v, err: %w", mergeQuery, err)
-			}
-		}
-
-		if err = tx.Commit(); err != nil {
-			return fmt.Errorf("failed to merge, parts: %v, err: %w", mergeParts, err)
-		}
+		return execInTransaction(dwh, transactionQueries(opts, mergeParts...))
+	}
 
-		return nil
-	} else if dwh.Label() == constants.MSSQL {
+	if dwh.Label() == constants.MSSQL {
 		mergeQuery, err := mergeArg.GetMSSQLStatement()
 		if err != nil {
 			return fmt.Errorf("failed to generate merge statement: %w", err)
 		}
 
+		if len(opts.LoadQueries) > 0 || len(opts.TransactionQueries) > 0 {
+			return execInTransaction(dwh, transactionQueries(opts, mergeQuery))
+		}
+
 		slog.Debug("Executing...", slog.String("query", mergeQuery))
 		_, err = dwh.Exec(mergeQuery)
 		if err != nil {
@@ -169,14 +254,51 @@ func Merge(dwh destination.DataWarehouse, tableData *optimization.TableData, cfg
 		}
 
 		return nil
-	} else {
-		mergeQuery, err := mergeArg.GetStatement()
-		if err != nil {
-			return fmt.Errorf("failed to generate merge statement: %w", err)
+	}
+
+	mergeQuery, err := mergeArg.GetStatement()
+	if err != nil {
+		return fmt.Errorf("failed to generate merge statement: %w", err)
+	}
+
+	if opts.UseTransaction || len(opts.LoadQueries) > 0 || len(opts.TransactionQueries) > 0 {
+		return execInTransaction(dwh, transactionQueries(opts, mergeQuery))
+	}
+
+	slog.Debug("Executing...", slog.String("query", mergeQuery))
+	_, err = dwh.Exec(mergeQuery)
+	return err
+}
+
+// transactionQueries returns the queries to load the temporary table, followed by `mergeQueries` and then the transaction queries.
+func transactionQueries(opts types.MergeOpts, mergeQueries ...string) []string {
+	var queries []string
+	queries = append(queries, opts.LoadQueries...)
+	queries = append(queries, mergeQueries...)
+	return append(queries, opts.TransactionQueries...)
+}
+
+// execInTransaction will run `queries` in a single transaction, if any of them fail, the transaction will be rolled back so the target table is not partially modified.
+func execInTransaction(dwh destination.DataWarehouse, queries []string) error {
+	tx, err := dwh.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start tx: %w", err)
+	}
+
+	for _, query := range queries {
+		slog.Debug("Executing...", slog.String("query", query))
+		if _, err = tx.Exec(query); err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				slog.Warn("Failed to rollback tx", slog.Any("err", rollbackErr))
+			}
+
+			return fmt.Errorf("failed to merge, query: %v, err: %w", query, err)
 		}
+	}
 
-		slog.Debug("Executing...", slog.String("query", mergeQuery))
-		_, err = dwh.Exec(mergeQuery)
-		return err
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to merge, parts: %v, err: %w", queries, err)
 	}
+
+	return nil
 }
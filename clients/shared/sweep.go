@@ -3,7 +3,9 @@ package shared
 import (
 	"fmt"
 	"log/slog"
+	"time"
 
+	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/destination"
 	"github.com/artie-labs/transfer/lib/destination/ddl"
 	"github.com/artie-labs/transfer/lib/kafkalib"
@@ -11,9 +13,27 @@ import (
 
 type GetQueryFunc func(dbAndSchemaPair kafkalib.DatabaseSchemaPair) (string, []any)
 
-func Sweep(dwh destination.DataWarehouse, topicConfigs []*kafkalib.TopicConfig, getQueryFunc GetQueryFunc) error {
-	slog.Info("Looking to see if there are any dangling artie temporary tables to delete...")
-	dbAndSchemaPairs := kafkalib.GetUniqueDatabaseAndSchema(topicConfigs)
+type SweepOpts struct {
+	// TTL is how long after a temporary table was created that it can be dropped, this defaults to [constants.TemporaryTableTTL].
+	TTL time.Duration
+	// BeforeDrop is optional, it's called with each temporary table before it's dropped (e.g. to remove its staged files).
+	BeforeDrop func(fqTableName string) error
+}
+
+// Sweep will drop the dangling temporary tables, `stagingSchema` should be set if the temporary tables are not created alongside the target tables.
+func Sweep(dwh destination.DataWarehouse, topicConfigs []*kafkalib.TopicConfig, stagingSchema string, getQueryFunc GetQueryFunc) error {
+	return SweepWithOpts(dwh, topicConfigs, stagingSchema, getQueryFunc, SweepOpts{})
+}
+
+// SweepWithOpts is the same as [Sweep], but allows the TTL and drop behavior to be overridden.
+func SweepWithOpts(dwh destination.DataWarehouse, topicConfigs []*kafkalib.TopicConfig, stagingSchema string, getQueryFunc GetQueryFunc, opts SweepOpts) error {
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = constants.TemporaryTableTTL
+	}
+
+	slog.Info("Looking to see if there are any dangling artie temporary tables to delete...", slog.Duration("ttl", ttl))
+	dbAndSchemaPairs := kafkalib.GetUniqueStagingDatabaseAndSchema(topicConfigs, stagingSchema)
 	for _, dbAndSchemaPair := range dbAndSchemaPairs {
 		query, args := getQueryFunc(dbAndSchemaPair)
 		rows, err := dwh.Query(query, args...)
@@ -28,8 +48,15 @@ func Sweep(dwh destination.DataWarehouse, topicConfigs []*kafkalib.TopicConfig,
 				return err
 			}
 
-			if ddl.ShouldDeleteFromName(tableName) {
-				err = ddl.DropTemporaryTable(dwh, fmt.Sprintf("%s.%s.%s", dbAndSchemaPair.Database, tableSchema, tableName), true)
+			if ddl.ShouldDeleteFromNameWithTTL(tableName, ttl) {
+				fqTableName := fmt.Sprintf("%s.%s.%s", dbAndSchemaPair.Database, tableSchema, tableName)
+				if opts.BeforeDrop != nil {
+					if err = opts.BeforeDrop(fqTableName); err != nil {
+						return err
+					}
+				}
+
+				err = ddl.DropTemporaryTable(dwh, fqTableName, true)
 				if err != nil {
 					return err
 				}
@@ -28,6 +28,8 @@ type GetTableCfgArgs struct {
 	ColumnDescLabel    string
 	EmptyCommentValue  *string
 	DropDeletedColumns bool
+	// CaseInsensitiveColumns will match our in-memory columns against the existing columns regardless of case.
+	CaseInsensitiveColumns bool
 }
 
 func (g GetTableCfgArgs) ShouldParseComment(comment string) bool {
@@ -47,6 +49,16 @@ func (g GetTableCfgArgs) GetTableConfig() (*types.DwhTableConfig, error) {
 		return tableConfig, nil
 	}
 
+	if cachedCols, isOk := g.ConfigMap.SchemaCache().Get(g.FqName); isOk {
+		tableCfg, err := g.tableConfigFromCache(cachedCols)
+		if err == nil {
+			return tableCfg, nil
+		}
+
+		// Fall through and describe the table instead.
+		slog.Warn("Failed to load table config from the schema cache", slog.Any("err", err), slog.String("fqName", g.FqName))
+	}
+
 	rows, err := g.Dwh.Query(g.Query, g.Args...)
 	defer func() {
 		if rows != nil {
@@ -74,6 +86,8 @@ func (g GetTableCfgArgs) GetTableConfig() (*types.DwhTableConfig, error) {
 	}
 
 	var cols columns.Columns
+	cols.SetCaseInsensitive(g.CaseInsensitiveColumns)
+	var cachedCols []types.CachedColumn
 	for rows != nil && rows.Next() {
 		// figure out what columns were returned
 		// the column names will be the JSON object field keys
@@ -96,12 +110,17 @@ func (g GetTableCfgArgs) GetTableConfig() (*types.DwhTableConfig, error) {
 		}
 
 		row := make(map[string]string)
+		var destinationName string
 		for idx, val := range values {
 			interfaceVal, isOk := val.(*interface{})
 			if !isOk || interfaceVal == nil {
 				return nil, errors.New("invalid value")
 			}
 
+			if columnNameList[idx] == g.ColumnNameLabel {
+				destinationName = fmt.Sprint(*interfaceVal)
+			}
+
 			row[columnNameList[idx]] = strings.ToLower(fmt.Sprint(*interfaceVal))
 		}
 
@@ -111,6 +130,11 @@ func (g GetTableCfgArgs) GetTableConfig() (*types.DwhTableConfig, error) {
 		}
 
 		col := columns.NewColumn(row[g.ColumnNameLabel], kindDetails)
+		if g.CaseInsensitiveColumns {
+			// The column may have been created with a case-sensitive name, so we'll need to refer to it by its actual name.
+			col.SetDestinationName(destinationName)
+		}
+
 		comment, isOk := row[g.ColumnDescLabel]
 		if isOk && g.ShouldParseComment(comment) {
 			// Try to parse the comment.
@@ -120,10 +144,18 @@ func (g GetTableCfgArgs) GetTableConfig() (*types.DwhTableConfig, error) {
 			}
 
 			col.SetBackfilled(_colComment.Backfilled)
-
+			col.SetComment(_colComment.Description)
 		}
 
 		cols.AddColumn(col)
+		cachedCols = append(cachedCols, types.CachedColumn{
+			Name:            col.RawName(),
+			DestinationName: col.DestinationName(),
+			Type:            row[g.ColumnTypeLabel],
+			StringPrecision: row[constants.StrPrecisionCol],
+			Backfilled:      col.Backfilled(),
+			Comment:         col.Comment(),
+		})
 	}
 
 	// Do it this way via rows.Next() because that will move the iterator and cause us to miss a column.
@@ -132,6 +164,57 @@ func (g GetTableCfgArgs) GetTableConfig() (*types.DwhTableConfig, error) {
 	}
 
 	tableCfg := types.NewDwhTableConfig(&cols, nil, tableMissing, g.DropDeletedColumns)
-	g.ConfigMap.AddTableToConfig(g.FqName, tableCfg)
+	if !tableMissing {
+		if err = g.ConfigMap.SchemaCache().Put(g.FqName, cachedCols); err != nil {
+			slog.Warn("Failed to update the schema cache", slog.Any("err", err), slog.String("fqName", g.FqName))
+		}
+	}
+
+	g.addTableToConfig(tableCfg, false)
 	return tableCfg, nil
 }
+
+func (g GetTableCfgArgs) tableConfigFromCache(cachedCols []types.CachedColumn) (*types.DwhTableConfig, error) {
+	var cols columns.Columns
+	cols.SetCaseInsensitive(g.CaseInsensitiveColumns)
+	for _, cachedCol := range cachedCols {
+		kindDetails, err := typing.DwhTypeToKind(g.Dwh.Label(), cachedCol.Type, cachedCol.StringPrecision)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get kind details: %w", err)
+		}
+
+		col := columns.NewColumn(cachedCol.Name, kindDetails)
+		col.SetDestinationName(cachedCol.DestinationName)
+		col.SetBackfilled(cachedCol.Backfilled)
+		col.SetComment(cachedCol.Comment)
+		cols.AddColumn(col)
+	}
+
+	tableCfg := types.NewDwhTableConfig(&cols, nil, false, g.DropDeletedColumns)
+	g.addTableToConfig(tableCfg, true)
+	return tableCfg, nil
+}
+
+func (g GetTableCfgArgs) addTableToConfig(tableCfg *types.DwhTableConfig, fromCache bool) {
+	schemaCache := g.ConfigMap.SchemaCache()
+	if schemaCache != nil {
+		invalidate := func() {
+			if err := schemaCache.Invalidate(g.FqName); err != nil {
+				slog.Warn("Failed to invalidate the schema cache", slog.Any("err", err), slog.String("fqName", g.FqName))
+			}
+		}
+
+		// If we change the schema of this table, the cached copy is no longer valid.
+		tableCfg.SetOnSchemaChange(invalidate)
+		// If a write fails, the cached copy may be out of date (e.g. a column was dropped outside of Transfer).
+		// When the config was loaded from the cache, we'll also drop it so the next attempt describes the table.
+		tableCfg.SetOnWriteFailure(func() {
+			invalidate()
+			if fromCache {
+				g.ConfigMap.RemoveTableFromConfig(g.FqName)
+			}
+		})
+	}
+
+	g.ConfigMap.AddTableToConfig(g.FqName, tableCfg)
+}
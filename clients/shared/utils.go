@@ -3,6 +3,8 @@ package shared
 import (
 	"fmt"
 	"log/slog"
+	"strings"
+	"sync"
 
 	"github.com/artie-labs/transfer/lib/config"
 
@@ -11,9 +13,18 @@ import (
 	"github.com/artie-labs/transfer/lib/config/constants"
 
 	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/ptr"
 	"github.com/artie-labs/transfer/lib/typing/columns"
 )
 
+var (
+	// These are used to warn once that DuckDB will skip the backfill and column comments, since we would otherwise log on every flush.
+	duckDBBackfillWarning sync.Once
+	duckDBCommentsWarning sync.Once
+)
+
 func BackfillColumn(cfg config.Config, dwh destination.DataWarehouse, column columns.Column, fqTableName string) error {
 	if !column.ShouldBackfill() {
 		// If we don't need to backfill, don't backfill.
@@ -25,14 +36,23 @@ func BackfillColumn(cfg config.Config, dwh destination.DataWarehouse, column col
 		return nil
 	}
 
+	if dwh.Label() == constants.DuckDB {
+		// DuckDB does not support column comments, so we would not be able to mark the column as backfilled.
+		// Without the marker, the backfill would run again after a restart and overwrite the NULL values written since.
+		duckDBBackfillWarning.Do(func() {
+			slog.Warn("DuckDB does not support column comments, so default values will not be backfilled", slog.String("colName", column.RawName()), slog.String("table", fqTableName))
+		})
+		return nil
+	}
+
 	additionalDateFmts := cfg.SharedTransferConfig.TypingSettings.AdditionalDateFormats
 	defaultVal, err := column.DefaultValue(&columns.DefaultValueArgs{Escape: true, DestKind: dwh.Label()}, additionalDateFmts)
 	if err != nil {
 		return fmt.Errorf("failed to escape default value: %w", err)
 	}
 
-	uppercaseEscNames := cfg.SharedDestinationConfig.UppercaseEscapedNames
-	escapedCol := column.Name(uppercaseEscNames, &sql.NameArgs{Escape: true, DestKind: dwh.Label()})
+	casing := cfg.SharedDestinationConfig.GetIdentifierCasing()
+	escapedCol := column.Name(casing, &sql.NameArgs{Escape: true, DestKind: dwh.Label()})
 
 	// TODO: This is added because `default` is not technically a column that requires escaping, but it is required when it's in the where clause.
 	// Once we escape everything by default, we can remove this patch of code.
@@ -57,14 +77,65 @@ func BackfillColumn(cfg config.Config, dwh destination.DataWarehouse, column col
 		return fmt.Errorf("failed to backfill, err: %w, query: %v", err, query)
 	}
 
-	query = fmt.Sprintf(`COMMENT ON COLUMN %s.%s IS '%v';`, fqTableName, escapedCol, `{"backfilled": true}`)
-	if dwh.Label() == constants.BigQuery {
-		query = fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET OPTIONS (description=`%s`);",
+	// The source column's description is kept, since the comment is overwritten.
+	_, err = dwh.Exec(columnCommentQuery(dwh.Label(), fqTableName, escapedCol, constants.ColComment{Backfilled: true, Description: column.Comment()}))
+	return err
+}
+
+// columnCommentQuery returns the query to set the comment for `escapedCol`.
+func columnCommentQuery(destKind constants.DestinationKind, fqTableName string, escapedCol string, comment constants.ColComment) string {
+	// Descriptions can contain quotes and the JSON encoding can contain backslashes, so both need to be escaped within the string literal.
+	value := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(comment.String())
+	switch destKind {
+	case constants.BigQuery:
+		// BigQuery doesn't support COMMENT ON COLUMN, so we'll persist the comment in the column's description.
+		// The description needs to be a string literal, BigQuery treats backticks as an identifier.
+		return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET OPTIONS (description='%s');",
 			// ALTER TABLE table ALTER COLUMN col set OPTIONS (description=...)
-			fqTableName, escapedCol, `{"backfilled": true}`,
+			fqTableName, escapedCol, value,
 		)
+	case constants.Databricks:
+		return fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s COMMENT '%s';`, fqTableName, escapedCol, value)
+	default:
+		return fmt.Sprintf(`COMMENT ON COLUMN %s.%s IS '%s';`, fqTableName, escapedCol, value)
 	}
+}
 
-	_, err = dwh.Exec(query)
-	return err
+// UpdateColumnComments will propagate the source column descriptions to the destination, columns whose description has not changed are skipped.
+func UpdateColumnComments(cfg config.Config, dwh destination.DataWarehouse, tableData *optimization.TableData, tableConfig *types.DwhTableConfig, fqTableName string) error {
+	if dwh.Label() == constants.MSSQL {
+		// TODO: Support MSSQL column descriptions, these are set with extended properties.
+		return nil
+	}
+
+	if dwh.Label() == constants.DuckDB {
+		// DuckDB does not support COMMENT ON COLUMN.
+		duckDBCommentsWarning.Do(func() {
+			slog.Warn("DuckDB does not support column comments, so source column descriptions will not be propagated", slog.String("table", fqTableName))
+		})
+		return nil
+	}
+
+	casing := cfg.SharedDestinationConfig.GetIdentifierCasing()
+	for _, col := range tableData.ReadOnlyInMemoryCols().GetColumns() {
+		if col.ShouldSkip() || col.Comment() == "" {
+			continue
+		}
+
+		destCol, isOk := tableConfig.Columns().GetColumn(strings.ToLower(col.RawName()))
+		// Describing a table will lowercase the comment, so we'll compare them case insensitively.
+		if !isOk || strings.EqualFold(destCol.Comment(), col.Comment()) {
+			continue
+		}
+
+		escapedCol := col.Name(casing, &sql.NameArgs{Escape: true, DestKind: dwh.Label()})
+		query := columnCommentQuery(dwh.Label(), fqTableName, escapedCol, constants.ColComment{Backfilled: destCol.Backfilled(), Description: col.Comment()})
+		if _, err := dwh.Exec(query); err != nil {
+			return fmt.Errorf("failed to update comment for col: %s, err: %w", col.RawName(), err)
+		}
+
+		tableConfig.Columns().UpsertColumn(destCol.RawName(), columns.UpsertColumnArg{Comment: ptr.ToString(col.Comment())})
+	}
+
+	return nil
 }
@@ -0,0 +1,25 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+func TestColumnCommentQuery(t *testing.T) {
+	{
+		// Backfilled marker
+		comment := constants.ColComment{Backfilled: true}
+		assert.Equal(t, `COMMENT ON COLUMN db.public.orders.name IS '{"backfilled": true}';`, columnCommentQuery(constants.Snowflake, "db.public.orders", "name", comment))
+		assert.Equal(t, `ALTER TABLE db.public.orders ALTER COLUMN name SET OPTIONS (description='{"backfilled": true}');`, columnCommentQuery(constants.BigQuery, "db.public.orders", "name", comment))
+		assert.Equal(t, `ALTER TABLE db.public.orders ALTER COLUMN name COMMENT '{"backfilled": true}';`, columnCommentQuery(constants.Databricks, "db.public.orders", "name", comment))
+	}
+	{
+		// Description from the source
+		comment := constants.ColComment{Description: `The customer's "preferred" name`}
+		assert.Equal(t, `COMMENT ON COLUMN public.orders.name IS '{"backfilled": false, "description": "The customer\'s \\"preferred\\" name"}';`, columnCommentQuery(constants.Redshift, "public.orders", "name", comment))
+		assert.Equal(t, `ALTER TABLE db.public.orders ALTER COLUMN name SET OPTIONS (description='{"backfilled": false, "description": "The customer\'s \\"preferred\\" name"}');`, columnCommentQuery(constants.BigQuery, "db.public.orders", "name", comment))
+	}
+}
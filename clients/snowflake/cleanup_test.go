@@ -0,0 +1,50 @@
+package snowflake
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/mocks/fakedriver"
+)
+
+func (s *SnowflakeTestSuite) TestCleanupOrphans() {
+	// The table names contain their expiry, which is `constants.TemporaryTableTTL` after they were created.
+	createdAgo := func(d time.Duration) int64 {
+		return time.Now().Add(constants.TemporaryTableTTL - d).Unix()
+	}
+
+	orphan := fmt.Sprintf("orders___artie_abcde_%d", createdAgo(2*time.Hour))
+	fakeDriver := &fakedriver.Driver{QueryRows: [][]string{
+		{"public", orphan},
+		{"public", fmt.Sprintf("customers___artie_fghij_%d", createdAgo(time.Minute))},
+	}}
+	sqlDB, err := fakeDriver.DB()
+	assert.NoError(s.T(), err)
+	rows, err := sqlDB.Query("SELECT")
+	assert.NoError(s.T(), err)
+	s.fakeStageStore.QueryReturns(rows, nil)
+
+	s.stageStore.config = config.Config{
+		Queue: constants.Kafka,
+		Kafka: &config.Kafka{TopicConfigs: []*kafkalib.TopicConfig{{Database: "db", Schema: "public", TableName: "orders"}}},
+	}
+	assert.NoError(s.T(), s.stageStore.CleanupOrphans(time.Hour))
+
+	// Underscores should be escaped so that they're not treated as wildcards.
+	query, args := s.fakeStageStore.QueryArgsForCall(0)
+	assert.Contains(s.T(), query, "FROM\n    db.information_schema.tables")
+	assert.Contains(s.T(), query, `table_name ILIKE ? ESCAPE '\\'`)
+	assert.Equal(s.T(), []any{"public", `%\_\_artie%`}, args)
+
+	// Only the table that is older than the TTL should have been dropped, its staged files are removed first.
+	assert.Equal(s.T(), 2, s.fakeStageStore.ExecCallCount())
+	removeQuery, _ := s.fakeStageStore.ExecArgsForCall(0)
+	assert.Equal(s.T(), "REMOVE @db.public.%"+orphan, removeQuery)
+	dropQuery, _ := s.fakeStageStore.ExecArgsForCall(1)
+	assert.Equal(s.T(), "DROP TABLE IF EXISTS db.public."+orphan, dropQuery)
+}
@@ -2,8 +2,11 @@ package snowflake
 
 import (
 	"fmt"
+	"path/filepath"
 	"time"
 
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/db"
 	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/optimization"
 
@@ -132,3 +135,100 @@ func (s *SnowflakeTestSuite) TestGetTableConfig() {
 	assert.Equal(s.T(), len(tableConfig.Columns().GetColumns()), 0)
 	assert.False(s.T(), tableConfig.DropDeletedColumns())
 }
+
+func (s *SnowflakeTestSuite) TestGetTableConfig_TableNameTemplate() {
+	s.fakeStageStore.QueryReturns(nil, fmt.Errorf("Table 'customers.public.prod_orders' does not exist or not authorized"))
+	tableData := optimization.NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{
+		Database:          "customers",
+		Schema:            "public",
+		TableNameTemplate: "prod_{table}",
+	}, "orders")
+
+	tableConfig, err := s.stageStore.GetTableConfig(tableData)
+	assert.NoError(s.T(), err)
+	assert.True(s.T(), tableConfig.CreateTable())
+
+	describeQuery, _ := s.fakeStageStore.QueryArgsForCall(0)
+	assert.Equal(s.T(), "DESC TABLE customers.public.prod_orders;", describeQuery)
+}
+
+func (s *SnowflakeTestSuite) TestGetTableConfig_SchemaCache() {
+	schemaCachePath := filepath.Join(s.T().TempDir(), "schema.json")
+	tableData := optimization.TableData{
+		TopicConfig: kafkalib.TopicConfig{
+			Database:  "customers",
+			Schema:    "public",
+			TableName: "orders",
+		},
+	}
+
+	fqName := s.stageStore.ToFullyQualifiedName(&tableData, true)
+	schemaCache, err := types.LoadSchemaCache(schemaCachePath)
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), schemaCache.Put(fqName, []types.CachedColumn{
+		{Name: "id", Type: "number(38,0)"},
+		{Name: "name", Type: "varchar(255)", StringPrecision: "255", Backfilled: true},
+	}))
+
+	store := db.Store(s.fakeStageStore)
+	s.stageStore = LoadSnowflake(config.Config{SchemaCachePath: schemaCachePath}, &store)
+
+	tableConfig, err := s.stageStore.GetTableConfig(&tableData)
+	assert.NoError(s.T(), err)
+	// We should not have described the table.
+	assert.Equal(s.T(), 0, s.fakeStageStore.QueryCallCount())
+	assert.False(s.T(), tableConfig.CreateTable())
+
+	idCol, isOk := tableConfig.Columns().GetColumn("id")
+	assert.True(s.T(), isOk)
+	assert.Equal(s.T(), typing.Integer.Kind, idCol.KindDetails.Kind)
+
+	nameCol, isOk := tableConfig.Columns().GetColumn("name")
+	assert.True(s.T(), isOk)
+	assert.Equal(s.T(), typing.String.Kind, nameCol.KindDetails.Kind)
+	assert.True(s.T(), nameCol.Backfilled())
+
+	// Altering the table should invalidate the cache entry.
+	tableConfig.SchemaChanged()
+	_, isOk = s.stageStore.configMap.SchemaCache().Get(fqName)
+	assert.False(s.T(), isOk)
+
+	schemaCache, err = types.LoadSchemaCache(schemaCachePath)
+	assert.NoError(s.T(), err)
+	_, isOk = schemaCache.Get(fqName)
+	assert.False(s.T(), isOk)
+}
+
+func (s *SnowflakeTestSuite) TestGetTableConfig_SchemaCacheWriteFailed() {
+	schemaCachePath := filepath.Join(s.T().TempDir(), "schema.json")
+	tableData := optimization.TableData{
+		TopicConfig: kafkalib.TopicConfig{
+			Database:  "customers",
+			Schema:    "public",
+			TableName: "orders",
+		},
+	}
+
+	fqName := s.stageStore.ToFullyQualifiedName(&tableData, true)
+	schemaCache, err := types.LoadSchemaCache(schemaCachePath)
+	assert.NoError(s.T(), err)
+	assert.NoError(s.T(), schemaCache.Put(fqName, []types.CachedColumn{{Name: "id", Type: "number(38,0)"}}))
+
+	store := db.Store(s.fakeStageStore)
+	s.stageStore = LoadSnowflake(config.Config{SchemaCachePath: schemaCachePath}, &store)
+
+	tableConfig, err := s.stageStore.GetTableConfig(&tableData)
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), 0, s.fakeStageStore.QueryCallCount())
+
+	// A failed write should drop the cache entry and the table config that was loaded from it.
+	tableConfig.WriteFailed()
+	_, isOk := s.stageStore.configMap.SchemaCache().Get(fqName)
+	assert.False(s.T(), isOk)
+	assert.Nil(s.T(), s.stageStore.configMap.TableConfig(fqName))
+
+	schemaCache, err = types.LoadSchemaCache(schemaCachePath)
+	assert.NoError(s.T(), err)
+	_, isOk = schemaCache.Get(fqName)
+	assert.False(s.T(), isOk)
+}
@@ -1,6 +1,23 @@
 package snowflake
 
-import "strings"
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/snowflakedb/gosnowflake"
+
+	"github.com/artie-labs/transfer/lib/destination"
+)
+
+// schemaErrorNumbers are the Snowflake error codes that mean the data does not fit the destination table.
+var schemaErrorNumbers = []int{
+	904,    // Invalid identifier, the column does not exist.
+	100035, // Timestamp is not recognized.
+	100037, // Boolean value is not recognized.
+	100038, // Numeric value is not recognized.
+	100040, // Date is not recognized.
+}
 
 func IsAuthExpiredError(err error) bool {
 	if err == nil {
@@ -9,3 +26,21 @@ func IsAuthExpiredError(err error) bool {
 
 	return strings.Contains(err.Error(), "Authentication token has expired")
 }
+
+func isSchemaError(err error) bool {
+	var snowflakeErr *gosnowflake.SnowflakeError
+	return errors.As(err, &snowflakeErr) && slices.Contains(schemaErrorNumbers, snowflakeErr.Number)
+}
+
+func (s *Store) classifyError(err error) error {
+	if IsAuthExpiredError(err) {
+		// We have already tried to reestablish the connection, so this will need to be looked at.
+		return destination.NewAuthError(err)
+	}
+
+	if isSchemaError(err) {
+		return destination.NewSchemaError(err)
+	}
+
+	return destination.ClassifyError(err, s.IsRetryableError)
+}
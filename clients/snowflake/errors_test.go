@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/snowflakedb/gosnowflake"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/destination"
 )
 
 func TestAuthenticationExpirationErr(t *testing.T) {
@@ -32,3 +35,27 @@ func TestAuthenticationExpirationErr(t *testing.T) {
 		assert.Equal(t, tc.expected, IsAuthExpiredError(tc.err), idx)
 	}
 }
+
+func (s *SnowflakeTestSuite) TestClassifyError() {
+	{
+		// Missing column
+		err := s.stageStore.classifyError(fmt.Errorf("failed to merge: %w", &gosnowflake.SnowflakeError{Number: 904, Message: "SQL compilation error: error line 1 at position 10 invalid identifier 'FOO'"}))
+		assert.Equal(s.T(), "schema", destination.ErrorType(err))
+		assert.ErrorContains(s.T(), err, "invalid identifier 'FOO'")
+	}
+	{
+		// Type mismatch
+		err := s.stageStore.classifyError(&gosnowflake.SnowflakeError{Number: 100038, Message: "Numeric value 'abc' is not recognized"})
+		assert.Equal(s.T(), "schema", destination.ErrorType(err))
+	}
+	{
+		// Auth token expired
+		err := s.stageStore.classifyError(fmt.Errorf("390114: Authentication token has expired.  The user must authenticate again."))
+		assert.Equal(s.T(), "auth", destination.ErrorType(err))
+	}
+	{
+		// Any other Snowflake error
+		err := s.stageStore.classifyError(&gosnowflake.SnowflakeError{Number: 2003, Message: "Object does not exist"})
+		assert.Equal(s.T(), "permanent", destination.ErrorType(err))
+	}
+}
@@ -0,0 +1,104 @@
+package snowflake
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/stringutil"
+)
+
+// offsetsTableName is where we record the Kafka offsets that have been written into each table.
+// This cannot contain the Artie prefix, otherwise it would be dropped by the sweeper.
+const offsetsTableName = "artie_kafka_offsets"
+
+func (s *Store) offsetsTableFqName(tableData *optimization.TableData) string {
+	return fmt.Sprintf("%s.%s.%s", tableData.TopicConfig.Database, stringutil.Override(tableData.TopicConfig.Schema, s.stagingSchema()), offsetsTableName)
+}
+
+func (s *Store) createOffsetsTable(fqName string) error {
+	if _, isOk := s.offsetsTables.Load(fqName); isOk {
+		return nil
+	}
+
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (kafka_topic STRING, kafka_partition INT, table_name STRING, kafka_offset INT, updated_at TIMESTAMP_TZ)", fqName)
+	if _, err := s.Exec(query); err != nil {
+		return fmt.Errorf("failed to create offsets table: %w", err)
+	}
+
+	s.offsetsTables.Store(fqName, true)
+	return nil
+}
+
+// upsertOffsetsQuery returns a query that records `offsets` as the last offsets written into `tableName`.
+func upsertOffsetsQuery(offsetsFqName string, tableName string, offsets []types.KafkaOffset) string {
+	var values []string
+	for _, offset := range offsets {
+		values = append(values, fmt.Sprintf("(%s, %d, %s, %d)", stringutil.Wrap(offset.Topic, false), offset.Partition, stringutil.Wrap(tableName, false), offset.Offset))
+	}
+
+	return fmt.Sprintf(`MERGE INTO %s tgt USING (SELECT column1 AS kafka_topic, column2 AS kafka_partition, column3 AS table_name, column4 AS kafka_offset FROM VALUES %s) src
+ON tgt.kafka_topic = src.kafka_topic AND tgt.kafka_partition = src.kafka_partition AND tgt.table_name = src.table_name
+WHEN MATCHED THEN UPDATE SET kafka_offset = src.kafka_offset, updated_at = CURRENT_TIMESTAMP()
+WHEN NOT MATCHED THEN INSERT (kafka_topic, kafka_partition, table_name, kafka_offset, updated_at) VALUES (src.kafka_topic, src.kafka_partition, src.table_name, src.kafka_offset, CURRENT_TIMESTAMP())`,
+		offsetsFqName, strings.Join(values, ", "))
+}
+
+// offsetsQueries returns the queries that will record `offsets` as written into `tableData`, this will create the offsets table if needed.
+func (s *Store) offsetsQueries(tableData *optimization.TableData, offsets []types.KafkaOffset) ([]string, error) {
+	if len(offsets) == 0 {
+		return nil, nil
+	}
+
+	offsetsFqName := s.offsetsTableFqName(tableData)
+	if err := s.createOffsetsTable(offsetsFqName); err != nil {
+		return nil, err
+	}
+
+	return []string{upsertOffsetsQuery(offsetsFqName, s.ToFullyQualifiedName(tableData, false), offsets)}, nil
+}
+
+// MergeWithOffsets will merge `tableData` and record `offsets` within the same transaction.
+// If the transaction is aborted, neither the rows nor the offsets will be written.
+func (s *Store) MergeWithOffsets(tableData *optimization.TableData, offsets []types.KafkaOffset) error {
+	return s.merge(tableData, offsets)
+}
+
+func (s *Store) WrittenOffsets(tableData *optimization.TableData) (map[int]int64, error) {
+	var offsets map[int]int64
+	err := s.withSession(tableData.TopicConfig, func(store *Store) error {
+		var err error
+		offsets, err = store.writtenOffsets(tableData)
+		return err
+	})
+	return offsets, err
+}
+
+func (s *Store) writtenOffsets(tableData *optimization.TableData) (map[int]int64, error) {
+	offsetsFqName := s.offsetsTableFqName(tableData)
+	if err := s.createOffsetsTable(offsetsFqName); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.Query(fmt.Sprintf("SELECT kafka_partition, kafka_offset FROM %s WHERE kafka_topic = ? AND table_name = ?", offsetsFqName),
+		tableData.TopicConfig.Topic, s.ToFullyQualifiedName(tableData, false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query offsets: %w", err)
+	}
+
+	defer rows.Close()
+
+	offsets := make(map[int]int64)
+	for rows.Next() {
+		var partition int
+		var offset int64
+		if err = rows.Scan(&partition, &offset); err != nil {
+			return nil, fmt.Errorf("failed to scan offsets: %w", err)
+		}
+
+		offsets[partition] = offset
+	}
+
+	return offsets, rows.Err()
+}
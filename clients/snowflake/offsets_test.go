@@ -0,0 +1,109 @@
+package snowflake
+
+import (
+	"fmt"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/mocks/fakedriver"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func (s *SnowflakeTestSuite) TestMergeWithOffsets() {
+	newTableData := func() *optimization.TableData {
+		var cols columns.Columns
+		cols.AddColumn(columns.NewColumn("id", typing.Integer))
+		cols.AddColumn(columns.NewColumn("name", typing.String))
+		cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+
+		topicConfig := kafkalib.TopicConfig{Database: "customer", TableName: "orders", Schema: "public", Topic: "dbserver1.public.orders"}
+		tableData := optimization.NewTableData(&cols, config.Replication, []string{"id"}, topicConfig, "orders")
+		tableData.ResetTempTableSuffix()
+		for i := 0; i < 3; i++ {
+			tableData.InsertRow(fmt.Sprintf("pk-%d", i), map[string]any{"id": i, "name": fmt.Sprintf("Robin-%d", i)}, false)
+		}
+
+		s.stageStore.configMap.AddTableToConfig(s.stageStore.ToFullyQualifiedName(tableData, true), types.NewDwhTableConfig(&cols, nil, false, true))
+		return tableData
+	}
+
+	offsets := []types.KafkaOffset{
+		{Topic: "dbserver1.public.orders", Partition: 0, Offset: 10},
+		{Topic: "dbserver1.public.orders", Partition: 2, Offset: 42},
+	}
+
+	{
+		// The merge and the offsets are committed together.
+		fakeDriver := &fakedriver.Driver{}
+		tx, err := newTx(fakeDriver)
+		assert.NoError(s.T(), err)
+		s.fakeStageStore.BeginReturns(tx, nil)
+
+		assert.NoError(s.T(), s.stageStore.MergeWithOffsets(newTableData(), offsets))
+		assert.Equal(s.T(), 1, s.fakeStageStore.BeginCallCount())
+		assert.Empty(s.T(), fakeDriver.RolledBack())
+		assert.Len(s.T(), fakeDriver.Committed(), 2)
+		assert.Contains(s.T(), fakeDriver.Committed()[0], "MERGE INTO customer.public.orders")
+		assert.Contains(s.T(), fakeDriver.Committed()[1], "MERGE INTO customer.public.artie_kafka_offsets")
+		assert.Contains(s.T(), fakeDriver.Committed()[1], "VALUES ('dbserver1.public.orders', 0, 'customer.public.orders', 10), ('dbserver1.public.orders', 2, 'customer.public.orders', 42)")
+
+		// The offsets table is created outside the transaction (CREATE offsets, CREATE, PUT, COPY and DROP).
+		assert.Equal(s.T(), 5, s.fakeStageStore.ExecCallCount())
+		createQuery, _ := s.fakeStageStore.ExecArgsForCall(0)
+		assert.Contains(s.T(), createQuery, "CREATE TABLE IF NOT EXISTS customer.public.artie_kafka_offsets")
+
+		// The offsets table should only be created once.
+		tx, err = newTx(&fakedriver.Driver{})
+		assert.NoError(s.T(), err)
+		s.fakeStageStore.BeginReturns(tx, nil)
+		assert.NoError(s.T(), s.stageStore.MergeWithOffsets(newTableData(), offsets))
+		assert.Equal(s.T(), 9, s.fakeStageStore.ExecCallCount())
+	}
+
+	s.ResetStore()
+	{
+		// The offsets cannot be written, so the merge is rolled back as well.
+		fakeDriver := &fakedriver.Driver{FailOn: "artie_kafka_offsets"}
+		tx, err := newTx(fakeDriver)
+		assert.NoError(s.T(), err)
+		s.fakeStageStore.BeginReturns(tx, nil)
+
+		assert.ErrorContains(s.T(), s.stageStore.MergeWithOffsets(newTableData(), offsets), "statement failed")
+		assert.Empty(s.T(), fakeDriver.Committed())
+		assert.Len(s.T(), fakeDriver.RolledBack(), 2)
+	}
+
+	s.ResetStore()
+	{
+		// The merge is aborted, so the offsets are never written.
+		fakeDriver := &fakedriver.Driver{FailOn: "MERGE INTO customer.public.orders"}
+		tx, err := newTx(fakeDriver)
+		assert.NoError(s.T(), err)
+		s.fakeStageStore.BeginReturns(tx, nil)
+
+		assert.ErrorContains(s.T(), s.stageStore.MergeWithOffsets(newTableData(), offsets), "statement failed")
+		assert.Empty(s.T(), fakeDriver.Committed())
+		assert.Len(s.T(), fakeDriver.RolledBack(), 1)
+		assert.NotContains(s.T(), fakeDriver.RolledBack()[0], "artie_kafka_offsets")
+	}
+
+	s.ResetStore()
+	s.stageStore.config.Snowflake = &config.Snowflake{StagingSchema: "staging"}
+	{
+		// The offsets table lives in the staging schema if one is configured.
+		fakeDriver := &fakedriver.Driver{}
+		tx, err := newTx(fakeDriver)
+		assert.NoError(s.T(), err)
+		s.fakeStageStore.BeginReturns(tx, nil)
+
+		assert.NoError(s.T(), s.stageStore.MergeWithOffsets(newTableData(), offsets))
+		assert.Len(s.T(), fakeDriver.Committed(), 2)
+		assert.Contains(s.T(), fakeDriver.Committed()[1], "MERGE INTO customer.staging.artie_kafka_offsets")
+	}
+}
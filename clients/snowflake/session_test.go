@@ -0,0 +1,155 @@
+package snowflake
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/mocks/fakedriver"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func (s *SnowflakeTestSuite) TestDsnConfig() {
+	store := LoadSnowflake(config.Config{Snowflake: &config.Snowflake{Warehouse: "compute_wh", Role: "loader"}}, &s.stageStore.Store)
+	assert.Equal(s.T(), "compute_wh", store.dsnConfig().Warehouse)
+	assert.Equal(s.T(), "loader", store.dsnConfig().Role)
+}
+
+func TestSessionStatements(t *testing.T) {
+	assert.Empty(t, sessionStatements(kafkalib.TopicConfig{}))
+	assert.Equal(t, []string{"USE WAREHOUSE large_wh"}, sessionStatements(kafkalib.TopicConfig{Warehouse: ptr.ToString("large_wh")}))
+	assert.Equal(t, []string{"USE ROLE transformer"}, sessionStatements(kafkalib.TopicConfig{Role: ptr.ToString("transformer")}))
+	assert.Equal(t, []string{"USE ROLE transformer", "USE WAREHOUSE large_wh"},
+		sessionStatements(kafkalib.TopicConfig{Warehouse: ptr.ToString("large_wh"), Role: ptr.ToString("transformer")}))
+}
+
+func (s *SnowflakeTestSuite) TestMergeWithSessionOverrides() {
+	newTableData := func(topicConfig kafkalib.TopicConfig) *optimization.TableData {
+		var cols columns.Columns
+		cols.AddColumn(columns.NewColumn("id", typing.Integer))
+		cols.AddColumn(columns.NewColumn("name", typing.String))
+		cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+
+		tableData := optimization.NewTableData(&cols, config.Replication, []string{"id"}, topicConfig, "orders")
+		tableData.ResetTempTableSuffix()
+		for i := 0; i < 3; i++ {
+			tableData.InsertRow(fmt.Sprintf("pk-%d", i), map[string]any{"id": i, "name": fmt.Sprintf("Robin-%d", i)}, false)
+		}
+
+		s.stageStore.configMap.AddTableToConfig(s.stageStore.ToFullyQualifiedName(tableData, true), types.NewDwhTableConfig(&cols, nil, false, true))
+		return tableData
+	}
+
+	newConn := func(fakeDriver *fakedriver.Driver) *sql.Conn {
+		sqlDB, err := fakeDriver.DB()
+		assert.NoError(s.T(), err)
+		conn, err := sqlDB.Conn(context.Background())
+		assert.NoError(s.T(), err)
+		return conn
+	}
+
+	topicConfig := kafkalib.TopicConfig{Database: "customer", TableName: "orders", Schema: "public"}
+	{
+		// No overrides, the merge is run on the pool.
+		assert.NoError(s.T(), s.stageStore.Merge(newTableData(topicConfig)))
+		assert.Equal(s.T(), 0, s.fakeStageStore.ConnCallCount())
+		assert.Equal(s.T(), 5, s.fakeStageStore.ExecCallCount())
+	}
+
+	s.ResetStore()
+	{
+		// Warehouse and role overrides, the role is set first and the merge is run on the same connection.
+		fakeDriver := &fakedriver.Driver{}
+		s.fakeStageStore.ConnReturns(newConn(fakeDriver), nil)
+
+		overrides := topicConfig
+		overrides.Warehouse = ptr.ToString("large_wh")
+		overrides.Role = ptr.ToString("transformer")
+		assert.NoError(s.T(), s.stageStore.Merge(newTableData(overrides)))
+		assert.Equal(s.T(), 1, s.fakeStageStore.ConnCallCount())
+		assert.Equal(s.T(), 0, s.fakeStageStore.ExecCallCount())
+
+		execs := fakeDriver.Execs()
+		assert.Len(s.T(), execs, 7)
+		assert.Equal(s.T(), "USE ROLE transformer", execs[0])
+		assert.Equal(s.T(), "USE WAREHOUSE large_wh", execs[1])
+		assert.Contains(s.T(), execs[2], "CREATE TABLE IF NOT EXISTS customer.public.orders___artie_")
+		assert.Contains(s.T(), execs[3], "PUT file://")
+		assert.Contains(s.T(), execs[4], "COPY INTO customer.public.orders___artie_")
+		assert.Contains(s.T(), execs[5], "MERGE INTO customer.public.orders")
+		assert.Contains(s.T(), execs[6], "DROP TABLE IF EXISTS customer.public.orders___artie_")
+
+		// The connection is discarded instead of being returned to the pool.
+		assert.Equal(s.T(), 1, fakeDriver.Closed())
+	}
+
+	s.ResetStore()
+	{
+		// Only a warehouse override
+		fakeDriver := &fakedriver.Driver{}
+		s.fakeStageStore.ConnReturns(newConn(fakeDriver), nil)
+
+		overrides := topicConfig
+		overrides.Warehouse = ptr.ToString("large_wh")
+		assert.NoError(s.T(), s.stageStore.Merge(newTableData(overrides)))
+		assert.Equal(s.T(), "USE WAREHOUSE large_wh", fakeDriver.Execs()[0])
+		assert.Contains(s.T(), fakeDriver.Execs()[1], "CREATE TABLE IF NOT EXISTS customer.public.orders___artie_")
+		assert.Equal(s.T(), 1, fakeDriver.Closed())
+	}
+
+	s.ResetStore()
+	{
+		// The warehouse cannot be used, so the merge is never run.
+		fakeDriver := &fakedriver.Driver{FailOn: "USE WAREHOUSE"}
+		s.fakeStageStore.ConnReturns(newConn(fakeDriver), nil)
+
+		overrides := topicConfig
+		overrides.Warehouse = ptr.ToString("large_wh")
+		overrides.Role = ptr.ToString("transformer")
+		assert.ErrorContains(s.T(), s.stageStore.Merge(newTableData(overrides)), `failed to run "USE WAREHOUSE large_wh"`)
+		assert.Equal(s.T(), []string{"USE ROLE transformer", "USE WAREHOUSE large_wh"}, fakeDriver.Execs())
+		assert.Equal(s.T(), 1, fakeDriver.Closed())
+	}
+}
+
+func (s *SnowflakeTestSuite) TestTransport() {
+	{
+		// No TLS settings, the driver's default transport should be used.
+		store := LoadSnowflake(config.Config{Snowflake: &config.Snowflake{}}, &s.stageStore.Store)
+		transport, err := store.transport()
+		assert.NoError(s.T(), err)
+		assert.Nil(s.T(), transport)
+	}
+	{
+		// CA bundle and client certificate
+		store := LoadSnowflake(config.Config{Snowflake: &config.Snowflake{DriverTLS: config.DriverTLS{
+			CABundlePath:   "../../lib/config/testdata/ca.pem",
+			ClientCertPath: "../../lib/config/testdata/client.pem",
+			ClientKeyPath:  "../../lib/config/testdata/client-key.pem",
+		}}}, &s.stageStore.Store)
+		transport, err := store.transport()
+		assert.NoError(s.T(), err)
+
+		httpTransport, isOk := transport.(*http.Transport)
+		assert.True(s.T(), isOk)
+		assert.NotNil(s.T(), httpTransport.TLSClientConfig.RootCAs)
+		assert.Len(s.T(), httpTransport.TLSClientConfig.Certificates, 1)
+	}
+	{
+		// Invalid CA bundle
+		store := LoadSnowflake(config.Config{Snowflake: &config.Snowflake{DriverTLS: config.DriverTLS{CABundlePath: "does-not-exist.pem"}}}, &s.stageStore.Store)
+		_, err := store.transport()
+		assert.ErrorContains(s.T(), err, "failed to read ca bundle")
+	}
+}
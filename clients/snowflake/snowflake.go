@@ -1,8 +1,13 @@
 package snowflake
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/snowflakedb/gosnowflake"
 
@@ -24,6 +29,9 @@ type Store struct {
 	testDB    bool // Used for testing
 	configMap *types.DwhToTablesConfigMap
 	config    config.Config
+
+	// offsetsTables contains the offsets tables that we have already created, see createOffsetsTable.
+	offsetsTables *sync.Map
 }
 
 const (
@@ -34,7 +42,22 @@ const (
 )
 
 func (s *Store) ToFullyQualifiedName(tableData *optimization.TableData, escape bool) string {
-	return tableData.ToFqName(s.Label(), escape, s.config.SharedDestinationConfig.UppercaseEscapedNames, optimization.FqNameOpts{})
+	return tableData.ToFqName(s.Label(), escape, s.config.SharedDestinationConfig.GetIdentifierCasing(), optimization.FqNameOpts{})
+}
+
+// TemporaryTableName returns the name of the temporary table for `tableData`, if a staging schema is configured the temporary table and its table stage will be created there.
+func (s *Store) TemporaryTableName(tableData *optimization.TableData) string {
+	return tableData.TemporaryTableName(s.Label(), optimization.FqNameOpts{
+		StagingSchema: s.stagingSchema(),
+	})
+}
+
+func (s *Store) stagingSchema() string {
+	if s.config.Snowflake == nil {
+		return ""
+	}
+
+	return s.config.Snowflake.StagingSchema
 }
 
 func (s *Store) GetTableConfig(tableData *optimization.TableData) (*types.DwhTableConfig, error) {
@@ -49,26 +72,50 @@ func (s *Store) GetTableConfig(tableData *optimization.TableData) (*types.DwhTab
 		ColumnDescLabel:    describeCommentCol,
 		EmptyCommentValue:  ptr.ToString("<nil>"),
 		DropDeletedColumns: tableData.TopicConfig.DropDeletedColumns,
+
+		CaseInsensitiveColumns: s.config.SharedDestinationConfig.CaseInsensitiveColumns,
 	}.GetTableConfig()
 }
 
 func (s *Store) Sweep() error {
+	ttl := constants.TemporaryTableTTL
+	if s.config.Snowflake != nil {
+		ttl = s.config.Snowflake.OrphanTTL()
+	}
+
+	return s.CleanupOrphans(ttl)
+}
+
+// CleanupOrphans will drop any temporary tables (and the files in their table stage) that were created more than `ttl` ago.
+// These are left behind if Transfer crashes in the middle of a flush, after the temporary table was created but before it was dropped.
+func (s *Store) CleanupOrphans(ttl time.Duration) error {
 	tcs, err := s.config.TopicConfigs()
 	if err != nil {
 		return err
 	}
 
 	queryFunc := func(dbAndSchemaPair kafkalib.DatabaseSchemaPair) (string, []any) {
+		// Underscores are single character wildcards, so they need to be escaped. Otherwise, we would also match tables like `COUNTERPARTIES`.
 		return fmt.Sprintf(`
 SELECT
     table_schema, table_name
 FROM
     %s.information_schema.tables
 WHERE
-    UPPER(table_schema) = UPPER(?) AND table_name ILIKE ?`, dbAndSchemaPair.Database), []any{dbAndSchemaPair.Schema, "%" + constants.ArtiePrefix + "%"}
+    UPPER(table_schema) = UPPER(?) AND table_name ILIKE ? ESCAPE '\\'`, dbAndSchemaPair.Database), []any{dbAndSchemaPair.Schema, "%" + strings.ReplaceAll(constants.ArtiePrefix, "_", `\_`) + "%"}
 	}
 
-	return shared.Sweep(s, tcs, queryFunc)
+	return shared.SweepWithOpts(s, tcs, s.stagingSchema(), queryFunc, shared.SweepOpts{
+		TTL: ttl,
+		BeforeDrop: func(fqTableName string) error {
+			// Dropping the table will also drop its table stage, but we'll remove the files first so that they're not left behind if the drop fails.
+			if _, err := s.Exec(fmt.Sprintf("REMOVE @%s", addPrefixToTableName(fqTableName, "%"))); err != nil {
+				return fmt.Errorf("failed to remove staged files for %q: %w", fqTableName, err)
+			}
+
+			return nil
+		},
+	})
 }
 
 func (s *Store) Label() constants.DestinationKind {
@@ -83,17 +130,13 @@ func (s *Store) GetConfigMap() *types.DwhToTablesConfigMap {
 	return s.configMap
 }
 
-func (s *Store) reestablishConnection() {
-	if s.testDB {
-		// Don't actually re-establish for tests.
-		return
-	}
-
+func (s *Store) dsnConfig() *gosnowflake.Config {
 	cfg := &gosnowflake.Config{
 		Account:     s.config.Snowflake.AccountID,
 		User:        s.config.Snowflake.Username,
 		Password:    s.config.Snowflake.Password,
 		Warehouse:   s.config.Snowflake.Warehouse,
+		Role:        s.config.Snowflake.Role,
 		Region:      s.config.Snowflake.Region,
 		Application: s.config.Snowflake.Application,
 	}
@@ -104,12 +147,93 @@ func (s *Store) reestablishConnection() {
 		cfg.Region = ""
 	}
 
-	dsn, err := gosnowflake.DSN(cfg)
+	return cfg
+}
+
+// sessionStatements returns the statements that will switch a session over to the topic's role and warehouse overrides.
+// The role is set first, since it determines which warehouses can be used.
+func sessionStatements(tc kafkalib.TopicConfig) []string {
+	var statements []string
+	if tc.Role != nil {
+		statements = append(statements, fmt.Sprintf("USE ROLE %s", *tc.Role))
+	}
+
+	if tc.Warehouse != nil {
+		statements = append(statements, fmt.Sprintf("USE WAREHOUSE %s", *tc.Warehouse))
+	}
+
+	return statements
+}
+
+// withSession will call `fn` with a store that is using the topic's warehouse and role overrides, if there are any.
+// `USE WAREHOUSE` and `USE ROLE` are scoped to the session, so we'll pin a single connection from the pool for the flush and discard it afterwards,
+// this way the overrides cannot leak into concurrent flushes for other tables.
+func (s *Store) withSession(tc kafkalib.TopicConfig, fn func(store *Store) error) error {
+	statements := sessionStatements(tc)
+	if len(statements) == 0 {
+		return fn(s)
+	}
+
+	ctx := context.Background()
+	conn, err := s.Store.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get a connection: %w", err)
+	}
+
+	defer db.DiscardConn(conn)
+	for _, statement := range statements {
+		if _, err = conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to run %q: %w", statement, err)
+		}
+	}
+
+	return fn(&Store{
+		Store:         db.NewConnStore(conn),
+		testDB:        s.testDB,
+		configMap:     s.configMap,
+		config:        s.config,
+		offsetsTables: s.offsetsTables,
+	})
+}
+
+func (s *Store) reestablishConnection() {
+	if s.testDB {
+		// Don't actually re-establish for tests.
+		return
+	}
+
+	transport, err := s.transport()
+	if err != nil {
+		logger.Panic("Failed to load snowflake tls settings", slog.Any("err", err))
+	}
+
+	if transport != nil {
+		// The transport cannot be passed through the DSN, so we'll need to hand the config to the driver directly.
+		dsnConfig := s.dsnConfig()
+		dsnConfig.Transporter = transport
+		s.Store = db.OpenConnector("snowflake", gosnowflake.NewConnector(gosnowflake.SnowflakeDriver{}, *dsnConfig), s.config.Snowflake.ConnectionPool)
+		return
+	}
+
+	dsn, err := gosnowflake.DSN(s.dsnConfig())
 	if err != nil {
 		logger.Panic("Failed to get snowflake dsn", slog.Any("err", err))
 	}
 
-	s.Store = db.Open("snowflake", dsn)
+	s.Store = db.Open("snowflake", dsn, s.config.Snowflake.ConnectionPool)
+}
+
+// transport returns the HTTP transport that the driver should use if a CA bundle or client certificate has been configured.
+// This returns nil otherwise, so that the driver's default transport (which also checks OCSP) is used.
+func (s *Store) transport() (http.RoundTripper, error) {
+	tlsConfig, err := s.config.Snowflake.TLSConfig()
+	if err != nil || tlsConfig == nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
 }
 
 func (s *Store) Dedupe(fqTableName string) error {
@@ -121,17 +245,19 @@ func LoadSnowflake(cfg config.Config, _store *db.Store) *Store {
 	if _store != nil {
 		// Used for tests.
 		return &Store{
-			testDB:    true,
-			configMap: &types.DwhToTablesConfigMap{},
-			config:    cfg,
+			testDB:        true,
+			configMap:     types.NewDwhToTablesConfigMap(cfg.SchemaCachePath),
+			config:        cfg,
+			offsetsTables: &sync.Map{},
 
 			Store: *_store,
 		}
 	}
 
 	s := &Store{
-		configMap: &types.DwhToTablesConfigMap{},
-		config:    cfg,
+		configMap:     types.NewDwhToTablesConfigMap(cfg.SchemaCachePath),
+		config:        cfg,
+		offsetsTables: &sync.Map{},
 	}
 
 	s.reestablishConnection()
@@ -66,7 +66,7 @@ func (s *SnowflakeTestSuite) TestExecuteMergeNilEdgeCase() {
 		anotherCols.AddColumn(columns.NewColumn(colName, kindDetails))
 	}
 
-	s.stageStore.configMap.AddTableToConfig(tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.UppercaseEscapedNames, optimization.FqNameOpts{}),
+	s.stageStore.configMap.AddTableToConfig(tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.GetIdentifierCasing(), optimization.FqNameOpts{}),
 		types.NewDwhTableConfig(&anotherCols, nil, false, true))
 
 	err := s.stageStore.Merge(tableData)
@@ -112,7 +112,7 @@ func (s *SnowflakeTestSuite) TestExecuteMergeReestablishAuth() {
 		tableData.InsertRow(pk, row, false)
 	}
 
-	s.stageStore.configMap.AddTableToConfig(tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.UppercaseEscapedNames, optimization.FqNameOpts{}),
+	s.stageStore.configMap.AddTableToConfig(tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.GetIdentifierCasing(), optimization.FqNameOpts{}),
 		types.NewDwhTableConfig(&cols, nil, false, true))
 
 	s.fakeStageStore.ExecReturnsOnCall(0, nil, fmt.Errorf("390114: Authentication token has expired. The user must authenticate again."))
@@ -164,7 +164,7 @@ func (s *SnowflakeTestSuite) TestExecuteMerge() {
 
 	var idx int
 
-	fqName := tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.UppercaseEscapedNames, optimization.FqNameOpts{})
+	fqName := tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.GetIdentifierCasing(), optimization.FqNameOpts{})
 	s.stageStore.configMap.AddTableToConfig(fqName, types.NewDwhTableConfig(&cols, nil, false, true))
 	err := s.stageStore.Merge(tableData)
 	assert.Nil(s.T(), err)
@@ -193,6 +193,175 @@ func (s *SnowflakeTestSuite) TestExecuteMerge() {
 	assert.Equal(s.T(), 5, s.fakeStageStore.ExecCallCount(), "called merge")
 }
 
+func (s *SnowflakeTestSuite) TestExecuteMergeCaseInsensitive() {
+	var cols columns.Columns
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("email", typing.String))
+	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+
+	tableData := optimization.NewTableData(&cols, config.Replication, []string{"id"}, kafkalib.TopicConfig{Database: "customer", TableName: "orders", Schema: "public"}, "orders")
+	tableData.ResetTempTableSuffix()
+	tableData.InsertRow("1", map[string]any{"id": 1, "email": "robin@example.com", constants.DeleteColumnMarker: false}, false)
+
+	// The table was created outside of Transfer with case-sensitive column names.
+	var existingCols columns.Columns
+	existingCols.SetCaseInsensitive(true)
+	for _, col := range []struct {
+		name            string
+		destinationName string
+		kd              typing.KindDetails
+	}{
+		{name: "id", destinationName: "ID", kd: typing.Integer},
+		{name: "email", destinationName: "Email", kd: typing.String},
+		{name: constants.DeleteColumnMarker, kd: typing.Boolean},
+	} {
+		existingCol := columns.NewColumn(col.name, col.kd)
+		existingCol.SetDestinationName(col.destinationName)
+		existingCols.AddColumn(existingCol)
+	}
+
+	fqName := s.stageStore.ToFullyQualifiedName(tableData, true)
+	s.stageStore.configMap.AddTableToConfig(fqName, types.NewDwhTableConfig(&existingCols, nil, false, true))
+	assert.NoError(s.T(), s.stageStore.Merge(tableData))
+
+	// We should not have added any columns and the merge should refer to the existing columns by their names.
+	assert.Equal(s.T(), 5, s.fakeStageStore.ExecCallCount())
+	mergeQuery, _ := s.fakeStageStore.ExecArgsForCall(3)
+	assert.Contains(s.T(), mergeQuery, `c."ID" = cc."ID"`, mergeQuery)
+	assert.Contains(s.T(), mergeQuery, `"Email"=cc."Email"`, mergeQuery)
+	assert.NotContains(s.T(), mergeQuery, "cc.email", mergeQuery)
+}
+
+func (s *SnowflakeTestSuite) TestExecuteMergeTableNameTemplate() {
+	var cols columns.Columns
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("name", typing.String))
+	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+
+	topicConfig := kafkalib.TopicConfig{
+		Database:          "customer",
+		Schema:            "public",
+		TableNameTemplate: "{schema}__{table}",
+	}
+
+	tableData := optimization.NewTableData(&cols, config.Replication, []string{"id"}, topicConfig, "orders")
+	tableData.ResetTempTableSuffix()
+	tableData.InsertRow("1", map[string]any{"id": 1, "name": "dusty"}, false)
+
+	fqName := s.stageStore.ToFullyQualifiedName(tableData, true)
+	assert.Equal(s.T(), "customer.public.public__orders", fqName)
+	s.stageStore.configMap.AddTableToConfig(fqName, types.NewDwhTableConfig(&cols, nil, false, true))
+	assert.NoError(s.T(), s.stageStore.Merge(tableData))
+
+	mergeQuery, _ := s.fakeStageStore.ExecArgsForCall(3)
+	assert.Contains(s.T(), mergeQuery, "MERGE INTO customer.public.public__orders", mergeQuery)
+}
+
+func (s *SnowflakeTestSuite) TestExecuteMergeStagingSchema() {
+	s.stageStore.config.Snowflake = &config.Snowflake{StagingSchema: "staging"}
+
+	var cols columns.Columns
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("name", typing.String))
+	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+
+	topicConfig := kafkalib.TopicConfig{Database: "customer", Schema: "public", TableName: "orders"}
+	tableData := optimization.NewTableData(&cols, config.Replication, []string{"id"}, topicConfig, "orders")
+	tableData.ResetTempTableSuffix()
+	tableData.InsertRow("1", map[string]any{"id": 1, "name": "dusty"}, false)
+
+	fqName := s.stageStore.ToFullyQualifiedName(tableData, true)
+	assert.Equal(s.T(), "customer.public.orders", fqName)
+	s.stageStore.configMap.AddTableToConfig(fqName, types.NewDwhTableConfig(&cols, nil, false, true))
+	assert.NoError(s.T(), s.stageStore.Merge(tableData))
+	assert.Equal(s.T(), 5, s.fakeStageStore.ExecCallCount())
+
+	// The temporary table (and its table stage) should be created under the staging schema.
+	createQuery, _ := s.fakeStageStore.ExecArgsForCall(0)
+	assert.Contains(s.T(), createQuery, "CREATE TABLE IF NOT EXISTS customer.staging.orders___artie_", createQuery)
+
+	putQuery, _ := s.fakeStageStore.ExecArgsForCall(1)
+	assert.Contains(s.T(), putQuery, "@customer.staging.%orders___artie_", putQuery)
+
+	copyQuery, _ := s.fakeStageStore.ExecArgsForCall(2)
+	assert.Contains(s.T(), copyQuery, "COPY INTO customer.staging.orders___artie_", copyQuery)
+
+	// The merge should target the real table and join against the staging table.
+	mergeQuery, _ := s.fakeStageStore.ExecArgsForCall(3)
+	assert.Contains(s.T(), mergeQuery, "MERGE INTO customer.public.orders c USING ( customer.staging.orders___artie_", mergeQuery)
+
+	dropQuery, _ := s.fakeStageStore.ExecArgsForCall(4)
+	assert.Contains(s.T(), dropQuery, "DROP TABLE IF EXISTS customer.staging.orders___artie_", dropQuery)
+}
+
+func (s *SnowflakeTestSuite) TestExecuteMergePartialUpdate() {
+	var cols columns.Columns
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("name", typing.String))
+	cols.AddColumn(columns.NewColumn("email", typing.String))
+	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+
+	topicConfig := kafkalib.TopicConfig{Database: "customer", Schema: "public", TableName: "users", PartialUpdate: true}
+	tableData := optimization.NewTableData(&cols, config.Replication, []string{"id"}, topicConfig, "users")
+	tableData.ResetTempTableSuffix()
+	tableData.InsertRow("1", map[string]any{"id": 1, "name": "dusty", "email": "dusty@example.com"}, false)
+	// This event only carried the email column.
+	tableData.InsertRow("2", map[string]any{"id": 2, "email": "robin@example.com"}, false)
+	s.stageStore.configMap.AddTableToConfig(s.stageStore.ToFullyQualifiedName(tableData, true), types.NewDwhTableConfig(&cols, nil, false, true))
+	assert.NoError(s.T(), s.stageStore.Merge(tableData))
+
+	// Each batch is loaded into its own temporary table and merged separately (CREATE, PUT, COPY, MERGE and DROP).
+	assert.Equal(s.T(), 10, s.fakeStageStore.ExecCallCount())
+	for idx, execIdx := range []int{0, 5} {
+		createQuery, _ := s.fakeStageStore.ExecArgsForCall(execIdx)
+		// The batch index comes before the expiry, so the sweeper can still parse it.
+		assert.Regexp(s.T(), fmt.Sprintf(`users___artie_[a-z0-9]{5}_%d_\d{10} `, idx), createQuery)
+	}
+	{
+		// The row that only carried the email column should not overwrite the name.
+		mergeQuery, _ := s.fakeStageStore.ExecArgsForCall(3)
+		assert.Contains(s.T(), mergeQuery, "THEN UPDATE SET id=cc.id,email=cc.email\n", mergeQuery)
+		assert.NotContains(s.T(), mergeQuery, "name=cc.name", mergeQuery)
+	}
+	{
+		mergeQuery, _ := s.fakeStageStore.ExecArgsForCall(8)
+		assert.Contains(s.T(), mergeQuery, "THEN UPDATE SET id=cc.id,name=cc.name,email=cc.email\n", mergeQuery)
+	}
+}
+
+func (s *SnowflakeTestSuite) TestExecuteMergeImmutableColumns() {
+	newTableData := func(immutableColumns []string) *optimization.TableData {
+		var cols columns.Columns
+		cols.AddColumn(columns.NewColumn("id", typing.Integer))
+		cols.AddColumn(columns.NewColumn("name", typing.String))
+		cols.AddColumn(columns.NewColumn("created_at", typing.String))
+		cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+
+		topicConfig := kafkalib.TopicConfig{Database: "customer", Schema: "public", TableName: "orders", ImmutableColumns: immutableColumns}
+		tableData := optimization.NewTableData(&cols, config.Replication, []string{"id"}, topicConfig, "orders")
+		tableData.ResetTempTableSuffix()
+		tableData.InsertRow("1", map[string]any{"id": 1, "name": "dusty", "created_at": "2024-01-01"}, false)
+		s.stageStore.configMap.AddTableToConfig(s.stageStore.ToFullyQualifiedName(tableData, true), types.NewDwhTableConfig(&cols, nil, false, true))
+		return tableData
+	}
+
+	{
+		// Immutable columns are not updated, but they are inserted.
+		assert.NoError(s.T(), s.stageStore.Merge(newTableData([]string{"CREATED_AT"})))
+		mergeQuery, _ := s.fakeStageStore.ExecArgsForCall(3)
+		assert.Contains(s.T(), mergeQuery, "THEN UPDATE SET id=cc.id,name=cc.name\n", mergeQuery)
+		assert.NotContains(s.T(), mergeQuery, "created_at=cc.created_at", mergeQuery)
+		assert.Contains(s.T(), mergeQuery, "INSERT (id,name,created_at) VALUES (cc.id,cc.name,cc.created_at)", mergeQuery)
+	}
+
+	s.ResetStore()
+	{
+		// Column does not exist
+		err := s.stageStore.Merge(newTableData([]string{"inserted_at"}))
+		assert.ErrorContains(s.T(), err, `immutable column: "inserted_at" does not exist in table: customer.public.orders`)
+	}
+}
+
 // TestExecuteMergeDeletionFlagRemoval is going to run execute merge twice.
 // First time, we will try to delete a column
 // Second time, we'll simulate the data catching up (column exists) and it should now
@@ -246,7 +415,7 @@ func (s *SnowflakeTestSuite) TestExecuteMergeDeletionFlagRemoval() {
 
 	sflkCols.AddColumn(columns.NewColumn("new", typing.String))
 	_config := types.NewDwhTableConfig(&sflkCols, nil, false, true)
-	s.stageStore.configMap.AddTableToConfig(tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.UppercaseEscapedNames, optimization.FqNameOpts{}), _config)
+	s.stageStore.configMap.AddTableToConfig(tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.GetIdentifierCasing(), optimization.FqNameOpts{}), _config)
 
 	err := s.stageStore.Merge(tableData)
 	assert.Nil(s.T(), err)
@@ -254,10 +423,10 @@ func (s *SnowflakeTestSuite) TestExecuteMergeDeletionFlagRemoval() {
 	assert.Equal(s.T(), s.fakeStageStore.ExecCallCount(), 5, "called merge")
 
 	// Check the temp deletion table now.
-	assert.Equal(s.T(), len(s.stageStore.configMap.TableConfig(tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.UppercaseEscapedNames, optimization.FqNameOpts{})).ReadOnlyColumnsToDelete()), 1,
-		s.stageStore.configMap.TableConfig(tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.UppercaseEscapedNames, optimization.FqNameOpts{})).ReadOnlyColumnsToDelete())
+	assert.Equal(s.T(), len(s.stageStore.configMap.TableConfig(tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.GetIdentifierCasing(), optimization.FqNameOpts{})).ReadOnlyColumnsToDelete()), 1,
+		s.stageStore.configMap.TableConfig(tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.GetIdentifierCasing(), optimization.FqNameOpts{})).ReadOnlyColumnsToDelete())
 
-	_, isOk := s.stageStore.configMap.TableConfig(tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.UppercaseEscapedNames, optimization.FqNameOpts{})).ReadOnlyColumnsToDelete()["new"]
+	_, isOk := s.stageStore.configMap.TableConfig(tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.GetIdentifierCasing(), optimization.FqNameOpts{})).ReadOnlyColumnsToDelete()["new"]
 	assert.True(s.T(), isOk)
 
 	// Now try to execute merge where 1 of the rows have the column now
@@ -278,8 +447,8 @@ func (s *SnowflakeTestSuite) TestExecuteMergeDeletionFlagRemoval() {
 	assert.Equal(s.T(), s.fakeStageStore.ExecCallCount(), 10, "called merge again")
 
 	// Caught up now, so columns should be 0.
-	assert.Equal(s.T(), len(s.stageStore.configMap.TableConfig(tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.UppercaseEscapedNames, optimization.FqNameOpts{})).ReadOnlyColumnsToDelete()), 0,
-		s.stageStore.configMap.TableConfig(tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.UppercaseEscapedNames, optimization.FqNameOpts{})).ReadOnlyColumnsToDelete())
+	assert.Equal(s.T(), len(s.stageStore.configMap.TableConfig(tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.GetIdentifierCasing(), optimization.FqNameOpts{})).ReadOnlyColumnsToDelete()), 0,
+		s.stageStore.configMap.TableConfig(tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.GetIdentifierCasing(), optimization.FqNameOpts{})).ReadOnlyColumnsToDelete())
 }
 
 func (s *SnowflakeTestSuite) TestExecuteMergeExitEarly() {
@@ -287,3 +456,42 @@ func (s *SnowflakeTestSuite) TestExecuteMergeExitEarly() {
 	err := s.stageStore.Merge(tableData)
 	assert.Nil(s.T(), err)
 }
+
+func (s *SnowflakeTestSuite) TestExecuteMergeColumnComments() {
+	var cols columns.Columns
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	emailCol := columns.NewColumn("email", typing.String)
+	emailCol.SetComment("The customer's primary email address")
+	cols.AddColumn(emailCol)
+	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+
+	newTableData := func() *optimization.TableData {
+		topicConfig := kafkalib.TopicConfig{Database: "customer", Schema: "public", TableName: "users"}
+		tableData := optimization.NewTableData(&cols, config.Replication, []string{"id"}, topicConfig, "users")
+		tableData.ResetTempTableSuffix()
+		tableData.InsertRow("1", map[string]any{"id": 1, "email": "dusty@example.com"}, false)
+		return tableData
+	}
+
+	// The destination already has these columns, but without a comment.
+	var destCols columns.Columns
+	for _, col := range cols.GetColumns() {
+		destCols.AddColumn(columns.NewColumn(col.RawName(), col.KindDetails))
+	}
+
+	s.stageStore.configMap.AddTableToConfig("customer.public.users", types.NewDwhTableConfig(&destCols, nil, false, true))
+	assert.NoError(s.T(), s.stageStore.Merge(newTableData()))
+
+	// COMMENT, CREATE, PUT, COPY, MERGE and DROP
+	assert.Equal(s.T(), 6, s.fakeStageStore.ExecCallCount())
+	commentQuery, _ := s.fakeStageStore.ExecArgsForCall(0)
+	assert.Equal(s.T(), `COMMENT ON COLUMN customer.public.users.email IS '{"backfilled": false, "description": "The customer\'s primary email address"}';`, commentQuery)
+
+	// The comment has been applied, so it should not be set again.
+	assert.NoError(s.T(), s.stageStore.Merge(newTableData()))
+	assert.Equal(s.T(), 11, s.fakeStageStore.ExecCallCount())
+	for i := 1; i < s.fakeStageStore.ExecCallCount(); i++ {
+		query, _ := s.fakeStageStore.ExecArgsForCall(i)
+		assert.NotContains(s.T(), query, "COMMENT ON COLUMN", query)
+	}
+}
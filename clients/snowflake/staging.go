@@ -29,25 +29,28 @@ func castColValStaging(colVal any, colKind columns.Column, additionalDateFmts []
 }
 
 func (s *Store) PrepareTemporaryTable(tableData *optimization.TableData, tableConfig *types.DwhTableConfig, tempTableName string, additionalSettings types.AdditionalSettings, createTempTable bool) error {
+	// The COPY command maps the file's fields by position, so we're taking a snapshot of the columns and using it for the temporary table, the file and the COPY command.
+	// Otherwise, a column that is added in the middle of this would misalign the data.
+	cols := tableData.ReadOnlyInMemoryCols()
 	if createTempTable {
 		tempAlterTableArgs := ddl.AlterTableArgs{
-			Dwh:               s,
-			Tc:                tableConfig,
-			FqTableName:       tempTableName,
-			CreateTable:       true,
-			TemporaryTable:    true,
-			ColumnOp:          constants.Add,
-			UppercaseEscNames: &s.config.SharedDestinationConfig.UppercaseEscapedNames,
-			Mode:              tableData.Mode(),
+			Dwh:              s,
+			Tc:               tableConfig,
+			FqTableName:      tempTableName,
+			CreateTable:      true,
+			TemporaryTable:   true,
+			ColumnOp:         constants.Add,
+			IdentifierCasing: s.config.SharedDestinationConfig.GetIdentifierCasing(),
+			Mode:             tableData.Mode(),
 		}
 
-		if err := tempAlterTableArgs.AlterTable(tableData.ReadOnlyInMemoryCols().GetColumns()...); err != nil {
+		if err := tempAlterTableArgs.AlterTable(cols.GetColumns()...); err != nil {
 			return fmt.Errorf("failed to create temp table: %w", err)
 		}
 	}
 
 	// Write data into CSV
-	fp, err := s.writeTemporaryTableFile(tableData, tempTableName)
+	fp, err := s.writeTemporaryTableFile(tableData, cols, tempTableName)
 	if err != nil {
 		return fmt.Errorf("failed to load temporary table: %w", err)
 	}
@@ -65,17 +68,28 @@ func (s *Store) PrepareTemporaryTable(tableData *optimization.TableData, tableCo
 	}
 
 	// COPY the CSV file (in Snowflake) into a table
+	targetCols := cols.GetColumnsToUpdate(s.config.SharedDestinationConfig.GetIdentifierCasing(), &sql.NameArgs{
+		Escape:   true,
+		DestKind: s.Label(),
+	})
+	selectCols := escapeColumns(cols)
+	if len(targetCols) != len(selectCols) {
+		return fmt.Errorf("column count mismatch for copy into temporary table, target columns: %d, select columns: %d", len(targetCols), len(selectCols))
+	}
+
 	copyCommand := fmt.Sprintf("COPY INTO %s (%s) FROM (SELECT %s FROM @%s)",
-		tempTableName, strings.Join(tableData.ReadOnlyInMemoryCols().GetColumnsToUpdate(s.config.SharedDestinationConfig.UppercaseEscapedNames, &sql.NameArgs{
-			Escape:   true,
-			DestKind: s.Label(),
-		}), ","),
-		escapeColumns(tableData.ReadOnlyInMemoryCols(), ","), addPrefixToTableName(tempTableName, "%"))
+		tempTableName, strings.Join(targetCols, ","), strings.Join(selectCols, ","), addPrefixToTableName(tempTableName, "%"))
 
 	if additionalSettings.AdditionalCopyClause != "" {
 		copyCommand += " " + additionalSettings.AdditionalCopyClause
 	}
 
+	if additionalSettings.DeferredQueries != nil {
+		// The caller will run the COPY within the same transaction as the merge.
+		*additionalSettings.DeferredQueries = append(*additionalSettings.DeferredQueries, copyCommand)
+		return nil
+	}
+
 	if _, err = s.Exec(copyCommand); err != nil {
 		return fmt.Errorf("failed to run copy into temporary table: %w", err)
 	}
@@ -83,7 +97,8 @@ func (s *Store) PrepareTemporaryTable(tableData *optimization.TableData, tableCo
 	return nil
 }
 
-func (s *Store) writeTemporaryTableFile(tableData *optimization.TableData, newTableName string) (string, error) {
+// writeTemporaryTableFile will write the rows to a CSV file, the fields will be in the same order as `cols`.
+func (s *Store) writeTemporaryTableFile(tableData *optimization.TableData, cols *columns.Columns, newTableName string) (string, error) {
 	fp := filepath.Join(os.TempDir(), fmt.Sprintf("%s.csv", newTableName))
 	file, err := os.Create(fp)
 	if err != nil {
@@ -95,10 +110,11 @@ func (s *Store) writeTemporaryTableFile(tableData *optimization.TableData, newTa
 	writer.Comma = '\t'
 
 	additionalDateFmts := s.config.SharedTransferConfig.TypingSettings.AdditionalDateFormats
+	colNames := cols.GetColumnsToUpdate(s.config.SharedDestinationConfig.GetIdentifierCasing(), nil)
 	for _, value := range tableData.Rows() {
 		var row []string
-		for _, col := range tableData.ReadOnlyInMemoryCols().GetColumnsToUpdate(s.config.SharedDestinationConfig.UppercaseEscapedNames, nil) {
-			column, _ := tableData.ReadOnlyInMemoryCols().GetColumn(col)
+		for _, col := range colNames {
+			column, _ := cols.GetColumn(col)
 			castedValue, castErr := castColValStaging(value[col], column, additionalDateFmts)
 			if castErr != nil {
 				return "", castErr
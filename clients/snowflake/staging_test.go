@@ -1,6 +1,7 @@
 package snowflake
 
 import (
+	gosql "database/sql"
 	"encoding/csv"
 	"fmt"
 	"io"
@@ -68,6 +69,10 @@ func (s *SnowflakeTestSuite) TestBackfillColumn() {
 	needsBackfillColDefault := columns.NewColumn("default", typing.Boolean)
 	needsBackfillColDefault.SetDefaultValue(true)
 
+	describedCol := columns.NewColumn("foo", typing.Boolean)
+	describedCol.SetDefaultValue(true)
+	describedCol.SetComment("Whether the order is active")
+
 	testCases := []struct {
 		name        string
 		col         columns.Column
@@ -94,6 +99,12 @@ func (s *SnowflakeTestSuite) TestBackfillColumn() {
 			backfillSQL: `UPDATE db.public.tableName SET default = true WHERE "DEFAULT" IS NULL;`,
 			commentSQL:  `COMMENT ON COLUMN db.public.tableName.default IS '{"backfilled": true}';`,
 		},
+		{
+			name:        "col with a description that needs to be backfilled",
+			col:         describedCol,
+			backfillSQL: `UPDATE db.public.tableName SET foo = true WHERE foo IS NULL;`,
+			commentSQL:  `COMMENT ON COLUMN db.public.tableName.foo IS '{"backfilled": true, "description": "Whether the order is active"}';`,
+		},
 	}
 
 	var count int
@@ -173,9 +184,57 @@ func (s *SnowflakeTestSuite) TestPrepareTempTable() {
 
 }
 
+func (s *SnowflakeTestSuite) TestPrepareTempTable_SchemaChange() {
+	tempTableName, tableData := generateTableData(10)
+	s.stageStore.GetConfigMap().AddTableToConfig(tempTableName, types.NewDwhTableConfig(&columns.Columns{}, nil, true, true))
+	sflkTc := s.stageStore.GetConfigMap().TableConfig(tempTableName)
+
+	var fileFields []int
+	s.fakeStageStore.ExecStub = func(query string, _ ...any) (gosql.Result, error) {
+		switch {
+		case strings.HasPrefix(query, "CREATE TABLE"):
+			// Simulate a column being added while we are flushing.
+			tableData.AddInMemoryCol(columns.NewColumn("late_column", typing.String))
+			for _, row := range tableData.Rows() {
+				row["late_column"] = "late"
+			}
+		case strings.HasPrefix(query, "PUT file://"):
+			fp := strings.Fields(strings.TrimPrefix(query, "PUT file://"))[0]
+			file, err := os.Open(fp)
+			assert.NoError(s.T(), err)
+			defer file.Close()
+
+			r := csv.NewReader(file)
+			r.Comma = '\t'
+			records, err := r.ReadAll()
+			assert.NoError(s.T(), err)
+			for _, record := range records {
+				fileFields = append(fileFields, len(record))
+			}
+		}
+
+		return nil, nil
+	}
+
+	assert.NoError(s.T(), s.stageStore.PrepareTemporaryTable(tableData, sflkTc, tempTableName, types.AdditionalSettings{}, true))
+	assert.Equal(s.T(), 3, s.fakeStageStore.ExecCallCount())
+
+	// The temporary table, the file and the COPY command should all have the columns from before the schema change.
+	createQuery, _ := s.fakeStageStore.ExecArgsForCall(0)
+	assert.Contains(s.T(), createQuery, "(user_id string,first_name string,last_name string,dusty string)")
+	assert.Len(s.T(), fileFields, 10)
+	for _, fields := range fileFields {
+		assert.Equal(s.T(), 4, fields)
+	}
+
+	copyQuery, _ := s.fakeStageStore.ExecArgsForCall(2)
+	assert.Equal(s.T(), fmt.Sprintf(`COPY INTO %s (user_id,first_name,last_name,dusty) FROM (SELECT $1,$2,$3,$4 FROM @%s)`,
+		tempTableName, addPrefixToTableName(tempTableName, "%")), copyQuery)
+}
+
 func (s *SnowflakeTestSuite) TestLoadTemporaryTable() {
 	tempTableName, tableData := generateTableData(100)
-	fp, err := s.stageStore.writeTemporaryTableFile(tableData, tempTableName)
+	fp, err := s.stageStore.writeTemporaryTableFile(tableData, tableData.ReadOnlyInMemoryCols(), tempTableName)
 	assert.NoError(s.T(), err)
 	// Read the CSV and confirm.
 	csvfile, err := os.Open(fp)
@@ -0,0 +1,117 @@
+package snowflake
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/mocks/fakedriver"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func newTx(fakeDriver *fakedriver.Driver) (*sql.Tx, error) {
+	db, err := fakeDriver.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	return db.Begin()
+}
+
+func (s *SnowflakeTestSuite) TestExecuteMergeTransactional() {
+	newTableData := func() *optimization.TableData {
+		var cols columns.Columns
+		for colName, colKind := range map[string]typing.KindDetails{"id": typing.Integer, "name": typing.String, constants.DeleteColumnMarker: typing.Boolean} {
+			cols.AddColumn(columns.NewColumn(colName, colKind))
+		}
+
+		topicConfig := kafkalib.TopicConfig{Database: "customer", TableName: "orders", Schema: "public"}
+		tableData := optimization.NewTableData(&cols, config.Replication, []string{"id"}, topicConfig, "orders")
+		tableData.ResetTempTableSuffix()
+		for i := 0; i < 5; i++ {
+			tableData.InsertRow(fmt.Sprintf("pk-%d", i), map[string]any{"id": i, "name": fmt.Sprintf("Robin-%d", i)}, false)
+		}
+
+		fqName := tableData.ToFqName(s.stageStore.Label(), true, s.stageStore.config.SharedDestinationConfig.GetIdentifierCasing(), optimization.FqNameOpts{})
+		s.stageStore.configMap.AddTableToConfig(fqName, types.NewDwhTableConfig(&cols, nil, false, true))
+		return tableData
+	}
+
+	s.stageStore.config.Snowflake = &config.Snowflake{TransactionalMerge: true}
+	{
+		// Successful merge is committed
+		fakeDriver := &fakedriver.Driver{}
+		tx, err := newTx(fakeDriver)
+		assert.NoError(s.T(), err)
+		s.fakeStageStore.BeginReturns(tx, nil)
+
+		assert.NoError(s.T(), s.stageStore.Merge(newTableData()))
+		assert.Equal(s.T(), 1, s.fakeStageStore.BeginCallCount())
+		assert.Len(s.T(), fakeDriver.Committed(), 2)
+		assert.Contains(s.T(), fakeDriver.Committed()[0], "COPY INTO customer.public.orders___artie_")
+		assert.Contains(s.T(), fakeDriver.Committed()[1], "MERGE INTO customer.public.orders")
+		assert.Empty(s.T(), fakeDriver.RolledBack())
+
+		// Create, PUT and DROP cannot be part of the transaction.
+		assert.Equal(s.T(), 3, s.fakeStageStore.ExecCallCount())
+		for i := 0; i < s.fakeStageStore.ExecCallCount(); i++ {
+			query, _ := s.fakeStageStore.ExecArgsForCall(i)
+			assert.NotContains(s.T(), query, "COPY INTO")
+			assert.NotContains(s.T(), query, "MERGE INTO")
+		}
+	}
+
+	s.ResetStore()
+	s.stageStore.config.Snowflake = &config.Snowflake{TransactionalMerge: true}
+	{
+		// Failed COPY is rolled back and the merge is never run
+		fakeDriver := &fakedriver.Driver{FailOn: "COPY INTO"}
+		tx, err := newTx(fakeDriver)
+		assert.NoError(s.T(), err)
+		s.fakeStageStore.BeginReturns(tx, nil)
+
+		assert.ErrorContains(s.T(), s.stageStore.Merge(newTableData()), "statement failed")
+		assert.Empty(s.T(), fakeDriver.Committed())
+		assert.Len(s.T(), fakeDriver.RolledBack(), 1)
+		assert.Contains(s.T(), fakeDriver.RolledBack()[0], "COPY INTO customer.public.orders___artie_")
+
+		dropQuery, _ := s.fakeStageStore.ExecArgsForCall(s.fakeStageStore.ExecCallCount() - 1)
+		assert.Contains(s.T(), dropQuery, "DROP TABLE IF EXISTS")
+	}
+
+	s.ResetStore()
+	s.stageStore.config.Snowflake = &config.Snowflake{TransactionalMerge: true}
+	{
+		// Failed merge is rolled back
+		fakeDriver := &fakedriver.Driver{FailOn: "MERGE INTO"}
+		tx, err := newTx(fakeDriver)
+		assert.NoError(s.T(), err)
+		s.fakeStageStore.BeginReturns(tx, nil)
+
+		assert.ErrorContains(s.T(), s.stageStore.Merge(newTableData()), "statement failed")
+		assert.Empty(s.T(), fakeDriver.Committed(), "no target rows should have changed")
+		assert.Len(s.T(), fakeDriver.RolledBack(), 2)
+		assert.Contains(s.T(), fakeDriver.RolledBack()[0], "COPY INTO customer.public.orders___artie_")
+		assert.Contains(s.T(), fakeDriver.RolledBack()[1], "MERGE INTO customer.public.orders")
+
+		// The temporary table should still be dropped.
+		dropQuery, _ := s.fakeStageStore.ExecArgsForCall(s.fakeStageStore.ExecCallCount() - 1)
+		assert.Contains(s.T(), dropQuery, "DROP TABLE IF EXISTS")
+	}
+
+	s.ResetStore()
+	{
+		// Not enabled, the merge is run with autocommit.
+		assert.NoError(s.T(), s.stageStore.Merge(newTableData()))
+		assert.Equal(s.T(), 0, s.fakeStageStore.BeginCallCount())
+		mergeQuery, _ := s.fakeStageStore.ExecArgsForCall(3)
+		assert.Contains(s.T(), mergeQuery, "MERGE INTO customer.public.orders")
+	}
+}
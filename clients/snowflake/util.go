@@ -23,19 +23,23 @@ func addPrefixToTableName(fqTableName string, prefix string) string {
 }
 
 // escapeColumns will take columns, filter out invalid, escape and return them in ordered received.
-// It'll return like this: $1, $2, $3
-func escapeColumns(columns *columns.Columns, delimiter string) string {
+// It'll return like this: [$1, $2, $3]
+func escapeColumns(columns *columns.Columns) []string {
 	var escapedCols []string
 	var index int
 	for _, col := range columns.GetColumns() {
 		escapedCol := fmt.Sprintf("$%d", index+1)
-		switch col.KindDetails {
-		case typing.Invalid:
+		switch {
+		case col.KindDetails == typing.Invalid:
 			continue
-		case typing.Struct:
+		case col.KindDetails.Kind == typing.Struct.Kind:
 			// https://community.snowflake.com/s/article/how-to-load-json-values-in-a-csv-file
-			escapedCol = fmt.Sprintf("PARSE_JSON(%s)", escapedCol)
-		case typing.Array:
+			if col.KindDetails.OptionalObject {
+				escapedCol = fmt.Sprintf("CAST(PARSE_JSON(%s) AS OBJECT) AS %s", escapedCol, escapedCol)
+			} else {
+				escapedCol = fmt.Sprintf("PARSE_JSON(%s)", escapedCol)
+			}
+		case col.KindDetails.Kind == typing.Array.Kind:
 			escapedCol = fmt.Sprintf("CAST(PARSE_JSON(%s) AS ARRAY) AS %s", escapedCol, escapedCol)
 		}
 
@@ -43,5 +47,5 @@ func escapeColumns(columns *columns.Columns, delimiter string) string {
 		index += 1
 	}
 
-	return strings.Join(escapedCols, delimiter)
+	return escapedCols
 }
@@ -1,6 +1,7 @@
 package snowflake
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/artie-labs/transfer/lib/typing/columns"
@@ -79,6 +80,10 @@ func (s *SnowflakeTestSuite) TestEscapeColumns() {
 	}
 	colsWithInvalidValues.AddColumn(columns.NewColumn("invalid2", typing.Invalid))
 
+	var objectAndTypedArrayCols columns.Columns
+	objectAndTypedArrayCols.AddColumn(columns.NewColumn("object", typing.Object))
+	objectAndTypedArrayCols.AddColumn(columns.NewColumn("tags", typing.NewArrayKindDetails(typing.String)))
+
 	testCases := []_testCase{
 		{
 			name:           "happy path",
@@ -101,10 +106,15 @@ func (s *SnowflakeTestSuite) TestEscapeColumns() {
 			// Index here should be the same still.
 			expectedString: "$1,$2,PARSE_JSON($3),CAST(PARSE_JSON($4) AS ARRAY) AS $4",
 		},
+		{
+			name:           "objects & typed arrays",
+			cols:           &objectAndTypedArrayCols,
+			expectedString: "CAST(PARSE_JSON($1) AS OBJECT) AS $1,CAST(PARSE_JSON($2) AS ARRAY) AS $2",
+		},
 	}
 
 	for _, testCase := range testCases {
-		actualString := escapeColumns(testCase.cols, ",")
+		actualString := strings.Join(escapeColumns(testCase.cols), ",")
 		assert.Equal(s.T(), testCase.expectedString, actualString, testCase.name)
 	}
 }
@@ -21,16 +21,23 @@ func (s *Store) Append(tableData *optimization.TableData) error {
 		}
 
 		// TODO: For history mode - in the future, we could also have a separate stage name for history mode so we can enable parallel processing.
-		err = shared.Append(s, tableData, s.config, types.AppendOpts{
-			TempTableName:        s.ToFullyQualifiedName(tableData, true),
-			AdditionalCopyClause: `FILE_FORMAT = (TYPE = 'csv' FIELD_DELIMITER= '\t' FIELD_OPTIONALLY_ENCLOSED_BY='"' NULL_IF='\\N' EMPTY_FIELD_AS_NULL=FALSE) PURGE = TRUE`,
+		err = s.withSession(tableData.TopicConfig, func(store *Store) error {
+			return shared.Append(store, tableData, store.config, types.AppendOpts{
+				TempTableName:        store.ToFullyQualifiedName(tableData, true),
+				AdditionalCopyClause: `FILE_FORMAT = (TYPE = 'csv' FIELD_DELIMITER= '\t' FIELD_OPTIONALLY_ENCLOSED_BY='"' NULL_IF='\\N' EMPTY_FIELD_AS_NULL=FALSE) PURGE = TRUE`,
+			})
 		})
 	}
 
-	return err
+	return s.classifyError(err)
 }
 
 func (s *Store) Merge(tableData *optimization.TableData) error {
+	return s.merge(tableData, nil)
+}
+
+// merge will record `offsets` within the same transaction as the merge, if any are provided.
+func (s *Store) merge(tableData *optimization.TableData, offsets []types.KafkaOffset) error {
 	var err error
 	for i := 0; i < maxRetries; i++ {
 		if i > 0 {
@@ -42,7 +49,17 @@ func (s *Store) Merge(tableData *optimization.TableData) error {
 			}
 		}
 
-		err = shared.Merge(s, tableData, s.config, types.MergeOpts{})
+		err = s.withSession(tableData.TopicConfig, func(store *Store) error {
+			transactionQueries, offsetsErr := store.offsetsQueries(tableData, offsets)
+			if offsetsErr != nil {
+				return offsetsErr
+			}
+
+			return shared.Merge(store, tableData, store.config, types.MergeOpts{
+				UseTransaction:     store.config.Snowflake != nil && store.config.Snowflake.TransactionalMerge,
+				TransactionQueries: transactionQueries,
+			})
+		})
 	}
-	return err
+	return s.classifyError(err)
 }
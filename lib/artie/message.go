@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"cloud.google.com/go/pubsub"
+	"github.com/nats-io/nats.go/jetstream"
 	"github.com/segmentio/kafka-go"
 
 	"github.com/artie-labs/transfer/lib/config"
@@ -18,16 +19,26 @@ const (
 	Invalid Kind = iota
 	Kafka
 	PubSub
+	NATS
 )
 
+// NATSKeyHeader is the header that holds the message key for NATS, JetStream messages do not have a dedicated key field.
+const NATSKeyHeader = "Key"
+
 type pubsubWrapper struct {
 	topic string
 	*pubsub.Message
 }
 
+type natsWrapper struct {
+	topic string
+	jetstream.Msg
+}
+
 type Message struct {
 	KafkaMsg *kafka.Message
 	PubSub   *pubsubWrapper
+	NATS     *natsWrapper
 }
 
 func KafkaMsgLogFields(msg kafka.Message) []any {
@@ -55,6 +66,19 @@ func NewMessage(kafkaMsg *kafka.Message, pubsubMsg *pubsub.Message, topic string
 	return msg
 }
 
+// NewNATSMessage wraps a JetStream message, `topic` is the topic config's subject that the message was consumed from.
+func NewNATSMessage(natsMsg jetstream.Msg, topic string) Message {
+	var msg Message
+	if natsMsg != nil {
+		msg.NATS = &natsWrapper{
+			topic: topic,
+			Msg:   natsMsg,
+		}
+	}
+
+	return msg
+}
+
 func (m *Message) Kind() Kind {
 	if m.KafkaMsg != nil {
 		return Kafka
@@ -64,6 +88,10 @@ func (m *Message) Kind() Kind {
 		return PubSub
 	}
 
+	if m.NATS != nil {
+		return NATS
+	}
+
 	return Invalid
 }
 
@@ -83,14 +111,49 @@ func (m *Message) EmitRowLag(metricsClient base.Client, mode config.Mode, groupI
 	}, 0.5)
 }
 
-func (m *Message) EmitIngestionLag(metricsClient base.Client, mode config.Mode, groupID, table string) {
-	metricsClient.Timing("ingestion.lag", time.Since(m.PublishTime()), map[string]string{
+// IngestionLag returns how far behind `now` the message is, `sourceTime` is when the change happened in the source and is only used if the settings ask for it.
+// Clock skew between the source, the broker and us can make the lag negative, negative lag is reported as zero.
+// If a clock skew tolerance is set, anything beyond it cannot be trusted, so we'll return false.
+func (m *Message) IngestionLag(settings *config.IngestionLag, sourceTime time.Time, now time.Time) (time.Duration, bool) {
+	ts := m.PublishTime()
+	if settings.UseSourceTimestamp() {
+		ts = sourceTime
+	}
+
+	if ts.IsZero() {
+		return 0, false
+	}
+
+	lag := now.Sub(ts)
+	if lag < 0 {
+		if tolerance := settings.ClockSkewTolerance(); tolerance > 0 && -lag > tolerance {
+			return 0, false
+		}
+
+		return 0, true
+	}
+
+	return lag, true
+}
+
+// EmitIngestionLag will emit the message's ingestion lag and return it, it will return false if the lag could not be computed.
+func (m *Message) EmitIngestionLag(metricsClient base.Client, mode config.Mode, settings *config.IngestionLag, groupID, table string, sourceTime time.Time) (time.Duration, bool) {
+	tags := map[string]string{
 		"mode":      mode.String(),
 		"groupID":   groupID,
 		"topic":     m.Topic(),
 		"table":     table,
 		"partition": m.Partition(),
-	})
+	}
+
+	lag, isOk := m.IngestionLag(settings, sourceTime, time.Now())
+	if !isOk {
+		metricsClient.Incr("ingestion.lag.skipped", tags)
+		return 0, false
+	}
+
+	metricsClient.Timing("ingestion.lag", lag, tags)
+	return lag, true
 }
 
 func (m *Message) PublishTime() time.Time {
@@ -102,6 +165,12 @@ func (m *Message) PublishTime() time.Time {
 		return m.PubSub.PublishTime
 	}
 
+	if m.NATS != nil {
+		if metadata, err := m.NATS.Metadata(); err == nil {
+			return metadata.Timestamp
+		}
+	}
+
 	return time.Time{}
 }
 
@@ -114,6 +183,10 @@ func (m *Message) Topic() string {
 		return m.PubSub.topic
 	}
 
+	if m.NATS != nil {
+		return m.NATS.topic
+	}
+
 	return ""
 }
 
@@ -127,9 +200,30 @@ func (m *Message) Partition() string {
 		return "no_partition"
 	}
 
+	if m.NATS != nil {
+		// JetStream only guarantees ordering per subject, so we'll use that as the partition.
+		return m.NATS.Subject()
+	}
+
 	return ""
 }
 
+// Offset returns the message's position within its partition, this will return false if the queue does not have offsets (e.g. Pub/Sub).
+func (m *Message) Offset() (int64, bool) {
+	if m.KafkaMsg != nil {
+		return m.KafkaMsg.Offset, true
+	}
+
+	if m.NATS != nil {
+		// The stream sequence is increasing across the whole stream, so it's also ordered within a subject.
+		if metadata, err := m.NATS.Metadata(); err == nil {
+			return int64(metadata.Sequence.Stream), true
+		}
+	}
+
+	return 0, false
+}
+
 func (m *Message) Key() []byte {
 	if m.KafkaMsg != nil {
 		return m.KafkaMsg.Key
@@ -139,6 +233,10 @@ func (m *Message) Key() []byte {
 		return []byte(m.PubSub.OrderingKey)
 	}
 
+	if m.NATS != nil && m.NATS.Headers() != nil {
+		return []byte(m.NATS.Headers().Get(NATSKeyHeader))
+	}
+
 	return nil
 }
 
@@ -151,5 +249,9 @@ func (m *Message) Value() []byte {
 		return m.PubSub.Data
 	}
 
+	if m.NATS != nil {
+		return m.NATS.Data()
+	}
+
 	return nil
 }
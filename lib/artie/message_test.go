@@ -2,9 +2,14 @@ package artie
 
 import (
 	"cloud.google.com/go/pubsub"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
+
+	"github.com/artie-labs/transfer/lib/config"
 )
 
 const keyString = "Struct{id=12}"
@@ -16,6 +21,8 @@ func TestNewMessage_Pubsub(t *testing.T) {
 	pubsubMsg := &pubsub.Message{}
 	msg = NewMessage(nil, pubsubMsg, "")
 	assert.Equal(t, "no_partition", msg.Partition())
+	_, isOk := msg.Offset()
+	assert.False(t, isOk)
 
 	pubsubMsg.Data = []byte("hello_world")
 	assert.Equal(t, "hello_world", string(msg.Value()))
@@ -31,6 +38,7 @@ func TestNewMessage(t *testing.T) {
 	kafkaMsg := &kafka.Message{
 		Topic:     "test_topic",
 		Partition: 5,
+		Offset:    42,
 		Key:       []byte(keyString),
 		Value:     []byte("kafka_value"),
 	}
@@ -38,6 +46,99 @@ func TestNewMessage(t *testing.T) {
 	msg := NewMessage(kafkaMsg, nil, "")
 	assert.Equal(t, "test_topic", msg.Topic())
 	assert.Equal(t, "5", msg.Partition())
+	offset, isOk := msg.Offset()
+	assert.True(t, isOk)
+	assert.Equal(t, int64(42), offset)
 	assert.Equal(t, keyString, string(msg.Key()))
 	assert.Equal(t, "kafka_value", string(msg.Value()))
 }
+
+type fakeNATSMsg struct {
+	jetstream.Msg
+	subject   string
+	data      []byte
+	headers   nats.Header
+	timestamp time.Time
+	sequence  uint64
+	acked     bool
+}
+
+func (f *fakeNATSMsg) Subject() string      { return f.subject }
+func (f *fakeNATSMsg) Data() []byte         { return f.data }
+func (f *fakeNATSMsg) Headers() nats.Header { return f.headers }
+func (f *fakeNATSMsg) Ack() error {
+	f.acked = true
+	return nil
+}
+func (f *fakeNATSMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{Timestamp: f.timestamp, Sequence: jetstream.SequencePair{Stream: f.sequence}}, nil
+}
+
+func TestNewNATSMessage(t *testing.T) {
+	msg := NewNATSMessage(nil, "")
+	assert.Equal(t, Invalid, msg.Kind())
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	natsMsg := &fakeNATSMsg{
+		subject:   "dbserver1.public.orders",
+		data:      []byte("nats_value"),
+		timestamp: ts,
+		sequence:  7,
+	}
+
+	msg = NewNATSMessage(natsMsg, "dbserver1.public.*")
+	assert.Equal(t, NATS, msg.Kind())
+	assert.Equal(t, "dbserver1.public.*", msg.Topic())
+	assert.Equal(t, "dbserver1.public.orders", msg.Partition())
+	assert.Equal(t, "nats_value", string(msg.Value()))
+	assert.Equal(t, ts, msg.PublishTime())
+	offset, isOk := msg.Offset()
+	assert.True(t, isOk)
+	assert.Equal(t, int64(7), offset)
+	assert.Empty(t, msg.Key())
+
+	natsMsg.headers = nats.Header{}
+	natsMsg.headers.Set(NATSKeyHeader, keyString)
+	assert.Equal(t, keyString, string(msg.Key()))
+
+	assert.NoError(t, msg.NATS.Ack())
+	assert.True(t, natsMsg.acked)
+}
+
+func TestMessage_IngestionLag(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	msg := NewMessage(&kafka.Message{Time: now.Add(-5 * time.Second)}, nil, "")
+	sourceTime := now.Add(-time.Minute)
+	{
+		// By default, we'll use the broker's timestamp.
+		lag, isOk := msg.IngestionLag(nil, sourceTime, now)
+		assert.True(t, isOk)
+		assert.Equal(t, 5*time.Second, lag)
+	}
+	{
+		// Source timestamp
+		lag, isOk := msg.IngestionLag(&config.IngestionLag{Timestamp: config.IngestionLagTimestampSource}, sourceTime, now)
+		assert.True(t, isOk)
+		assert.Equal(t, time.Minute, lag)
+
+		// The source timestamp is not available.
+		_, isOk = msg.IngestionLag(&config.IngestionLag{Timestamp: config.IngestionLagTimestampSource}, time.Time{}, now)
+		assert.False(t, isOk)
+	}
+	{
+		// Negative lag is clamped to zero, the broker's timestamp is ahead of us here.
+		lag, isOk := msg.IngestionLag(nil, sourceTime, now.Add(-time.Hour))
+		assert.True(t, isOk)
+		assert.Zero(t, lag)
+
+		// Negative lag within the tolerance is clamped to zero.
+		settings := &config.IngestionLag{Timestamp: config.IngestionLagTimestampSource, ClockSkewToleranceSeconds: 10}
+		lag, isOk = msg.IngestionLag(settings, now.Add(3*time.Second), now)
+		assert.True(t, isOk)
+		assert.Zero(t, lag)
+
+		// Anything beyond the tolerance is dropped.
+		_, isOk = msg.IngestionLag(settings, now.Add(time.Minute), now)
+		assert.False(t, isOk)
+	}
+}
@@ -0,0 +1,218 @@
+package assertions
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/artie-labs/transfer/lib/kafkalib"
+)
+
+// Table is the destination table that the assertions are run against.
+type Table struct {
+	FqName string
+	// EscapeName will escape a column name so that it can be referenced in a query against this table.
+	EscapeName func(name string) string
+}
+
+// Assertion is a check that is run against the destination table after it has been flushed.
+type Assertion interface {
+	// Query returns the SQL to run against `table`, it must return a single numeric value.
+	Query(table Table) string
+	// Expect returns an error if `value` (the result of Query) violates the assertion.
+	Expect(value float64) error
+}
+
+// Factory creates an assertion from the topic config, it should return an error if the config is invalid.
+type Factory func(cfg kafkalib.AssertionConfig) (Assertion, error)
+
+var (
+	registry = map[string]Factory{
+		"rowCount": newRowCount,
+		"notNull":  newNotNull,
+		"sql":      newSQL,
+	}
+	registryMu sync.RWMutex
+)
+
+// Register will add a custom assertion that can be referenced by `name` in the topic config, this will replace any existing assertion with the same name.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// New returns the assertion registered under `cfg.Type`.
+func New(cfg kafkalib.AssertionConfig) (Assertion, error) {
+	registryMu.RLock()
+	factory, isOk := registry[cfg.Type]
+	registryMu.RUnlock()
+	if !isOk {
+		return nil, fmt.Errorf("assertion %q has an unknown type: %q", cfg.Name, cfg.Type)
+	}
+
+	return factory(cfg)
+}
+
+type Querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// Failure is returned for each assertion that failed or could not be run.
+type Failure struct {
+	Config kafkalib.AssertionConfig
+	Err    error
+}
+
+// Run will run each assertion against `table` and return the ones that have failed.
+func Run(querier Querier, table Table, cfgs []kafkalib.AssertionConfig) []Failure {
+	return run(table, cfgs, func(query string) (float64, error) {
+		return queryValue(querier, query)
+	})
+}
+
+func run(table Table, cfgs []kafkalib.AssertionConfig, queryValue func(query string) (float64, error)) []Failure {
+	var failures []Failure
+	for _, cfg := range cfgs {
+		if err := runAssertion(table, cfg, queryValue); err != nil {
+			failures = append(failures, Failure{
+				Config: cfg,
+				Err:    fmt.Errorf("assertion %q failed: %w", cfg.Name, err),
+			})
+		}
+	}
+
+	return failures
+}
+
+func runAssertion(table Table, cfg kafkalib.AssertionConfig, queryValue func(query string) (float64, error)) error {
+	assertion, err := New(cfg)
+	if err != nil {
+		return err
+	}
+
+	value, err := queryValue(assertion.Query(table))
+	if err != nil {
+		return fmt.Errorf("failed to run query: %w", err)
+	}
+
+	return assertion.Expect(value)
+}
+
+func queryValue(querier Querier, query string) (float64, error) {
+	rows, err := querier.Query(query)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return 0, err
+		}
+
+		return 0, fmt.Errorf("query did not return any rows")
+	}
+
+	var value sql.NullFloat64
+	if err = rows.Scan(&value); err != nil {
+		return 0, err
+	}
+
+	if !value.Valid {
+		return 0, fmt.Errorf("query returned NULL")
+	}
+
+	return value.Float64, nil
+}
+
+type bounds struct {
+	min *float64
+	max *float64
+}
+
+func newBounds(cfg kafkalib.AssertionConfig) (bounds, error) {
+	if cfg.Min == nil && cfg.Max == nil {
+		return bounds{}, fmt.Errorf("assertion %q requires a min or max", cfg.Name)
+	}
+
+	return bounds{min: cfg.Min, max: cfg.Max}, nil
+}
+
+func (b bounds) Expect(value float64) error {
+	if b.min != nil && value < *b.min {
+		return fmt.Errorf("value: %v is less than the min: %v", value, *b.min)
+	}
+
+	if b.max != nil && value > *b.max {
+		return fmt.Errorf("value: %v is greater than the max: %v", value, *b.max)
+	}
+
+	return nil
+}
+
+// rowCount checks that the number of rows in the table is within bounds.
+type rowCount struct {
+	bounds
+}
+
+func newRowCount(cfg kafkalib.AssertionConfig) (Assertion, error) {
+	b, err := newBounds(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return rowCount{bounds: b}, nil
+}
+
+func (rowCount) Query(table Table) string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s", table.FqName)
+}
+
+// notNull checks the number of rows where `column` is NULL, by default this expects none.
+type notNull struct {
+	bounds
+	column string
+}
+
+func newNotNull(cfg kafkalib.AssertionConfig) (Assertion, error) {
+	if cfg.Column == "" {
+		return nil, fmt.Errorf("assertion %q requires a column", cfg.Name)
+	}
+
+	b := bounds{min: cfg.Min, max: cfg.Max}
+	if b.max == nil {
+		var zero float64
+		b.max = &zero
+	}
+
+	return notNull{bounds: b, column: cfg.Column}, nil
+}
+
+func (n notNull) Query(table Table) string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s IS NULL", table.FqName, table.EscapeName(n.column))
+}
+
+// sqlAssertion runs a custom query that returns a single numeric value and checks that it's within bounds.
+type sqlAssertion struct {
+	bounds
+	sql string
+}
+
+func newSQL(cfg kafkalib.AssertionConfig) (Assertion, error) {
+	if !strings.Contains(cfg.SQL, "{table}") {
+		return nil, fmt.Errorf("assertion %q requires sql that references {table}", cfg.Name)
+	}
+
+	b, err := newBounds(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return sqlAssertion{bounds: b, sql: cfg.SQL}, nil
+}
+
+func (s sqlAssertion) Query(table Table) string {
+	return strings.ReplaceAll(s.sql, "{table}", table.FqName)
+}
@@ -0,0 +1,116 @@
+package assertions
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/ptr"
+)
+
+func TestNew(t *testing.T) {
+	{
+		// Unknown type
+		_, err := New(kafkalib.AssertionConfig{Name: "foo", Type: "unknown"})
+		assert.ErrorContains(t, err, `assertion "foo" has an unknown type: "unknown"`)
+	}
+	{
+		// Row count without bounds
+		_, err := New(kafkalib.AssertionConfig{Name: "foo", Type: "rowCount"})
+		assert.ErrorContains(t, err, `assertion "foo" requires a min or max`)
+	}
+	{
+		// Not null without a column
+		_, err := New(kafkalib.AssertionConfig{Name: "foo", Type: "notNull"})
+		assert.ErrorContains(t, err, `assertion "foo" requires a column`)
+	}
+	{
+		// Not null
+		assertion, err := New(kafkalib.AssertionConfig{Name: "foo", Type: "notNull", Column: "email"})
+		assert.NoError(t, err)
+		table := Table{FqName: "db.schema.users", EscapeName: func(name string) string { return fmt.Sprintf(`"%s"`, name) }}
+		assert.Equal(t, `SELECT COUNT(*) FROM db.schema.users WHERE "email" IS NULL`, assertion.Query(table))
+		assert.NoError(t, assertion.Expect(0))
+		assert.ErrorContains(t, assertion.Expect(1), "value: 1 is greater than the max: 0")
+	}
+	{
+		// SQL without a table
+		_, err := New(kafkalib.AssertionConfig{Name: "foo", Type: "sql", SQL: "SELECT 1", Max: ptr.ToFloat64(1)})
+		assert.ErrorContains(t, err, `assertion "foo" requires sql that references {table}`)
+	}
+	{
+		// SQL
+		assertion, err := New(kafkalib.AssertionConfig{Name: "foo", Type: "sql", SQL: "SELECT MAX(price) FROM {table}", Max: ptr.ToFloat64(1000)})
+		assert.NoError(t, err)
+		assert.Equal(t, "SELECT MAX(price) FROM db.schema.orders", assertion.Query(Table{FqName: "db.schema.orders"}))
+	}
+}
+
+type constantAssertion struct{}
+
+func (constantAssertion) Query(_ Table) string { return "SELECT 1" }
+func (constantAssertion) Expect(value float64) error {
+	if value != 1 {
+		return fmt.Errorf("expected 1")
+	}
+
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	Register("constant", func(_ kafkalib.AssertionConfig) (Assertion, error) {
+		return constantAssertion{}, nil
+	})
+
+	assertion, err := New(kafkalib.AssertionConfig{Name: "foo", Type: "constant"})
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT 1", assertion.Query(Table{FqName: "db.schema.users"}))
+}
+
+func TestRun_NumericBounds(t *testing.T) {
+	cfgs := []kafkalib.AssertionConfig{
+		{
+			Name: "row count",
+			Type: "rowCount",
+			Min:  ptr.ToFloat64(10),
+			Max:  ptr.ToFloat64(100),
+		},
+	}
+
+	var queries []string
+	queryValue := func(value float64) func(query string) (float64, error) {
+		return func(query string) (float64, error) {
+			queries = append(queries, query)
+			return value, nil
+		}
+	}
+
+	{
+		// Passing
+		assert.Empty(t, run(Table{FqName: "db.schema.orders"}, cfgs, queryValue(50)))
+		assert.Equal(t, []string{"SELECT COUNT(*) FROM db.schema.orders"}, queries)
+	}
+	{
+		// Below the min
+		failures := run(Table{FqName: "db.schema.orders"}, cfgs, queryValue(5))
+		assert.Len(t, failures, 1)
+		assert.Equal(t, "row count", failures[0].Config.Name)
+		assert.ErrorContains(t, failures[0].Err, `assertion "row count" failed: value: 5 is less than the min: 10`)
+	}
+	{
+		// Above the max
+		failures := run(Table{FqName: "db.schema.orders"}, cfgs, queryValue(500))
+		assert.Len(t, failures, 1)
+		assert.ErrorContains(t, failures[0].Err, `assertion "row count" failed: value: 500 is greater than the max: 100`)
+	}
+	{
+		// Query failed
+		failures := run(Table{FqName: "db.schema.orders"}, cfgs, func(_ string) (float64, error) {
+			return 0, fmt.Errorf("table does not exist")
+		})
+		assert.Len(t, failures, 1)
+		assert.ErrorContains(t, failures[0].Err, `assertion "row count" failed: failed to run query: table does not exist`)
+	}
+}
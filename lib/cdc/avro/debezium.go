@@ -0,0 +1,148 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/linkedin/goavro/v2"
+
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/cdc/util"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/debezium"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
+	"github.com/artie-labs/transfer/lib/typing"
+)
+
+type codec struct {
+	codec  *goavro.Codec
+	schema *avroSchema
+}
+
+// Debezium parses Debezium events that have been serialized with the Confluent Avro converter.
+type Debezium struct {
+	registry *schemaregistry.Client
+
+	codecs map[int]*codec
+	sync.RWMutex
+}
+
+func NewDebezium(registry *schemaregistry.Client) *Debezium {
+	return &Debezium{
+		registry: registry,
+		codecs:   make(map[int]*codec),
+	}
+}
+
+func (d *Debezium) Labels() []string {
+	return []string{constants.DBZAvroFormat, constants.DBZAvroAltFormat}
+}
+
+func (d *Debezium) getCodec(id int) (*codec, error) {
+	d.RLock()
+	c, isOk := d.codecs[id]
+	d.RUnlock()
+	if isOk {
+		return c, nil
+	}
+
+	schemaString, err := d.registry.GetSchema(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schema: %w", err)
+	}
+
+	schema, err := newAvroSchema(schemaString)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaBytes, err := json.Marshal(withoutLogicalTypes(schema.root))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal avro schema: %w", err)
+	}
+
+	avroCodec, err := goavro.NewCodec(string(schemaBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create avro codec for schema id: %d: %w", id, err)
+	}
+
+	c = &codec{codec: avroCodec, schema: schema}
+	d.Lock()
+	d.codecs[id] = c
+	d.Unlock()
+	return c, nil
+}
+
+// invalidate drops the cached codec and schema so that the next message with `id` will re-fetch the schema.
+func (d *Debezium) invalidate(id int) {
+	d.Lock()
+	delete(d.codecs, id)
+	d.Unlock()
+
+	d.registry.Invalidate(id)
+}
+
+// decode will decode the Avro message and return the schema along with the normalized (JSON-like) value.
+func (d *Debezium) decode(bytes []byte) (*avroSchema, any, error) {
+	id, payload, err := schemaregistry.ParseWireFormat(bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c, err := d.getCodec(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	native, _, err := c.codec.NativeFromBinary(payload)
+	if err != nil {
+		d.invalidate(id)
+		return nil, nil, fmt.Errorf("failed to decode avro message with schema id: %d: %w", id, err)
+	}
+
+	return c.schema, c.schema.normalize(c.schema.root, "", native), nil
+}
+
+func (d *Debezium) GetEventFromBytes(typingSettings typing.Settings, bytes []byte) (cdc.Event, error) {
+	if len(bytes) == 0 {
+		return nil, fmt.Errorf("empty message")
+	}
+
+	schema, payload, err := d.decode(bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	dbzSchema, err := schema.toDebeziumSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := util.NewSchemaEventPayload(typingSettings, dbzSchema, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func (d *Debezium) GetPrimaryKey(key []byte, tc *kafkalib.TopicConfig) (map[string]any, error) {
+	if !schemaregistry.HasWireFormat(key) {
+		// Keys are not required to use the same converter as the value.
+		return debezium.ParsePartitionKey(key, tc.CDCKeyFormat)
+	}
+
+	_, payload, err := d.decode(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key: %w", err)
+	}
+
+	jsonBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal key: %w", err)
+	}
+
+	return debezium.ParsePartitionKey(jsonBytes, kafkalib.JSONKeyFmt)
+}
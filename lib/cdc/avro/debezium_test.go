@@ -0,0 +1,266 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/debezium"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+const (
+	valueSchemaID = 1
+	keySchemaID   = 2
+
+	// Trimmed down version of what the Confluent Avro converter emits for a Debezium Postgres table.
+	valueSchema = `{
+  "type": "record",
+  "name": "Envelope",
+  "namespace": "dbserver1.public.customers",
+  "fields": [
+    {"name": "before", "type": ["null", {
+      "type": "record",
+      "name": "Value",
+      "fields": [
+        {"name": "id", "type": "int"},
+        {"name": "first_name", "type": ["null", "string"], "default": null},
+        {"name": "age", "type": ["null", {"type": "int", "connect.type": "int16"}], "default": null},
+        {"name": "balance", "type": ["null", {"type": "bytes", "scale": 2, "precision": 10, "connect.version": 1, "connect.parameters": {"scale": "2", "connect.decimal.precision": "10"}, "connect.name": "org.apache.kafka.connect.data.Decimal", "logicalType": "decimal"}], "default": null},
+        {"name": "birthday", "type": ["null", {"type": "int", "connect.version": 1, "connect.name": "io.debezium.time.Date"}], "default": null},
+        {"name": "active", "type": "boolean", "default": true}
+      ],
+      "connect.name": "dbserver1.public.customers.Value"
+    }], "default": null},
+    {"name": "after", "type": ["null", "Value"], "default": null},
+    {"name": "source", "type": {
+      "type": "record",
+      "name": "Source",
+      "namespace": "io.debezium.connector.postgresql",
+      "fields": [
+        {"name": "connector", "type": "string"},
+        {"name": "ts_ms", "type": "long"},
+        {"name": "db", "type": "string"},
+        {"name": "schema", "type": "string"},
+        {"name": "table", "type": "string"}
+      ]
+    }},
+    {"name": "op", "type": "string"},
+    {"name": "ts_ms", "type": ["null", "long"], "default": null}
+  ],
+  "connect.name": "dbserver1.public.customers.Envelope"
+}`
+
+	keySchema = `{"type": "record", "name": "Key", "namespace": "dbserver1.public.customers", "fields": [{"name": "id", "type": "int"}]}`
+)
+
+func newRegistry(t *testing.T) (*schemaregistry.Client, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var schema string
+		switch r.URL.Path {
+		case fmt.Sprintf("/schemas/ids/%d", valueSchemaID):
+			schema = valueSchema
+		case fmt.Sprintf("/schemas/ids/%d", keySchemaID):
+			schema = keySchema
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		resp, err := json.Marshal(map[string]string{"schema": schema})
+		assert.NoError(t, err)
+		w.Write(resp)
+	}))
+
+	return schemaregistry.NewClient(config.SchemaRegistry{URL: server.URL}), server.Close
+}
+
+func encode(t *testing.T, schemaID int, schema string, native any) []byte {
+	parsedSchema, err := newAvroSchema(schema)
+	assert.NoError(t, err)
+
+	schemaBytes, err := json.Marshal(withoutLogicalTypes(parsedSchema.root))
+	assert.NoError(t, err)
+
+	codec, err := goavro.NewCodec(string(schemaBytes))
+	assert.NoError(t, err)
+
+	header := make([]byte, 5)
+	binary.BigEndian.PutUint32(header[1:], uint32(schemaID))
+	bytes, err := codec.BinaryFromNative(header, native)
+	assert.NoError(t, err)
+	return bytes
+}
+
+func TestDebezium_Labels(t *testing.T) {
+	assert.Equal(t, []string{constants.DBZAvroFormat, constants.DBZAvroAltFormat}, NewDebezium(nil).Labels())
+}
+
+func TestDebezium_GetEventFromBytes(t *testing.T) {
+	registry, closeFn := newRegistry(t)
+	defer closeFn()
+
+	d := NewDebezium(registry)
+	{
+		// Empty message
+		_, err := d.GetEventFromBytes(typing.Settings{}, nil)
+		assert.ErrorContains(t, err, "empty message")
+	}
+	{
+		// Not in the wire format
+		_, err := d.GetEventFromBytes(typing.Settings{}, []byte(`{"payload": {}}`))
+		assert.ErrorContains(t, err, "unexpected magic byte")
+	}
+	{
+		// Unknown schema
+		_, err := d.GetEventFromBytes(typing.Settings{}, encode(t, 99, `"string"`, "foo"))
+		assert.ErrorContains(t, err, "failed to fetch schema id: 99")
+	}
+	{
+		// Create event
+		bytes := encode(t, valueSchemaID, valueSchema, map[string]any{
+			"before": nil,
+			"after": goavro.Union("dbserver1.public.customers.Value", map[string]any{
+				"id":         int32(1001),
+				"first_name": goavro.Union("string", "Sally"),
+				"age":        goavro.Union("int", int32(35)),
+				// 12.34 with a scale of 2 is 1234 => 0x04D2
+				"balance":  goavro.Union("bytes", []byte{0x04, 0xD2}),
+				"birthday": goavro.Union("int", int32(19000)),
+				"active":   false,
+			}),
+			"source": map[string]any{
+				"connector": "postgresql",
+				"ts_ms":     int64(1700000000000),
+				"db":        "postgres",
+				"schema":    "public",
+				"table":     "customers",
+			},
+			"op":    "c",
+			"ts_ms": goavro.Union("long", int64(1700000000500)),
+		})
+
+		evt, err := d.GetEventFromBytes(typing.Settings{}, bytes)
+		assert.NoError(t, err)
+		assert.Equal(t, "customers", evt.GetTableName())
+		assert.Equal(t, "c", evt.Operation())
+		assert.False(t, evt.DeletePayload())
+		assert.Equal(t, time.UnixMilli(1700000000000).UTC(), evt.GetExecutionTime())
+
+		// The schema should match what the JSON converter would have emitted.
+		afterSchema := evt.(interface {
+			GetOptionalSchema() map[string]typing.KindDetails
+		}).GetOptionalSchema()
+		assert.Equal(t, typing.NewIntegerKindDetails(typing.RegularIntegerKind), afterSchema["id"])
+		assert.Equal(t, typing.String, afterSchema["first_name"])
+		assert.Equal(t, typing.NewIntegerKindDetails(typing.SmallIntegerKind), afterSchema["age"])
+		assert.Equal(t, typing.EDecimal.Kind, afterSchema["balance"].Kind)
+		assert.Equal(t, ext.DateKindType, afterSchema["birthday"].ExtendedTimeDetails.Type)
+		assert.Equal(t, typing.Boolean, afterSchema["active"])
+
+		data := evt.GetData(map[string]any{"id": 1001}, &kafkalib.TopicConfig{})
+		assert.Equal(t, 1001, data["id"])
+		assert.Equal(t, "Sally", data["first_name"])
+		assert.Equal(t, 35, data["age"])
+		assert.Equal(t, "12.34", data["balance"].(*decimal.Decimal).String())
+		assert.Equal(t, "2022-01-08", data["birthday"].(*ext.ExtendedTime).String(""))
+		assert.Equal(t, false, data["active"])
+		assert.Equal(t, false, data[constants.DeleteColumnMarker])
+	}
+	{
+		// Delete event
+		bytes := encode(t, valueSchemaID, valueSchema, map[string]any{
+			"before": goavro.Union("dbserver1.public.customers.Value", map[string]any{
+				"id":         int32(1001),
+				"first_name": nil,
+				"age":        nil,
+				"balance":    nil,
+				"birthday":   nil,
+				"active":     true,
+			}),
+			"after": nil,
+			"source": map[string]any{
+				"connector": "postgresql",
+				"ts_ms":     int64(1700000000000),
+				"db":        "postgres",
+				"schema":    "public",
+				"table":     "customers",
+			},
+			"op":    "d",
+			"ts_ms": nil,
+		})
+
+		evt, err := d.GetEventFromBytes(typing.Settings{}, bytes)
+		assert.NoError(t, err)
+		assert.True(t, evt.DeletePayload())
+
+		data := evt.GetData(map[string]any{"id": 1001}, &kafkalib.TopicConfig{})
+		assert.Equal(t, 1001, data["id"])
+		assert.Equal(t, true, data[constants.DeleteColumnMarker])
+	}
+}
+
+func TestDebezium_GetPrimaryKey(t *testing.T) {
+	registry, closeFn := newRegistry(t)
+	defer closeFn()
+
+	d := NewDebezium(registry)
+	{
+		// Avro encoded key
+		pkMap, err := d.GetPrimaryKey(encode(t, keySchemaID, keySchema, map[string]any{"id": int32(47)}), &kafkalib.TopicConfig{})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": float64(47)}, pkMap)
+	}
+	{
+		// JSON encoded key
+		pkMap, err := d.GetPrimaryKey([]byte(`{"id": 47}`), &kafkalib.TopicConfig{CDCKeyFormat: kafkalib.JSONKeyFmt})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": float64(47)}, pkMap)
+	}
+}
+
+func TestAvroSchema_ToDebeziumSchema(t *testing.T) {
+	schema, err := newAvroSchema(valueSchema)
+	assert.NoError(t, err)
+
+	dbzSchema, err := schema.toDebeziumSchema()
+	assert.NoError(t, err)
+
+	after := dbzSchema.GetSchemaFromLabel(cdc.After)
+	assert.NotNil(t, after)
+	assert.True(t, after.Optional)
+	assert.Equal(t, "struct", after.FieldObjectType)
+	assert.Equal(t, []debezium.Field{
+		{FieldName: "id", Type: debezium.Int32},
+		{FieldName: "first_name", Type: debezium.String, Optional: true},
+		{FieldName: "age", Type: debezium.Int16, Optional: true},
+		{
+			FieldName:    "balance",
+			Type:         debezium.Bytes,
+			Optional:     true,
+			DebeziumType: debezium.KafkaDecimalType,
+			Parameters:   map[string]any{"scale": "2", "connect.decimal.precision": "10"},
+		},
+		{FieldName: "birthday", Type: debezium.Int32, Optional: true, DebeziumType: debezium.Date},
+		{FieldName: "active", Type: debezium.Boolean, Default: true},
+	}, after.Fields)
+
+	source := dbzSchema.GetSchemaFromLabel(cdc.Source)
+	assert.NotNil(t, source)
+	assert.False(t, source.Optional)
+	assert.Len(t, source.Fields, 5)
+}
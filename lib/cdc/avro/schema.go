@@ -0,0 +1,355 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/debezium"
+)
+
+const (
+	connectNameKey       = "connect.name"
+	connectTypeKey       = "connect.type"
+	connectParametersKey = "connect.parameters"
+	logicalTypeKey       = "logicalType"
+)
+
+// avroSchema is a parsed Avro schema with all the named types (records, enums and fixed) indexed by their full name,
+// so that we can resolve type references while walking the schema.
+type avroSchema struct {
+	root  any
+	named map[string]map[string]any
+}
+
+func newAvroSchema(schema string) (*avroSchema, error) {
+	var root any
+	if err := json.Unmarshal([]byte(schema), &root); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal avro schema: %w", err)
+	}
+
+	a := &avroSchema{
+		root:  root,
+		named: make(map[string]map[string]any),
+	}
+
+	a.index(root, "")
+	return a, nil
+}
+
+func fullName(node map[string]any, namespace string) string {
+	name, _ := node["name"].(string)
+	if strings.Contains(name, ".") {
+		return name
+	}
+
+	if ns, isOk := node["namespace"].(string); isOk {
+		namespace = ns
+	}
+
+	if namespace == "" {
+		return name
+	}
+
+	return namespace + "." + name
+}
+
+func namespaceOf(name string) string {
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		return name[:idx]
+	}
+
+	return ""
+}
+
+func (a *avroSchema) index(node any, namespace string) {
+	switch castedNode := node.(type) {
+	case []any:
+		for _, branch := range castedNode {
+			a.index(branch, namespace)
+		}
+	case map[string]any:
+		switch castedNode["type"] {
+		case "record", "error":
+			name := fullName(castedNode, namespace)
+			a.named[name] = castedNode
+			for _, field := range fields(castedNode) {
+				a.index(field["type"], namespaceOf(name))
+			}
+		case "enum", "fixed":
+			a.named[fullName(castedNode, namespace)] = castedNode
+		case "array":
+			a.index(castedNode["items"], namespace)
+		case "map":
+			a.index(castedNode["values"], namespace)
+		}
+	}
+}
+
+// resolve will return the type definition if `node` is a reference to a named type.
+func (a *avroSchema) resolve(node any, namespace string) (any, string) {
+	ref, isOk := node.(string)
+	if !isOk {
+		return node, namespace
+	}
+
+	if named, isOk := a.named[ref]; isOk {
+		return named, namespaceOf(ref)
+	}
+
+	if named, isOk := a.named[namespace+"."+ref]; isOk {
+		return named, namespace
+	}
+
+	return node, namespace
+}
+
+func fields(record map[string]any) []map[string]any {
+	rawFields, _ := record["fields"].([]any)
+	var out []map[string]any
+	for _, rawField := range rawFields {
+		if field, isOk := rawField.(map[string]any); isOk {
+			out = append(out, field)
+		}
+	}
+
+	return out
+}
+
+// typeName returns the name that goavro uses as the key when it decodes a union value.
+func typeName(node any, namespace string) string {
+	switch castedNode := node.(type) {
+	case string:
+		return castedNode
+	case map[string]any:
+		typeString, _ := castedNode["type"].(string)
+		switch typeString {
+		case "record", "error", "enum", "fixed":
+			return fullName(castedNode, namespace)
+		}
+
+		return typeString
+	}
+
+	return ""
+}
+
+// nonNullBranch returns the first non-null branch of a union and whether the union is nullable.
+func (a *avroSchema) nonNullBranch(union []any, namespace string) (any, string, bool) {
+	var optional bool
+	var branch any
+	var branchNamespace string
+	for _, candidate := range union {
+		if candidate == "null" {
+			optional = true
+			continue
+		}
+
+		if branch == nil {
+			branch, branchNamespace = a.resolve(candidate, namespace)
+		}
+	}
+
+	return branch, branchNamespace, optional
+}
+
+// normalize will take the value that goavro decoded and strip the union wrappers so that it has the same shape as a JSON payload.
+func (a *avroSchema) normalize(node any, namespace string, value any) any {
+	if value == nil {
+		return nil
+	}
+
+	node, namespace = a.resolve(node, namespace)
+	switch castedNode := node.(type) {
+	case []any:
+		union, isOk := value.(map[string]any)
+		if !isOk || len(union) != 1 {
+			return value
+		}
+
+		for name, unionValue := range union {
+			for _, branch := range castedNode {
+				resolvedBranch, branchNamespace := a.resolve(branch, namespace)
+				if typeName(resolvedBranch, branchNamespace) == name {
+					return a.normalize(resolvedBranch, branchNamespace, unionValue)
+				}
+			}
+
+			return unionValue
+		}
+	case map[string]any:
+		switch castedNode["type"] {
+		case "record", "error":
+			record, isOk := value.(map[string]any)
+			if !isOk {
+				return value
+			}
+
+			recordNamespace := namespaceOf(fullName(castedNode, namespace))
+			out := make(map[string]any, len(record))
+			for _, field := range fields(castedNode) {
+				name, _ := field["name"].(string)
+				out[name] = a.normalize(field["type"], recordNamespace, record[name])
+			}
+
+			return out
+		case "array":
+			items, isOk := value.([]any)
+			if !isOk {
+				return value
+			}
+
+			out := make([]any, len(items))
+			for i, item := range items {
+				out[i] = a.normalize(castedNode["items"], namespace, item)
+			}
+
+			return out
+		case "map":
+			values, isOk := value.(map[string]any)
+			if !isOk {
+				return value
+			}
+
+			out := make(map[string]any, len(values))
+			for key, val := range values {
+				out[key] = a.normalize(castedNode["values"], namespace, val)
+			}
+
+			return out
+		}
+	}
+
+	return value
+}
+
+func toFieldType(avroType string, connectType string) debezium.FieldType {
+	if connectType != "" {
+		return debezium.FieldType(connectType)
+	}
+
+	switch avroType {
+	case "string", "enum":
+		return debezium.String
+	case "bytes", "fixed":
+		return debezium.Bytes
+	case "boolean":
+		return debezium.Boolean
+	case "int":
+		return debezium.Int32
+	case "long":
+		return debezium.Int64
+	case "float":
+		return debezium.Float
+	case "double":
+		return debezium.Double
+	case "record":
+		return debezium.Struct
+	case "array":
+		return debezium.Array
+	case "map":
+		return debezium.Map
+	}
+
+	return debezium.FieldType(avroType)
+}
+
+func (a *avroSchema) toField(field map[string]any, namespace string) debezium.Field {
+	name, _ := field["name"].(string)
+	node, namespace := a.resolve(field["type"], namespace)
+
+	dbzField := debezium.Field{FieldName: name}
+	if union, isOk := node.([]any); isOk {
+		node, namespace, dbzField.Optional = a.nonNullBranch(union, namespace)
+	}
+
+	switch castedNode := node.(type) {
+	case string:
+		dbzField.Type = toFieldType(castedNode, "")
+	case map[string]any:
+		avroType, _ := castedNode["type"].(string)
+		connectType, _ := castedNode[connectTypeKey].(string)
+		dbzField.Type = toFieldType(avroType, connectType)
+		if connectName, isOk := castedNode[connectNameKey].(string); isOk {
+			dbzField.DebeziumType = debezium.SupportedDebeziumType(connectName)
+		}
+
+		if params, isOk := castedNode[connectParametersKey].(map[string]any); isOk {
+			dbzField.Parameters = params
+		}
+	}
+
+	// Avro encodes bytes defaults as unicode strings, which is not the same as the base64 encoding Debezium uses in JSON.
+	if dbzField.Type != debezium.Bytes {
+		dbzField.Default = field["default"]
+	}
+
+	return dbzField
+}
+
+// toDebeziumSchema converts the Debezium envelope's Avro schema into the same schema we would get from the JSON converter.
+func (a *avroSchema) toDebeziumSchema() (debezium.Schema, error) {
+	root, namespace := a.resolve(a.root, "")
+	envelope, isOk := root.(map[string]any)
+	if !isOk || envelope["type"] != "record" {
+		return debezium.Schema{}, fmt.Errorf("expected the avro schema to be a record")
+	}
+
+	schema := debezium.Schema{SchemaType: string(debezium.Struct)}
+	envelopeNamespace := namespaceOf(fullName(envelope, namespace))
+	for _, field := range fields(envelope) {
+		name, _ := field["name"].(string)
+		fieldsObject := debezium.FieldsObject{FieldLabel: cdc.FieldLabelKind(name)}
+
+		node, nodeNamespace := a.resolve(field["type"], envelopeNamespace)
+		if union, isOk := node.([]any); isOk {
+			node, nodeNamespace, fieldsObject.Optional = a.nonNullBranch(union, nodeNamespace)
+		}
+
+		switch castedNode := node.(type) {
+		case string:
+			fieldsObject.FieldObjectType = string(toFieldType(castedNode, ""))
+		case map[string]any:
+			avroType, _ := castedNode["type"].(string)
+			fieldsObject.FieldObjectType = string(toFieldType(avroType, ""))
+			if avroType == "record" {
+				recordNamespace := namespaceOf(fullName(castedNode, nodeNamespace))
+				for _, recordField := range fields(castedNode) {
+					fieldsObject.Fields = append(fieldsObject.Fields, a.toField(recordField, recordNamespace))
+				}
+			}
+		}
+
+		schema.FieldsObject = append(schema.FieldsObject, fieldsObject)
+	}
+
+	return schema, nil
+}
+
+// withoutLogicalTypes returns a copy of the schema without any logical types.
+// We do this so goavro decodes the underlying primitives (e.g. bytes for decimals), which our Debezium value parser already knows how to handle.
+func withoutLogicalTypes(node any) any {
+	switch castedNode := node.(type) {
+	case []any:
+		out := make([]any, len(castedNode))
+		for i, item := range castedNode {
+			out[i] = withoutLogicalTypes(item)
+		}
+
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(castedNode))
+		for key, val := range castedNode {
+			if key == logicalTypeKey {
+				continue
+			}
+
+			out[key] = withoutLogicalTypes(val)
+		}
+
+		return out
+	}
+
+	return node
+}
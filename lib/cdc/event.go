@@ -20,6 +20,8 @@ type Event interface {
 	GetExecutionTime() time.Time
 	Operation() string
 	DeletePayload() bool
+	// IsSnapshot returns true if the row was read by a snapshot (initial or incremental), rather than from streaming changes.
+	IsSnapshot() bool
 	GetTableName() string
 	GetData(pkMap map[string]any, config *kafkalib.TopicConfig) map[string]any
 	GetOptionalSchema() map[string]typing.KindDetails
@@ -27,6 +29,23 @@ type Event interface {
 	GetColumns() *columns.Columns
 }
 
+// ValueDetector is an optional interface for formats that need to see the message value before they can parse the key (e.g. when the format is detected from the message).
+type ValueDetector interface {
+	DetectFromValue(value []byte)
+}
+
+// PartialEvent is an optional interface for events that may only carry the columns that have changed (e.g. MongoDB $set patches).
+// Columns that are missing from a partial event should keep their existing value.
+type PartialEvent interface {
+	IsPartial() bool
+}
+
+// TruncateOperation is the operation Debezium uses for TRUNCATE TABLE events, these events do not have a key or any row data.
+const TruncateOperation = "t"
+
+// DeleteOperation is the operation Debezium uses for deletes.
+const DeleteOperation = "d"
+
 // FieldLabelKind is used when the schema is turned on. Each schema object will be labelled.
 type FieldLabelKind string
 
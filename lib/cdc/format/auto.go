@@ -0,0 +1,174 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/cdc/avro"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/debezium"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
+	"github.com/artie-labs/transfer/lib/typing"
+)
+
+// envelope contains just enough of a Debezium message to tell the formats apart.
+type envelope struct {
+	Payload struct {
+		Before json.RawMessage `json:"before"`
+		After  json.RawMessage `json:"after"`
+		Patch  json.RawMessage `json:"patch"`
+		Source struct {
+			Connector string `json:"connector"`
+		} `json:"source"`
+	} `json:"payload"`
+}
+
+// isMongo returns true if this was emitted by Debezium's MongoDB connector.
+// The MongoDB connector serializes the document as an extended JSON string, whereas relational connectors will emit an object.
+func (e envelope) isMongo() bool {
+	if e.Payload.Source.Connector != "" {
+		return e.Payload.Source.Connector == "mongodb"
+	}
+
+	for _, value := range []json.RawMessage{e.Payload.After, e.Payload.Before} {
+		if len(value) > 0 && value[0] == '"' {
+			return true
+		}
+	}
+
+	return len(e.Payload.Patch) > 0 && string(e.Payload.Patch) != "null"
+}
+
+// autoFormat will inspect the message to pick the parser, this is used when the topic does not specify a CDC format.
+// Once a message value has been parsed, the format is locked in for the rest of the topic.
+type autoFormat struct {
+	relational cdc.Format
+	mongo      cdc.Format
+	// avro is nil if we don't have a schema registry.
+	avro  cdc.Format
+	topic string
+
+	mu       sync.Mutex
+	detected cdc.Format
+}
+
+func newAutoFormat(topic string, registry *schemaregistry.Client) *autoFormat {
+	auto := &autoFormat{relational: &d, mongo: &m, topic: topic}
+	if registry != nil {
+		auto.avro = avro.NewDebezium(registry)
+	}
+
+	return auto
+}
+
+func (a *autoFormat) Labels() []string {
+	return []string{constants.AutoFormat}
+}
+
+func (a *autoFormat) getDetected() cdc.Format {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.detected
+}
+
+func (a *autoFormat) setDetected(format cdc.Format) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.detected == nil {
+		slog.Info("Detected CDC format", slog.String("label", format.Labels()[0]), slog.String("topic", a.topic))
+	}
+
+	a.detected = format
+}
+
+// detect will pick the parser for a message value.
+func (a *autoFormat) detect(bytes []byte) (cdc.Format, error) {
+	if len(bytes) == 0 {
+		return nil, fmt.Errorf("empty message")
+	}
+
+	if schemaregistry.HasWireFormat(bytes) {
+		if a.avro == nil {
+			return nil, fmt.Errorf("message looks like it was serialized with a schema registry, but the schema registry is not configured")
+		}
+
+		return a.avro, nil
+	}
+
+	var evt envelope
+	if err := json.Unmarshal(bytes, &evt); err != nil {
+		return nil, fmt.Errorf("failed to detect cdc format, message is not JSON: %w", err)
+	}
+
+	if evt.isMongo() {
+		return a.mongo, nil
+	}
+
+	return a.relational, nil
+}
+
+// DetectFromValue will lock in the format from the message value, this is called before the key is parsed.
+// Otherwise, a MongoDB key with a numeric id (e.g. Struct{id=1001}) would be parsed as a relational key.
+func (a *autoFormat) DetectFromValue(value []byte) {
+	if a.getDetected() != nil {
+		return
+	}
+
+	// Tombstones and messages that we cannot detect will fall back to inspecting the key.
+	if format, err := a.detect(value); err == nil {
+		a.setDetected(format)
+	}
+}
+
+func (a *autoFormat) GetEventFromBytes(typingSettings typing.Settings, bytes []byte) (cdc.Event, error) {
+	format, err := a.detect(bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	evt, err := format.GetEventFromBytes(typingSettings, bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	a.setDetected(format)
+	return evt, nil
+}
+
+// GetPrimaryKey will use the detected format, see [autoFormat.DetectFromValue]. If we have not detected one yet, we'll inspect the key.
+// MongoDB keys are serialized as extended JSON (e.g. {"id":"{\"$oid\": \"...\"}"}), so numeric MongoDB ids cannot be told apart from a relational key by the key alone.
+func (a *autoFormat) GetPrimaryKey(key []byte, tc *kafkalib.TopicConfig) (map[string]any, error) {
+	if detected := a.getDetected(); detected != nil {
+		return detected.GetPrimaryKey(key, tc)
+	}
+
+	if a.avro != nil && schemaregistry.HasWireFormat(key) {
+		return a.avro.GetPrimaryKey(key, tc)
+	}
+
+	kvMap, err := debezium.ParsePartitionKey(key, tc.CDCKeyFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	if id, isOk := kvMap["id"].(string); isOk && len(kvMap) == 1 && isExtendedJSON(id) {
+		return a.mongo.GetPrimaryKey(key, tc)
+	}
+
+	return kvMap, nil
+}
+
+// isExtendedJSON returns true if the value is a JSON object or a JSON string, which is how MongoDB ids are written to the key.
+func isExtendedJSON(value string) bool {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "{") && !strings.HasPrefix(value, `"`) {
+		return false
+	}
+
+	return json.Valid([]byte(value))
+}
@@ -0,0 +1,137 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/cdc/mongo"
+	"github.com/artie-labs/transfer/lib/cdc/util"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/typing"
+)
+
+const relationalPayload = `{
+	"schema": {},
+	"payload": {
+		"before": null,
+		"after": {"id": 1001, "first_name": "Sally"},
+		"source": {"connector": "postgresql", "ts_ms": 1668753321000, "db": "inventory", "schema": "public", "table": "customers"},
+		"op": "c",
+		"ts_ms": 1668753329388
+	}
+}`
+
+const mongoPayload = `{
+	"schema": {},
+	"payload": {
+		"before": null,
+		"after": "{\"_id\": {\"$numberLong\": \"10004\"}, \"quantity\": 1}",
+		"patch": null,
+		"filter": null,
+		"source": {"connector": "mongodb", "ts_ms": 1668753321000, "db": "inventory", "collection": "orders"},
+		"op": "c",
+		"ts_ms": 1668753329388
+	}
+}`
+
+func TestGetFormatParser_Auto(t *testing.T) {
+	for _, cdcFormat := range []string{"", constants.AutoFormat} {
+		parser, err := GetFormatParser(&kafkalib.TopicConfig{CDCFormat: cdcFormat, Topic: "topicA"}, nil)
+		assert.NoError(t, err, cdcFormat)
+		assert.Equal(t, []string{constants.AutoFormat}, parser.Labels(), cdcFormat)
+	}
+}
+
+func TestAutoFormat_GetEventFromBytes(t *testing.T) {
+	{
+		// Relational
+		auto := newAutoFormat("customers", nil)
+		evt, err := auto.GetEventFromBytes(typing.Settings{}, []byte(relationalPayload))
+		assert.NoError(t, err)
+		assert.IsType(t, &util.SchemaEventPayload{}, evt)
+		assert.Equal(t, "customers", evt.GetTableName())
+		assert.Equal(t, "Sally", evt.GetData(map[string]any{"id": 1001}, &kafkalib.TopicConfig{})["first_name"])
+		assert.Equal(t, auto.relational, auto.getDetected())
+	}
+	{
+		// MongoDB
+		auto := newAutoFormat("orders", nil)
+		evt, err := auto.GetEventFromBytes(typing.Settings{}, []byte(mongoPayload))
+		assert.NoError(t, err)
+		assert.IsType(t, &mongo.SchemaEventPayload{}, evt)
+		assert.Equal(t, "orders", evt.GetTableName())
+		assert.Equal(t, int64(10004), evt.GetData(map[string]any{"_id": int64(10004)}, &kafkalib.TopicConfig{})["_id"])
+		assert.Equal(t, auto.mongo, auto.getDetected())
+	}
+	{
+		// MongoDB without a connector, the document is a string
+		auto := newAutoFormat("orders", nil)
+		evt, err := auto.GetEventFromBytes(typing.Settings{}, []byte(`{"payload": {"after": "{\"_id\": \"abc\"}", "op": "c", "source": {"collection": "orders"}}}`))
+		assert.NoError(t, err)
+		assert.IsType(t, &mongo.SchemaEventPayload{}, evt)
+	}
+	{
+		// Not JSON
+		auto := newAutoFormat("orders", nil)
+		_, err := auto.GetEventFromBytes(typing.Settings{}, []byte("hello"))
+		assert.ErrorContains(t, err, "failed to detect cdc format, message is not JSON")
+		assert.Nil(t, auto.getDetected())
+	}
+	{
+		// Schema registry is not configured
+		auto := newAutoFormat("orders", nil)
+		_, err := auto.GetEventFromBytes(typing.Settings{}, []byte{0, 0, 0, 0, 1, 2})
+		assert.ErrorContains(t, err, "the schema registry is not configured")
+	}
+}
+
+func TestAutoFormat_GetPrimaryKey(t *testing.T) {
+	tc := &kafkalib.TopicConfig{CDCKeyFormat: kafkalib.JSONKeyFmt}
+	{
+		// Relational
+		auto := newAutoFormat("customers", nil)
+		pkMap, err := auto.GetPrimaryKey([]byte(`{"id": 1001}`), tc)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": float64(1001)}, pkMap)
+
+		// A string id that is not JSON
+		pkMap, err = auto.GetPrimaryKey([]byte(`{"id": "1001"}`), tc)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"id": "1001"}, pkMap)
+	}
+	{
+		// MongoDB object id
+		auto := newAutoFormat("orders", nil)
+		pkMap, err := auto.GetPrimaryKey([]byte(`{"id":"{\"$oid\": \"63e3a3bf314a4076d249e203\"}"}`), tc)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"_id": "63e3a3bf314a4076d249e203"}, pkMap)
+	}
+	{
+		// Once a message has been parsed, the detected format is used for the key.
+		auto := newAutoFormat("orders", nil)
+		_, err := auto.GetEventFromBytes(typing.Settings{}, []byte(mongoPayload))
+		assert.NoError(t, err)
+
+		pkMap, err := auto.GetPrimaryKey([]byte(`{"id": 1001}`), tc)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"_id": float64(1001)}, pkMap)
+	}
+	{
+		// MongoDB numeric id, detected from the value before the key is parsed
+		auto := newAutoFormat("orders", nil)
+		auto.DetectFromValue([]byte(mongoPayload))
+		assert.Equal(t, auto.mongo, auto.getDetected())
+
+		pkMap, err := auto.GetPrimaryKey([]byte("Struct{id=1001}"), &kafkalib.TopicConfig{CDCKeyFormat: kafkalib.StringKeyFmt})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"_id": "1001"}, pkMap)
+	}
+	{
+		// Tombstones should not lock in a format
+		auto := newAutoFormat("orders", nil)
+		auto.DetectFromValue(nil)
+		assert.Nil(t, auto.getDetected())
+	}
+}
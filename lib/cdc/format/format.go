@@ -1,14 +1,19 @@
 package format
 
 import (
+	"fmt"
 	"log/slog"
 
 	"github.com/artie-labs/transfer/lib/cdc/mysql"
 
 	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/cdc/avro"
 	"github.com/artie-labs/transfer/lib/cdc/mongo"
 	"github.com/artie-labs/transfer/lib/cdc/postgres"
-	"github.com/artie-labs/transfer/lib/logger"
+	"github.com/artie-labs/transfer/lib/cdc/protobuf"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
 )
 
 var (
@@ -17,11 +22,34 @@ var (
 	mySQL mysql.Debezium
 )
 
-func GetFormatParser(label, topic string) cdc.Format {
+// GetFormatParser returns the parser for the topic's CDC format, `registry` is only required for formats that are serialized with a schema registry.
+// This will return an error if the parser cannot be initialized, so we can fail at startup instead of when the first message is consumed.
+func GetFormatParser(tc *kafkalib.TopicConfig, registry *schemaregistry.Client) (cdc.Format, error) {
+	label, topic := tc.CDCFormat, tc.Topic
+	if label == "" || label == constants.AutoFormat {
+		slog.Info("Loaded CDC Format parser, the format will be detected from the message...", slog.String("topic", topic))
+		return newAutoFormat(topic, registry), nil
+	}
+
 	validFormats := []cdc.Format{
 		&d, &m, &mySQL,
 	}
 
+	if registry != nil {
+		validFormats = append(validFormats, avro.NewDebezium(registry))
+	} else if label == constants.DBZAvroFormat || label == constants.DBZAvroAltFormat {
+		return nil, fmt.Errorf("cdc format: %q requires a schema registry, but the schema registry is not configured", label)
+	}
+
+	if tc.ProtobufSettings != nil {
+		protobufParser, err := protobuf.NewDebezium(*tc.ProtobufSettings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load protobuf parser: %w", err)
+		}
+
+		validFormats = append(validFormats, protobufParser)
+	}
+
 	for _, validFormat := range validFormats {
 		for _, fmtLabel := range validFormat.Labels() {
 			if fmtLabel == label {
@@ -29,11 +57,10 @@ func GetFormatParser(label, topic string) cdc.Format {
 					slog.String("label", label),
 					slog.String("topic", topic),
 				)
-				return validFormat
+				return validFormat, nil
 			}
 		}
 	}
 
-	logger.Panic("Failed to fetch CDC format parser", slog.String("label", label))
-	return nil
+	return nil, fmt.Errorf("unsupported cdc format: %q", label)
 }
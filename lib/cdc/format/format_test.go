@@ -1,41 +1,53 @@
 package format
 
 import (
-	"os"
-	"os/exec"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
 )
 
 func TestGetFormatParser(t *testing.T) {
 	validFormats := []string{constants.DBZPostgresAltFormat, constants.DBZPostgresFormat, constants.DBZMongoFormat}
 	for _, validFormat := range validFormats {
-		assert.NotNil(t, GetFormatParser(validFormat, "topicA"))
+		parser, err := GetFormatParser(&kafkalib.TopicConfig{CDCFormat: validFormat, Topic: "topicA"}, nil)
+		assert.NoError(t, err, validFormat)
+		assert.NotNil(t, parser, validFormat)
 	}
-}
 
-func testOsExit(t *testing.T, testFunc func(*testing.T)) {
-	if os.Getenv(t.Name()) == "1" {
-		testFunc(t)
-		return
+	registry := schemaregistry.NewClient(config.SchemaRegistry{URL: "http://localhost:8081"})
+	for _, avroFormat := range []string{constants.DBZAvroFormat, constants.DBZAvroAltFormat} {
+		parser, err := GetFormatParser(&kafkalib.TopicConfig{CDCFormat: avroFormat, Topic: "topicA"}, registry)
+		assert.NoError(t, err, avroFormat)
+		assert.NotNil(t, parser, avroFormat)
 	}
+}
 
-	cmd := exec.Command(os.Args[0], "-test.run="+t.Name())
-	cmd.Env = append(os.Environ(), t.Name()+"=1")
-	err := cmd.Run()
-	if e, ok := err.(*exec.ExitError); ok && !e.Success() {
-		return
-	}
+func TestGetFormatParserAvroWithoutRegistry(t *testing.T) {
+	parser, err := GetFormatParser(&kafkalib.TopicConfig{CDCFormat: constants.DBZAvroFormat, Topic: "topicC"}, nil)
+	assert.ErrorContains(t, err, `cdc format: "debezium.avro" requires a schema registry, but the schema registry is not configured`)
+	assert.Nil(t, parser)
+}
 
-	t.Fatal("subprocess ran successfully, want non-zero exit status")
+func TestGetFormatParserProtobufInvalidDescriptor(t *testing.T) {
+	parser, err := GetFormatParser(&kafkalib.TopicConfig{
+		CDCFormat: constants.DBZProtobufFormat,
+		Topic:     "topicD",
+		ProtobufSettings: &kafkalib.ProtobufSettings{
+			DescriptorSetPath: "/tmp/does-not-exist.desc",
+			MessageName:       "foo.Envelope",
+		},
+	}, nil)
+	assert.ErrorContains(t, err, "failed to load protobuf parser")
+	assert.Nil(t, parser)
 }
 
-func TestGetFormatParserFatal(t *testing.T) {
-	// This test cannot be iterated because it forks a separate process to do `go test -test.run=...`
-	testOsExit(t, func(t *testing.T) {
-		GetFormatParser("foo", "topicB")
-	})
+func TestGetFormatParserUnsupported(t *testing.T) {
+	parser, err := GetFormatParser(&kafkalib.TopicConfig{CDCFormat: "foo", Topic: "topicB"}, nil)
+	assert.ErrorContains(t, err, `unsupported cdc format: "foo"`)
+	assert.Nil(t, parser)
 }
@@ -49,20 +49,41 @@ func (d *Debezium) GetEventFromBytes(typingSettings typing.Settings, bytes []byt
 			return nil, fmt.Errorf("failed to call mongo JSONEToMap: %w", err)
 		}
 
-		// Now, we need to iterate over each key and if the value is JSON
-		// We need to parse the JSON into a string format
-		for key, value := range after {
-			if typing.ParseValue(typingSettings, key, nil, value) == typing.Struct {
-				valBytes, err := json.Marshal(value)
-				if err != nil {
-					return nil, fmt.Errorf("failed to marshal: %w", err)
-				}
-
-				after[key] = string(valBytes)
+		schemaEventPayload.Payload.afterMap = after
+	} else if schemaEventPayload.Payload.Patch != nil {
+		patch, err := mongo.JSONEToMap([]byte(*schemaEventPayload.Payload.Patch))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse patch: %w", err)
+		}
+
+		var filter map[string]any
+		if schemaEventPayload.Payload.Filter != nil {
+			filter, err = mongo.JSONEToMap([]byte(*schemaEventPayload.Payload.Filter))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse filter: %w", err)
 			}
 		}
 
+		after, partial, err := applyPatch(filter, patch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply patch: %w", err)
+		}
+
 		schemaEventPayload.Payload.afterMap = after
+		schemaEventPayload.Payload.partial = partial
+	}
+
+	// Now, we need to iterate over each key and if the value is JSON
+	// We need to parse the JSON into a string format
+	for key, value := range schemaEventPayload.Payload.afterMap {
+		if typing.ParseValue(typingSettings, key, nil, value) == typing.Struct {
+			valBytes, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal: %w", err)
+			}
+
+			schemaEventPayload.Payload.afterMap[key] = string(valBytes)
+		}
 	}
 
 	return &schemaEventPayload, nil
@@ -119,10 +140,19 @@ func (s *SchemaEventPayload) DeletePayload() bool {
 	return s.Payload.Operation == "d"
 }
 
+func (s *SchemaEventPayload) IsSnapshot() bool {
+	return s.Payload.Source.Snapshot.IsSnapshot()
+}
+
 func (s *SchemaEventPayload) GetExecutionTime() time.Time {
 	return time.UnixMilli(s.Payload.Source.TsMs).UTC()
 }
 
+// IsPartial returns true if the event only contains the fields that were changed by a $set / $unset patch.
+func (s *SchemaEventPayload) IsPartial() bool {
+	return s.Payload.partial
+}
+
 func (s *SchemaEventPayload) GetTableName() string {
 	return s.Payload.Source.Collection
 }
@@ -179,6 +209,16 @@ func (s *SchemaEventPayload) GetData(pkMap map[string]any, tc *kafkalib.TopicCon
 		retMap[constants.DeleteColumnMarker] = false
 	}
 
+	if tc.IncludeDeletedAtColumn() {
+		// This is cleared when the row is inserted or updated again.
+		var deletedAt any
+		if len(s.Payload.afterMap) == 0 {
+			deletedAt = s.GetExecutionTime().Format(ext.ISO8601)
+		}
+
+		retMap[constants.DeletedAtColumnMarker] = deletedAt
+	}
+
 	if tc.IncludeArtieUpdatedAt {
 		retMap[constants.UpdateColumnMarker] = ext.NewUTCTime(ext.ISO8601)
 	}
@@ -187,5 +227,22 @@ func (s *SchemaEventPayload) GetData(pkMap map[string]any, tc *kafkalib.TopicCon
 		retMap[constants.DatabaseUpdatedColumnMarker] = s.GetExecutionTime().Format(ext.ISO8601)
 	}
 
+	if tc.IncludeSnapshotColumn {
+		retMap[constants.SnapshotColumnMarker] = s.IsSnapshot()
+	}
+
+	for _, metadataColumn := range tc.MetadataColumnsToInclude() {
+		// MongoDB does not have a binlog file or position.
+		var value any
+		switch metadataColumn {
+		case kafkalib.MetadataColumnOp:
+			value = s.Payload.Operation
+		case kafkalib.MetadataColumnSourceTsMs:
+			value = s.Payload.Source.TsMs
+		}
+
+		retMap[metadataColumn.ColumnName()] = value
+	}
+
 	return retMap
 }
@@ -2,6 +2,7 @@ package mongo
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/artie-labs/transfer/lib/typing/ext"
@@ -10,6 +11,7 @@ import (
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/debezium"
 	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
 
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson"
@@ -459,3 +461,166 @@ func (p *MongoTestSuite) TestMongoDBEventWithSchema() {
 	cols := schemaEvt.GetColumns()
 	assert.NotNil(p.T(), cols)
 }
+
+func (p *MongoTestSuite) TestMongoDBEventPatch() {
+	const payloadTemplate = `
+{
+	"schema": {},
+	"payload": {
+		"before": null,
+		"after": %s,
+		"patch": %s,
+		"filter": %s,
+		"source": {
+			"connector": "mongodb",
+			"ts_ms": 1668753321000,
+			"db": "inventory",
+			"collection": "customers"
+		},
+		"op": "%s"
+	}
+}`
+
+	pkMap := map[string]any{"_id": "63e3a3bf314a4076d249e203"}
+	{
+		// Insert with extended JSON types
+		after := `"{\"_id\": {\"$oid\": \"63e3a3bf314a4076d249e203\"}, \"first_name\": \"Robin\", \"balance\": {\"$numberDecimal\": \"13.37\"}, \"created_at\": {\"$date\": 1456012800000}}"`
+		evt, err := p.Debezium.GetEventFromBytes(typing.Settings{}, []byte(fmt.Sprintf(payloadTemplate, after, "null", "null", "c")))
+		assert.NoError(p.T(), err)
+		assert.False(p.T(), evt.(cdc.PartialEvent).IsPartial())
+
+		evtData := evt.GetData(pkMap, &kafkalib.TopicConfig{})
+		assert.Equal(p.T(), "63e3a3bf314a4076d249e203", evtData["_id"])
+		assert.Equal(p.T(), "Robin", evtData["first_name"])
+		balance, isOk := evtData["balance"].(*decimal.Decimal)
+		assert.True(p.T(), isOk)
+		assert.Equal(p.T(), "13.37", balance.String())
+		assert.Equal(p.T(), "2016-02-21T00:00:00+00:00", evtData["created_at"])
+		assert.Equal(p.T(), false, evtData[constants.DeleteColumnMarker])
+
+		assert.Equal(p.T(), typing.String, typing.ParseValue(typing.Settings{}, "_id", nil, evtData["_id"]))
+		assert.Equal(p.T(), typing.EDecimal.Kind, typing.ParseValue(typing.Settings{}, "balance", nil, evtData["balance"]).Kind)
+		assert.Equal(p.T(), typing.ETime.Kind, typing.ParseValue(typing.Settings{}, "created_at", nil, evtData["created_at"]).Kind)
+	}
+	{
+		// $set and $unset update
+		patch := `"{\"$v\": 1, \"$set\": {\"first_name\": \"Dusty\", \"address\": {\"city\": \"SF\"}, \"address.zip\": \"94107\"}, \"$unset\": {\"last_name\": true}}"`
+		filter := `"{\"_id\": {\"$oid\": \"63e3a3bf314a4076d249e203\"}}"`
+		evt, err := p.Debezium.GetEventFromBytes(typing.Settings{}, []byte(fmt.Sprintf(payloadTemplate, "null", patch, filter, "u")))
+		assert.NoError(p.T(), err)
+		assert.False(p.T(), evt.DeletePayload())
+		assert.True(p.T(), evt.(cdc.PartialEvent).IsPartial())
+
+		evtData := evt.GetData(pkMap, &kafkalib.TopicConfig{})
+		assert.Equal(p.T(), map[string]any{
+			"_id":                        "63e3a3bf314a4076d249e203",
+			"first_name":                 "Dusty",
+			"address":                    `{"city":"SF"}`,
+			"last_name":                  nil,
+			constants.DeleteColumnMarker: false,
+		}, evtData)
+	}
+	{
+		// Oplog v2 diff update
+		patch := `"{\"$v\": 2, \"diff\": {\"u\": {\"first_name\": \"Dusty\"}, \"i\": {\"age\": 3}, \"d\": {\"last_name\": false}}}"`
+		filter := `"{\"_id\": {\"$oid\": \"63e3a3bf314a4076d249e203\"}}"`
+		evt, err := p.Debezium.GetEventFromBytes(typing.Settings{}, []byte(fmt.Sprintf(payloadTemplate, "null", patch, filter, "u")))
+		assert.NoError(p.T(), err)
+		assert.True(p.T(), evt.(cdc.PartialEvent).IsPartial())
+
+		evtData := evt.GetData(pkMap, &kafkalib.TopicConfig{})
+		assert.Equal(p.T(), "Dusty", evtData["first_name"])
+		assert.Equal(p.T(), float64(3), evtData["age"])
+		assert.Nil(p.T(), evtData["last_name"])
+		_, isOk := evtData["last_name"]
+		assert.True(p.T(), isOk)
+	}
+	{
+		// Decimals within $set and diff updates should be typed the same as they are for inserts.
+		for _, patch := range []string{
+			`"{\"$v\": 1, \"$set\": {\"balance\": {\"$numberDecimal\": \"12345678901234567890.123456789012\"}, \"address\": {\"credit\": {\"$numberDecimal\": \"1.5\"}}}}"`,
+			`"{\"$v\": 2, \"diff\": {\"u\": {\"balance\": {\"$numberDecimal\": \"12345678901234567890.123456789012\"}}, \"i\": {\"address\": {\"credit\": {\"$numberDecimal\": \"1.5\"}}}}}"`,
+		} {
+			filter := `"{\"_id\": {\"$oid\": \"63e3a3bf314a4076d249e203\"}}"`
+			evt, err := p.Debezium.GetEventFromBytes(typing.Settings{}, []byte(fmt.Sprintf(payloadTemplate, "null", patch, filter, "u")))
+			assert.NoError(p.T(), err, patch)
+
+			evtData := evt.GetData(pkMap, &kafkalib.TopicConfig{})
+			balance, isOk := evtData["balance"].(*decimal.Decimal)
+			assert.True(p.T(), isOk, patch)
+			assert.Equal(p.T(), "12345678901234567890.123456789012", balance.String(), patch)
+			assert.Equal(p.T(), typing.EDecimal.Kind, typing.ParseValue(typing.Settings{}, "balance", nil, evtData["balance"]).Kind, patch)
+			assert.Equal(p.T(), `{"credit":1.5}`, evtData["address"], patch)
+		}
+	}
+	{
+		// Full document replacement
+		patch := `"{\"_id\": {\"$oid\": \"63e3a3bf314a4076d249e203\"}, \"first_name\": \"Dusty\"}"`
+		evt, err := p.Debezium.GetEventFromBytes(typing.Settings{}, []byte(fmt.Sprintf(payloadTemplate, "null", patch, "null", "u")))
+		assert.NoError(p.T(), err)
+		assert.False(p.T(), evt.(cdc.PartialEvent).IsPartial())
+
+		evtData := evt.GetData(pkMap, &kafkalib.TopicConfig{})
+		assert.Equal(p.T(), "Dusty", evtData["first_name"])
+		assert.Equal(p.T(), false, evtData[constants.DeleteColumnMarker])
+	}
+	{
+		// Delete
+		filter := `"{\"_id\": {\"$oid\": \"63e3a3bf314a4076d249e203\"}}"`
+		evt, err := p.Debezium.GetEventFromBytes(typing.Settings{}, []byte(fmt.Sprintf(payloadTemplate, "null", "null", filter, "d")))
+		assert.NoError(p.T(), err)
+		assert.True(p.T(), evt.DeletePayload())
+
+		evtData := evt.GetData(pkMap, &kafkalib.TopicConfig{})
+		assert.Equal(p.T(), map[string]any{
+			"_id":                        "63e3a3bf314a4076d249e203",
+			constants.DeleteColumnMarker: true,
+		}, evtData)
+	}
+	{
+		// Malformed patch
+		patch := `"{\"$set\": 5}"`
+		_, err := p.Debezium.GetEventFromBytes(typing.Settings{}, []byte(fmt.Sprintf(payloadTemplate, "null", patch, "null", "u")))
+		assert.ErrorContains(p.T(), err, "expected $set to be an object")
+	}
+}
+
+func (p *MongoTestSuite) TestMongoDBEventSnapshot() {
+	const payloadTemplate = `
+{
+	"schema": {},
+	"payload": {
+		"before": null,
+		"after": "{\"_id\": {\"$oid\": \"63e3a3bf314a4076d249e203\"}, \"first_name\": \"Robin\"}",
+		"source": {
+			"connector": "mongodb",
+			"ts_ms": 1668753321000,
+			"snapshot": "%s",
+			"db": "inventory",
+			"collection": "customers"
+		},
+		"op": "%s"
+	}
+}`
+
+	pkMap := map[string]any{"_id": "63e3a3bf314a4076d249e203"}
+	tc := &kafkalib.TopicConfig{IncludeSnapshotColumn: true}
+	{
+		// Snapshot
+		evt, err := p.Debezium.GetEventFromBytes(typing.Settings{}, []byte(fmt.Sprintf(payloadTemplate, "true", "r")))
+		assert.NoError(p.T(), err)
+		assert.True(p.T(), evt.IsSnapshot())
+		assert.Equal(p.T(), true, evt.GetData(pkMap, tc)[constants.SnapshotColumnMarker])
+	}
+	{
+		// Streaming
+		evt, err := p.Debezium.GetEventFromBytes(typing.Settings{}, []byte(fmt.Sprintf(payloadTemplate, "false", "c")))
+		assert.NoError(p.T(), err)
+		assert.False(p.T(), evt.IsSnapshot())
+
+		// Not included by default
+		_, isOk := evt.GetData(pkMap, &kafkalib.TopicConfig{})[constants.SnapshotColumnMarker]
+		assert.False(p.T(), isOk)
+		assert.Equal(p.T(), false, evt.GetData(pkMap, tc)[constants.SnapshotColumnMarker])
+	}
+}
@@ -1,6 +1,7 @@
 package mongo
 
 import (
+	"github.com/artie-labs/transfer/lib/cdc"
 	"github.com/artie-labs/transfer/lib/debezium"
 )
 
@@ -13,6 +14,9 @@ type SchemaEventPayload struct {
 type Payload struct {
 	Before *string `json:"before"`
 	After  *string `json:"after"`
+	// Patch and Filter are emitted for updates by connectors that do not capture the full document.
+	Patch  *string `json:"patch"`
+	Filter *string `json:"filter"`
 
 	Source    Source `json:"source"`
 	Operation string `json:"op"`
@@ -20,6 +24,8 @@ type Payload struct {
 	// These maps are used to store the before and after JSONE as a map, since `before` and `after` come in as a JSONE string.
 	beforeMap map[string]any
 	afterMap  map[string]any
+	// partial is set when afterMap was built from a $set / $unset patch and only contains the fields that changed.
+	partial bool
 }
 
 type Source struct {
@@ -27,4 +33,6 @@ type Source struct {
 	TsMs       int64  `json:"ts_ms"`
 	Database   string `json:"db"`
 	Collection string `json:"collection"`
+	// Snapshot is set for documents that were read by a snapshot.
+	Snapshot cdc.SnapshotFlag `json:"snapshot"`
 }
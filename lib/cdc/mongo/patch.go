@@ -0,0 +1,134 @@
+package mongo
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/typing/mongo"
+)
+
+const (
+	setOperator   = "$set"
+	unsetOperator = "$unset"
+	// Oplog v2 (MongoDB 5.0+) emits updates as a diff, where `u` are updated fields, `i` are inserted fields and `d` are deleted fields.
+	diffKey         = "diff"
+	diffUpdateKey   = "u"
+	diffInsertKey   = "i"
+	diffDeleteKey   = "d"
+	versionKey      = "$v"
+	fieldPathSymbol = "."
+)
+
+// applyPatch converts a Debezium MongoDB `patch` into a row.
+// Older MongoDB connectors do not emit the full document for updates, they only emit the `filter` (which contains `_id`) and the `patch`.
+// The patch can either be a full document replacement or an update with $set / $unset operators, in which case the row is partial.
+func applyPatch(filter map[string]any, patch map[string]any) (map[string]any, bool, error) {
+	row := make(map[string]any)
+	for key, value := range filter {
+		row[key] = value
+	}
+
+	set, unset, isUpdate, err := parseUpdateOperators(patch)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !isUpdate {
+		// This is a full document replacement.
+		for key, value := range patch {
+			if key == versionKey {
+				continue
+			}
+
+			row[key] = value
+		}
+
+		return row, false, nil
+	}
+
+	for key, value := range set {
+		if strings.Contains(key, fieldPathSymbol) {
+			// We do not have the rest of the nested document, so we cannot partially update it without losing data.
+			slog.Warn("Skipping nested field update from MongoDB patch, nested field paths are not supported", slog.String("field", key))
+			continue
+		}
+
+		// The fields within the update operators are parsed as nested values, but they are columns just like the fields of an insert.
+		parsedValue, err := mongo.ParseNestedDecimal(value)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to parse field %q: %w", key, err)
+		}
+
+		row[key] = parsedValue
+	}
+
+	for key := range unset {
+		if strings.Contains(key, fieldPathSymbol) {
+			slog.Warn("Skipping nested field removal from MongoDB patch, nested field paths are not supported", slog.String("field", key))
+			continue
+		}
+
+		row[key] = nil
+	}
+
+	return row, true, nil
+}
+
+func parseUpdateOperators(patch map[string]any) (map[string]any, map[string]any, bool, error) {
+	set := make(map[string]any)
+	unset := make(map[string]any)
+	var isUpdate bool
+
+	for key, value := range patch {
+		switch key {
+		case setOperator, unsetOperator:
+			fields, isOk := value.(map[string]any)
+			if !isOk {
+				return nil, nil, false, fmt.Errorf("expected %s to be an object, got: %T", key, value)
+			}
+
+			target := set
+			if key == unsetOperator {
+				target = unset
+			}
+
+			for field, fieldValue := range fields {
+				target[field] = fieldValue
+			}
+
+			isUpdate = true
+		case diffKey:
+			diff, isOk := value.(map[string]any)
+			if !isOk {
+				return nil, nil, false, fmt.Errorf("expected %s to be an object, got: %T", key, value)
+			}
+
+			for diffOp, diffValue := range diff {
+				fields, isOk := diffValue.(map[string]any)
+				if !isOk {
+					// Nested diffs look like `sfield: {...}`, these are nested field updates which we do not support.
+					slog.Warn("Skipping unsupported MongoDB diff operation", slog.String("op", diffOp))
+					continue
+				}
+
+				switch diffOp {
+				case diffUpdateKey, diffInsertKey:
+					for field, fieldValue := range fields {
+						set[field] = fieldValue
+					}
+				case diffDeleteKey:
+					for field := range fields {
+						unset[field] = nil
+					}
+				default:
+					slog.Warn("Skipping unsupported MongoDB diff operation", slog.String("op", diffOp))
+				}
+			}
+
+			isUpdate = true
+		}
+	}
+
+	return set, unset, isUpdate, nil
+}
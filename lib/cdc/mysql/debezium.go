@@ -1,7 +1,6 @@
 package mysql
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/artie-labs/transfer/lib/cdc"
@@ -14,18 +13,17 @@ import (
 
 type Debezium string
 
-func (d *Debezium) GetEventFromBytes(_ typing.Settings, bytes []byte) (cdc.Event, error) {
-	var event util.SchemaEventPayload
+func (d *Debezium) GetEventFromBytes(typingSettings typing.Settings, bytes []byte) (cdc.Event, error) {
 	if len(bytes) == 0 {
 		return nil, fmt.Errorf("empty message")
 	}
 
-	err := json.Unmarshal(bytes, &event)
+	event, err := util.ParseSchemaEventPayload(typingSettings, bytes)
 	if err != nil {
 		return nil, err
 	}
 
-	return &event, nil
+	return event, nil
 }
 
 func (d *Debezium) Labels() []string {
@@ -345,6 +345,20 @@ func (m *MySQLTestSuite) TestGetEventFromBytes() {
 	assert.Equal(m.T(), evtData["id"], 1001)
 	assert.Equal(m.T(), evtData["first_name"], "Sally")
 	assert.Equal(m.T(), evtData["bool_test"], false)
+
+	// Metadata columns
+	_, isOk = evtData[constants.OpColumnMarker]
+	assert.False(m.T(), isOk)
+
+	evtData = evt.GetData(kvMap, &kafkalib.TopicConfig{IncludeMetadataColumns: true})
+	assert.Equal(m.T(), "u", evtData[constants.OpColumnMarker])
+	assert.Equal(m.T(), int64(1678735164000), evtData[constants.SourceTsMsColumnMarker])
+	assert.Equal(m.T(), "mysql-bin.000003", evtData[constants.FileColumnMarker])
+	assert.Equal(m.T(), int64(3723), evtData[constants.PosColumnMarker])
+	for _, col := range []string{constants.SourceTsMsColumnMarker, constants.PosColumnMarker} {
+		assert.Equal(m.T(), typing.Integer, typing.ParseValue(typing.Settings{}, col, evt.GetOptionalSchema(), evtData[col]), col)
+	}
+
 	cols := evt.GetColumns()
 	assert.NotNil(m.T(), cols)
 
@@ -195,7 +195,7 @@ func (p *PostgresTestSuite) TestPostgresEventWithSchemaAndTimestampNoTZ() {
 	// Testing typing.
 	assert.Equal(p.T(), evtData["id"], 1001)
 	assert.Equal(p.T(), evtData["another_id"], 333)
-	assert.Equal(p.T(), typing.ParseValue(typing.Settings{}, "another_id", evt.GetOptionalSchema(), evtData["another_id"]), typing.Integer)
+	assert.Equal(p.T(), typing.ParseValue(typing.Settings{}, "another_id", evt.GetOptionalSchema(), evtData["another_id"]), typing.NewIntegerKindDetails(typing.RegularIntegerKind))
 
 	assert.Equal(p.T(), evtData["email"], "sally.thomas@acme.com")
 
@@ -207,9 +207,129 @@ func (p *PostgresTestSuite) TestPostgresEventWithSchemaAndTimestampNoTZ() {
 			Type:   ext.DateTimeKindType,
 			Format: time.RFC3339Nano,
 		},
+		Precision: ext.MicrosecondPrecision,
 	})
 
 	assert.Equal(p.T(), time.Date(2023, time.February, 2,
 		17, 54, 11, 451000000, time.UTC), evt.GetExecutionTime())
 	assert.Equal(p.T(), "customers", evt.GetTableName())
 }
+
+func (p *PostgresTestSuite) TestPostgresDeleteEventCompositeKey() {
+	key := `{
+	"schema": {
+		"type": "struct",
+		"fields": [
+			{"type": "int32", "optional": false, "field": "quarter_id"},
+			{"type": "string", "optional": false, "field": "course_id"}
+		],
+		"optional": false,
+		"name": "dbserver1.public.course_grades.Key"
+	},
+	"payload": {"quarter_id": 1, "course_id": "course1"}
+}`
+
+	pkMap, err := p.GetPrimaryKey([]byte(key), validTc)
+	assert.NoError(p.T(), err)
+
+	for _, includeAfterSchema := range []bool{true, false} {
+		afterSchema := `{
+			"type": "struct",
+			"fields": [
+				{"type": "int32", "optional": false, "field": "quarter_id"},
+				{"type": "string", "optional": false, "field": "course_id"},
+				{"type": "int32", "optional": true, "field": "grade"}
+			],
+			"optional": true,
+			"name": "dbserver1.public.course_grades.Value",
+			"field": "after"
+		},`
+		if !includeAfterSchema {
+			// Some connectors will omit the schema for `after` since it's null.
+			afterSchema = ""
+		}
+
+		payload := `{
+	"schema": {
+		"type": "struct",
+		"fields": [{
+			"type": "struct",
+			"fields": [
+				{"type": "int32", "optional": false, "field": "quarter_id"},
+				{"type": "string", "optional": false, "field": "course_id"},
+				{"type": "int32", "optional": true, "field": "grade"}
+			],
+			"optional": true,
+			"name": "dbserver1.public.course_grades.Value",
+			"field": "before"
+		}, ` + afterSchema + ` {
+			"type": "string",
+			"optional": false,
+			"field": "op"
+		}],
+		"optional": false,
+		"name": "dbserver1.public.course_grades.Envelope"
+	},
+	"payload": {
+		"before": {"quarter_id": 1, "course_id": "course1", "grade": null},
+		"after": null,
+		"source": {"ts_ms": 1675360451451, "table": "course_grades"},
+		"op": "d"
+	}
+}`
+
+		evt, err := p.GetEventFromBytes(typing.Settings{}, []byte(payload))
+		assert.NoError(p.T(), err, includeAfterSchema)
+		assert.True(p.T(), evt.DeletePayload(), includeAfterSchema)
+
+		// The delete row should carry both key columns, typed by the schema.
+		evtData := evt.GetData(pkMap, &kafkalib.TopicConfig{})
+		assert.Equal(p.T(), map[string]any{
+			"quarter_id":                 1,
+			"course_id":                  "course1",
+			constants.DeleteColumnMarker: true,
+		}, evtData, includeAfterSchema)
+
+		assert.Equal(p.T(), map[string]typing.KindDetails{
+			"quarter_id": typing.NewIntegerKindDetails(typing.RegularIntegerKind),
+			"course_id":  typing.String,
+			"grade":      typing.NewIntegerKindDetails(typing.RegularIntegerKind),
+		}, evt.GetOptionalSchema(), includeAfterSchema)
+
+		cols := evt.GetColumns()
+		assert.NotNil(p.T(), cols, includeAfterSchema)
+		assert.Len(p.T(), cols.GetColumns(), 3, includeAfterSchema)
+	}
+}
+
+func (p *PostgresTestSuite) TestGetEventFromBytes_PreserveJSONNumbers() {
+	payload := []byte(`{
+	"schema": {"type": "struct", "fields": [{"type": "struct", "fields": [{"type": "int64", "optional": false, "field": "id"}, {"type": "int64", "optional": true, "field": "parent_id"}], "optional": true, "field": "after"}]},
+	"payload": {
+		"before": null,
+		"after": {"id": 1234567890123456789, "parent_id": 1234567890123456788, "amount": 7.5, "external_id": 9223372036854775807},
+		"source": {"connector": "postgresql", "ts_ms": 1668571313308, "db": "demo", "schema": "public", "table": "orders"},
+		"op": "c"
+	}
+}`)
+
+	{
+		// By default, integers beyond 2^53 will be rounded.
+		evt, err := p.GetEventFromBytes(typing.Settings{}, payload)
+		assert.NoError(p.T(), err)
+		evtData := evt.GetData(map[string]any{"id": 1234567890123456789}, &kafkalib.TopicConfig{})
+		assert.NotEqual(p.T(), 1234567890123456789, evtData["id"])
+	}
+	{
+		evt, err := p.GetEventFromBytes(typing.Settings{PreserveJSONNumbers: true}, payload)
+		assert.NoError(p.T(), err)
+		evtData := evt.GetData(map[string]any{"id": 1234567890123456789}, &kafkalib.TopicConfig{})
+		// Columns that are in the schema
+		assert.Equal(p.T(), 1234567890123456789, evtData["id"])
+		assert.Equal(p.T(), 1234567890123456788, evtData["parent_id"])
+		// Columns that are not in the schema
+		assert.Equal(p.T(), int64(9223372036854775807), evtData["external_id"])
+		assert.Equal(p.T(), typing.Integer, typing.ParseValue(typing.Settings{}, "external_id", evt.GetOptionalSchema(), evtData["external_id"]))
+		assert.Equal(p.T(), 7.5, evtData["amount"])
+	}
+}
@@ -0,0 +1,97 @@
+package protobuf
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/cdc/util"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/debezium"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
+	"github.com/artie-labs/transfer/lib/typing"
+)
+
+// Debezium parses Debezium events that have been serialized as Protobuf, the envelope is described by a descriptor set.
+type Debezium struct {
+	descriptor protoreflect.MessageDescriptor
+	schema     debezium.Schema
+}
+
+func NewDebezium(settings kafkalib.ProtobufSettings) (*Debezium, error) {
+	descriptor, err := loadMessageDescriptor(settings.DescriptorSetPath, settings.MessageName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Debezium{
+		descriptor: descriptor,
+		schema:     toDebeziumSchema(descriptor),
+	}, nil
+}
+
+func (d *Debezium) Labels() []string {
+	return []string{constants.DBZProtobufFormat, constants.DBZProtobufAltFormat}
+}
+
+// stripWireFormat removes the Confluent schema registry header if the message has one.
+// The header looks like: [magic byte (1)] [schema ID (4)] [message indexes (varint array)]
+// A plain Protobuf message can never start with a zero byte since field numbers start at 1.
+func stripWireFormat(bytes []byte) ([]byte, error) {
+	if !schemaregistry.HasWireFormat(bytes) {
+		return bytes, nil
+	}
+
+	_, payload, err := schemaregistry.ParseWireFormat(bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	count, n := binary.Varint(payload)
+	if n <= 0 {
+		return nil, fmt.Errorf("failed to read message indexes length")
+	}
+
+	payload = payload[n:]
+	for i := int64(0); i < count; i++ {
+		if _, n = binary.Varint(payload); n <= 0 {
+			return nil, fmt.Errorf("failed to read message index")
+		}
+
+		payload = payload[n:]
+	}
+
+	return payload, nil
+}
+
+func (d *Debezium) GetEventFromBytes(typingSettings typing.Settings, bytes []byte) (cdc.Event, error) {
+	if len(bytes) == 0 {
+		return nil, fmt.Errorf("empty message")
+	}
+
+	payload, err := stripWireFormat(bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse wire format: %w", err)
+	}
+
+	msg := dynamicpb.NewMessage(d.descriptor)
+	if err = proto.Unmarshal(payload, msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal protobuf message: %w", err)
+	}
+
+	event, err := util.NewSchemaEventPayload(typingSettings, d.schema, toNative(msg))
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func (d *Debezium) GetPrimaryKey(key []byte, tc *kafkalib.TopicConfig) (map[string]any, error) {
+	return debezium.ParsePartitionKey(key, tc.CDCKeyFormat)
+}
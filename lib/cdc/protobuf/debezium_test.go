@@ -0,0 +1,261 @@
+package protobuf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+const envelopeName = "dbserver1.public.customers.Envelope"
+
+func field(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type, typeName string, label descriptorpb.FieldDescriptorProto_Label, proto3Optional bool) *descriptorpb.FieldDescriptorProto {
+	fd := &descriptorpb.FieldDescriptorProto{
+		Name:     ptr.ToString(name),
+		JsonName: ptr.ToString(name),
+		Number:   &number,
+		Type:     kind.Enum(),
+		Label:    label.Enum(),
+	}
+
+	if typeName != "" {
+		fd.TypeName = ptr.ToString(typeName)
+	}
+
+	if proto3Optional {
+		fd.Proto3Optional = &proto3Optional
+	}
+
+	return fd
+}
+
+// writeDescriptorSet writes a descriptor set that is equivalent to:
+//
+//	syntax = "proto3";
+//	package dbserver1.public.customers;
+//	import "google/protobuf/timestamp.proto";
+//	enum Status { UNKNOWN = 0; ACTIVE = 1; }
+//	message Value { int32 id = 1; optional string first_name = 2; google.protobuf.Timestamp created_at = 3; repeated string tags = 4; Status status = 5; }
+//	message Source { string connector = 1; int64 ts_ms = 2; string db = 3; string schema = 4; string table = 5; }
+//	message Envelope { Value before = 1; Value after = 2; Source source = 3; string op = 4; int64 ts_ms = 5; }
+func writeDescriptorSet(t *testing.T) string {
+	const (
+		optional = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		repeated = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	)
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       ptr.ToString("customers.proto"),
+		Package:    ptr.ToString("dbserver1.public.customers"),
+		Syntax:     ptr.ToString("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto"},
+		EnumType: []*descriptorpb.EnumDescriptorProto{
+			{
+				Name: ptr.ToString("Status"),
+				Value: []*descriptorpb.EnumValueDescriptorProto{
+					{Name: ptr.ToString("UNKNOWN"), Number: proto.Int32(0)},
+					{Name: ptr.ToString("ACTIVE"), Number: proto.Int32(1)},
+				},
+			},
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: ptr.ToString("Value"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT32, "", optional, false),
+					field("first_name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", optional, true),
+					field("created_at", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp", optional, false),
+					field("tags", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", repeated, false),
+					field("status", 5, descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".dbserver1.public.customers.Status", optional, false),
+				},
+				OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: ptr.ToString("_first_name")}},
+			},
+			{
+				Name: ptr.ToString("Source"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("connector", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", optional, false),
+					field("ts_ms", 2, descriptorpb.FieldDescriptorProto_TYPE_INT64, "", optional, false),
+					field("db", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", optional, false),
+					field("schema", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", optional, false),
+					field("table", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", optional, false),
+				},
+			},
+			{
+				Name: ptr.ToString("Envelope"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("before", 1, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".dbserver1.public.customers.Value", optional, false),
+					field("after", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".dbserver1.public.customers.Value", optional, false),
+					field("source", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".dbserver1.public.customers.Source", optional, false),
+					field("op", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", optional, false),
+					field("ts_ms", 5, descriptorpb.FieldDescriptorProto_TYPE_INT64, "", optional, false),
+				},
+			},
+		},
+	}
+
+	// Mark first_name as part of the synthetic oneof that proto3 `optional` generates.
+	file.MessageType[0].Field[1].OneofIndex = proto.Int32(0)
+
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(timestamppb.File_google_protobuf_timestamp_proto),
+			file,
+		},
+	}
+
+	bytes, err := proto.Marshal(set)
+	assert.NoError(t, err)
+
+	fp := filepath.Join(t.TempDir(), "customers.desc")
+	assert.NoError(t, os.WriteFile(fp, bytes, 0644))
+	return fp
+}
+
+func newMessage(md protoreflect.MessageDescriptor, values map[string]protoreflect.Value) *dynamicpb.Message {
+	msg := dynamicpb.NewMessage(md)
+	for name, value := range values {
+		msg.Set(md.Fields().ByName(protoreflect.Name(name)), value)
+	}
+
+	return msg
+}
+
+func TestNewDebezium(t *testing.T) {
+	fp := writeDescriptorSet(t)
+	{
+		// File does not exist
+		_, err := NewDebezium(kafkalib.ProtobufSettings{DescriptorSetPath: filepath.Join(t.TempDir(), "foo.desc"), MessageName: envelopeName})
+		assert.ErrorContains(t, err, "failed to read descriptor set")
+	}
+	{
+		// Message does not exist
+		_, err := NewDebezium(kafkalib.ProtobufSettings{DescriptorSetPath: fp, MessageName: "foo.Envelope"})
+		assert.ErrorContains(t, err, "failed to find message: foo.Envelope")
+	}
+	{
+		// Valid
+		d, err := NewDebezium(kafkalib.ProtobufSettings{DescriptorSetPath: fp, MessageName: envelopeName})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{constants.DBZProtobufFormat, constants.DBZProtobufAltFormat}, d.Labels())
+	}
+}
+
+func TestDebezium_GetEventFromBytes(t *testing.T) {
+	d, err := NewDebezium(kafkalib.ProtobufSettings{DescriptorSetPath: writeDescriptorSet(t), MessageName: envelopeName})
+	assert.NoError(t, err)
+
+	envelope := d.descriptor
+	valueMd := envelope.Fields().ByName("after").Message()
+	sourceMd := envelope.Fields().ByName("source").Message()
+	tsMd := valueMd.Fields().ByName("created_at").Message()
+
+	tags := dynamicpb.NewMessage(valueMd).NewField(valueMd.Fields().ByName("tags")).List()
+	tags.Append(protoreflect.ValueOfString("foo"))
+	tags.Append(protoreflect.ValueOfString("bar"))
+
+	after := newMessage(valueMd, map[string]protoreflect.Value{
+		"id":         protoreflect.ValueOfInt32(1001),
+		"first_name": protoreflect.ValueOfString("Sally"),
+		"created_at": protoreflect.ValueOfMessage(newMessage(tsMd, map[string]protoreflect.Value{
+			"seconds": protoreflect.ValueOfInt64(1700000000),
+			"nanos":   protoreflect.ValueOfInt32(123_000_000),
+		})),
+		"tags":   protoreflect.ValueOfList(tags),
+		"status": protoreflect.ValueOfEnum(1),
+	})
+
+	source := newMessage(sourceMd, map[string]protoreflect.Value{
+		"connector": protoreflect.ValueOfString("postgresql"),
+		"ts_ms":     protoreflect.ValueOfInt64(1700000000000),
+		"db":        protoreflect.ValueOfString("postgres"),
+		"schema":    protoreflect.ValueOfString("public"),
+		"table":     protoreflect.ValueOfString("customers"),
+	})
+
+	bytes, err := proto.Marshal(newMessage(envelope, map[string]protoreflect.Value{
+		"after":  protoreflect.ValueOfMessage(after),
+		"source": protoreflect.ValueOfMessage(source),
+		"op":     protoreflect.ValueOfString("c"),
+	}))
+	assert.NoError(t, err)
+
+	// Plain Protobuf and the Confluent wire format (magic byte, schema ID = 1, message indexes = [0]) should be parsed the same way.
+	for _, msgBytes := range [][]byte{bytes, append([]byte{0, 0, 0, 0, 1, 0}, bytes...)} {
+		evt, err := d.GetEventFromBytes(typing.Settings{}, msgBytes)
+		assert.NoError(t, err)
+		assert.Equal(t, "customers", evt.GetTableName())
+		assert.Equal(t, "c", evt.Operation())
+		assert.False(t, evt.DeletePayload())
+		assert.Equal(t, time.UnixMilli(1700000000000).UTC(), evt.GetExecutionTime())
+
+		optionalSchema := evt.GetOptionalSchema()
+		assert.Equal(t, typing.NewIntegerKindDetails(typing.RegularIntegerKind), optionalSchema["id"])
+		assert.Equal(t, typing.String, optionalSchema["first_name"])
+		assert.Equal(t, ext.DateTimeKindType, optionalSchema["created_at"].ExtendedTimeDetails.Type)
+		assert.Equal(t, typing.Array, optionalSchema["tags"])
+		assert.Equal(t, typing.String, optionalSchema["status"])
+
+		data := evt.GetData(map[string]any{"id": 1001}, &kafkalib.TopicConfig{})
+		assert.Equal(t, 1001, data["id"])
+		assert.Equal(t, "Sally", data["first_name"])
+		assert.Equal(t, "2023-11-14T22:13:20.123Z", data["created_at"].(*ext.ExtendedTime).String(""))
+		assert.Equal(t, []any{"foo", "bar"}, data["tags"])
+		assert.Equal(t, "ACTIVE", data["status"])
+	}
+
+	{
+		// Delete event
+		bytes, err = proto.Marshal(newMessage(envelope, map[string]protoreflect.Value{
+			"before": protoreflect.ValueOfMessage(newMessage(valueMd, map[string]protoreflect.Value{"id": protoreflect.ValueOfInt32(1001)})),
+			"source": protoreflect.ValueOfMessage(source),
+			"op":     protoreflect.ValueOfString("d"),
+		}))
+		assert.NoError(t, err)
+
+		evt, err := d.GetEventFromBytes(typing.Settings{}, bytes)
+		assert.NoError(t, err)
+		assert.True(t, evt.DeletePayload())
+
+		data := evt.GetData(map[string]any{"id": 1001}, &kafkalib.TopicConfig{})
+		assert.Equal(t, true, data[constants.DeleteColumnMarker])
+	}
+	{
+		// Empty or malformed messages
+		_, err = d.GetEventFromBytes(typing.Settings{}, nil)
+		assert.ErrorContains(t, err, "empty message")
+
+		_, err = d.GetEventFromBytes(typing.Settings{}, []byte{0xff, 0xff})
+		assert.ErrorContains(t, err, "failed to unmarshal protobuf message")
+	}
+}
+
+func TestToDebeziumSchema(t *testing.T) {
+	d, err := NewDebezium(kafkalib.ProtobufSettings{DescriptorSetPath: writeDescriptorSet(t), MessageName: envelopeName})
+	assert.NoError(t, err)
+
+	after := d.schema.GetSchemaFromLabel(cdc.After)
+	assert.NotNil(t, after)
+	assert.True(t, after.Optional)
+	assert.Len(t, after.Fields, 5)
+	assert.True(t, after.Fields[1].Optional)
+	assert.False(t, after.Fields[0].Optional)
+
+	op := d.schema.GetSchemaFromLabel(cdc.Op)
+	assert.NotNil(t, op)
+	assert.Equal(t, "string", op.FieldObjectType)
+}
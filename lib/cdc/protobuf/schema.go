@@ -0,0 +1,188 @@
+package protobuf
+
+import (
+	"fmt"
+	"os"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/debezium"
+)
+
+const timestampFullName = "google.protobuf.Timestamp"
+
+// loadMessageDescriptor reads a serialized `FileDescriptorSet` and returns the descriptor for `messageName`.
+func loadMessageDescriptor(descriptorSetPath string, messageName string) (protoreflect.MessageDescriptor, error) {
+	bytes, err := os.ReadFile(descriptorSetPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+
+	var fileDescriptorSet descriptorpb.FileDescriptorSet
+	if err = proto.Unmarshal(bytes, &fileDescriptorSet); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal descriptor set: %w", err)
+	}
+
+	files, err := protodesc.NewFiles(&fileDescriptorSet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build file registry: %w", err)
+	}
+
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(messageName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find message: %s: %w", messageName, err)
+	}
+
+	messageDescriptor, isOk := descriptor.(protoreflect.MessageDescriptor)
+	if !isOk {
+		return nil, fmt.Errorf("descriptor: %s is not a message", messageName)
+	}
+
+	return messageDescriptor, nil
+}
+
+func toFieldType(fd protoreflect.FieldDescriptor) debezium.FieldType {
+	if fd.IsMap() {
+		return debezium.Map
+	}
+
+	if fd.IsList() {
+		return debezium.Array
+	}
+
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return debezium.Boolean
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return debezium.Int32
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return debezium.Int64
+	case protoreflect.FloatKind:
+		return debezium.Float
+	case protoreflect.DoubleKind:
+		return debezium.Double
+	case protoreflect.StringKind, protoreflect.EnumKind:
+		return debezium.String
+	case protoreflect.BytesKind:
+		return debezium.Bytes
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if fd.Message().FullName() == timestampFullName {
+			// Timestamps are converted into epoch milliseconds.
+			return debezium.Int64
+		}
+
+		return debezium.Struct
+	}
+
+	return ""
+}
+
+func toField(fd protoreflect.FieldDescriptor) debezium.Field {
+	field := debezium.Field{
+		FieldName: string(fd.Name()),
+		Type:      toFieldType(fd),
+		Optional:  fd.HasPresence(),
+	}
+
+	if !fd.IsList() && fd.Kind() == protoreflect.MessageKind && fd.Message().FullName() == timestampFullName {
+		field.DebeziumType = debezium.Timestamp
+	}
+
+	return field
+}
+
+// toDebeziumSchema converts the envelope message descriptor into the same schema we would get from the JSON converter.
+func toDebeziumSchema(md protoreflect.MessageDescriptor) debezium.Schema {
+	schema := debezium.Schema{SchemaType: string(debezium.Struct)}
+	envelopeFields := md.Fields()
+	for i := 0; i < envelopeFields.Len(); i++ {
+		fd := envelopeFields.Get(i)
+		fieldsObject := debezium.FieldsObject{
+			FieldObjectType: string(toFieldType(fd)),
+			Optional:        fd.HasPresence(),
+			FieldLabel:      cdc.FieldLabelKind(fd.Name()),
+		}
+
+		if fd.Kind() == protoreflect.MessageKind && !fd.IsList() && !fd.IsMap() {
+			fields := fd.Message().Fields()
+			for j := 0; j < fields.Len(); j++ {
+				fieldsObject.Fields = append(fieldsObject.Fields, toField(fields.Get(j)))
+			}
+		}
+
+		schema.FieldsObject = append(schema.FieldsObject, fieldsObject)
+	}
+
+	return schema
+}
+
+// toNative converts a Protobuf message into a map that has the same shape as a JSON payload.
+// Fields with explicit presence that are not set are returned as nil.
+func toNative(msg protoreflect.Message) map[string]any {
+	out := make(map[string]any)
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.HasPresence() && !msg.Has(fd) {
+			out[string(fd.Name())] = nil
+			continue
+		}
+
+		out[string(fd.Name())] = toNativeField(fd, msg.Get(fd))
+	}
+
+	return out
+}
+
+func toNativeField(fd protoreflect.FieldDescriptor, value protoreflect.Value) any {
+	if fd.IsList() {
+		list := value.List()
+		out := make([]any, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			out[i] = toNativeValue(fd, list.Get(i))
+		}
+
+		return out
+	}
+
+	if fd.IsMap() {
+		out := make(map[string]any)
+		value.Map().Range(func(key protoreflect.MapKey, val protoreflect.Value) bool {
+			out[key.String()] = toNativeValue(fd.MapValue(), val)
+			return true
+		})
+
+		return out
+	}
+
+	return toNativeValue(fd, value)
+}
+
+func toNativeValue(fd protoreflect.FieldDescriptor, value protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if enumValue := fd.Enum().Values().ByNumber(value.Enum()); enumValue != nil {
+			return string(enumValue.Name())
+		}
+
+		return int32(value.Enum())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		msg := value.Message()
+		if msg.Descriptor().FullName() == timestampFullName {
+			fields := msg.Descriptor().Fields()
+			seconds := msg.Get(fields.ByName("seconds")).Int()
+			nanos := msg.Get(fields.ByName("nanos")).Int()
+			return seconds*1000 + nanos/1_000_000
+		}
+
+		return toNative(msg)
+	}
+
+	return value.Interface()
+}
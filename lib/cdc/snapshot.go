@@ -0,0 +1,40 @@
+package cdc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SnapshotFlag is Debezium's `source.snapshot` field, this is set for rows that were read by the initial (or incremental) snapshot.
+// Debezium will emit this as a string (true, first, last, incremental, false) but older connectors emit it as a boolean.
+type SnapshotFlag string
+
+func (s *SnapshotFlag) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch castedValue := value.(type) {
+	case nil:
+		*s = ""
+	case bool:
+		*s = SnapshotFlag(fmt.Sprint(castedValue))
+	case string:
+		*s = SnapshotFlag(castedValue)
+	default:
+		return fmt.Errorf("unexpected type %T for snapshot flag", value)
+	}
+
+	return nil
+}
+
+// IsSnapshot returns true if the row was emitted by a snapshot rather than from streaming changes.
+func (s SnapshotFlag) IsSnapshot() bool {
+	switch s {
+	case "", "false":
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,82 @@
+package cdc
+
+import (
+	"time"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+// TombstoneEvent is a delete for a key on a log-compacted topic.
+// Tombstones do not have a value, so the primary keys and the time the message was published is all we have to go off of.
+type TombstoneEvent struct {
+	executionTime time.Time
+}
+
+func NewTombstoneEvent(executionTime time.Time) TombstoneEvent {
+	return TombstoneEvent{executionTime: executionTime}
+}
+
+func (t TombstoneEvent) GetExecutionTime() time.Time {
+	return t.executionTime
+}
+
+func (t TombstoneEvent) Operation() string {
+	return DeleteOperation
+}
+
+func (t TombstoneEvent) DeletePayload() bool {
+	return true
+}
+
+func (t TombstoneEvent) IsSnapshot() bool {
+	return false
+}
+
+// GetTableName returns an empty string since tombstones do not carry the source table, the topic config's table name will be used instead.
+func (t TombstoneEvent) GetTableName() string {
+	return ""
+}
+
+func (t TombstoneEvent) GetData(pkMap map[string]any, tc *kafkalib.TopicConfig) map[string]any {
+	retMap := map[string]any{
+		constants.DeleteColumnMarker: true,
+	}
+
+	for k, v := range pkMap {
+		retMap[k] = v
+	}
+
+	if tc.IdempotentKey != "" {
+		retMap[tc.IdempotentKey] = t.executionTime.Format(ext.ISO8601)
+	}
+
+	if tc.IncludeDeletedAtColumn() {
+		retMap[constants.DeletedAtColumnMarker] = t.executionTime.Format(ext.ISO8601)
+	}
+
+	if tc.IncludeArtieUpdatedAt {
+		retMap[constants.UpdateColumnMarker] = ext.NewUTCTime(ext.ISO8601)
+	}
+
+	if tc.IncludeDatabaseUpdatedAt {
+		retMap[constants.DatabaseUpdatedColumnMarker] = t.executionTime.Format(ext.ISO8601)
+	}
+
+	if tc.IncludeSnapshotColumn {
+		retMap[constants.SnapshotColumnMarker] = false
+	}
+
+	return retMap
+}
+
+func (t TombstoneEvent) GetOptionalSchema() map[string]typing.KindDetails {
+	return nil
+}
+
+func (t TombstoneEvent) GetColumns() *columns.Columns {
+	return nil
+}
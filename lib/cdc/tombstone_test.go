@@ -0,0 +1,35 @@
+package cdc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+)
+
+func TestTombstoneEvent(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	evt := NewTombstoneEvent(ts)
+	assert.Equal(t, ts, evt.GetExecutionTime())
+	assert.Equal(t, DeleteOperation, evt.Operation())
+	assert.True(t, evt.DeletePayload())
+	assert.False(t, evt.IsSnapshot())
+	assert.Empty(t, evt.GetTableName())
+	assert.Nil(t, evt.GetOptionalSchema())
+	assert.Nil(t, evt.GetColumns())
+
+	{
+		// Only the primary keys and the delete marker
+		assert.Equal(t, map[string]any{"id": "1", constants.DeleteColumnMarker: true}, evt.GetData(map[string]any{"id": "1"}, &kafkalib.TopicConfig{}))
+	}
+	{
+		// Soft deletes with a deleted at column
+		data := evt.GetData(map[string]any{"id": "1"}, &kafkalib.TopicConfig{SoftDelete: true, SoftDeleteMarker: kafkalib.SoftDeleteMarkerBoth, IncludeDatabaseUpdatedAt: true})
+		assert.Equal(t, true, data[constants.DeleteColumnMarker])
+		assert.Equal(t, "2024-01-01T00:00:00+00:00", data[constants.DeletedAtColumnMarker])
+		assert.Equal(t, "2024-01-01T00:00:00+00:00", data[constants.DatabaseUpdatedColumnMarker])
+	}
+}
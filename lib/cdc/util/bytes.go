@@ -0,0 +1,42 @@
+package util
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/debezium"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+)
+
+// isRawBytes returns true if the field is a `bytes` field without a logical type (e.g. decimals are also sent as bytes).
+func isRawBytes(field debezium.Field) bool {
+	return field.Type == debezium.Bytes && field.DebeziumType == ""
+}
+
+// encodeBytes will decode Debezium's base64 transport form and re-encode it with `encoding`.
+func encodeBytes(value any, encoding kafkalib.BytesEncoding) (any, error) {
+	if encoding == "" || encoding == kafkalib.BytesEncodingBase64 {
+		return value, nil
+	}
+
+	castedValue, isOk := value.(string)
+	if !isOk || castedValue == constants.ToastUnavailableValuePlaceholder {
+		return value, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(castedValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to base64 decode: %w", err)
+	}
+
+	switch encoding {
+	case kafkalib.BytesEncodingHex:
+		return hex.EncodeToString(data), nil
+	case kafkalib.BytesEncodingNative:
+		return data, nil
+	}
+
+	return nil, fmt.Errorf("invalid bytes encoding: %q", encoding)
+}
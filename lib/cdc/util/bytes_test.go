@@ -0,0 +1,35 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+)
+
+func TestEncodeBytes(t *testing.T) {
+	{
+		// Not a string
+		val, err := encodeBytes(123, kafkalib.BytesEncodingHex)
+		assert.NoError(t, err)
+		assert.Equal(t, 123, val)
+	}
+	{
+		// TOAST placeholder is left alone
+		val, err := encodeBytes(constants.ToastUnavailableValuePlaceholder, kafkalib.BytesEncodingNative)
+		assert.NoError(t, err)
+		assert.Equal(t, constants.ToastUnavailableValuePlaceholder, val)
+	}
+	{
+		// Invalid base64
+		_, err := encodeBytes("not base64!", kafkalib.BytesEncodingHex)
+		assert.ErrorContains(t, err, "failed to base64 decode")
+	}
+	{
+		// Invalid encoding
+		_, err := encodeBytes("aGVsbG8=", kafkalib.BytesEncoding("foo"))
+		assert.ErrorContains(t, err, `invalid bytes encoding: "foo"`)
+	}
+}
@@ -3,20 +3,29 @@ package util
 import (
 	"log/slog"
 
-	"github.com/artie-labs/transfer/lib/cdc"
 	"github.com/artie-labs/transfer/lib/typing"
 )
 
 func (s *SchemaEventPayload) GetOptionalSchema() map[string]typing.KindDetails {
-	fieldsObject := s.Schema.GetSchemaFromLabel(cdc.After)
+	fieldsObject := s.fieldsObject()
 	if fieldsObject == nil {
-		// AFTER schema does not exist.
+		// Neither the AFTER nor BEFORE schema exists.
 		return nil
 	}
 
 	schema := make(map[string]typing.KindDetails)
 	for _, field := range fieldsObject.Fields {
 		kd := field.ToKindDetails()
+		if isRawBytes(field) {
+			// This is the declared kind, the column is only created as a binary column if the topic uses the native bytes encoding, see [event.ToMemoryEvent].
+			kd = typing.Bytes
+		}
+
+		if kd == typing.Invalid {
+			// If an unknown type policy has been set, the field will be created with that kind instead.
+			kd = s.unknownTypePolicy.KindDetails()
+		}
+
 		if kd == typing.Invalid {
 			slog.Warn("Skipping field from optional schema b/c we cannot determine the data type", slog.String("field", field.FieldName))
 			continue
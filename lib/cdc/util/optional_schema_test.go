@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/ptr"
 	"github.com/artie-labs/transfer/lib/typing"
 	"github.com/artie-labs/transfer/lib/typing/decimal"
@@ -123,3 +124,72 @@ func TestGetOptionalSchema(t *testing.T) {
 		}
 	}
 }
+
+func TestGetOptionalSchema_UnknownTypePolicy(t *testing.T) {
+	// `interval` is not a Kafka Connect type, so we will not be able to map it.
+	body := `{
+	"schema": {
+		"type": "struct",
+		"fields": [
+			{
+				"type": "struct",
+				"fields": [
+					{"type": "int32", "optional": false, "field": "id"},
+					{"type": "interval", "optional": true, "name": "io.example.Interval", "field": "duration"}
+				],
+				"optional": true,
+				"field": "after"
+			}
+		]
+	},
+	"payload": {
+		"before": null,
+		"after": {"id": 1, "duration": {"months": 1, "days": 2}},
+		"source": {"connector": "postgresql", "ts_ms": 1698297600000, "db": "customers", "schema": "public", "table": "orders"},
+		"op": "c"
+	}
+}`
+
+	tc := &kafkalib.TopicConfig{}
+	{
+		// Not set, the field is skipped from the schema and the value is passed through.
+		evt, err := ParseSchemaEventPayload(typing.Settings{}, []byte(body))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]typing.KindDetails{"id": typing.NewIntegerKindDetails(typing.RegularIntegerKind)}, evt.GetOptionalSchema())
+		assert.Equal(t, map[string]any{"months": float64(1), "days": float64(2)}, evt.GetData(map[string]any{"id": 1}, tc)["duration"])
+	}
+	{
+		// String
+		evt, err := ParseSchemaEventPayload(typing.Settings{UnknownTypePolicy: typing.UnknownTypePolicyString}, []byte(body))
+		assert.NoError(t, err)
+		assert.Equal(t, typing.String, evt.GetOptionalSchema()["duration"])
+		assert.Equal(t, `{"days":2,"months":1}`, evt.GetData(map[string]any{"id": 1}, tc)["duration"])
+	}
+	{
+		// JSON
+		evt, err := ParseSchemaEventPayload(typing.Settings{UnknownTypePolicy: typing.UnknownTypePolicyJSON}, []byte(body))
+		assert.NoError(t, err)
+		assert.Equal(t, typing.Struct, evt.GetOptionalSchema()["duration"])
+		assert.Equal(t, map[string]any{"months": float64(1), "days": float64(2)}, evt.GetData(map[string]any{"id": 1}, tc)["duration"])
+	}
+	{
+		// Error
+		_, err := ParseSchemaEventPayload(typing.Settings{UnknownTypePolicy: typing.UnknownTypePolicyError}, []byte(body))
+		assert.ErrorContains(t, err, `field "duration" has an unknown type, type: "interval", name: "io.example.Interval"`)
+	}
+}
+
+func TestToRawString(t *testing.T) {
+	for _, value := range []any{nil, "hello"} {
+		actual, err := toRawString(value)
+		assert.NoError(t, err)
+		assert.Equal(t, value, actual)
+	}
+
+	actual, err := toRawString([]any{1, "two"})
+	assert.NoError(t, err)
+	assert.Equal(t, `[1,"two"]`, actual)
+
+	_, err = toRawString(map[string]any{"ch": make(chan int)})
+	assert.ErrorContains(t, err, "failed to marshal value")
+}
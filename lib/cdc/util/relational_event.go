@@ -1,6 +1,8 @@
 package util
 
 import (
+	"encoding/json"
+	"fmt"
 	"log/slog"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/artie-labs/transfer/lib/cdc"
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/debezium"
+	"github.com/artie-labs/transfer/lib/jsonutil"
 	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/typing"
 )
@@ -19,6 +22,9 @@ import (
 type SchemaEventPayload struct {
 	Schema  debezium.Schema `json:"schema"`
 	Payload Payload         `json:"payload"`
+
+	// unknownTypePolicy is sourced from the typing settings and applies to fields that [debezium.Field.ToKindDetails] does not recognize.
+	unknownTypePolicy typing.UnknownTypePolicy
 }
 
 type Payload struct {
@@ -34,12 +40,88 @@ type Source struct {
 	Database  string `json:"db"`
 	Schema    string `json:"schema"`
 	Table     string `json:"table"`
+	// File and Pos are only set by MySQL, Postgres will set the LSN instead.
+	File string `json:"file"`
+	Pos  *int64 `json:"pos"`
+	LSN  *int64 `json:"lsn"`
+	// Snapshot is set for rows that were read by a snapshot.
+	Snapshot cdc.SnapshotFlag `json:"snapshot"`
+}
+
+// NewSchemaEventPayload builds an event from a schema and an already decoded payload.
+// This is used by the non-JSON formats (e.g. Avro), we round-trip the event through JSON so that it has the exact same shape and value types as the JSON parser.
+func NewSchemaEventPayload(typingSettings typing.Settings, schema debezium.Schema, payload any) (*SchemaEventPayload, error) {
+	bytes, err := json.Marshal(map[string]any{
+		"schema":  schema,
+		"payload": payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	var event SchemaEventPayload
+	if err = json.Unmarshal(bytes, &event); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	if err = event.setUnknownTypePolicy(typingSettings.UnknownTypePolicy); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// ParseSchemaEventPayload will decode a JSON event, if `preserveJSONNumbers` is enabled the row's numbers will not be decoded as float64.
+// Top-level numbers are converted into an int64 or float64, nested values are kept as [json.Number] so they'll be marshalled back exactly.
+func ParseSchemaEventPayload(typingSettings typing.Settings, bytes []byte) (*SchemaEventPayload, error) {
+	var event SchemaEventPayload
+	if err := jsonutil.Unmarshal(bytes, &event, typingSettings.PreserveJSONNumbers); err != nil {
+		return nil, err
+	}
+
+	for _, row := range []map[string]any{event.Payload.Before, event.Payload.After} {
+		for key, value := range row {
+			row[key] = jsonutil.NumberValue(value)
+		}
+	}
+
+	if err := event.setUnknownTypePolicy(typingSettings.UnknownTypePolicy); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// setUnknownTypePolicy will return an error if the policy is `error` and the schema contains a field with a type that we do not recognize.
+func (s *SchemaEventPayload) setUnknownTypePolicy(policy typing.UnknownTypePolicy) error {
+	if policy == typing.UnknownTypePolicyError {
+		if fieldsObject := s.fieldsObject(); fieldsObject != nil {
+			for _, field := range fieldsObject.Fields {
+				if field.ToKindDetails() == typing.Invalid {
+					return fmt.Errorf("field %q has an unknown type, type: %q, name: %q", field.FieldName, field.Type, field.DebeziumType)
+				}
+			}
+		}
+	}
+
+	s.unknownTypePolicy = policy
+	return nil
+}
+
+// fieldsObject returns the schema for the row, this is the AFTER schema.
+// Delete events will have a null `after` and some connectors will also omit its schema, in which case we'll fall back to the BEFORE schema.
+func (s *SchemaEventPayload) fieldsObject() *debezium.FieldsObject {
+	if fieldsObject := s.Schema.GetSchemaFromLabel(cdc.After); fieldsObject != nil {
+		return fieldsObject
+	}
+
+	return s.Schema.GetSchemaFromLabel(cdc.Before)
 }
 
 func (s *SchemaEventPayload) GetColumns() *columns.Columns {
-	fieldsObject := s.Schema.GetSchemaFromLabel(cdc.After)
+	fieldsObject := s.fieldsObject()
 	if fieldsObject == nil {
-		// AFTER schema does not exist.
+		// Neither the AFTER nor BEFORE schema exists.
 		return nil
 	}
 
@@ -57,6 +139,7 @@ func (s *SchemaEventPayload) GetColumns() *columns.Columns {
 			col.SetDefaultValue(val)
 		}
 
+		col.SetComment(field.Comment())
 		cols.AddColumn(col)
 	}
 
@@ -71,6 +154,10 @@ func (s *SchemaEventPayload) DeletePayload() bool {
 	return s.Payload.Operation == "d"
 }
 
+func (s *SchemaEventPayload) IsSnapshot() bool {
+	return s.Payload.Source.Snapshot.IsSnapshot()
+}
+
 func (s *SchemaEventPayload) GetExecutionTime() time.Time {
 	return time.UnixMilli(s.Payload.Source.TsMs).UTC()
 }
@@ -86,10 +173,25 @@ func (s *SchemaEventPayload) GetData(pkMap map[string]any, tc *kafkalib.TopicCon
 		// And we need to reconstruct the data bit since it will be empty.
 		// We _can_ rely on *before* since even without running replicate identity, it will still copy over
 		// the PK. We can explore simplifying this interface in the future by leveraging before.
-		retMap = map[string]any{
-			constants.DeleteColumnMarker: true,
+		retMap = make(map[string]any)
+		if tc.TombstoneDeletes() {
+			// The before image will contain every column if the table has full replica identity, otherwise it'll only contain the primary keys.
+			for k, v := range s.Payload.Before {
+				retMap[k] = v
+			}
+		}
+
+		// The primary key override columns need to be carried over, so we know which row to delete.
+		for _, col := range tc.PrimaryKeyOverride {
+			if v, isOk := s.Payload.Before[col]; isOk {
+				retMap[col] = v
+			}
 		}
 
+		retMap[constants.DeleteColumnMarker] = true
+
+		// The key will always have the primary keys, even if the before image is empty.
+		// These values will be typed below by the schema, so deletes will have the same primary key values as inserts and updates.
 		for k, v := range pkMap {
 			retMap[k] = v
 		}
@@ -103,6 +205,16 @@ func (s *SchemaEventPayload) GetData(pkMap map[string]any, tc *kafkalib.TopicCon
 		retMap[constants.DeleteColumnMarker] = false
 	}
 
+	if tc.IncludeDeletedAtColumn() {
+		// This is cleared when the row is inserted or updated again.
+		var deletedAt any
+		if len(s.Payload.After) == 0 {
+			deletedAt = s.GetExecutionTime().Format(ext.ISO8601)
+		}
+
+		retMap[constants.DeletedAtColumnMarker] = deletedAt
+	}
+
 	if tc.IncludeArtieUpdatedAt {
 		retMap[constants.UpdateColumnMarker] = ext.NewUTCTime(ext.ISO8601)
 	}
@@ -111,10 +223,27 @@ func (s *SchemaEventPayload) GetData(pkMap map[string]any, tc *kafkalib.TopicCon
 		retMap[constants.DatabaseUpdatedColumnMarker] = s.GetExecutionTime().Format(ext.ISO8601)
 	}
 
+	if tc.IncludeSnapshotColumn {
+		retMap[constants.SnapshotColumnMarker] = s.IsSnapshot()
+	}
+
+	for _, metadataColumn := range tc.MetadataColumnsToInclude() {
+		retMap[metadataColumn.ColumnName()] = s.metadataValue(metadataColumn)
+	}
+
+	if tc.UnavailableValuePlaceholder != "" {
+		// Swap a custom placeholder with the default one, so the rest of the pipeline will treat it as a TOAST column.
+		for key, value := range retMap {
+			if tc.IsUnavailableValue(value) {
+				retMap[key] = constants.ToastUnavailableValuePlaceholder
+			}
+		}
+	}
+
 	// Iterate over the schema and identify if there are any fields that require extra care.
-	afterSchemaObject := s.Schema.GetSchemaFromLabel(cdc.After)
-	if afterSchemaObject != nil {
-		for _, field := range afterSchemaObject.Fields {
+	fieldsObject := s.fieldsObject()
+	if fieldsObject != nil {
+		for _, field := range fieldsObject.Fields {
 			_, isOk := retMap[field.FieldName]
 			if !isOk {
 				// Skipping b/c envelope mismatch with the actual request body
@@ -122,6 +251,14 @@ func (s *SchemaEventPayload) GetData(pkMap map[string]any, tc *kafkalib.TopicCon
 			}
 
 			val, parseErr := field.ParseValue(retMap[field.FieldName])
+			if parseErr == nil && isRawBytes(field) {
+				val, parseErr = encodeBytes(val, tc.BytesEncoding)
+			}
+
+			if parseErr == nil && s.unknownTypePolicy == typing.UnknownTypePolicyString && field.ToKindDetails() == typing.Invalid {
+				val, parseErr = toRawString(val)
+			}
+
 			if parseErr == nil {
 				retMap[field.FieldName] = val
 			} else {
@@ -133,3 +270,42 @@ func (s *SchemaEventPayload) GetData(pkMap map[string]any, tc *kafkalib.TopicCon
 
 	return retMap
 }
+
+// toRawString is used for fields with an unknown type when they are stored as text, strings are kept as-is and anything else is stored as JSON.
+func toRawString(value any) (any, error) {
+	switch castedValue := value.(type) {
+	case nil, string:
+		return castedValue, nil
+	default:
+		bytes, err := json.Marshal(castedValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal value: %w", err)
+		}
+
+		return string(bytes), nil
+	}
+}
+
+// metadataValue returns the value for `column` from the event's source block, this will be nil if the source does not have it.
+func (s *SchemaEventPayload) metadataValue(column kafkalib.MetadataColumn) any {
+	switch column {
+	case kafkalib.MetadataColumnOp:
+		return s.Payload.Operation
+	case kafkalib.MetadataColumnSourceTsMs:
+		return s.Payload.Source.TsMs
+	case kafkalib.MetadataColumnFile:
+		if s.Payload.Source.File != "" {
+			return s.Payload.Source.File
+		}
+	case kafkalib.MetadataColumnPos:
+		if s.Payload.Source.Pos != nil {
+			return *s.Payload.Source.Pos
+		}
+
+		if s.Payload.Source.LSN != nil {
+			return *s.Payload.Source.LSN
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -130,6 +131,31 @@ func TestGetDataTestInsert(t *testing.T) {
 	assert.True(t, isOk)
 }
 
+func TestGetData_UnavailableValuePlaceholder(t *testing.T) {
+	schemaEventPayload := SchemaEventPayload{
+		Payload: Payload{
+			After: map[string]any{
+				"pk":    1,
+				"name":  "dusty",
+				"notes": "__unchanged__",
+			},
+			Operation: "u",
+		},
+	}
+
+	{
+		// Default placeholder, the value should be left as is.
+		evtData := schemaEventPayload.GetData(map[string]any{"pk": 1}, &kafkalib.TopicConfig{})
+		assert.Equal(t, "__unchanged__", evtData["notes"])
+	}
+	{
+		// Custom placeholder should be swapped with the default one.
+		evtData := schemaEventPayload.GetData(map[string]any{"pk": 1}, &kafkalib.TopicConfig{UnavailableValuePlaceholder: "__unchanged__"})
+		assert.Equal(t, constants.ToastUnavailableValuePlaceholder, evtData["notes"])
+		assert.Equal(t, "dusty", evtData["name"])
+	}
+}
+
 func TestGetDataTestDelete(t *testing.T) {
 	tc := &kafkalib.TopicConfig{
 		IdempotentKey: "updated_at",
@@ -163,6 +189,87 @@ func TestGetDataTestDelete(t *testing.T) {
 	assert.False(t, isOk, evtData)
 }
 
+func TestGetDataTestDelete_Tombstone(t *testing.T) {
+	now := time.Now().UTC()
+	schemaEventPayload := SchemaEventPayload{
+		Payload: Payload{
+			Before: map[string]any{
+				"pk":    1,
+				"name":  "dusty",
+				"email": "dusty@artie.so",
+			},
+			After:     nil,
+			Operation: "d",
+			Source:    Source{TsMs: now.UnixMilli()},
+		},
+	}
+
+	kvMap := map[string]any{"pk": 1}
+	{
+		// By default, we will only have the primary keys.
+		evtData := schemaEventPayload.GetData(kvMap, &kafkalib.TopicConfig{})
+		assert.Equal(t, map[string]any{"pk": 1, constants.DeleteColumnMarker: true}, evtData)
+	}
+	{
+		// Tombstone should contain the prior column values.
+		evtData := schemaEventPayload.GetData(kvMap, &kafkalib.TopicConfig{SoftDeleteStrategy: kafkalib.SoftDeleteTombstone})
+		assert.Equal(t, map[string]any{
+			"pk":                         1,
+			"name":                       "dusty",
+			"email":                      "dusty@artie.so",
+			constants.DeleteColumnMarker: true,
+		}, evtData)
+	}
+	{
+		// Primary key override columns should be carried over from the before image.
+		evtData := schemaEventPayload.GetData(kvMap, &kafkalib.TopicConfig{PrimaryKeyOverride: []string{"email"}})
+		assert.Equal(t, map[string]any{"pk": 1, "email": "dusty@artie.so", constants.DeleteColumnMarker: true}, evtData)
+	}
+}
+
+func TestGetData_DeletedAtColumn(t *testing.T) {
+	now := time.Now().UTC()
+	tc := &kafkalib.TopicConfig{SoftDelete: true, SoftDeleteMarker: kafkalib.SoftDeleteMarkerTimestamp}
+	kvMap := map[string]any{"pk": 1}
+	{
+		// Delete should set the deleted at column to the source timestamp.
+		schemaEventPayload := SchemaEventPayload{
+			Payload: Payload{
+				Before:    map[string]any{"pk": 1, "name": "dusty"},
+				Operation: "d",
+				Source:    Source{TsMs: now.UnixMilli()},
+			},
+		}
+
+		evtData := schemaEventPayload.GetData(kvMap, tc)
+		assert.Equal(t, now.Format(ext.ISO8601), evtData[constants.DeletedAtColumnMarker])
+		assert.Equal(t, true, evtData[constants.DeleteColumnMarker])
+	}
+	{
+		// Insert and updates should clear it.
+		for _, op := range []string{"c", "u"} {
+			schemaEventPayload := SchemaEventPayload{
+				Payload: Payload{
+					After:     map[string]any{"pk": 1, "name": "dusty"},
+					Operation: op,
+					Source:    Source{TsMs: now.UnixMilli()},
+				},
+			}
+
+			evtData := schemaEventPayload.GetData(kvMap, tc)
+			value, isOk := evtData[constants.DeletedAtColumnMarker]
+			assert.True(t, isOk, op)
+			assert.Nil(t, value, op)
+		}
+	}
+	{
+		// Not included by default.
+		schemaEventPayload := SchemaEventPayload{Payload: Payload{Operation: "d", Source: Source{TsMs: now.UnixMilli()}}}
+		_, isOk := schemaEventPayload.GetData(kvMap, &kafkalib.TopicConfig{SoftDelete: true})[constants.DeletedAtColumnMarker]
+		assert.False(t, isOk)
+	}
+}
+
 func TestGetDataTestUpdate(t *testing.T) {
 	before := map[string]any{
 		"pk":           1,
@@ -213,3 +320,150 @@ func TestGetDataTestUpdate(t *testing.T) {
 	_, isOk = evtData[constants.UpdateColumnMarker]
 	assert.True(t, isOk)
 }
+
+func TestGetData_MetadataColumns(t *testing.T) {
+	lsn := int64(24023128)
+	newSchemaEventPayload := func() SchemaEventPayload {
+		return SchemaEventPayload{
+			Payload: Payload{
+				After:     map[string]any{"pk": 1, "name": "dusty"},
+				Operation: "c",
+				Source:    Source{Connector: "postgresql", TsMs: 1678735164000, LSN: &lsn},
+			},
+		}
+	}
+
+	kvMap := map[string]any{"pk": 1}
+	{
+		// All metadata columns, Postgres does not have a file and will use the LSN as the position.
+		schemaEventPayload := newSchemaEventPayload()
+		evtData := schemaEventPayload.GetData(kvMap, &kafkalib.TopicConfig{IncludeMetadataColumns: true})
+		assert.Equal(t, "c", evtData[constants.OpColumnMarker])
+		assert.Equal(t, int64(1678735164000), evtData[constants.SourceTsMsColumnMarker])
+		assert.Equal(t, lsn, evtData[constants.PosColumnMarker])
+		value, isOk := evtData[constants.FileColumnMarker]
+		assert.True(t, isOk)
+		assert.Nil(t, value)
+	}
+	{
+		// Subset
+		tc := &kafkalib.TopicConfig{IncludeMetadataColumns: true, MetadataColumns: []kafkalib.MetadataColumn{kafkalib.MetadataColumnOp}}
+		schemaEventPayload := newSchemaEventPayload()
+		evtData := schemaEventPayload.GetData(kvMap, tc)
+		assert.Equal(t, "c", evtData[constants.OpColumnMarker])
+		for _, col := range []string{constants.SourceTsMsColumnMarker, constants.FileColumnMarker, constants.PosColumnMarker} {
+			_, isOk := evtData[col]
+			assert.False(t, isOk, col)
+		}
+	}
+}
+
+func TestSchemaEventPayload_IsSnapshot(t *testing.T) {
+	const payloadTemplate = `{"payload": {"after": {"pk": 1, "name": "dusty"}, "op": "%s", "source": {"connector": "postgresql", "ts_ms": 1678735164000 %s}}}`
+	for _, tc := range []struct {
+		name           string
+		op             string
+		snapshot       string
+		expectedResult bool
+	}{
+		{name: "streaming, no snapshot field", op: "c"},
+		{name: "streaming", op: "u", snapshot: `, "snapshot": "false"`},
+		{name: "snapshot", op: "r", snapshot: `, "snapshot": "true"`, expectedResult: true},
+		{name: "last snapshot row", op: "r", snapshot: `, "snapshot": "last"`, expectedResult: true},
+		{name: "incremental snapshot", op: "r", snapshot: `, "snapshot": "incremental"`, expectedResult: true},
+		{name: "boolean snapshot", op: "r", snapshot: `, "snapshot": true`, expectedResult: true},
+		{name: "boolean streaming", op: "c", snapshot: `, "snapshot": false`},
+		{name: "null snapshot", op: "c", snapshot: `, "snapshot": null`},
+	} {
+		var schemaEventPayload SchemaEventPayload
+		assert.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(payloadTemplate, tc.op, tc.snapshot)), &schemaEventPayload), tc.name)
+		assert.Equal(t, tc.expectedResult, schemaEventPayload.IsSnapshot(), tc.name)
+
+		// Not included by default
+		evtData := schemaEventPayload.GetData(map[string]any{"pk": 1}, &kafkalib.TopicConfig{})
+		_, isOk := evtData[constants.SnapshotColumnMarker]
+		assert.False(t, isOk, tc.name)
+
+		evtData = schemaEventPayload.GetData(map[string]any{"pk": 1}, &kafkalib.TopicConfig{IncludeSnapshotColumn: true})
+		assert.Equal(t, tc.expectedResult, evtData[constants.SnapshotColumnMarker], tc.name)
+	}
+}
+
+func TestGetData_BytesEncoding(t *testing.T) {
+	const payload = `{
+	"schema": {
+		"type": "struct",
+		"fields": [{
+			"type": "struct",
+			"fields": [
+				{"type": "int32", "optional": false, "field": "pk"},
+				{"type": "bytes", "optional": true, "field": "data"},
+				{"type": "bytes", "optional": true, "name": "org.apache.kafka.connect.data.Decimal", "version": 1, "parameters": {"scale": "2"}, "field": "price"}
+			],
+			"optional": true,
+			"field": "after"
+		}]
+	},
+	"payload": {"after": {"pk": 1, "data": "aGVsbG8=", "price": "MDk="}, "op": "c"}
+}`
+
+	for _, tc := range []struct {
+		name          string
+		encoding      kafkalib.BytesEncoding
+		expectedValue any
+	}{
+		{name: "default", expectedValue: "aGVsbG8="},
+		{name: "base64", encoding: kafkalib.BytesEncodingBase64, expectedValue: "aGVsbG8="},
+		{name: "hex", encoding: kafkalib.BytesEncodingHex, expectedValue: "68656c6c6f"},
+		{name: "native", encoding: kafkalib.BytesEncodingNative, expectedValue: []byte("hello")},
+	} {
+		var schemaEventPayload SchemaEventPayload
+		assert.NoError(t, json.Unmarshal([]byte(payload), &schemaEventPayload), tc.name)
+
+		evtData := schemaEventPayload.GetData(map[string]any{"pk": 1}, &kafkalib.TopicConfig{BytesEncoding: tc.encoding})
+		assert.Equal(t, tc.expectedValue, evtData["data"], tc.name)
+
+		// Decimals are also sent as bytes, they should still be parsed as decimals.
+		assert.Equal(t, "123.45", fmt.Sprint(evtData["price"]), tc.name)
+
+		// The schema should be declared as bytes, regardless of the encoding.
+		assert.Equal(t, typing.Bytes, schemaEventPayload.GetOptionalSchema()["data"], tc.name)
+	}
+}
+
+func TestSchemaEventPayload_GetColumns_Comment(t *testing.T) {
+	var schemaEventPayload SchemaEventPayload
+	assert.NoError(t, json.Unmarshal([]byte(`{
+	"schema": {
+		"type": "struct",
+		"fields": [{
+			"type": "struct",
+			"fields": [{
+				"type": "int32",
+				"optional": false,
+				"field": "id"
+			}, {
+				"type": "string",
+				"optional": true,
+				"field": "email",
+				"doc": "Primary email address"
+			}],
+			"optional": true,
+			"name": "dbserver1.inventory.customers.Value",
+			"field": "after"
+		}],
+		"optional": false,
+		"name": "dbserver1.inventory.customers.Envelope"
+	},
+	"payload": {}
+}`), &schemaEventPayload))
+
+	cols := schemaEventPayload.GetColumns()
+	idCol, isOk := cols.GetColumn("id")
+	assert.True(t, isOk)
+	assert.Empty(t, idCol.Comment())
+
+	emailCol, isOk := cols.GetColumn("email")
+	assert.True(t, isOk)
+	assert.Equal(t, "Primary email address", emailCol.Comment())
+}
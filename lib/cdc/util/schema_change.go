@@ -0,0 +1,120 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+// SchemaChangeEvent is the event that Debezium writes to the schema change topic, see: https://debezium.io/documentation/reference/stable/connectors/mysql.html#mysql-schema-change-topic
+type SchemaChangeEvent struct {
+	DatabaseName string        `json:"databaseName"`
+	SchemaName   string        `json:"schemaName"`
+	DDL          string        `json:"ddl"`
+	TableChanges []TableChange `json:"tableChanges"`
+}
+
+type TableChange struct {
+	// Type is one of CREATE, ALTER or DROP.
+	Type string `json:"type"`
+	// ID is the fully qualified table name, e.g. "inventory"."customers"
+	ID    string `json:"id"`
+	Table struct {
+		Columns []SchemaChangeColumn `json:"columns"`
+	} `json:"table"`
+}
+
+// TableName returns the unquoted table name from the table change's ID.
+func (t TableChange) TableName() string {
+	parts := strings.Split(t.ID, ".")
+	return strings.Trim(parts[len(parts)-1], `"`)
+}
+
+type SchemaChangeColumn struct {
+	Name string `json:"name"`
+	// JdbcType is the column's type from java.sql.Types
+	JdbcType int  `json:"jdbcType"`
+	Length   *int `json:"length"`
+	Scale    *int `json:"scale"`
+}
+
+// java.sql.Types, see: https://docs.oracle.com/javase/8/docs/api/constant-values.html#java.sql.Types.BIT
+const (
+	jdbcBit                   = -7
+	jdbcTinyInt               = -6
+	jdbcBigInt                = -5
+	jdbcNumeric               = 2
+	jdbcDecimal               = 3
+	jdbcInteger               = 4
+	jdbcSmallInt              = 5
+	jdbcFloat                 = 6
+	jdbcReal                  = 7
+	jdbcDouble                = 8
+	jdbcBoolean               = 16
+	jdbcDate                  = 91
+	jdbcTime                  = 92
+	jdbcTimestamp             = 93
+	jdbcTimeWithTimezone      = 2013
+	jdbcTimestampWithTimezone = 2014
+)
+
+// KindDetails maps the column's JDBC type to our typing, anything that we do not recognize will be a string.
+func (s SchemaChangeColumn) KindDetails() typing.KindDetails {
+	switch s.JdbcType {
+	case jdbcBit, jdbcBoolean:
+		return typing.Boolean
+	case jdbcTinyInt, jdbcSmallInt, jdbcInteger, jdbcBigInt:
+		return typing.Integer
+	case jdbcFloat, jdbcReal, jdbcDouble:
+		return typing.Float
+	case jdbcNumeric, jdbcDecimal:
+		precision := ptr.ToInt(decimal.PrecisionNotSpecified)
+		if s.Length != nil {
+			precision = s.Length
+		}
+
+		scale := decimal.DefaultScale
+		if s.Scale != nil {
+			scale = *s.Scale
+		}
+
+		eDecimal := typing.EDecimal
+		eDecimal.ExtendedDecimalDetails = decimal.NewDecimal(precision, scale, nil)
+		return eDecimal
+	case jdbcDate:
+		return typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateKindType)
+	case jdbcTime, jdbcTimeWithTimezone:
+		return typing.NewKindDetailsFromTemplate(typing.ETime, ext.TimeKindType)
+	case jdbcTimestamp, jdbcTimestampWithTimezone:
+		return typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateTimeKindType)
+	default:
+		return typing.String
+	}
+}
+
+// ParseSchemaChangeEvent will parse a schema change event, with or without the schema envelope.
+func ParseSchemaChangeEvent(bytes []byte) (*SchemaChangeEvent, error) {
+	var envelope struct {
+		Payload *SchemaChangeEvent `json:"payload"`
+	}
+
+	if err := json.Unmarshal(bytes, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema change event: %w", err)
+	}
+
+	if envelope.Payload != nil {
+		return envelope.Payload, nil
+	}
+
+	var event SchemaChangeEvent
+	if err := json.Unmarshal(bytes, &event); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema change event: %w", err)
+	}
+
+	return &event, nil
+}
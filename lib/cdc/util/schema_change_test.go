@@ -0,0 +1,50 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+func TestParseSchemaChangeEvent(t *testing.T) {
+	{
+		// With the schema envelope
+		event, err := ParseSchemaChangeEvent([]byte(`{"schema": {}, "payload": {"databaseName": "inventory", "ddl": "ALTER TABLE customers ADD COLUMN email VARCHAR(255)", "tableChanges": [{"type": "ALTER", "id": "\"inventory\".\"customers\"", "table": {"columns": [{"name": "email", "jdbcType": 12, "length": 255}]}}]}}`))
+		assert.NoError(t, err)
+		assert.Equal(t, "inventory", event.DatabaseName)
+		assert.Len(t, event.TableChanges, 1)
+		assert.Equal(t, "ALTER", event.TableChanges[0].Type)
+		assert.Equal(t, "customers", event.TableChanges[0].TableName())
+		assert.Equal(t, []SchemaChangeColumn{{Name: "email", JdbcType: 12, Length: ptr.ToInt(255)}}, event.TableChanges[0].Table.Columns)
+	}
+	{
+		// Without the schema envelope
+		event, err := ParseSchemaChangeEvent([]byte(`{"databaseName": "inventory", "schemaName": "public", "ddl": "DROP TABLE orders", "tableChanges": [{"type": "DROP", "id": "\"inventory\".\"public\".\"orders\""}]}`))
+		assert.NoError(t, err)
+		assert.Equal(t, "public", event.SchemaName)
+		assert.Equal(t, "orders", event.TableChanges[0].TableName())
+	}
+	{
+		// Malformed
+		_, err := ParseSchemaChangeEvent([]byte(`{`))
+		assert.ErrorContains(t, err, "failed to unmarshal schema change event")
+	}
+}
+
+func TestSchemaChangeColumn_KindDetails(t *testing.T) {
+	assert.Equal(t, typing.Boolean, SchemaChangeColumn{JdbcType: jdbcBoolean}.KindDetails())
+	assert.Equal(t, typing.Integer, SchemaChangeColumn{JdbcType: jdbcBigInt}.KindDetails())
+	assert.Equal(t, typing.Float, SchemaChangeColumn{JdbcType: jdbcDouble}.KindDetails())
+	assert.Equal(t, typing.String, SchemaChangeColumn{JdbcType: 12}.KindDetails())
+	assert.Equal(t, ext.DateKindType, SchemaChangeColumn{JdbcType: jdbcDate}.KindDetails().ExtendedTimeDetails.Type)
+	assert.Equal(t, ext.DateTimeKindType, SchemaChangeColumn{JdbcType: jdbcTimestamp}.KindDetails().ExtendedTimeDetails.Type)
+
+	decimalKind := SchemaChangeColumn{JdbcType: jdbcDecimal, Length: ptr.ToInt(10), Scale: ptr.ToInt(2)}.KindDetails()
+	assert.Equal(t, typing.EDecimal.Kind, decimalKind.Kind)
+	assert.Equal(t, 10, *decimalKind.ExtendedDecimalDetails.Precision())
+	assert.Equal(t, 2, decimalKind.ExtendedDecimalDetails.Scale())
+}
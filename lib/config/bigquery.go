@@ -10,6 +10,10 @@ type BigQuery struct {
 	ProjectID         string `yaml:"projectID"`
 	Location          string `yaml:"location"`
 	BatchSize         int    `yaml:"batchSize"`
+	// SkipInvalidRows - if enabled, rows that BigQuery rejects will be logged and skipped instead of failing the whole batch.
+	SkipInvalidRows bool `yaml:"skipInvalidRows"`
+
+	ConnectionPool *ConnectionPool `yaml:"connectionPool,omitempty"`
 }
 
 func (b *BigQuery) LoadDefaultValues() {
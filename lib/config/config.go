@@ -1,15 +1,17 @@
 package config
 
 import (
+	"context"
 	"fmt"
-	"io"
-	"os"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/artie-labs/transfer/lib/array"
+	"github.com/artie-labs/transfer/lib/assertions"
 	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/jitter"
 	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/numbers"
 	"github.com/artie-labs/transfer/lib/stringutil"
@@ -20,7 +22,14 @@ const (
 	defaultFlushTimeSeconds = 10
 	defaultFlushSizeKb      = 25 * 1024 // 25 mb
 	defaultBufferPoolSize   = 30000
-	bufferPoolSizeMin       = 5
+	// defaultSnapshotIdleSeconds is how long we'll wait without receiving a message before we consider the backlog drained.
+	defaultSnapshotIdleSeconds = 30
+	// defaultFlushJitterBaseMs is the minimum upper bound for the Pub/Sub flush jitter.
+	defaultFlushJitterBaseMs = 500
+	bufferPoolSizeMin        = 5
+	maxRedshiftCopyErrors    = 100_000
+	// maxRedshiftStagingFileCount is well above the number of slices of the largest Redshift cluster.
+	maxRedshiftStagingFileCount = 1024
 
 	FlushIntervalSecondsMin = 5
 	FlushIntervalSecondsMax = 6 * 60 * 60
@@ -30,28 +39,207 @@ type Sentry struct {
 	DSN string `yaml:"dsn"`
 }
 
+type HealthCheck struct {
+	Port int `yaml:"port"`
+	// StalenessThresholdSeconds - readiness will fail if a table has had rows buffered for longer than this without a successful flush.
+	StalenessThresholdSeconds int `yaml:"stalenessThresholdSeconds"`
+}
+
+func (h HealthCheck) Validate() error {
+	if !numbers.BetweenEq(1, 65535, h.Port) {
+		return fmt.Errorf("invalid port: %d", h.Port)
+	}
+
+	if h.StalenessThresholdSeconds <= 0 {
+		return fmt.Errorf("staleness threshold seconds has to be a positive number, current value: %v", h.StalenessThresholdSeconds)
+	}
+
+	return nil
+}
+
 type Pubsub struct {
-	ProjectID         string                  `yaml:"projectID"`
-	TopicConfigs      []*kafkalib.TopicConfig `yaml:"topicConfigs"`
-	PathToCredentials string                  `yaml:"pathToCredentials"`
+	ProjectID    string                  `yaml:"projectID"`
+	TopicConfigs []*kafkalib.TopicConfig `yaml:"topicConfigs"`
+	// PathToCredentials is optional, if it's not set we'll use Application Default Credentials (e.g. workload identity).
+	PathToCredentials string `yaml:"pathToCredentials"`
+	// AckDeadlineSeconds is used when Transfer creates the subscription, this defaults to 10 minutes.
+	AckDeadlineSeconds int `yaml:"ackDeadlineSeconds,omitempty"`
+	// MaxOutstandingMessages is the maximum number of unacknowledged messages, this defaults to `bufferRows` + 1.
+	MaxOutstandingMessages int `yaml:"maxOutstandingMessages,omitempty"`
+	// MaxOutstandingBytes is the maximum size of unacknowledged messages, this defaults to the Pub/Sub client's default (1 GB).
+	MaxOutstandingBytes int `yaml:"maxOutstandingBytes,omitempty"`
+	// FlushJitterBaseMs and FlushJitterMaxMs bound how long we'll wait after a flush before receiving the next message.
+	// Within these bounds, the wait will grow with how long recent flushes took so that concurrent receives do not trigger redundant flushes.
+	// These default to 500ms and 3.5s.
+	FlushJitterBaseMs int `yaml:"flushJitterBaseMs,omitempty"`
+	FlushJitterMaxMs  int `yaml:"flushJitterMaxMs,omitempty"`
+}
+
+// FlushJitterBounds returns the base and max for the flush jitter in milliseconds.
+func (p Pubsub) FlushJitterBounds() (int, int) {
+	baseMs, maxMs := defaultFlushJitterBaseMs, jitter.DefaultMaxMs
+	if p.FlushJitterBaseMs > 0 {
+		baseMs = p.FlushJitterBaseMs
+	}
+
+	if p.FlushJitterMaxMs > 0 {
+		maxMs = p.FlushJitterMaxMs
+	}
+
+	return baseMs, maxMs
+}
+
+func (p Pubsub) Validate() error {
+	if p.ProjectID == "" {
+		return fmt.Errorf("pubsub projectID is empty")
+	}
+
+	// Pub/Sub only allows an ack deadline between 10 seconds and 10 minutes.
+	if p.AckDeadlineSeconds != 0 && (p.AckDeadlineSeconds < 10 || p.AckDeadlineSeconds > 600) {
+		return fmt.Errorf("pubsub ackDeadlineSeconds must be between 10 and 600, current value: %d", p.AckDeadlineSeconds)
+	}
+
+	if p.MaxOutstandingMessages < 0 {
+		return fmt.Errorf("pubsub maxOutstandingMessages cannot be negative, current value: %d", p.MaxOutstandingMessages)
+	}
+
+	if p.MaxOutstandingBytes < 0 {
+		return fmt.Errorf("pubsub maxOutstandingBytes cannot be negative, current value: %d", p.MaxOutstandingBytes)
+	}
+
+	if p.FlushJitterBaseMs < 0 || p.FlushJitterMaxMs < 0 {
+		return fmt.Errorf("pubsub flushJitterBaseMs and flushJitterMaxMs cannot be negative")
+	}
+
+	if baseMs, maxMs := p.FlushJitterBounds(); baseMs > maxMs {
+		return fmt.Errorf("pubsub flushJitterBaseMs: %d cannot be greater than flushJitterMaxMs: %d", baseMs, maxMs)
+	}
+
+	return nil
 }
 
 type Kafka struct {
 	// Comma-separated Kafka servers to port.
 	// e.g. host1:port1,host2:port2,...
 	// Following kafka's spec mentioned here: https://kafka.apache.org/documentation/#producerconfigs_bootstrap.servers
-	BootstrapServer string                  `yaml:"bootstrapServer"`
-	GroupID         string                  `yaml:"groupID"`
-	Username        string                  `yaml:"username,omitempty"`
-	Password        string                  `yaml:"password,omitempty"`
-	EnableAWSMSKIAM bool                    `yaml:"enableAWSMKSIAM"`
-	TopicConfigs    []*kafkalib.TopicConfig `yaml:"topicConfigs"`
+	BootstrapServer string `yaml:"bootstrapServer"`
+	GroupID         string `yaml:"groupID"`
+	Username        string `yaml:"username,omitempty"`
+	Password        string `yaml:"password,omitempty"`
+	EnableAWSMSKIAM bool   `yaml:"enableAWSMKSIAM"`
+	// SASLMechanism is optional, if it's not set we'll use PLAIN when a username is provided.
+	// For OAUTHBEARER, the password is used as the bearer token.
+	SASLMechanism SASLMechanism `yaml:"saslMechanism,omitempty"`
+	// TLSEnabled will connect to the brokers over TLS, this is always enabled when using SASL or AWS MSK IAM.
+	// Azure Event Hubs requires TLS with PLAIN, the username set to `$ConnectionString` and the password set to the connection string.
+	TLSEnabled bool `yaml:"tlsEnabled,omitempty"`
+	// FlushOnRebalance - if enabled, buffered rows will be flushed (and their offsets committed) before partitions are revoked during a rebalance.
+	FlushOnRebalance bool `yaml:"flushOnRebalance"`
+	// StartFrom is optional and is only applied to partitions that the consumer group has not committed an offset for, otherwise we'll resume from the committed offset.
+	// This can be `earliest`, `latest`, an RFC 3339 timestamp or explicit partition offsets (e.g. `0:100,1:250`).
+	// Setting this will join the consumer group directly, so buffered rows will also be flushed on rebalance.
+	StartFrom string `yaml:"startFrom,omitempty"`
+	// OffsetLagIntervalSeconds is optional, if set we'll poll the committed offsets of the consumer group and the partition high-water marks on this interval.
+	// The offset lag will be emitted as a metric and exposed through the health endpoint, this requires the consumer to be able to describe the group's offsets.
+	OffsetLagIntervalSeconds int                     `yaml:"offsetLagIntervalSeconds,omitempty"`
+	TopicConfigs             []*kafkalib.TopicConfig `yaml:"topicConfigs"`
+}
+
+type SASLMechanism string
+
+const (
+	SASLMechanismPlain       SASLMechanism = "PLAIN"
+	SASLMechanismSCRAMSHA256 SASLMechanism = "SCRAM-SHA-256"
+	SASLMechanismSCRAMSHA512 SASLMechanism = "SCRAM-SHA-512"
+	SASLMechanismOAuthBearer SASLMechanism = "OAUTHBEARER"
+)
+
+func (s SASLMechanism) Validate() error {
+	switch s {
+	case "", SASLMechanismPlain, SASLMechanismSCRAMSHA256, SASLMechanismSCRAMSHA512, SASLMechanismOAuthBearer:
+		return nil
+	}
+
+	return fmt.Errorf("invalid sasl mechanism: %q", s)
+}
+
+func (k Kafka) Validate() error {
+	if err := k.SASLMechanism.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := kafkalib.ParseStartFrom(k.StartFrom); err != nil {
+		return fmt.Errorf("invalid start from: %w", err)
+	}
+
+	if k.OffsetLagIntervalSeconds < 0 {
+		return fmt.Errorf("offset lag interval seconds cannot be negative, current value: %v", k.OffsetLagIntervalSeconds)
+	}
+
+	if k.SASLMechanism != "" {
+		if k.EnableAWSMSKIAM {
+			return fmt.Errorf("sasl mechanism cannot be used with AWS MSK IAM")
+		}
+
+		if k.SASLMechanism != SASLMechanismOAuthBearer && k.Username == "" {
+			return fmt.Errorf("username is required for sasl mechanism: %s", k.SASLMechanism)
+		}
+
+		if k.Password == "" {
+			return fmt.Errorf("password is required for sasl mechanism: %s", k.SASLMechanism)
+		}
+	}
+
+	return nil
 }
 
 func (k *Kafka) BootstrapServers() []string {
 	return strings.Split(k.BootstrapServer, ",")
 }
 
+type NATS struct {
+	// Servers is a list of NATS server URLs, e.g. nats://host1:4222
+	Servers []string `yaml:"servers"`
+	// Stream is the JetStream stream that the topics (subjects) are published to.
+	Stream string `yaml:"stream"`
+	// DurableName is used to name the durable consumer that we create for each topic, so progress is kept across restarts.
+	DurableName string `yaml:"durableName"`
+	// CredentialsFile is optional, it is the path to a NATS user credentials (.creds) file.
+	CredentialsFile string `yaml:"credentialsFile,omitempty"`
+	Username        string `yaml:"username,omitempty"`
+	Password        string `yaml:"password,omitempty"`
+	Token           string `yaml:"token,omitempty"`
+	// AckWaitSeconds is how long JetStream will wait for an ack before redelivering, this defaults to 10 minutes.
+	AckWaitSeconds int `yaml:"ackWaitSeconds,omitempty"`
+	// MaxAckPending is the maximum number of unacknowledged messages, this defaults to `bufferRows` + 1.
+	MaxAckPending int                     `yaml:"maxAckPending,omitempty"`
+	TopicConfigs  []*kafkalib.TopicConfig `yaml:"topicConfigs"`
+}
+
+func (n NATS) Validate() error {
+	if len(n.Servers) == 0 {
+		return fmt.Errorf("nats servers is empty")
+	}
+
+	if array.Empty([]string{n.Stream, n.DurableName}) {
+		return fmt.Errorf("nats stream or durableName is empty")
+	}
+
+	if n.Token != "" && (n.Username != "" || n.Password != "") {
+		return fmt.Errorf("nats token cannot be used with username and password")
+	}
+
+	if n.AckWaitSeconds < 0 {
+		return fmt.Errorf("nats ackWaitSeconds cannot be negative, current value: %d", n.AckWaitSeconds)
+	}
+
+	if n.MaxAckPending < 0 {
+		return fmt.Errorf("nats maxAckPending cannot be negative, current value: %d", n.MaxAckPending)
+	}
+
+	return nil
+}
+
 type S3Settings struct {
 	OptionalPrefix     string                   `yaml:"optionalPrefix"`
 	Bucket             string                   `yaml:"bucket"`
@@ -87,10 +275,48 @@ type Redshift struct {
 	// https://docs.aws.amazon.com/redshift/latest/dg/copy-parameters-authorization.html
 	CredentialsClause string `yaml:"credentialsClause"`
 	SkipLgCols        bool   `yaml:"skipLgCols"`
+	// CompressStaging - staging files are gzip compressed by default, set this to false to upload them uncompressed.
+	CompressStaging *bool `yaml:"compressStaging,omitempty"`
+	// KMSKeyARN is optional, if set the staging files will be encrypted at rest with SSE-KMS using this key.
+	KMSKeyARN string `yaml:"kmsKeyARN,omitempty"`
+	// MaxCopyErrors is optional, if set the COPY command will skip up to this many bad rows instead of failing. Rows that were rejected will be logged.
+	MaxCopyErrors int `yaml:"maxCopyErrors,omitempty"`
+	// AutoWidenVarchar - if enabled, VARCHAR columns that are too narrow for the incoming strings will be widened (up to 65535) before loading.
+	AutoWidenVarchar bool `yaml:"autoWidenVarchar,omitempty"`
+	// Spectrum - if this is set, Transfer will write Parquet files into S3 and register them against a Redshift Spectrum external table instead of loading into Redshift.
+	Spectrum *RedshiftSpectrum `yaml:"spectrum,omitempty"`
+	// StagingSchema is optional, if set the temporary tables will be created in this schema instead of alongside the target table.
+	StagingSchema string `yaml:"stagingSchema,omitempty"`
+	// StagingFileCount is optional, if set the staging data will be split into this many files under a common S3 prefix so that COPY can load them in parallel.
+	// This should be a multiple of the number of slices in the cluster, by default we'll upload a single file.
+	StagingFileCount int `yaml:"stagingFileCount,omitempty"`
+	// DriverTLS is optional, the CA bundle and client certificate will be passed to the Postgres driver.
+	DriverTLS `yaml:",inline"`
+
+	ConnectionPool *ConnectionPool `yaml:"connectionPool,omitempty"`
+}
+
+func (r Redshift) ShouldCompressStaging() bool {
+	return r.CompressStaging == nil || *r.CompressStaging
+}
+
+type RedshiftSpectrum struct {
+	// ExternalSchema is the external schema that was created via `CREATE EXTERNAL SCHEMA ... FROM DATA CATALOG`.
+	ExternalSchema string `yaml:"externalSchema"`
 }
 
 type SharedDestinationConfig struct {
-	UppercaseEscapedNames bool `yaml:"uppercaseEscapedNames"`
+	// Deprecated: Use IdentifierCasing instead, this is the same as setting IdentifierCasing to `upper`.
+	UppercaseEscapedNames bool             `yaml:"uppercaseEscapedNames"`
+	IdentifierCasing      IdentifierCasing `yaml:"identifierCasing"`
+	// SanitizeColumnNames - if enabled, characters that are not allowed in the destination's identifiers (e.g. dashes) will be replaced with underscores.
+	SanitizeColumnNames bool `yaml:"sanitizeColumnNames"`
+	// MaxValueLength is optional, if set string values that are longer than this (in bytes) will be truncated and JSON values will be replaced with a marker.
+	// This can be overridden per column with the topic's `columnMaxLengths`.
+	MaxValueLength int `yaml:"maxValueLength,omitempty"`
+	// CaseInsensitiveColumns - if enabled, our columns will match the existing destination columns regardless of case (e.g. `Email` will match `email`).
+	// This is useful if the destination folds the case of identifiers differently than we do, so that we don't try to add the column again and our queries refer to the column by its existing name.
+	CaseInsensitiveColumns bool `yaml:"caseInsensitiveColumns,omitempty"`
 }
 
 type SharedTransferConfig struct {
@@ -98,31 +324,98 @@ type SharedTransferConfig struct {
 }
 
 type Snowflake struct {
-	AccountID   string `yaml:"account"`
-	Username    string `yaml:"username"`
-	Password    string `yaml:"password"`
-	Warehouse   string `yaml:"warehouse"`
+	AccountID string `yaml:"account"`
+	Username  string `yaml:"username"`
+	Password  string `yaml:"password"`
+	Warehouse string `yaml:"warehouse"`
+	// Role is optional, if not set the user's default role will be used.
+	Role        string `yaml:"role"`
 	Region      string `yaml:"region"`
 	Host        string `yaml:"host"`
 	Application string `yaml:"application"`
+	// OrphanTTLSeconds is how old a temporary table has to be before it's dropped (along with its staged files) by the startup sweep, this defaults to `constants.TemporaryTableTTL`.
+	OrphanTTLSeconds int `yaml:"orphanTTLSeconds"`
+	// TransactionalMerge - if enabled, the COPY into the temporary table and the merge will be run within an explicit BEGIN/COMMIT and will be rolled back if either fails.
+	// DDL (creating the temporary table, adding columns and backfills) and the PUT are run beforehand and the temporary table is dropped afterwards,
+	// since Snowflake will implicitly commit any open transaction when DDL is executed and PUT is not transactional.
+	// This is off by default, some users prefer autocommit for very large merges.
+	TransactionalMerge bool `yaml:"transactionalMerge,omitempty"`
+	// StagingSchema is optional, if set the temporary tables (and their table stages) will be created in this schema instead of the target table's schema.
+	StagingSchema string `yaml:"stagingSchema,omitempty"`
+	// DriverTLS is optional, if set the driver's HTTP client will use the provided CA bundle and client certificate.
+	DriverTLS `yaml:",inline"`
+
+	ConnectionPool *ConnectionPool `yaml:"connectionPool,omitempty"`
+}
+
+func (s Snowflake) OrphanTTL() time.Duration {
+	if s.OrphanTTLSeconds > 0 {
+		return time.Duration(s.OrphanTTLSeconds) * time.Second
+	}
+
+	return constants.TemporaryTableTTL
 }
 
 func (p *Pubsub) String() string {
 	return fmt.Sprintf("project_id=%s, pathToCredentials=%s", p.ProjectID, p.PathToCredentials)
 }
 
+func (n *NATS) String() string {
+	// Don't log credentials.
+	return fmt.Sprintf("servers=%s, stream=%s, durableName=%s, creds_set=%v, user_set=%v, token_set=%v",
+		strings.Join(n.Servers, ","), n.Stream, n.DurableName, n.CredentialsFile != "", n.Username != "", n.Token != "")
+}
+
 func (k *Kafka) String() string {
 	// Don't log credentials.
-	return fmt.Sprintf("bootstrapServer=%s, groupID=%s, user_set=%v, pass_set=%v",
-		k.BootstrapServer, k.GroupID, k.Username != "", k.Password != "")
+	return fmt.Sprintf("bootstrapServer=%s, groupID=%s, user_set=%v, pass_set=%v, saslMechanism=%s, tlsEnabled=%v",
+		k.BootstrapServer, k.GroupID, k.Username != "", k.Password != "", k.SASLMechanism, k.TLSEnabled)
+}
+
+func (c Config) validateKafka(kafkaCfg Kafka) error {
+	// Username and password are not required (if it's within the same VPC or connecting locally
+	if array.Empty([]string{kafkaCfg.GroupID, kafkaCfg.BootstrapServer}) {
+		return fmt.Errorf("kafka group or bootstrap server is empty")
+	}
+
+	if kafkaCfg.FlushOnRebalance && c.Mode == Snapshot {
+		return fmt.Errorf("flushOnRebalance is not supported in snapshot mode")
+	}
+
+	if kafkaCfg.StartFrom != "" && c.Mode == Snapshot {
+		return fmt.Errorf("startFrom is not supported in snapshot mode")
+	}
+
+	if err := kafkaCfg.Validate(); err != nil {
+		return fmt.Errorf("invalid kafka config: %w", err)
+	}
+
+	return nil
+}
+
+// KafkaConfigs returns every Kafka cluster that we are consuming from.
+func (c Config) KafkaConfigs() []*Kafka {
+	var kafkaConfigs []*Kafka
+	if c.Kafka != nil {
+		kafkaConfigs = append(kafkaConfigs, c.Kafka)
+	}
+
+	return append(kafkaConfigs, c.KafkaClusters...)
 }
 
 func (c Config) TopicConfigs() ([]*kafkalib.TopicConfig, error) {
 	switch c.Queue {
 	case constants.Kafka:
-		return c.Kafka.TopicConfigs, nil
+		var tcs []*kafkalib.TopicConfig
+		for _, kafkaCfg := range c.KafkaConfigs() {
+			tcs = append(tcs, kafkaCfg.TopicConfigs...)
+		}
+
+		return tcs, nil
 	case constants.PubSub:
 		return c.Pubsub.TopicConfigs, nil
+	case constants.NATS:
+		return c.NATS.TopicConfigs, nil
 	}
 
 	return nil, fmt.Errorf("unsupported queue: %v", c.Queue)
@@ -133,25 +426,101 @@ type Mode string
 const (
 	History     Mode = "history"
 	Replication Mode = "replication"
+	// Snapshot will consume the existing backlog, perform a final flush and exit.
+	// Data is written the same way as replication.
+	Snapshot Mode = "snapshot"
 )
 
 func (m Mode) String() string {
 	return string(m)
 }
 
+// TableMode returns the mode that is used to write into the destination table.
+func (m Mode) TableMode() Mode {
+	if m == Snapshot {
+		return Replication
+	}
+
+	return m
+}
+
+// FlushSizeAccounting is how the size of the buffered rows is calculated, which is compared against [Config.FlushSizeKb].
+type FlushSizeAccounting string
+
+const (
+	// FlushSizeAccountingApproximate will only count the size of the values, this is cheap but will under count rows with many columns.
+	FlushSizeAccountingApproximate FlushSizeAccounting = "approximate"
+	// FlushSizeAccountingSerialized will count the column names and the values as they would be serialized.
+	FlushSizeAccountingSerialized FlushSizeAccounting = "serialized"
+)
+
+func (f FlushSizeAccounting) Validate() error {
+	switch f {
+	case "", FlushSizeAccountingApproximate, FlushSizeAccountingSerialized:
+		return nil
+	}
+
+	return fmt.Errorf("invalid flush size accounting: %q", f)
+}
+
+// FanOutPolicy is used when there are additional outputs and decides which destinations have to succeed before we commit the offsets.
+type FanOutPolicy string
+
+const (
+	// FanOutPolicyAll will only commit the offsets once every destination has succeeded.
+	FanOutPolicyAll FanOutPolicy = "all"
+	// FanOutPolicyPrimary will commit the offsets once the primary destination has succeeded, failures from the additional outputs are logged.
+	FanOutPolicyPrimary FanOutPolicy = "primary"
+)
+
+func (f FanOutPolicy) Validate() error {
+	switch f {
+	case "", FanOutPolicyAll, FanOutPolicyPrimary:
+		return nil
+	}
+
+	return fmt.Errorf("invalid fan out policy: %q", f)
+}
+
 type Config struct {
 	Mode   Mode                      `yaml:"mode"`
 	Output constants.DestinationKind `yaml:"outputSource"`
 	Queue  constants.QueueKind       `yaml:"queue"`
 
+	// AdditionalOutputs is optional, each flush will also be written to these destinations.
+	// Every destination is written to even if another one fails, [FanOutPolicy] decides which ones have to succeed before we commit the offsets.
+	AdditionalOutputs []constants.DestinationKind `yaml:"additionalOutputSources,omitempty"`
+	FanOutPolicy      FanOutPolicy                `yaml:"fanOutPolicy,omitempty"`
+
+	// SnapshotIdleSeconds is only used in snapshot mode, we'll consider the backlog drained once we have not received a message for this long.
+	SnapshotIdleSeconds int `yaml:"snapshotIdleSeconds"`
+
 	// Flush rules
 	FlushIntervalSeconds int  `yaml:"flushIntervalSeconds"`
 	FlushSizeKb          int  `yaml:"flushSizeKb"`
 	BufferRows           uint `yaml:"bufferRows"`
+	// FlushSizeAccounting is optional and defaults to [FlushSizeAccountingApproximate].
+	FlushSizeAccounting FlushSizeAccounting `yaml:"flushSizeAccounting,omitempty"`
+	// IdleFlushSeconds is optional, if set we'll flush a table once it has not received a new row for this long (instead of waiting for the flush interval).
+	IdleFlushSeconds int `yaml:"idleFlushSeconds"`
+	// MaxMemoryMb is an optional cap on the estimated size of all the buffered tables, once exceeded we will stop consuming until a flush brings it back down.
+	MaxMemoryMb int `yaml:"maxMemoryMb"`
+	// MaxConcurrentFlushes and FlushesPerMinute are optional and apply across all tables, flushes will wait (instead of failing) until they're allowed to run.
+	// This is useful to stay within the destination's concurrency limits or query quotas.
+	MaxConcurrentFlushes int `yaml:"maxConcurrentFlushes,omitempty"`
+	FlushesPerMinute     int `yaml:"flushesPerMinute,omitempty"`
 
 	// Supported message queues
 	Pubsub *Pubsub `yaml:"pubsub,omitempty"`
 	Kafka  *Kafka  `yaml:"kafka,omitempty"`
+	NATS   *NATS   `yaml:"nats,omitempty"`
+
+	// KafkaClusters is optional and is used to consume from additional Kafka clusters, each cluster has its own consumer group.
+	// Topic names need to be unique across all the clusters.
+	KafkaClusters []*Kafka `yaml:"kafkaClusters,omitempty"`
+
+	// Used to decode messages that are serialized with a schema registry (e.g. Avro)
+	SchemaRegistry *SchemaRegistry `yaml:"schemaRegistry,omitempty"`
 
 	// Shared Transfer settings
 	SharedTransferConfig SharedTransferConfig `yaml:"sharedTransferConfig"`
@@ -160,34 +529,60 @@ type Config struct {
 	SharedDestinationConfig SharedDestinationConfig `yaml:"sharedDestinationConfig"`
 
 	// Supported destinations
-	MSSQL     *MSSQL      `yaml:"mssql,omitempty"`
-	BigQuery  *BigQuery   `yaml:"bigquery,omitempty"`
-	Snowflake *Snowflake  `yaml:"snowflake,omitempty"`
-	Redshift  *Redshift   `yaml:"redshift,omitempty"`
-	S3        *S3Settings `yaml:"s3,omitempty"`
+	MSSQL      *MSSQL      `yaml:"mssql,omitempty"`
+	BigQuery   *BigQuery   `yaml:"bigquery,omitempty"`
+	Snowflake  *Snowflake  `yaml:"snowflake,omitempty"`
+	Redshift   *Redshift   `yaml:"redshift,omitempty"`
+	S3         *S3Settings `yaml:"s3,omitempty"`
+	Databricks *Databricks `yaml:"databricks,omitempty"`
+	Iceberg    *Iceberg    `yaml:"iceberg,omitempty"`
+	DuckDB     *DuckDB     `yaml:"duckdb,omitempty"`
+	S3Parquet  *S3Parquet  `yaml:"s3Parquet,omitempty"`
 
 	Reporting struct {
 		Sentry *Sentry `yaml:"sentry"`
 	}
 
+	// Logging is optional, if not set we'll log text at the info level.
+	Logging *Logging `yaml:"logging,omitempty"`
+
+	// Debug is optional, if set we'll capture sample messages to help diagnose format issues.
+	Debug *Debug `yaml:"debug,omitempty"`
+
+	// IngestionLag is optional, if not set we'll compute the ingestion lag from the broker's timestamp.
+	IngestionLag *IngestionLag `yaml:"ingestionLag,omitempty"`
+
+	// DeliveryGuarantee is optional, this defaults to `at-least-once`.
+	// Setting this to `exactly-once` requires a destination that can write the Kafka offsets within the same transaction as the merge.
+	DeliveryGuarantee DeliveryGuarantee `yaml:"deliveryGuarantee,omitempty"`
+
+	// StatusTopic is optional, if set we'll publish an event to this topic after every successful flush.
+	StatusTopic *StatusTopic `yaml:"statusTopic,omitempty"`
+
+	// FlushRetry is optional, if not set a failing flush will be retried as a whole until it succeeds.
+	FlushRetry *FlushRetry `yaml:"flushRetry,omitempty"`
+
+	// HealthCheck is optional, if set we'll serve /healthz and /readyz on this port.
+	HealthCheck *HealthCheck `yaml:"healthCheck,omitempty"`
+
+	// SchemaCachePath is optional, if set we'll persist the destination table schemas to this file.
+	// This is so that on restart, we don't need to describe every table again.
+	SchemaCachePath string `yaml:"schemaCachePath,omitempty"`
+
 	Telemetry struct {
 		Metrics struct {
 			Provider constants.ExporterKind `yaml:"provider"`
 			Settings map[string]any         `yaml:"settings,omitempty"`
 		}
 	}
-}
 
-func readFileToConfig(pathToConfig string) (*Config, error) {
-	file, err := os.Open(pathToConfig)
-	if err != nil {
-		return nil, err
-	}
-
-	defer file.Close()
+	// defaultsApplied is set by readFileToConfig and is used to describe the plan.
+	defaultsApplied []string
+}
 
-	var bytes []byte
-	bytes, err = io.ReadAll(file)
+// readFileToConfig will read and merge the config files in order, see mergeConfigFiles for how overlays are applied.
+func readFileToConfig(pathsToConfig ...string) (*Config, error) {
+	bytes, err := mergeConfigFiles(pathsToConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -198,30 +593,182 @@ func readFileToConfig(pathToConfig string) (*Config, error) {
 		return nil, err
 	}
 
+	if err = resolveSecrets(context.Background(), &config); err != nil {
+		return nil, fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+
 	if config.Queue == "" {
 		// We default to Kafka for backwards compatibility
 		config.Queue = constants.Kafka
+		config.defaultsApplied = append(config.defaultsApplied, fmt.Sprintf("queue=%s", config.Queue))
 	}
 
 	if config.FlushIntervalSeconds == 0 {
 		config.FlushIntervalSeconds = defaultFlushTimeSeconds
+		config.defaultsApplied = append(config.defaultsApplied, fmt.Sprintf("flushIntervalSeconds=%d", config.FlushIntervalSeconds))
 	}
 
 	if config.BufferRows == 0 {
 		config.BufferRows = defaultBufferPoolSize
+		config.defaultsApplied = append(config.defaultsApplied, fmt.Sprintf("bufferRows=%d", config.BufferRows))
 	}
 
 	if config.FlushSizeKb == 0 {
 		config.FlushSizeKb = defaultFlushSizeKb
+		config.defaultsApplied = append(config.defaultsApplied, fmt.Sprintf("flushSizeKb=%d", config.FlushSizeKb))
 	}
 
 	if config.Mode == "" {
 		config.Mode = Replication
+		config.defaultsApplied = append(config.defaultsApplied, fmt.Sprintf("mode=%s", config.Mode))
+	}
+
+	if config.SnapshotIdleSeconds == 0 {
+		config.SnapshotIdleSeconds = defaultSnapshotIdleSeconds
+		config.defaultsApplied = append(config.defaultsApplied, fmt.Sprintf("snapshotIdleSeconds=%d", config.SnapshotIdleSeconds))
 	}
 
 	return &config, nil
 }
 
+// validateFlushOverrides will make sure that the topic's flush overrides are within the same bounds as the global settings.
+func validateFlushOverrides(tc kafkalib.TopicConfig) error {
+	if tc.FlushSizeKb < 0 {
+		return fmt.Errorf("flush size kb cannot be negative, current value: %v", tc.FlushSizeKb)
+	}
+
+	if tc.FlushIntervalSeconds != 0 && !numbers.BetweenEq(FlushIntervalSecondsMin, FlushIntervalSecondsMax, tc.FlushIntervalSeconds) {
+		return fmt.Errorf("flush interval is outside of our range, seconds: %d, expected start: %d, end: %d",
+			tc.FlushIntervalSeconds, FlushIntervalSecondsMin, FlushIntervalSecondsMax)
+	}
+
+	if tc.BufferRows != 0 && bufferPoolSizeMin > int(tc.BufferRows) {
+		return fmt.Errorf("buffer pool is too small, min value: %d, actual: %d", bufferPoolSizeMin, int(tc.BufferRows))
+	}
+
+	return nil
+}
+
+// MinFlushInterval returns the shortest flush interval across the global setting and the topic overrides.
+// This is how often the time-based flush needs to run so that every topic is flushed on its own interval.
+func (c Config) MinFlushInterval() time.Duration {
+	minSeconds := c.FlushIntervalSeconds
+	tcs, err := c.TopicConfigs()
+	if err != nil {
+		return time.Duration(minSeconds) * time.Second
+	}
+
+	for _, tc := range tcs {
+		if tc.FlushIntervalSeconds > 0 && tc.FlushIntervalSeconds < minSeconds {
+			minSeconds = tc.FlushIntervalSeconds
+		}
+	}
+
+	return time.Duration(minSeconds) * time.Second
+}
+
+// Outputs returns the primary destination followed by the additional outputs.
+func (c Config) Outputs() []constants.DestinationKind {
+	return append([]constants.DestinationKind{c.Output}, c.AdditionalOutputs...)
+}
+
+// ForOutput returns a copy of the config that will write to `output`, this is used to load each destination when fanning out.
+func (c Config) ForOutput(output constants.DestinationKind) Config {
+	c.Output = output
+	c.AdditionalOutputs = nil
+	return c
+}
+
+func (c Config) validateOutput() error {
+	if !constants.IsValidDestination(c.Output) {
+		return fmt.Errorf("invalid destination: %s", c.Output)
+	}
+
+	switch c.Output {
+	case constants.Snowflake:
+		if c.Snowflake != nil && c.Snowflake.OrphanTTLSeconds < 0 {
+			return fmt.Errorf("snowflake orphan ttl seconds cannot be negative, current value: %v", c.Snowflake.OrphanTTLSeconds)
+		}
+
+		if c.Snowflake != nil {
+			if err := c.Snowflake.DriverTLS.Validate(); err != nil {
+				return fmt.Errorf("invalid snowflake tls settings: %w", err)
+			}
+		}
+	case constants.MSSQL:
+		if err := c.ValidateMSSQL(); err != nil {
+			return err
+		}
+	case constants.Redshift:
+		if err := c.ValidateRedshift(); err != nil {
+			return err
+		}
+	case constants.Databricks:
+		if err := c.ValidateDatabricks(); err != nil {
+			return err
+		}
+	case constants.S3:
+		if err := c.S3.Validate(); err != nil {
+			return err
+		}
+	case constants.Iceberg:
+		if err := c.ValidateIceberg(); err != nil {
+			return err
+		}
+	case constants.DuckDB:
+		if err := c.ValidateDuckDB(); err != nil {
+			return err
+		}
+	case constants.S3Parquet:
+		if err := c.ValidateS3Parquet(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c Config) validateAdditionalOutputs() error {
+	if err := c.FanOutPolicy.Validate(); err != nil {
+		return err
+	}
+
+	seen := map[constants.DestinationKind]bool{c.Output: true}
+	for _, output := range c.AdditionalOutputs {
+		if seen[output] {
+			return fmt.Errorf("duplicate output: %q", output)
+		}
+
+		seen[output] = true
+		if err := c.ForOutput(output).validateOutput(); err != nil {
+			return fmt.Errorf("invalid additional output: %q: %w", output, err)
+		}
+	}
+
+	if len(c.AdditionalOutputs) == 0 {
+		return nil
+	}
+
+	tcs, err := c.TopicConfigs()
+	if err != nil {
+		return err
+	}
+
+	// Fanning out only covers merges and appends, these would otherwise be skipped or fail for every destination.
+	for _, tc := range tcs {
+		switch {
+		case len(tc.Assertions) > 0:
+			return fmt.Errorf("assertions are not supported with additional outputs, topic: %q", tc.Topic)
+		case tc.HandleTruncate:
+			return fmt.Errorf("handleTruncate is not supported with additional outputs, topic: %q", tc.Topic)
+		case tc.ApplySchemaChanges:
+			return fmt.Errorf("applySchemaChanges is not supported with additional outputs, topic: %q", tc.Topic)
+		}
+	}
+
+	return nil
+}
+
 func (c Config) ValidateRedshift() error {
 	if c.Output != constants.Redshift {
 		return fmt.Errorf("output is not redshift, output: %v", c.Output)
@@ -240,6 +787,27 @@ func (c Config) ValidateRedshift() error {
 		return fmt.Errorf("redshift invalid port")
 	}
 
+	if c.Redshift.Spectrum != nil && c.Redshift.Spectrum.ExternalSchema == "" {
+		return fmt.Errorf("redshift spectrum external schema is empty")
+	}
+
+	if c.Redshift.KMSKeyARN != "" && !strings.HasPrefix(c.Redshift.KMSKeyARN, "arn:") {
+		return fmt.Errorf("redshift kms key arn is invalid: %q", c.Redshift.KMSKeyARN)
+	}
+
+	// Redshift's MAXERROR cannot exceed 100,000.
+	if c.Redshift.MaxCopyErrors < 0 || c.Redshift.MaxCopyErrors > maxRedshiftCopyErrors {
+		return fmt.Errorf("redshift maxCopyErrors must be between 0 and %d, got: %d", maxRedshiftCopyErrors, c.Redshift.MaxCopyErrors)
+	}
+
+	if c.Redshift.StagingFileCount < 0 || c.Redshift.StagingFileCount > maxRedshiftStagingFileCount {
+		return fmt.Errorf("redshift stagingFileCount must be between 0 and %d, got: %d", maxRedshiftStagingFileCount, c.Redshift.StagingFileCount)
+	}
+
+	if err := c.Redshift.DriverTLS.Validate(); err != nil {
+		return fmt.Errorf("invalid redshift tls settings: %w", err)
+	}
+
 	return nil
 }
 
@@ -251,6 +819,10 @@ func (c Config) Validate() error {
 		return fmt.Errorf("flush size pool has to be a positive number, current value: %v", c.FlushSizeKb)
 	}
 
+	if err := c.FlushSizeAccounting.Validate(); err != nil {
+		return err
+	}
+
 	if !numbers.BetweenEq(FlushIntervalSecondsMin, FlushIntervalSecondsMax, c.FlushIntervalSeconds) {
 		return fmt.Errorf("flush interval is outside of our range, seconds: %d, expected start: %d, end: %d",
 			c.FlushIntervalSeconds, FlushIntervalSecondsMin, FlushIntervalSecondsMax)
@@ -260,33 +832,128 @@ func (c Config) Validate() error {
 		return fmt.Errorf("buffer pool is too small, min value: %d, actual: %d", bufferPoolSizeMin, int(c.BufferRows))
 	}
 
+	if c.IdleFlushSeconds < 0 {
+		return fmt.Errorf("idle flush seconds cannot be negative, current value: %v", c.IdleFlushSeconds)
+	}
+
+	if c.IdleFlushSeconds > 0 && c.IdleFlushSeconds >= c.FlushIntervalSeconds {
+		return fmt.Errorf("idle flush seconds has to be less than the flush interval, idle flush seconds: %d, flush interval seconds: %d",
+			c.IdleFlushSeconds, c.FlushIntervalSeconds)
+	}
+
+	if c.MaxMemoryMb < 0 {
+		return fmt.Errorf("max memory mb cannot be negative, current value: %v", c.MaxMemoryMb)
+	}
+
+	if c.MaxConcurrentFlushes < 0 {
+		return fmt.Errorf("max concurrent flushes cannot be negative, current value: %v", c.MaxConcurrentFlushes)
+	}
+
+	if c.FlushesPerMinute < 0 {
+		return fmt.Errorf("flushes per minute cannot be negative, current value: %v", c.FlushesPerMinute)
+	}
+
 	if !constants.IsValidDestination(c.Output) {
 		return fmt.Errorf("invalid destination: %s", c.Output)
 	}
 
-	switch c.Output {
-	case constants.MSSQL:
-		if err := c.ValidateMSSQL(); err != nil {
-			return err
-		}
-	case constants.Redshift:
-		if err := c.ValidateRedshift(); err != nil {
-			return err
-		}
-	case constants.S3:
-		if err := c.S3.Validate(); err != nil {
-			return err
+	if err := c.validateAdditionalOutputs(); err != nil {
+		return err
+	}
+
+	if c.Mode == Snapshot && c.SnapshotIdleSeconds <= 0 {
+		return fmt.Errorf("snapshot idle seconds has to be a positive number, current value: %v", c.SnapshotIdleSeconds)
+	}
+
+	if err := c.SharedDestinationConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid shared destination config: %w", err)
+	}
+
+	if c.SharedDestinationConfig.MaxValueLength < 0 {
+		return fmt.Errorf("max value length cannot be negative, current value: %v", c.SharedDestinationConfig.MaxValueLength)
+	}
+
+	if err := c.SharedTransferConfig.TypingSettings.InvalidDatePolicy.Validate(); err != nil {
+		return fmt.Errorf("invalid typing settings: %w", err)
+	}
+
+	if err := c.SharedTransferConfig.TypingSettings.InvalidJSONPolicy.Validate(); err != nil {
+		return fmt.Errorf("invalid typing settings: %w", err)
+	}
+
+	if err := c.SharedTransferConfig.TypingSettings.DecimalScaleOverflow.Validate(); err != nil {
+		return fmt.Errorf("invalid typing settings: %w", err)
+	}
+
+	if err := c.SharedTransferConfig.TypingSettings.UnknownTypePolicy.Validate(); err != nil {
+		return fmt.Errorf("invalid typing settings: %w", err)
+	}
+
+	if err := c.Logging.Validate(); err != nil {
+		return fmt.Errorf("invalid logging config: %w", err)
+	}
+
+	if err := c.Debug.Validate(); err != nil {
+		return fmt.Errorf("invalid debug config: %w", err)
+	}
+
+	if err := c.IngestionLag.Validate(); err != nil {
+		return fmt.Errorf("invalid ingestion lag config: %w", err)
+	}
+
+	if c.HealthCheck != nil {
+		if err := c.HealthCheck.Validate(); err != nil {
+			return fmt.Errorf("invalid health check config: %w", err)
 		}
 	}
 
+	if err := c.validateOutput(); err != nil {
+		return err
+	}
+
+	if err := c.validateDeliveryGuarantee(); err != nil {
+		return fmt.Errorf("invalid delivery guarantee config: %w", err)
+	}
+
+	if err := c.validateStatusTopic(); err != nil {
+		return fmt.Errorf("invalid status topic config: %w", err)
+	}
+
+	if err := c.validateFlushRetry(); err != nil {
+		return fmt.Errorf("invalid flush retry config: %w", err)
+	}
+
 	if c.Queue == constants.Kafka {
-		if c.Kafka == nil {
+		if c.Kafka == nil && len(c.KafkaClusters) == 0 {
 			return fmt.Errorf("kafka config is nil")
 		}
 
-		// Username and password are not required (if it's within the same VPC or connecting locally
-		if array.Empty([]string{c.Kafka.GroupID, c.Kafka.BootstrapServer}) {
-			return fmt.Errorf("kafka group or bootstrap server is empty")
+		if c.Kafka != nil {
+			if err := c.validateKafka(*c.Kafka); err != nil {
+				return err
+			}
+		}
+
+		for idx, kafkaCfg := range c.KafkaClusters {
+			if kafkaCfg == nil {
+				return fmt.Errorf("kafka cluster %d is nil", idx)
+			}
+
+			if err := c.validateKafka(*kafkaCfg); err != nil {
+				return fmt.Errorf("invalid kafka cluster %d: %w", idx, err)
+			}
+		}
+
+		// Consumers, offsets and parsers are all looked up by the topic name.
+		clusterForTopic := make(map[string]int)
+		for idx, kafkaCfg := range c.KafkaConfigs() {
+			for _, tc := range kafkaCfg.TopicConfigs {
+				if clusterIdx, isOk := clusterForTopic[tc.Topic]; isOk && clusterIdx != idx {
+					return fmt.Errorf("topic: %q is consumed from more than one kafka cluster", tc.Topic)
+				}
+
+				clusterForTopic[tc.Topic] = idx
+			}
 		}
 	}
 
@@ -295,8 +962,18 @@ func (c Config) Validate() error {
 			return fmt.Errorf("pubsub config is nil")
 		}
 
-		if array.Empty([]string{c.Pubsub.ProjectID, c.Pubsub.PathToCredentials}) {
-			return fmt.Errorf("pubsub projectID or pathToCredentials is empty")
+		if err := c.Pubsub.Validate(); err != nil {
+			return fmt.Errorf("invalid pubsub config: %w", err)
+		}
+	}
+
+	if c.Queue == constants.NATS {
+		if c.NATS == nil {
+			return fmt.Errorf("nats config is nil")
+		}
+
+		if err := c.NATS.Validate(); err != nil {
+			return fmt.Errorf("invalid nats config: %w", err)
 		}
 	}
 
@@ -314,6 +991,40 @@ func (c Config) Validate() error {
 			return fmt.Errorf("failed to validate topic config: %w", err)
 		}
 
+		for _, output := range c.Outputs() {
+			if err = topicConfig.ValidateTarget(output); err != nil {
+				return fmt.Errorf("failed to validate topic config: %w", err)
+			}
+		}
+
+		if err = validateFlushOverrides(*topicConfig); err != nil {
+			return fmt.Errorf("invalid flush overrides for topic: %s: %w", topicConfig.Topic, err)
+		}
+
+		for _, assertion := range topicConfig.Assertions {
+			if _, err = assertions.New(assertion); err != nil {
+				return fmt.Errorf("failed to validate assertion for topic: %s: %w", topicConfig.Topic, err)
+			}
+		}
+
+		if topicConfig.NullPrimaryKeyPolicy == kafkalib.NullPrimaryKeyPolicyRouteToDLQ && (c.FlushRetry == nil || c.FlushRetry.DeadLetterTopic == "") {
+			return fmt.Errorf("nullPrimaryKeyPolicy %q requires flushRetry.deadLetterTopic to be set, topic: %s", topicConfig.NullPrimaryKeyPolicy, topicConfig.String())
+		}
+
+		if topicConfig.BytesEncoding == kafkalib.BytesEncodingNative {
+			for _, output := range c.Outputs() {
+				if output != constants.Snowflake && output != constants.BigQuery {
+					return fmt.Errorf("bytesEncoding %q is only supported for Snowflake and BigQuery, topic: %s", topicConfig.BytesEncoding, topicConfig.String())
+				}
+			}
+		}
+
+		if requiresSchemaRegistry(topicConfig.CDCFormat) {
+			if err = c.SchemaRegistry.Validate(); err != nil {
+				return fmt.Errorf("failed to validate schema registry for topic: %s: %w", topicConfig.String(), err)
+			}
+		}
+
 		// History Mode Validation
 		if c.Mode == History {
 			if topicConfig.DropDeletedColumns {
@@ -10,7 +10,11 @@ import (
 	"time"
 
 	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/jsonutil"
 	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/ext"
 	"gopkg.in/yaml.v3"
 
 	"github.com/stretchr/testify/assert"
@@ -243,6 +247,7 @@ kafka:
 
 	assert.Equal(t, config.FlushIntervalSeconds, defaultFlushTimeSeconds)
 	assert.Equal(t, int(config.BufferRows), defaultBufferPoolSize)
+	assert.Equal(t, config.SnapshotIdleSeconds, defaultSnapshotIdleSeconds)
 
 	tcs, err := config.TopicConfigs()
 	assert.NoError(t, err)
@@ -261,6 +266,16 @@ kafka:
 		}
 	}
 
+	// Flushing on rebalance is not supported in snapshot mode
+	config.Kafka.BootstrapServer = "localhost:9092"
+	config.Kafka.FlushOnRebalance = true
+	config.Mode = Snapshot
+	assert.ErrorContains(t, config.Validate(), "flushOnRebalance is not supported in snapshot mode")
+
+	// Start from is not supported in snapshot mode either
+	config.Kafka.FlushOnRebalance = false
+	config.Kafka.StartFrom = "earliest"
+	assert.ErrorContains(t, config.Validate(), "startFrom is not supported in snapshot mode")
 }
 
 func TestReadSentryDSNAndTelemetry(t *testing.T) {
@@ -500,7 +515,7 @@ func TestConfig_Validate(t *testing.T) {
 
 	pubsub.TopicConfigs = []*kafkalib.TopicConfig{&tc}
 	pubsub.ProjectID = ""
-	assert.ErrorContains(t, cfg.Validate(), "pubsub projectID or pathToCredentials is empty")
+	assert.ErrorContains(t, cfg.Validate(), "pubsub projectID is empty")
 	pubsub.ProjectID = "foo"
 
 	assert.ErrorContains(t, cfg.Validate(), "failed to validate topic config")
@@ -508,6 +523,10 @@ func TestConfig_Validate(t *testing.T) {
 	pubsub.TopicConfigs[0].CDCKeyFormat = "org.apache.kafka.connect.json.JsonConverter"
 
 	pubsub.ProjectID = "project_id"
+	pubsub.PathToCredentials = ""
+	// pathToCredentials is optional since we'll fall back to ADC.
+	assert.Nil(t, cfg.Validate())
+
 	pubsub.PathToCredentials = "/tmp/abc"
 	assert.Nil(t, cfg.Validate())
 
@@ -541,6 +560,183 @@ func TestConfig_Validate(t *testing.T) {
 	cfg.FlushIntervalSeconds = 600
 	assert.Nil(t, cfg.Validate())
 
+	{
+		// The parts of the table name that are required depend on the destination.
+		tc.Schema = ""
+		cfg.Output = constants.BigQuery
+		assert.Nil(t, cfg.Validate())
+
+		cfg.Output = constants.Snowflake
+		assert.ErrorContains(t, cfg.Validate(), `database and schema are required for snowflake, topic: "topic"`)
+
+		tc.Schema = "schema"
+		tc.Database = ""
+		cfg.Output = constants.BigQuery
+		assert.ErrorContains(t, cfg.Validate(), `database or dataset is required for bigquery, topic: "topic"`)
+		tc.Dataset = "dataset"
+		assert.Nil(t, cfg.Validate())
+
+		tc.Database = "db"
+		tc.Dataset = ""
+	}
+
+	// Additional outputs are optional
+	cfg.AdditionalOutputs = []constants.DestinationKind{"foo"}
+	assert.ErrorContains(t, cfg.Validate(), `invalid additional output: "foo": invalid destination: foo`)
+	cfg.AdditionalOutputs = []constants.DestinationKind{cfg.Output}
+	assert.ErrorContains(t, cfg.Validate(), `duplicate output: "bigquery"`)
+	cfg.AdditionalOutputs = []constants.DestinationKind{constants.S3}
+	assert.ErrorContains(t, cfg.Validate(), `invalid additional output: "s3": s3 settings are nil`)
+	cfg.S3 = &S3Settings{Bucket: "foo", AwsSecretAccessKey: "foo", AwsAccessKeyID: "bar", OutputFormat: constants.ParquetFormat}
+	assert.Nil(t, cfg.Validate())
+	assert.Equal(t, []constants.DestinationKind{constants.BigQuery, constants.S3}, cfg.Outputs())
+	assert.Equal(t, constants.S3, cfg.ForOutput(constants.S3).Output)
+	assert.Empty(t, cfg.ForOutput(constants.S3).AdditionalOutputs)
+	cfg.FanOutPolicy = "any"
+	assert.ErrorContains(t, cfg.Validate(), `invalid fan out policy: "any"`)
+	cfg.FanOutPolicy = FanOutPolicyPrimary
+	assert.Nil(t, cfg.Validate())
+	{
+		// Only merges and appends are fanned out.
+		tc.HandleTruncate = true
+		assert.ErrorContains(t, cfg.Validate(), `handleTruncate is not supported with additional outputs, topic: "topic"`)
+		tc.HandleTruncate = false
+		tc.ApplySchemaChanges = true
+		assert.ErrorContains(t, cfg.Validate(), `applySchemaChanges is not supported with additional outputs, topic: "topic"`)
+		tc.ApplySchemaChanges = false
+		tc.Assertions = []kafkalib.AssertionConfig{{Name: "no_nulls"}}
+		assert.ErrorContains(t, cfg.Validate(), `assertions are not supported with additional outputs, topic: "topic"`)
+		tc.Assertions = nil
+	}
+	cfg.AdditionalOutputs = nil
+	cfg.FanOutPolicy = ""
+	cfg.S3 = nil
+
+	// Flush size accounting is optional
+	cfg.FlushSizeAccounting = "exact"
+	assert.ErrorContains(t, cfg.Validate(), `invalid flush size accounting: "exact"`)
+	cfg.FlushSizeAccounting = FlushSizeAccountingSerialized
+	assert.Nil(t, cfg.Validate())
+	cfg.FlushSizeAccounting = ""
+
+	// Debug is optional
+	cfg.Debug = &Debug{SampleMessages: -1}
+	assert.ErrorContains(t, cfg.Validate(), "invalid debug config: sample messages cannot be negative")
+	cfg.Debug = &Debug{SampleMessages: 10}
+	assert.Nil(t, cfg.Validate())
+	cfg.Debug = nil
+
+	// Flush overrides are optional, but have the same bounds as the global settings
+	pubsub.TopicConfigs[0].FlushIntervalSeconds = 1
+	assert.ErrorContains(t, cfg.Validate(), "invalid flush overrides for topic: topic: flush interval is outside of our range, seconds: 1")
+	pubsub.TopicConfigs[0].FlushIntervalSeconds = FlushIntervalSecondsMax + 1
+	assert.ErrorContains(t, cfg.Validate(), "flush interval is outside of our range")
+	pubsub.TopicConfigs[0].FlushIntervalSeconds = 0
+	pubsub.TopicConfigs[0].BufferRows = 2
+	assert.ErrorContains(t, cfg.Validate(), "invalid flush overrides for topic: topic: buffer pool is too small, min value: 5, actual: 2")
+	pubsub.TopicConfigs[0].BufferRows = 0
+	pubsub.TopicConfigs[0].FlushSizeKb = -1
+	assert.ErrorContains(t, cfg.Validate(), "invalid flush overrides for topic: topic: flush size kb cannot be negative, current value: -1")
+	pubsub.TopicConfigs[0].FlushSizeKb = 10
+	pubsub.TopicConfigs[0].FlushIntervalSeconds = 30
+	pubsub.TopicConfigs[0].BufferRows = 50
+	assert.Nil(t, cfg.Validate())
+	pubsub.TopicConfigs[0].FlushSizeKb = 0
+	pubsub.TopicConfigs[0].FlushIntervalSeconds = 0
+	pubsub.TopicConfigs[0].BufferRows = 0
+
+	// Invalid date policy is optional
+	cfg.SharedTransferConfig.TypingSettings.InvalidDatePolicy = "zero"
+	assert.ErrorContains(t, cfg.Validate(), `invalid typing settings: invalid date policy: "zero"`)
+	cfg.SharedTransferConfig.TypingSettings.InvalidDatePolicy = ext.InvalidDatePolicyNull
+	assert.Nil(t, cfg.Validate())
+	cfg.SharedTransferConfig.TypingSettings.InvalidDatePolicy = ""
+
+	// Invalid JSON policy is optional
+	cfg.SharedTransferConfig.TypingSettings.InvalidJSONPolicy = "null"
+	assert.ErrorContains(t, cfg.Validate(), `invalid typing settings: invalid JSON policy: "null"`)
+	cfg.SharedTransferConfig.TypingSettings.InvalidJSONPolicy = jsonutil.InvalidJSONPolicyString
+	assert.Nil(t, cfg.Validate())
+	cfg.SharedTransferConfig.TypingSettings.InvalidJSONPolicy = ""
+
+	// Decimal scale overflow is optional
+	cfg.SharedTransferConfig.TypingSettings.DecimalScaleOverflow = "ceil"
+	assert.ErrorContains(t, cfg.Validate(), `invalid typing settings: invalid decimal scale overflow: "ceil"`)
+	cfg.SharedTransferConfig.TypingSettings.DecimalScaleOverflow = decimal.ScaleOverflowRound
+	assert.Nil(t, cfg.Validate())
+	cfg.SharedTransferConfig.TypingSettings.DecimalScaleOverflow = ""
+
+	// Unknown type policy is optional
+	cfg.SharedTransferConfig.TypingSettings.UnknownTypePolicy = "skip"
+	assert.ErrorContains(t, cfg.Validate(), `invalid typing settings: invalid unknown type policy: "skip"`)
+	cfg.SharedTransferConfig.TypingSettings.UnknownTypePolicy = typing.UnknownTypePolicyJSON
+	assert.Nil(t, cfg.Validate())
+	cfg.SharedTransferConfig.TypingSettings.UnknownTypePolicy = ""
+
+	// Max value length is optional, but cannot be negative
+	cfg.SharedDestinationConfig.MaxValueLength = -1
+	assert.ErrorContains(t, cfg.Validate(), "max value length cannot be negative, current value: -1")
+	cfg.SharedDestinationConfig.MaxValueLength = 1024
+	assert.Nil(t, cfg.Validate())
+	cfg.SharedDestinationConfig.MaxValueLength = 0
+
+	// Snapshot mode requires a positive idle window
+	cfg.Mode = Snapshot
+	assert.ErrorContains(t, cfg.Validate(), "snapshot idle seconds has to be a positive number")
+	cfg.SnapshotIdleSeconds = 30
+	assert.Nil(t, cfg.Validate())
+	cfg.Mode = Replication
+
+	// Max memory is optional, but cannot be negative
+	cfg.MaxMemoryMb = -1
+	assert.ErrorContains(t, cfg.Validate(), "max memory mb cannot be negative")
+	cfg.MaxMemoryMb = 512
+	assert.Nil(t, cfg.Validate())
+	cfg.MaxMemoryMb = 0
+
+	// Flush limits are optional, but cannot be negative
+	cfg.MaxConcurrentFlushes = -1
+	assert.ErrorContains(t, cfg.Validate(), "max concurrent flushes cannot be negative")
+	cfg.MaxConcurrentFlushes = 4
+	cfg.FlushesPerMinute = -1
+	assert.ErrorContains(t, cfg.Validate(), "flushes per minute cannot be negative")
+	cfg.FlushesPerMinute = 60
+	assert.Nil(t, cfg.Validate())
+	cfg.MaxConcurrentFlushes = 0
+	cfg.FlushesPerMinute = 0
+
+	// Health check is optional
+	cfg.HealthCheck = &HealthCheck{}
+	assert.ErrorContains(t, cfg.Validate(), "invalid health check config: invalid port: 0")
+	cfg.HealthCheck.Port = 8080
+	assert.ErrorContains(t, cfg.Validate(), "invalid health check config: staleness threshold seconds has to be a positive number")
+	cfg.HealthCheck.StalenessThresholdSeconds = 300
+	assert.Nil(t, cfg.Validate())
+	cfg.HealthCheck = nil
+
+	// Idle flush is optional, but has to be shorter than the flush interval
+	cfg.IdleFlushSeconds = -1
+	assert.ErrorContains(t, cfg.Validate(), "idle flush seconds cannot be negative")
+	cfg.IdleFlushSeconds = cfg.FlushIntervalSeconds
+	assert.ErrorContains(t, cfg.Validate(), "idle flush seconds has to be less than the flush interval")
+	cfg.IdleFlushSeconds = cfg.FlushIntervalSeconds - 1
+	assert.Nil(t, cfg.Validate())
+	cfg.IdleFlushSeconds = 0
+
+	// Orphan cleanup TTL is optional, but cannot be negative
+	cfg.Output = constants.Snowflake
+	cfg.Snowflake = &Snowflake{OrphanTTLSeconds: -1}
+	assert.ErrorContains(t, cfg.Validate(), "snowflake orphan ttl seconds cannot be negative")
+	cfg.Snowflake.OrphanTTLSeconds = 3600
+	assert.Nil(t, cfg.Validate())
+
+	// The CA bundle and client certificate have to be readable
+	cfg.Snowflake.ClientCertPath = "testdata/client.pem"
+	assert.ErrorContains(t, cfg.Validate(), "invalid snowflake tls settings: clientCertPath and clientKeyPath must be set together")
+	cfg.Snowflake.ClientKeyPath = "testdata/client-key.pem"
+	assert.Nil(t, cfg.Validate())
+	cfg.Snowflake = nil
+
 	// Now that we have a valid output, let's test with S3.
 	cfg.Output = constants.S3
 	assert.ErrorContains(t, cfg.Validate(), "s3 settings are nil")
@@ -553,6 +749,21 @@ func TestConfig_Validate(t *testing.T) {
 
 	assert.Nil(t, cfg.Validate())
 
+	// Native bytes are only supported for Snowflake and BigQuery
+	pubsub.TopicConfigs[0].BytesEncoding = kafkalib.BytesEncodingNative
+	assert.ErrorContains(t, cfg.Validate(), `bytesEncoding "native" is only supported for Snowflake and BigQuery`)
+	pubsub.TopicConfigs[0].BytesEncoding = kafkalib.BytesEncodingHex
+	assert.Nil(t, cfg.Validate())
+	pubsub.TopicConfigs[0].BytesEncoding = ""
+
+	// Routing null primary keys to the dead letter topic requires one to be configured
+	pubsub.TopicConfigs[0].NullPrimaryKeyPolicy = kafkalib.NullPrimaryKeyPolicyRouteToDLQ
+	assert.ErrorContains(t, cfg.Validate(), `nullPrimaryKeyPolicy "route-to-dlq" requires flushRetry.deadLetterTopic to be set`)
+	cfg.FlushRetry = &FlushRetry{MaxBatchRetries: 3, DeadLetterTopic: "transfer_dlq"}
+	assert.Nil(t, cfg.Validate())
+	pubsub.TopicConfigs[0].NullPrimaryKeyPolicy = ""
+	cfg.FlushRetry = nil
+
 	// Now let's change to history mode and see.
 	cfg.Mode = History
 	pubsub.TopicConfigs[0].DropDeletedColumns = true
@@ -566,12 +777,26 @@ func TestConfig_Validate(t *testing.T) {
 	assert.NoError(t, cfg.Validate())
 	// End history mode
 
+	// Avro requires a schema registry
+	pubsub.TopicConfigs[0].CDCFormat = constants.DBZAvroFormat
+	assert.ErrorContains(t, cfg.Validate(), "schema registry config is nil")
+	cfg.SchemaRegistry = &SchemaRegistry{}
+	assert.ErrorContains(t, cfg.Validate(), "schema registry url is empty")
+	cfg.SchemaRegistry.URL = "http://localhost:8081"
+	assert.NoError(t, cfg.Validate())
+	pubsub.TopicConfigs[0].CDCFormat = constants.DBZPostgresAltFormat
+
 	for _, num := range []int{-500, -300, -5, 0} {
 		cfg.FlushSizeKb = num
 		assert.ErrorContains(t, cfg.Validate(), "flush size pool has to be a positive number")
 	}
 }
 
+func TestSnowflake_OrphanTTL(t *testing.T) {
+	assert.Equal(t, constants.TemporaryTableTTL, Snowflake{}.OrphanTTL())
+	assert.Equal(t, time.Hour, Snowflake{OrphanTTLSeconds: 3600}.OrphanTTL())
+}
+
 func TestCfg_KafkaBootstrapServers(t *testing.T) {
 	kafka := Kafka{
 		BootstrapServer: "localhost:9092",
@@ -588,3 +813,221 @@ func TestCfg_KafkaBootstrapServers(t *testing.T) {
 
 	assert.Equal(t, []string{"a:9092", "b:9093", "c:9094"}, brokers)
 }
+
+func TestMode_TableMode(t *testing.T) {
+	assert.Equal(t, Replication, Replication.TableMode())
+	assert.Equal(t, History, History.TableMode())
+	// Snapshot writes the same way as replication
+	assert.Equal(t, Replication, Snapshot.TableMode())
+}
+
+func TestKafka_Validate(t *testing.T) {
+	{
+		// No SASL mechanism
+		assert.NoError(t, Kafka{}.Validate())
+		assert.NoError(t, Kafka{TLSEnabled: true}.Validate())
+	}
+	{
+		// Invalid mechanism
+		assert.ErrorContains(t, Kafka{SASLMechanism: "foo"}.Validate(), `invalid sasl mechanism: "foo"`)
+	}
+	{
+		// Valid mechanisms
+		for _, mechanism := range []SASLMechanism{SASLMechanismPlain, SASLMechanismSCRAMSHA256, SASLMechanismSCRAMSHA512} {
+			assert.NoError(t, Kafka{SASLMechanism: mechanism, Username: "user", Password: "pass"}.Validate(), mechanism)
+			assert.ErrorContains(t, Kafka{SASLMechanism: mechanism, Password: "pass"}.Validate(), "username is required for sasl mechanism", mechanism)
+			assert.ErrorContains(t, Kafka{SASLMechanism: mechanism, Username: "user"}.Validate(), "password is required for sasl mechanism", mechanism)
+		}
+
+		// OAUTHBEARER does not need a username.
+		assert.NoError(t, Kafka{SASLMechanism: SASLMechanismOAuthBearer, Password: "token"}.Validate())
+	}
+	{
+		// Start from
+		for _, startFrom := range []string{"earliest", "latest", "2024-03-01T12:30:00Z", "0:100,1:250"} {
+			assert.NoError(t, Kafka{StartFrom: startFrom}.Validate(), startFrom)
+		}
+
+		assert.ErrorContains(t, Kafka{StartFrom: "yesterday"}.Validate(), `invalid start from: "yesterday" is not earliest`)
+	}
+	{
+		// Offset lag interval
+		assert.NoError(t, Kafka{OffsetLagIntervalSeconds: 30}.Validate())
+		assert.ErrorContains(t, Kafka{OffsetLagIntervalSeconds: -1}.Validate(), "offset lag interval seconds cannot be negative, current value: -1")
+	}
+	{
+		// AWS MSK IAM
+		assert.ErrorContains(t, Kafka{SASLMechanism: SASLMechanismPlain, Username: "user", Password: "pass", EnableAWSMSKIAM: true}.Validate(), "sasl mechanism cannot be used with AWS MSK IAM")
+	}
+}
+
+func TestConfig_MinFlushInterval(t *testing.T) {
+	cfg := Config{
+		Queue:                constants.Kafka,
+		FlushIntervalSeconds: 60,
+		Kafka: &Kafka{
+			TopicConfigs: []*kafkalib.TopicConfig{
+				{Topic: "default"},
+				{Topic: "quiet", FlushIntervalSeconds: 300},
+			},
+		},
+	}
+
+	// None of the topics have a shorter interval.
+	assert.Equal(t, time.Minute, cfg.MinFlushInterval())
+
+	cfg.Kafka.TopicConfigs = append(cfg.Kafka.TopicConfigs, &kafkalib.TopicConfig{Topic: "chatty", FlushIntervalSeconds: 10})
+	assert.Equal(t, 10*time.Second, cfg.MinFlushInterval())
+}
+
+func TestConfig_ValidateDeliveryGuarantee(t *testing.T) {
+	cfg := Config{
+		Queue:  constants.Kafka,
+		Mode:   Replication,
+		Output: constants.Snowflake,
+		Kafka: &Kafka{
+			TopicConfigs: []*kafkalib.TopicConfig{{Topic: "orders"}},
+		},
+	}
+
+	// Defaults to at-least-once
+	assert.NoError(t, cfg.validateDeliveryGuarantee())
+	cfg.DeliveryGuarantee = AtLeastOnce
+	assert.NoError(t, cfg.validateDeliveryGuarantee())
+	cfg.DeliveryGuarantee = "at-most-once"
+	assert.ErrorContains(t, cfg.validateDeliveryGuarantee(), `invalid delivery guarantee: "at-most-once"`)
+
+	cfg.DeliveryGuarantee = ExactlyOnce
+	assert.NoError(t, cfg.validateDeliveryGuarantee())
+	{
+		// Snapshot writes the same way as replication
+		cfg.Mode = Snapshot
+		assert.NoError(t, cfg.validateDeliveryGuarantee())
+		cfg.Mode = History
+		assert.ErrorContains(t, cfg.validateDeliveryGuarantee(), "exactly-once delivery is not supported in history mode")
+		cfg.Mode = Replication
+	}
+	{
+		// Unsupported destination
+		cfg.Output = constants.BigQuery
+		assert.ErrorContains(t, cfg.validateDeliveryGuarantee(), `exactly-once delivery is not supported for destination: "bigquery"`)
+		cfg.Output = constants.Snowflake
+	}
+	{
+		// Additional outputs
+		cfg.AdditionalOutputs = []constants.DestinationKind{constants.S3}
+		assert.ErrorContains(t, cfg.validateDeliveryGuarantee(), "exactly-once delivery is not supported with additional outputs")
+		cfg.AdditionalOutputs = nil
+	}
+	{
+		// Append only topics
+		cfg.Kafka.TopicConfigs[0].AppendOnly = true
+		assert.ErrorContains(t, cfg.validateDeliveryGuarantee(), `exactly-once delivery is not supported for append only topics, topic: "orders"`)
+		cfg.Kafka.TopicConfigs[0].AppendOnly = false
+	}
+	{
+		// Truncates
+		cfg.Kafka.TopicConfigs[0].HandleTruncate = true
+		assert.ErrorContains(t, cfg.validateDeliveryGuarantee(), `exactly-once delivery is not supported with handleTruncate, topic: "orders"`)
+		cfg.Kafka.TopicConfigs[0].HandleTruncate = false
+	}
+	{
+		// Non-Kafka queues
+		cfg.Queue = constants.PubSub
+		assert.ErrorContains(t, cfg.validateDeliveryGuarantee(), `exactly-once delivery is only supported for kafka, queue: "pubsub"`)
+	}
+}
+
+func TestConfig_Validate_KafkaClusters(t *testing.T) {
+	cfg := Config{
+		Queue:                constants.Kafka,
+		Mode:                 Replication,
+		Output:               constants.Snowflake,
+		FlushIntervalSeconds: 10,
+		BufferRows:           10,
+		FlushSizeKb:          10,
+		Kafka: &Kafka{
+			BootstrapServer: "us-east:9092",
+			GroupID:         "transfer-us",
+			TopicConfigs:    []*kafkalib.TopicConfig{{Database: "shop", Schema: "public", Topic: "us.shop.orders", CDCFormat: constants.DBZPostgresFormat, CDCKeyFormat: kafkalib.JSONKeyFmt}},
+		},
+		KafkaClusters: []*Kafka{
+			{
+				BootstrapServer: "eu-west:9092",
+				GroupID:         "transfer-eu",
+				TopicConfigs:    []*kafkalib.TopicConfig{{Database: "shop", Schema: "public", Topic: "eu.shop.orders", CDCFormat: constants.DBZPostgresFormat, CDCKeyFormat: kafkalib.JSONKeyFmt}},
+			},
+		},
+	}
+
+	for _, kafkaCfg := range cfg.KafkaConfigs() {
+		kafkaCfg.TopicConfigs[0].Load()
+	}
+
+	assert.NoError(t, cfg.Validate())
+	assert.Len(t, cfg.KafkaConfigs(), 2)
+
+	tcs, err := cfg.TopicConfigs()
+	assert.NoError(t, err)
+	assert.Len(t, tcs, 2)
+	{
+		// Each cluster is validated independently
+		cfg.KafkaClusters[0].GroupID = ""
+		assert.ErrorContains(t, cfg.Validate(), "invalid kafka cluster 0: kafka group or bootstrap server is empty")
+		cfg.KafkaClusters[0].GroupID = "transfer-eu"
+
+		cfg.KafkaClusters[0].SASLMechanism = "foo"
+		assert.ErrorContains(t, cfg.Validate(), `invalid kafka cluster 0: invalid kafka config: invalid sasl mechanism: "foo"`)
+		cfg.KafkaClusters[0].SASLMechanism = ""
+		assert.NoError(t, cfg.Validate())
+	}
+	{
+		// Topics cannot be consumed from more than one cluster
+		cfg.KafkaClusters[0].TopicConfigs[0].Topic = "us.shop.orders"
+		assert.ErrorContains(t, cfg.Validate(), `topic: "us.shop.orders" is consumed from more than one kafka cluster`)
+		cfg.KafkaClusters[0].TopicConfigs[0].Topic = "eu.shop.orders"
+	}
+	{
+		// The top level Kafka config is optional if clusters are specified
+		cfg.Kafka = nil
+		assert.NoError(t, cfg.Validate())
+
+		cfg.KafkaClusters = nil
+		assert.ErrorContains(t, cfg.Validate(), "kafka config is nil")
+	}
+}
+
+func TestConfig_ValidateStatusTopic(t *testing.T) {
+	cfg := Config{
+		Queue: constants.Kafka,
+		Kafka: &Kafka{
+			TopicConfigs: []*kafkalib.TopicConfig{{Topic: "orders"}},
+		},
+	}
+
+	// Optional
+	assert.NoError(t, cfg.validateStatusTopic())
+
+	cfg.StatusTopic = &StatusTopic{}
+	assert.ErrorContains(t, cfg.validateStatusTopic(), "topic is empty")
+
+	cfg.StatusTopic.Topic = "transfer_status"
+	assert.NoError(t, cfg.validateStatusTopic())
+	{
+		// Cannot publish to a topic that we are consuming from
+		cfg.StatusTopic.Topic = "orders"
+		assert.ErrorContains(t, cfg.validateStatusTopic(), `status topic "orders" cannot be one of the topics that we are consuming from`)
+		cfg.StatusTopic.Topic = "transfer_status"
+	}
+	{
+		// Pub/Sub
+		cfg.Queue = constants.PubSub
+		cfg.Pubsub = &Pubsub{TopicConfigs: []*kafkalib.TopicConfig{{Topic: "orders"}}}
+		assert.NoError(t, cfg.validateStatusTopic())
+	}
+	{
+		// NATS is not supported
+		cfg.Queue = constants.NATS
+		assert.ErrorContains(t, cfg.validateStatusTopic(), `status topic is not supported for queue: "nats"`)
+	}
+}
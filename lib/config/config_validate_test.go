@@ -1,6 +1,7 @@
 package config
 
 import (
+	"log/slog"
 	"testing"
 
 	"github.com/artie-labs/transfer/lib/config/constants"
@@ -130,6 +131,155 @@ func TestCfg_ValidateRedshift(t *testing.T) {
 				CredentialsClause: "creds",
 			},
 		},
+		{
+			name: "redshift spectrum without an external schema",
+			redshift: &Redshift{
+				Host:              "host",
+				Port:              123,
+				Database:          "db",
+				Username:          "user",
+				Password:          "pw",
+				Bucket:            "bucket",
+				CredentialsClause: "creds",
+				Spectrum:          &RedshiftSpectrum{},
+			},
+			expectedErr: "redshift spectrum external schema is empty",
+		},
+		{
+			name: "redshift spectrum",
+			redshift: &Redshift{
+				Host:              "host",
+				Port:              123,
+				Database:          "db",
+				Username:          "user",
+				Password:          "pw",
+				Bucket:            "bucket",
+				CredentialsClause: "creds",
+				Spectrum:          &RedshiftSpectrum{ExternalSchema: "spectrum"},
+			},
+		},
+		{
+			name: "redshift kms key arn is invalid",
+			redshift: &Redshift{
+				Host:              "host",
+				Port:              123,
+				Database:          "db",
+				Username:          "user",
+				Password:          "pw",
+				Bucket:            "bucket",
+				CredentialsClause: "creds",
+				KMSKeyARN:         "my-key",
+			},
+			expectedErr: `redshift kms key arn is invalid: "my-key"`,
+		},
+		{
+			name: "redshift kms key arn",
+			redshift: &Redshift{
+				Host:              "host",
+				Port:              123,
+				Database:          "db",
+				Username:          "user",
+				Password:          "pw",
+				Bucket:            "bucket",
+				CredentialsClause: "creds",
+				KMSKeyARN:         "arn:aws:kms:us-east-1:123456789012:key/abcd",
+			},
+		},
+		{
+			name: "redshift max copy errors is negative",
+			redshift: &Redshift{
+				Host:              "host",
+				Port:              123,
+				Database:          "db",
+				Username:          "user",
+				Password:          "pw",
+				Bucket:            "bucket",
+				CredentialsClause: "creds",
+				MaxCopyErrors:     -1,
+			},
+			expectedErr: "redshift maxCopyErrors must be between 0 and 100000, got: -1",
+		},
+		{
+			name: "redshift max copy errors is too large",
+			redshift: &Redshift{
+				Host:              "host",
+				Port:              123,
+				Database:          "db",
+				Username:          "user",
+				Password:          "pw",
+				Bucket:            "bucket",
+				CredentialsClause: "creds",
+				MaxCopyErrors:     100_001,
+			},
+			expectedErr: "redshift maxCopyErrors must be between 0 and 100000, got: 100001",
+		},
+		{
+			name: "redshift max copy errors",
+			redshift: &Redshift{
+				Host:              "host",
+				Port:              123,
+				Database:          "db",
+				Username:          "user",
+				Password:          "pw",
+				Bucket:            "bucket",
+				CredentialsClause: "creds",
+				MaxCopyErrors:     10,
+			},
+		},
+		{
+			name: "redshift staging file count is negative",
+			redshift: &Redshift{
+				Host:              "host",
+				Port:              123,
+				Database:          "db",
+				Username:          "user",
+				Password:          "pw",
+				Bucket:            "bucket",
+				CredentialsClause: "creds",
+				StagingFileCount:  -1,
+			},
+			expectedErr: "redshift stagingFileCount must be between 0 and 1024, got: -1",
+		},
+		{
+			name: "redshift staging file count",
+			redshift: &Redshift{
+				Host:              "host",
+				Port:              123,
+				Database:          "db",
+				Username:          "user",
+				Password:          "pw",
+				Bucket:            "bucket",
+				CredentialsClause: "creds",
+				StagingFileCount:  16,
+			},
+		},
+		{
+			name: "redshift ca bundle does not exist",
+			redshift: &Redshift{
+				Host:              "host",
+				Port:              123,
+				Database:          "db",
+				Username:          "user",
+				Password:          "pw",
+				Bucket:            "bucket",
+				CredentialsClause: "creds",
+				DriverTLS:         DriverTLS{CABundlePath: "testdata/does-not-exist.pem"},
+			},
+			expectedErr: "invalid redshift tls settings: failed to read ca bundle",
+		},
+		{
+			name: "redshift ca bundle and client certificate",
+			redshift: &Redshift{
+				Host:              "host",
+				Port:              123,
+				Database:          "db",
+				Username:          "user",
+				Password:          "pw",
+				Bucket:            "bucket",
+				CredentialsClause: "creds",
+				DriverTLS:         DriverTLS{CABundlePath: "testdata/ca.pem", ClientCertPath: "testdata/client.pem", ClientKeyPath: "testdata/client-key.pem"},
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -145,3 +295,157 @@ func TestCfg_ValidateRedshift(t *testing.T) {
 		}
 	}
 }
+
+func TestPubsub_Validate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		pubsub      Pubsub
+		expectedErr string
+	}{
+		{
+			name:        "missing project id",
+			pubsub:      Pubsub{},
+			expectedErr: "pubsub projectID is empty",
+		},
+		{
+			name:   "valid w/o credentials",
+			pubsub: Pubsub{ProjectID: "project"},
+		},
+		{
+			name:        "ack deadline too short",
+			pubsub:      Pubsub{ProjectID: "project", AckDeadlineSeconds: 5},
+			expectedErr: "pubsub ackDeadlineSeconds must be between 10 and 600, current value: 5",
+		},
+		{
+			name:        "ack deadline too long",
+			pubsub:      Pubsub{ProjectID: "project", AckDeadlineSeconds: 601},
+			expectedErr: "pubsub ackDeadlineSeconds must be between 10 and 600, current value: 601",
+		},
+		{
+			name:        "negative max outstanding messages",
+			pubsub:      Pubsub{ProjectID: "project", MaxOutstandingMessages: -1},
+			expectedErr: "pubsub maxOutstandingMessages cannot be negative, current value: -1",
+		},
+		{
+			name:        "negative max outstanding bytes",
+			pubsub:      Pubsub{ProjectID: "project", MaxOutstandingBytes: -1},
+			expectedErr: "pubsub maxOutstandingBytes cannot be negative, current value: -1",
+		},
+		{
+			name:        "negative flush jitter",
+			pubsub:      Pubsub{ProjectID: "project", FlushJitterBaseMs: -1},
+			expectedErr: "pubsub flushJitterBaseMs and flushJitterMaxMs cannot be negative",
+		},
+		{
+			name:        "flush jitter base is greater than max",
+			pubsub:      Pubsub{ProjectID: "project", FlushJitterBaseMs: 5000},
+			expectedErr: "pubsub flushJitterBaseMs: 5000 cannot be greater than flushJitterMaxMs: 3500",
+		},
+		{
+			name:   "valid",
+			pubsub: Pubsub{ProjectID: "project", AckDeadlineSeconds: 600, MaxOutstandingMessages: 1000, MaxOutstandingBytes: 1024, FlushJitterBaseMs: 1000, FlushJitterMaxMs: 10_000},
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.pubsub.Validate()
+		if testCase.expectedErr != "" {
+			assert.ErrorContains(t, err, testCase.expectedErr, testCase.name)
+		} else {
+			assert.NoError(t, err, testCase.name)
+		}
+	}
+}
+
+func TestNATS_Validate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		nats        NATS
+		expectedErr string
+	}{
+		{
+			name:        "missing servers",
+			nats:        NATS{Stream: "stream", DurableName: "transfer"},
+			expectedErr: "nats servers is empty",
+		},
+		{
+			name:        "missing stream",
+			nats:        NATS{Servers: []string{"nats://localhost:4222"}, DurableName: "transfer"},
+			expectedErr: "nats stream or durableName is empty",
+		},
+		{
+			name:        "missing durable name",
+			nats:        NATS{Servers: []string{"nats://localhost:4222"}, Stream: "stream"},
+			expectedErr: "nats stream or durableName is empty",
+		},
+		{
+			name:        "token and username",
+			nats:        NATS{Servers: []string{"nats://localhost:4222"}, Stream: "stream", DurableName: "transfer", Token: "token", Username: "user"},
+			expectedErr: "nats token cannot be used with username and password",
+		},
+		{
+			name:        "negative ack wait",
+			nats:        NATS{Servers: []string{"nats://localhost:4222"}, Stream: "stream", DurableName: "transfer", AckWaitSeconds: -1},
+			expectedErr: "nats ackWaitSeconds cannot be negative, current value: -1",
+		},
+		{
+			name:        "negative max ack pending",
+			nats:        NATS{Servers: []string{"nats://localhost:4222"}, Stream: "stream", DurableName: "transfer", MaxAckPending: -1},
+			expectedErr: "nats maxAckPending cannot be negative, current value: -1",
+		},
+		{
+			name: "valid",
+			nats: NATS{Servers: []string{"nats://localhost:4222"}, Stream: "stream", DurableName: "transfer", CredentialsFile: "/tmp/user.creds", AckWaitSeconds: 60, MaxAckPending: 1000},
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := testCase.nats.Validate()
+		if testCase.expectedErr != "" {
+			assert.ErrorContains(t, err, testCase.expectedErr, testCase.name)
+		} else {
+			assert.NoError(t, err, testCase.name)
+		}
+	}
+}
+
+func TestPubsub_FlushJitterBounds(t *testing.T) {
+	{
+		// Defaults
+		baseMs, maxMs := Pubsub{}.FlushJitterBounds()
+		assert.Equal(t, 500, baseMs)
+		assert.Equal(t, 3500, maxMs)
+	}
+	{
+		baseMs, maxMs := Pubsub{FlushJitterBaseMs: 1000, FlushJitterMaxMs: 10_000}.FlushJitterBounds()
+		assert.Equal(t, 1000, baseMs)
+		assert.Equal(t, 10_000, maxMs)
+	}
+}
+
+func TestLogging_Validate(t *testing.T) {
+	{
+		// Not set
+		var logging *Logging
+		assert.NoError(t, logging.Validate())
+		assert.Equal(t, LogFormatText, logging.GetFormat())
+		assert.Equal(t, slog.LevelInfo, logging.SlogLevel(false))
+		assert.Equal(t, slog.LevelDebug, logging.SlogLevel(true))
+	}
+	{
+		// Valid
+		logging := &Logging{Format: LogFormatJSON, Level: "WARN"}
+		assert.NoError(t, logging.Validate())
+		assert.Equal(t, LogFormatJSON, logging.GetFormat())
+		assert.Equal(t, slog.LevelWarn, logging.SlogLevel(false))
+		assert.Equal(t, slog.LevelDebug, logging.SlogLevel(true))
+	}
+	{
+		// Invalid format
+		assert.ErrorContains(t, (&Logging{Format: "xml"}).Validate(), `invalid log format: "xml"`)
+	}
+	{
+		// Invalid level
+		assert.ErrorContains(t, (&Logging{Level: "trace"}).Validate(), `invalid log level: "trace"`)
+	}
+}
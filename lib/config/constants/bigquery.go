@@ -0,0 +1,7 @@
+package constants
+
+// BigQueryReservedKeywords https://cloud.google.com/bigquery/docs/reference/standard-sql/lexical#reserved_keywords
+// These are the ANSI keywords that we escape for every destination, along with the ones that are only reserved by BigQuery.
+var BigQueryReservedKeywords = append(append([]string{}, ReservedKeywords...),
+	"default",
+)
@@ -1,6 +1,8 @@
 package constants
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -18,9 +20,22 @@ const (
 	DeletionConfidencePadding   = 4 * time.Hour
 	UpdateColumnMarker          = ArtiePrefix + "_updated_at"
 	DatabaseUpdatedColumnMarker = ArtiePrefix + "_db_updated_at"
+	DeletedAtColumnMarker       = ArtiePrefix + "_deleted_at"
 	OperationColumnMarker       = ArtiePrefix + "_operation"
 	ExceededValueMarker         = ArtiePrefix + "_exceeded_value"
 
+	// Metadata columns are opt-in and are extracted from Debezium's source block.
+	OpColumnMarker         = ArtiePrefix + "_op"
+	SourceTsMsColumnMarker = ArtiePrefix + "_source_ts_ms"
+	FileColumnMarker       = ArtiePrefix + "_file"
+	PosColumnMarker        = ArtiePrefix + "_pos"
+
+	// SnapshotColumnMarker is opt-in and is true for rows that were read by a snapshot, rather than from streaming changes.
+	SnapshotColumnMarker = ArtiePrefix + "_snapshot"
+
+	// OverflowColumnMarker is the JSON column that holds the columns that are over the topic's column limit.
+	OverflowColumnMarker = ArtiePrefix + "_overflow"
+
 	TemporaryTableTTL = 6 * time.Hour
 
 	// DBZPostgresFormat is the only supported CDC format right now
@@ -28,6 +43,12 @@ const (
 	DBZPostgresAltFormat = "debezium.postgres.wal2json"
 	DBZMongoFormat       = "debezium.mongodb"
 	DBZMySQLFormat       = "debezium.mysql"
+	DBZAvroFormat        = "debezium.avro"
+	DBZAvroAltFormat     = "avro"
+	DBZProtobufFormat    = "debezium.protobuf"
+	DBZProtobufAltFormat = "protobuf"
+	// AutoFormat will inspect each message to pick the parser, this is also used if the CDC format is not specified.
+	AutoFormat = "auto"
 )
 
 // ReservedKeywords is populated from: https://docs.snowflake.com/en/sql-reference/reserved-keywords
@@ -112,17 +133,22 @@ type QueueKind string
 const (
 	Kafka  QueueKind = "kafka"
 	PubSub QueueKind = "pubsub"
+	NATS   QueueKind = "nats"
 )
 
 type DestinationKind string
 
 const (
-	Snowflake DestinationKind = "snowflake"
-	Test      DestinationKind = "test"
-	BigQuery  DestinationKind = "bigquery"
-	Redshift  DestinationKind = "redshift"
-	S3        DestinationKind = "s3"
-	MSSQL     DestinationKind = "mssql"
+	Snowflake  DestinationKind = "snowflake"
+	Test       DestinationKind = "test"
+	BigQuery   DestinationKind = "bigquery"
+	Redshift   DestinationKind = "redshift"
+	S3         DestinationKind = "s3"
+	MSSQL      DestinationKind = "mssql"
+	Databricks DestinationKind = "databricks"
+	Iceberg    DestinationKind = "iceberg"
+	DuckDB     DestinationKind = "duckdb"
+	S3Parquet  DestinationKind = "s3parquet"
 )
 
 var ValidDestinations = []DestinationKind{
@@ -131,6 +157,10 @@ var ValidDestinations = []DestinationKind{
 	Redshift,
 	S3,
 	MSSQL,
+	Databricks,
+	Iceberg,
+	DuckDB,
+	S3Parquet,
 	Test,
 }
 
@@ -144,8 +174,57 @@ func IsValidDestination(destination DestinationKind) bool {
 	return false
 }
 
+// The maximum length of an identifier (in bytes) for each destination, we are using the smaller of the column and table limits.
+const (
+	// https://cloud.google.com/bigquery/docs/schemas#column_names
+	BigQueryMaxIdentifierLength = 300
+	// https://docs.snowflake.com/en/sql-reference/identifiers-syntax
+	SnowflakeMaxIdentifierLength = 255
+	// https://docs.aws.amazon.com/redshift/latest/dg/r_names.html
+	RedshiftMaxIdentifierLength = 127
+	// https://learn.microsoft.com/en-us/sql/relational-databases/databases/database-identifiers
+	MSSQLMaxIdentifierLength = 128
+	// https://docs.databricks.com/en/sql/language-manual/sql-ref-names.html
+	DatabricksMaxIdentifierLength = 255
+)
+
+// MaxIdentifierLength returns the maximum identifier length for `destKind`, zero means that there is no limit.
+func MaxIdentifierLength(destKind DestinationKind) int {
+	switch destKind {
+	case BigQuery:
+		return BigQueryMaxIdentifierLength
+	case Snowflake:
+		return SnowflakeMaxIdentifierLength
+	case Redshift:
+		return RedshiftMaxIdentifierLength
+	case MSSQL:
+		return MSSQLMaxIdentifierLength
+	case Databricks:
+		return DatabricksMaxIdentifierLength
+	default:
+		return 0
+	}
+}
+
 type ColComment struct {
 	Backfilled bool `json:"backfilled"`
+	// Description is propagated from the source column's comment.
+	Description string `json:"description,omitempty"`
+}
+
+// String returns the comment that we'll persist in the destination, this is parsed back when the table is described.
+func (c ColComment) String() string {
+	if c.Description == "" {
+		return fmt.Sprintf(`{"backfilled": %t}`, c.Backfilled)
+	}
+
+	description, err := json.Marshal(c.Description)
+	if err != nil {
+		// This should never happen since we are marshalling a string.
+		panic(err)
+	}
+
+	return fmt.Sprintf(`{"backfilled": %t, "description": %s}`, c.Backfilled, description)
 }
 
 type S3OutputFormat string
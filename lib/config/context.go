@@ -14,8 +14,8 @@ type Settings struct {
 // LoadSettings will take the flags and then parse, loadConfig is optional for testing purposes.
 func LoadSettings(args []string, loadConfig bool) (*Settings, error) {
 	var opts struct {
-		ConfigFilePath string `short:"c" long:"config" description:"path to the config file"`
-		Verbose        bool   `short:"v" long:"verbose" description:"debug logging" optional:"true"`
+		ConfigFilePaths []string `short:"c" long:"config" description:"path to the config file, this can be passed multiple times and later files will override earlier ones"`
+		Verbose         bool     `short:"v" long:"verbose" description:"debug logging" optional:"true"`
 	}
 
 	_, err := flags.ParseArgs(&opts, args)
@@ -28,7 +28,7 @@ func LoadSettings(args []string, loadConfig bool) (*Settings, error) {
 	}
 
 	if loadConfig {
-		config, err := readFileToConfig(opts.ConfigFilePath)
+		config, err := readFileToConfig(opts.ConfigFilePaths...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse config file: %w", err)
 		}
@@ -15,3 +15,13 @@ func TestParseArgs(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, settings.VerboseLogging, true)
 }
+
+func TestLoadSettings_MultipleConfigFiles(t *testing.T) {
+	base := writeConfigFile(t, "base.yaml", "outputSource: test\nflushSizeKb: 500\n"+validKafkaTopic)
+	overlay := writeConfigFile(t, "overlay.yaml", "flushSizeKb: 1000\n")
+
+	settings, err := LoadSettings([]string{"-c", base, "--config", overlay}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, settings.Config.FlushSizeKb)
+	assert.Len(t, settings.Config.Kafka.TopicConfigs, 2)
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/stringutil"
+)
+
+type Databricks struct {
+	// Host is the workspace hostname, e.g. dbc-a1b2345c-d6e7.cloud.databricks.com
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	HTTPPath string `yaml:"httpPath"`
+	// Token is a personal access token (or a service principal's token).
+	Token   string `yaml:"token"`
+	Catalog string `yaml:"catalog"`
+	// Schema is optional, it is only used as the initial namespace for the session. Tables will be written to the topic's schema.
+	Schema string `yaml:"schema,omitempty"`
+	// Volume is the fully qualified name of the Unity Catalog volume that will be used to stage files, e.g. catalog.schema.volume
+	Volume string `yaml:"volume"`
+	// StagingSchema is optional, if set the temporary tables will be created in this schema (within [Catalog]) instead of the topic's schema.
+	StagingSchema string `yaml:"stagingSchema,omitempty"`
+
+	ConnectionPool *ConnectionPool `yaml:"connectionPool,omitempty"`
+}
+
+func (d Databricks) DSN() string {
+	query := url.Values{}
+	query.Add("catalog", d.Catalog)
+	if d.Schema != "" {
+		query.Add("schema", d.Schema)
+	}
+
+	u := &url.URL{
+		Scheme:   "https",
+		User:     url.UserPassword("token", d.Token),
+		Host:     fmt.Sprintf("%s:%d", d.Host, d.Port),
+		Path:     d.HTTPPath,
+		RawQuery: query.Encode(),
+	}
+
+	return u.String()
+}
+
+// VolumePath returns the path of the staging volume, e.g. /Volumes/catalog/schema/volume
+func (d Databricks) VolumePath() string {
+	return "/Volumes/" + strings.ReplaceAll(d.Volume, ".", "/")
+}
+
+func (c Config) ValidateDatabricks() error {
+	if c.Output != constants.Databricks {
+		return fmt.Errorf("output is not databricks, output: %v", c.Output)
+	}
+
+	if c.Databricks == nil {
+		return fmt.Errorf("databricks config is nil")
+	}
+
+	if empty := stringutil.Empty(c.Databricks.Host, c.Databricks.HTTPPath, c.Databricks.Token, c.Databricks.Catalog, c.Databricks.Volume); empty {
+		return fmt.Errorf("one of databricks settings is empty (host, httpPath, token, catalog, volume)")
+	}
+
+	if c.Databricks.Port <= 0 {
+		return fmt.Errorf("invalid databricks port: %d", c.Databricks.Port)
+	}
+
+	if parts := strings.Split(c.Databricks.Volume, "."); len(parts) != 3 {
+		return fmt.Errorf("databricks volume must be in the form of catalog.schema.volume, volume: %q", c.Databricks.Volume)
+	}
+
+	return nil
+}
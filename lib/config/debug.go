@@ -0,0 +1,28 @@
+package config
+
+import "fmt"
+
+// Debug contains settings that are useful when diagnosing issues in production, e.g. what the messages on the wire look like.
+type Debug struct {
+	// SampleMessages is the number of raw messages per topic that we'll capture along with their parse result.
+	// Once a topic has reached this number, we'll stop capturing messages for it.
+	SampleMessages int `yaml:"sampleMessages"`
+	// SampleFilePath is optional, if set the sampled messages will be appended to this file as JSON lines instead of being logged.
+	SampleFilePath string `yaml:"sampleFilePath,omitempty"`
+}
+
+func (d *Debug) Validate() error {
+	if d == nil {
+		return nil
+	}
+
+	if d.SampleMessages < 0 {
+		return fmt.Errorf("sample messages cannot be negative, current value: %v", d.SampleMessages)
+	}
+
+	if d.SampleFilePath != "" && d.SampleMessages == 0 {
+		return fmt.Errorf("sample file path is set, but sample messages is not")
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebug_Validate(t *testing.T) {
+	{
+		// Nil
+		var d *Debug
+		assert.NoError(t, d.Validate())
+	}
+	{
+		// Valid
+		assert.NoError(t, (&Debug{SampleMessages: 5}).Validate())
+		assert.NoError(t, (&Debug{SampleMessages: 5, SampleFilePath: "/tmp/samples.jsonl"}).Validate())
+	}
+	{
+		// Invalid
+		assert.ErrorContains(t, (&Debug{SampleMessages: -1}).Validate(), "sample messages cannot be negative, current value: -1")
+		assert.ErrorContains(t, (&Debug{SampleFilePath: "/tmp/samples.jsonl"}).Validate(), "sample file path is set, but sample messages is not")
+	}
+}
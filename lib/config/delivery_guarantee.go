@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+type DeliveryGuarantee string
+
+const (
+	// AtLeastOnce will commit the Kafka offsets after the destination has been written to, a crash in between will replay the messages.
+	AtLeastOnce DeliveryGuarantee = "at-least-once"
+	// ExactlyOnce will write the Kafka offsets to the destination within the same transaction as the merge.
+	// Messages that are replayed after a crash will be skipped if their offsets have already been written.
+	ExactlyOnce DeliveryGuarantee = "exactly-once"
+)
+
+// exactlyOnceDestinations are the destinations that can write the offsets within the same transaction as the merge.
+var exactlyOnceDestinations = []constants.DestinationKind{constants.Snowflake}
+
+func (d DeliveryGuarantee) Validate() error {
+	switch d {
+	case "", AtLeastOnce, ExactlyOnce:
+		return nil
+	default:
+		return fmt.Errorf("invalid delivery guarantee: %q", d)
+	}
+}
+
+func (c Config) validateDeliveryGuarantee() error {
+	if err := c.DeliveryGuarantee.Validate(); err != nil {
+		return err
+	}
+
+	if c.DeliveryGuarantee != ExactlyOnce {
+		return nil
+	}
+
+	if c.Queue != constants.Kafka {
+		return fmt.Errorf("exactly-once delivery is only supported for kafka, queue: %q", c.Queue)
+	}
+
+	if c.Mode.TableMode() != Replication {
+		return fmt.Errorf("exactly-once delivery is not supported in %s mode", c.Mode)
+	}
+
+	if len(c.AdditionalOutputs) > 0 {
+		return fmt.Errorf("exactly-once delivery is not supported with additional outputs")
+	}
+
+	if !slices.Contains(exactlyOnceDestinations, c.Output) {
+		return fmt.Errorf("exactly-once delivery is not supported for destination: %q", c.Output)
+	}
+
+	tcs, err := c.TopicConfigs()
+	if err != nil {
+		return err
+	}
+
+	for _, tc := range tcs {
+		// Appends are not run within a transaction, so we cannot write the offsets atomically.
+		if tc.AppendOnly {
+			return fmt.Errorf("exactly-once delivery is not supported for append only topics, topic: %q", tc.Topic)
+		}
+
+		// Truncates are not written with an offset, so a replayed truncate would wipe out the rows that were merged after it.
+		if tc.HandleTruncate {
+			return fmt.Errorf("exactly-once delivery is not supported with handleTruncate, topic: %q", tc.Topic)
+		}
+	}
+
+	return nil
+}
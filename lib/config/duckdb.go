@@ -0,0 +1,29 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+type DuckDB struct {
+	// Path is the DuckDB database file, it will be created if it does not exist.
+	// DuckDB only allows one process to open the file for writing, so it cannot be shared with another Transfer process.
+	Path string `yaml:"path"`
+}
+
+func (c Config) ValidateDuckDB() error {
+	if c.Output != constants.DuckDB {
+		return fmt.Errorf("output is not duckdb, output: %v", c.Output)
+	}
+
+	if c.DuckDB == nil {
+		return fmt.Errorf("duckdb config is nil")
+	}
+
+	if c.DuckDB.Path == "" {
+		return fmt.Errorf("duckdb path is empty")
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+func TestValidateDuckDB(t *testing.T) {
+	var cfg Config
+	assert.ErrorContains(t, cfg.ValidateDuckDB(), "output is not duckdb")
+	cfg.Output = constants.DuckDB
+	assert.ErrorContains(t, cfg.ValidateDuckDB(), "duckdb config is nil")
+	cfg.DuckDB = &DuckDB{}
+	assert.ErrorContains(t, cfg.ValidateDuckDB(), "duckdb path is empty")
+	cfg.DuckDB.Path = "/var/lib/artie/transfer.duckdb"
+	assert.NoError(t, cfg.ValidateDuckDB())
+}
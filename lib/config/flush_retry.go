@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+// FlushRetry is used so that a single row that the destination keeps rejecting does not stall the whole table.
+type FlushRetry struct {
+	// MaxBatchRetries is how many times the whole batch has to fail before we start bisecting it.
+	MaxBatchRetries int `yaml:"maxBatchRetries"`
+	// Bisect will split the failing batch in half (and so on) until the failing rows have been isolated.
+	// The isolated rows are published to `DeadLetterTopic` and the rest of the batch is written.
+	Bisect bool `yaml:"bisect"`
+	// DeadLetterTopic is required if bisection is enabled or a topic's null primary key policy is `route-to-dlq`, this uses the same client that we are consuming with.
+	DeadLetterTopic string `yaml:"deadLetterTopic,omitempty"`
+}
+
+func (c Config) validateFlushRetry() error {
+	if c.FlushRetry == nil {
+		return nil
+	}
+
+	if c.FlushRetry.MaxBatchRetries <= 0 {
+		return fmt.Errorf("maxBatchRetries must be greater than 0, maxBatchRetries: %d", c.FlushRetry.MaxBatchRetries)
+	}
+
+	if c.FlushRetry.DeadLetterTopic == "" {
+		if c.FlushRetry.Bisect {
+			return fmt.Errorf("deadLetterTopic is required when bisect is enabled")
+		}
+
+		return nil
+	}
+
+	switch c.Queue {
+	case constants.Kafka, constants.PubSub:
+	default:
+		return fmt.Errorf("dead letter topic is not supported for queue: %q", c.Queue)
+	}
+
+	if c.StatusTopic != nil && c.StatusTopic.Topic == c.FlushRetry.DeadLetterTopic {
+		return fmt.Errorf("dead letter topic %q cannot be the same as the status topic", c.FlushRetry.DeadLetterTopic)
+	}
+
+	tcs, err := c.TopicConfigs()
+	if err != nil {
+		return err
+	}
+
+	for _, tc := range tcs {
+		if tc.Topic == c.FlushRetry.DeadLetterTopic {
+			return fmt.Errorf("dead letter topic %q cannot be one of the topics that we are consuming from", c.FlushRetry.DeadLetterTopic)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+)
+
+func TestConfig_ValidateFlushRetry(t *testing.T) {
+	cfg := Config{
+		Queue: constants.Kafka,
+		Kafka: &Kafka{
+			TopicConfigs: []*kafkalib.TopicConfig{{Topic: "orders"}},
+		},
+	}
+
+	// Optional
+	assert.NoError(t, cfg.validateFlushRetry())
+
+	cfg.FlushRetry = &FlushRetry{}
+	assert.ErrorContains(t, cfg.validateFlushRetry(), "maxBatchRetries must be greater than 0, maxBatchRetries: 0")
+
+	cfg.FlushRetry.MaxBatchRetries = 3
+	assert.NoError(t, cfg.validateFlushRetry())
+	{
+		// The dead letter topic is validated even if bisection is disabled, since null primary keys can be routed to it.
+		cfg.FlushRetry.DeadLetterTopic = "orders"
+		assert.ErrorContains(t, cfg.validateFlushRetry(), `dead letter topic "orders" cannot be one of the topics that we are consuming from`)
+		cfg.FlushRetry.DeadLetterTopic = ""
+	}
+
+	cfg.FlushRetry.Bisect = true
+	assert.ErrorContains(t, cfg.validateFlushRetry(), "deadLetterTopic is required when bisect is enabled")
+
+	cfg.FlushRetry.DeadLetterTopic = "transfer_dlq"
+	assert.NoError(t, cfg.validateFlushRetry())
+	{
+		// Cannot publish to a topic that we are consuming from
+		cfg.FlushRetry.DeadLetterTopic = "orders"
+		assert.ErrorContains(t, cfg.validateFlushRetry(), `dead letter topic "orders" cannot be one of the topics that we are consuming from`)
+		cfg.FlushRetry.DeadLetterTopic = "transfer_dlq"
+	}
+	{
+		// Cannot share the status topic
+		cfg.StatusTopic = &StatusTopic{Topic: "transfer_dlq"}
+		assert.ErrorContains(t, cfg.validateFlushRetry(), `dead letter topic "transfer_dlq" cannot be the same as the status topic`)
+		cfg.StatusTopic = nil
+	}
+	{
+		// Exactly-once
+		cfg.DeliveryGuarantee = ExactlyOnce
+		assert.NoError(t, cfg.validateFlushRetry())
+		cfg.DeliveryGuarantee = ""
+	}
+	{
+		// Pub/Sub
+		cfg.Queue = constants.PubSub
+		cfg.Pubsub = &Pubsub{TopicConfigs: []*kafkalib.TopicConfig{{Topic: "orders"}}}
+		assert.NoError(t, cfg.validateFlushRetry())
+	}
+	{
+		// NATS is not supported
+		cfg.Queue = constants.NATS
+		assert.ErrorContains(t, cfg.validateFlushRetry(), `dead letter topic is not supported for queue: "nats"`)
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/stringutil"
+)
+
+type IcebergCatalogType string
+
+const (
+	// IcebergCatalogREST is the Iceberg REST catalog, spec: https://github.com/apache/iceberg/blob/main/open-api/rest-catalog-open-api.yaml
+	IcebergCatalogREST IcebergCatalogType = "rest"
+)
+
+type Iceberg struct {
+	// CatalogType - only the REST catalog is supported today.
+	CatalogType IcebergCatalogType `yaml:"catalogType"`
+	// CatalogURI is the base URL of the catalog, e.g. https://catalog.example.com/api/catalog
+	CatalogURI string `yaml:"catalogURI"`
+	// Token is optional, if set it will be sent to the catalog as a bearer token.
+	Token string `yaml:"token,omitempty"`
+	// Warehouse is passed to the catalog when we fetch its config, depending on the catalog this is either the name or the location of the warehouse (e.g. s3://bucket/warehouse).
+	Warehouse string `yaml:"warehouse"`
+	// Namespace is where the tables will be created, nested namespaces are separated with a period (e.g. analytics.artie).
+	Namespace string `yaml:"namespace"`
+	// AwsAccessKeyID and AwsSecretAccessKey are optional and are used to write data and metadata files into the table's location.
+	// If they are not set, we'll use the default AWS credentials chain.
+	AwsAccessKeyID     string `yaml:"awsAccessKeyID,omitempty"`
+	AwsSecretAccessKey string `yaml:"awsSecretAccessKey,omitempty"`
+}
+
+// NamespaceLevels returns the namespace split into its levels, e.g. analytics.artie -> [analytics, artie]
+func (i Iceberg) NamespaceLevels() []string {
+	return strings.Split(i.Namespace, ".")
+}
+
+func (c Config) ValidateIceberg() error {
+	if c.Output != constants.Iceberg {
+		return fmt.Errorf("output is not iceberg, output: %v", c.Output)
+	}
+
+	if c.Iceberg == nil {
+		return fmt.Errorf("iceberg config is nil")
+	}
+
+	if c.Iceberg.CatalogType != IcebergCatalogREST {
+		return fmt.Errorf("unsupported iceberg catalog type: %q", c.Iceberg.CatalogType)
+	}
+
+	if empty := stringutil.Empty(c.Iceberg.CatalogURI, c.Iceberg.Warehouse, c.Iceberg.Namespace); empty {
+		return fmt.Errorf("one of iceberg settings is empty (catalogURI, warehouse, namespace)")
+	}
+
+	if _, err := url.ParseRequestURI(c.Iceberg.CatalogURI); err != nil {
+		return fmt.Errorf("invalid iceberg catalog uri: %q: %w", c.Iceberg.CatalogURI, err)
+	}
+
+	for _, level := range c.Iceberg.NamespaceLevels() {
+		if level == "" {
+			return fmt.Errorf("invalid iceberg namespace: %q", c.Iceberg.Namespace)
+		}
+	}
+
+	if (c.Iceberg.AwsAccessKeyID == "") != (c.Iceberg.AwsSecretAccessKey == "") {
+		return fmt.Errorf("iceberg awsAccessKeyID and awsSecretAccessKey must be set together")
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+func TestValidateIceberg(t *testing.T) {
+	var cfg Config
+	assert.ErrorContains(t, cfg.ValidateIceberg(), "output is not iceberg")
+	cfg.Output = constants.Iceberg
+	assert.ErrorContains(t, cfg.ValidateIceberg(), "iceberg config is nil")
+	cfg.Iceberg = &Iceberg{}
+	assert.ErrorContains(t, cfg.ValidateIceberg(), `unsupported iceberg catalog type: ""`)
+	cfg.Iceberg.CatalogType = "glue"
+	assert.ErrorContains(t, cfg.ValidateIceberg(), `unsupported iceberg catalog type: "glue"`)
+
+	cfg.Iceberg.CatalogType = IcebergCatalogREST
+	assert.ErrorContains(t, cfg.ValidateIceberg(), "one of iceberg settings is empty (catalogURI, warehouse, namespace)")
+
+	cfg.Iceberg.CatalogURI = "catalog"
+	cfg.Iceberg.Warehouse = "s3://bucket/warehouse"
+	cfg.Iceberg.Namespace = "analytics..artie"
+	assert.ErrorContains(t, cfg.ValidateIceberg(), `invalid iceberg catalog uri: "catalog"`)
+
+	cfg.Iceberg.CatalogURI = "https://catalog.example.com/api/catalog"
+	assert.ErrorContains(t, cfg.ValidateIceberg(), `invalid iceberg namespace: "analytics..artie"`)
+
+	cfg.Iceberg.Namespace = "analytics.artie"
+	assert.Equal(t, []string{"analytics", "artie"}, cfg.Iceberg.NamespaceLevels())
+	assert.NoError(t, cfg.ValidateIceberg())
+
+	cfg.Iceberg.AwsAccessKeyID = "key"
+	assert.ErrorContains(t, cfg.ValidateIceberg(), "iceberg awsAccessKeyID and awsSecretAccessKey must be set together")
+	cfg.Iceberg.AwsSecretAccessKey = "secret"
+	assert.NoError(t, cfg.ValidateIceberg())
+}
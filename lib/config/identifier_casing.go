@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IdentifierCasing controls how escaped identifiers (table and column names) are cased in the destination.
+type IdentifierCasing string
+
+const (
+	PreserveCasing IdentifierCasing = "preserve"
+	UpperCasing    IdentifierCasing = "upper"
+	LowerCasing    IdentifierCasing = "lower"
+)
+
+func (i IdentifierCasing) Validate() error {
+	switch i {
+	case PreserveCasing, UpperCasing, LowerCasing:
+		return nil
+	case "":
+		return fmt.Errorf("identifier casing cannot be empty")
+	default:
+		return fmt.Errorf("invalid identifier casing: %q", i)
+	}
+}
+
+// Apply will return `name` with the casing applied.
+func (i IdentifierCasing) Apply(name string) string {
+	switch i {
+	case UpperCasing:
+		return strings.ToUpper(name)
+	case LowerCasing:
+		return strings.ToLower(name)
+	default:
+		return name
+	}
+}
+
+// GetIdentifierCasing returns the identifier casing, if it's not specified, we'll fall back to `UppercaseEscapedNames`.
+func (s SharedDestinationConfig) GetIdentifierCasing() IdentifierCasing {
+	if s.IdentifierCasing != "" {
+		return s.IdentifierCasing
+	}
+
+	if s.UppercaseEscapedNames {
+		return UpperCasing
+	}
+
+	return PreserveCasing
+}
+
+func (s SharedDestinationConfig) Validate() error {
+	if s.IdentifierCasing == "" {
+		return nil
+	}
+
+	if err := s.IdentifierCasing.Validate(); err != nil {
+		return err
+	}
+
+	if s.UppercaseEscapedNames && s.IdentifierCasing != UpperCasing {
+		return fmt.Errorf("uppercaseEscapedNames cannot be used with identifierCasing: %q", s.IdentifierCasing)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentifierCasing_Validate(t *testing.T) {
+	for _, casing := range []IdentifierCasing{PreserveCasing, UpperCasing, LowerCasing} {
+		assert.NoError(t, casing.Validate(), casing)
+	}
+
+	assert.ErrorContains(t, IdentifierCasing("").Validate(), "identifier casing cannot be empty")
+	assert.ErrorContains(t, IdentifierCasing("camel").Validate(), `invalid identifier casing: "camel"`)
+}
+
+func TestIdentifierCasing_Apply(t *testing.T) {
+	assert.Equal(t, "Foo_Bar", PreserveCasing.Apply("Foo_Bar"))
+	assert.Equal(t, "FOO_BAR", UpperCasing.Apply("Foo_Bar"))
+	assert.Equal(t, "foo_bar", LowerCasing.Apply("Foo_Bar"))
+}
+
+func TestSharedDestinationConfig_GetIdentifierCasing(t *testing.T) {
+	// Defaults to preserve
+	assert.Equal(t, PreserveCasing, SharedDestinationConfig{}.GetIdentifierCasing())
+	// Backwards compatibility with uppercaseEscapedNames
+	assert.Equal(t, UpperCasing, SharedDestinationConfig{UppercaseEscapedNames: true}.GetIdentifierCasing())
+	// Identifier casing takes precedence
+	assert.Equal(t, LowerCasing, SharedDestinationConfig{IdentifierCasing: LowerCasing}.GetIdentifierCasing())
+	assert.Equal(t, UpperCasing, SharedDestinationConfig{UppercaseEscapedNames: true, IdentifierCasing: UpperCasing}.GetIdentifierCasing())
+}
+
+func TestSharedDestinationConfig_Validate(t *testing.T) {
+	assert.NoError(t, SharedDestinationConfig{}.Validate())
+	assert.NoError(t, SharedDestinationConfig{UppercaseEscapedNames: true}.Validate())
+	assert.NoError(t, SharedDestinationConfig{IdentifierCasing: LowerCasing}.Validate())
+	assert.NoError(t, SharedDestinationConfig{UppercaseEscapedNames: true, IdentifierCasing: UpperCasing}.Validate())
+
+	assert.ErrorContains(t, SharedDestinationConfig{IdentifierCasing: "camel"}.Validate(), `invalid identifier casing: "camel"`)
+	assert.ErrorContains(t, SharedDestinationConfig{UppercaseEscapedNames: true, IdentifierCasing: LowerCasing}.Validate(),
+		`uppercaseEscapedNames cannot be used with identifierCasing: "lower"`)
+}
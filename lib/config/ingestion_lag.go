@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type IngestionLagTimestamp string
+
+const (
+	// IngestionLagTimestampBroker will compute the lag from when the message was appended to the broker.
+	IngestionLagTimestampBroker IngestionLagTimestamp = "broker"
+	// IngestionLagTimestampSource will compute the lag from when the change happened in the source (e.g. Debezium's `source.ts_ms`).
+	IngestionLagTimestampSource IngestionLagTimestamp = "source"
+)
+
+// IngestionLag contains settings for how we compute the `ingestion.lag` metric.
+type IngestionLag struct {
+	// Timestamp is which timestamp we'll compute the lag from, this defaults to `broker`.
+	Timestamp IngestionLagTimestamp `yaml:"timestamp,omitempty"`
+	// ClockSkewToleranceSeconds is optional, it's how far ahead of us a timestamp can be before we consider it bogus and drop it.
+	// Negative lag is always reported as zero, if this is not set we'll never drop it.
+	ClockSkewToleranceSeconds int `yaml:"clockSkewToleranceSeconds,omitempty"`
+}
+
+func (i *IngestionLag) Validate() error {
+	if i == nil {
+		return nil
+	}
+
+	switch i.Timestamp {
+	case "", IngestionLagTimestampBroker, IngestionLagTimestampSource:
+	default:
+		return fmt.Errorf("invalid timestamp: %q", i.Timestamp)
+	}
+
+	if i.ClockSkewToleranceSeconds < 0 {
+		return fmt.Errorf("clock skew tolerance seconds cannot be negative, current value: %v", i.ClockSkewToleranceSeconds)
+	}
+
+	return nil
+}
+
+// UseSourceTimestamp returns true if the lag should be computed from the source's timestamp rather than the broker's.
+func (i *IngestionLag) UseSourceTimestamp() bool {
+	return i != nil && i.Timestamp == IngestionLagTimestampSource
+}
+
+// ClockSkewTolerance returns how far ahead of us a timestamp can be before we consider it bogus.
+func (i *IngestionLag) ClockSkewTolerance() time.Duration {
+	if i == nil {
+		return 0
+	}
+
+	return time.Duration(i.ClockSkewToleranceSeconds) * time.Second
+}
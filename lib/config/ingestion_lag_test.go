@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIngestionLag_Validate(t *testing.T) {
+	{
+		// Nil
+		var i *IngestionLag
+		assert.NoError(t, i.Validate())
+		assert.False(t, i.UseSourceTimestamp())
+		assert.Zero(t, i.ClockSkewTolerance())
+	}
+	{
+		// Valid
+		assert.NoError(t, (&IngestionLag{}).Validate())
+		assert.NoError(t, (&IngestionLag{Timestamp: IngestionLagTimestampBroker}).Validate())
+
+		i := &IngestionLag{Timestamp: IngestionLagTimestampSource, ClockSkewToleranceSeconds: 30}
+		assert.NoError(t, i.Validate())
+		assert.True(t, i.UseSourceTimestamp())
+		assert.Equal(t, 30*time.Second, i.ClockSkewTolerance())
+	}
+	{
+		// Invalid
+		assert.ErrorContains(t, (&IngestionLag{Timestamp: "consumer"}).Validate(), `invalid timestamp: "consumer"`)
+		assert.ErrorContains(t, (&IngestionLag{ClockSkewToleranceSeconds: -1}).Validate(), "clock skew tolerance seconds cannot be negative, current value: -1")
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+type LogFormat string
+
+const (
+	LogFormatText LogFormat = "text"
+	LogFormatJSON LogFormat = "json"
+)
+
+// Logging configures the shared logger, this is applied at startup so every log line has the same format and level.
+type Logging struct {
+	// Format is optional and defaults to text.
+	Format LogFormat `yaml:"format,omitempty"`
+	// Level is optional and defaults to info, valid levels are: debug, info, warn and error.
+	Level string `yaml:"level,omitempty"`
+}
+
+func (l *Logging) Validate() error {
+	if l == nil {
+		return nil
+	}
+
+	switch l.Format {
+	case "", LogFormatText, LogFormatJSON:
+	default:
+		return fmt.Errorf("invalid log format: %q, valid formats are: %q and %q", l.Format, LogFormatText, LogFormatJSON)
+	}
+
+	if _, err := parseLogLevel(l.Level); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetFormat returns the log format, text is the default.
+func (l *Logging) GetFormat() LogFormat {
+	if l == nil || l.Format == "" {
+		return LogFormatText
+	}
+
+	return l.Format
+}
+
+// SlogLevel returns the minimum level that should be logged, verbose logging (-v) will always log at debug.
+func (l *Logging) SlogLevel(verbose bool) slog.Level {
+	if verbose {
+		return slog.LevelDebug
+	}
+
+	if l == nil {
+		return slog.LevelInfo
+	}
+
+	level, err := parseLogLevel(l.Level)
+	if err != nil {
+		return slog.LevelInfo
+	}
+
+	return level
+}
+
+func parseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("invalid log level: %q, valid levels are: debug, info, warn and error", level)
+	}
+}
@@ -14,6 +14,10 @@ type MSSQL struct {
 	Username string `yaml:"username"`
 	Password string `yaml:"password"`
 	Database string `yaml:"database"`
+	// StagingSchema is optional, if set the temporary tables will be created in this schema instead of alongside the target table.
+	StagingSchema string `yaml:"stagingSchema,omitempty"`
+
+	ConnectionPool *ConnectionPool `yaml:"connectionPool,omitempty"`
 }
 
 func (m MSSQL) DSN() string {
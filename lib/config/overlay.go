@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	topicConfigsKey = "topicConfigs"
+	topicKey        = "topic"
+)
+
+func readYAMLFile(pathToConfig string) (map[string]any, error) {
+	file, err := os.Open(pathToConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	bytes, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var out map[string]any
+	if err = yaml.Unmarshal(bytes, &out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %q: %w", pathToConfig, err)
+	}
+
+	return out, nil
+}
+
+// mergeConfigFiles will read each file and deep merge them in order, so later files will override earlier ones.
+// The rules are:
+// 1. Maps are merged key by key.
+// 2. `topicConfigs` are merged by `topic`, a topic config with the same topic will be merged into the existing one and new topics will be appended.
+// 3. Any other value (including other lists) will be replaced.
+func mergeConfigFiles(paths []string) ([]byte, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no config files provided")
+	}
+
+	merged := make(map[string]any)
+	for _, path := range paths {
+		overlay, err := readYAMLFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		merged = mergeMaps(merged, overlay)
+	}
+
+	return yaml.Marshal(merged)
+}
+
+func mergeMaps(base, overlay map[string]any) map[string]any {
+	out := make(map[string]any, len(base))
+	for key, value := range base {
+		out[key] = value
+	}
+
+	for key, overlayValue := range overlay {
+		baseValue, isOk := out[key]
+		if !isOk {
+			out[key] = overlayValue
+			continue
+		}
+
+		switch castedOverlayValue := overlayValue.(type) {
+		case map[string]any:
+			if castedBaseValue, isOk := baseValue.(map[string]any); isOk {
+				out[key] = mergeMaps(castedBaseValue, castedOverlayValue)
+				continue
+			}
+		case []any:
+			if castedBaseValue, isOk := baseValue.([]any); isOk && key == topicConfigsKey {
+				out[key] = mergeTopicConfigs(castedBaseValue, castedOverlayValue)
+				continue
+			}
+		}
+
+		out[key] = overlayValue
+	}
+
+	return out
+}
+
+func mergeTopicConfigs(base, overlay []any) []any {
+	out := make([]any, len(base))
+	copy(out, base)
+
+	topicToIdx := make(map[any]int)
+	for idx, tc := range out {
+		if castedTc, isOk := tc.(map[string]any); isOk {
+			if topic, isOk := castedTc[topicKey]; isOk {
+				topicToIdx[topic] = idx
+			}
+		}
+	}
+
+	for _, tc := range overlay {
+		castedTc, isOk := tc.(map[string]any)
+		if !isOk {
+			out = append(out, tc)
+			continue
+		}
+
+		idx, isOk := topicToIdx[castedTc[topicKey]]
+		if !isOk {
+			topicToIdx[castedTc[topicKey]] = len(out)
+			out = append(out, tc)
+			continue
+		}
+
+		if castedBaseTc, isOk := out[idx].(map[string]any); isOk {
+			out[idx] = mergeMaps(castedBaseTc, castedTc)
+		} else {
+			out[idx] = tc
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+func writeConfigFile(t *testing.T, name string, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	return path
+}
+
+func TestReadFileToConfig_Overlays(t *testing.T) {
+	base := writeConfigFile(t, "base.yaml", `
+outputSource: snowflake
+flushIntervalSeconds: 15
+flushSizeKb: 500
+bufferRows: 10
+`+validKafkaTopic)
+
+	overlay := writeConfigFile(t, "overlay.yaml", `
+flushSizeKb: 1000
+kafka:
+ groupID: overlay-group
+ topicConfigs:
+  - { topic: orders, softDelete: true }
+  - { db: customer, tableName: payments, schema: public, topic: payments, cdcFormat: debezium.mongodb, cdcKeyFormat: org.apache.kafka.connect.json.JsonConverter}
+`)
+
+	{
+		// Base only
+		config, err := readFileToConfig(base)
+		assert.NoError(t, err)
+		assert.Equal(t, 500, config.FlushSizeKb)
+		assert.Len(t, config.Kafka.TopicConfigs, 2)
+	}
+	{
+		config, err := readFileToConfig(base, overlay)
+		assert.NoError(t, err)
+		// Overridden
+		assert.Equal(t, 1000, config.FlushSizeKb)
+		assert.Equal(t, "overlay-group", config.Kafka.GroupID)
+		// Preserved from the base
+		assert.Equal(t, constants.Snowflake, config.Output)
+		assert.Equal(t, 15, config.FlushIntervalSeconds)
+		assert.Equal(t, "kafka:9092", config.Kafka.BootstrapServer)
+		assert.Equal(t, "foo", config.Kafka.Username)
+
+		// Topic configs are merged by topic and new topics are appended.
+		assert.Len(t, config.Kafka.TopicConfigs, 3)
+		assert.Equal(t, "orders", config.Kafka.TopicConfigs[0].Topic)
+		assert.Equal(t, "customer", config.Kafka.TopicConfigs[0].Database)
+		assert.True(t, config.Kafka.TopicConfigs[0].SoftDelete)
+		assert.Equal(t, "customer", config.Kafka.TopicConfigs[1].Topic)
+		assert.False(t, config.Kafka.TopicConfigs[1].SoftDelete)
+		assert.Equal(t, "payments", config.Kafka.TopicConfigs[2].Topic)
+		assert.Equal(t, "payments", config.Kafka.TopicConfigs[2].TableName)
+
+		for _, tc := range config.Kafka.TopicConfigs {
+			tc.Load()
+		}
+
+		assert.NoError(t, config.Validate())
+	}
+	{
+		// Order matters, later files win.
+		config, err := readFileToConfig(overlay, base)
+		assert.NoError(t, err)
+		assert.Equal(t, 500, config.FlushSizeKb)
+		assert.Equal(t, "123", config.Kafka.GroupID)
+	}
+}
+
+func TestMergeMaps(t *testing.T) {
+	base := map[string]any{
+		"a":    1,
+		"list": []any{1, 2},
+		"nested": map[string]any{
+			"b": 2,
+			"c": 3,
+		},
+	}
+
+	overlay := map[string]any{
+		"list": []any{3},
+		"nested": map[string]any{
+			"c": 4,
+			"d": 5,
+		},
+		"e": "foo",
+	}
+
+	assert.Equal(t, map[string]any{
+		"a":    1,
+		"list": []any{3},
+		"nested": map[string]any{
+			"b": 2,
+			"c": 4,
+			"d": 5,
+		},
+		"e": "foo",
+	}, mergeMaps(base, overlay))
+
+	// Base should not have been modified.
+	assert.Equal(t, 3, base["nested"].(map[string]any)["c"])
+}
+
+func TestReadFileToConfig_NoFiles(t *testing.T) {
+	_, err := readFileToConfig()
+	assert.ErrorContains(t, err, "no config files provided")
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+// ValidateCommand is the subcommand used to validate the config files without starting Transfer.
+const ValidateCommand = "validate"
+
+// RunValidate will load and validate the config files the same way as on start up and then write the resolved plan to `w`.
+// `args` are the same flags that Transfer is started with (e.g. -c config.yaml).
+func RunValidate(args []string, w io.Writer) error {
+	settings, err := LoadSettings(args, true)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, settings.Config.Plan())
+	return err
+}
+
+// Plan returns a human-readable description of what Transfer will do with this config.
+func (c Config) Plan() string {
+	var sb strings.Builder
+	sb.WriteString("Config is valid\n")
+	fmt.Fprintf(&sb, "Mode: %s\n", c.Mode)
+	fmt.Fprintf(&sb, "Destination: %s\n", c.Output)
+	if len(c.AdditionalOutputs) > 0 {
+		fanOutPolicy := c.FanOutPolicy
+		if fanOutPolicy == "" {
+			fanOutPolicy = FanOutPolicyAll
+		}
+
+		fmt.Fprintf(&sb, "Additional destinations: %v (fan out policy: %s)\n", c.AdditionalOutputs, fanOutPolicy)
+	}
+
+	fmt.Fprintf(&sb, "Queue: %s", c.Queue)
+	switch {
+	case c.Queue == constants.Kafka && len(c.KafkaConfigs()) > 0:
+		var clusters []string
+		for _, kafkaCfg := range c.KafkaConfigs() {
+			clusters = append(clusters, kafkaCfg.String())
+		}
+
+		fmt.Fprintf(&sb, " (%s)", strings.Join(clusters, "; "))
+	case c.Pubsub != nil && c.Queue == constants.PubSub:
+		fmt.Fprintf(&sb, " (%s)", c.Pubsub.String())
+	case c.NATS != nil && c.Queue == constants.NATS:
+		fmt.Fprintf(&sb, " (%s)", c.NATS.String())
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("Topics:\n")
+	tcs, err := c.TopicConfigs()
+	if err != nil {
+		fmt.Fprintf(&sb, "  %v\n", err)
+	}
+
+	for _, tc := range tcs {
+		fmt.Fprintf(&sb, "  - %s\n", tc.String())
+	}
+
+	sb.WriteString("Flush rules:\n")
+	fmt.Fprintf(&sb, "  flushIntervalSeconds=%d\n", c.FlushIntervalSeconds)
+	fmt.Fprintf(&sb, "  flushSizeKb=%d\n", c.FlushSizeKb)
+	if c.FlushSizeAccounting != "" {
+		fmt.Fprintf(&sb, "  flushSizeAccounting=%s\n", c.FlushSizeAccounting)
+	}
+
+	fmt.Fprintf(&sb, "  bufferRows=%d\n", c.BufferRows)
+	if c.IdleFlushSeconds > 0 {
+		fmt.Fprintf(&sb, "  idleFlushSeconds=%d\n", c.IdleFlushSeconds)
+	}
+
+	if c.MaxMemoryMb > 0 {
+		fmt.Fprintf(&sb, "  maxMemoryMb=%d\n", c.MaxMemoryMb)
+	}
+
+	if c.MaxConcurrentFlushes > 0 {
+		fmt.Fprintf(&sb, "  maxConcurrentFlushes=%d\n", c.MaxConcurrentFlushes)
+	}
+
+	if c.FlushesPerMinute > 0 {
+		fmt.Fprintf(&sb, "  flushesPerMinute=%d\n", c.FlushesPerMinute)
+	}
+
+	if c.Mode == Snapshot {
+		fmt.Fprintf(&sb, "  snapshotIdleSeconds=%d\n", c.SnapshotIdleSeconds)
+	}
+
+	sb.WriteString("Defaults applied:\n")
+	if len(c.defaultsApplied) == 0 {
+		sb.WriteString("  none\n")
+	}
+
+	for _, defaultApplied := range c.defaultsApplied {
+		fmt.Fprintf(&sb, "  %s\n", defaultApplied)
+	}
+
+	return sb.String()
+}
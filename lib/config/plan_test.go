@@ -0,0 +1,39 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunValidate(t *testing.T) {
+	{
+		// Valid
+		path := writeConfigFile(t, "config.yaml", "outputSource: test\nflushSizeKb: 500\nidleFlushSeconds: 5\n"+validKafkaTopic)
+		var out bytes.Buffer
+		assert.NoError(t, RunValidate([]string{"-c", path}, &out))
+
+		plan := out.String()
+		assert.Contains(t, plan, "Config is valid")
+		assert.Contains(t, plan, "Mode: replication\n")
+		assert.Contains(t, plan, "Destination: test\n")
+		assert.Contains(t, plan, "Queue: kafka (bootstrapServer=kafka:9092, groupID=123, user_set=true, pass_set=true, saslMechanism=, tlsEnabled=false)\n")
+		assert.Contains(t, plan, "topic=orders")
+		assert.Contains(t, plan, "topic=customer")
+		assert.Contains(t, plan, "  flushSizeKb=500\n")
+		assert.Contains(t, plan, "  idleFlushSeconds=5\n")
+		assert.NotContains(t, plan, "maxMemoryMb")
+		assert.Contains(t, plan, "Defaults applied:\n  queue=kafka\n  flushIntervalSeconds=10\n  bufferRows=30000\n  mode=replication\n  snapshotIdleSeconds=30\n")
+		// Credentials should not be printed.
+		assert.NotContains(t, plan, "bar")
+	}
+	{
+		// Invalid
+		path := writeConfigFile(t, "config.yaml", "outputSource: test\nflushSizeKb: -5\n"+validKafkaTopic)
+		var out bytes.Buffer
+		err := RunValidate([]string{"-c", path}, &out)
+		assert.ErrorContains(t, err, "failed to validate config: flush size pool has to be a positive number")
+		assert.Empty(t, out.String())
+	}
+}
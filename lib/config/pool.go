@@ -0,0 +1,38 @@
+package config
+
+import "time"
+
+const (
+	defaultHealthCheckIntervalSeconds = 60
+)
+
+// ConnectionPool configures the connection pool that is shared by a destination.
+type ConnectionPool struct {
+	MaxOpenConnections     int `yaml:"maxOpenConnections"`
+	MaxIdleConnections     int `yaml:"maxIdleConnections"`
+	ConnMaxLifetimeSeconds int `yaml:"connMaxLifetimeSeconds"`
+	// HealthCheckIntervalSeconds is how often we'll ping the pool before using it, if the ping fails we'll reconnect.
+	// Set this to a negative number to disable health checks.
+	HealthCheckIntervalSeconds int `yaml:"healthCheckIntervalSeconds"`
+}
+
+func (c *ConnectionPool) ConnMaxLifetime() time.Duration {
+	if c == nil || c.ConnMaxLifetimeSeconds <= 0 {
+		return 0
+	}
+
+	return time.Duration(c.ConnMaxLifetimeSeconds) * time.Second
+}
+
+// HealthCheckInterval returns 0 if health checks are disabled.
+func (c *ConnectionPool) HealthCheckInterval() time.Duration {
+	if c == nil || c.HealthCheckIntervalSeconds == 0 {
+		return defaultHealthCheckIntervalSeconds * time.Second
+	}
+
+	if c.HealthCheckIntervalSeconds < 0 {
+		return 0
+	}
+
+	return time.Duration(c.HealthCheckIntervalSeconds) * time.Second
+}
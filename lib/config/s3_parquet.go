@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+type S3PartitionGranularity string
+
+const (
+	S3PartitionHour  S3PartitionGranularity = "hour"
+	S3PartitionDay   S3PartitionGranularity = "day"
+	S3PartitionMonth S3PartitionGranularity = "month"
+	S3PartitionYear  S3PartitionGranularity = "year"
+)
+
+type S3PartitionBy struct {
+	// Column is the column whose value decides which partition a row is written to.
+	Column string `yaml:"column"`
+	// Granularity is optional and is used to derive a date from a timestamp column, e.g. `day` will write `dt=2024-01-02/`.
+	// If this is not set, the column's value will be used as-is.
+	Granularity S3PartitionGranularity `yaml:"granularity,omitempty"`
+	// Name is optional and is the partition key in the path, this defaults to the column name.
+	Name string `yaml:"name,omitempty"`
+}
+
+// PartitionName returns the key that will be used in the Hive-style path, e.g. `name=value/`.
+func (s S3PartitionBy) PartitionName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+
+	return s.Column
+}
+
+// S3Parquet writes Parquet files to S3 using Hive-style partitions, so the tables can be queried with Athena or Spark.
+// Files are written to: s3://bucket/prefix/db.schema.table/partition=value/file.parquet.gz
+type S3Parquet struct {
+	Bucket string `yaml:"bucket"`
+	// Prefix is optional.
+	Prefix      string        `yaml:"prefix,omitempty"`
+	PartitionBy S3PartitionBy `yaml:"partitionBy"`
+	// AwsAccessKeyID and AwsSecretAccessKey are optional, if they are not set we'll use the default AWS credentials chain.
+	AwsAccessKeyID     string `yaml:"awsAccessKeyID,omitempty"`
+	AwsSecretAccessKey string `yaml:"awsSecretAccessKey,omitempty"`
+}
+
+func (c Config) ValidateS3Parquet() error {
+	if c.Output != constants.S3Parquet {
+		return fmt.Errorf("output is not s3parquet, output: %v", c.Output)
+	}
+
+	if c.S3Parquet == nil {
+		return fmt.Errorf("s3parquet config is nil")
+	}
+
+	if c.S3Parquet.Bucket == "" {
+		return fmt.Errorf("s3parquet bucket is empty")
+	}
+
+	if c.S3Parquet.PartitionBy.Column == "" {
+		return fmt.Errorf("s3parquet partitionBy column is empty")
+	}
+
+	if strings.ContainsAny(c.S3Parquet.PartitionBy.PartitionName(), "/=") {
+		return fmt.Errorf("invalid s3parquet partition name: %q", c.S3Parquet.PartitionBy.PartitionName())
+	}
+
+	switch c.S3Parquet.PartitionBy.Granularity {
+	case "", S3PartitionHour, S3PartitionDay, S3PartitionMonth, S3PartitionYear:
+	default:
+		return fmt.Errorf("invalid s3parquet partition granularity: %q", c.S3Parquet.PartitionBy.Granularity)
+	}
+
+	if (c.S3Parquet.AwsAccessKeyID == "") != (c.S3Parquet.AwsSecretAccessKey == "") {
+		return fmt.Errorf("s3parquet awsAccessKeyID and awsSecretAccessKey must be set together")
+	}
+
+	return nil
+}
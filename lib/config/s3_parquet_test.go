@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+func TestValidateS3Parquet(t *testing.T) {
+	var cfg Config
+	assert.ErrorContains(t, cfg.ValidateS3Parquet(), "output is not s3parquet")
+	cfg.Output = constants.S3Parquet
+	assert.ErrorContains(t, cfg.ValidateS3Parquet(), "s3parquet config is nil")
+	cfg.S3Parquet = &S3Parquet{}
+	assert.ErrorContains(t, cfg.ValidateS3Parquet(), "s3parquet bucket is empty")
+	cfg.S3Parquet.Bucket = "bucket"
+	assert.ErrorContains(t, cfg.ValidateS3Parquet(), "s3parquet partitionBy column is empty")
+	cfg.S3Parquet.PartitionBy.Column = "created_at"
+	assert.NoError(t, cfg.ValidateS3Parquet())
+	{
+		// Granularity
+		cfg.S3Parquet.PartitionBy.Granularity = "week"
+		assert.ErrorContains(t, cfg.ValidateS3Parquet(), `invalid s3parquet partition granularity: "week"`)
+		cfg.S3Parquet.PartitionBy.Granularity = S3PartitionDay
+		assert.NoError(t, cfg.ValidateS3Parquet())
+	}
+	{
+		// Partition name
+		assert.Equal(t, "created_at", cfg.S3Parquet.PartitionBy.PartitionName())
+		cfg.S3Parquet.PartitionBy.Name = "dt=1"
+		assert.ErrorContains(t, cfg.ValidateS3Parquet(), `invalid s3parquet partition name: "dt=1"`)
+		cfg.S3Parquet.PartitionBy.Name = "dt"
+		assert.NoError(t, cfg.ValidateS3Parquet())
+		assert.Equal(t, "dt", cfg.S3Parquet.PartitionBy.PartitionName())
+	}
+	{
+		// Credentials must be set together
+		cfg.S3Parquet.AwsAccessKeyID = "foo"
+		assert.ErrorContains(t, cfg.ValidateS3Parquet(), "s3parquet awsAccessKeyID and awsSecretAccessKey must be set together")
+		cfg.S3Parquet.AwsSecretAccessKey = "bar"
+		assert.NoError(t, cfg.ValidateS3Parquet())
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+// SchemaRegistry is the Confluent Schema Registry that is used to decode Avro encoded messages.
+type SchemaRegistry struct {
+	URL      string `yaml:"url"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+func (s *SchemaRegistry) Validate() error {
+	if s == nil {
+		return fmt.Errorf("schema registry config is nil")
+	}
+
+	if s.URL == "" {
+		return fmt.Errorf("schema registry url is empty")
+	}
+
+	return nil
+}
+
+// requiresSchemaRegistry returns true if the CDC format can only be decoded with a schema registry.
+func requiresSchemaRegistry(cdcFormat string) bool {
+	return cdcFormat == constants.DBZAvroFormat || cdcFormat == constants.DBZAvroAltFormat
+}
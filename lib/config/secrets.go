@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/artie-labs/transfer/lib/secrets"
+)
+
+// secretsProviders is a variable so that tests can swap in their own providers.
+var secretsProviders = secrets.DefaultProviders
+
+// resolveSecrets will replace every string in the config that is a `secret://` reference with the value fetched from its provider.
+func resolveSecrets(ctx context.Context, cfg *Config) error {
+	return resolveSecretsInValue(ctx, secrets.NewResolver(secretsProviders()), reflect.ValueOf(cfg).Elem())
+}
+
+func resolveSecretsInValue(ctx context.Context, resolver *secrets.Resolver, value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+
+		if value.Kind() == reflect.Interface {
+			// Values inside an interface cannot be set, so we'll resolve a copy and then swap it in.
+			elem := reflect.New(value.Elem().Type()).Elem()
+			elem.Set(value.Elem())
+			if err := resolveSecretsInValue(ctx, resolver, elem); err != nil {
+				return err
+			}
+
+			if value.CanSet() {
+				value.Set(elem)
+			}
+
+			return nil
+		}
+
+		return resolveSecretsInValue(ctx, resolver, value.Elem())
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			if !value.Type().Field(i).IsExported() {
+				continue
+			}
+
+			if err := resolveSecretsInValue(ctx, resolver, value.Field(i)); err != nil {
+				return fmt.Errorf("%s: %w", value.Type().Field(i).Name, err)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := resolveSecretsInValue(ctx, resolver, value.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := value.MapRange()
+		for iter.Next() {
+			// Map values cannot be set in place either.
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			if err := resolveSecretsInValue(ctx, resolver, elem); err != nil {
+				return fmt.Errorf("%v: %w", iter.Key(), err)
+			}
+
+			value.SetMapIndex(iter.Key(), elem)
+		}
+	case reflect.String:
+		resolved, err := resolver.Resolve(ctx, value.String())
+		if err != nil {
+			return err
+		}
+
+		if value.CanSet() {
+			value.SetString(resolved)
+		}
+	}
+
+	return nil
+}
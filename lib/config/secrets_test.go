@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/secrets"
+)
+
+type mockSecretsProvider struct {
+	secrets map[string]string
+}
+
+func (m mockSecretsProvider) GetSecret(_ context.Context, name string, key string) (string, error) {
+	secret, isOk := m.secrets[name+"#"+key]
+	if !isOk {
+		return "", fmt.Errorf("secret not found")
+	}
+
+	return secret, nil
+}
+
+func TestReadFileToConfig_Secrets(t *testing.T) {
+	secretsProviders = func() map[string]secrets.Provider {
+		return map[string]secrets.Provider{
+			secrets.AWSSecretsManager: mockSecretsProvider{secrets: map[string]string{
+				"snowflake#password": "hunter2",
+				"kafka#username":     "transfer",
+			}},
+		}
+	}
+	defer func() {
+		secretsProviders = secrets.DefaultProviders
+	}()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+outputSource: snowflake
+kafka:
+  bootstrapServer: localhost:9092
+  groupID: transfer
+  username: secret://aws-sm/kafka#username
+  password: plain-password
+  topicConfigs:
+    - { db: shop, schema: public, topic: orders, cdcFormat: debezium.postgres }
+snowflake:
+  account: account
+  username: snowflake
+  password: secret://aws-sm/snowflake#password
+  warehouse: warehouse
+telemetry:
+  metrics:
+    settings:
+      apiKey: secret://aws-sm/kafka#username
+`), 0o600))
+
+	{
+		cfg, err := readFileToConfig(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "hunter2", cfg.Snowflake.Password)
+		assert.Equal(t, "snowflake", cfg.Snowflake.Username)
+		assert.Equal(t, "transfer", cfg.Kafka.Username)
+		assert.Equal(t, "plain-password", cfg.Kafka.Password)
+		assert.Equal(t, "transfer", cfg.Telemetry.Metrics.Settings["apiKey"])
+	}
+	{
+		// Unknown secret
+		overlay := filepath.Join(t.TempDir(), "overlay.yaml")
+		assert.NoError(t, os.WriteFile(overlay, []byte(`
+snowflake:
+  password: secret://aws-sm/snowflake#other
+`), 0o600))
+
+		_, err := readFileToConfig(path, overlay)
+		assert.ErrorContains(t, err, "failed to resolve secrets: Snowflake: Password: failed to resolve secret://aws-sm/snowflake#other: secret not found")
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+// StatusTopic is used to publish an event after every successful flush, this is useful for downstream orchestration (e.g. kicking off a dbt run).
+// The events are published with the same client that we are consuming with, for Kafka this is the first configured cluster.
+type StatusTopic struct {
+	Topic string `yaml:"topic"`
+}
+
+func (c Config) validateStatusTopic() error {
+	if c.StatusTopic == nil {
+		return nil
+	}
+
+	if c.StatusTopic.Topic == "" {
+		return fmt.Errorf("topic is empty")
+	}
+
+	switch c.Queue {
+	case constants.Kafka, constants.PubSub:
+	default:
+		return fmt.Errorf("status topic is not supported for queue: %q", c.Queue)
+	}
+
+	tcs, err := c.TopicConfigs()
+	if err != nil {
+		return err
+	}
+
+	for _, tc := range tcs {
+		// Otherwise, we would be consuming our own status events.
+		if tc.Topic == c.StatusTopic.Topic {
+			return fmt.Errorf("status topic %q cannot be one of the topics that we are consuming from", c.StatusTopic.Topic)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// DriverTLS is used to connect to a warehouse through a proxy that re-signs traffic or to a warehouse that uses a private CA.
+type DriverTLS struct {
+	// CABundlePath is optional, if set the PEM encoded certificates in this file will be trusted instead of the system's root CAs.
+	CABundlePath string `yaml:"caBundlePath,omitempty"`
+	// ClientCertPath and ClientKeyPath are optional, if set the client certificate will be presented to the server (mTLS).
+	// They must be set together.
+	ClientCertPath string `yaml:"clientCertPath,omitempty"`
+	ClientKeyPath  string `yaml:"clientKeyPath,omitempty"`
+}
+
+func (d DriverTLS) IsSet() bool {
+	return d.CABundlePath != "" || d.ClientCertPath != "" || d.ClientKeyPath != ""
+}
+
+func (d DriverTLS) Validate() error {
+	_, err := d.TLSConfig()
+	return err
+}
+
+// TLSConfig will load the CA bundle and client certificate from disk, this returns nil if none of the settings are set.
+func (d DriverTLS) TLSConfig() (*tls.Config, error) {
+	if !d.IsSet() {
+		return nil, nil
+	}
+
+	if (d.ClientCertPath == "") != (d.ClientKeyPath == "") {
+		return nil, fmt.Errorf("clientCertPath and clientKeyPath must be set together")
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if d.CABundlePath != "" {
+		bundle, err := os.ReadFile(d.CABundlePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca bundle: %w", err)
+		}
+
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("ca bundle %q does not contain any valid PEM certificates", d.CABundlePath)
+		}
+
+		tlsConfig.RootCAs = certPool
+	}
+
+	if d.ClientCertPath != "" {
+		cert, err := tls.LoadX509KeyPair(d.ClientCertPath, d.ClientKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
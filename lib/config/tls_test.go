@@ -0,0 +1,71 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDriverTLS_TLSConfig(t *testing.T) {
+	{
+		// Not set
+		tlsConfig, err := DriverTLS{}.TLSConfig()
+		assert.NoError(t, err)
+		assert.Nil(t, tlsConfig)
+	}
+	{
+		// CA bundle
+		tlsConfig, err := DriverTLS{CABundlePath: "testdata/ca.pem"}.TLSConfig()
+		assert.NoError(t, err)
+		assert.NotNil(t, tlsConfig.RootCAs)
+		assert.Empty(t, tlsConfig.Certificates)
+	}
+	{
+		// CA bundle and client certificate
+		tlsConfig, err := DriverTLS{CABundlePath: "testdata/ca.pem", ClientCertPath: "testdata/client.pem", ClientKeyPath: "testdata/client-key.pem"}.TLSConfig()
+		assert.NoError(t, err)
+		assert.NotNil(t, tlsConfig.RootCAs)
+		assert.Len(t, tlsConfig.Certificates, 1)
+	}
+	{
+		// Client certificate only, the system's root CAs will be used.
+		tlsConfig, err := DriverTLS{ClientCertPath: "testdata/client.pem", ClientKeyPath: "testdata/client-key.pem"}.TLSConfig()
+		assert.NoError(t, err)
+		assert.Nil(t, tlsConfig.RootCAs)
+		assert.Len(t, tlsConfig.Certificates, 1)
+	}
+	{
+		// Client certificate without a key
+		_, err := DriverTLS{ClientCertPath: "testdata/client.pem"}.TLSConfig()
+		assert.ErrorContains(t, err, "clientCertPath and clientKeyPath must be set together")
+	}
+	{
+		// CA bundle does not exist
+		_, err := DriverTLS{CABundlePath: "testdata/does-not-exist.pem"}.TLSConfig()
+		assert.ErrorContains(t, err, "failed to read ca bundle")
+	}
+	{
+		// CA bundle does not contain any certificates
+		_, err := DriverTLS{CABundlePath: "testdata/client-key.pem"}.TLSConfig()
+		assert.ErrorContains(t, err, `ca bundle "testdata/client-key.pem" does not contain any valid PEM certificates`)
+	}
+	{
+		// Client key does not match the certificate
+		_, err := DriverTLS{ClientCertPath: "testdata/client.pem", ClientKeyPath: "testdata/ca.pem"}.TLSConfig()
+		assert.ErrorContains(t, err, "failed to load client certificate")
+	}
+}
+
+func TestDriverTLS_YAML(t *testing.T) {
+	var snowflake Snowflake
+	assert.NoError(t, yaml.Unmarshal([]byte(`
+account: account
+caBundlePath: /etc/ssl/ca.pem
+clientCertPath: /etc/ssl/client.pem
+clientKeyPath: /etc/ssl/client-key.pem
+`), &snowflake))
+
+	assert.Equal(t, "account", snowflake.AccountID)
+	assert.Equal(t, DriverTLS{CABundlePath: "/etc/ssl/ca.pem", ClientCertPath: "/etc/ssl/client.pem", ClientKeyPath: "/etc/ssl/client-key.pem"}, snowflake.DriverTLS)
+}
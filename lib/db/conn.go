@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// connStore is a Store that runs every statement on the same connection, this is for settings that are scoped to the session (e.g. `USE ROLE`).
+type connStore struct {
+	conn *sql.Conn
+}
+
+// NewConnStore returns a Store that is pinned to `conn`, statements are not retried since the session would be lost if the connection were replaced.
+func NewConnStore(conn *sql.Conn) Store {
+	return &connStore{conn: conn}
+}
+
+func (c *connStore) Exec(query string, args ...any) (sql.Result, error) {
+	return c.conn.ExecContext(context.Background(), query, args...)
+}
+
+func (c *connStore) Query(query string, args ...any) (*sql.Rows, error) {
+	return c.conn.QueryContext(context.Background(), query, args...)
+}
+
+func (c *connStore) Begin() (*sql.Tx, error) {
+	return c.conn.BeginTx(context.Background(), nil)
+}
+
+func (c *connStore) Conn(_ context.Context) (*sql.Conn, error) {
+	return nil, fmt.Errorf("store is already pinned to a connection")
+}
+
+func (c *connStore) IsRetryableError(err error) bool {
+	return retryableError(err)
+}
+
+// DiscardConn closes `conn` without returning it to the pool, so that any session settings that were changed on it cannot leak into other callers.
+func DiscardConn(conn *sql.Conn) {
+	// Returning [driver.ErrBadConn] from Raw will close the underlying connection instead of putting it back into the pool.
+	_ = conn.Raw(func(_ any) error {
+		return driver.ErrBadConn
+	})
+	_ = conn.Close()
+}
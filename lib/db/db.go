@@ -1,10 +1,15 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/jitter"
 	"github.com/artie-labs/transfer/lib/logger"
 )
@@ -18,11 +23,85 @@ type Store interface {
 	Exec(query string, args ...any) (sql.Result, error)
 	Query(query string, args ...any) (*sql.Rows, error)
 	Begin() (*sql.Tx, error)
+	// Conn returns a single connection from the pool, callers must close it once they are done with it.
+	Conn(ctx context.Context) (*sql.Conn, error)
 	IsRetryableError(err error) bool
 }
 
+// pooledDB is a connection pool along with the number of callers that are using it, so that a stale pool is only closed once they are done.
+type pooledDB struct {
+	db    *sql.DB
+	users int
+	stale bool
+}
+
 type storeWrapper struct {
-	*sql.DB
+	driverName string
+	dsn        string
+	// connector is optional, if set it will be used instead of `dsn`. This is for drivers that have settings which cannot be expressed in a DSN.
+	connector driver.Connector
+	pool      *config.ConnectionPool
+
+	healthCheckInterval time.Duration
+	lastHealthCheck     time.Time
+
+	mu sync.Mutex
+	db *pooledDB
+}
+
+// acquire returns the connection pool to use, callers must call [storeWrapper.release] once they are done with it.
+// If the health check interval has elapsed, it will ping the pool first and reconnect if the ping fails, so that a dead connection is replaced before it's used.
+func (s *storeWrapper) acquire() *pooledDB {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.healthCheckInterval > 0 && time.Since(s.lastHealthCheck) >= s.healthCheckInterval {
+		s.lastHealthCheck = time.Now()
+		s.checkHealth()
+	}
+
+	s.db.users++
+	return s.db
+}
+
+func (s *storeWrapper) release(p *pooledDB) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p.users--
+	if p.stale && p.users == 0 {
+		s.closeDB(p.db)
+	}
+}
+
+// checkHealth will ping the pool and replace it if the ping fails, `s.mu` must be held.
+// The stale pool is closed once the callers that are still using it have released it.
+func (s *storeWrapper) checkHealth() {
+	err := s.db.db.Ping()
+	if err == nil {
+		return
+	}
+
+	slog.Warn("DB connection failed health check, reconnecting...", slog.String("driverName", s.driverName), slog.Any("err", err))
+	db, err := openDB(s.driverName, s.dsn, s.connector, s.pool)
+	if err != nil {
+		// We'll try again on the next health check.
+		slog.Warn("Failed to reconnect to the DB", slog.String("driverName", s.driverName), slog.Any("err", err))
+		return
+	}
+
+	staleDB := s.db
+	staleDB.stale = true
+	s.db = &pooledDB{db: db}
+	if staleDB.users == 0 {
+		s.closeDB(staleDB.db)
+	}
+}
+
+func (s *storeWrapper) closeDB(db *sql.DB) {
+	if err := db.Close(); err != nil {
+		slog.Warn("Failed to close stale DB connection", slog.String("driverName", s.driverName), slog.Any("err", err))
+	}
 }
 
 func (s *storeWrapper) Exec(query string, args ...any) (sql.Result, error) {
@@ -39,7 +118,9 @@ func (s *storeWrapper) Exec(query string, args ...any) (sql.Result, error) {
 			time.Sleep(sleepDuration)
 		}
 
-		result, err = s.DB.Exec(query, args...)
+		p := s.acquire()
+		result, err = p.db.Exec(query, args...)
+		s.release(p)
 		if err == nil || !retryableError(err) {
 			break
 		}
@@ -47,36 +128,99 @@ func (s *storeWrapper) Exec(query string, args ...any) (sql.Result, error) {
 	return result, err
 }
 
+// Query and Begin can release the pool once they return, closing a [sql.DB] does not interrupt the rows or transactions that are still holding a connection.
 func (s *storeWrapper) Query(query string, args ...any) (*sql.Rows, error) {
-	return s.DB.Query(query, args...)
+	p := s.acquire()
+	defer s.release(p)
+	return p.db.Query(query, args...)
 }
 
 func (s *storeWrapper) Begin() (*sql.Tx, error) {
-	return s.DB.Begin()
+	p := s.acquire()
+	defer s.release(p)
+	return p.db.Begin()
+}
+
+func (s *storeWrapper) Conn(ctx context.Context) (*sql.Conn, error) {
+	p := s.acquire()
+	defer s.release(p)
+	return p.db.Conn(ctx)
 }
 
 func (s *storeWrapper) IsRetryableError(err error) bool {
 	return retryableError(err)
 }
 
-func Open(driverName, dsn string) Store {
-	db, err := sql.Open(driverName, dsn)
+func openDB(driverName, dsn string, connector driver.Connector, pool *config.ConnectionPool) (*sql.DB, error) {
+	var db *sql.DB
+	if connector != nil {
+		db = sql.OpenDB(connector)
+	} else {
+		var err error
+		db, err = sql.Open(driverName, dsn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start a SQL client: %w", err)
+		}
+	}
+
+	if pool != nil {
+		if pool.MaxOpenConnections > 0 {
+			db.SetMaxOpenConns(pool.MaxOpenConnections)
+		}
+
+		if pool.MaxIdleConnections > 0 {
+			db.SetMaxIdleConns(pool.MaxIdleConnections)
+		}
+	}
+
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime())
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to validate the DB connection: %w", err)
+	}
+
+	return db, nil
+}
+
+func newStoreWrapper(driverName, dsn string, connector driver.Connector, pool *config.ConnectionPool) (*storeWrapper, error) {
+	db, err := openDB(driverName, dsn, connector, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return &storeWrapper{
+		driverName:          driverName,
+		dsn:                 dsn,
+		connector:           connector,
+		pool:                pool,
+		healthCheckInterval: pool.HealthCheckInterval(),
+		lastHealthCheck:     time.Now(),
+		db:                  &pooledDB{db: db},
+	}, nil
+}
+
+// Open returns a Store that is backed by a connection pool, `pool` is optional and if it's nil, we'll use the default settings.
+func Open(driverName, dsn string, pool *config.ConnectionPool) Store {
+	store, err := newStoreWrapper(driverName, dsn, nil, pool)
 	if err != nil {
-		logger.Panic("Failed to start a SQL client",
+		logger.Panic("Failed to open the DB connection",
 			slog.String("driverName", driverName),
 			slog.Any("err", err),
 		)
 	}
 
-	err = db.Ping()
+	return store
+}
+
+// OpenConnector is the same as [Open], but the connection pool will be created from `connector` instead of a DSN.
+func OpenConnector(driverName string, connector driver.Connector, pool *config.ConnectionPool) Store {
+	store, err := newStoreWrapper(driverName, "", connector, pool)
 	if err != nil {
-		logger.Panic("Failed to validate the DB connection",
+		logger.Panic("Failed to open the DB connection",
 			slog.String("driverName", driverName),
 			slog.Any("err", err),
 		)
 	}
 
-	return &storeWrapper{
-		DB: db,
-	}
+	return store
 }
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/mocks/fakedriver"
+)
+
+var testDriver = &fakedriver.Driver{}
+
+var testDriverName = testDriver.Register()
+
+func TestStoreWrapper_HealthCheck(t *testing.T) {
+	store, err := newStoreWrapper(testDriverName, "", nil, &config.ConnectionPool{MaxOpenConnections: 2, MaxIdleConnections: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, store.healthCheckInterval)
+	assert.Equal(t, 2, store.db.db.Stats().MaxOpenConnections)
+
+	originalDB := store.db
+	openedBefore := testDriver.Opened()
+
+	// The health check interval has not elapsed, so we should not ping.
+	testDriver.KillConnections()
+	_, err = store.Exec("SELECT 1")
+	assert.ErrorContains(t, err, "connection is dead")
+	assert.Equal(t, originalDB, store.db)
+
+	// Once the interval elapses, the dead connection should be detected and replaced before use.
+	store.lastHealthCheck = time.Now().Add(-2 * time.Minute)
+	_, err = store.Exec("SELECT 1")
+	assert.NoError(t, err)
+	assert.NotEqual(t, originalDB, store.db)
+	assert.Greater(t, testDriver.Opened(), openedBefore)
+	assert.WithinDuration(t, time.Now(), store.lastHealthCheck, time.Second)
+	assert.ErrorContains(t, originalDB.db.Ping(), "sql: database is closed")
+
+	// The new connection is healthy, so we should keep using it.
+	replacedDB := store.db
+	store.lastHealthCheck = time.Now().Add(-2 * time.Minute)
+	rows, err := store.Query("SELECT 1")
+	assert.NoError(t, err)
+	assert.NoError(t, rows.Close())
+	assert.Equal(t, replacedDB, store.db)
+}
+
+func TestStoreWrapper_HealthCheckInFlight(t *testing.T) {
+	store, err := newStoreWrapper(testDriverName, "", nil, nil)
+	assert.NoError(t, err)
+
+	// A caller is still using the pool when it's replaced.
+	inFlight := store.acquire()
+	testDriver.KillConnections()
+	store.lastHealthCheck = time.Now().Add(-2 * time.Minute)
+	_, err = store.Exec("SELECT 1")
+	assert.NoError(t, err)
+	assert.NotEqual(t, inFlight, store.db)
+
+	// The stale pool should only be closed once it has been released.
+	assert.ErrorContains(t, inFlight.db.Ping(), "connection is dead")
+	store.release(inFlight)
+	assert.ErrorContains(t, inFlight.db.Ping(), "sql: database is closed")
+}
+
+func TestStoreWrapper_HealthCheckDisabled(t *testing.T) {
+	store, err := newStoreWrapper(testDriverName, "", nil, &config.ConnectionPool{HealthCheckIntervalSeconds: -1})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), store.healthCheckInterval)
+
+	originalDB := store.db
+	testDriver.KillConnections()
+	store.lastHealthCheck = time.Now().Add(-2 * time.Minute)
+	_, err = store.Query("SELECT 1")
+	assert.ErrorContains(t, err, "connection is dead")
+	assert.Equal(t, originalDB, store.db)
+}
+
+type fakeConnector struct {
+	connected int
+}
+
+func (f *fakeConnector) Connect(_ context.Context) (driver.Conn, error) {
+	f.connected++
+	return testDriver.Open("")
+}
+
+func (f *fakeConnector) Driver() driver.Driver {
+	return testDriver
+}
+
+func TestStoreWrapper_Connector(t *testing.T) {
+	connector := &fakeConnector{}
+	store, err := newStoreWrapper(testDriverName, "", connector, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, connector.connected)
+
+	_, err = store.Exec("SELECT 1")
+	assert.NoError(t, err)
+
+	// Reconnecting should go through the connector as well.
+	testDriver.KillConnections()
+	store.lastHealthCheck = time.Now().Add(-2 * time.Minute)
+	_, err = store.Exec("SELECT 1")
+	assert.NoError(t, err)
+	assert.Greater(t, connector.connected, 1)
+}
@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -28,6 +29,11 @@ func (m *DB) Begin() (*sql.Tx, error) {
 	return m.Fake.Begin()
 }
 
+func (m *DB) Conn(ctx context.Context) (*sql.Conn, error) {
+	fmt.Println("Mock DB Conn()")
+	return m.Fake.Conn(ctx)
+}
+
 func (m *DB) IsRetryableError(_ error) bool {
 	return false
 }
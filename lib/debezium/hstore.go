@@ -0,0 +1,124 @@
+package debezium
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// parseHstore takes in a Postgres hstore value and returns a JSON object string.
+// Depending on `hstore.handling.mode`, Debezium will either emit the hstore as a map or as a string.
+// The string can either be JSON (e.g. {"a":"b"}) or the Postgres text representation (e.g. "a"=>"b", "c"=>NULL).
+func parseHstore(value any) (string, error) {
+	switch castedValue := value.(type) {
+	case map[string]any:
+		bytes, err := json.Marshal(castedValue)
+		if err != nil {
+			return "", err
+		}
+
+		return string(bytes), nil
+	case string:
+		if strings.HasPrefix(strings.TrimSpace(castedValue), "{") {
+			var obj map[string]any
+			if err := json.Unmarshal([]byte(castedValue), &obj); err != nil {
+				return "", fmt.Errorf("failed to unmarshal hstore: %w", err)
+			}
+
+			return parseHstore(obj)
+		}
+
+		obj, err := parseHstoreText(castedValue)
+		if err != nil {
+			return "", err
+		}
+
+		return parseHstore(obj)
+	default:
+		return "", fmt.Errorf("unexpected type %T for hstore", value)
+	}
+}
+
+// parseHstoreText parses the Postgres text representation of an hstore, see: https://www.postgresql.org/docs/current/hstore.html
+func parseHstoreText(value string) (map[string]any, error) {
+	out := make(map[string]any)
+	remaining := strings.TrimSpace(value)
+	for len(remaining) > 0 {
+		key, rest, err := readHstoreToken(remaining)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read hstore key: %w", err)
+		}
+
+		if key == nil {
+			return nil, fmt.Errorf("hstore key cannot be NULL")
+		}
+
+		rest = strings.TrimSpace(rest)
+		if !strings.HasPrefix(rest, "=>") {
+			return nil, fmt.Errorf("expected => after hstore key: %q", *key)
+		}
+
+		val, rest, err := readHstoreToken(strings.TrimSpace(rest[2:]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read hstore value for key %q: %w", *key, err)
+		}
+
+		if val == nil {
+			out[*key] = nil
+		} else {
+			out[*key] = *val
+		}
+
+		rest = strings.TrimSpace(rest)
+		if len(rest) > 0 {
+			if rest[0] != ',' {
+				return nil, fmt.Errorf("expected , between hstore pairs")
+			}
+
+			rest = strings.TrimSpace(rest[1:])
+		}
+
+		remaining = rest
+	}
+
+	return out, nil
+}
+
+// readHstoreToken reads either a double-quoted string or an unquoted token, an unquoted NULL is returned as nil.
+func readHstoreToken(value string) (*string, string, error) {
+	if len(value) == 0 {
+		return nil, "", fmt.Errorf("unexpected end of hstore")
+	}
+
+	if value[0] != '"' {
+		end := strings.IndexAny(value, "=,")
+		if end == -1 {
+			end = len(value)
+		}
+
+		token := strings.TrimSpace(value[:end])
+		if strings.EqualFold(token, "NULL") {
+			return nil, value[end:], nil
+		}
+
+		return &token, value[end:], nil
+	}
+
+	var sb strings.Builder
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if i+1 < len(value) {
+				i++
+				sb.WriteByte(value[i])
+			}
+		case '"':
+			token := sb.String()
+			return &token, value[i+1:], nil
+		default:
+			sb.WriteByte(value[i])
+		}
+	}
+
+	return nil, "", fmt.Errorf("unterminated quoted string")
+}
@@ -0,0 +1,39 @@
+package debezium
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHstore(t *testing.T) {
+	{
+		// Postgres text representation
+		val, err := parseHstore(`"a"=>"1", "b"=>NULL, "key with \"quotes\""=>"x,y"`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":"1","b":null,"key with \"quotes\"":"x,y"}`, val)
+	}
+	{
+		// Unquoted tokens
+		val, err := parseHstore(`a=>1, b=>2`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":"1","b":"2"}`, val)
+	}
+	{
+		// Empty hstore
+		val, err := parseHstore("")
+		assert.NoError(t, err)
+		assert.Equal(t, `{}`, val)
+	}
+	{
+		// Malformed
+		_, err := parseHstore(`"a"=>"1`)
+		assert.ErrorContains(t, err, "unterminated quoted string")
+
+		_, err = parseHstore(`"a" "1"`)
+		assert.ErrorContains(t, err, "expected => after hstore key")
+
+		_, err = parseHstore(123)
+		assert.ErrorContains(t, err, "unexpected type int for hstore")
+	}
+}
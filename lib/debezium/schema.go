@@ -1,6 +1,8 @@
 package debezium
 
 import (
+	"strings"
+
 	"github.com/artie-labs/transfer/lib/cdc"
 	"github.com/artie-labs/transfer/lib/maputil"
 	"github.com/artie-labs/transfer/lib/ptr"
@@ -59,10 +61,19 @@ type Field struct {
 	FieldName    string                `json:"field"`
 	DebeziumType SupportedDebeziumType `json:"name"`
 	Parameters   map[string]any        `json:"parameters"`
+	// Items is the schema of the elements, this is only set for arrays.
+	Items *Field `json:"items,omitempty"`
+	// Doc is set to the source column's comment if Debezium is configured with `include.schema.comments`.
+	Doc string `json:"doc,omitempty"`
+}
+
+// Comment returns the source column's comment, or an empty string if there isn't one.
+func (f Field) Comment() string {
+	return strings.TrimSpace(f.Doc)
 }
 
 func (f Field) IsInteger() (valid bool) {
-	return f.ToKindDetails() == typing.Integer
+	return f.ToKindDetails().Kind == typing.Integer.Kind
 }
 
 func (f Field) GetScaleAndPrecision() (int, *int, error) {
@@ -88,14 +99,16 @@ func (f Field) ToKindDetails() typing.KindDetails {
 	// We'll first cast based on Debezium types
 	// Then, we'll fall back on the actual data types.
 	switch f.DebeziumType {
-	case Timestamp, MicroTimestamp, DateTimeKafkaConnect, DateTimeWithTimezone:
+	case Timestamp, MicroTimestamp, NanoTimestamp, DateTimeKafkaConnect, DateTimeWithTimezone:
 		return typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateTimeKindType)
 	case Date, DateKafkaConnect:
 		return typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateKindType)
-	case Time, MicroTime, TimeKafkaConnect, TimeWithTimezone:
+	case Time, MicroTime, NanoTime, TimeKafkaConnect, TimeWithTimezone:
 		return typing.NewKindDetailsFromTemplate(typing.ETime, ext.TimeKindType)
-	case JSON, GeometryPointType, GeometryType, GeographyType:
+	case JSON, Hstore, GeometryPointType, GeometryType, GeographyType:
 		return typing.Struct
+	case Ltree:
+		return typing.String
 	case KafkaDecimalType:
 		scale, precision, err := f.GetScaleAndPrecision()
 		if err != nil {
@@ -117,8 +130,12 @@ func (f Field) ToKindDetails() typing.KindDetails {
 	switch f.Type {
 	case Map:
 		return typing.Struct
-	case Int16, Int32, Int64:
-		return typing.Integer
+	case Int16:
+		return typing.NewIntegerKindDetails(typing.SmallIntegerKind)
+	case Int32:
+		return typing.NewIntegerKindDetails(typing.RegularIntegerKind)
+	case Int64:
+		return typing.NewIntegerKindDetails(typing.BigIntegerKind)
 	case Float, Double:
 		return typing.Float
 	case String, Bytes:
@@ -128,7 +145,11 @@ func (f Field) ToKindDetails() typing.KindDetails {
 	case Boolean:
 		return typing.Boolean
 	case Array:
-		return typing.Array
+		if f.Items == nil {
+			return typing.Array
+		}
+
+		return typing.NewArrayKindDetails(f.Items.ToKindDetails())
 	default:
 		return typing.Invalid
 	}
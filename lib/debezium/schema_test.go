@@ -154,6 +154,57 @@ func TestField_IsInteger(t *testing.T) {
 	}
 }
 
+func TestField_ArrayItems(t *testing.T) {
+	payload := `{
+	"type": "struct",
+	"fields": [{
+		"type": "struct",
+		"fields": [{
+			"type": "array",
+			"items": {"type": "int64", "optional": true},
+			"optional": true,
+			"field": "int_arr"
+		}, {
+			"type": "array",
+			"items": {"type": "string", "optional": true},
+			"optional": true,
+			"field": "text_arr"
+		}, {
+			"type": "array",
+			"items": {
+				"type": "struct",
+				"fields": [{"type": "string", "optional": true, "field": "street"}],
+				"optional": true,
+				"name": "dbserver1.inventory.address"
+			},
+			"optional": true,
+			"field": "addresses"
+		}],
+		"optional": true,
+		"name": "dbserver1.inventory.customers.Value",
+		"field": "after"
+	}],
+	"optional": false,
+	"name": "dbserver1.inventory.customers.Envelope",
+	"version": 1
+}`
+
+	var schema Schema
+	assert.NoError(t, json.Unmarshal([]byte(payload), &schema))
+
+	expected := map[string]typing.KindDetails{
+		"int_arr":   typing.NewArrayKindDetails(typing.NewIntegerKindDetails(typing.BigIntegerKind)),
+		"text_arr":  typing.NewArrayKindDetails(typing.String),
+		"addresses": typing.NewArrayKindDetails(typing.Struct),
+	}
+
+	fields := schema.GetSchemaFromLabel(cdc.After).Fields
+	assert.Len(t, fields, len(expected))
+	for _, field := range fields {
+		assert.Equal(t, expected[field.FieldName], field.ToKindDetails(), field.FieldName)
+	}
+}
+
 func TestField_ToKindDetails(t *testing.T) {
 	type _tc struct {
 		name                string
@@ -171,17 +222,17 @@ func TestField_ToKindDetails(t *testing.T) {
 		{
 			name:                "int16",
 			field:               Field{Type: "int16"},
-			expectedKindDetails: typing.Integer,
+			expectedKindDetails: typing.NewIntegerKindDetails(typing.SmallIntegerKind),
 		},
 		{
 			name:                "int32",
 			field:               Field{Type: "int32"},
-			expectedKindDetails: typing.Integer,
+			expectedKindDetails: typing.NewIntegerKindDetails(typing.RegularIntegerKind),
 		},
 		{
 			name:                "int64",
 			field:               Field{Type: "int64"},
-			expectedKindDetails: typing.Integer,
+			expectedKindDetails: typing.NewIntegerKindDetails(typing.BigIntegerKind),
 		},
 		{
 			name:                "float",
@@ -218,6 +269,31 @@ func TestField_ToKindDetails(t *testing.T) {
 			field:               Field{Type: "array"},
 			expectedKindDetails: typing.Array,
 		},
+		{
+			name:                "array of int64",
+			field:               Field{Type: "array", Items: &Field{Type: "int64", Optional: true}},
+			expectedKindDetails: typing.NewArrayKindDetails(typing.NewIntegerKindDetails(typing.BigIntegerKind)),
+		},
+		{
+			name:                "array of strings",
+			field:               Field{Type: "array", Items: &Field{Type: "string", Optional: true}},
+			expectedKindDetails: typing.NewArrayKindDetails(typing.String),
+		},
+		{
+			name:                "array of structs",
+			field:               Field{Type: "array", Items: &Field{Type: "struct"}},
+			expectedKindDetails: typing.NewArrayKindDetails(typing.Struct),
+		},
+		{
+			name:                "array of timestamps",
+			field:               Field{Type: "array", Items: &Field{Type: "int64", DebeziumType: MicroTimestamp}},
+			expectedKindDetails: typing.NewArrayKindDetails(typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateTimeKindType)),
+		},
+		{
+			name:                "nested array",
+			field:               Field{Type: "array", Items: &Field{Type: "array", Items: &Field{Type: "int64"}}},
+			expectedKindDetails: typing.Array,
+		},
 		{
 			name:                "Invalid",
 			field:               Field{Type: "unknown"},
@@ -304,6 +380,22 @@ func TestField_ToKindDetails(t *testing.T) {
 			},
 			expectedKindDetails: typing.Struct,
 		},
+		{
+			name: "Hstore",
+			field: Field{
+				Type:         String,
+				DebeziumType: Hstore,
+			},
+			expectedKindDetails: typing.Struct,
+		},
+		{
+			name: "Ltree",
+			field: Field{
+				Type:         String,
+				DebeziumType: Ltree,
+			},
+			expectedKindDetails: typing.String,
+		},
 		// Decimal
 		{
 			name: "KafkaDecimalType",
@@ -326,6 +418,14 @@ func TestField_ToKindDetails(t *testing.T) {
 			},
 			expectedKindDetails: eDecimal,
 		},
+		{
+			name: "KafkaVariableNumericType (no scale parameter)",
+			field: Field{
+				Type:         Struct,
+				DebeziumType: KafkaVariableNumericType,
+			},
+			expectedKindDetails: eDecimal,
+		},
 		{
 			name: "Debezium Map",
 			field: Field{
@@ -340,3 +440,14 @@ func TestField_ToKindDetails(t *testing.T) {
 		assert.Equal(t, tc.expectedKindDetails, tc.field.ToKindDetails(), tc.name)
 	}
 }
+
+func TestField_Comment(t *testing.T) {
+	var field Field
+	assert.NoError(t, json.Unmarshal([]byte(`{"type": "string", "optional": true, "field": "email", "doc": " The customer's primary email address\n"}`), &field))
+	assert.Equal(t, "The customer's primary email address", field.Comment())
+
+	// Columns without a comment
+	var idField Field
+	assert.NoError(t, json.Unmarshal([]byte(`{"type": "int32", "optional": false, "field": "id"}`), &idField))
+	assert.Empty(t, idField.Comment())
+}
@@ -2,6 +2,7 @@ package debezium
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"strconv"
@@ -24,12 +25,16 @@ const (
 	Enum    SupportedDebeziumType = "io.debezium.data.Enum"
 	EnumSet SupportedDebeziumType = "io.debezium.data.EnumSet"
 	UUID    SupportedDebeziumType = "io.debezium.data.Uuid"
+	Ltree   SupportedDebeziumType = "io.debezium.data.Ltree"
+	Hstore  SupportedDebeziumType = "io.debezium.data.Hstore"
 
 	Timestamp            SupportedDebeziumType = "io.debezium.time.Timestamp"
 	MicroTimestamp       SupportedDebeziumType = "io.debezium.time.MicroTimestamp"
+	NanoTimestamp        SupportedDebeziumType = "io.debezium.time.NanoTimestamp"
 	Date                 SupportedDebeziumType = "io.debezium.time.Date"
 	Time                 SupportedDebeziumType = "io.debezium.time.Time"
 	MicroTime            SupportedDebeziumType = "io.debezium.time.MicroTime"
+	NanoTime             SupportedDebeziumType = "io.debezium.time.NanoTime"
 	Year                 SupportedDebeziumType = "io.debezium.time.Year"
 	TimeWithTimezone     SupportedDebeziumType = "io.debezium.time.ZonedTime"
 	DateTimeWithTimezone SupportedDebeziumType = "io.debezium.time.ZonedTimestamp"
@@ -80,8 +85,11 @@ func toBytes(value any) ([]byte, error) {
 // toInt64 attempts to convert a value of unknown type to a an int64.
 // - If the value is coming from Kafka it will be decoded as a float64 when it is unmarshalled from JSON.
 // - If the value is coming from reader the value will be an int16/int32/int64.
+// - If `preserveJSONNumbers` is enabled, the value may be a [json.Number].
 func toInt64(value any) (int64, error) {
 	switch typedValue := value.(type) {
+	case json.Number:
+		return typedValue.Int64()
 	case int:
 		return int64(typedValue), nil
 	case int16:
@@ -131,6 +139,11 @@ func (f Field) ParseValue(value any) (any, error) {
 			return value, nil
 		}
 		return jsonutil.SanitizePayload(value)
+	case Hstore:
+		if value == constants.ToastUnavailableValuePlaceholder {
+			return value, nil
+		}
+		return parseHstore(value)
 	case GeometryType, GeographyType:
 		return parseGeometry(value)
 	case GeometryPointType:
@@ -146,9 +159,11 @@ func (f Field) ParseValue(value any) (any, error) {
 	case
 		Timestamp,
 		MicroTimestamp,
+		NanoTimestamp,
 		Date,
 		Time,
 		MicroTime,
+		NanoTime,
 		DateKafkaConnect,
 		TimeKafkaConnect,
 		DateTimeKafkaConnect:
@@ -220,19 +235,29 @@ func FromDebeziumTypeToTime(supportedType SupportedDebeziumType, val int64) (*ex
 	case Timestamp, DateTimeKafkaConnect:
 		// Represents the number of milliseconds since the epoch, and does not include timezone information.
 		extTime = ext.NewExtendedTime(time.UnixMilli(val).In(time.UTC), ext.DateTimeKindType, time.RFC3339Nano)
+		extTime.Precision = ext.MillisecondPrecision
 	case MicroTimestamp:
 		// Represents the number of microseconds since the epoch, and does not include timezone information.
 		extTime = ext.NewExtendedTime(time.UnixMicro(val).In(time.UTC), ext.DateTimeKindType, time.RFC3339Nano)
+		extTime.Precision = ext.MicrosecondPrecision
+	case NanoTimestamp:
+		// Represents the number of nanoseconds since the epoch, and does not include timezone information.
+		extTime = ext.NewExtendedTime(time.Unix(0, val).In(time.UTC), ext.DateTimeKindType, time.RFC3339Nano)
+		extTime.Precision = ext.NanosecondPrecision
 	case Date, DateKafkaConnect:
 		unix := time.UnixMilli(0).In(time.UTC) // 1970-01-01
 		// Represents the number of days since the epoch.
 		extTime = ext.NewExtendedTime(unix.AddDate(0, 0, int(val)), ext.DateKindType, "")
 	case Time, TimeKafkaConnect:
 		// Represents the number of milliseconds past midnight, and does not include timezone information.
-		extTime = ext.NewExtendedTime(time.UnixMilli(val).In(time.UTC), ext.TimeKindType, "")
+		// Kafka Connect's Time is an int32 of milliseconds past midnight, so it has the same unit as Debezium's Time.
+		return timeOfDay(supportedType, val, time.Millisecond, ext.MillisecondPrecision)
 	case MicroTime:
 		// Represents the number of microseconds past midnight, and does not include timezone information.
-		extTime = ext.NewExtendedTime(time.UnixMicro(val).In(time.UTC), ext.TimeKindType, "")
+		return timeOfDay(supportedType, val, time.Microsecond, ext.MicrosecondPrecision)
+	case NanoTime:
+		// Represents the number of nanoseconds past midnight, and does not include timezone information.
+		return timeOfDay(supportedType, val, time.Nanosecond, ext.NanosecondPrecision)
 	default:
 		return nil, fmt.Errorf("supportedType: %s, val: %v failed to be matched", supportedType, val)
 	}
@@ -244,6 +269,19 @@ func FromDebeziumTypeToTime(supportedType SupportedDebeziumType, val int64) (*ex
 	return extTime, nil
 }
 
+// timeOfDay will convert `val` which is the number of `unit` past midnight into a time.
+// If the wrong unit is used, the value will be off by orders of magnitude, so we'll return an error if it does not fit within a day.
+func timeOfDay(supportedType SupportedDebeziumType, val int64, unit time.Duration, precision int) (*ext.ExtendedTime, error) {
+	// 24:00:00 is allowed since Postgres supports it. This is checked before multiplying so the duration cannot overflow.
+	if val < 0 || val > int64(24*time.Hour/unit) {
+		return nil, fmt.Errorf("supportedType: %s, val: %v is not within a day", supportedType, val)
+	}
+
+	extTime := ext.NewExtendedTime(time.Unix(0, 0).In(time.UTC).Add(time.Duration(val)*unit), ext.TimeKindType, "")
+	extTime.Precision = precision
+	return extTime, nil
+}
+
 // DecodeDecimal is used to handle `org.apache.kafka.connect.data.Decimal` where this would be emitted by Debezium when the `decimal.handling.mode` is `precise`
 // * Encoded - takes the encoded value as a slice of bytes
 // * Parameters - which contains:
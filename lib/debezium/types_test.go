@@ -1,6 +1,7 @@
 package debezium
 
 import (
+	"math"
 	"math/big"
 	"testing"
 	"time"
@@ -212,6 +213,32 @@ func TestField_ParseValue(t *testing.T) {
 			expectedValue:   "123.45",
 			expectedDecimal: true,
 		},
+		{
+			name: "variable decimal (no scale parameter, scale 3)",
+			field: Field{
+				Type:         Struct,
+				DebeziumType: KafkaVariableNumericType,
+			},
+			value: map[string]any{
+				"scale": 3,
+				"value": "SOx4FQ==",
+			},
+			expectedValue:   "1223456.789",
+			expectedDecimal: true,
+		},
+		{
+			name: "variable decimal (no scale parameter, scale 2)",
+			field: Field{
+				Type:         Struct,
+				DebeziumType: KafkaVariableNumericType,
+			},
+			value: map[string]any{
+				"scale": 2,
+				"value": "MDk=",
+			},
+			expectedValue:   "123.45",
+			expectedDecimal: true,
+		},
 		{
 			name: "geometry (no srid)",
 			field: Field{
@@ -277,6 +304,22 @@ func TestField_ParseValue(t *testing.T) {
 			value:         `[[{"foo":"bar", "foo": "bar"}], [{"hello":"world"}, {"dusty":"the mini aussie"}]]`,
 			expectedValue: `[[{"foo":"bar"}],[{"hello":"world"},{"dusty":"the mini aussie"}]]`,
 		},
+		{
+			name: "null in JSONB",
+			field: Field{
+				DebeziumType: JSON,
+			},
+			value:         `null`,
+			expectedValue: `null`,
+		},
+		{
+			name: "scalar in JSONB",
+			field: Field{
+				DebeziumType: JSON,
+			},
+			value:         `"hello"`,
+			expectedValue: `"hello"`,
+		},
 		{
 			name: "int64 micro-timestamp",
 			field: Field{
@@ -290,6 +333,7 @@ func TestField_ParseValue(t *testing.T) {
 					Type:   ext.DateTimeKindType,
 					Format: "2006-01-02T15:04:05.999999999Z07:00",
 				},
+				Precision: ext.MicrosecondPrecision,
 			},
 		},
 		{
@@ -305,6 +349,7 @@ func TestField_ParseValue(t *testing.T) {
 					Type:   ext.DateTimeKindType,
 					Format: "2006-01-02T15:04:05.999999999Z07:00",
 				},
+				Precision: ext.MicrosecondPrecision,
 			},
 		},
 		{
@@ -320,6 +365,7 @@ func TestField_ParseValue(t *testing.T) {
 					Type:   ext.DateTimeKindType,
 					Format: "2006-01-02T15:04:05.999999999Z07:00",
 				},
+				Precision: ext.MicrosecondPrecision,
 			},
 		},
 		{
@@ -356,6 +402,42 @@ func TestField_ParseValue(t *testing.T) {
 			value:       "i'm not json",
 			expectedErr: "invalid character 'i' looking for beginning of value",
 		},
+		{
+			name: "Ltree",
+			field: Field{
+				Type:         String,
+				DebeziumType: Ltree,
+			},
+			value:         "Top.Science.Astronomy",
+			expectedValue: "Top.Science.Astronomy",
+		},
+		{
+			name: "Hstore map",
+			field: Field{
+				Type:         Map,
+				DebeziumType: Hstore,
+			},
+			value:         map[string]any{"a": "1", "b": nil},
+			expectedValue: `{"a":"1","b":null}`,
+		},
+		{
+			name: "Hstore JSON string",
+			field: Field{
+				Type:         String,
+				DebeziumType: Hstore,
+			},
+			value:         `{"b":"2","a":"1"}`,
+			expectedValue: `{"a":"1","b":"2"}`,
+		},
+		{
+			name: "Hstore toast",
+			field: Field{
+				Type:         String,
+				DebeziumType: Hstore,
+			},
+			value:         constants.ToastUnavailableValuePlaceholder,
+			expectedValue: constants.ToastUnavailableValuePlaceholder,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -390,7 +472,7 @@ func TestFromDebeziumTypeTimePrecisionConnect(t *testing.T) {
 	// Time
 	extendedTime, timeErr := FromDebeziumTypeToTime(TimeKafkaConnect, 54720000)
 	assert.NoError(t, timeErr)
-	assert.Equal(t, "15:12:00+00", extendedTime.String(""))
+	assert.Equal(t, "15:12:00.000+00", extendedTime.String(""))
 
 	// Date
 	extendedDate, dateErr := FromDebeziumTypeToTime(DateKafkaConnect, 19429)
@@ -398,6 +480,63 @@ func TestFromDebeziumTypeTimePrecisionConnect(t *testing.T) {
 	assert.Equal(t, "2023-03-13", extendedDate.String(""))
 }
 
+func TestFromDebeziumTypeToTime_NanoPrecision(t *testing.T) {
+	// NanoTimestamp
+	extendedTimestamp, err := FromDebeziumTypeToTime(NanoTimestamp, 1662434364942000000)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2022, time.September, 6, 3, 19, 24, 942000000, time.UTC), extendedTimestamp.Time)
+	assert.Equal(t, "2022-09-06T03:19:24.942000000Z", extendedTimestamp.String(""))
+
+	// NanoTime
+	extendedTime, err := FromDebeziumTypeToTime(NanoTime, 11964942000000)
+	assert.NoError(t, err)
+	assert.Equal(t, ext.TimeKindType, extendedTime.NestedKind.Type)
+	assert.Equal(t, "03:19:24.942000000", extendedTime.String("15:04:05.999999999"))
+}
+
+func TestFromDebeziumTypeToTime_TimeUnits(t *testing.T) {
+	// 15:12:00.123 past midnight
+	const millis = int64(54720123)
+	for _, tc := range []struct {
+		supportedType SupportedDebeziumType
+		value         int64
+	}{
+		{supportedType: Time, value: millis},
+		{supportedType: TimeKafkaConnect, value: millis},
+		{supportedType: MicroTime, value: millis * 1_000},
+		{supportedType: NanoTime, value: millis * 1_000_000},
+	} {
+		extTime, err := FromDebeziumTypeToTime(tc.supportedType, tc.value)
+		assert.NoError(t, err, tc.supportedType)
+		assert.Equal(t, ext.TimeKindType, extTime.NestedKind.Type, tc.supportedType)
+		assert.Equal(t, time.Date(1970, time.January, 1, 15, 12, 0, 123000000, time.UTC), extTime.Time, tc.supportedType)
+		assert.Equal(t, "15:12:00.123", extTime.String("15:04:05.999"), tc.supportedType)
+	}
+
+	{
+		// Midnight and 24:00:00 are both valid.
+		_, err := FromDebeziumTypeToTime(MicroTime, 0)
+		assert.NoError(t, err)
+		_, err = FromDebeziumTypeToTime(MicroTime, 86_400_000_000)
+		assert.NoError(t, err)
+	}
+	{
+		// Microseconds being decoded as milliseconds would not fit within a day.
+		_, err := FromDebeziumTypeToTime(Time, millis*1_000)
+		assert.ErrorContains(t, err, "supportedType: io.debezium.time.Time, val: 54720123000 is not within a day")
+	}
+	{
+		// Negative
+		_, err := FromDebeziumTypeToTime(TimeKafkaConnect, -1)
+		assert.ErrorContains(t, err, "is not within a day")
+	}
+	{
+		// Would overflow if we multiplied first.
+		_, err := FromDebeziumTypeToTime(Time, math.MaxInt64)
+		assert.ErrorContains(t, err, "is not within a day")
+	}
+}
+
 func TestField_DecodeDecimal(t *testing.T) {
 	testCases := []struct {
 		name    string
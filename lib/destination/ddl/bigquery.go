@@ -0,0 +1,71 @@
+package ddl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib/partition"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+// bigQueryTableOptions returns the `PARTITION BY` and `CLUSTER BY` clauses that will be appended to the `CREATE TABLE` statement.
+// The partition and cluster fields must be one of the columns that we are creating the table with.
+func (a AlterTableArgs) bigQueryTableOptions(cols []columns.Column) (string, error) {
+	colsByName := make(map[string]columns.Column)
+	for _, col := range cols {
+		colsByName[col.RawName()] = col
+	}
+
+	escapedName := func(name string) (string, columns.Column, error) {
+		col, isOk := colsByName[name]
+		if !isOk {
+			return "", columns.Column{}, fmt.Errorf("column %q does not exist", name)
+		}
+
+		return col.Name(a.IdentifierCasing, &sql.NameArgs{Escape: true, DestKind: constants.BigQuery}), col, nil
+	}
+
+	var parts []string
+	if a.BigQueryPartitionSettings != nil {
+		if err := a.BigQueryPartitionSettings.Valid(); err != nil {
+			return "", fmt.Errorf("invalid partition settings: %w", err)
+		}
+
+		switch a.BigQueryPartitionSettings.PartitionType {
+		case partition.TimePartitionType:
+			colName, col, err := escapedName(a.BigQueryPartitionSettings.PartitionField)
+			if err != nil {
+				return "", fmt.Errorf("invalid partition field: %w", err)
+			}
+
+			if col.KindDetails.Kind == typing.ETime.Kind && col.KindDetails.ExtendedTimeDetails != nil && col.KindDetails.ExtendedTimeDetails.Type == ext.DateKindType {
+				// DATE columns can be used as is.
+				parts = append(parts, fmt.Sprintf("PARTITION BY %s", colName))
+			} else {
+				parts = append(parts, fmt.Sprintf("PARTITION BY DATE(%s)", colName))
+			}
+		case partition.IngestionTimePartitionType:
+			parts = append(parts, "PARTITION BY _PARTITIONDATE")
+		}
+	}
+
+	if len(a.BigQueryClusterFields) > 0 {
+		var clusterCols []string
+		for _, field := range a.BigQueryClusterFields {
+			colName, _, err := escapedName(field)
+			if err != nil {
+				return "", fmt.Errorf("invalid cluster field: %w", err)
+			}
+
+			clusterCols = append(clusterCols, colName)
+		}
+
+		parts = append(parts, fmt.Sprintf("CLUSTER BY %s", strings.Join(clusterCols, ", ")))
+	}
+
+	return strings.Join(parts, " "), nil
+}
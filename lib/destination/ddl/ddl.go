@@ -15,6 +15,7 @@ import (
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/destination"
 	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/kafkalib/partition"
 	"github.com/artie-labs/transfer/lib/typing"
 )
 
@@ -46,12 +47,16 @@ type AlterTableArgs struct {
 	FqTableName            string
 	CreateTable            bool
 	TemporaryTable         bool
-	UppercaseEscNames      *bool
+	IdentifierCasing       config.IdentifierCasing
 
 	ColumnOp constants.ColumnOperation
 	Mode     config.Mode
 
 	CdcTime time.Time
+
+	// BigQueryPartitionSettings and BigQueryClusterFields are optional, these are only used when we are creating a BigQuery table.
+	BigQueryPartitionSettings *partition.BigQuerySettings
+	BigQueryClusterFields     []string
 }
 
 func (a AlterTableArgs) Validate() error {
@@ -71,8 +76,8 @@ func (a AlterTableArgs) Validate() error {
 		}
 	}
 
-	if a.UppercaseEscNames == nil {
-		return fmt.Errorf("uppercaseEscNames cannot be nil")
+	if err := a.IdentifierCasing.Validate(); err != nil {
+		return err
 	}
 
 	return nil
@@ -103,22 +108,31 @@ func (a AlterTableArgs) AlterTable(cols ...columns.Column) error {
 			}
 		}
 
+		if a.ColumnOp == constants.Add && !a.CreateTable {
+			// If the table's columns are matched case-insensitively, `Email` will match the existing `email` column and shouldn't be added again.
+			if existingCol, isOk := a.Tc.Columns().GetColumn(col.RawName()); isOk && existingCol.RawName() != col.RawName() {
+				continue
+			}
+		}
+
 		mutateCol = append(mutateCol, col)
 		switch a.ColumnOp {
 		case constants.Add:
-			colName := col.Name(*a.UppercaseEscNames, &sql.NameArgs{
+			colName := col.Name(a.IdentifierCasing, &sql.NameArgs{
 				Escape:   true,
 				DestKind: a.Dwh.Label(),
 			})
 
-			if col.PrimaryKey() && a.Mode != config.History {
+			// Databricks only supports informational primary keys in Unity Catalog and they require the columns to be NOT NULL, so we'll skip it.
+			// DuckDB enforces primary keys with an index that rejects UPDATEs that rewrite the key columns, which our merge does, so we'll skip it as well.
+			if col.PrimaryKey() && a.Mode != config.History && a.Dwh.Label() != constants.Databricks && a.Dwh.Label() != constants.DuckDB {
 				// Don't create a PK for history mode because it's append-only, so the primary key should not be enforced.
 				pkCols = append(pkCols, colName)
 			}
 
 			colSQLParts = append(colSQLParts, fmt.Sprintf(`%s %s`, colName, typing.KindToDWHType(col.KindDetails, a.Dwh.Label(), col.PrimaryKey())))
 		case constants.Delete:
-			colSQLParts = append(colSQLParts, col.Name(*a.UppercaseEscNames, &sql.NameArgs{
+			colSQLParts = append(colSQLParts, col.Name(a.IdentifierCasing, &sql.NameArgs{
 				Escape:   true,
 				DestKind: a.Dwh.Label(),
 			}))
@@ -143,6 +157,13 @@ func (a AlterTableArgs) AlterTable(cols ...columns.Column) error {
 				sqlQuery = fmt.Sprintf("CREATE TABLE %s (%s);", a.FqTableName, strings.Join(colSQLParts, ","))
 			case constants.Redshift:
 				sqlQuery = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s);", a.FqTableName, strings.Join(colSQLParts, ","))
+			case constants.Databricks:
+				// Databricks does not have session scoped temporary tables, these are dropped after the merge and swept on startup.
+				sqlQuery = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", a.FqTableName, strings.Join(colSQLParts, ","))
+			case constants.DuckDB:
+				// DuckDB's temporary tables are only visible to the connection that created them and the COPY may run on another connection from the pool.
+				// These are regular tables that are dropped after the merge and swept on startup.
+				sqlQuery = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", a.FqTableName, strings.Join(colSQLParts, ","))
 			case constants.BigQuery:
 				sqlQuery = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (%s) OPTIONS (expiration_timestamp = TIMESTAMP("%s"))`,
 					a.FqTableName, strings.Join(colSQLParts, ","), typing.ExpiresDate(time.Now().UTC().Add(constants.TemporaryTableTTL)))
@@ -163,6 +184,17 @@ func (a AlterTableArgs) AlterTable(cols ...columns.Column) error {
 			} else {
 				sqlQuery = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", a.FqTableName, strings.Join(colSQLParts, ","))
 			}
+
+			if a.Dwh.Label() == constants.BigQuery {
+				tableOptions, err := a.bigQueryTableOptions(mutateCol)
+				if err != nil {
+					return fmt.Errorf("failed to build table options: %w", err)
+				}
+
+				if tableOptions != "" {
+					sqlQuery = fmt.Sprintf("%s %s", sqlQuery, tableOptions)
+				}
+			}
 		}
 
 		slog.Info("DDL - executing sql", slog.String("query", sqlQuery))
@@ -197,6 +229,9 @@ func (a AlterTableArgs) AlterTable(cols ...columns.Column) error {
 	if err == nil {
 		// createTable = false since it all successfully updated.
 		a.Tc.MutateInMemoryColumns(false, a.ColumnOp, mutateCol...)
+		if !a.TemporaryTable && len(mutateCol) > 0 {
+			a.Tc.SchemaChanged()
+		}
 	}
 
 	return nil
@@ -6,8 +6,6 @@ import (
 
 	"github.com/artie-labs/transfer/lib/config"
 
-	"github.com/artie-labs/transfer/lib/ptr"
-
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/destination/ddl"
 	"github.com/artie-labs/transfer/lib/destination/types"
@@ -33,10 +31,10 @@ func (d *DDLTestSuite) TestAlterDelete_Complete() {
 	}, "tableName")
 
 	originalColumnLength := len(cols.GetColumns())
-	bqName := td.ToFqName(constants.BigQuery, true, false,
+	bqName := td.ToFqName(constants.BigQuery, true, config.PreserveCasing,
 		optimization.FqNameOpts{BigQueryProjectID: d.bigQueryCfg.BigQuery.ProjectID})
-	redshiftName := td.ToFqName(constants.Redshift, true, false, optimization.FqNameOpts{})
-	snowflakeName := td.ToFqName(constants.Snowflake, true, false, optimization.FqNameOpts{})
+	redshiftName := td.ToFqName(constants.Redshift, true, config.PreserveCasing, optimization.FqNameOpts{})
+	snowflakeName := td.ToFqName(constants.Snowflake, true, config.PreserveCasing, optimization.FqNameOpts{})
 
 	// Testing 3 scenarios here
 	// 1. DropDeletedColumns = false, ContainOtherOperations = true, don't delete ever.
@@ -63,7 +61,7 @@ func (d *DDLTestSuite) TestAlterDelete_Complete() {
 			ContainOtherOperations: true,
 			ColumnOp:               constants.Delete,
 			CdcTime:                ts,
-			UppercaseEscNames:      ptr.ToBool(false),
+			IdentifierCasing:       config.PreserveCasing,
 			Mode:                   config.Replication,
 		}
 
@@ -84,7 +82,7 @@ func (d *DDLTestSuite) TestAlterDelete_Complete() {
 			ContainOtherOperations: true,
 			ColumnOp:               constants.Delete,
 			CdcTime:                ts,
-			UppercaseEscNames:      ptr.ToBool(false),
+			IdentifierCasing:       config.PreserveCasing,
 			Mode:                   config.Replication,
 		}
 
@@ -106,7 +104,7 @@ func (d *DDLTestSuite) TestAlterDelete_Complete() {
 			ContainOtherOperations: true,
 			ColumnOp:               constants.Delete,
 			CdcTime:                ts,
-			UppercaseEscNames:      ptr.ToBool(false),
+			IdentifierCasing:       config.PreserveCasing,
 			Mode:                   config.Replication,
 		}
 
@@ -141,7 +139,7 @@ func (d *DDLTestSuite) TestAlterDelete_Complete() {
 			ContainOtherOperations: false,
 			ColumnOp:               constants.Delete,
 			CdcTime:                ts,
-			UppercaseEscNames:      ptr.ToBool(false),
+			IdentifierCasing:       config.PreserveCasing,
 			Mode:                   config.Replication,
 		}
 
@@ -162,7 +160,7 @@ func (d *DDLTestSuite) TestAlterDelete_Complete() {
 			ContainOtherOperations: false,
 			ColumnOp:               constants.Delete,
 			CdcTime:                ts,
-			UppercaseEscNames:      ptr.ToBool(false),
+			IdentifierCasing:       config.PreserveCasing,
 			Mode:                   config.Replication,
 		}
 
@@ -183,7 +181,7 @@ func (d *DDLTestSuite) TestAlterDelete_Complete() {
 			ContainOtherOperations: false,
 			ColumnOp:               constants.Delete,
 			CdcTime:                ts,
-			UppercaseEscNames:      ptr.ToBool(false),
+			IdentifierCasing:       config.PreserveCasing,
 			Mode:                   config.Replication,
 		}
 
@@ -220,7 +218,7 @@ func (d *DDLTestSuite) TestAlterDelete_Complete() {
 			ContainOtherOperations: true,
 			ColumnOp:               constants.Delete,
 			CdcTime:                ts,
-			UppercaseEscNames:      ptr.ToBool(false),
+			IdentifierCasing:       config.PreserveCasing,
 			Mode:                   config.Replication,
 		}
 
@@ -237,7 +235,7 @@ func (d *DDLTestSuite) TestAlterDelete_Complete() {
 			ContainOtherOperations: true,
 			ColumnOp:               constants.Delete,
 			CdcTime:                ts,
-			UppercaseEscNames:      ptr.ToBool(false),
+			IdentifierCasing:       config.PreserveCasing,
 			Mode:                   config.Replication,
 		}
 
@@ -254,7 +252,7 @@ func (d *DDLTestSuite) TestAlterDelete_Complete() {
 			ContainOtherOperations: true,
 			ColumnOp:               constants.Delete,
 			CdcTime:                ts,
-			UppercaseEscNames:      ptr.ToBool(false),
+			IdentifierCasing:       config.PreserveCasing,
 			Mode:                   config.Replication,
 		}
 
@@ -279,7 +277,7 @@ func (d *DDLTestSuite) TestAlterDelete_Complete() {
 			ContainOtherOperations: true,
 			ColumnOp:               constants.Delete,
 			CdcTime:                ts.Add(2 * constants.DeletionConfidencePadding),
-			UppercaseEscNames:      ptr.ToBool(false),
+			IdentifierCasing:       config.PreserveCasing,
 			Mode:                   config.Replication,
 		}
 
@@ -294,7 +292,7 @@ func (d *DDLTestSuite) TestAlterDelete_Complete() {
 			ContainOtherOperations: true,
 			ColumnOp:               constants.Delete,
 			CdcTime:                ts.Add(2 * constants.DeletionConfidencePadding),
-			UppercaseEscNames:      ptr.ToBool(false),
+			IdentifierCasing:       config.PreserveCasing,
 			Mode:                   config.Replication,
 		}
 
@@ -309,7 +307,7 @@ func (d *DDLTestSuite) TestAlterDelete_Complete() {
 			ContainOtherOperations: true,
 			ColumnOp:               constants.Delete,
 			CdcTime:                ts.Add(2 * constants.DeletionConfidencePadding),
-			UppercaseEscNames:      ptr.ToBool(false),
+			IdentifierCasing:       config.PreserveCasing,
 			Mode:                   config.Replication,
 		}
 
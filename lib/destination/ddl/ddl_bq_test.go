@@ -8,8 +8,6 @@ import (
 
 	"github.com/artie-labs/transfer/lib/config"
 
-	"github.com/artie-labs/transfer/lib/ptr"
-
 	artieSQL "github.com/artie-labs/transfer/lib/sql"
 
 	"github.com/artie-labs/transfer/lib/typing/columns"
@@ -20,8 +18,10 @@ import (
 	"github.com/artie-labs/transfer/lib/destination/ddl"
 	"github.com/artie-labs/transfer/lib/destination/types"
 	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/kafkalib/partition"
 	"github.com/artie-labs/transfer/lib/optimization"
 	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/ext"
 )
 
 func (d *DDLTestSuite) TestAlterTableDropColumnsBigQuery() {
@@ -47,7 +47,7 @@ func (d *DDLTestSuite) TestAlterTableDropColumnsBigQuery() {
 	}
 
 	bqProjectID := d.bigQueryCfg.BigQuery.ProjectID
-	fqName := td.ToFqName(d.bigQueryStore.Label(), true, false, optimization.FqNameOpts{BigQueryProjectID: bqProjectID})
+	fqName := td.ToFqName(d.bigQueryStore.Label(), true, config.PreserveCasing, optimization.FqNameOpts{BigQueryProjectID: bqProjectID})
 	originalColumnLength := len(cols.GetColumns())
 	d.bigQueryStore.GetConfigMap().AddTableToConfig(fqName, types.NewDwhTableConfig(&cols, nil, false, true))
 	tc := d.bigQueryStore.GetConfigMap().TableConfig(fqName)
@@ -63,7 +63,7 @@ func (d *DDLTestSuite) TestAlterTableDropColumnsBigQuery() {
 			ColumnOp:               constants.Delete,
 			ContainOtherOperations: true,
 			CdcTime:                ts,
-			UppercaseEscNames:      ptr.ToBool(false),
+			IdentifierCasing:       config.PreserveCasing,
 			Mode:                   config.Replication,
 		}
 
@@ -86,13 +86,13 @@ func (d *DDLTestSuite) TestAlterTableDropColumnsBigQuery() {
 			ColumnOp:               constants.Delete,
 			ContainOtherOperations: true,
 			CdcTime:                ts.Add(2 * constants.DeletionConfidencePadding),
-			UppercaseEscNames:      ptr.ToBool(false),
+			IdentifierCasing:       config.PreserveCasing,
 			Mode:                   config.Replication,
 		}
 
 		assert.NoError(d.T(), alterTableArgs.AlterTable(column))
 		query, _ := d.fakeBigQueryStore.ExecArgsForCall(callIdx)
-		assert.Equal(d.T(), fmt.Sprintf("ALTER TABLE %s drop COLUMN %s", fqName, column.Name(false, &artieSQL.NameArgs{
+		assert.Equal(d.T(), fmt.Sprintf("ALTER TABLE %s drop COLUMN %s", fqName, column.Name(config.PreserveCasing, &artieSQL.NameArgs{
 			Escape:   true,
 			DestKind: d.bigQueryStore.Label(),
 		})), query)
@@ -138,21 +138,21 @@ func (d *DDLTestSuite) TestAlterTableAddColumns() {
 	tc := d.bigQueryStore.GetConfigMap().TableConfig(fqName)
 	for name, kind := range newCols {
 		alterTableArgs := ddl.AlterTableArgs{
-			Dwh:               d.bigQueryStore,
-			Tc:                tc,
-			FqTableName:       fqName,
-			CreateTable:       tc.CreateTable(),
-			ColumnOp:          constants.Add,
-			CdcTime:           ts,
-			UppercaseEscNames: ptr.ToBool(false),
-			Mode:              config.Replication,
+			Dwh:              d.bigQueryStore,
+			Tc:               tc,
+			FqTableName:      fqName,
+			CreateTable:      tc.CreateTable(),
+			ColumnOp:         constants.Add,
+			CdcTime:          ts,
+			IdentifierCasing: config.PreserveCasing,
+			Mode:             config.Replication,
 		}
 
 		col := columns.NewColumn(name, kind)
 
 		assert.NoError(d.T(), alterTableArgs.AlterTable(col))
 		query, _ := d.fakeBigQueryStore.ExecArgsForCall(callIdx)
-		assert.Equal(d.T(), fmt.Sprintf("ALTER TABLE %s %s COLUMN %s %s", fqName, constants.Add, col.Name(false, &artieSQL.NameArgs{
+		assert.Equal(d.T(), fmt.Sprintf("ALTER TABLE %s %s COLUMN %s %s", fqName, constants.Add, col.Name(config.PreserveCasing, &artieSQL.NameArgs{
 			Escape:   true,
 			DestKind: d.bigQueryStore.Label(),
 		}), typing.KindToDWHType(kind, d.bigQueryStore.Label(), false)), query)
@@ -201,19 +201,19 @@ func (d *DDLTestSuite) TestAlterTableAddColumnsSomeAlreadyExist() {
 		// BQ returning the same error because the column already exists.
 		d.fakeBigQueryStore.ExecReturnsOnCall(0, sqlResult, errors.New("Column already exists: _string at [1:39]"))
 		alterTableArgs := ddl.AlterTableArgs{
-			Dwh:               d.bigQueryStore,
-			Tc:                tc,
-			FqTableName:       fqName,
-			CreateTable:       tc.CreateTable(),
-			ColumnOp:          constants.Add,
-			CdcTime:           ts,
-			UppercaseEscNames: ptr.ToBool(false),
-			Mode:              config.Replication,
+			Dwh:              d.bigQueryStore,
+			Tc:               tc,
+			FqTableName:      fqName,
+			CreateTable:      tc.CreateTable(),
+			ColumnOp:         constants.Add,
+			CdcTime:          ts,
+			IdentifierCasing: config.PreserveCasing,
+			Mode:             config.Replication,
 		}
 
 		assert.NoError(d.T(), alterTableArgs.AlterTable(column))
 		query, _ := d.fakeBigQueryStore.ExecArgsForCall(callIdx)
-		assert.Equal(d.T(), fmt.Sprintf("ALTER TABLE %s %s COLUMN %s %s", fqName, constants.Add, column.Name(false, &artieSQL.NameArgs{
+		assert.Equal(d.T(), fmt.Sprintf("ALTER TABLE %s %s COLUMN %s %s", fqName, constants.Add, column.Name(config.PreserveCasing, &artieSQL.NameArgs{
 			Escape:   true,
 			DestKind: d.bigQueryStore.Label(),
 		}), typing.KindToDWHType(column.KindDetails, d.bigQueryStore.Label(), false)), query)
@@ -250,7 +250,7 @@ func (d *DDLTestSuite) TestAlterTableDropColumnsBigQuerySafety() {
 	}
 
 	bqProjectID := d.bigQueryCfg.BigQuery.ProjectID
-	fqName := td.ToFqName(d.bigQueryStore.Label(), true, false, optimization.FqNameOpts{BigQueryProjectID: bqProjectID})
+	fqName := td.ToFqName(d.bigQueryStore.Label(), true, config.PreserveCasing, optimization.FqNameOpts{BigQueryProjectID: bqProjectID})
 	originalColumnLength := len(columnNameToKindDetailsMap)
 	d.bigQueryStore.GetConfigMap().AddTableToConfig(fqName, types.NewDwhTableConfig(&cols, nil, false, false))
 	tc := d.bigQueryStore.GetConfigMap().TableConfig(fqName)
@@ -259,14 +259,14 @@ func (d *DDLTestSuite) TestAlterTableDropColumnsBigQuerySafety() {
 	assert.Equal(d.T(), 0, len(d.bigQueryStore.GetConfigMap().TableConfig(fqName).ReadOnlyColumnsToDelete()), d.bigQueryStore.GetConfigMap().TableConfig(fqName).ReadOnlyColumnsToDelete())
 	for _, column := range cols.GetColumns() {
 		alterTableArgs := ddl.AlterTableArgs{
-			Dwh:               d.bigQueryStore,
-			Tc:                tc,
-			FqTableName:       fqName,
-			CreateTable:       tc.CreateTable(),
-			ColumnOp:          constants.Delete,
-			CdcTime:           ts,
-			UppercaseEscNames: ptr.ToBool(false),
-			Mode:              config.Replication,
+			Dwh:              d.bigQueryStore,
+			Tc:               tc,
+			FqTableName:      fqName,
+			CreateTable:      tc.CreateTable(),
+			ColumnOp:         constants.Delete,
+			CdcTime:          ts,
+			IdentifierCasing: config.PreserveCasing,
+			Mode:             config.Replication,
 		}
 		assert.NoError(d.T(), alterTableArgs.AlterTable(column))
 	}
@@ -277,14 +277,14 @@ func (d *DDLTestSuite) TestAlterTableDropColumnsBigQuerySafety() {
 	// Now try to delete again and with an increased TS. It should now be all deleted.
 	for _, column := range cols.GetColumns() {
 		alterTableArgs := ddl.AlterTableArgs{
-			Dwh:               d.bigQueryStore,
-			Tc:                tc,
-			FqTableName:       fqName,
-			CreateTable:       tc.CreateTable(),
-			ColumnOp:          constants.Delete,
-			CdcTime:           ts.Add(2 * constants.DeletionConfidencePadding),
-			UppercaseEscNames: ptr.ToBool(false),
-			Mode:              config.Replication,
+			Dwh:              d.bigQueryStore,
+			Tc:               tc,
+			FqTableName:      fqName,
+			CreateTable:      tc.CreateTable(),
+			ColumnOp:         constants.Delete,
+			CdcTime:          ts.Add(2 * constants.DeletionConfidencePadding),
+			IdentifierCasing: config.PreserveCasing,
+			Mode:             config.Replication,
 		}
 
 		assert.NoError(d.T(), alterTableArgs.AlterTable(column))
@@ -295,3 +295,93 @@ func (d *DDLTestSuite) TestAlterTableDropColumnsBigQuerySafety() {
 	assert.Equal(d.T(), 0, len(d.bigQueryStore.GetConfigMap().TableConfig(fqName).ReadOnlyColumnsToDelete()))
 	assert.Equal(d.T(), originalColumnLength, len(d.bigQueryStore.GetConfigMap().TableConfig(fqName).Columns().GetColumns()))
 }
+
+func (d *DDLTestSuite) TestCreateTableBigQueryPartitionAndCluster() {
+	createdAtKind := typing.ETime
+	createdAtKind.ExtendedTimeDetails = &ext.DateTime
+	dateKind := typing.ETime
+	dateKind.ExtendedTimeDetails = &ext.Date
+
+	cols := []columns.Column{
+		columns.NewColumn("id", typing.Integer),
+		columns.NewColumn("customer_id", typing.String),
+		columns.NewColumn("created_at", createdAtKind),
+		columns.NewColumn("created_on", dateKind),
+	}
+
+	type _testCase struct {
+		name              string
+		partitionSettings *partition.BigQuerySettings
+		clusterFields     []string
+		expectedQuery     string
+		expectedErr       string
+	}
+
+	testCases := []_testCase{
+		{
+			name:          "no partition or clustering",
+			expectedQuery: "CREATE TABLE IF NOT EXISTS mock_dataset.mock_table (id int,customer_id string,created_at timestamp,created_on date)",
+		},
+		{
+			name:              "partitioned by a timestamp column",
+			partitionSettings: &partition.BigQuerySettings{PartitionType: "time", PartitionField: "created_at", PartitionBy: "daily"},
+			expectedQuery:     "CREATE TABLE IF NOT EXISTS mock_dataset.mock_table (id int,customer_id string,created_at timestamp,created_on date) PARTITION BY DATE(created_at)",
+		},
+		{
+			name:              "partitioned by a date column",
+			partitionSettings: &partition.BigQuerySettings{PartitionType: "time", PartitionField: "created_on", PartitionBy: "daily"},
+			expectedQuery:     "CREATE TABLE IF NOT EXISTS mock_dataset.mock_table (id int,customer_id string,created_at timestamp,created_on date) PARTITION BY created_on",
+		},
+		{
+			name:              "partitioned by ingestion time and clustered",
+			partitionSettings: &partition.BigQuerySettings{PartitionType: "ingestion", PartitionBy: "daily"},
+			clusterFields:     []string{"customer_id", "id"},
+			expectedQuery:     "CREATE TABLE IF NOT EXISTS mock_dataset.mock_table (id int,customer_id string,created_at timestamp,created_on date) PARTITION BY _PARTITIONDATE CLUSTER BY customer_id, id",
+		},
+		{
+			name:          "clustered only",
+			clusterFields: []string{"customer_id"},
+			expectedQuery: "CREATE TABLE IF NOT EXISTS mock_dataset.mock_table (id int,customer_id string,created_at timestamp,created_on date) CLUSTER BY customer_id",
+		},
+		{
+			name:              "partition field does not exist",
+			partitionSettings: &partition.BigQuerySettings{PartitionType: "time", PartitionField: "updated_at", PartitionBy: "daily"},
+			expectedErr:       `failed to build table options: invalid partition field: column "updated_at" does not exist`,
+		},
+		{
+			name:          "cluster field does not exist",
+			clusterFields: []string{"customer_id", "region"},
+			expectedErr:   `failed to build table options: invalid cluster field: column "region" does not exist`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		d.SetupTest()
+		fqName := "mock_dataset.mock_table"
+		tc := types.NewDwhTableConfig(&columns.Columns{}, nil, true, false)
+		alterTableArgs := ddl.AlterTableArgs{
+			Dwh:                       d.bigQueryStore,
+			Tc:                        tc,
+			FqTableName:               fqName,
+			CreateTable:               true,
+			ColumnOp:                  constants.Add,
+			IdentifierCasing:          config.PreserveCasing,
+			Mode:                      config.Replication,
+			BigQueryPartitionSettings: testCase.partitionSettings,
+			BigQueryClusterFields:     testCase.clusterFields,
+		}
+
+		err := alterTableArgs.AlterTable(cols...)
+		if testCase.expectedErr != "" {
+			assert.ErrorContains(d.T(), err, testCase.expectedErr, testCase.name)
+			assert.Equal(d.T(), 0, d.fakeBigQueryStore.ExecCallCount(), testCase.name)
+			assert.True(d.T(), tc.CreateTable(), testCase.name)
+			continue
+		}
+
+		assert.NoError(d.T(), err, testCase.name)
+		assert.Equal(d.T(), 1, d.fakeBigQueryStore.ExecCallCount(), testCase.name)
+		query, _ := d.fakeBigQueryStore.ExecArgsForCall(0)
+		assert.Equal(d.T(), testCase.expectedQuery, query, testCase.name)
+	}
+}
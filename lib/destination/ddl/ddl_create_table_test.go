@@ -6,8 +6,6 @@ import (
 
 	"github.com/artie-labs/transfer/lib/config"
 
-	"github.com/artie-labs/transfer/lib/ptr"
-
 	"github.com/artie-labs/transfer/lib/typing/columns"
 
 	"github.com/artie-labs/transfer/lib/config/constants"
@@ -46,13 +44,13 @@ func (d *DDLTestSuite) Test_CreateTable() {
 		},
 	} {
 		alterTableArgs := ddl.AlterTableArgs{
-			Dwh:               dwhTc._dwh,
-			Tc:                dwhTc._tableConfig,
-			FqTableName:       fqName,
-			CreateTable:       dwhTc._tableConfig.CreateTable(),
-			ColumnOp:          constants.Add,
-			UppercaseEscNames: ptr.ToBool(false),
-			Mode:              config.Replication,
+			Dwh:              dwhTc._dwh,
+			Tc:               dwhTc._tableConfig,
+			FqTableName:      fqName,
+			CreateTable:      dwhTc._tableConfig.CreateTable(),
+			ColumnOp:         constants.Add,
+			IdentifierCasing: config.PreserveCasing,
+			Mode:             config.Replication,
 		}
 
 		assert.NoError(d.T(), alterTableArgs.AlterTable(columns.NewColumn("name", typing.String)))
@@ -86,6 +84,10 @@ func (d *DDLTestSuite) TestCreateTable() {
 			columns.NewColumn("array", typing.Array),
 			columns.NewColumn("struct", typing.Struct),
 		}
+		objectCols = []columns.Column{
+			columns.NewColumn("object", typing.Object),
+			columns.NewColumn("tags", typing.NewArrayKindDetails(typing.String)),
+		}
 	)
 
 	testCases := []_testCase{
@@ -104,6 +106,11 @@ func (d *DDLTestSuite) TestCreateTable() {
 			cols:          bunchOfCols,
 			expectedQuery: "CREATE TABLE IF NOT EXISTS demo.public.experiments (user_id string,enabled_boolean boolean,array array,struct variant)",
 		},
+		{
+			name:          "objects and typed arrays",
+			cols:          objectCols,
+			expectedQuery: "CREATE TABLE IF NOT EXISTS demo.public.experiments (object object,tags array)",
+		},
 	}
 
 	for index, testCase := range testCases {
@@ -112,14 +119,14 @@ func (d *DDLTestSuite) TestCreateTable() {
 		tc := d.snowflakeStagesStore.GetConfigMap().TableConfig(fqTable)
 
 		alterTableArgs := ddl.AlterTableArgs{
-			Dwh:               d.snowflakeStagesStore,
-			Tc:                tc,
-			FqTableName:       fqTable,
-			CreateTable:       tc.CreateTable(),
-			ColumnOp:          constants.Add,
-			CdcTime:           time.Now().UTC(),
-			UppercaseEscNames: ptr.ToBool(false),
-			Mode:              config.Replication,
+			Dwh:              d.snowflakeStagesStore,
+			Tc:               tc,
+			FqTableName:      fqTable,
+			CreateTable:      tc.CreateTable(),
+			ColumnOp:         constants.Add,
+			CdcTime:          time.Now().UTC(),
+			IdentifierCasing: config.PreserveCasing,
+			Mode:             config.Replication,
 		}
 
 		assert.NoError(d.T(), alterTableArgs.AlterTable(testCase.cols...), testCase.name)
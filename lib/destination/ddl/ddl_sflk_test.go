@@ -7,8 +7,6 @@ import (
 
 	"github.com/artie-labs/transfer/lib/config"
 
-	"github.com/artie-labs/transfer/lib/ptr"
-
 	"github.com/artie-labs/transfer/lib/sql"
 
 	"github.com/artie-labs/transfer/lib/typing/columns"
@@ -35,19 +33,19 @@ func (d *DDLTestSuite) TestAlterComplexObjects() {
 	tc := d.snowflakeStagesStore.GetConfigMap().TableConfig(fqTable)
 
 	alterTableArgs := ddl.AlterTableArgs{
-		Dwh:               d.snowflakeStagesStore,
-		Tc:                tc,
-		FqTableName:       fqTable,
-		ColumnOp:          constants.Add,
-		CdcTime:           time.Now().UTC(),
-		UppercaseEscNames: ptr.ToBool(false),
-		Mode:              config.Replication,
+		Dwh:              d.snowflakeStagesStore,
+		Tc:               tc,
+		FqTableName:      fqTable,
+		ColumnOp:         constants.Add,
+		CdcTime:          time.Now().UTC(),
+		IdentifierCasing: config.PreserveCasing,
+		Mode:             config.Replication,
 	}
 
 	assert.NoError(d.T(), alterTableArgs.AlterTable(cols...))
 	for i := 0; i < len(cols); i++ {
 		execQuery, _ := d.fakeSnowflakeStagesStore.ExecArgsForCall(i)
-		assert.Equal(d.T(), fmt.Sprintf("ALTER TABLE %s add COLUMN %s %s", fqTable, cols[i].Name(false, &sql.NameArgs{
+		assert.Equal(d.T(), fmt.Sprintf("ALTER TABLE %s add COLUMN %s %s", fqTable, cols[i].Name(config.PreserveCasing, &sql.NameArgs{
 			Escape:   true,
 			DestKind: d.snowflakeStagesStore.Label(),
 		}),
@@ -71,13 +69,13 @@ func (d *DDLTestSuite) TestAlterIdempotency() {
 
 	d.fakeSnowflakeStagesStore.ExecReturns(nil, errors.New("column 'order_name' already exists"))
 	alterTableArgs := ddl.AlterTableArgs{
-		Dwh:               d.snowflakeStagesStore,
-		Tc:                tc,
-		FqTableName:       fqTable,
-		ColumnOp:          constants.Add,
-		CdcTime:           time.Now().UTC(),
-		UppercaseEscNames: ptr.ToBool(false),
-		Mode:              config.Replication,
+		Dwh:              d.snowflakeStagesStore,
+		Tc:               tc,
+		FqTableName:      fqTable,
+		ColumnOp:         constants.Add,
+		CdcTime:          time.Now().UTC(),
+		IdentifierCasing: config.PreserveCasing,
+		Mode:             config.Replication,
 	}
 
 	assert.NoError(d.T(), alterTableArgs.AlterTable(cols...))
@@ -101,13 +99,13 @@ func (d *DDLTestSuite) TestAlterTableAdd() {
 	tc := d.snowflakeStagesStore.GetConfigMap().TableConfig(fqTable)
 
 	alterTableArgs := ddl.AlterTableArgs{
-		Dwh:               d.snowflakeStagesStore,
-		Tc:                tc,
-		FqTableName:       fqTable,
-		ColumnOp:          constants.Add,
-		CdcTime:           time.Now().UTC(),
-		UppercaseEscNames: ptr.ToBool(false),
-		Mode:              config.Replication,
+		Dwh:              d.snowflakeStagesStore,
+		Tc:               tc,
+		FqTableName:      fqTable,
+		ColumnOp:         constants.Add,
+		CdcTime:          time.Now().UTC(),
+		IdentifierCasing: config.PreserveCasing,
+		Mode:             config.Replication,
 	}
 
 	assert.NoError(d.T(), alterTableArgs.AlterTable(cols...))
@@ -130,6 +128,39 @@ func (d *DDLTestSuite) TestAlterTableAdd() {
 	}
 }
 
+func (d *DDLTestSuite) TestAlterTableAddCaseInsensitive() {
+	var existingCols columns.Columns
+	existingCols.SetCaseInsensitive(true)
+	existingCols.AddColumn(columns.NewColumn("email", typing.String))
+
+	fqTable := "shop.public.customers"
+	d.snowflakeStagesStore.GetConfigMap().AddTableToConfig(fqTable, types.NewDwhTableConfig(&existingCols, nil, false, true))
+	tc := d.snowflakeStagesStore.GetConfigMap().TableConfig(fqTable)
+
+	alterTableArgs := ddl.AlterTableArgs{
+		Dwh:              d.snowflakeStagesStore,
+		Tc:               tc,
+		FqTableName:      fqTable,
+		ColumnOp:         constants.Add,
+		CdcTime:          time.Now().UTC(),
+		IdentifierCasing: config.PreserveCasing,
+		Mode:             config.Replication,
+	}
+
+	// `Email` matches the existing `email` column, so only `name` should be added.
+	assert.NoError(d.T(), alterTableArgs.AlterTable(columns.NewColumn("Email", typing.String), columns.NewColumn("name", typing.String)))
+	assert.Equal(d.T(), 1, d.fakeSnowflakeStagesStore.ExecCallCount())
+	execQuery, _ := d.fakeSnowflakeStagesStore.ExecArgsForCall(0)
+	assert.Equal(d.T(), "ALTER TABLE shop.public.customers add COLUMN name string", execQuery)
+
+	var colNames []string
+	for _, col := range tc.Columns().GetColumns() {
+		colNames = append(colNames, col.RawName())
+	}
+
+	assert.Equal(d.T(), []string{"email", "name"}, colNames)
+}
+
 func (d *DDLTestSuite) TestAlterTableDeleteDryRun() {
 	// Test adding a bunch of columns
 	cols := []columns.Column{
@@ -149,7 +180,7 @@ func (d *DDLTestSuite) TestAlterTableDeleteDryRun() {
 		ContainOtherOperations: true,
 		ColumnOp:               constants.Delete,
 		CdcTime:                time.Now().UTC(),
-		UppercaseEscNames:      ptr.ToBool(false),
+		IdentifierCasing:       config.PreserveCasing,
 		Mode:                   config.Replication,
 	}
 
@@ -183,7 +214,7 @@ func (d *DDLTestSuite) TestAlterTableDeleteDryRun() {
 
 		execArg, _ := d.fakeSnowflakeStagesStore.ExecArgsForCall(i)
 		assert.Equal(d.T(), execArg, fmt.Sprintf("ALTER TABLE %s %s COLUMN %s", fqTable, constants.Delete,
-			cols[i].Name(false, &sql.NameArgs{Escape: true, DestKind: d.snowflakeStagesStore.Label()})))
+			cols[i].Name(config.PreserveCasing, &sql.NameArgs{Escape: true, DestKind: d.snowflakeStagesStore.Label()})))
 	}
 }
 
@@ -214,7 +245,7 @@ func (d *DDLTestSuite) TestAlterTableDelete() {
 		ColumnOp:               constants.Delete,
 		ContainOtherOperations: true,
 		CdcTime:                time.Now(),
-		UppercaseEscNames:      ptr.ToBool(false),
+		IdentifierCasing:       config.PreserveCasing,
 		Mode:                   config.Replication,
 	}
 
@@ -5,8 +5,6 @@ import (
 
 	"github.com/artie-labs/transfer/lib/config"
 
-	"github.com/artie-labs/transfer/lib/ptr"
-
 	"github.com/artie-labs/transfer/lib/typing/columns"
 
 	"github.com/artie-labs/transfer/lib/config/constants"
@@ -18,10 +16,10 @@ import (
 
 func (d *DDLTestSuite) TestValidate_AlterTableArgs() {
 	a := &ddl.AlterTableArgs{
-		ColumnOp:          constants.Delete,
-		CreateTable:       true,
-		UppercaseEscNames: ptr.ToBool(false),
-		Mode:              config.Replication,
+		ColumnOp:         constants.Delete,
+		CreateTable:      true,
+		IdentifierCasing: config.PreserveCasing,
+		Mode:             config.Replication,
 	}
 
 	assert.Contains(d.T(), a.Validate().Error(), "incompatible operation - cannot drop columns and create table at the same time")
@@ -37,15 +35,15 @@ func (d *DDLTestSuite) TestCreateTemporaryTable_Errors() {
 	d.snowflakeStagesStore.GetConfigMap().AddTableToConfig(fqName, types.NewDwhTableConfig(&columns.Columns{}, nil, true, true))
 	snowflakeTc := d.snowflakeStagesStore.GetConfigMap().TableConfig(fqName)
 	args := ddl.AlterTableArgs{
-		Dwh:               d.snowflakeStagesStore,
-		Tc:                snowflakeTc,
-		FqTableName:       fqName,
-		CreateTable:       true,
-		TemporaryTable:    true,
-		ColumnOp:          constants.Add,
-		CdcTime:           time.Time{},
-		UppercaseEscNames: ptr.ToBool(false),
-		Mode:              config.Replication,
+		Dwh:              d.snowflakeStagesStore,
+		Tc:               snowflakeTc,
+		FqTableName:      fqName,
+		CreateTable:      true,
+		TemporaryTable:   true,
+		ColumnOp:         constants.Add,
+		CdcTime:          time.Time{},
+		IdentifierCasing: config.PreserveCasing,
+		Mode:             config.Replication,
 	}
 
 	// No columns.
@@ -70,15 +68,15 @@ func (d *DDLTestSuite) TestCreateTemporaryTable() {
 	d.snowflakeStagesStore.GetConfigMap().AddTableToConfig(fqName, types.NewDwhTableConfig(&columns.Columns{}, nil, true, true))
 	sflkStageTc := d.snowflakeStagesStore.GetConfigMap().TableConfig(fqName)
 	args := ddl.AlterTableArgs{
-		Dwh:               d.snowflakeStagesStore,
-		Tc:                sflkStageTc,
-		FqTableName:       fqName,
-		CreateTable:       true,
-		TemporaryTable:    true,
-		ColumnOp:          constants.Add,
-		CdcTime:           time.Time{},
-		UppercaseEscNames: ptr.ToBool(false),
-		Mode:              config.Replication,
+		Dwh:              d.snowflakeStagesStore,
+		Tc:               sflkStageTc,
+		FqTableName:      fqName,
+		CreateTable:      true,
+		TemporaryTable:   true,
+		ColumnOp:         constants.Add,
+		CdcTime:          time.Time{},
+		IdentifierCasing: config.PreserveCasing,
+		Mode:             config.Replication,
 	}
 
 	assert.NoError(d.T(), args.AlterTable(columns.NewColumn("foo", typing.String), columns.NewColumn("bar", typing.Float), columns.NewColumn("start", typing.String)))
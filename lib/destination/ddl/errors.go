@@ -16,9 +16,13 @@ func ColumnAlreadyExistErr(err error, kind constants.DestinationKind) bool {
 		// Error ends up looking like something like this: Column already exists: _string at [1:39]
 		return strings.Contains(err.Error(), "Column already exists")
 
-	case constants.Snowflake, constants.Redshift:
+	case constants.Databricks:
+		// [FIELDS_ALREADY_EXISTS] Cannot add column, because `foo` already exists
+		return strings.Contains(err.Error(), "FIELDS_ALREADY_EXISTS")
+	case constants.Snowflake, constants.Redshift, constants.DuckDB:
 		// Snowflake doesn't have column mutations (IF NOT EXISTS)
 		// Redshift's error: ERROR: column "foo" of relation "statement" already exists
+		// DuckDB's error: Catalog Error: Column with name foo already exists!
 		return strings.Contains(err.Error(), "already exists")
 	case constants.MSSQL:
 		alreadyExistErrs := []string{
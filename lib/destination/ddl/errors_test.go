@@ -46,6 +46,12 @@ func (d *DDLTestSuite) TestColumnAlreadyExistErr() {
 			err:  fmt.Errorf("hello there qux"),
 			kind: constants.MSSQL,
 		},
+		{
+			name:           "DuckDB, column already exists error",
+			err:            fmt.Errorf(`Catalog Error: Column with name foo already exists!`),
+			kind:           constants.DuckDB,
+			expectedResult: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -5,9 +5,17 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
 )
 
 func ShouldDeleteFromName(name string) bool {
+	return ShouldDeleteFromNameWithTTL(name, constants.TemporaryTableTTL)
+}
+
+// ShouldDeleteFromNameWithTTL returns true if the temporary table was created more than `ttl` ago.
+// The name contains the table's expiry, which is [constants.TemporaryTableTTL] after it was created.
+func ShouldDeleteFromNameWithTTL(name string, ttl time.Duration) bool {
 	nameParts := strings.Split(name, "_")
 	if len(nameParts) < 2 {
 		return false
@@ -20,6 +28,6 @@ func ShouldDeleteFromName(name string) bool {
 		return false
 	}
 
-	ts := time.Unix(int64(unix), 0)
+	ts := time.Unix(int64(unix), 0).Add(ttl - constants.TemporaryTableTTL)
 	return time.Now().UTC().After(ts)
 }
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
 )
 
 func TestShouldDeleteFromName(t *testing.T) {
@@ -28,3 +30,11 @@ func TestShouldDeleteFromName(t *testing.T) {
 		assert.True(t, ShouldDeleteFromName(tblToDelete), tblToDelete)
 	}
 }
+
+func TestShouldDeleteFromNameWithTTL(t *testing.T) {
+	// Created an hour ago
+	name := fmt.Sprintf("tbl__artie_%d", time.Now().Add(constants.TemporaryTableTTL-time.Hour).Unix())
+	assert.False(t, ShouldDeleteFromName(name))
+	assert.False(t, ShouldDeleteFromNameWithTTL(name, 2*time.Hour))
+	assert.True(t, ShouldDeleteFromNameWithTTL(name, 30*time.Minute))
+}
@@ -3,6 +3,7 @@ package dml
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/artie-labs/transfer/lib/sql"
@@ -14,6 +15,7 @@ import (
 	"github.com/artie-labs/transfer/lib/typing"
 
 	"github.com/artie-labs/transfer/lib/array"
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
 )
 
@@ -31,10 +33,17 @@ type MergeArgument struct {
 
 	DestKind   constants.DestinationKind
 	SoftDelete bool
-	// ContainsHardDeletes is only used for Redshift and MergeStatementParts,
+	// OmitDeleteColumn is only used with SoftDelete, it means the destination table does not have the `__artie_delete` column (e.g. it only has `__artie_deleted_at`).
+	OmitDeleteColumn bool
+	// ContainsHardDeletes is only used for Redshift, DuckDB and MergeStatementParts,
 	// where we do not issue a DELETE statement if there are no hard deletes in the batch
 	ContainsHardDeletes *bool
-	UppercaseEscNames   *bool
+	IdentifierCasing    config.IdentifierCasing
+	// ImmutableColumns are left out of the UPDATE SET clause, so their value is only written when the row is inserted.
+	ImmutableColumns []string
+	// PartialUpdateColumns is optional, if it's set, only these columns will be in the UPDATE SET clause.
+	// This is used for partial updates, where the rows only carried a subset of the columns.
+	PartialUpdateColumns []string
 }
 
 func (m *MergeArgument) Valid() error {
@@ -54,8 +63,8 @@ func (m *MergeArgument) Valid() error {
 		return fmt.Errorf("one of these arguments is empty: fqTableName, subQuery")
 	}
 
-	if m.UppercaseEscNames == nil {
-		return fmt.Errorf("uppercaseEscNames cannot be nil")
+	if err := m.IdentifierCasing.Validate(); err != nil {
+		return err
 	}
 
 	if !constants.IsValidDestination(m.DestKind) {
@@ -70,11 +79,11 @@ func (m *MergeArgument) GetParts() ([]string, error) {
 		return nil, err
 	}
 
-	if m.DestKind != constants.Redshift {
-		return nil, fmt.Errorf("err - this is meant for redshift only")
+	if m.DestKind != constants.Redshift && m.DestKind != constants.DuckDB {
+		return nil, fmt.Errorf("err - this is meant for redshift and duckdb only")
 	}
 
-	// ContainsHardDeletes is only used for Redshift, so we'll validate it now
+	// ContainsHardDeletes is only used with merge parts, so we'll validate it now
 	if m.ContainsHardDeletes == nil {
 		return nil, fmt.Errorf("containsHardDeletes cannot be nil")
 	}
@@ -98,12 +107,16 @@ func (m *MergeArgument) GetParts() ([]string, error) {
 		equalitySQLParts = append(equalitySQLParts, equalitySQL)
 	}
 
-	cols := m.Columns.GetColumnsToUpdate(*m.UppercaseEscNames, &sql.NameArgs{
+	cols := m.Columns.GetColumnsToUpdate(m.IdentifierCasing, &sql.NameArgs{
 		Escape:   true,
 		DestKind: m.DestKind,
 	})
 
 	if m.SoftDelete {
+		if m.OmitDeleteColumn {
+			cols, _ = removeDeleteColumn(cols)
+		}
+
 		return []string{
 			// INSERT
 			fmt.Sprintf(`INSERT INTO %s (%s) SELECT %s FROM %s as cc LEFT JOIN %s as c on %s WHERE c.%s IS NULL;`,
@@ -122,7 +135,7 @@ func (m *MergeArgument) GetParts() ([]string, error) {
 			// UPDATE
 			fmt.Sprintf(`UPDATE %s as c SET %s FROM %s as cc WHERE %s%s;`,
 				// UPDATE table set col1 = cc. col1
-				m.FqTableName, m.Columns.UpdateQuery(m.DestKind, *m.UppercaseEscNames, false),
+				m.FqTableName, m.updateQuery(m.OmitDeleteColumn),
 				// FROM table (temp) WHERE join on PK(s)
 				m.SubQuery, strings.Join(equalitySQLParts, " and "), idempotentClause,
 			),
@@ -130,15 +143,7 @@ func (m *MergeArgument) GetParts() ([]string, error) {
 	}
 
 	// We also need to remove __artie flags since it does not exist in the destination table
-	var removed bool
-	for idx, col := range cols {
-		if col == constants.DeleteColumnMarker {
-			cols = append(cols[:idx], cols[idx+1:]...)
-			removed = true
-			break
-		}
-	}
-
+	cols, removed := removeDeleteColumn(cols)
 	if !removed {
 		return nil, errors.New("artie delete flag doesn't exist")
 	}
@@ -166,13 +171,23 @@ func (m *MergeArgument) GetParts() ([]string, error) {
 		// UPDATE
 		fmt.Sprintf(`UPDATE %s as c SET %s FROM %s as cc WHERE %s%s AND COALESCE(cc.%s, false) = false;`,
 			// UPDATE table set col1 = cc. col1
-			m.FqTableName, m.Columns.UpdateQuery(m.DestKind, *m.UppercaseEscNames, true),
+			m.FqTableName, m.updateQuery(true),
 			// FROM staging WHERE join on PK(s)
 			m.SubQuery, strings.Join(equalitySQLParts, " and "), idempotentClause, constants.DeleteColumnMarker,
 		),
 	}
 
-	if *m.ContainsHardDeletes {
+	if *m.ContainsHardDeletes && m.DestKind == constants.DuckDB {
+		// DuckDB does not support multi-column IN subqueries, so we'll join against the staging table instead.
+		parts = append(parts,
+			// DELETE
+			fmt.Sprintf(`DELETE FROM %s as c USING %s as cc WHERE %s AND cc.%s = true;`,
+				// DELETE from table using staging
+				m.FqTableName, m.SubQuery,
+				// WHERE join on PK(s)
+				strings.Join(equalitySQLParts, " and "), constants.DeleteColumnMarker,
+			))
+	} else if *m.ContainsHardDeletes {
 		parts = append(parts,
 			// DELETE
 			fmt.Sprintf(`DELETE FROM %s WHERE (%s) IN (SELECT %s FROM %s as cc WHERE cc.%s = true);`,
@@ -233,19 +248,23 @@ func (m *MergeArgument) GetStatement() (string, error) {
 		}
 	}
 
-	cols := m.Columns.GetColumnsToUpdate(*m.UppercaseEscNames, &sql.NameArgs{
+	cols := m.Columns.GetColumnsToUpdate(m.IdentifierCasing, &sql.NameArgs{
 		Escape:   true,
 		DestKind: m.DestKind,
 	})
 
 	if m.SoftDelete {
+		if m.OmitDeleteColumn {
+			cols, _ = removeDeleteColumn(cols)
+		}
+
 		return fmt.Sprintf(`
 MERGE INTO %s c USING %s AS cc ON %s
 WHEN MATCHED %sTHEN UPDATE SET %s
 WHEN NOT MATCHED AND IFNULL(cc.%s, false) = false THEN INSERT (%s) VALUES (%s);`,
 			m.FqTableName, subQuery, strings.Join(equalitySQLParts, " and "),
 			// Update + Soft Deletion
-			idempotentClause, m.Columns.UpdateQuery(m.DestKind, *m.UppercaseEscNames, false),
+			idempotentClause, m.updateQuery(m.OmitDeleteColumn),
 			// Insert
 			constants.DeleteColumnMarker, strings.Join(cols, ","),
 			array.StringsJoinAddPrefix(array.StringsJoinAddPrefixArgs{
@@ -256,15 +275,7 @@ WHEN NOT MATCHED AND IFNULL(cc.%s, false) = false THEN INSERT (%s) VALUES (%s);`
 	}
 
 	// We also need to remove __artie flags since it does not exist in the destination table
-	var removed bool
-	for idx, col := range cols {
-		if col == constants.DeleteColumnMarker {
-			cols = append(cols[:idx], cols[idx+1:]...)
-			removed = true
-			break
-		}
-	}
-
+	cols, removed := removeDeleteColumn(cols)
 	if !removed {
 		return "", errors.New("artie delete flag doesn't exist")
 	}
@@ -278,7 +289,7 @@ WHEN NOT MATCHED AND IFNULL(cc.%s, false) = false THEN INSERT (%s) VALUES (%s);`
 		// Delete
 		constants.DeleteColumnMarker,
 		// Update
-		constants.DeleteColumnMarker, idempotentClause, m.Columns.UpdateQuery(m.DestKind, *m.UppercaseEscNames, true),
+		constants.DeleteColumnMarker, idempotentClause, m.updateQuery(true),
 		// Insert
 		constants.DeleteColumnMarker, strings.Join(cols, ","),
 		array.StringsJoinAddPrefix(array.StringsJoinAddPrefixArgs{
@@ -305,12 +316,16 @@ func (m *MergeArgument) GetMSSQLStatement() (string, error) {
 		equalitySQLParts = append(equalitySQLParts, equalitySQL)
 	}
 
-	cols := m.Columns.GetColumnsToUpdate(*m.UppercaseEscNames, &sql.NameArgs{
+	cols := m.Columns.GetColumnsToUpdate(m.IdentifierCasing, &sql.NameArgs{
 		Escape:   true,
 		DestKind: m.DestKind,
 	})
 
 	if m.SoftDelete {
+		if m.OmitDeleteColumn {
+			cols, _ = removeDeleteColumn(cols)
+		}
+
 		return fmt.Sprintf(`
 MERGE INTO %s c
 USING %s AS cc ON %s
@@ -318,7 +333,7 @@ WHEN MATCHED %sTHEN UPDATE SET %s
 WHEN NOT MATCHED AND COALESCE(cc.%s, 0) = 0 THEN INSERT (%s) VALUES (%s);`,
 			m.FqTableName, m.SubQuery, strings.Join(equalitySQLParts, " and "),
 			// Update + Soft Deletion
-			idempotentClause, m.Columns.UpdateQuery(m.DestKind, *m.UppercaseEscNames, false),
+			idempotentClause, m.updateQuery(m.OmitDeleteColumn),
 			// Insert
 			constants.DeleteColumnMarker, strings.Join(cols, ","),
 			array.StringsJoinAddPrefix(array.StringsJoinAddPrefixArgs{
@@ -329,15 +344,7 @@ WHEN NOT MATCHED AND COALESCE(cc.%s, 0) = 0 THEN INSERT (%s) VALUES (%s);`,
 	}
 
 	// We also need to remove __artie flags since it does not exist in the destination table
-	var removed bool
-	for idx, col := range cols {
-		if col == constants.DeleteColumnMarker {
-			cols = append(cols[:idx], cols[idx+1:]...)
-			removed = true
-			break
-		}
-	}
-
+	cols, removed := removeDeleteColumn(cols)
 	if !removed {
 		return "", errors.New("artie delete flag doesn't exist")
 	}
@@ -352,7 +359,7 @@ WHEN NOT MATCHED AND COALESCE(cc.%s, 1) = 0 THEN INSERT (%s) VALUES (%s);`,
 		// Delete
 		constants.DeleteColumnMarker,
 		// Update
-		constants.DeleteColumnMarker, idempotentClause, m.Columns.UpdateQuery(m.DestKind, *m.UppercaseEscNames, true),
+		constants.DeleteColumnMarker, idempotentClause, m.updateQuery(true),
 		// Insert
 		constants.DeleteColumnMarker, strings.Join(cols, ","),
 		array.StringsJoinAddPrefix(array.StringsJoinAddPrefixArgs{
@@ -361,3 +368,40 @@ WHEN NOT MATCHED AND COALESCE(cc.%s, 1) = 0 THEN INSERT (%s) VALUES (%s);`,
 			Prefix:    "cc.",
 		})), nil
 }
+
+// updateQuery returns the UPDATE SET clause without the immutable columns (and the columns that were not carried for partial updates).
+func (m *MergeArgument) updateQuery(skipDeleteCol bool) string {
+	if len(m.ImmutableColumns) == 0 && len(m.PartialUpdateColumns) == 0 {
+		return m.Columns.UpdateQuery(m.DestKind, m.IdentifierCasing, skipDeleteCol)
+	}
+
+	var cols columns.Columns
+	for _, col := range m.Columns.GetColumns() {
+		matchesCol := func(colName string) bool {
+			return strings.EqualFold(colName, col.RawName())
+		}
+
+		if slices.ContainsFunc(m.ImmutableColumns, matchesCol) {
+			continue
+		}
+
+		if len(m.PartialUpdateColumns) > 0 && !slices.ContainsFunc(m.PartialUpdateColumns, matchesCol) {
+			continue
+		}
+
+		cols.AddColumn(col)
+	}
+
+	return cols.UpdateQuery(m.DestKind, m.IdentifierCasing, skipDeleteCol)
+}
+
+// removeDeleteColumn returns `cols` without the delete marker, it will return false if the delete marker does not exist.
+func removeDeleteColumn(cols []string) ([]string, bool) {
+	for idx, col := range cols {
+		if col == constants.DeleteColumnMarker {
+			return append(cols[:idx:idx], cols[idx+1:]...), true
+		}
+	}
+
+	return cols, false
+}
@@ -3,8 +3,8 @@ package dml
 import (
 	"testing"
 
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
-	"github.com/artie-labs/transfer/lib/ptr"
 	"github.com/artie-labs/transfer/lib/typing"
 	"github.com/artie-labs/transfer/lib/typing/columns"
 	"github.com/stretchr/testify/assert"
@@ -17,13 +17,13 @@ func TestMergeStatement_TempTable(t *testing.T) {
 	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
 
 	mergeArg := &MergeArgument{
-		FqTableName:       "customers.orders",
-		SubQuery:          "customers.orders_tmp",
-		PrimaryKeys:       []columns.Wrapper{columns.NewWrapper(columns.NewColumn("order_id", typing.Invalid), false, nil)},
-		Columns:           &cols,
-		DestKind:          constants.BigQuery,
-		SoftDelete:        false,
-		UppercaseEscNames: ptr.ToBool(false),
+		FqTableName:      "customers.orders",
+		SubQuery:         "customers.orders_tmp",
+		PrimaryKeys:      []columns.Wrapper{columns.NewWrapper(columns.NewColumn("order_id", typing.Invalid), config.PreserveCasing, nil)},
+		Columns:          &cols,
+		DestKind:         constants.BigQuery,
+		SoftDelete:       false,
+		IdentifierCasing: config.PreserveCasing,
 	}
 
 	mergeSQL, err := mergeArg.GetStatement()
@@ -39,13 +39,13 @@ func TestMergeStatement_JSONKey(t *testing.T) {
 	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
 
 	mergeArg := &MergeArgument{
-		FqTableName:       "customers.orders",
-		SubQuery:          "customers.orders_tmp",
-		PrimaryKeys:       []columns.Wrapper{columns.NewWrapper(columns.NewColumn("order_oid", typing.Invalid), false, nil)},
-		Columns:           &cols,
-		DestKind:          constants.BigQuery,
-		SoftDelete:        false,
-		UppercaseEscNames: ptr.ToBool(false),
+		FqTableName:      "customers.orders",
+		SubQuery:         "customers.orders_tmp",
+		PrimaryKeys:      []columns.Wrapper{columns.NewWrapper(columns.NewColumn("order_oid", typing.Invalid), config.PreserveCasing, nil)},
+		Columns:          &cols,
+		DestKind:         constants.BigQuery,
+		SoftDelete:       false,
+		IdentifierCasing: config.PreserveCasing,
 	}
 
 	mergeSQL, err := mergeArg.GetStatement()
@@ -0,0 +1,55 @@
+package dml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func TestMergeStatement_Databricks(t *testing.T) {
+	fqTable := "main.public.orders"
+	cols := []string{"id", "group", "updated_at", constants.DeleteColumnMarker}
+	var _cols columns.Columns
+	for _, col := range cols {
+		kd := typing.String
+		if col == constants.DeleteColumnMarker {
+			kd = typing.Boolean
+		}
+
+		_cols.AddColumn(columns.NewColumn(col, kd))
+	}
+
+	mergeArg := MergeArgument{
+		FqTableName:      fqTable,
+		SubQuery:         "main.public.orders___artie_abcde",
+		IdempotentKey:    "updated_at",
+		PrimaryKeys:      []columns.Wrapper{columns.NewWrapper(columns.NewColumn("id", typing.Invalid), config.PreserveCasing, &sql.NameArgs{Escape: true, DestKind: constants.Databricks})},
+		Columns:          &_cols,
+		DestKind:         constants.Databricks,
+		IdentifierCasing: config.PreserveCasing,
+	}
+
+	{
+		// Hard deletes
+		mergeSQL, err := mergeArg.GetStatement()
+		assert.NoError(t, err)
+		assert.Equal(t, "\nMERGE INTO main.public.orders c USING ( main.public.orders___artie_abcde ) AS cc ON c.id = cc.id\n"+
+			"WHEN MATCHED AND cc.__artie_delete THEN DELETE\n"+
+			"WHEN MATCHED AND IFNULL(cc.__artie_delete, false) = false AND cc.updated_at >= c.updated_at THEN UPDATE SET id=cc.id,`group`=cc.`group`,updated_at=cc.updated_at\n"+
+			"WHEN NOT MATCHED AND IFNULL(cc.__artie_delete, false) = false THEN INSERT (id,`group`,updated_at) VALUES (cc.id,cc.`group`,cc.updated_at);", mergeSQL)
+	}
+	{
+		// Soft deletes
+		mergeArg.SoftDelete = true
+		mergeSQL, err := mergeArg.GetStatement()
+		assert.NoError(t, err)
+		assert.Contains(t, mergeSQL, "WHEN MATCHED AND cc.updated_at >= c.updated_at THEN UPDATE SET id=cc.id,`group`=cc.`group`,updated_at=cc.updated_at,__artie_delete=cc.__artie_delete", mergeSQL)
+		assert.NotContains(t, mergeSQL, "THEN DELETE", mergeSQL)
+	}
+}
@@ -6,8 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
-	"github.com/artie-labs/transfer/lib/ptr"
 	"github.com/artie-labs/transfer/lib/typing"
 	"github.com/artie-labs/transfer/lib/typing/columns"
 	"github.com/stretchr/testify/assert"
@@ -41,14 +41,14 @@ func Test_GetMSSQLStatement(t *testing.T) {
 		strings.Join(cols, ","), strings.Join(tableValues, ","), "_tbl", strings.Join(cols, ","))
 
 	mergeArg := MergeArgument{
-		FqTableName:       fqTable,
-		SubQuery:          subQuery,
-		IdempotentKey:     "",
-		PrimaryKeys:       []columns.Wrapper{columns.NewWrapper(columns.NewColumn("id", typing.Invalid), false, nil)},
-		Columns:           &_cols,
-		DestKind:          constants.MSSQL,
-		SoftDelete:        false,
-		UppercaseEscNames: ptr.ToBool(false),
+		FqTableName:      fqTable,
+		SubQuery:         subQuery,
+		IdempotentKey:    "",
+		PrimaryKeys:      []columns.Wrapper{columns.NewWrapper(columns.NewColumn("id", typing.Invalid), config.PreserveCasing, nil)},
+		Columns:          &_cols,
+		DestKind:         constants.MSSQL,
+		SoftDelete:       false,
+		IdentifierCasing: config.PreserveCasing,
 	}
 
 	mergeSQL, err := mergeArg.GetMSSQLStatement()
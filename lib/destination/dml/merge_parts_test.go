@@ -3,6 +3,7 @@ package dml
 import (
 	"testing"
 
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/ptr"
 	"github.com/artie-labs/transfer/lib/sql"
@@ -32,7 +33,7 @@ type result struct {
 // getBasicColumnsForTest - will return you all the columns within `result` that are needed for tests.
 // * In here, we'll return if compositeKey=false - id (pk), email, first_name, last_name, created_at, toast_text (TOAST-able)
 // * Else if compositeKey=true - id(pk), email (pk), first_name, last_name, created_at, toast_text (TOAST-able)
-func getBasicColumnsForTest(compositeKey bool, uppercaseEscNames bool) result {
+func getBasicColumnsForTest(compositeKey bool, casing config.IdentifierCasing) result {
 	idCol := columns.NewColumn("id", typing.Float)
 	emailCol := columns.NewColumn("email", typing.String)
 	textToastCol := columns.NewColumn("toast_text", typing.String)
@@ -48,13 +49,13 @@ func getBasicColumnsForTest(compositeKey bool, uppercaseEscNames bool) result {
 	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
 
 	var pks []columns.Wrapper
-	pks = append(pks, columns.NewWrapper(idCol, uppercaseEscNames, &sql.NameArgs{
+	pks = append(pks, columns.NewWrapper(idCol, casing, &sql.NameArgs{
 		Escape:   true,
 		DestKind: constants.Redshift,
 	}))
 
 	if compositeKey {
-		pks = append(pks, columns.NewWrapper(emailCol, uppercaseEscNames, &sql.NameArgs{
+		pks = append(pks, columns.NewWrapper(emailCol, casing, &sql.NameArgs{
 			Escape:   true,
 			DestKind: constants.Redshift,
 		}))
@@ -72,7 +73,7 @@ func TestMergeStatementParts_SkipDelete(t *testing.T) {
 	// 2. There are 3 SQL queries (INSERT, UPDATE and DELETE)
 	fqTableName := "public.tableName"
 	tempTableName := "public.tableName__temp"
-	res := getBasicColumnsForTest(false, false)
+	res := getBasicColumnsForTest(false, config.PreserveCasing)
 	mergeArg := &MergeArgument{
 		FqTableName:         fqTableName,
 		SubQuery:            tempTableName,
@@ -80,7 +81,7 @@ func TestMergeStatementParts_SkipDelete(t *testing.T) {
 		Columns:             &res.ColumnsToTypes,
 		DestKind:            constants.Redshift,
 		ContainsHardDeletes: ptr.ToBool(false),
-		UppercaseEscNames:   ptr.ToBool(false),
+		IdentifierCasing:    config.PreserveCasing,
 	}
 
 	parts, err := mergeArg.GetParts()
@@ -99,7 +100,7 @@ func TestMergeStatementParts_SkipDelete(t *testing.T) {
 func TestMergeStatementPartsSoftDelete(t *testing.T) {
 	fqTableName := "public.tableName"
 	tempTableName := "public.tableName__temp"
-	res := getBasicColumnsForTest(false, false)
+	res := getBasicColumnsForTest(false, config.PreserveCasing)
 	mergeArg := &MergeArgument{
 		FqTableName:         fqTableName,
 		SubQuery:            tempTableName,
@@ -107,7 +108,7 @@ func TestMergeStatementPartsSoftDelete(t *testing.T) {
 		Columns:             &res.ColumnsToTypes,
 		DestKind:            constants.Redshift,
 		SoftDelete:          true,
-		UppercaseEscNames:   ptr.ToBool(false),
+		IdentifierCasing:    config.PreserveCasing,
 		ContainsHardDeletes: ptr.ToBool(false),
 	}
 
@@ -139,7 +140,7 @@ func TestMergeStatementPartsSoftDelete(t *testing.T) {
 func TestMergeStatementPartsSoftDeleteComposite(t *testing.T) {
 	fqTableName := "public.tableName"
 	tempTableName := "public.tableName__temp"
-	res := getBasicColumnsForTest(true, false)
+	res := getBasicColumnsForTest(true, config.PreserveCasing)
 	mergeArg := &MergeArgument{
 		FqTableName:         fqTableName,
 		SubQuery:            tempTableName,
@@ -147,7 +148,7 @@ func TestMergeStatementPartsSoftDeleteComposite(t *testing.T) {
 		Columns:             &res.ColumnsToTypes,
 		DestKind:            constants.Redshift,
 		SoftDelete:          true,
-		UppercaseEscNames:   ptr.ToBool(false),
+		IdentifierCasing:    config.PreserveCasing,
 		ContainsHardDeletes: ptr.ToBool(false),
 	}
 
@@ -182,7 +183,7 @@ func TestMergeStatementParts(t *testing.T) {
 	// 2. There are 3 SQL queries (INSERT, UPDATE and DELETE)
 	fqTableName := "public.tableName"
 	tempTableName := "public.tableName__temp"
-	res := getBasicColumnsForTest(false, false)
+	res := getBasicColumnsForTest(false, config.PreserveCasing)
 	mergeArg := &MergeArgument{
 		FqTableName:         fqTableName,
 		SubQuery:            tempTableName,
@@ -190,7 +191,7 @@ func TestMergeStatementParts(t *testing.T) {
 		Columns:             &res.ColumnsToTypes,
 		DestKind:            constants.Redshift,
 		ContainsHardDeletes: ptr.ToBool(true),
-		UppercaseEscNames:   ptr.ToBool(false),
+		IdentifierCasing:    config.PreserveCasing,
 	}
 
 	parts, err := mergeArg.GetParts()
@@ -217,7 +218,7 @@ func TestMergeStatementParts(t *testing.T) {
 		DestKind:            constants.Redshift,
 		IdempotentKey:       "created_at",
 		ContainsHardDeletes: ptr.ToBool(true),
-		UppercaseEscNames:   ptr.ToBool(false),
+		IdentifierCasing:    config.PreserveCasing,
 	}
 
 	parts, err = mergeArg.GetParts()
@@ -240,7 +241,7 @@ func TestMergeStatementParts(t *testing.T) {
 func TestMergeStatementPartsCompositeKey(t *testing.T) {
 	fqTableName := "public.tableName"
 	tempTableName := "public.tableName__temp"
-	res := getBasicColumnsForTest(true, false)
+	res := getBasicColumnsForTest(true, config.PreserveCasing)
 	mergeArg := &MergeArgument{
 		FqTableName:         fqTableName,
 		SubQuery:            tempTableName,
@@ -248,7 +249,7 @@ func TestMergeStatementPartsCompositeKey(t *testing.T) {
 		Columns:             &res.ColumnsToTypes,
 		DestKind:            constants.Redshift,
 		ContainsHardDeletes: ptr.ToBool(true),
-		UppercaseEscNames:   ptr.ToBool(false),
+		IdentifierCasing:    config.PreserveCasing,
 	}
 
 	parts, err := mergeArg.GetParts()
@@ -275,7 +276,7 @@ func TestMergeStatementPartsCompositeKey(t *testing.T) {
 		DestKind:            constants.Redshift,
 		ContainsHardDeletes: ptr.ToBool(true),
 		IdempotentKey:       "created_at",
-		UppercaseEscNames:   ptr.ToBool(false),
+		IdentifierCasing:    config.PreserveCasing,
 	}
 
 	parts, err = mergeArg.GetParts()
@@ -294,3 +295,33 @@ func TestMergeStatementPartsCompositeKey(t *testing.T) {
 		`DELETE FROM public.tableName WHERE (id,email) IN (SELECT cc.id,cc.email FROM public.tableName__temp as cc WHERE cc.__artie_delete = true);`,
 		parts[2])
 }
+
+func TestMergeStatementPartsDuckDB(t *testing.T) {
+	res := getBasicColumnsForTest(true, config.PreserveCasing)
+	mergeArg := &MergeArgument{
+		FqTableName:         "public.tableName",
+		SubQuery:            "public.tableName__temp",
+		PrimaryKeys:         res.PrimaryKeys,
+		Columns:             &res.ColumnsToTypes,
+		DestKind:            constants.DuckDB,
+		ContainsHardDeletes: ptr.ToBool(true),
+		IdentifierCasing:    config.PreserveCasing,
+	}
+
+	parts, err := mergeArg.GetParts()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(parts))
+
+	assert.Equal(t,
+		`INSERT INTO public.tableName (id,email,first_name,last_name,created_at,toast_text) SELECT cc.id,cc.email,cc.first_name,cc.last_name,cc.created_at,cc.toast_text FROM public.tableName__temp as cc LEFT JOIN public.tableName as c on c.id = cc.id and c.email = cc.email WHERE c.id IS NULL;`,
+		parts[0])
+
+	assert.Equal(t,
+		`UPDATE public.tableName as c SET id=cc.id,email=cc.email,first_name=cc.first_name,last_name=cc.last_name,created_at=cc.created_at,toast_text= CASE WHEN COALESCE(cc.toast_text != '__debezium_unavailable_value', true) THEN cc.toast_text ELSE c.toast_text END FROM public.tableName__temp as cc WHERE c.id = cc.id and c.email = cc.email AND COALESCE(cc.__artie_delete, false) = false;`,
+		parts[1])
+
+	// DuckDB does not support multi-column IN subqueries, so the delete joins against the staging table.
+	assert.Equal(t,
+		`DELETE FROM public.tableName as c USING public.tableName__temp as cc WHERE c.id = cc.id and c.email = cc.email AND cc.__artie_delete = true;`,
+		parts[2])
+}
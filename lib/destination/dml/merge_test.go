@@ -6,8 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/ptr"
-
 	"github.com/artie-labs/transfer/lib/sql"
 
 	"github.com/artie-labs/transfer/lib/typing/columns"
@@ -43,14 +43,14 @@ func TestMergeStatementSoftDelete(t *testing.T) {
 
 	for _, idempotentKey := range []string{"", "updated_at"} {
 		mergeArg := MergeArgument{
-			FqTableName:       fqTable,
-			SubQuery:          subQuery,
-			IdempotentKey:     idempotentKey,
-			PrimaryKeys:       []columns.Wrapper{columns.NewWrapper(columns.NewColumn("id", typing.Invalid), false, nil)},
-			Columns:           &_cols,
-			DestKind:          constants.Snowflake,
-			SoftDelete:        true,
-			UppercaseEscNames: ptr.ToBool(false),
+			FqTableName:      fqTable,
+			SubQuery:         subQuery,
+			IdempotentKey:    idempotentKey,
+			PrimaryKeys:      []columns.Wrapper{columns.NewWrapper(columns.NewColumn("id", typing.Invalid), config.PreserveCasing, nil)},
+			Columns:          &_cols,
+			DestKind:         constants.Snowflake,
+			SoftDelete:       true,
+			IdentifierCasing: config.PreserveCasing,
 		}
 
 		mergeSQL, err := mergeArg.GetStatement()
@@ -63,6 +63,106 @@ func TestMergeStatementSoftDelete(t *testing.T) {
 	}
 }
 
+func TestMergeStatementSoftDelete_OmitDeleteColumn(t *testing.T) {
+	var _cols columns.Columns
+	_cols.AddColumn(columns.NewColumn("id", typing.String))
+	_cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+	_cols.AddColumn(columns.NewColumn(constants.DeletedAtColumnMarker, typing.String))
+
+	mergeArg := MergeArgument{
+		FqTableName:      "database.schema.table",
+		SubQuery:         "{SUB_QUERY}",
+		PrimaryKeys:      []columns.Wrapper{columns.NewWrapper(columns.NewColumn("id", typing.Invalid), config.PreserveCasing, nil)},
+		Columns:          &_cols,
+		DestKind:         constants.Snowflake,
+		SoftDelete:       true,
+		OmitDeleteColumn: true,
+		IdentifierCasing: config.PreserveCasing,
+	}
+
+	mergeSQL, err := mergeArg.GetStatement()
+	assert.NoError(t, err)
+	assert.Contains(t, mergeSQL, fmt.Sprintf("%s=cc.%s", constants.DeletedAtColumnMarker, constants.DeletedAtColumnMarker), mergeSQL)
+	assert.NotContains(t, mergeSQL, fmt.Sprintf("%s=cc.%s", constants.DeleteColumnMarker, constants.DeleteColumnMarker), mergeSQL)
+	assert.Contains(t, mergeSQL, fmt.Sprintf("INSERT (id,%s) VALUES (cc.id,cc.%s)", constants.DeletedAtColumnMarker, constants.DeletedAtColumnMarker), mergeSQL)
+}
+
+func TestMergeStatement_ImmutableColumns(t *testing.T) {
+	var cols columns.Columns
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("name", typing.String))
+	cols.AddColumn(columns.NewColumn("created_at", typing.String))
+	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+
+	for _, destKind := range []constants.DestinationKind{constants.Snowflake, constants.BigQuery} {
+		for _, softDelete := range []bool{false, true} {
+			mergeArg := MergeArgument{
+				FqTableName:      "database.schema.table",
+				SubQuery:         "database.schema.table_tmp",
+				PrimaryKeys:      []columns.Wrapper{columns.NewWrapper(columns.NewColumn("id", typing.Invalid), config.PreserveCasing, nil)},
+				Columns:          &cols,
+				DestKind:         destKind,
+				SoftDelete:       softDelete,
+				IdentifierCasing: config.PreserveCasing,
+				ImmutableColumns: []string{"Created_At"},
+			}
+
+			mergeSQL, err := mergeArg.GetStatement()
+			assert.NoError(t, err)
+			assert.Contains(t, mergeSQL, "THEN UPDATE SET id=cc.id,name=cc.name", mergeSQL)
+			assert.NotContains(t, mergeSQL, "created_at=cc.created_at", mergeSQL)
+			// Immutable columns are still written when the row is inserted.
+			assert.Contains(t, mergeSQL, "INSERT (id,name,created_at", mergeSQL)
+
+			mergeArg.ImmutableColumns = nil
+			mergeSQL, err = mergeArg.GetStatement()
+			assert.NoError(t, err)
+			assert.Contains(t, mergeSQL, "THEN UPDATE SET id=cc.id,name=cc.name,created_at=cc.created_at", mergeSQL)
+		}
+	}
+}
+
+func TestMergeStatement_PartialUpdateColumns(t *testing.T) {
+	var cols columns.Columns
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("name", typing.String))
+	cols.AddColumn(columns.NewColumn("email", typing.String))
+	cols.AddColumn(columns.NewColumn("created_at", typing.String))
+	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+
+	mergeArg := MergeArgument{
+		FqTableName:          "database.schema.table",
+		SubQuery:             "database.schema.table_tmp",
+		PrimaryKeys:          []columns.Wrapper{columns.NewWrapper(columns.NewColumn("id", typing.Invalid), config.PreserveCasing, nil)},
+		Columns:              &cols,
+		DestKind:             constants.Snowflake,
+		IdentifierCasing:     config.PreserveCasing,
+		PartialUpdateColumns: []string{"id", "email", "created_at", constants.DeleteColumnMarker},
+		ImmutableColumns:     []string{"created_at"},
+	}
+
+	mergeSQL, err := mergeArg.GetStatement()
+	assert.NoError(t, err)
+	assert.Contains(t, mergeSQL, "THEN UPDATE SET id=cc.id,email=cc.email\n", mergeSQL)
+	assert.NotContains(t, mergeSQL, "name=cc.name", mergeSQL)
+	// All the columns are written when the row is inserted.
+	assert.Contains(t, mergeSQL, "INSERT (id,name,email,created_at)", mergeSQL)
+
+	// MSSQL
+	mergeArg.DestKind = constants.MSSQL
+	mergeSQL, err = mergeArg.GetMSSQLStatement()
+	assert.NoError(t, err)
+	assert.Contains(t, mergeSQL, "THEN UPDATE SET id=cc.id,email=cc.email\n", mergeSQL)
+
+	// Redshift
+	mergeArg.DestKind = constants.Redshift
+	mergeArg.ContainsHardDeletes = ptr.ToBool(false)
+	parts, err := mergeArg.GetParts()
+	assert.NoError(t, err)
+	assert.Len(t, parts, 2)
+	assert.Contains(t, parts[1], "SET id=cc.id,email=cc.email FROM", parts[1])
+}
+
 func TestMergeStatement(t *testing.T) {
 	// No idempotent key
 	fqTable := "database.schema.table"
@@ -92,14 +192,14 @@ func TestMergeStatement(t *testing.T) {
 		strings.Join(cols, ","), strings.Join(tableValues, ","), "_tbl", strings.Join(cols, ","))
 
 	mergeArg := MergeArgument{
-		FqTableName:       fqTable,
-		SubQuery:          subQuery,
-		IdempotentKey:     "",
-		PrimaryKeys:       []columns.Wrapper{columns.NewWrapper(columns.NewColumn("id", typing.Invalid), false, nil)},
-		Columns:           &_cols,
-		DestKind:          constants.Snowflake,
-		SoftDelete:        false,
-		UppercaseEscNames: ptr.ToBool(false),
+		FqTableName:      fqTable,
+		SubQuery:         subQuery,
+		IdempotentKey:    "",
+		PrimaryKeys:      []columns.Wrapper{columns.NewWrapper(columns.NewColumn("id", typing.Invalid), config.PreserveCasing, nil)},
+		Columns:          &_cols,
+		DestKind:         constants.Snowflake,
+		SoftDelete:       false,
+		IdentifierCasing: config.PreserveCasing,
 	}
 
 	mergeSQL, err := mergeArg.GetStatement()
@@ -140,14 +240,14 @@ func TestMergeStatementIdempotentKey(t *testing.T) {
 	_cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
 
 	mergeArg := MergeArgument{
-		FqTableName:       fqTable,
-		SubQuery:          subQuery,
-		IdempotentKey:     "updated_at",
-		PrimaryKeys:       []columns.Wrapper{columns.NewWrapper(columns.NewColumn("id", typing.Invalid), false, nil)},
-		Columns:           &_cols,
-		DestKind:          constants.Snowflake,
-		SoftDelete:        false,
-		UppercaseEscNames: ptr.ToBool(false),
+		FqTableName:      fqTable,
+		SubQuery:         subQuery,
+		IdempotentKey:    "updated_at",
+		PrimaryKeys:      []columns.Wrapper{columns.NewWrapper(columns.NewColumn("id", typing.Invalid), config.PreserveCasing, nil)},
+		Columns:          &_cols,
+		DestKind:         constants.Snowflake,
+		SoftDelete:       false,
+		IdentifierCasing: config.PreserveCasing,
 	}
 
 	mergeSQL, err := mergeArg.GetStatement()
@@ -185,12 +285,12 @@ func TestMergeStatementCompositeKey(t *testing.T) {
 		FqTableName:   fqTable,
 		SubQuery:      subQuery,
 		IdempotentKey: "updated_at",
-		PrimaryKeys: []columns.Wrapper{columns.NewWrapper(columns.NewColumn("id", typing.Invalid), false, nil),
-			columns.NewWrapper(columns.NewColumn("another_id", typing.Invalid), false, nil)},
-		Columns:           &_cols,
-		DestKind:          constants.Snowflake,
-		SoftDelete:        false,
-		UppercaseEscNames: ptr.ToBool(false),
+		PrimaryKeys: []columns.Wrapper{columns.NewWrapper(columns.NewColumn("id", typing.Invalid), config.PreserveCasing, nil),
+			columns.NewWrapper(columns.NewColumn("another_id", typing.Invalid), config.PreserveCasing, nil)},
+		Columns:          &_cols,
+		DestKind:         constants.Snowflake,
+		SoftDelete:       false,
+		IdentifierCasing: config.PreserveCasing,
 	}
 
 	mergeSQL, err := mergeArg.GetStatement()
@@ -233,19 +333,19 @@ func TestMergeStatementEscapePrimaryKeys(t *testing.T) {
 		SubQuery:      subQuery,
 		IdempotentKey: "",
 		PrimaryKeys: []columns.Wrapper{
-			columns.NewWrapper(columns.NewColumn("id", typing.Invalid), false, &sql.NameArgs{
+			columns.NewWrapper(columns.NewColumn("id", typing.Invalid), config.PreserveCasing, &sql.NameArgs{
 				Escape:   true,
 				DestKind: constants.Snowflake,
 			}),
-			columns.NewWrapper(columns.NewColumn("group", typing.Invalid), false, &sql.NameArgs{
+			columns.NewWrapper(columns.NewColumn("group", typing.Invalid), config.PreserveCasing, &sql.NameArgs{
 				Escape:   true,
 				DestKind: constants.Snowflake,
 			}),
 		},
-		Columns:           &_cols,
-		DestKind:          constants.Snowflake,
-		SoftDelete:        false,
-		UppercaseEscNames: ptr.ToBool(false),
+		Columns:          &_cols,
+		DestKind:         constants.Snowflake,
+		SoftDelete:       false,
+		IdentifierCasing: config.PreserveCasing,
 	}
 
 	mergeSQL, err := mergeArg.GetStatement()
@@ -3,9 +3,9 @@ package dml
 import (
 	"testing"
 
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
 
-	"github.com/artie-labs/transfer/lib/ptr"
 	"github.com/artie-labs/transfer/lib/typing"
 	"github.com/artie-labs/transfer/lib/typing/columns"
 
@@ -14,7 +14,7 @@ import (
 
 func TestMergeArgument_Valid(t *testing.T) {
 	primaryKeys := []columns.Wrapper{
-		columns.NewWrapper(columns.NewColumn("id", typing.Integer), false, nil),
+		columns.NewWrapper(columns.NewColumn("id", typing.Integer), config.PreserveCasing, nil),
 	}
 
 	var cols columns.Columns
@@ -70,35 +70,35 @@ func TestMergeArgument_Valid(t *testing.T) {
 			expectedErr: "one of these arguments is empty: fqTableName, subQuery",
 		},
 		{
-			name: "did not pass in uppercase esc col",
+			name: "did not pass in identifier casing",
 			mergeArg: &MergeArgument{
 				PrimaryKeys: primaryKeys,
 				Columns:     &cols,
 				FqTableName: "schema.tableName",
 				SubQuery:    "schema.tableName",
 			},
-			expectedErr: "uppercaseEscNames cannot be nil",
+			expectedErr: "identifier casing cannot be empty",
 		},
 		{
 			name: "missing dest kind",
 			mergeArg: &MergeArgument{
-				PrimaryKeys:       primaryKeys,
-				Columns:           &cols,
-				SubQuery:          "schema.tableName",
-				FqTableName:       "schema.tableName",
-				UppercaseEscNames: ptr.ToBool(false),
+				PrimaryKeys:      primaryKeys,
+				Columns:          &cols,
+				SubQuery:         "schema.tableName",
+				FqTableName:      "schema.tableName",
+				IdentifierCasing: config.PreserveCasing,
 			},
 			expectedErr: "invalid destination",
 		},
 		{
 			name: "everything exists",
 			mergeArg: &MergeArgument{
-				PrimaryKeys:       primaryKeys,
-				Columns:           &cols,
-				SubQuery:          "schema.tableName",
-				FqTableName:       "schema.tableName",
-				UppercaseEscNames: ptr.ToBool(false),
-				DestKind:          constants.BigQuery,
+				PrimaryKeys:      primaryKeys,
+				Columns:          &cols,
+				SubQuery:         "schema.tableName",
+				FqTableName:      "schema.tableName",
+				IdentifierCasing: config.PreserveCasing,
+				DestKind:         constants.BigQuery,
 			},
 		},
 	}
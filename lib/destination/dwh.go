@@ -21,10 +21,18 @@ type DataWarehouse interface {
 
 	IsRetryableError(err error) bool
 	ToFullyQualifiedName(tableData *optimization.TableData, escape bool) string
+	TemporaryTableName(tableData *optimization.TableData) string
 	GetTableConfig(tableData *optimization.TableData) (*types.DwhTableConfig, error)
 	PrepareTemporaryTable(tableData *optimization.TableData, tableConfig *types.DwhTableConfig, tempTableName string, additionalSettings types.AdditionalSettings, createTempTable bool) error
 }
 
+// ExactlyOnce is implemented by destinations that can write the Kafka offsets within the same transaction as the merge.
+type ExactlyOnce interface {
+	MergeWithOffsets(tableData *optimization.TableData, offsets []types.KafkaOffset) error
+	// WrittenOffsets returns the last offset that has been written into this table, keyed by partition.
+	WrittenOffsets(tableData *optimization.TableData) (map[int]int64, error)
+}
+
 type Baseline interface {
 	Label() constants.DestinationKind
 	Merge(tableData *optimization.TableData) error
@@ -0,0 +1,132 @@
+package destination
+
+import "errors"
+
+// RetryableError is returned when the destination failed because of a transient issue (e.g. a network error or the destination being unavailable).
+// The same operation may succeed if it is retried.
+type RetryableError struct {
+	err error
+}
+
+func NewRetryableError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &RetryableError{err: err}
+}
+
+func (e *RetryableError) Error() string { return e.err.Error() }
+func (e *RetryableError) Unwrap() error { return e.err }
+
+// SchemaError is returned when the data does not fit the destination table (e.g. a type mismatch or a missing column).
+type SchemaError struct {
+	err error
+}
+
+func NewSchemaError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &SchemaError{err: err}
+}
+
+func (e *SchemaError) Error() string { return e.err.Error() }
+func (e *SchemaError) Unwrap() error { return e.err }
+
+// AuthError is returned when the destination rejected our credentials or we do not have permission to perform the operation.
+type AuthError struct {
+	err error
+}
+
+func NewAuthError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &AuthError{err: err}
+}
+
+func (e *AuthError) Error() string { return e.err.Error() }
+func (e *AuthError) Unwrap() error { return e.err }
+
+// PermanentError is returned for any other failure that will not go away by retrying.
+type PermanentError struct {
+	err error
+}
+
+func NewPermanentError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &PermanentError{err: err}
+}
+
+func (e *PermanentError) Error() string { return e.err.Error() }
+func (e *PermanentError) Unwrap() error { return e.err }
+
+// RejectedRowsError is returned when the destination wrote the batch without some of its rows, since they can never be written (e.g. they contain a value that the destination cannot merge).
+// The rest of the batch has been written, so the rejected rows can be routed to the dead letter topic.
+type RejectedRowsError struct {
+	rows []map[string]any
+	err  error
+}
+
+func NewRejectedRowsError(rows []map[string]any, err error) error {
+	return &RejectedRowsError{rows: rows, err: err}
+}
+
+func (e *RejectedRowsError) Error() string { return e.err.Error() }
+func (e *RejectedRowsError) Unwrap() error { return e.err }
+
+// Rows returns the rows that were not written.
+func (e *RejectedRowsError) Rows() []map[string]any { return e.rows }
+
+func IsRetryable(err error) bool {
+	var retryableErr *RetryableError
+	return errors.As(err, &retryableErr)
+}
+
+// IsPermanent returns true if `err` has been classified and retrying will not help, this includes schema and auth errors.
+func IsPermanent(err error) bool {
+	var schemaErr *SchemaError
+	var authErr *AuthError
+	var permanentErr *PermanentError
+	return errors.As(err, &schemaErr) || errors.As(err, &authErr) || errors.As(err, &permanentErr)
+}
+
+// ErrorType returns a short label for the classification of `err`, this is used for logging and metrics.
+func ErrorType(err error) string {
+	var schemaErr *SchemaError
+	var authErr *AuthError
+	switch {
+	case err == nil:
+		return ""
+	case IsRetryable(err):
+		return "retryable"
+	case errors.As(err, &schemaErr):
+		return "schema"
+	case errors.As(err, &authErr):
+		return "auth"
+	case IsPermanent(err):
+		return "permanent"
+	default:
+		return "unknown"
+	}
+}
+
+// ClassifyError wraps `err` as a RetryableError if `isRetryable` returns true, otherwise as a PermanentError.
+// Errors that have already been classified are returned as is.
+func ClassifyError(err error, isRetryable func(err error) bool) error {
+	if err == nil || IsRetryable(err) || IsPermanent(err) {
+		return err
+	}
+
+	if isRetryable(err) {
+		return NewRetryableError(err)
+	}
+
+	return NewPermanentError(err)
+}
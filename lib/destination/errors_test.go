@@ -0,0 +1,60 @@
+package destination
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/retry"
+)
+
+func TestNewErrors_Nil(t *testing.T) {
+	assert.NoError(t, NewRetryableError(nil))
+	assert.NoError(t, NewSchemaError(nil))
+	assert.NoError(t, NewAuthError(nil))
+	assert.NoError(t, NewPermanentError(nil))
+}
+
+func TestErrorType(t *testing.T) {
+	baseErr := fmt.Errorf("boom")
+	testCases := []struct {
+		err               error
+		expectedType      string
+		expectedRetryable bool
+		expectedPermanent bool
+	}{
+		{err: nil, expectedType: ""},
+		{err: baseErr, expectedType: "unknown"},
+		{err: NewRetryableError(baseErr), expectedType: "retryable", expectedRetryable: true},
+		{err: NewSchemaError(baseErr), expectedType: "schema", expectedPermanent: true},
+		{err: NewAuthError(baseErr), expectedType: "auth", expectedPermanent: true},
+		{err: NewPermanentError(baseErr), expectedType: "permanent", expectedPermanent: true},
+		// Wrapped
+		{err: fmt.Errorf("failed to merge: %w", NewAuthError(baseErr)), expectedType: "auth", expectedPermanent: true},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expectedType, ErrorType(testCase.err), testCase.err)
+		assert.Equal(t, testCase.expectedRetryable, IsRetryable(testCase.err), testCase.err)
+		assert.Equal(t, testCase.expectedPermanent, IsPermanent(testCase.err), testCase.err)
+		if testCase.err != nil {
+			assert.ErrorIs(t, testCase.err, baseErr)
+		}
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	assert.NoError(t, ClassifyError(nil, retry.AlwaysRetry))
+
+	neverRetry := func(_ error) bool { return false }
+	baseErr := fmt.Errorf("boom")
+	assert.Equal(t, "retryable", ErrorType(ClassifyError(baseErr, retry.AlwaysRetry)))
+	assert.Equal(t, "permanent", ErrorType(ClassifyError(baseErr, neverRetry)))
+
+	// Errors that have already been classified should not be reclassified.
+	schemaErr := NewSchemaError(baseErr)
+	assert.Equal(t, schemaErr, ClassifyError(schemaErr, retry.AlwaysRetry))
+	retryableErr := NewRetryableError(baseErr)
+	assert.Equal(t, retryableErr, ClassifyError(retryableErr, neverRetry))
+}
@@ -0,0 +1,141 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// schemaCacheTTL is how long a cached table is trusted before we describe the table again.
+// This bounds how long we'll keep using a stale schema if the table was changed outside of Transfer.
+const schemaCacheTTL = 24 * time.Hour
+
+// CachedColumn is the last known state of a column in the destination.
+type CachedColumn struct {
+	Name string `json:"name"`
+	// DestinationName is only set if the column's name in the destination differs from Name by case.
+	DestinationName string `json:"destinationName,omitempty"`
+	// Type is the raw destination type, this will be parsed with [typing.DwhTypeToKind] when it's loaded.
+	Type            string `json:"type"`
+	StringPrecision string `json:"stringPrecision,omitempty"`
+	Backfilled      bool   `json:"backfilled,omitempty"`
+	Comment         string `json:"comment,omitempty"`
+}
+
+type cachedTable struct {
+	Columns  []CachedColumn `json:"columns"`
+	CachedAt time.Time      `json:"cachedAt"`
+}
+
+// SchemaCache persists the last known columns of each table to a local file.
+// This is so that on startup, we don't need to describe every table before we can flush.
+type SchemaCache struct {
+	path   string
+	ttl    time.Duration
+	tables map[string]cachedTable
+	sync.Mutex
+}
+
+func newSchemaCache(path string) *SchemaCache {
+	return &SchemaCache{
+		path:   path,
+		ttl:    schemaCacheTTL,
+		tables: make(map[string]cachedTable),
+	}
+}
+
+// LoadSchemaCache will load the schema cache from `path`, it's okay if the file does not exist yet.
+func LoadSchemaCache(path string) (*SchemaCache, error) {
+	cache := newSchemaCache(path)
+
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cache, nil
+		}
+
+		return nil, fmt.Errorf("failed to read schema cache: %w", err)
+	}
+
+	if err = json.Unmarshal(bytes, &cache.tables); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schema cache: %w", err)
+	}
+
+	return cache, nil
+}
+
+// Get returns the cached columns for `fqName`, entries that are older than the TTL are treated as missing.
+func (s *SchemaCache) Get(fqName string) ([]CachedColumn, bool) {
+	if s == nil {
+		return nil, false
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	table, isOk := s.tables[fqName]
+	if !isOk || time.Since(table.CachedAt) > s.ttl {
+		return nil, false
+	}
+
+	return table.Columns, true
+}
+
+func (s *SchemaCache) Put(fqName string, cols []CachedColumn) error {
+	if s == nil {
+		return nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.tables[fqName] = cachedTable{Columns: cols, CachedAt: time.Now()}
+	return s.save()
+}
+
+// Invalidate will remove `fqName` from the cache, this should be called whenever we change the table's schema
+// or when a write fails, since the cached columns may no longer match the destination.
+func (s *SchemaCache) Invalidate(fqName string) error {
+	if s == nil {
+		return nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if _, isOk := s.tables[fqName]; !isOk {
+		return nil
+	}
+
+	delete(s.tables, fqName)
+	return s.save()
+}
+
+// save writes the cache to a temporary file first and then renames it, so a crash will not leave behind a partially written cache.
+func (s *SchemaCache) save() error {
+	bytes, err := json.Marshal(s.tables)
+	if err != nil {
+		return fmt.Errorf("failed to marshal schema cache: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	defer os.Remove(tmpFile.Name())
+	if _, err = tmpFile.Write(bytes); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write schema cache: %w", err)
+	}
+
+	if err = tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close schema cache: %w", err)
+	}
+
+	return os.Rename(tmpFile.Name(), s.path)
+}
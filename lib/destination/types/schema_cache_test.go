@@ -0,0 +1,83 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.json")
+	{
+		// File does not exist yet
+		cache, err := LoadSchemaCache(path)
+		assert.NoError(t, err)
+		_, isOk := cache.Get("db.schema.table")
+		assert.False(t, isOk)
+	}
+	{
+		// Put, then load it back
+		cache, err := LoadSchemaCache(path)
+		assert.NoError(t, err)
+
+		cols := []CachedColumn{
+			{Name: "id", Type: "number(38,0)"},
+			{Name: "name", Type: "varchar(255)", StringPrecision: "255", Backfilled: true},
+		}
+		assert.NoError(t, cache.Put("db.schema.table", cols))
+
+		cache, err = LoadSchemaCache(path)
+		assert.NoError(t, err)
+		actualCols, isOk := cache.Get("db.schema.table")
+		assert.True(t, isOk)
+		assert.Equal(t, cols, actualCols)
+
+		// Invalidating it should also remove it from the file.
+		assert.NoError(t, cache.Invalidate("db.schema.table"))
+		_, isOk = cache.Get("db.schema.table")
+		assert.False(t, isOk)
+
+		cache, err = LoadSchemaCache(path)
+		assert.NoError(t, err)
+		_, isOk = cache.Get("db.schema.table")
+		assert.False(t, isOk)
+	}
+	{
+		// Expired entries should be ignored
+		cache, err := LoadSchemaCache(path)
+		assert.NoError(t, err)
+		assert.NoError(t, cache.Put("db.schema.table", []CachedColumn{{Name: "id", Type: "number(38,0)"}}))
+		_, isOk := cache.Get("db.schema.table")
+		assert.True(t, isOk)
+
+		table := cache.tables["db.schema.table"]
+		table.CachedAt = time.Now().Add(-schemaCacheTTL - time.Minute)
+		cache.tables["db.schema.table"] = table
+		_, isOk = cache.Get("db.schema.table")
+		assert.False(t, isOk)
+	}
+	{
+		// Invalid file
+		assert.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+		_, err := LoadSchemaCache(path)
+		assert.ErrorContains(t, err, "failed to unmarshal schema cache")
+
+		// The config map should ignore it and start with an empty cache.
+		configMap := NewDwhToTablesConfigMap(path)
+		assert.NotNil(t, configMap.SchemaCache())
+		_, isOk := configMap.SchemaCache().Get("db.schema.table")
+		assert.False(t, isOk)
+	}
+	{
+		// Schema cache is not enabled
+		configMap := NewDwhToTablesConfigMap("")
+		assert.Nil(t, configMap.SchemaCache())
+		_, isOk := configMap.SchemaCache().Get("db.schema.table")
+		assert.False(t, isOk)
+		assert.NoError(t, configMap.SchemaCache().Put("db.schema.table", nil))
+		assert.NoError(t, configMap.SchemaCache().Invalidate("db.schema.table"))
+	}
+}
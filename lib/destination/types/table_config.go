@@ -18,6 +18,10 @@ type DwhTableConfig struct {
 
 	// Whether to drop deleted columns in the destination or not.
 	dropDeletedColumns bool
+	// onSchemaChange is optional, it's called after we have changed the table's schema.
+	onSchemaChange func()
+	// onWriteFailure is optional, it's called if we failed to write into the table.
+	onWriteFailure func()
 	sync.RWMutex
 }
 
@@ -34,6 +38,52 @@ func NewDwhTableConfig(columns *columns.Columns, colsToDelete map[string]time.Ti
 	}
 }
 
+// SetOnSchemaChange sets a callback that will be invoked by SchemaChanged, this is used to invalidate the schema cache.
+func (d *DwhTableConfig) SetOnSchemaChange(fn func()) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.onSchemaChange = fn
+}
+
+// SchemaChanged should be called after we have applied DDL to the table.
+func (d *DwhTableConfig) SchemaChanged() {
+	if d == nil {
+		return
+	}
+
+	d.RLock()
+	fn := d.onSchemaChange
+	d.RUnlock()
+
+	if fn != nil {
+		fn()
+	}
+}
+
+// SetOnWriteFailure sets a callback that will be invoked by WriteFailed, this is used to drop a cached schema that may be stale.
+func (d *DwhTableConfig) SetOnWriteFailure(fn func()) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.onWriteFailure = fn
+}
+
+// WriteFailed should be called if a merge or append into the table failed.
+func (d *DwhTableConfig) WriteFailed() {
+	if d == nil {
+		return
+	}
+
+	d.RLock()
+	fn := d.onWriteFailure
+	d.RUnlock()
+
+	if fn != nil {
+		fn()
+	}
+}
+
 func (d *DwhTableConfig) CreateTable() bool {
 	d.RLock()
 	defer d.RUnlock()
@@ -62,6 +112,8 @@ func (d *DwhTableConfig) MutateInMemoryColumns(createTable bool, columnOp consta
 	switch columnOp {
 	case constants.Add:
 		for _, col := range cols {
+			// The column was just added, so the destination will not have a comment for it yet.
+			col.SetComment("")
 			d.columns.AddColumn(col)
 			// Delete from the permissions table, if exists.
 			delete(d.columnsToDelete, col.RawName())
@@ -2,14 +2,44 @@ package types
 
 import (
 	"fmt"
+	"log/slog"
 	"sync"
 )
 
 type DwhToTablesConfigMap struct {
 	fqNameToDwhTableConfig map[string]*DwhTableConfig
+	// schemaCache is optional, if set the table configs will be persisted so they can be reused after a restart.
+	schemaCache *SchemaCache
 	sync.RWMutex
 }
 
+// NewDwhToTablesConfigMap will load the schema cache from `schemaCachePath` if it's set.
+// The cache is only an optimization, so if it cannot be loaded we'll log and carry on without it.
+func NewDwhToTablesConfigMap(schemaCachePath string) *DwhToTablesConfigMap {
+	configMap := &DwhToTablesConfigMap{}
+	if schemaCachePath == "" {
+		return configMap
+	}
+
+	schemaCache, err := LoadSchemaCache(schemaCachePath)
+	if err != nil {
+		// Start with an empty cache, the file will be overwritten as tables are described.
+		slog.Warn("Failed to load schema cache, ignoring it", slog.Any("err", err), slog.String("path", schemaCachePath))
+		schemaCache = newSchemaCache(schemaCachePath)
+	}
+
+	configMap.schemaCache = schemaCache
+	return configMap
+}
+
+func (d *DwhToTablesConfigMap) SchemaCache() *SchemaCache {
+	if d == nil {
+		return nil
+	}
+
+	return d.schemaCache
+}
+
 func (d *DwhToTablesConfigMap) TableConfig(fqName string) *DwhTableConfig {
 	d.RLock()
 	defer d.RUnlock()
@@ -33,15 +63,39 @@ func (d *DwhToTablesConfigMap) AddTableToConfig(fqName string, config *DwhTableC
 	d.fqNameToDwhTableConfig[fqName] = config
 }
 
+// RemoveTableFromConfig will drop `fqName` from the map, so the next flush will fetch the table config again.
+func (d *DwhToTablesConfigMap) RemoveTableFromConfig(fqName string) {
+	d.Lock()
+	defer d.Unlock()
+
+	delete(d.fqNameToDwhTableConfig, fqName)
+}
+
 type MergeOpts struct {
 	UseMergeParts             bool
 	SubQueryDedupe            bool
 	AdditionalEqualityStrings []string
 	RetryColBackfill          bool
+	// UseTransaction will run the merge within an explicit transaction that is rolled back if it fails.
+	UseTransaction bool
+	// TransactionQueries will be executed after the merge within the same transaction, this is used to record the Kafka offsets for exactly-once delivery.
+	TransactionQueries []string
+	// LoadQueries will be executed before the merge within the same transaction, these are set if the destination deferred loading the temporary table.
+	LoadQueries []string
+}
+
+// KafkaOffset is the last offset of a partition that has been written into a table.
+type KafkaOffset struct {
+	Topic     string
+	Partition int
+	Offset    int64
 }
 
 type AdditionalSettings struct {
 	AdditionalCopyClause string
+	// DeferredQueries is optional, if set the destination may append the queries that load the temporary table here instead of executing them.
+	// This is used so that the temporary table can be loaded within the same transaction as the merge.
+	DeferredQueries *[]string
 }
 
 type AppendOpts struct {
@@ -4,6 +4,9 @@ import (
 	"log/slog"
 
 	"github.com/artie-labs/transfer/clients/bigquery"
+	"github.com/artie-labs/transfer/clients/databricks"
+	"github.com/artie-labs/transfer/clients/duckdb"
+	"github.com/artie-labs/transfer/clients/iceberg"
 	"github.com/artie-labs/transfer/clients/mssql"
 	"github.com/artie-labs/transfer/clients/redshift"
 	"github.com/artie-labs/transfer/clients/s3"
@@ -17,8 +20,27 @@ import (
 	"github.com/artie-labs/transfer/lib/mocks"
 )
 
+// Destination will load the destination for `cfg.Output`, if there are additional outputs then each flush will be fanned out to all of them.
+func Destination(cfg config.Config) destination.Baseline {
+	var stores []destination.Baseline
+	for _, output := range cfg.Outputs() {
+		outputCfg := cfg.ForOutput(output)
+		if IsOutputBaseline(outputCfg) {
+			stores = append(stores, Baseline(outputCfg))
+		} else {
+			stores = append(stores, DataWarehouse(outputCfg, nil))
+		}
+	}
+
+	if len(stores) == 1 {
+		return stores[0]
+	}
+
+	return newMultiStore(cfg.FanOutPolicy, stores...)
+}
+
 func IsOutputBaseline(cfg config.Config) bool {
-	return cfg.Output == constants.S3
+	return cfg.Output == constants.S3 || cfg.Output == constants.Iceberg || cfg.Output == constants.S3Parquet
 }
 
 func Baseline(cfg config.Config) destination.Baseline {
@@ -29,6 +51,20 @@ func Baseline(cfg config.Config) destination.Baseline {
 			logger.Panic("Failed to load s3", slog.Any("err", err))
 		}
 
+		return store
+	case constants.Iceberg:
+		store, err := iceberg.LoadStore(cfg)
+		if err != nil {
+			logger.Panic("Failed to load iceberg", slog.Any("err", err))
+		}
+
+		return store
+	case constants.S3Parquet:
+		store, err := s3.LoadPartitionedStore(cfg)
+		if err != nil {
+			logger.Panic("Failed to load s3parquet", slog.Any("err", err))
+		}
+
 		return store
 	}
 
@@ -63,12 +99,28 @@ func DataWarehouse(cfg config.Config, store *db.Store) destination.DataWarehouse
 			logger.Panic("Failed to clean up mssql", slog.Any("err", err))
 		}
 		return s
+	case constants.Databricks:
+		s := databricks.LoadStore(cfg)
+		if err := s.Sweep(); err != nil {
+			logger.Panic("Failed to clean up databricks", slog.Any("err", err))
+		}
+		return s
 	case constants.Redshift:
 		s := redshift.LoadRedshift(cfg, store)
 		if err := s.Sweep(); err != nil {
 			logger.Panic("Failed to clean up redshift", slog.Any("err", err))
 		}
 		return s
+	case constants.DuckDB:
+		s, err := duckdb.LoadStore(cfg)
+		if err != nil {
+			logger.Panic("Failed to load duckdb", slog.Any("err", err))
+		}
+
+		if err = s.Sweep(); err != nil {
+			logger.Panic("Failed to clean up duckdb", slog.Any("err", err))
+		}
+		return s
 	}
 
 	logger.Panic("No valid output sources specified", slog.Any("source", cfg.Output))
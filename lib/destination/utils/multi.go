@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/optimization"
+)
+
+// multiStore will fan out each flush to all of the destinations, the first destination is the primary one.
+// Every destination is written to even if another one fails, `policy` decides which errors are returned to the caller (which will then skip committing the offsets).
+// Only merges and appends are fanned out, the config will reject truncates, schema changes and assertions when there are additional outputs.
+type multiStore struct {
+	stores []destination.Baseline
+	policy config.FanOutPolicy
+
+	// written keeps track of the destinations that have already written a batch that has not been fully flushed yet,
+	// so that retrying the batch will only write to the destinations that failed.
+	written   map[*optimization.TableData]map[int]writtenBatch
+	writtenMu sync.Mutex
+}
+
+// writtenBatch is what a destination has written for a batch.
+type writtenBatch struct {
+	rows           uint
+	lastInsertTime time.Time
+}
+
+func newMultiStore(policy config.FanOutPolicy, stores ...destination.Baseline) *multiStore {
+	return &multiStore{stores: stores, policy: policy, written: make(map[*optimization.TableData]map[int]writtenBatch)}
+}
+
+// Label returns the primary destination's label.
+func (m *multiStore) Label() constants.DestinationKind {
+	return m.stores[0].Label()
+}
+
+func (m *multiStore) Merge(tableData *optimization.TableData) error {
+	return m.fanOut(tableData, destination.Baseline.Merge)
+}
+
+func (m *multiStore) Append(tableData *optimization.TableData) error {
+	return m.fanOut(tableData, destination.Baseline.Append)
+}
+
+func (m *multiStore) IsRetryableError(err error) bool {
+	for _, store := range m.stores {
+		if store.IsRetryableError(err) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fanOut will call `write` for each destination in order, every destination gets its own copy of the batch since writing will update the columns and rows in place.
+// If the batch was written to a destination by a previous attempt, it will be skipped unless new rows have been added since then.
+func (m *multiStore) fanOut(tableData *optimization.TableData, write func(destination.Baseline, *optimization.TableData) error) error {
+	written := m.writtenFor(tableData)
+	errs := make([]error, len(m.stores))
+	for i, store := range m.stores {
+		batch := tableData.Clone()
+		if prev, isOk := written[i]; isOk {
+			if prev.lastInsertTime.Equal(tableData.LastInsertTime()) && tableData.NumberOfRows() <= prev.rows {
+				// Nothing has changed since this destination wrote the batch.
+				continue
+			}
+
+			// Rows are appended in order, so we only need to write the rows that this destination has not seen yet.
+			// Merges are upserts on the primary keys, so it's safe for them to write the whole batch again.
+			batch.DropRows(prev.rows)
+		}
+
+		if err := write(store, batch); err != nil {
+			errs[i] = fmt.Errorf("failed to write to %s: %w", store.Label(), err)
+			continue
+		}
+
+		m.writtenMu.Lock()
+		written[i] = writtenBatch{rows: tableData.NumberOfRows(), lastInsertTime: tableData.LastInsertTime()}
+		m.writtenMu.Unlock()
+	}
+
+	err := errors.Join(errs...)
+	if m.policy == config.FanOutPolicyPrimary {
+		for _, err := range errs[1:] {
+			if err != nil {
+				slog.Warn("Failed to write to an additional output, ignoring because of the fan out policy", slog.Any("err", err), slog.String("table", tableData.RawName()))
+			}
+		}
+
+		err = errs[0]
+	}
+
+	if err == nil {
+		// The batch has been flushed, so it will not be retried.
+		m.writtenMu.Lock()
+		delete(m.written, tableData)
+		m.writtenMu.Unlock()
+	}
+
+	return err
+}
+
+// writtenFor returns what each destination has already written for `tableData`.
+// Bisecting a batch will share the last insert time with the batch that it was split from, so the halves will skip the destinations that have already written the whole batch.
+func (m *multiStore) writtenFor(tableData *optimization.TableData) map[int]writtenBatch {
+	m.writtenMu.Lock()
+	defer m.writtenMu.Unlock()
+
+	if written, isOk := m.written[tableData]; isOk {
+		return written
+	}
+
+	written := make(map[int]writtenBatch)
+	for td, tdWritten := range m.written {
+		if td.RawName() != tableData.RawName() {
+			continue
+		}
+
+		if !td.LastInsertTime().Equal(tableData.LastInsertTime()) {
+			// Rows have been inserted since, so this is from a batch that has been flushed or is no longer being retried.
+			delete(m.written, td)
+			continue
+		}
+
+		for idx, batch := range tdWritten {
+			if batch.lastInsertTime.Equal(tableData.LastInsertTime()) {
+				written[idx] = batch
+			}
+		}
+	}
+
+	m.written[tableData] = written
+	return written
+}
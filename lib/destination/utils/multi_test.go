@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+type fakeBaseline struct {
+	label     constants.DestinationKind
+	err       error
+	retryable bool
+
+	merged   []*optimization.TableData
+	appended []*optimization.TableData
+}
+
+func (f *fakeBaseline) Label() constants.DestinationKind {
+	return f.label
+}
+
+func (f *fakeBaseline) Merge(tableData *optimization.TableData) error {
+	f.merged = append(f.merged, tableData)
+	return f.err
+}
+
+func (f *fakeBaseline) Append(tableData *optimization.TableData) error {
+	f.appended = append(f.appended, tableData)
+	return f.err
+}
+
+func (f *fakeBaseline) IsRetryableError(_ error) bool {
+	return f.retryable
+}
+
+func TestMultiStore(t *testing.T) {
+	tableData := optimization.NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "orders")
+	{
+		// Both stores are written to
+		bigQuery := &fakeBaseline{label: constants.BigQuery}
+		s3 := &fakeBaseline{label: constants.S3}
+		store := newMultiStore("", bigQuery, s3)
+		assert.Equal(t, constants.BigQuery, store.Label())
+
+		assert.NoError(t, store.Merge(tableData))
+		assert.Len(t, bigQuery.merged, 1)
+		assert.Len(t, s3.merged, 1)
+		// Each store should get its own copy.
+		assert.Equal(t, tableData, bigQuery.merged[0])
+		assert.NotSame(t, tableData, bigQuery.merged[0])
+		assert.NotSame(t, bigQuery.merged[0], s3.merged[0])
+
+		assert.NoError(t, store.Append(tableData))
+		assert.Len(t, bigQuery.appended, 1)
+		assert.Len(t, s3.appended, 1)
+	}
+	{
+		// The primary store fails, the additional output should still be written to.
+		bigQuery := &fakeBaseline{label: constants.BigQuery, err: fmt.Errorf("quota exceeded")}
+		s3 := &fakeBaseline{label: constants.S3}
+		for _, policy := range []config.FanOutPolicy{config.FanOutPolicyAll, config.FanOutPolicyPrimary} {
+			err := newMultiStore(policy, bigQuery, s3).Merge(tableData)
+			assert.ErrorContains(t, err, "failed to write to bigquery: quota exceeded", policy)
+		}
+		assert.Len(t, s3.merged, 2)
+	}
+	{
+		// The additional output fails
+		bigQuery := &fakeBaseline{label: constants.BigQuery}
+		s3 := &fakeBaseline{label: constants.S3, err: fmt.Errorf("access denied")}
+		assert.ErrorContains(t, newMultiStore(config.FanOutPolicyAll, bigQuery, s3).Merge(tableData), "failed to write to s3: access denied")
+		assert.ErrorContains(t, newMultiStore("", bigQuery, s3).Append(tableData), "failed to write to s3: access denied")
+
+		// Only the primary store has to succeed.
+		assert.NoError(t, newMultiStore(config.FanOutPolicyPrimary, bigQuery, s3).Merge(tableData))
+		assert.Len(t, bigQuery.merged, 2)
+		assert.Len(t, s3.merged, 2)
+	}
+	{
+		// Retryable if any of the stores say so.
+		bigQuery := &fakeBaseline{label: constants.BigQuery}
+		s3 := &fakeBaseline{label: constants.S3}
+		assert.False(t, newMultiStore("", bigQuery, s3).IsRetryableError(fmt.Errorf("foo")))
+		s3.retryable = true
+		assert.True(t, newMultiStore("", bigQuery, s3).IsRetryableError(fmt.Errorf("foo")))
+	}
+}
+
+func TestMultiStore_Retry(t *testing.T) {
+	{
+		// Merge, only the store that failed should be retried.
+		tableData := optimization.NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "orders")
+		tableData.InsertRow("1", map[string]any{"id": 1}, false)
+
+		bigQuery := &fakeBaseline{label: constants.BigQuery}
+		s3 := &fakeBaseline{label: constants.S3, err: fmt.Errorf("access denied")}
+		store := newMultiStore(config.FanOutPolicyAll, bigQuery, s3)
+		assert.ErrorContains(t, store.Merge(tableData), "failed to write to s3: access denied")
+		assert.ErrorContains(t, store.Merge(tableData), "failed to write to s3: access denied")
+		assert.Len(t, bigQuery.merged, 1)
+		assert.Len(t, s3.merged, 2)
+
+		s3.err = nil
+		assert.NoError(t, store.Merge(tableData))
+		assert.Len(t, bigQuery.merged, 1)
+		assert.Len(t, s3.merged, 3)
+
+		// A new row has been added since, so the whole batch is merged again.
+		tableData.InsertRow("2", map[string]any{"id": 2}, false)
+		assert.NoError(t, store.Merge(tableData))
+		assert.Len(t, bigQuery.merged, 2)
+		assert.Equal(t, uint(2), bigQuery.merged[1].NumberOfRows())
+		assert.Len(t, s3.merged, 4)
+	}
+	{
+		// Append, the store that succeeded should only get the rows that were added since.
+		tableData := optimization.NewTableData(nil, config.History, []string{"id"}, kafkalib.TopicConfig{}, "orders")
+		tableData.InsertRow("1", map[string]any{"id": 1}, false)
+
+		bigQuery := &fakeBaseline{label: constants.BigQuery}
+		s3 := &fakeBaseline{label: constants.S3, err: fmt.Errorf("access denied")}
+		store := newMultiStore(config.FanOutPolicyAll, bigQuery, s3)
+		assert.ErrorContains(t, store.Append(tableData), "failed to write to s3: access denied")
+
+		tableData.InsertRow("2", map[string]any{"id": 2}, false)
+		s3.err = nil
+		assert.NoError(t, store.Append(tableData))
+		assert.Len(t, bigQuery.appended, 2)
+		assert.Equal(t, []map[string]any{{"id": 2}}, bigQuery.appended[1].Rows())
+		assert.Len(t, s3.appended, 2)
+		assert.Equal(t, []map[string]any{{"id": 1}, {"id": 2}}, s3.appended[1].Rows())
+	}
+	{
+		// Bisecting a batch should skip the stores that have already written the whole batch.
+		tableData := optimization.NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "orders")
+		tableData.InsertRow("1", map[string]any{"id": 1}, false)
+		tableData.InsertRow("2", map[string]any{"id": 2}, false)
+
+		bigQuery := &fakeBaseline{label: constants.BigQuery}
+		s3 := &fakeBaseline{label: constants.S3, err: fmt.Errorf("access denied")}
+		store := newMultiStore(config.FanOutPolicyAll, bigQuery, s3)
+		assert.ErrorContains(t, store.Merge(tableData), "failed to write to s3: access denied")
+
+		s3.err = nil
+		left, right := tableData.Bisect()
+		assert.NoError(t, store.Merge(left))
+		assert.NoError(t, store.Merge(right))
+		assert.Len(t, bigQuery.merged, 1)
+		assert.Len(t, s3.merged, 3)
+	}
+	{
+		// Modifying the columns or rows of one store's copy should not leak into the next store.
+		cols := &columns.Columns{}
+		cols.AddColumn(columns.NewColumn("id", typing.Integer))
+		tableData := optimization.NewTableData(cols, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "orders")
+		tableData.InsertRow("1", map[string]any{"id": 1}, false)
+
+		bigQuery := &mutatingBaseline{fakeBaseline: fakeBaseline{label: constants.BigQuery}}
+		s3 := &fakeBaseline{label: constants.S3}
+		assert.NoError(t, newMultiStore("", bigQuery, s3).Merge(tableData))
+		col, isOk := s3.merged[0].ReadOnlyInMemoryCols().GetColumn("id")
+		assert.True(t, isOk)
+		assert.Equal(t, typing.Integer, col.KindDetails)
+		assert.Equal(t, []map[string]any{{"id": 1}}, s3.merged[0].Rows())
+		assert.Equal(t, []map[string]any{{"id": 1}}, tableData.Rows())
+	}
+}
+
+// mutatingBaseline updates the columns and rows while merging, like the destinations do.
+type mutatingBaseline struct {
+	fakeBaseline
+}
+
+func (m *mutatingBaseline) Merge(tableData *optimization.TableData) error {
+	tableData.MergeColumnsFromDestination(columns.NewColumn("id", typing.String))
+	for _, row := range tableData.Rows() {
+		row["id"] = "changed"
+	}
+
+	return m.fakeBaseline.Merge(tableData)
+}
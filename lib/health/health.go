@@ -0,0 +1,184 @@
+package health
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultTracker is used by the consumers and flush, so they do not need to pass a tracker around.
+var defaultTracker = NewTracker()
+
+func Default() *Tracker {
+	return defaultTracker
+}
+
+type topicState struct {
+	connected    bool
+	ingestionLag time.Duration
+	// offsetLag is a map of partition to the number of messages the consumer group is behind, it is nil if it has not been recorded.
+	offsetLag map[int]int64
+}
+
+type tableState struct {
+	lastFlush time.Time
+	// pendingSince is when the first row was buffered since the last successful flush, it is zero if there is nothing buffered.
+	pendingSince time.Time
+}
+
+// Tracker keeps track of the consumer connection, ingestion lag and the last successful flush for each table.
+type Tracker struct {
+	topics map[string]*topicState
+	tables map[string]*tableState
+	sync.RWMutex
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{
+		topics: make(map[string]*topicState),
+		tables: make(map[string]*tableState),
+	}
+}
+
+func (t *Tracker) topic(topic string) *topicState {
+	state, isOk := t.topics[topic]
+	if !isOk {
+		state = &topicState{}
+		t.topics[topic] = state
+	}
+
+	return state
+}
+
+func (t *Tracker) table(table string) *tableState {
+	state, isOk := t.tables[table]
+	if !isOk {
+		state = &tableState{}
+		t.tables[table] = state
+	}
+
+	return state
+}
+
+func (t *Tracker) SetConnected(topic string, connected bool) {
+	t.Lock()
+	defer t.Unlock()
+	t.topic(topic).connected = connected
+}
+
+func (t *Tracker) RecordIngestionLag(topic string, lag time.Duration) {
+	t.Lock()
+	defer t.Unlock()
+	t.topic(topic).ingestionLag = lag
+}
+
+// RecordOffsetLag is called with the consumer group's offset lag for each partition of `topic`.
+func (t *Tracker) RecordOffsetLag(topic string, lag map[int]int64) {
+	t.Lock()
+	defer t.Unlock()
+	t.topic(topic).offsetLag = lag
+}
+
+// RecordRow is called when a row has been buffered for `table`.
+func (t *Tracker) RecordRow(table string, ts time.Time) {
+	t.Lock()
+	defer t.Unlock()
+	state := t.table(table)
+	if state.pendingSince.IsZero() {
+		state.pendingSince = ts
+	}
+}
+
+// RecordFlush is called once `table` has been successfully flushed.
+func (t *Tracker) RecordFlush(table string, ts time.Time) {
+	t.Lock()
+	defer t.Unlock()
+	state := t.table(table)
+	state.lastFlush = ts
+	state.pendingSince = time.Time{}
+}
+
+type TopicStatus struct {
+	Connected      bool  `json:"connected"`
+	IngestionLagMs int64 `json:"ingestionLagMs"`
+	// OffsetLag is the total number of messages the consumer group is behind, PartitionOffsetLag has the breakdown by partition.
+	// These are only set for Kafka when offset lag polling is enabled.
+	OffsetLag          *int64        `json:"offsetLag,omitempty"`
+	PartitionOffsetLag map[int]int64 `json:"partitionOffsetLag,omitempty"`
+}
+
+type TableStatus struct {
+	LastFlush             *time.Time `json:"lastFlush,omitempty"`
+	SecondsSinceLastFlush *float64   `json:"secondsSinceLastFlush,omitempty"`
+	// PendingSeconds is how long rows have been buffered for this table without being flushed.
+	PendingSeconds float64 `json:"pendingSeconds"`
+}
+
+type Status struct {
+	Ready   bool                   `json:"ready"`
+	Reasons []string               `json:"reasons,omitempty"`
+	Topics  map[string]TopicStatus `json:"topics"`
+	Tables  map[string]TableStatus `json:"tables"`
+}
+
+// Status returns the current state as of `now`, it will not be ready if any topic has disconnected or if any table has had rows buffered for longer than `staleness`.
+func (t *Tracker) Status(now time.Time, staleness time.Duration) Status {
+	t.RLock()
+	defer t.RUnlock()
+
+	status := Status{
+		Ready:  true,
+		Topics: make(map[string]TopicStatus),
+		Tables: make(map[string]TableStatus),
+	}
+
+	for topic, state := range t.topics {
+		topicStatus := TopicStatus{
+			Connected:      state.connected,
+			IngestionLagMs: state.ingestionLag.Milliseconds(),
+		}
+
+		if state.offsetLag != nil {
+			var totalLag int64
+			partitionLag := make(map[int]int64)
+			for partition, lag := range state.offsetLag {
+				totalLag += lag
+				partitionLag[partition] = lag
+			}
+
+			topicStatus.OffsetLag = &totalLag
+			topicStatus.PartitionOffsetLag = partitionLag
+		}
+
+		status.Topics[topic] = topicStatus
+
+		if !state.connected {
+			status.Reasons = append(status.Reasons, fmt.Sprintf("consumer for topic %q is disconnected", topic))
+		}
+	}
+
+	for table, state := range t.tables {
+		var tableStatus TableStatus
+		if !state.lastFlush.IsZero() {
+			lastFlush := state.lastFlush
+			sinceLastFlush := now.Sub(lastFlush).Seconds()
+			tableStatus.LastFlush = &lastFlush
+			tableStatus.SecondsSinceLastFlush = &sinceLastFlush
+		}
+
+		if !state.pendingSince.IsZero() {
+			pending := now.Sub(state.pendingSince)
+			tableStatus.PendingSeconds = pending.Seconds()
+			if pending > staleness {
+				status.Reasons = append(status.Reasons, fmt.Sprintf("table %q has not been flushed for %s", table, pending.Round(time.Second)))
+			}
+		}
+
+		status.Tables[table] = tableStatus
+	}
+
+	sort.Strings(status.Reasons)
+	status.Ready = len(status.Reasons) == 0
+	return status
+}
@@ -0,0 +1,56 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker_Status(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewTracker()
+
+	{
+		// Nothing has happened yet
+		status := tracker.Status(now, time.Minute)
+		assert.True(t, status.Ready)
+		assert.Empty(t, status.Reasons)
+	}
+	{
+		// Connected with rows that were just buffered
+		tracker.SetConnected("orders", true)
+		tracker.RecordIngestionLag("orders", 1500*time.Millisecond)
+		tracker.RecordRow("orders", now.Add(-10*time.Second))
+		tracker.RecordRow("orders", now)
+
+		status := tracker.Status(now, time.Minute)
+		assert.True(t, status.Ready)
+		assert.Equal(t, TopicStatus{Connected: true, IngestionLagMs: 1500}, status.Topics["orders"])
+		// The first buffered row is what counts.
+		assert.Equal(t, float64(10), status.Tables["orders"].PendingSeconds)
+		assert.Nil(t, status.Tables["orders"].LastFlush)
+	}
+	{
+		// Rows have been buffered for too long
+		status := tracker.Status(now.Add(2*time.Minute), time.Minute)
+		assert.False(t, status.Ready)
+		assert.Equal(t, []string{`table "orders" has not been flushed for 2m10s`}, status.Reasons)
+	}
+	{
+		// Flushing should clear the pending rows
+		tracker.RecordFlush("orders", now.Add(2*time.Minute))
+		status := tracker.Status(now.Add(5*time.Minute), time.Minute)
+		assert.True(t, status.Ready)
+		assert.Equal(t, float64(0), status.Tables["orders"].PendingSeconds)
+		assert.Equal(t, now.Add(2*time.Minute), *status.Tables["orders"].LastFlush)
+		assert.Equal(t, float64(180), *status.Tables["orders"].SecondsSinceLastFlush)
+	}
+	{
+		// Disconnected
+		tracker.SetConnected("orders", false)
+		status := tracker.Status(now, time.Minute)
+		assert.False(t, status.Ready)
+		assert.Equal(t, []string{`consumer for topic "orders" is disconnected`}, status.Reasons)
+	}
+}
@@ -0,0 +1,64 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/artie-labs/transfer/lib/config"
+)
+
+// NewHandler returns a handler that serves:
+// 1. /healthz - liveness, this will always return 200 as long as the process is up.
+// 2. /readyz - readiness, this will return 503 if the consumer has disconnected or a table has not been flushed within `staleness`.
+func NewHandler(tracker *Tracker, staleness time.Duration) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		writeStatus(w, http.StatusOK, tracker.Status(time.Now(), staleness))
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		status := tracker.Status(time.Now(), staleness)
+		statusCode := http.StatusOK
+		if !status.Ready {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		writeStatus(w, statusCode, status)
+	})
+
+	return mux
+}
+
+func writeStatus(w http.ResponseWriter, statusCode int, status Status) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		slog.Warn("Failed to write health status", slog.Any("err", err))
+	}
+}
+
+// StartServer will serve the health endpoints until `ctx` is done.
+func StartServer(ctx context.Context, cfg config.HealthCheck) {
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           NewHandler(Default(), time.Duration(cfg.StalenessThresholdSeconds)*time.Second),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := server.Close(); err != nil {
+			slog.Warn("Failed to close health check server", slog.Any("err", err))
+		}
+	}()
+
+	slog.Info("Starting health check server...", slog.Int("port", cfg.Port))
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Health check server failed", slog.Any("err", err))
+	}
+}
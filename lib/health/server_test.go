@@ -0,0 +1,66 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func request(t *testing.T, handler http.Handler, path string) (int, Status) {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var status Status
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+	return recorder.Code, status
+}
+
+func TestNewHandler(t *testing.T) {
+	tracker := NewTracker()
+	tracker.SetConnected("orders", true)
+	tracker.RecordIngestionLag("orders", 2*time.Second)
+	tracker.RecordRow("orders", time.Now())
+	handler := NewHandler(tracker, time.Minute)
+
+	{
+		// Healthy
+		code, status := request(t, handler, "/healthz")
+		assert.Equal(t, http.StatusOK, code)
+		assert.True(t, status.Ready)
+
+		code, status = request(t, handler, "/readyz")
+		assert.Equal(t, http.StatusOK, code)
+		assert.True(t, status.Ready)
+		assert.Equal(t, int64(2000), status.Topics["orders"].IngestionLagMs)
+	}
+	{
+		// Stale
+		tracker.RecordRow("customers", time.Now().Add(-2*time.Minute))
+		code, status := request(t, handler, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.False(t, status.Ready)
+		assert.Len(t, status.Reasons, 1)
+		assert.Contains(t, status.Reasons[0], `table "customers" has not been flushed`)
+
+		// Liveness should not be affected
+		code, _ = request(t, handler, "/healthz")
+		assert.Equal(t, http.StatusOK, code)
+
+		// Once flushed, it should be ready again
+		tracker.RecordFlush("customers", time.Now())
+		code, _ = request(t, handler, "/readyz")
+		assert.Equal(t, http.StatusOK, code)
+	}
+	{
+		// Disconnected
+		tracker.SetConnected("orders", false)
+		code, status := request(t, handler, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, []string{`consumer for topic "orders" is disconnected`}, status.Reasons)
+	}
+}
@@ -0,0 +1,79 @@
+package jitter
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// adaptiveWindowSize is the number of recent durations that we'll keep track of.
+const adaptiveWindowSize = 10
+
+// AdaptiveJitter scales the jitter with how long recent operations (e.g. flushes) have taken.
+// The upper bound is the average of the recent durations, capped to [baseMs, maxMs].
+type AdaptiveJitter struct {
+	baseMs int64
+	maxMs  int64
+
+	mu     sync.Mutex
+	recent []time.Duration
+	next   int
+}
+
+func NewAdaptiveJitter(baseMs, maxMs int) *AdaptiveJitter {
+	return &AdaptiveJitter{
+		baseMs: int64(baseMs),
+		maxMs:  int64(maxMs),
+		recent: make([]time.Duration, 0, adaptiveWindowSize),
+	}
+}
+
+// Observe records how long the operation took, only the last [adaptiveWindowSize] durations are kept.
+func (a *AdaptiveJitter) Observe(duration time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.recent) < adaptiveWindowSize {
+		a.recent = append(a.recent, duration)
+		return
+	}
+
+	a.recent[a.next] = duration
+	a.next = (a.next + 1) % adaptiveWindowSize
+}
+
+// UpperBound returns the current upper bound for the jitter.
+func (a *AdaptiveJitter) UpperBound() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return time.Duration(computeAdaptiveUpperBoundMs(a.baseMs, a.maxMs, a.recent)) * time.Millisecond
+}
+
+// Jitter returns a random duration between 0 and the current upper bound.
+func (a *AdaptiveJitter) Jitter() time.Duration {
+	upperBoundMs := a.UpperBound().Milliseconds()
+	if upperBoundMs <= 0 {
+		return time.Duration(0)
+	}
+
+	return time.Duration(rand.Int63n(upperBoundMs)) * time.Millisecond
+}
+
+// computeAdaptiveUpperBoundMs calculates min(maxMs, max(baseMs, average(recent))).
+func computeAdaptiveUpperBoundMs(baseMs, maxMs int64, recent []time.Duration) int64 {
+	if maxMs <= 0 {
+		return 0
+	}
+
+	if len(recent) == 0 {
+		return min(maxMs, baseMs)
+	}
+
+	var total time.Duration
+	for _, duration := range recent {
+		total += duration
+	}
+
+	averageMs := (total / time.Duration(len(recent))).Milliseconds()
+	return min(maxMs, max(baseMs, averageMs))
+}
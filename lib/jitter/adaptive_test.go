@@ -0,0 +1,49 @@
+package jitter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeAdaptiveUpperBoundMs(t *testing.T) {
+	// No flushes yet, we'll use the base.
+	assert.Equal(t, int64(500), computeAdaptiveUpperBoundMs(500, 3500, nil))
+	// Disabled
+	assert.Equal(t, int64(0), computeAdaptiveUpperBoundMs(500, 0, nil))
+	// Fast flushes are bounded by the base.
+	assert.Equal(t, int64(500), computeAdaptiveUpperBoundMs(500, 3500, []time.Duration{50 * time.Millisecond, 100 * time.Millisecond}))
+	// Average of the recent flushes.
+	assert.Equal(t, int64(1500), computeAdaptiveUpperBoundMs(500, 3500, []time.Duration{time.Second, 2 * time.Second}))
+	assert.Equal(t, int64(1200), computeAdaptiveUpperBoundMs(500, 3500, []time.Duration{600 * time.Millisecond, 900 * time.Millisecond, 2100 * time.Millisecond}))
+	// Slow flushes are capped by the max.
+	assert.Equal(t, int64(3500), computeAdaptiveUpperBoundMs(500, 3500, []time.Duration{10 * time.Second, 20 * time.Second}))
+}
+
+func TestAdaptiveJitter(t *testing.T) {
+	adaptiveJitter := NewAdaptiveJitter(500, 3500)
+	assert.Equal(t, 500*time.Millisecond, adaptiveJitter.UpperBound())
+
+	adaptiveJitter.Observe(3 * time.Second)
+	assert.Equal(t, 3*time.Second, adaptiveJitter.UpperBound())
+
+	// Once we have more than the window size, the oldest durations are dropped.
+	for i := 0; i < adaptiveWindowSize; i++ {
+		adaptiveJitter.Observe(time.Second)
+	}
+	assert.Len(t, adaptiveJitter.recent, adaptiveWindowSize)
+	assert.Equal(t, time.Second, adaptiveJitter.UpperBound())
+
+	adaptiveJitter.Observe(11 * time.Second)
+	assert.Equal(t, 2*time.Second, adaptiveJitter.UpperBound())
+
+	for i := 0; i < 100; i++ {
+		jitter := adaptiveJitter.Jitter()
+		assert.GreaterOrEqual(t, jitter, time.Duration(0))
+		assert.Less(t, jitter, 2*time.Second)
+	}
+
+	// Disabled
+	assert.Equal(t, time.Duration(0), NewAdaptiveJitter(0, 0).Jitter())
+}
@@ -1,9 +1,51 @@
 package jsonutil
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 )
 
+// Unmarshal is [json.Unmarshal], but if `useNumber` is true numbers will be decoded as [json.Number] rather than float64.
+// This will preserve the precision of integers that are larger than 2^53.
+func Unmarshal(data []byte, v any, useNumber bool) error {
+	if !useNumber {
+		return json.Unmarshal(data, v)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	// [json.Unmarshal] will reject trailing data, so we'll do the same.
+	if decoder.More() {
+		return fmt.Errorf("invalid character after top-level value")
+	}
+
+	return nil
+}
+
+// NumberValue will convert a [json.Number] into an int64 if it's an integer that fits, otherwise a float64.
+// Values that are not a [json.Number] are returned as-is.
+func NumberValue(val any) any {
+	number, isOk := val.(json.Number)
+	if !isOk {
+		return val
+	}
+
+	if intVal, err := number.Int64(); err == nil {
+		return intVal
+	}
+
+	if floatVal, err := number.Float64(); err == nil {
+		return floatVal
+	}
+
+	return number.String()
+}
+
 // SanitizePayload will take in a JSON string, and return a JSON string that has been sanitized (removed duplicate keys)
 func SanitizePayload(val any) (any, error) {
 	valString, isOk := val.(string)
@@ -22,3 +64,51 @@ func SanitizePayload(val any) (any, error) {
 
 	return nil, err
 }
+
+// InvalidJSONPolicy controls what we do with string values for JSON columns that are not valid JSON.
+type InvalidJSONPolicy string
+
+const (
+	// InvalidJSONPolicyError will reject the value.
+	InvalidJSONPolicyError InvalidJSONPolicy = "error"
+	// InvalidJSONPolicyString will store the value as text by encoding it as a JSON string.
+	InvalidJSONPolicyString InvalidJSONPolicy = "string"
+)
+
+func (i InvalidJSONPolicy) Validate() error {
+	switch i {
+	case "", InvalidJSONPolicyError, InvalidJSONPolicyString:
+		return nil
+	default:
+		return fmt.Errorf("invalid JSON policy: %q", i)
+	}
+}
+
+// ToJSONString will return `val` as valid JSON. Strings are expected to already be JSON (objects, arrays, scalars and null are all valid)
+// and will be returned as-is, strings that are not valid JSON are handled based on `policy`. Any other value will be marshalled.
+func ToJSONString(val any, policy InvalidJSONPolicy) (string, error) {
+	valString, isOk := val.(string)
+	if !isOk {
+		valBytes, err := json.Marshal(val)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal value: %w", err)
+		}
+
+		return string(valBytes), nil
+	}
+
+	if json.Valid([]byte(valString)) {
+		return valString, nil
+	}
+
+	if policy == InvalidJSONPolicyString {
+		valBytes, err := json.Marshal(valString)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal value: %w", err)
+		}
+
+		return string(valBytes), nil
+	}
+
+	return "", fmt.Errorf("invalid JSON value: %q", valString)
+}
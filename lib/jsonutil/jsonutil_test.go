@@ -45,3 +45,92 @@ func TestSanitizePayload(t *testing.T) {
 		assert.Contains(t, jsonMap, "foo")
 	}
 }
+
+func TestInvalidJSONPolicy_Validate(t *testing.T) {
+	for _, policy := range []InvalidJSONPolicy{"", InvalidJSONPolicyError, InvalidJSONPolicyString} {
+		assert.NoError(t, policy.Validate(), policy)
+	}
+
+	assert.ErrorContains(t, InvalidJSONPolicy("null").Validate(), `invalid JSON policy: "null"`)
+}
+
+func TestToJSONString(t *testing.T) {
+	{
+		// Valid JSON strings are returned as-is.
+		for _, value := range []string{`{"foo": "bar"}`, `[1, 2, {"foo": null}]`, `null`, `123`, `"hello"`, `true`} {
+			for _, policy := range []InvalidJSONPolicy{"", InvalidJSONPolicyError, InvalidJSONPolicyString} {
+				val, err := ToJSONString(value, policy)
+				assert.NoError(t, err, value)
+				assert.Equal(t, value, val, value)
+			}
+		}
+	}
+	{
+		// Other values are marshalled.
+		val, err := ToJSONString(map[string]any{"foo": []any{"bar", nil}}, "")
+		assert.NoError(t, err)
+		assert.Equal(t, `{"foo":["bar",null]}`, val)
+
+		val, err = ToJSONString([]any{1, "two"}, "")
+		assert.NoError(t, err)
+		assert.Equal(t, `[1,"two"]`, val)
+
+		val, err = ToJSONString(nil, "")
+		assert.NoError(t, err)
+		assert.Equal(t, "null", val)
+
+		_, err = ToJSONString(make(chan int), "")
+		assert.ErrorContains(t, err, "failed to marshal value")
+	}
+	{
+		// Malformed JSON
+		_, err := ToJSONString(`{"foo": "bar"`, "")
+		assert.ErrorContains(t, err, `invalid JSON value: "{\"foo\": \"bar\""`)
+
+		_, err = ToJSONString(`{"foo": "bar"`, InvalidJSONPolicyError)
+		assert.ErrorContains(t, err, `invalid JSON value: "{\"foo\": \"bar\""`)
+
+		val, err := ToJSONString(`{"foo": "bar"`, InvalidJSONPolicyString)
+		assert.NoError(t, err)
+		assert.Equal(t, `"{\"foo\": \"bar\""`, val)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	data := []byte(`{"id": 1234567890123456789, "price": 7.5, "nested": {"id": 1234567890123456789}}`)
+	{
+		// Numbers are decoded as float64 by default.
+		var obj map[string]any
+		assert.NoError(t, Unmarshal(data, &obj, false))
+		assert.Equal(t, float64(1234567890123456789), obj["id"])
+		assert.NotEqual(t, "1234567890123456789", fmt.Sprint(obj["id"]))
+	}
+	{
+		// Use number
+		var obj map[string]any
+		assert.NoError(t, Unmarshal(data, &obj, true))
+		assert.Equal(t, json.Number("1234567890123456789"), obj["id"])
+		assert.Equal(t, json.Number("7.5"), obj["price"])
+
+		// Nested numbers are marshalled back exactly.
+		nested, err := json.Marshal(obj["nested"])
+		assert.NoError(t, err)
+		assert.Equal(t, `{"id":1234567890123456789}`, string(nested))
+	}
+	{
+		// Invalid JSON
+		var obj map[string]any
+		assert.Error(t, Unmarshal([]byte(`{"id": 1`), &obj, true))
+		assert.ErrorContains(t, Unmarshal([]byte(`{"id": 1} {"id": 2}`), &obj, true), "invalid character after top-level value")
+	}
+}
+
+func TestNumberValue(t *testing.T) {
+	assert.Equal(t, int64(1234567890123456789), NumberValue(json.Number("1234567890123456789")))
+	assert.Equal(t, int64(-5), NumberValue(json.Number("-5")))
+	assert.Equal(t, 7.5, NumberValue(json.Number("7.5")))
+	assert.Equal(t, 1e20, NumberValue(json.Number("100000000000000000000")))
+	// Values that are not a json.Number are returned as-is.
+	assert.Equal(t, "foo", NumberValue("foo"))
+	assert.Equal(t, 7.5, NumberValue(7.5))
+}
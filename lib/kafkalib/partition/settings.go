@@ -7,8 +7,16 @@ import (
 	"github.com/artie-labs/transfer/lib/array"
 )
 
+const (
+	// TimePartitionType will partition the table by a timestamp or date column.
+	TimePartitionType = "time"
+	// IngestionTimePartitionType will partition the table by when the rows were loaded, so it does not require a partition field.
+	IngestionTimePartitionType = "ingestion"
+)
+
 var ValidPartitionTypes = []string{
-	"time",
+	TimePartitionType,
+	IngestionTimePartitionType,
 }
 
 // TODO: We should be able to support different partition by fields in the future.
@@ -34,7 +42,7 @@ func (b *BigQuerySettings) GenerateMergeString(values []string) (string, error)
 	}
 
 	switch b.PartitionType {
-	case "time":
+	case TimePartitionType:
 		switch b.PartitionBy {
 		case "daily":
 			return fmt.Sprintf(`DATE(c.%s) IN (%s)`, b.PartitionField, array.StringsJoinAddSingleQuotes(values)), nil
@@ -44,6 +52,11 @@ func (b *BigQuerySettings) GenerateMergeString(values []string) (string, error)
 	return "", fmt.Errorf("unexpected partitionType: %s and/or partitionBy: %s", b.PartitionType, b.PartitionBy)
 }
 
+// HasPartitionField returns true if the table is partitioned by a column in the data.
+func (b *BigQuerySettings) HasPartitionField() bool {
+	return b != nil && b.PartitionType == TimePartitionType
+}
+
 func (b *BigQuerySettings) Valid() error {
 	if b == nil {
 		return fmt.Errorf("bigQuerySettings is nil")
@@ -53,7 +66,7 @@ func (b *BigQuerySettings) Valid() error {
 		return fmt.Errorf("partitionTypes cannot be empty")
 	}
 
-	if b.PartitionField == "" {
+	if b.PartitionField == "" && b.PartitionType != IngestionTimePartitionType {
 		return fmt.Errorf("partitionField cannot be empty")
 	}
 
@@ -62,6 +62,13 @@ func TestBigQuerySettings_Valid(t *testing.T) {
 				PartitionBy:    "daily",
 			},
 		},
+		{
+			name: "valid (ingestion time does not need a partitionField)",
+			bigQuerySettings: &BigQuerySettings{
+				PartitionType: "ingestion",
+				PartitionBy:   "daily",
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -118,3 +125,10 @@ func TestBigQuerySettings_GenerateMergeString(t *testing.T) {
 		}
 	}
 }
+
+func TestBigQuerySettings_HasPartitionField(t *testing.T) {
+	var settings *BigQuerySettings
+	assert.False(t, settings.HasPartitionField())
+	assert.True(t, (&BigQuerySettings{PartitionType: TimePartitionType, PartitionField: "created_at", PartitionBy: "daily"}).HasPartitionField())
+	assert.False(t, (&BigQuerySettings{PartitionType: IngestionTimePartitionType, PartitionBy: "daily"}).HasPartitionField())
+}
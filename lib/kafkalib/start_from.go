@@ -0,0 +1,70 @@
+package kafkalib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type StartFromKind string
+
+const (
+	StartFromEarliest  StartFromKind = "earliest"
+	StartFromLatest    StartFromKind = "latest"
+	StartFromTimestamp StartFromKind = "timestamp"
+	StartFromOffsets   StartFromKind = "offsets"
+)
+
+// StartFrom is where the consumer should start reading from instead of the committed group offset.
+type StartFrom struct {
+	Kind StartFromKind
+	// Timestamp is set if Kind is StartFromTimestamp.
+	Timestamp time.Time
+	// PartitionOffsets is set if Kind is StartFromOffsets, partitions that are not in this map will start from the committed offset.
+	PartitionOffsets map[int]int64
+}
+
+// ParseStartFrom will parse `earliest`, `latest`, an RFC 3339 timestamp or explicit partition offsets (e.g. `0:100,1:250`).
+// This returns nil if `value` is empty.
+func ParseStartFrom(value string) (*StartFrom, error) {
+	value = strings.TrimSpace(value)
+	switch value {
+	case "":
+		return nil, nil
+	case string(StartFromEarliest):
+		return &StartFrom{Kind: StartFromEarliest}, nil
+	case string(StartFromLatest):
+		return &StartFrom{Kind: StartFromLatest}, nil
+	}
+
+	if ts, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return &StartFrom{Kind: StartFromTimestamp, Timestamp: ts}, nil
+	}
+
+	partitionOffsets := make(map[int]int64)
+	for _, part := range strings.Split(value, ",") {
+		partition, offset, isOk := strings.Cut(strings.TrimSpace(part), ":")
+		if !isOk {
+			return nil, fmt.Errorf("%q is not earliest, latest, an RFC 3339 timestamp or partition:offset pairs", value)
+		}
+
+		partitionID, err := strconv.Atoi(partition)
+		if err != nil || partitionID < 0 {
+			return nil, fmt.Errorf("invalid partition: %q", partition)
+		}
+
+		partitionOffset, err := strconv.ParseInt(offset, 10, 64)
+		if err != nil || partitionOffset < 0 {
+			return nil, fmt.Errorf("invalid offset: %q for partition: %d", offset, partitionID)
+		}
+
+		if _, isOk = partitionOffsets[partitionID]; isOk {
+			return nil, fmt.Errorf("duplicate partition: %d", partitionID)
+		}
+
+		partitionOffsets[partitionID] = partitionOffset
+	}
+
+	return &StartFrom{Kind: StartFromOffsets, PartitionOffsets: partitionOffsets}, nil
+}
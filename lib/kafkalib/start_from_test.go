@@ -0,0 +1,54 @@
+package kafkalib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStartFrom(t *testing.T) {
+	{
+		// Not set
+		startFrom, err := ParseStartFrom("")
+		assert.NoError(t, err)
+		assert.Nil(t, startFrom)
+	}
+	{
+		// Earliest and latest
+		startFrom, err := ParseStartFrom("earliest")
+		assert.NoError(t, err)
+		assert.Equal(t, &StartFrom{Kind: StartFromEarliest}, startFrom)
+
+		startFrom, err = ParseStartFrom("latest")
+		assert.NoError(t, err)
+		assert.Equal(t, &StartFrom{Kind: StartFromLatest}, startFrom)
+	}
+	{
+		// Timestamp
+		startFrom, err := ParseStartFrom("2024-03-01T12:30:00Z")
+		assert.NoError(t, err)
+		assert.Equal(t, StartFromTimestamp, startFrom.Kind)
+		assert.True(t, time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC).Equal(startFrom.Timestamp))
+	}
+	{
+		// Partition offsets
+		startFrom, err := ParseStartFrom("0:100, 1:250")
+		assert.NoError(t, err)
+		assert.Equal(t, &StartFrom{Kind: StartFromOffsets, PartitionOffsets: map[int]int64{0: 100, 1: 250}}, startFrom)
+	}
+	{
+		// Invalid
+		_, err := ParseStartFrom("yesterday")
+		assert.ErrorContains(t, err, `"yesterday" is not earliest, latest, an RFC 3339 timestamp or partition:offset pairs`)
+
+		_, err = ParseStartFrom("a:100")
+		assert.ErrorContains(t, err, `invalid partition: "a"`)
+
+		_, err = ParseStartFrom("0:-5")
+		assert.ErrorContains(t, err, `invalid offset: "-5" for partition: 0`)
+
+		_, err = ParseStartFrom("0:1,0:2")
+		assert.ErrorContains(t, err, "duplicate partition: 0")
+	}
+}
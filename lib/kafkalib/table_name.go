@@ -0,0 +1,55 @@
+package kafkalib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	databasePlaceholder = "{database}"
+	schemaPlaceholder   = "{schema}"
+	tablePlaceholder    = "{table}"
+)
+
+var (
+	placeholderRegex = regexp.MustCompile(`\{[^{}]*\}`)
+	identifierRegex  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+)
+
+// TableNameFor returns the destination table name for `table` after applying the topic's `TableNameTemplate` (if set).
+func (t TopicConfig) TableNameFor(table string) string {
+	if t.TableNameTemplate == "" {
+		return table
+	}
+
+	return strings.NewReplacer(
+		databasePlaceholder, t.Database,
+		schemaPlaceholder, t.Schema,
+		tablePlaceholder, table,
+	).Replace(t.TableNameTemplate)
+}
+
+func (t TopicConfig) validateTableNameTemplate() error {
+	if t.TableNameTemplate == "" {
+		return nil
+	}
+
+	for _, placeholder := range placeholderRegex.FindAllString(t.TableNameTemplate, -1) {
+		switch placeholder {
+		case databasePlaceholder, schemaPlaceholder, tablePlaceholder:
+		default:
+			return fmt.Errorf("unsupported placeholder: %q", placeholder)
+		}
+	}
+
+	if !strings.Contains(t.TableNameTemplate, tablePlaceholder) {
+		return fmt.Errorf("template must contain %s, otherwise every table would be written to the same destination table", tablePlaceholder)
+	}
+
+	if name := t.TableNameFor("table"); !identifierRegex.MatchString(name) {
+		return fmt.Errorf("template produces an invalid table name: %q", name)
+	}
+
+	return nil
+}
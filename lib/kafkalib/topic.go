@@ -6,7 +6,10 @@ import (
 	"strings"
 
 	"github.com/artie-labs/transfer/lib/array"
+	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/kafkalib/partition"
+	"github.com/artie-labs/transfer/lib/rowfilter"
+	"github.com/artie-labs/transfer/lib/typing"
 )
 
 type DatabaseSchemaPair struct {
@@ -33,23 +36,300 @@ func GetUniqueDatabaseAndSchema(tcs []*TopicConfig) []DatabaseSchemaPair {
 	return pairs
 }
 
+// GetUniqueStagingDatabaseAndSchema returns the unique database and schema pairs where the temporary tables are created.
+// If `stagingSchema` is set, the temporary tables are created in the staging schema of each database instead of the topic's schema.
+func GetUniqueStagingDatabaseAndSchema(tcs []*TopicConfig, stagingSchema string) []DatabaseSchemaPair {
+	if stagingSchema == "" {
+		return GetUniqueDatabaseAndSchema(tcs)
+	}
+
+	var stagingTcs []*TopicConfig
+	for _, tc := range tcs {
+		stagingTcs = append(stagingTcs, &TopicConfig{Database: tc.Database, Schema: stagingSchema})
+	}
+
+	return GetUniqueDatabaseAndSchema(stagingTcs)
+}
+
 type TopicConfig struct {
-	Database                  string                      `yaml:"db"`
-	TableName                 string                      `yaml:"tableName"`
-	Schema                    string                      `yaml:"schema"`
-	Topic                     string                      `yaml:"topic"`
-	IdempotentKey             string                      `yaml:"idempotentKey,omitempty"`
-	CDCFormat                 string                      `yaml:"cdcFormat"`
-	CDCKeyFormat              string                      `yaml:"cdcKeyFormat"`
-	DropDeletedColumns        bool                        `yaml:"dropDeletedColumns"`
-	SoftDelete                bool                        `yaml:"softDelete"`
-	SkippedOperations         string                      `yaml:"skippedOperations,omitempty"`
-	IncludeArtieUpdatedAt     bool                        `yaml:"includeArtieUpdatedAt"`
-	IncludeDatabaseUpdatedAt  bool                        `yaml:"includeDatabaseUpdatedAt"`
+	Database                 string             `yaml:"db"`
+	TableName                string             `yaml:"tableName"`
+	Schema                   string             `yaml:"schema"`
+	Topic                    string             `yaml:"topic"`
+	IdempotentKey            string             `yaml:"idempotentKey,omitempty"`
+	CDCFormat                string             `yaml:"cdcFormat"`
+	CDCKeyFormat             string             `yaml:"cdcKeyFormat"`
+	DropDeletedColumns       bool               `yaml:"dropDeletedColumns"`
+	SoftDelete               bool               `yaml:"softDelete"`
+	SoftDeleteStrategy       SoftDeleteStrategy `yaml:"softDeleteStrategy,omitempty"`
+	SoftDeleteMarker         SoftDeleteMarker   `yaml:"softDeleteMarker,omitempty"`
+	SkippedOperations        string             `yaml:"skippedOperations,omitempty"`
+	IncludeArtieUpdatedAt    bool               `yaml:"includeArtieUpdatedAt"`
+	IncludeDatabaseUpdatedAt bool               `yaml:"includeDatabaseUpdatedAt"`
+	// IncludeSnapshotColumn is opt-in, if enabled every row will have a `__artie_snapshot` column that is true if the row was read by a snapshot.
+	IncludeSnapshotColumn     bool                        `yaml:"includeSnapshotColumn,omitempty"`
 	BigQueryPartitionSettings *partition.BigQuerySettings `yaml:"bigQueryPartitionSettings,omitempty"`
+	ProtobufSettings          *ProtobufSettings           `yaml:"protobufSettings,omitempty"`
+	// BigQueryClusterFields is optional, if set the BigQuery table will be clustered by these columns when it's created.
+	BigQueryClusterFields []string `yaml:"bigQueryClusterFields,omitempty"`
+	// Dataset is optional and only used by BigQuery, if set the table will be written into this dataset instead of `Database`.
+	// This allows topics to pick their dataset independently of the database that they are reported under.
+	Dataset string `yaml:"dataset,omitempty"`
+	// ProcessOps is an optional allow-list of the Debezium operations that will be applied (e.g. `["c", "u", "d"]` to skip snapshot reads).
+	// Events with any other operation will be skipped, if this is not set every operation (that isn't in SkippedOperations) is processed.
+	ProcessOps []string `yaml:"processOps,omitempty"`
+	// RowFilter is an optional predicate, rows that do not match will be dropped, e.g. `tenant_id = 42 AND status != 'deleted'`
+	// Supported operators are =, !=, <, <=, >, >= and IN.
+	RowFilter string `yaml:"rowFilter,omitempty"`
+	// Warehouse and Role are optional Snowflake overrides, if set `USE WAREHOUSE` and `USE ROLE` will be run on the connection before this topic is flushed.
+	Warehouse *string `yaml:"warehouse,omitempty"`
+	Role      *string `yaml:"role,omitempty"`
+	// PrimaryKeyOverride is optional, if set these columns will be used as the primary keys instead of the message key.
+	// This is useful for tables without a primary key (or views).
+	PrimaryKeyOverride []string `yaml:"primaryKeyOverride,omitempty"`
+	// ColumnRenames is an optional map of source column name to destination column name.
+	ColumnRenames map[string]string `yaml:"columnRenames,omitempty"`
+	// Assertions are optional checks that will be run against the destination table after each flush.
+	Assertions []AssertionConfig `yaml:"assertions,omitempty"`
+	// AppendOnly is for immutable tables (e.g. event logs), rows will be appended to the destination table without deduping or merging on the primary keys.
+	AppendOnly bool `yaml:"appendOnly,omitempty"`
+	// PartialUpdate is for sources that only emit the columns that have changed, the merge will only update the columns that each row carried.
+	// Columns that were not in the event will keep their existing value in the destination instead of being overwritten with NULL.
+	PartialUpdate bool `yaml:"partialUpdate,omitempty"`
+	// UnavailableValuePlaceholder is optional and should match Debezium's `unavailable.value.placeholder` if it has been changed.
+	// Columns with this value have not changed (e.g. TOAST columns), so we will preserve the existing value in the destination.
+	UnavailableValuePlaceholder string `yaml:"unavailableValuePlaceholder,omitempty"`
+	// TableNameTemplate is optional and is applied to the destination table name, e.g. `prod_{table}` or `{schema}__{table}`.
+	// Supported placeholders are {database}, {schema} and {table}.
+	TableNameTemplate string `yaml:"tableNameTemplate,omitempty"`
+	// HandleTruncate is opt-in, if enabled Debezium truncate events will truncate the destination table. Otherwise, they are skipped.
+	HandleTruncate bool `yaml:"handleTruncate,omitempty"`
+	// Compacted is opt-in and should be set for log-compacted topics, a message without a value (tombstone) will be treated as a delete for its key.
+	// Otherwise, tombstones are skipped. Tombstones do not carry the source table, so `tableName` is required.
+	Compacted bool `yaml:"compacted,omitempty"`
+	// ApplySchemaChanges should only be set on Debezium's schema change topic, columns that are added in the source will be added to the destination tables.
+	ApplySchemaChanges bool `yaml:"applySchemaChanges,omitempty"`
+	// TreatStringsAsJSON is optional, if enabled string values that are valid JSON objects or arrays will be inferred as JSON for this topic.
+	TreatStringsAsJSON bool `yaml:"treatStringsAsJSON,omitempty"`
+	// DecimalToFloat is opt-in, if enabled decimal columns will be loaded as floats. This is a tradeoff of precision for speed and storage.
+	DecimalToFloat bool `yaml:"decimalToFloat,omitempty"`
+	// IncludeMetadataColumns is opt-in, if enabled every row will have the operation, source timestamp, binlog file and position columns.
+	// MetadataColumns is optional and can be used to only include a subset of them.
+	IncludeMetadataColumns bool             `yaml:"includeMetadataColumns,omitempty"`
+	MetadataColumns        []MetadataColumn `yaml:"metadataColumns,omitempty"`
+	// NullPrimaryKeyPolicy is what we'll do with rows that have a null primary key value, this defaults to skip.
+	NullPrimaryKeyPolicy NullPrimaryKeyPolicy `yaml:"nullPrimaryKeyPolicy,omitempty"`
+	// MaxColumns and ColumnAllowList are optional and are used to cap how wide the destination table can get.
+	// New columns that are over the limit (or not in the allow-list) will be folded into the `__artie_overflow` JSON column instead of being added to the table.
+	// Primary keys and columns that already exist in the destination will always stay typed.
+	MaxColumns      int      `yaml:"maxColumns,omitempty"`
+	ColumnAllowList []string `yaml:"columnAllowList,omitempty"`
+	// BytesEncoding is how Debezium `bytes` fields will be written, this defaults to the base64 string that Debezium sends.
+	BytesEncoding BytesEncoding `yaml:"bytesEncoding,omitempty"`
+	// ImmutableColumns is optional, these columns will only be written when the row is inserted and will not be overwritten by updates (e.g. `created_at`).
+	ImmutableColumns []string `yaml:"immutableColumns,omitempty"`
+	// ColumnTypeOverrides is an optional map of column name to type (e.g. `string`, `integer`, `decimal(10, 2)` or `timestamp`).
+	// These columns will be created with this type instead of the one we would have inferred.
+	ColumnTypeOverrides map[string]string `yaml:"columnTypeOverrides,omitempty"`
+	// BooleanColumns is an optional list of integer columns that should be written as booleans (0 is false, everything else is true).
+	// This is useful for MySQL `tinyint(1)` columns, which Debezium will emit as integers.
+	BooleanColumns []string `yaml:"booleanColumns,omitempty"`
+	// ColumnMaxLengths is an optional map of column name to the max length (in bytes) of its values, this overrides the global max value length.
+	ColumnMaxLengths map[string]int `yaml:"columnMaxLengths,omitempty"`
+	// FlushIntervalSeconds, FlushSizeKb and BufferRows are optional overrides of the global flush settings for this topic.
+	// This is useful when a consumer has a mix of high and low volume topics, if they're not set we'll use the global settings.
+	FlushIntervalSeconds int  `yaml:"flushIntervalSeconds,omitempty"`
+	FlushSizeKb          int  `yaml:"flushSizeKb,omitempty"`
+	BufferRows           uint `yaml:"bufferRows,omitempty"`
 
 	// Internal metadata
-	opsToSkipMap map[string]bool `yaml:"-"`
+	opsToSkipMap        map[string]bool               `yaml:"-"`
+	opsToProcessMap     map[string]bool               `yaml:"-"`
+	rowFilter           *rowfilter.Filter             `yaml:"-"`
+	rowFilterErr        error                         `yaml:"-"`
+	columnTypeOverrides map[string]typing.KindDetails `yaml:"-"`
+}
+
+// ProtobufSettings is used to decode topics that have a Protobuf CDC format.
+type ProtobufSettings struct {
+	// DescriptorSetPath is the path to a serialized `FileDescriptorSet`, this can be generated with: protoc --include_imports --descriptor_set_out=...
+	DescriptorSetPath string `yaml:"descriptorSetPath"`
+	// MessageName is the fully qualified name of the Debezium envelope message, e.g. `dbserver1.public.customers.Envelope`
+	MessageName string `yaml:"messageName"`
+}
+
+func (p *ProtobufSettings) Validate() error {
+	if p == nil {
+		return fmt.Errorf("protobuf settings are nil")
+	}
+
+	if array.Empty([]string{p.DescriptorSetPath, p.MessageName}) {
+		return fmt.Errorf("protobuf descriptor set path or message name is empty")
+	}
+
+	return nil
+}
+
+type AssertionSeverity string
+
+const (
+	// AssertionSeverityError is the default, a failed assertion will fail the flush.
+	AssertionSeverityError AssertionSeverity = "error"
+	// AssertionSeverityWarn will log and emit a metric for a failed assertion, but the flush will still succeed.
+	AssertionSeverityWarn AssertionSeverity = "warn"
+)
+
+type AssertionConfig struct {
+	Name string `yaml:"name"`
+	// Type is the name the assertion was registered under, see the `assertions` package.
+	Type string `yaml:"type"`
+	// Column is used by assertions that check a specific column, e.g. `notNull`.
+	Column string `yaml:"column,omitempty"`
+	// SQL is used by the `sql` assertion, `{table}` will be replaced with the fully qualified table name.
+	SQL string `yaml:"sql,omitempty"`
+	// Min and Max are the inclusive bounds that the value returned by the assertion's query must fall within.
+	Min      *float64          `yaml:"min,omitempty"`
+	Max      *float64          `yaml:"max,omitempty"`
+	Severity AssertionSeverity `yaml:"severity,omitempty"`
+}
+
+func (a AssertionConfig) Validate() error {
+	if array.Empty([]string{a.Name, a.Type}) {
+		return fmt.Errorf("assertion name or type is empty")
+	}
+
+	if a.Min != nil && a.Max != nil && *a.Min > *a.Max {
+		return fmt.Errorf("assertion %q min: %v is greater than max: %v", a.Name, *a.Min, *a.Max)
+	}
+
+	switch a.Severity {
+	case "", AssertionSeverityError, AssertionSeverityWarn:
+		return nil
+	}
+
+	return fmt.Errorf("assertion %q has an invalid severity: %q", a.Name, a.Severity)
+}
+
+// IsWarning returns true if a failure of this assertion should not fail the flush.
+func (a AssertionConfig) IsWarning() bool {
+	return a.Severity == AssertionSeverityWarn
+}
+
+type SoftDeleteStrategy string
+
+const (
+	// SoftDeleteUpdate is the default, deletes will only contain the primary keys and the delete marker.
+	// Destinations that can merge will update the existing row.
+	SoftDeleteUpdate SoftDeleteStrategy = "update"
+	// SoftDeleteTombstone will emit a tombstone row for deletes which contains the `before` image of the row and the delete marker.
+	// This is useful for append-only destinations (e.g. S3) where we cannot update the existing row, the tombstone is written as a new row.
+	// It requires `softDelete`, otherwise the merge would hard delete the row. Since `appendOnly` topics cannot use `softDelete`, it cannot be used with `appendOnly` either.
+	SoftDeleteTombstone SoftDeleteStrategy = "tombstone"
+)
+
+func (s SoftDeleteStrategy) Validate() error {
+	switch s {
+	case "", SoftDeleteUpdate, SoftDeleteTombstone:
+		return nil
+	}
+
+	return fmt.Errorf("invalid soft delete strategy: %q", s)
+}
+
+type SoftDeleteMarker string
+
+const (
+	// SoftDeleteMarkerBoolean is the default, deleted rows will have `__artie_delete` set to true.
+	SoftDeleteMarkerBoolean SoftDeleteMarker = "boolean"
+	// SoftDeleteMarkerTimestamp will only have `__artie_deleted_at`, this is set to the delete event's source timestamp and is NULL for live rows.
+	SoftDeleteMarkerTimestamp SoftDeleteMarker = "timestamp"
+	// SoftDeleteMarkerBoth will have both `__artie_delete` and `__artie_deleted_at`.
+	SoftDeleteMarkerBoth SoftDeleteMarker = "both"
+)
+
+func (s SoftDeleteMarker) Validate() error {
+	switch s {
+	case "", SoftDeleteMarkerBoolean, SoftDeleteMarkerTimestamp, SoftDeleteMarkerBoth:
+		return nil
+	}
+
+	return fmt.Errorf("invalid soft delete marker: %q", s)
+}
+
+type NullPrimaryKeyPolicy string
+
+const (
+	// NullPrimaryKeyPolicySkip is the default, the row will be skipped and we'll log a warning and emit a metric.
+	NullPrimaryKeyPolicySkip NullPrimaryKeyPolicy = "skip"
+	// NullPrimaryKeyPolicyError will stop consuming the partition until the row has been fixed or the policy has been changed.
+	NullPrimaryKeyPolicyError NullPrimaryKeyPolicy = "error"
+	// NullPrimaryKeyPolicyRouteToDLQ will publish the row to the dead letter topic, this requires `flushRetry.deadLetterTopic` to be set.
+	NullPrimaryKeyPolicyRouteToDLQ NullPrimaryKeyPolicy = "route-to-dlq"
+)
+
+func (n NullPrimaryKeyPolicy) Validate() error {
+	switch n {
+	case "", NullPrimaryKeyPolicySkip, NullPrimaryKeyPolicyError, NullPrimaryKeyPolicyRouteToDLQ:
+		return nil
+	}
+
+	return fmt.Errorf("invalid null primary key policy: %q", n)
+}
+
+type BytesEncoding string
+
+const (
+	BytesEncodingBase64 BytesEncoding = "base64"
+	BytesEncodingHex    BytesEncoding = "hex"
+	// BytesEncodingNative will write the bytes into a native binary column, this is only supported by Snowflake and BigQuery.
+	BytesEncodingNative BytesEncoding = "native"
+)
+
+func (b BytesEncoding) Validate() error {
+	switch b {
+	case "", BytesEncodingBase64, BytesEncodingHex, BytesEncodingNative:
+		return nil
+	}
+
+	return fmt.Errorf("invalid bytes encoding: %q", b)
+}
+
+type MetadataColumn string
+
+const (
+	// MetadataColumnOp is the Debezium operation (c, u, d or r).
+	MetadataColumnOp MetadataColumn = "op"
+	// MetadataColumnSourceTsMs is when the change was made in the source database.
+	MetadataColumnSourceTsMs MetadataColumn = "source_ts_ms"
+	// MetadataColumnFile and MetadataColumnPos are the binlog file and position, Postgres does not have a file and will use the LSN as the position.
+	MetadataColumnFile MetadataColumn = "file"
+	MetadataColumnPos  MetadataColumn = "pos"
+)
+
+var allMetadataColumns = []MetadataColumn{MetadataColumnOp, MetadataColumnSourceTsMs, MetadataColumnFile, MetadataColumnPos}
+
+func (m MetadataColumn) Validate() error {
+	if slices.Contains(allMetadataColumns, m) {
+		return nil
+	}
+
+	return fmt.Errorf("invalid metadata column: %q", m)
+}
+
+// ColumnName returns the name of the destination column.
+func (m MetadataColumn) ColumnName() string {
+	switch m {
+	case MetadataColumnOp:
+		return constants.OpColumnMarker
+	case MetadataColumnSourceTsMs:
+		return constants.SourceTsMsColumnMarker
+	case MetadataColumnFile:
+		return constants.FileColumnMarker
+	case MetadataColumnPos:
+		return constants.PosColumnMarker
+	}
+
+	return ""
 }
 
 const (
@@ -59,6 +339,23 @@ const (
 
 var validKeyFormats = []string{StringKeyFmt, JSONKeyFmt}
 
+// validCDCFormats - An empty CDC format will detect the format from the message.
+var validCDCFormats = []string{
+	"",
+	constants.AutoFormat,
+	constants.DBZPostgresFormat,
+	constants.DBZPostgresAltFormat,
+	constants.DBZMongoFormat,
+	constants.DBZMySQLFormat,
+	constants.DBZAvroFormat,
+	constants.DBZAvroAltFormat,
+	constants.DBZProtobufFormat,
+	constants.DBZProtobufAltFormat,
+}
+
+// knownOperations are the operations that Debezium emits for row changes: create, read (snapshot), update and delete.
+var knownOperations = []string{"c", "r", "u", "d"}
+
 func (t *TopicConfig) Load() {
 	// Operations that we support today:
 	// 1. c - create
@@ -71,15 +368,123 @@ func (t *TopicConfig) Load() {
 		// Lowercase and trim space.
 		t.opsToSkipMap[strings.ToLower(strings.TrimSpace(op))] = true
 	}
+
+	t.opsToProcessMap = nil
+	if len(t.ProcessOps) > 0 {
+		t.opsToProcessMap = make(map[string]bool)
+		for _, op := range t.ProcessOps {
+			t.opsToProcessMap[strings.ToLower(strings.TrimSpace(op))] = true
+		}
+	}
+
+	t.rowFilter, t.rowFilterErr = nil, nil
+	if strings.TrimSpace(t.RowFilter) != "" {
+		// If the row filter is invalid, this will be returned by Validate()
+		t.rowFilter, t.rowFilterErr = rowfilter.Parse(t.RowFilter)
+	}
+
+	t.columnTypeOverrides = nil
+	for colName, value := range t.ColumnTypeOverrides {
+		// If the type is invalid, this will be returned by Validate()
+		if kindDetails, err := typing.ParseKindOverride(value); err == nil {
+			if t.columnTypeOverrides == nil {
+				t.columnTypeOverrides = make(map[string]typing.KindDetails)
+			}
+
+			t.columnTypeOverrides[strings.ToLower(colName)] = kindDetails
+		}
+	}
+}
+
+// ColumnTypeOverride returns the type that `colName` should be created with, if it has been overridden.
+func (t TopicConfig) ColumnTypeOverride(colName string) (typing.KindDetails, bool) {
+	kindDetails, isOk := t.columnTypeOverrides[strings.ToLower(colName)]
+	return kindDetails, isOk
+}
+
+// IsBooleanColumn returns whether `colName` is an integer column that should be written as a boolean.
+func (t TopicConfig) IsBooleanColumn(colName string) bool {
+	return slices.ContainsFunc(t.BooleanColumns, func(name string) bool {
+		return strings.EqualFold(name, colName)
+	})
 }
 
+// MaxValueLength returns the max length for `colName`'s values, if the column does not have an override then we'll return `defaultMax`.
+func (t TopicConfig) MaxValueLength(colName string, defaultMax int) int {
+	for name, maxLength := range t.ColumnMaxLengths {
+		if strings.EqualFold(name, colName) {
+			return maxLength
+		}
+	}
+
+	return defaultMax
+}
+
+// ShouldSkip returns true if events with this operation should not be applied, either because it has been skipped or it's not in ProcessOps.
 func (t TopicConfig) ShouldSkip(op string) bool {
 	if t.opsToSkipMap == nil {
 		panic("opsToSkipMap is nil, Load() was never called")
 	}
 
-	_, isOk := t.opsToSkipMap[op]
-	return isOk
+	if _, isOk := t.opsToSkipMap[op]; isOk {
+		return true
+	}
+
+	return t.opsToProcessMap != nil && !t.opsToProcessMap[op]
+}
+
+// IsKnownOperation returns true if `op` is one of the row level operations that Debezium emits.
+func IsKnownOperation(op string) bool {
+	return slices.Contains(knownOperations, op)
+}
+
+// ShouldKeepRow returns false if the topic has a row filter and `row` does not match it.
+func (t TopicConfig) ShouldKeepRow(row map[string]any) bool {
+	return t.rowFilter.Matches(row)
+}
+
+// TombstoneDeletes returns true if deletes should carry the `before` image of the row.
+func (t TopicConfig) TombstoneDeletes() bool {
+	return t.SoftDeleteStrategy == SoftDeleteTombstone
+}
+
+// IsUnavailableValue returns true if `value` is Debezium's placeholder for a column value that is unavailable.
+func (t TopicConfig) IsUnavailableValue(value any) bool {
+	placeholder := t.UnavailableValuePlaceholder
+	if placeholder == "" {
+		placeholder = constants.ToastUnavailableValuePlaceholder
+	}
+
+	stringValue, isOk := value.(string)
+	return isOk && stringValue == placeholder
+}
+
+// IncludeDeleteColumn returns true if the destination table should have the `__artie_delete` column.
+func (t TopicConfig) IncludeDeleteColumn() bool {
+	return t.SoftDelete && t.SoftDeleteMarker != SoftDeleteMarkerTimestamp
+}
+
+// IncludeDeletedAtColumn returns true if the destination table should have the `__artie_deleted_at` column.
+func (t TopicConfig) IncludeDeletedAtColumn() bool {
+	return t.SoftDelete && (t.SoftDeleteMarker == SoftDeleteMarkerTimestamp || t.SoftDeleteMarker == SoftDeleteMarkerBoth)
+}
+
+// MetadataColumnsToInclude returns the metadata columns that should be added to each row, this will be empty if they are not enabled.
+func (t TopicConfig) MetadataColumnsToInclude() []MetadataColumn {
+	if !t.IncludeMetadataColumns {
+		return nil
+	}
+
+	if len(t.MetadataColumns) > 0 {
+		return t.MetadataColumns
+	}
+
+	return allMetadataColumns
+}
+
+// OverflowEnabled returns true if columns can be folded into the overflow column.
+func (t TopicConfig) OverflowEnabled() bool {
+	return t.MaxColumns > 0 || len(t.ColumnAllowList) > 0
 }
 
 func (t TopicConfig) String() string {
@@ -87,11 +492,51 @@ func (t TopicConfig) String() string {
 		t.Database, t.Schema, t.TableName, t.Topic, t.IdempotentKey, t.CDCFormat, t.DropDeletedColumns, t.SkippedOperations)
 }
 
+// BigQueryDataset returns the dataset that the table will be written into for BigQuery.
+func (t TopicConfig) BigQueryDataset() string {
+	if t.Dataset != "" {
+		return t.Dataset
+	}
+
+	return t.Database
+}
+
+// ValidateTarget will check that the parts of the fully qualified table name that `destKind` needs have been set.
+func (t TopicConfig) ValidateTarget(destKind constants.DestinationKind) error {
+	switch destKind {
+	case constants.BigQuery:
+		// project_id.dataset.table, the project is configured on the destination.
+		if t.BigQueryDataset() == "" {
+			return fmt.Errorf("database or dataset is required for %s, topic: %q", destKind, t.Topic)
+		}
+	case constants.Redshift, constants.MSSQL, constants.DuckDB, constants.Databricks:
+		// schema.table, the database (or catalog) is picked when we connect.
+		if t.Schema == "" {
+			return fmt.Errorf("schema is required for %s, topic: %q", destKind, t.Topic)
+		}
+	default:
+		// db.schema.table
+		if t.Database == "" || t.Schema == "" {
+			return fmt.Errorf("database and schema are required for %s, topic: %q", destKind, t.Topic)
+		}
+	}
+
+	// Iceberg merges replace the whole row, so we cannot keep the existing value of an immutable column.
+	if destKind == constants.Iceberg && len(t.ImmutableColumns) > 0 {
+		return fmt.Errorf("immutableColumns is not supported for %s, topic: %q", destKind, t.Topic)
+	}
+
+	return nil
+}
+
 func (t TopicConfig) Validate() error {
-	// IdempotentKey is optional.
-	empty := array.Empty([]string{t.Database, t.Schema, t.Topic, t.CDCFormat})
-	if empty {
-		return fmt.Errorf("database, schema, topic or cdc format is empty")
+	// IdempotentKey is optional, the database and schema are validated per destination, see [TopicConfig.ValidateTarget].
+	if t.Topic == "" {
+		return fmt.Errorf("topic is empty")
+	}
+
+	if !slices.Contains(validCDCFormats, t.CDCFormat) {
+		return fmt.Errorf("invalid cdc format: %q, valid formats are: %s", t.CDCFormat, strings.Join(validCDCFormats[1:], ", "))
 	}
 
 	if !slices.Contains(validKeyFormats, t.CDCKeyFormat) {
@@ -102,5 +547,203 @@ func (t TopicConfig) Validate() error {
 		return fmt.Errorf("opsToSkipMap is nil, call Load() first")
 	}
 
+	if t.rowFilterErr != nil {
+		return fmt.Errorf("invalid row filter: %w", t.rowFilterErr)
+	}
+
+	for _, op := range t.ProcessOps {
+		if !IsKnownOperation(strings.ToLower(strings.TrimSpace(op))) {
+			return fmt.Errorf("invalid process op: %q, valid ops are: %s", op, strings.Join(knownOperations, ", "))
+		}
+	}
+
+	if t.Compacted && t.TableName == "" {
+		return fmt.Errorf("table name is required for compacted topics")
+	}
+
+	for colName, value := range t.ColumnTypeOverrides {
+		if strings.TrimSpace(colName) == "" {
+			return fmt.Errorf("invalid column type override: column name cannot be empty")
+		}
+
+		if _, err := typing.ParseKindOverride(value); err != nil {
+			return fmt.Errorf("invalid column type override for column: %q: %w", colName, err)
+		}
+	}
+
+	seenBooleanColumns := make(map[string]bool)
+	for _, colName := range t.BooleanColumns {
+		if strings.TrimSpace(colName) == "" {
+			return fmt.Errorf("invalid boolean column: column name cannot be empty")
+		}
+
+		if seenBooleanColumns[strings.ToLower(colName)] {
+			return fmt.Errorf("invalid boolean column: %q is specified more than once", colName)
+		}
+
+		for overrideColName := range t.ColumnTypeOverrides {
+			if strings.EqualFold(overrideColName, colName) {
+				return fmt.Errorf("invalid boolean column: %q also has a column type override", colName)
+			}
+		}
+
+		seenBooleanColumns[strings.ToLower(colName)] = true
+	}
+
+	for colName, maxLength := range t.ColumnMaxLengths {
+		if strings.TrimSpace(colName) == "" {
+			return fmt.Errorf("invalid column max length: column name cannot be empty")
+		}
+
+		if maxLength <= 0 {
+			return fmt.Errorf("column max length for column: %q has to be a positive number, current value: %d", colName, maxLength)
+		}
+	}
+
+	if t.MaxColumns < 0 {
+		return fmt.Errorf("max columns cannot be negative, current value: %d", t.MaxColumns)
+	}
+
+	if slices.Contains(t.ColumnAllowList, "") {
+		return fmt.Errorf("column allow list cannot contain an empty column name")
+	}
+
+	if t.Warehouse != nil && strings.TrimSpace(*t.Warehouse) == "" {
+		return fmt.Errorf("warehouse override cannot be empty")
+	}
+
+	if t.Role != nil && strings.TrimSpace(*t.Role) == "" {
+		return fmt.Errorf("role override cannot be empty")
+	}
+
+	if err := t.SoftDeleteStrategy.Validate(); err != nil {
+		return err
+	}
+
+	if t.TombstoneDeletes() && !t.SoftDelete {
+		return fmt.Errorf("soft delete strategy: %q requires soft delete to be enabled", t.SoftDeleteStrategy)
+	}
+
+	if err := t.SoftDeleteMarker.Validate(); err != nil {
+		return err
+	}
+
+	if t.SoftDeleteMarker != "" && !t.SoftDelete {
+		return fmt.Errorf("soft delete marker requires soft delete to be enabled")
+	}
+
+	if err := t.NullPrimaryKeyPolicy.Validate(); err != nil {
+		return err
+	}
+
+	if err := t.BytesEncoding.Validate(); err != nil {
+		return err
+	}
+
+	for _, metadataColumn := range t.MetadataColumns {
+		if err := metadataColumn.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if len(t.MetadataColumns) > 0 && !t.IncludeMetadataColumns {
+		return fmt.Errorf("metadata columns requires include metadata columns to be enabled")
+	}
+
+	if t.AppendOnly && t.SoftDelete {
+		return fmt.Errorf("append only cannot be used with soft delete")
+	}
+
+	if t.AppendOnly && t.PartialUpdate {
+		return fmt.Errorf("append only cannot be used with partial update")
+	}
+
+	if err := t.validateTableNameTemplate(); err != nil {
+		return fmt.Errorf("invalid table name template: %w", err)
+	}
+
+	if err := validateColumnRenames(t.ColumnRenames); err != nil {
+		return fmt.Errorf("invalid column renames: %w", err)
+	}
+
+	if err := validateColumnList(t.PrimaryKeyOverride); err != nil {
+		return fmt.Errorf("invalid primary key override: %w", err)
+	}
+
+	if err := validateColumnList(t.ImmutableColumns); err != nil {
+		return fmt.Errorf("invalid immutable columns: %w", err)
+	}
+
+	if t.BigQueryPartitionSettings != nil {
+		if err := t.BigQueryPartitionSettings.Valid(); err != nil {
+			return fmt.Errorf("invalid bigquery partition settings: %w", err)
+		}
+	}
+
+	if err := validateColumnList(t.BigQueryClusterFields); err != nil {
+		return fmt.Errorf("invalid bigquery cluster fields: %w", err)
+	}
+
+	// https://cloud.google.com/bigquery/docs/clustered-tables#limitations
+	if len(t.BigQueryClusterFields) > 4 {
+		return fmt.Errorf("invalid bigquery cluster fields: at most 4 columns can be specified")
+	}
+
+	for _, assertion := range t.Assertions {
+		if err := assertion.Validate(); err != nil {
+			return fmt.Errorf("invalid assertion: %w", err)
+		}
+	}
+
+	if t.CDCFormat == constants.DBZProtobufFormat || t.CDCFormat == constants.DBZProtobufAltFormat {
+		if err := t.ProtobufSettings.Validate(); err != nil {
+			return fmt.Errorf("failed to validate protobuf settings: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func validateColumnList(cols []string) error {
+	seen := make(map[string]bool)
+	for _, col := range cols {
+		if strings.TrimSpace(col) == "" {
+			return fmt.Errorf("column name cannot be empty")
+		}
+
+		if seen[col] {
+			return fmt.Errorf("duplicate column: %q", col)
+		}
+
+		seen[col] = true
+	}
+
+	return nil
+}
+
+func validateColumnRenames(renames map[string]string) error {
+	destinations := make(map[string]string)
+	for source, destination := range renames {
+		if array.Empty([]string{source, destination}) {
+			return fmt.Errorf("source and destination column names cannot be empty")
+		}
+
+		// Column names are lowercased when we process the event, so we need to do the same here to detect duplicates.
+		destination = strings.ToLower(destination)
+		if otherSource, isOk := destinations[destination]; isOk {
+			return fmt.Errorf("columns %q and %q cannot both be renamed to %q", otherSource, source, destination)
+		}
+
+		destinations[destination] = source
+	}
+
+	// Renames are applied at the same time, so a column cannot be renamed to another column that is also being renamed.
+	// This rejects both chained (a -> b, b -> c) and swapped (a -> b, b -> a) renames.
+	for source := range renames {
+		if otherSource, isOk := destinations[strings.ToLower(source)]; isOk && otherSource != source {
+			return fmt.Errorf("column %q cannot be renamed to %q, since %q is also being renamed", otherSource, renames[otherSource], source)
+		}
+	}
+
 	return nil
 }
@@ -5,6 +5,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib/partition"
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/typing"
 )
 
 func TestGetUniqueDatabaseAndSchema(t *testing.T) {
@@ -96,6 +101,23 @@ func TestGetUniqueDatabaseAndSchema(t *testing.T) {
 	}
 }
 
+func TestGetUniqueStagingDatabaseAndSchema(t *testing.T) {
+	tcs := []*TopicConfig{
+		{Database: "db", Schema: "schema_uno"},
+		{Database: "db", Schema: "schema_deux"},
+		{Database: "db_two", Schema: "schema_uno"},
+	}
+
+	// No staging schema
+	assert.ElementsMatch(t, GetUniqueDatabaseAndSchema(tcs), GetUniqueStagingDatabaseAndSchema(tcs, ""))
+
+	// Staging schema
+	assert.ElementsMatch(t, []DatabaseSchemaPair{
+		{Database: "db", Schema: "staging"},
+		{Database: "db_two", Schema: "staging"},
+	}, GetUniqueStagingDatabaseAndSchema(tcs, "staging"))
+}
+
 func TestTopicConfig_String(t *testing.T) {
 	tc := TopicConfig{
 		Database:          "aaa",
@@ -116,9 +138,46 @@ func TestTopicConfig_String(t *testing.T) {
 	assert.Contains(t, tc.String(), fmt.Sprintf("skippedOperations=%s", tc.SkippedOperations), tc.String())
 }
 
+func TestTopicConfig_ValidateTarget(t *testing.T) {
+	{
+		// Snowflake needs both the database and schema
+		tc := TopicConfig{Topic: "orders", Schema: "public"}
+		assert.ErrorContains(t, tc.ValidateTarget(constants.Snowflake), `database and schema are required for snowflake, topic: "orders"`)
+		tc.Database = "shop"
+		assert.NoError(t, tc.ValidateTarget(constants.Snowflake))
+		tc.Schema = ""
+		assert.ErrorContains(t, tc.ValidateTarget(constants.Snowflake), `database and schema are required for snowflake, topic: "orders"`)
+	}
+	{
+		// Redshift only needs the schema
+		tc := TopicConfig{Topic: "orders"}
+		assert.ErrorContains(t, tc.ValidateTarget(constants.Redshift), `schema is required for redshift, topic: "orders"`)
+		tc.Schema = "public"
+		assert.NoError(t, tc.ValidateTarget(constants.Redshift))
+	}
+	{
+		// BigQuery only needs the dataset, which defaults to the database
+		tc := TopicConfig{Topic: "orders", Schema: "public"}
+		assert.ErrorContains(t, tc.ValidateTarget(constants.BigQuery), `database or dataset is required for bigquery, topic: "orders"`)
+		tc.Dataset = "warehouse"
+		assert.NoError(t, tc.ValidateTarget(constants.BigQuery))
+		assert.Equal(t, "warehouse", tc.BigQueryDataset())
+
+		tc = TopicConfig{Topic: "orders", Database: "shop"}
+		assert.NoError(t, tc.ValidateTarget(constants.BigQuery))
+		assert.Equal(t, "shop", tc.BigQueryDataset())
+	}
+	{
+		// Iceberg cannot keep the existing value of immutable columns
+		tc := TopicConfig{Topic: "orders", Database: "shop", Schema: "public", ImmutableColumns: []string{"created_at"}}
+		assert.ErrorContains(t, tc.ValidateTarget(constants.Iceberg), `immutableColumns is not supported for iceberg, topic: "orders"`)
+		assert.NoError(t, tc.ValidateTarget(constants.Snowflake))
+	}
+}
+
 func TestTopicConfig_Validate(t *testing.T) {
 	var tc TopicConfig
-	assert.ErrorContains(t, tc.Validate(), "database, schema, topic or cdc format is empty", tc.String())
+	assert.ErrorContains(t, tc.Validate(), "topic is empty", tc.String())
 
 	tc = TopicConfig{
 		Database:     "12",
@@ -129,11 +188,22 @@ func TestTopicConfig_Validate(t *testing.T) {
 		CDCKeyFormat: JSONKeyFmt,
 	}
 
+	assert.ErrorContains(t, tc.Validate(), `invalid cdc format: "aa", valid formats are: auto, debezium.postgres`)
+
+	tc.CDCFormat = constants.DBZPostgresFormat
 	assert.ErrorContains(t, tc.Validate(), "opsToSkipMap is nil, call Load() first")
 
 	tc.Load()
 	assert.NoError(t, tc.Validate(), tc.String())
 
+	// The format will be detected if it's not specified.
+	for _, cdcFormat := range []string{"", constants.AutoFormat} {
+		tc.CDCFormat = cdcFormat
+		assert.NoError(t, tc.Validate(), tc.String())
+	}
+
+	tc.CDCFormat = constants.DBZPostgresFormat
+
 	tc.CDCKeyFormat = "non_existent"
 	assert.ErrorContains(t, tc.Validate(), "invalid cdc key format: non_existent", tc.String())
 
@@ -141,6 +211,319 @@ func TestTopicConfig_Validate(t *testing.T) {
 		tc.CDCKeyFormat = validKeyFormat
 		assert.NoError(t, tc.Validate(), tc.String())
 	}
+
+	// Protobuf requires a descriptor set and message name
+	tc.CDCFormat = constants.DBZProtobufFormat
+	assert.ErrorContains(t, tc.Validate(), "protobuf settings are nil", tc.String())
+
+	tc.ProtobufSettings = &ProtobufSettings{DescriptorSetPath: "/tmp/customers.desc"}
+	assert.ErrorContains(t, tc.Validate(), "protobuf descriptor set path or message name is empty", tc.String())
+
+	tc.ProtobufSettings.MessageName = "dbserver1.public.customers.Envelope"
+	assert.NoError(t, tc.Validate(), tc.String())
+
+	// Row filters are compiled in Load()
+	tc.RowFilter = "tenant_id = "
+	tc.Load()
+	assert.ErrorContains(t, tc.Validate(), "invalid row filter: expected a value, got the end of the expression", tc.String())
+
+	tc.RowFilter = "tenant_id = 42"
+	tc.Load()
+	assert.NoError(t, tc.Validate(), tc.String())
+
+	// Column renames
+	tc.ColumnRenames = map[string]string{"select": ""}
+	assert.ErrorContains(t, tc.Validate(), "invalid column renames: source and destination column names cannot be empty", tc.String())
+
+	tc.ColumnRenames = map[string]string{"select": "selected", "choice": "SELECTED"}
+	assert.ErrorContains(t, tc.Validate(), `cannot both be renamed to "selected"`, tc.String())
+
+	tc.ColumnRenames = map[string]string{"first_name": "last_name", "last_name": "first_name"}
+	assert.ErrorContains(t, tc.Validate(), "is also being renamed", tc.String())
+
+	tc.ColumnRenames = map[string]string{"email": "email_address", "email_address": "legacy_email"}
+	assert.ErrorContains(t, tc.Validate(), `column "email" cannot be renamed to "email_address", since "email_address" is also being renamed`, tc.String())
+
+	tc.ColumnRenames = map[string]string{"select": "selected"}
+	assert.NoError(t, tc.Validate(), tc.String())
+
+	// Soft delete strategy
+	tc.SoftDeleteStrategy = "foo"
+	assert.ErrorContains(t, tc.Validate(), `invalid soft delete strategy: "foo"`, tc.String())
+
+	for _, strategy := range []SoftDeleteStrategy{"", SoftDeleteUpdate} {
+		tc.SoftDeleteStrategy = strategy
+		assert.NoError(t, tc.Validate(), tc.String())
+	}
+
+	// Without soft delete, the merge would hard delete the row and the before image would never be written.
+	tc.SoftDeleteStrategy = SoftDeleteTombstone
+	assert.ErrorContains(t, tc.Validate(), `soft delete strategy: "tombstone" requires soft delete to be enabled`, tc.String())
+
+	tc.SoftDelete = true
+	assert.NoError(t, tc.Validate(), tc.String())
+
+	tc.AppendOnly = true
+	assert.ErrorContains(t, tc.Validate(), "append only cannot be used with soft delete", tc.String())
+
+	tc.AppendOnly = false
+	tc.SoftDelete = false
+	tc.SoftDeleteStrategy = ""
+
+	// Primary key override
+	tc.PrimaryKeyOverride = []string{"order_id", " "}
+	assert.ErrorContains(t, tc.Validate(), "invalid primary key override: column name cannot be empty", tc.String())
+
+	tc.PrimaryKeyOverride = []string{"order_id", "order_id"}
+	assert.ErrorContains(t, tc.Validate(), `invalid primary key override: duplicate column: "order_id"`, tc.String())
+
+	tc.PrimaryKeyOverride = []string{"order_id", "line_no"}
+	assert.NoError(t, tc.Validate(), tc.String())
+
+	// Immutable columns
+	tc.ImmutableColumns = []string{"created_at", ""}
+	assert.ErrorContains(t, tc.Validate(), "invalid immutable columns: column name cannot be empty", tc.String())
+
+	tc.ImmutableColumns = []string{"created_at", "created_at"}
+	assert.ErrorContains(t, tc.Validate(), `invalid immutable columns: duplicate column: "created_at"`, tc.String())
+
+	tc.ImmutableColumns = []string{"created_at"}
+	assert.NoError(t, tc.Validate(), tc.String())
+
+	// Assertions
+	tc.Assertions = []AssertionConfig{{Type: "rowCount"}}
+	assert.ErrorContains(t, tc.Validate(), "invalid assertion: assertion name or type is empty", tc.String())
+
+	tc.Assertions = []AssertionConfig{{Name: "row count", Type: "rowCount", Min: ptr.ToFloat64(10), Max: ptr.ToFloat64(1)}}
+	assert.ErrorContains(t, tc.Validate(), `invalid assertion: assertion "row count" min: 10 is greater than max: 1`, tc.String())
+
+	tc.Assertions = []AssertionConfig{{Name: "row count", Type: "rowCount", Min: ptr.ToFloat64(1), Severity: "fatal"}}
+	assert.ErrorContains(t, tc.Validate(), `invalid assertion: assertion "row count" has an invalid severity: "fatal"`, tc.String())
+
+	tc.Assertions = []AssertionConfig{{Name: "row count", Type: "rowCount", Min: ptr.ToFloat64(1), Severity: AssertionSeverityWarn}}
+	assert.NoError(t, tc.Validate(), tc.String())
+
+	// Warehouse and role overrides
+	tc.Warehouse = ptr.ToString(" ")
+	assert.ErrorContains(t, tc.Validate(), "warehouse override cannot be empty", tc.String())
+
+	tc.Warehouse = ptr.ToString("compute_wh")
+	tc.Role = ptr.ToString("")
+	assert.ErrorContains(t, tc.Validate(), "role override cannot be empty", tc.String())
+
+	tc.Role = ptr.ToString("transformer")
+	assert.NoError(t, tc.Validate(), tc.String())
+
+	// BigQuery partitioning and clustering
+	tc.BigQueryPartitionSettings = &partition.BigQuerySettings{PartitionType: "time", PartitionBy: "daily"}
+	assert.ErrorContains(t, tc.Validate(), "invalid bigquery partition settings: partitionField cannot be empty", tc.String())
+
+	tc.BigQueryPartitionSettings.PartitionField = "created_at"
+	assert.NoError(t, tc.Validate(), tc.String())
+
+	tc.BigQueryClusterFields = []string{"customer_id", "customer_id"}
+	assert.ErrorContains(t, tc.Validate(), `invalid bigquery cluster fields: duplicate column: "customer_id"`, tc.String())
+
+	tc.BigQueryClusterFields = []string{"a", "b", "c", "d", "e"}
+	assert.ErrorContains(t, tc.Validate(), "invalid bigquery cluster fields: at most 4 columns can be specified", tc.String())
+
+	tc.BigQueryClusterFields = []string{"customer_id", "status"}
+	assert.NoError(t, tc.Validate(), tc.String())
+
+	// Append only
+	tc.AppendOnly = true
+	assert.NoError(t, tc.Validate(), tc.String())
+
+	tc.SoftDelete = true
+	assert.ErrorContains(t, tc.Validate(), "append only cannot be used with soft delete", tc.String())
+
+	tc.SoftDelete = false
+	tc.PartialUpdate = true
+	assert.ErrorContains(t, tc.Validate(), "append only cannot be used with partial update", tc.String())
+	tc.PartialUpdate = false
+	tc.SoftDelete = true
+
+	// Soft delete marker
+	tc.AppendOnly = false
+	tc.SoftDeleteMarker = "foo"
+	assert.ErrorContains(t, tc.Validate(), `invalid soft delete marker: "foo"`, tc.String())
+
+	tc.SoftDeleteMarker = SoftDeleteMarkerTimestamp
+	assert.NoError(t, tc.Validate(), tc.String())
+
+	tc.SoftDelete = false
+	assert.ErrorContains(t, tc.Validate(), "soft delete marker requires soft delete to be enabled", tc.String())
+	tc.SoftDeleteMarker = ""
+
+	// Null primary key policy
+	for _, policy := range []NullPrimaryKeyPolicy{NullPrimaryKeyPolicySkip, NullPrimaryKeyPolicyError, NullPrimaryKeyPolicyRouteToDLQ} {
+		tc.NullPrimaryKeyPolicy = policy
+		assert.NoError(t, tc.Validate(), tc.String())
+	}
+
+	tc.NullPrimaryKeyPolicy = "foo"
+	assert.ErrorContains(t, tc.Validate(), `invalid null primary key policy: "foo"`, tc.String())
+	tc.NullPrimaryKeyPolicy = ""
+
+	// Column type overrides
+	tc.ColumnTypeOverrides = map[string]string{"external_id": "string", "amount": "decimal(10, 2)"}
+	assert.NoError(t, tc.Validate(), tc.String())
+
+	tc.ColumnTypeOverrides["created_at"] = "timestamp with time zone"
+	assert.ErrorContains(t, tc.Validate(), `invalid column type override for column: "created_at": unsupported type: "timestamp with time zone"`, tc.String())
+
+	tc.ColumnTypeOverrides = map[string]string{"": "string"}
+	assert.ErrorContains(t, tc.Validate(), "invalid column type override: column name cannot be empty", tc.String())
+	tc.ColumnTypeOverrides = nil
+
+	// Bytes encoding
+	for _, encoding := range []BytesEncoding{BytesEncodingBase64, BytesEncodingHex, BytesEncodingNative} {
+		tc.BytesEncoding = encoding
+		assert.NoError(t, tc.Validate(), tc.String())
+	}
+
+	tc.BytesEncoding = "binary"
+	assert.ErrorContains(t, tc.Validate(), `invalid bytes encoding: "binary"`, tc.String())
+	tc.BytesEncoding = ""
+
+	// Metadata columns
+	tc.MetadataColumns = []MetadataColumn{MetadataColumnOp, "foo"}
+	assert.ErrorContains(t, tc.Validate(), `invalid metadata column: "foo"`, tc.String())
+
+	tc.MetadataColumns = []MetadataColumn{MetadataColumnOp, MetadataColumnPos}
+	assert.ErrorContains(t, tc.Validate(), "metadata columns requires include metadata columns to be enabled", tc.String())
+
+	tc.IncludeMetadataColumns = true
+	assert.NoError(t, tc.Validate(), tc.String())
+	tc.MetadataColumns = nil
+	tc.IncludeMetadataColumns = false
+
+	// Column limits
+	tc.MaxColumns = -1
+	assert.ErrorContains(t, tc.Validate(), "max columns cannot be negative, current value: -1", tc.String())
+
+	tc.MaxColumns = 100
+	assert.NoError(t, tc.Validate(), tc.String())
+	assert.True(t, tc.OverflowEnabled())
+
+	tc.ColumnAllowList = []string{"id", ""}
+	assert.ErrorContains(t, tc.Validate(), "column allow list cannot contain an empty column name", tc.String())
+
+	tc.ColumnAllowList = []string{"id"}
+	assert.NoError(t, tc.Validate(), tc.String())
+	tc.MaxColumns = 0
+	tc.ColumnAllowList = nil
+	assert.False(t, tc.OverflowEnabled())
+
+	// Compacted topics require a table name
+	tc.Compacted = true
+	tc.TableName = ""
+	assert.ErrorContains(t, tc.Validate(), "table name is required for compacted topics", tc.String())
+
+	tc.TableName = "34"
+	assert.NoError(t, tc.Validate(), tc.String())
+	tc.Compacted = false
+
+	// Boolean columns
+	tc.BooleanColumns = []string{"is_active", ""}
+	assert.ErrorContains(t, tc.Validate(), "invalid boolean column: column name cannot be empty", tc.String())
+
+	tc.BooleanColumns = []string{"is_active", "IS_ACTIVE"}
+	assert.ErrorContains(t, tc.Validate(), `invalid boolean column: "IS_ACTIVE" is specified more than once`, tc.String())
+
+	tc.BooleanColumns = []string{"is_active"}
+	tc.ColumnTypeOverrides = map[string]string{"Is_Active": "integer"}
+	assert.ErrorContains(t, tc.Validate(), `invalid boolean column: "is_active" also has a column type override`, tc.String())
+
+	tc.ColumnTypeOverrides = nil
+	assert.NoError(t, tc.Validate(), tc.String())
+	tc.BooleanColumns = nil
+
+	// Column max lengths
+	tc.ColumnMaxLengths = map[string]int{"": 10}
+	assert.ErrorContains(t, tc.Validate(), "invalid column max length: column name cannot be empty", tc.String())
+
+	tc.ColumnMaxLengths = map[string]int{"name": 0}
+	assert.ErrorContains(t, tc.Validate(), `column max length for column: "name" has to be a positive number, current value: 0`, tc.String())
+
+	tc.ColumnMaxLengths = map[string]int{"name": 10}
+	assert.NoError(t, tc.Validate(), tc.String())
+	tc.ColumnMaxLengths = nil
+
+	// Table name template
+	tc.TableNameTemplate = "prod_{table}"
+	assert.NoError(t, tc.Validate(), tc.String())
+
+	tc.TableNameTemplate = "prod_{tbl}"
+	assert.ErrorContains(t, tc.Validate(), `invalid table name template: unsupported placeholder: "{tbl}"`, tc.String())
+
+	tc.TableNameTemplate = "prod_{schema}"
+	assert.ErrorContains(t, tc.Validate(), "invalid table name template: template must contain {table}", tc.String())
+
+	tc.TableNameTemplate = "prod-{table}"
+	assert.ErrorContains(t, tc.Validate(), `invalid table name template: template produces an invalid table name: "prod-table"`, tc.String())
+
+	// The database is "12", so the table name would start with a digit.
+	tc.TableNameTemplate = "{database}_{table}"
+	assert.ErrorContains(t, tc.Validate(), `invalid table name template: template produces an invalid table name: "12_table"`, tc.String())
+}
+
+func TestTopicConfig_TableNameFor(t *testing.T) {
+	{
+		// No template
+		tc := TopicConfig{Database: "shop", Schema: "public"}
+		assert.Equal(t, "orders", tc.TableNameFor("orders"))
+	}
+	{
+		// Static prefix
+		tc := TopicConfig{Database: "shop", Schema: "public", TableNameTemplate: "prod_{table}"}
+		assert.Equal(t, "prod_orders", tc.TableNameFor("orders"))
+	}
+	{
+		// Multiple placeholders
+		tc := TopicConfig{Database: "shop", Schema: "public", TableNameTemplate: "{database}_{schema}__{table}"}
+		assert.Equal(t, "shop_public__orders", tc.TableNameFor("orders"))
+	}
+}
+
+func TestTopicConfig_IncludeDeleteColumns(t *testing.T) {
+	type _tc struct {
+		softDelete              bool
+		marker                  SoftDeleteMarker
+		expectedDeleteColumn    bool
+		expectedDeletedAtColumn bool
+	}
+
+	tcs := []_tc{
+		{softDelete: false},
+		{softDelete: false, marker: SoftDeleteMarkerBoth},
+		{softDelete: true, expectedDeleteColumn: true},
+		{softDelete: true, marker: SoftDeleteMarkerBoolean, expectedDeleteColumn: true},
+		{softDelete: true, marker: SoftDeleteMarkerTimestamp, expectedDeletedAtColumn: true},
+		{softDelete: true, marker: SoftDeleteMarkerBoth, expectedDeleteColumn: true, expectedDeletedAtColumn: true},
+	}
+
+	for idx, tc := range tcs {
+		topicConfig := TopicConfig{SoftDelete: tc.softDelete, SoftDeleteMarker: tc.marker}
+		assert.Equal(t, tc.expectedDeleteColumn, topicConfig.IncludeDeleteColumn(), idx)
+		assert.Equal(t, tc.expectedDeletedAtColumn, topicConfig.IncludeDeletedAtColumn(), idx)
+	}
+}
+
+func TestTopicConfig_ShouldKeepRow(t *testing.T) {
+	{
+		// No row filter
+		tc := TopicConfig{}
+		tc.Load()
+		assert.True(t, tc.ShouldKeepRow(map[string]any{"tenant_id": 1}))
+	}
+	{
+		tc := TopicConfig{RowFilter: "tenant_id IN (42, 43) AND status != 'deleted'"}
+		tc.Load()
+		assert.True(t, tc.ShouldKeepRow(map[string]any{"tenant_id": 42, "status": "active"}))
+		assert.False(t, tc.ShouldKeepRow(map[string]any{"tenant_id": 42, "status": "deleted"}))
+		assert.False(t, tc.ShouldKeepRow(map[string]any{"tenant_id": 1, "status": "active"}))
+	}
 }
 
 func TestTopicConfig_Load_ShouldSkip(t *testing.T) {
@@ -168,4 +551,112 @@ func TestTopicConfig_Load_ShouldSkip(t *testing.T) {
 		tc.Load()
 		assert.True(t, tc.ShouldSkip("d"), tc.String())
 	}
+	{
+		// Nothing is skipped by default
+		tc := TopicConfig{}
+		tc.Load()
+		for _, op := range []string{"c", "r", "u", "d", "x"} {
+			assert.False(t, tc.ShouldSkip(op), op)
+		}
+	}
+	{
+		// Process ops is an allow-list
+		tc := TopicConfig{
+			ProcessOps: []string{"c", " U", "d"},
+		}
+		tc.Load()
+		for _, op := range []string{"c", "u", "d"} {
+			assert.False(t, tc.ShouldSkip(op), op)
+		}
+
+		assert.True(t, tc.ShouldSkip("r"))
+		assert.True(t, tc.ShouldSkip("x"))
+	}
+	{
+		// Skipped operations take precedence
+		tc := TopicConfig{
+			ProcessOps:        []string{"c", "u", "d"},
+			SkippedOperations: "d",
+		}
+		tc.Load()
+		assert.False(t, tc.ShouldSkip("c"))
+		assert.True(t, tc.ShouldSkip("d"))
+	}
+}
+
+func TestTopicConfig_Validate_ProcessOps(t *testing.T) {
+	tc := TopicConfig{
+		Database:     "db",
+		Schema:       "public",
+		Topic:        "orders",
+		CDCFormat:    constants.DBZPostgresFormat,
+		CDCKeyFormat: JSONKeyFmt,
+		ProcessOps:   []string{"c", "U", "d"},
+	}
+	tc.Load()
+	assert.NoError(t, tc.Validate())
+
+	tc.ProcessOps = []string{"c", "insert"}
+	tc.Load()
+	assert.ErrorContains(t, tc.Validate(), `invalid process op: "insert", valid ops are: c, r, u, d`)
+}
+
+func TestTopicConfig_IsUnavailableValue(t *testing.T) {
+	{
+		// Default placeholder
+		tc := TopicConfig{}
+		assert.True(t, tc.IsUnavailableValue(constants.ToastUnavailableValuePlaceholder))
+		assert.False(t, tc.IsUnavailableValue("dusty"))
+		assert.False(t, tc.IsUnavailableValue(nil))
+		assert.False(t, tc.IsUnavailableValue(123))
+	}
+	{
+		// Custom placeholder
+		tc := TopicConfig{UnavailableValuePlaceholder: "__unchanged__"}
+		assert.True(t, tc.IsUnavailableValue("__unchanged__"))
+		assert.False(t, tc.IsUnavailableValue(constants.ToastUnavailableValuePlaceholder))
+	}
+}
+
+func TestTopicConfig_MetadataColumnsToInclude(t *testing.T) {
+	assert.Empty(t, TopicConfig{}.MetadataColumnsToInclude())
+	assert.Empty(t, TopicConfig{MetadataColumns: []MetadataColumn{MetadataColumnOp}}.MetadataColumnsToInclude())
+	assert.Equal(t, []MetadataColumn{MetadataColumnOp, MetadataColumnSourceTsMs, MetadataColumnFile, MetadataColumnPos},
+		TopicConfig{IncludeMetadataColumns: true}.MetadataColumnsToInclude())
+	assert.Equal(t, []MetadataColumn{MetadataColumnPos},
+		TopicConfig{IncludeMetadataColumns: true, MetadataColumns: []MetadataColumn{MetadataColumnPos}}.MetadataColumnsToInclude())
+
+	for _, metadataColumn := range allMetadataColumns {
+		assert.NotEmpty(t, metadataColumn.ColumnName(), metadataColumn)
+	}
+}
+
+func TestTopicConfig_MaxValueLength(t *testing.T) {
+	tc := TopicConfig{ColumnMaxLengths: map[string]int{"Description": 100}}
+	assert.Equal(t, 100, tc.MaxValueLength("description", 10))
+	assert.Equal(t, 10, tc.MaxValueLength("name", 10))
+	assert.Equal(t, 0, TopicConfig{}.MaxValueLength("name", 0))
+}
+
+func TestTopicConfig_IsBooleanColumn(t *testing.T) {
+	tc := TopicConfig{BooleanColumns: []string{"Is_Active"}}
+	assert.True(t, tc.IsBooleanColumn("is_active"))
+	assert.False(t, tc.IsBooleanColumn("id"))
+	assert.False(t, TopicConfig{}.IsBooleanColumn("is_active"))
+}
+
+func TestTopicConfig_ColumnTypeOverride(t *testing.T) {
+	tc := TopicConfig{ColumnTypeOverrides: map[string]string{"External_ID": "string", "amount": "foo"}}
+	tc.Load()
+
+	kindDetails, isOk := tc.ColumnTypeOverride("external_id")
+	assert.True(t, isOk)
+	assert.Equal(t, typing.String, kindDetails)
+
+	// Invalid types are returned by Validate()
+	_, isOk = tc.ColumnTypeOverride("amount")
+	assert.False(t, isOk)
+
+	_, isOk = tc.ColumnTypeOverride("name")
+	assert.False(t, isOk)
 }
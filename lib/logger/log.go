@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"io"
 	"log/slog"
 	"os"
 	"time"
@@ -16,16 +17,21 @@ import (
 
 var handlersToTerminate []func()
 
-func NewLogger(verbose bool, sentryCfg *config.Sentry) (*slog.Logger, func()) {
-	tintLogLevel := slog.LevelInfo
-	if verbose {
-		tintLogLevel = slog.LevelDebug
+// newHandler returns the handler for the configured format and level, verbose logging will always log at debug.
+func newHandler(w io.Writer, loggingCfg *config.Logging, verbose bool, noColor bool) slog.Handler {
+	level := loggingCfg.SlogLevel(verbose)
+	if loggingCfg.GetFormat() == config.LogFormatJSON {
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
 	}
 
-	handler := tint.NewHandler(os.Stderr, &tint.Options{
-		Level:   tintLogLevel,
-		NoColor: !isatty.IsTerminal(os.Stderr.Fd()),
+	return tint.NewHandler(w, &tint.Options{
+		Level:   level,
+		NoColor: noColor,
 	})
+}
+
+func NewLogger(verbose bool, loggingCfg *config.Logging, sentryCfg *config.Sentry) (*slog.Logger, func()) {
+	handler := newHandler(os.Stderr, loggingCfg, verbose, !isatty.IsTerminal(os.Stderr.Fd()))
 	if sentryCfg != nil && sentryCfg.DSN != "" {
 		if err := sentry.Init(sentry.ClientOptions{Dsn: sentryCfg.DSN}); err != nil {
 			slog.New(handler).Warn("Failed to enable Sentry output", slog.Any("err", err))
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+)
+
+func TestNewHandler(t *testing.T) {
+	{
+		// Defaults to text at info
+		var buf bytes.Buffer
+		_logger := slog.New(newHandler(&buf, nil, false, true))
+		_logger.Debug("debug message")
+		_logger.Info("info message", slog.String("table", "orders"))
+
+		assert.NotContains(t, buf.String(), "debug message")
+		assert.Contains(t, buf.String(), "INF info message table=orders")
+		assert.False(t, json.Valid(buf.Bytes()))
+	}
+	{
+		// JSON at warn
+		var buf bytes.Buffer
+		_logger := slog.New(newHandler(&buf, &config.Logging{Format: config.LogFormatJSON, Level: "warn"}, false, true))
+		_logger.Info("info message")
+		_logger.Warn("warn message", slog.String("table", "orders"))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 1)
+
+		var line map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+		assert.Equal(t, "WARN", line["level"])
+		assert.Equal(t, "warn message", line["msg"])
+		assert.Equal(t, "orders", line["table"])
+	}
+	{
+		// Verbose logging will override the configured level
+		var buf bytes.Buffer
+		_logger := slog.New(newHandler(&buf, &config.Logging{Format: config.LogFormatJSON, Level: "error"}, true, true))
+		_logger.Debug("debug message")
+
+		var line map[string]any
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+		assert.Equal(t, "DEBUG", line["level"])
+	}
+}
@@ -2,6 +2,7 @@
 package mocks
 
 import (
+	"context"
 	"database/sql"
 	"sync"
 
@@ -21,6 +22,19 @@ type FakeStore struct {
 		result1 *sql.Tx
 		result2 error
 	}
+	ConnStub        func(context.Context) (*sql.Conn, error)
+	connMutex       sync.RWMutex
+	connArgsForCall []struct {
+		arg1 context.Context
+	}
+	connReturns struct {
+		result1 *sql.Conn
+		result2 error
+	}
+	connReturnsOnCall map[int]struct {
+		result1 *sql.Conn
+		result2 error
+	}
 	ExecStub        func(string, ...any) (sql.Result, error)
 	execMutex       sync.RWMutex
 	execArgsForCall []struct {
@@ -120,6 +134,70 @@ func (fake *FakeStore) BeginReturnsOnCall(i int, result1 *sql.Tx, result2 error)
 	}{result1, result2}
 }
 
+func (fake *FakeStore) Conn(arg1 context.Context) (*sql.Conn, error) {
+	fake.connMutex.Lock()
+	ret, specificReturn := fake.connReturnsOnCall[len(fake.connArgsForCall)]
+	fake.connArgsForCall = append(fake.connArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.ConnStub
+	fakeReturns := fake.connReturns
+	fake.recordInvocation("Conn", []interface{}{arg1})
+	fake.connMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStore) ConnCallCount() int {
+	fake.connMutex.RLock()
+	defer fake.connMutex.RUnlock()
+	return len(fake.connArgsForCall)
+}
+
+func (fake *FakeStore) ConnCalls(stub func(context.Context) (*sql.Conn, error)) {
+	fake.connMutex.Lock()
+	defer fake.connMutex.Unlock()
+	fake.ConnStub = stub
+}
+
+func (fake *FakeStore) ConnArgsForCall(i int) context.Context {
+	fake.connMutex.RLock()
+	defer fake.connMutex.RUnlock()
+	argsForCall := fake.connArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStore) ConnReturns(result1 *sql.Conn, result2 error) {
+	fake.connMutex.Lock()
+	defer fake.connMutex.Unlock()
+	fake.ConnStub = nil
+	fake.connReturns = struct {
+		result1 *sql.Conn
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStore) ConnReturnsOnCall(i int, result1 *sql.Conn, result2 error) {
+	fake.connMutex.Lock()
+	defer fake.connMutex.Unlock()
+	fake.ConnStub = nil
+	if fake.connReturnsOnCall == nil {
+		fake.connReturnsOnCall = make(map[int]struct {
+			result1 *sql.Conn
+			result2 error
+		})
+	}
+	fake.connReturnsOnCall[i] = struct {
+		result1 *sql.Conn
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeStore) Exec(arg1 string, arg2 ...any) (sql.Result, error) {
 	fake.execMutex.Lock()
 	ret, specificReturn := fake.execReturnsOnCall[len(fake.execArgsForCall)]
@@ -316,6 +394,8 @@ func (fake *FakeStore) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.beginMutex.RLock()
 	defer fake.beginMutex.RUnlock()
+	fake.connMutex.RLock()
+	defer fake.connMutex.RUnlock()
 	fake.execMutex.RLock()
 	defer fake.execMutex.RUnlock()
 	fake.isRetryableErrorMutex.RLock()
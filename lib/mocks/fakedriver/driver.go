@@ -0,0 +1,234 @@
+package fakedriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var registered atomic.Int64
+
+// Driver is a fake database/sql driver for tests, it keeps track of the statements that were executed and whether they were committed or rolled back.
+type Driver struct {
+	// FailOn will fail any statement that contains this.
+	FailOn string
+	// QueryRows are returned for any query, every row should have the same number of columns.
+	QueryRows [][]string
+
+	mu         sync.Mutex
+	opened     int
+	closed     int
+	conns      []*conn
+	execs      []string
+	pending    []string
+	committed  []string
+	rolledBack []string
+}
+
+// Register will register the driver under a unique name and return it, so that it can be passed to [sql.Open].
+func (d *Driver) Register() string {
+	name := fmt.Sprintf("fakedriver-%d", registered.Add(1))
+	sql.Register(name, d)
+	return name
+}
+
+// DB will register the driver and open a [sql.DB] with it.
+func (d *Driver) DB() (*sql.DB, error) {
+	return sql.Open(d.Register(), "")
+}
+
+func (d *Driver) Open(_ string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.opened++
+	c := &conn{driver: d}
+	d.conns = append(d.conns, c)
+	return c, nil
+}
+
+// KillConnections will mark every connection that has been opened so far as dead, to simulate the database going away (e.g. after a maintenance window).
+func (d *Driver) KillConnections() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, c := range d.conns {
+		c.dead = true
+	}
+}
+
+// Opened returns how many connections have been opened.
+func (d *Driver) Opened() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.opened
+}
+
+// Closed returns how many connections have been closed, [database/sql] will only close a connection if it's discarded or the pool is closed.
+func (d *Driver) Closed() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+// Execs returns every statement that was executed, with the surrounding whitespace trimmed.
+func (d *Driver) Execs() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.execs...)
+}
+
+// Committed returns the statements that were executed within a transaction that was committed.
+func (d *Driver) Committed() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.committed...)
+}
+
+// RolledBack returns the statements that were executed within a transaction that was rolled back.
+func (d *Driver) RolledBack() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.rolledBack...)
+}
+
+type conn struct {
+	driver *Driver
+	dead   bool
+	inTx   bool
+}
+
+func (c *conn) isDead() bool {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	return c.dead
+}
+
+func (c *conn) Ping(_ context.Context) error {
+	if c.isDead() {
+		return fmt.Errorf("connection is dead")
+	}
+
+	return nil
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	if c.isDead() {
+		return nil, fmt.Errorf("connection is dead")
+	}
+
+	return stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.closed++
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.inTx = true
+	return tx{conn: c}, nil
+}
+
+type tx struct {
+	conn *conn
+}
+
+func (t tx) Commit() error {
+	d := t.conn.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.committed = append(d.committed, d.pending...)
+	d.pending = nil
+	t.conn.inTx = false
+	return nil
+}
+
+func (t tx) Rollback() error {
+	d := t.conn.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rolledBack = append(d.rolledBack, d.pending...)
+	d.pending = nil
+	t.conn.inTx = false
+	return nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s stmt) Close() error {
+	return nil
+}
+
+func (s stmt) NumInput() int {
+	return -1
+}
+
+func (s stmt) Exec(_ []driver.Value) (driver.Result, error) {
+	d := s.conn.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.execs = append(d.execs, strings.TrimSpace(s.query))
+	if s.conn.inTx {
+		d.pending = append(d.pending, s.query)
+	}
+
+	if d.FailOn != "" && strings.Contains(s.query, d.FailOn) {
+		return nil, fmt.Errorf("statement failed")
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+func (s stmt) Query(_ []driver.Value) (driver.Rows, error) {
+	d := s.conn.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return &rows{values: append([][]string(nil), d.QueryRows...)}, nil
+}
+
+type rows struct {
+	values [][]string
+}
+
+func (r *rows) Columns() []string {
+	if len(r.values) == 0 {
+		return []string{"value"}
+	}
+
+	var columns []string
+	for i := range r.values[0] {
+		columns = append(columns, fmt.Sprintf("value%d", i))
+	}
+
+	return columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	for i, value := range r.values[0] {
+		dest[i] = value
+	}
+
+	r.values = r.values[1:]
+	return nil
+}
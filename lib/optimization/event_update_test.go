@@ -61,6 +61,13 @@ func TestTableData_UpdateInMemoryColumnsFromDestination(t *testing.T) {
 	assert.True(t, isOk)
 	assert.Equal(t, typing.String, prevInvalidCol.KindDetails)
 
+	// Testing to make sure we're copying over whether the destination column can only contain objects.
+	tableData.inMemoryColumns.AddColumn(columns.NewColumn("payload", typing.Struct))
+	tableData.MergeColumnsFromDestination(columns.NewColumn("payload", typing.Object))
+	payloadCol, isOk := tableData.inMemoryColumns.GetColumn("payload")
+	assert.True(t, isOk)
+	assert.Equal(t, typing.Object, payloadCol.KindDetails)
+
 	// Testing backfill
 	for _, inMemoryCol := range tableData.inMemoryColumns.GetColumns() {
 		assert.False(t, inMemoryCol.Backfilled(), inMemoryCol.RawName())
@@ -146,4 +153,41 @@ func TestTableData_UpdateInMemoryColumnsFromDestination(t *testing.T) {
 	assert.True(t, isOk)
 	assert.Equal(t, typing.String.Kind, foundStrCol.KindDetails.Kind)
 	assert.Equal(t, 123, *foundStrCol.KindDetails.OptionalStringPrecision)
+
+	// Testing array element types
+	tableData.AddInMemoryCol(columns.NewColumn("int_arr", typing.NewArrayKindDetails(typing.Integer)))
+	tableData.AddInMemoryCol(columns.NewColumn("str_arr", typing.NewArrayKindDetails(typing.String)))
+	// Destination does not know about the element type, so we should fall back to a generic array.
+	tableData.MergeColumnsFromDestination(columns.NewColumn("int_arr", typing.Array))
+	intArrCol, isOk := tableData.inMemoryColumns.GetColumn("int_arr")
+	assert.True(t, isOk)
+	assert.Equal(t, typing.Array, intArrCol.KindDetails)
+	// Destination's element type should win.
+	tableData.MergeColumnsFromDestination(columns.NewColumn("str_arr", typing.NewArrayKindDetails(typing.Struct)))
+	strArrCol, isOk := tableData.inMemoryColumns.GetColumn("str_arr")
+	assert.True(t, isOk)
+	assert.Equal(t, typing.NewArrayKindDetails(typing.Struct), strArrCol.KindDetails)
+
+	// Testing destination names that differ by case
+	tableData.AddInMemoryCol(columns.NewColumn("email", typing.String))
+	emailDestCol := columns.NewColumn("email", typing.String)
+	emailDestCol.SetDestinationName("Email")
+	tableData.MergeColumnsFromDestination(emailDestCol)
+	emailCol, isOk := tableData.inMemoryColumns.GetColumn("email")
+	assert.True(t, isOk)
+	assert.Equal(t, "Email", emailCol.DestinationName())
+
+	// Testing integer widths
+	tableData.AddInMemoryCol(columns.NewColumn("int32_col", typing.NewIntegerKindDetails(typing.RegularIntegerKind)))
+	tableData.AddInMemoryCol(columns.NewColumn("int64_col", typing.NewIntegerKindDetails(typing.RegularIntegerKind)))
+	// Destination does not declare a width, so we should not hold the values to the source's width.
+	tableData.MergeColumnsFromDestination(columns.NewColumn("int32_col", typing.Integer))
+	int32Col, isOk := tableData.inMemoryColumns.GetColumn("int32_col")
+	assert.True(t, isOk)
+	assert.Equal(t, typing.Integer, int32Col.KindDetails)
+	// Destination's width should win.
+	tableData.MergeColumnsFromDestination(columns.NewColumn("int64_col", typing.NewIntegerKindDetails(typing.BigIntegerKind)))
+	int64Col, isOk := tableData.inMemoryColumns.GetColumn("int64_col")
+	assert.True(t, isOk)
+	assert.Equal(t, typing.NewIntegerKindDetails(typing.BigIntegerKind), int64Col.KindDetails)
 }
@@ -0,0 +1,135 @@
+package optimization
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+// overflowColumns returns the in-memory columns that should be folded into the overflow column.
+// Primary keys, Artie columns and columns that already exist in the destination will stay typed. The remaining columns are kept
+// in the order they were first seen, as long as they are in the allow-list (if set) and we're under [MaxColumns].
+func (t *TableData) overflowColumns(destCols *columns.Columns) []string {
+	// Artie columns do not count towards the limit.
+	typedCount := 0
+	destColNames := make(map[string]bool)
+	for _, col := range destCols.GetColumns() {
+		name := strings.ToLower(col.RawName())
+		destColNames[name] = true
+		if !strings.HasPrefix(name, constants.ArtiePrefix) {
+			typedCount++
+		}
+	}
+
+	var allowList []string
+	for _, col := range t.TopicConfig.ColumnAllowList {
+		allowList = append(allowList, strings.ToLower(col))
+	}
+
+	var overflowCols []string
+	for _, col := range t.inMemoryColumns.GetColumns() {
+		if strings.HasPrefix(col.RawName(), constants.ArtiePrefix) || destColNames[col.RawName()] {
+			continue
+		}
+
+		if slices.Contains(t.primaryKeys, col.RawName()) {
+			typedCount++
+			continue
+		}
+
+		if len(allowList) > 0 && !slices.Contains(allowList, col.RawName()) {
+			overflowCols = append(overflowCols, col.RawName())
+			continue
+		}
+
+		if t.TopicConfig.MaxColumns > 0 && typedCount >= t.TopicConfig.MaxColumns {
+			overflowCols = append(overflowCols, col.RawName())
+			continue
+		}
+
+		typedCount++
+	}
+
+	return overflowCols
+}
+
+// FoldOverflowColumns will move the columns that are over the topic's column limit into the `__artie_overflow` JSON column.
+// This should be called with the destination's columns before we figure out which columns need to be added to the destination.
+// TOAST values are left out of the overflow column, the folded columns are merged into the existing overflow column (where the destination supports it) so their previous values are preserved.
+func (t *TableData) FoldOverflowColumns(destCols *columns.Columns) {
+	if t == nil || !t.TopicConfig.OverflowEnabled() || t.inMemoryColumns == nil {
+		return
+	}
+
+	overflowCols := t.overflowColumns(destCols)
+	if len(overflowCols) == 0 {
+		return
+	}
+
+	slog.Info("Folding columns into the overflow column", slog.String("table", t.name), slog.Any("columns", overflowCols))
+	mergeKeys := slices.Clone(overflowCols)
+	foldRow := func(row map[string]any) {
+		overflow := make(map[string]any)
+		for _, col := range overflowCols {
+			val, isOk := row[col]
+			if !isOk {
+				continue
+			}
+
+			delete(row, col)
+			if val != constants.ToastUnavailableValuePlaceholder {
+				overflow[col] = val
+			}
+		}
+
+		// Delete rows only have the primary keys.
+		if isDelete, _ := row[constants.DeleteColumnMarker].(bool); isDelete && len(overflow) == 0 {
+			return
+		}
+
+		if existing, isOk := row[constants.OverflowColumnMarker].(map[string]any); isOk {
+			for key, val := range existing {
+				if _, isOk = overflow[key]; !isOk {
+					overflow[key] = val
+				}
+
+				if !slices.Contains(mergeKeys, key) {
+					mergeKeys = append(mergeKeys, key)
+				}
+			}
+		}
+
+		row[constants.OverflowColumnMarker] = overflow
+	}
+
+	for _, row := range t.rowsData {
+		foldRow(row)
+	}
+
+	for _, row := range t.rows {
+		foldRow(row)
+	}
+
+	for _, col := range overflowCols {
+		t.inMemoryColumns.DeleteColumn(col)
+	}
+
+	overflowCol, isOk := t.inMemoryColumns.GetColumn(constants.OverflowColumnMarker)
+	if !isOk {
+		overflowCol = columns.NewColumn(constants.OverflowColumnMarker, typing.Struct)
+		t.inMemoryColumns.AddColumn(overflowCol)
+	}
+
+	for _, key := range overflowCol.MergeKeys() {
+		if !slices.Contains(mergeKeys, key) {
+			mergeKeys = append(mergeKeys, key)
+		}
+	}
+
+	overflowCol.SetMergeKeys(mergeKeys)
+	t.inMemoryColumns.UpdateColumn(overflowCol)
+}
@@ -0,0 +1,99 @@
+package optimization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+func newOverflowTableData(tc kafkalib.TopicConfig, mode config.Mode) *TableData {
+	var cols columns.Columns
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.AddColumn(columns.NewColumn("name", typing.String))
+	cols.AddColumn(columns.NewColumn("age", typing.Integer))
+	cols.AddColumn(columns.NewColumn("city", typing.String))
+	cols.AddColumn(columns.NewColumn("zip", typing.String))
+	cols.AddColumn(columns.NewColumn(constants.DeleteColumnMarker, typing.Boolean))
+
+	td := NewTableData(&cols, mode, []string{"id"}, tc, "users")
+	td.InsertRow("1", map[string]any{"id": 1, "name": "robin", "age": 30, "city": "sf", "zip": "94107", constants.DeleteColumnMarker: false}, false)
+	td.InsertRow("2", map[string]any{"id": 2, "name": "jane", "age": 40, "city": constants.ToastUnavailableValuePlaceholder, constants.DeleteColumnMarker: false}, false)
+	td.InsertRow("3", map[string]any{"id": 3, constants.DeleteColumnMarker: true}, true)
+	return td
+}
+
+func columnNames(cols *columns.Columns) []string {
+	var names []string
+	for _, col := range cols.GetColumns() {
+		names = append(names, col.RawName())
+	}
+
+	return names
+}
+
+func TestTableData_FoldOverflowColumns(t *testing.T) {
+	{
+		// Not enabled
+		td := newOverflowTableData(kafkalib.TopicConfig{}, config.Replication)
+		td.FoldOverflowColumns(nil)
+		assert.Equal(t, []string{"id", "name", "age", "city", "zip", constants.DeleteColumnMarker}, columnNames(td.ReadOnlyInMemoryCols()))
+		assert.Equal(t, 30, td.rowsData["1"]["age"])
+	}
+	{
+		// Under the limit
+		td := newOverflowTableData(kafkalib.TopicConfig{MaxColumns: 5}, config.Replication)
+		td.FoldOverflowColumns(nil)
+		assert.Equal(t, []string{"id", "name", "age", "city", "zip", constants.DeleteColumnMarker}, columnNames(td.ReadOnlyInMemoryCols()))
+	}
+	{
+		// Over the limit, new table
+		td := newOverflowTableData(kafkalib.TopicConfig{MaxColumns: 3}, config.Replication)
+		td.FoldOverflowColumns(nil)
+		assert.Equal(t, []string{"id", "name", "age", constants.DeleteColumnMarker, constants.OverflowColumnMarker}, columnNames(td.ReadOnlyInMemoryCols()))
+
+		col, isOk := td.ReadOnlyInMemoryCols().GetColumn(constants.OverflowColumnMarker)
+		assert.True(t, isOk)
+		assert.Equal(t, typing.Struct, col.KindDetails)
+		assert.Equal(t, []string{"city", "zip"}, col.MergeKeys())
+
+		rows := td.rowsData
+		// Core columns stay typed and the extra columns are in the overflow column.
+		assert.Equal(t, map[string]any{"id": 1, "name": "robin", "age": 30, constants.DeleteColumnMarker: false, constants.OverflowColumnMarker: map[string]any{"city": "sf", "zip": "94107"}}, rows["1"])
+		// TOAST values are left out, the existing value will be kept when the overflow column is merged.
+		assert.Equal(t, map[string]any{"id": 2, "name": "jane", "age": 40, constants.DeleteColumnMarker: false, constants.OverflowColumnMarker: map[string]any{}}, rows["2"])
+		// Deletes only have the primary keys.
+		assert.Equal(t, map[string]any{"id": 3, constants.DeleteColumnMarker: true}, rows["3"])
+	}
+	{
+		// Columns that exist in the destination will stay typed and count towards the limit.
+		var destCols columns.Columns
+		destCols.AddColumn(columns.NewColumn("id", typing.Integer))
+		destCols.AddColumn(columns.NewColumn("zip", typing.String))
+		destCols.AddColumn(columns.NewColumn(constants.OverflowColumnMarker, typing.Struct))
+
+		td := newOverflowTableData(kafkalib.TopicConfig{MaxColumns: 3}, config.Replication)
+		td.FoldOverflowColumns(&destCols)
+		assert.Equal(t, []string{"id", "name", "zip", constants.DeleteColumnMarker, constants.OverflowColumnMarker}, columnNames(td.ReadOnlyInMemoryCols()))
+		assert.Equal(t, map[string]any{"id": 1, "name": "robin", "zip": "94107", constants.DeleteColumnMarker: false, constants.OverflowColumnMarker: map[string]any{"age": 30, "city": "sf"}}, td.rowsData["1"])
+	}
+	{
+		// Allow list
+		td := newOverflowTableData(kafkalib.TopicConfig{ColumnAllowList: []string{"NAME", "city"}}, config.Replication)
+		td.FoldOverflowColumns(nil)
+		assert.Equal(t, []string{"id", "name", "city", constants.DeleteColumnMarker, constants.OverflowColumnMarker}, columnNames(td.ReadOnlyInMemoryCols()))
+		assert.Equal(t, map[string]any{"age": 30, "zip": "94107"}, td.rowsData["1"][constants.OverflowColumnMarker])
+	}
+	{
+		// History mode
+		td := newOverflowTableData(kafkalib.TopicConfig{MaxColumns: 2}, config.History)
+		td.FoldOverflowColumns(nil)
+		assert.Equal(t, []string{"id", "name", constants.DeleteColumnMarker, constants.OverflowColumnMarker}, columnNames(td.ReadOnlyInMemoryCols()))
+		assert.Equal(t, map[string]any{"age": 30, "city": "sf", "zip": "94107"}, td.Rows()[0][constants.OverflowColumnMarker])
+	}
+}
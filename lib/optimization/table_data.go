@@ -1,7 +1,11 @@
 package optimization
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
@@ -23,6 +27,8 @@ type TableData struct {
 
 	// rowsData is used for replication
 	rowsData map[string]map[string]any // pk -> { col -> val }
+	// rowsPositions is where each row in `rowsData` was read from, this is used to make sure we are keeping the latest row for each primary key.
+	rowsPositions map[string]RowPosition
 	// rows is used for history mode, since it's append only.
 	rows []map[string]any
 
@@ -33,10 +39,20 @@ type TableData struct {
 	// For Kafka, we only need the last message to commit the offset
 	// However, pub/sub requires every single message to be acked
 	PartitionsToLastMessage map[string][]artie.Message
+	// firstOffsets is the first offset that was buffered for each partition, see [TableData.BatchID].
+	firstOffsets map[string]int64
+	// batchID is returned by [TableData.BatchID] if the queue does not have offsets.
+	batchID string
+	// batchPart is set for the halves of a bisected batch, so that each half has its own [TableData.BatchID].
+	batchPart string
 
 	// This is used for the automatic schema detection
 	LatestCDCTs time.Time
 	approxSize  int
+	// sizeAccounting is how the row sizes are calculated for [TableData.ApproxSize].
+	sizeAccounting config.FlushSizeAccounting
+	// lastInsertTime is when the last row was inserted, this is used to figure out if the table is idle.
+	lastInsertTime time.Time
 	// containOtherOperations - this means the `TableData` object contains other events that arises from CREATE, UPDATE, REPLICATION
 	// if this value is false, that means it is only deletes. Which means we should not drop columns
 	containOtherOperations bool
@@ -46,6 +62,10 @@ type TableData struct {
 
 	temporaryTableSuffix string
 
+	// maxStringLengths is the longest string (in bytes) that we have seen for each column, this is keyed by the lowercased column name.
+	// This is used to widen VARCHAR columns before loading.
+	maxStringLengths map[string]int
+
 	// Name of the table in the destination
 	// Prefer calling .Name() everywhere
 	name string
@@ -55,6 +75,11 @@ func (t *TableData) Mode() config.Mode {
 	return t.mode
 }
 
+// AppendOnly returns true if the rows should be appended to the destination as is, instead of being deduped and merged on the primary keys.
+func (t *TableData) AppendOnly() bool {
+	return t.mode == config.History || t.TopicConfig.AppendOnly
+}
+
 // ShouldSkipUpdate will check if there are any rows or any columns
 func (t *TableData) ShouldSkipUpdate() bool {
 	return t.NumberOfRows() == 0 || t.ReadOnlyInMemoryCols() == nil
@@ -68,21 +93,33 @@ func (t *TableData) ContainOtherOperations() bool {
 	return t.containOtherOperations
 }
 
-func (t *TableData) PrimaryKeys(uppercaseEscNames bool, args *sql.NameArgs) []columns.Wrapper {
+func (t *TableData) PrimaryKeys(casing config.IdentifierCasing, args *sql.NameArgs) []columns.Wrapper {
 	var pks []columns.Wrapper
 	for _, pk := range t.primaryKeys {
-		pks = append(pks, columns.NewWrapper(columns.NewColumn(pk, typing.Invalid), uppercaseEscNames, args))
+		col, isOk := t.inMemoryColumns.GetColumn(pk)
+		if !isOk {
+			col = columns.NewColumn(pk, typing.Invalid)
+		}
+
+		pks = append(pks, columns.NewWrapper(col, casing, args))
 	}
 
 	return pks
 }
 
+// RawName returns the name of the table in the destination, this will have the topic's table name template applied.
 func (t *TableData) RawName() string {
-	return t.name
+	return t.TopicConfig.TableNameFor(t.name)
+}
+
+// TruncatedName returns the unescaped name of the table as it is stored in the destination, this is the raw name truncated to the destination's max identifier length.
+// This should be used when looking up the table in the destination's information schema.
+func (t *TableData) TruncatedName(kind constants.DestinationKind) string {
+	return sql.TruncateIdentifier(kind, t.RawName())
 }
 
-func (t *TableData) Name(uppercaseEscNames bool, args *sql.NameArgs) string {
-	return sql.EscapeName(t.name, uppercaseEscNames, args)
+func (t *TableData) Name(casing config.IdentifierCasing, args *sql.NameArgs) string {
+	return sql.EscapeName(t.RawName(), casing, args)
 }
 
 func (t *TableData) SetInMemoryColumns(columns *columns.Columns) {
@@ -116,24 +153,129 @@ func NewTableData(inMemoryColumns *columns.Columns, mode config.Mode, primaryKey
 		// temporaryTableSuffix is being set in `ResetTempTableSuffix`
 		temporaryTableSuffix:    "",
 		PartitionsToLastMessage: map[string][]artie.Message{},
+		firstOffsets:            map[string]int64{},
+		batchID:                 strings.ToLower(stringutil.Random(16)),
 		name:                    name,
 	}
 }
 
+// RecordOffset will keep track of the first offset that was buffered for `partition`, see [TableData.BatchID].
+func (t *TableData) RecordOffset(partition string, offset int64) {
+	if t.firstOffsets == nil {
+		t.firstOffsets = make(map[string]int64)
+	}
+
+	if _, isOk := t.firstOffsets[partition]; !isOk {
+		t.firstOffsets[partition] = offset
+	}
+}
+
+// BatchID identifies the buffered rows, this stays the same when a failed flush is retried so destinations can overwrite what the failed attempt wrote.
+// If the queue has offsets, this is derived from the first offset of each partition so it's also the same when the rows are consumed again after a restart.
+func (t *TableData) BatchID() string {
+	batchID := t.batchID
+	if len(t.firstOffsets) > 0 {
+		var parts []string
+		for partition, offset := range t.firstOffsets {
+			parts = append(parts, fmt.Sprintf("%s=%d", partition, offset))
+		}
+
+		slices.Sort(parts)
+
+		hash := sha256.Sum256([]byte(strings.Join(parts, ",")))
+		batchID = hex.EncodeToString(hash[:])[:16]
+	}
+
+	if t.batchPart != "" {
+		return batchID + "_" + t.batchPart
+	}
+
+	return batchID
+}
+
 // InsertRow creates a single entrypoint for how rows get added to TableData
 // This is important to avoid concurrent r/w, but also the ability for us to add or decrement row size by keeping a running total
 // With this, we are able to reduce the latency by 500x+ on a 5k row table. See event_bench_test.go vs. size_bench_test.go
 func (t *TableData) InsertRow(pk string, rowData map[string]any, delete bool) {
-	if t.mode == config.History {
+	t.InsertRowAt(pk, rowData, delete, time.Time{})
+}
+
+// InsertRowAt is the same as [TableData.InsertRow], but it will also take the row's CDC timestamp into account, see [TableData.InsertRowAtPosition].
+func (t *TableData) InsertRowAt(pk string, rowData map[string]any, delete bool, cdcTs time.Time) bool {
+	return t.InsertRowAtPosition(pk, rowData, delete, RowPosition{CDCTs: cdcTs})
+}
+
+// RowPosition is where a row was read from.
+type RowPosition struct {
+	// CDCTs is the source's CDC timestamp, this is zero if the source did not provide one.
+	CDCTs time.Time
+	// Partition and Offset are only set for messages that have offsets (e.g. Kafka).
+	Partition string
+	Offset    *int64
+}
+
+// Before returns true if `p` is known to have been written before `other`.
+// Offsets within the same partition are in the order that the source committed them, so they take precedence over the CDC timestamps.
+// The CDC timestamps can be out of order (e.g. snapshot rows are stamped when they are read), so they are only compared across partitions or if an offset is missing.
+// Rows that cannot be ordered by either will return false.
+func (p RowPosition) Before(other RowPosition) bool {
+	if p.Offset != nil && other.Offset != nil && p.Partition == other.Partition {
+		return *p.Offset < *other.Offset
+	}
+
+	if !p.CDCTs.IsZero() && !other.CDCTs.IsZero() {
+		return p.CDCTs.Before(other.CDCTs)
+	}
+
+	return false
+}
+
+// InsertRowAtPosition is the same as [TableData.InsertRow], but it will also take the row's position into account.
+// The same primary key can show up multiple times within a single flush (rapid updates), we'll collapse them so that the merge source only has one row per primary key.
+// If `position` is before the row that we have already buffered for `pk`, the row will be dropped and this will return false.
+// Rows with the same position will replace the buffered row, since they were read after it. If the position is unknown, the row is always kept.
+func (t *TableData) InsertRowAtPosition(pk string, rowData map[string]any, delete bool, position RowPosition) bool {
+	if !t.AppendOnly() {
+		if bufferedPosition, isOk := t.rowsPositions[pk]; isOk && position.Before(bufferedPosition) {
+			return false
+		}
+	}
+
+	t.insertRow(pk, rowData, delete)
+	if !t.AppendOnly() {
+		if t.rowsPositions == nil {
+			t.rowsPositions = make(map[string]RowPosition)
+		}
+
+		// Nothing is before an unknown position, so the next row for this primary key will always be kept.
+		t.rowsPositions[pk] = position
+	}
+
+	return true
+}
+
+func (t *TableData) insertRow(pk string, rowData map[string]any, delete bool) {
+	t.lastInsertTime = time.Now()
+	t.trackStringLengths(rowData)
+	if t.AppendOnly() {
 		t.rows = append(t.rows, rowData)
-		t.approxSize += size.GetApproxSize(rowData)
+		t.approxSize += t.rowSize(rowData)
 		return
 	}
 
 	var prevRowSize int
 	prevRow, isOk := t.rowsData[pk]
 	if isOk {
-		prevRowSize = size.GetApproxSize(prevRow)
+		prevRowSize = t.rowSize(prevRow)
+		if delete && rowData != nil && t.TopicConfig.TombstoneDeletes() {
+			// If the before image only contained the primary keys, we'll carry over the rest of the columns from the previous row.
+			for key, prevVal := range prevRow {
+				if _, isOk = rowData[key]; !isOk {
+					rowData[key] = prevVal
+				}
+			}
+		}
+
 		for key, val := range rowData {
 			if val == constants.ToastUnavailableValuePlaceholder {
 				// Copy it from prevRow.
@@ -146,9 +288,19 @@ func (t *TableData) InsertRow(pk string, rowData map[string]any, delete bool) {
 				rowData[key] = prevVal
 			}
 		}
+
+		if !delete && t.TopicConfig.PartialUpdate {
+			// Columns that were not in this event have not changed, so we'll carry them over from the previous row.
+			// The columns in the row are what we'll update when merging, see [TableData.PartialUpdateBatches].
+			for key, prevVal := range prevRow {
+				if _, isOk = rowData[key]; !isOk {
+					rowData[key] = prevVal
+				}
+			}
+		}
 	}
 
-	newRowSize := size.GetApproxSize(rowData)
+	newRowSize := t.rowSize(rowData)
 	// If prevRow doesn't exist, it'll be 0, which is a no-op.
 	t.approxSize += newRowSize - prevRowSize
 	t.rowsData[pk] = rowData
@@ -164,12 +316,52 @@ func (t *TableData) InsertRow(pk string, rowData map[string]any, delete bool) {
 	}
 }
 
+// SetSizeAccounting changes how the row sizes are calculated, this should be called before any rows are inserted.
+func (t *TableData) SetSizeAccounting(sizeAccounting config.FlushSizeAccounting) {
+	t.sizeAccounting = sizeAccounting
+}
+
+func (t *TableData) rowSize(row map[string]any) int {
+	if t.sizeAccounting == config.FlushSizeAccountingSerialized {
+		return size.GetSerializedSize(row)
+	}
+
+	return size.GetApproxSize(row)
+}
+
+func (t *TableData) trackStringLengths(rowData map[string]any) {
+	for key, val := range rowData {
+		castedValue, isOk := val.(string)
+		if !isOk || castedValue == constants.ToastUnavailableValuePlaceholder {
+			continue
+		}
+
+		key = strings.ToLower(key)
+		if len(castedValue) > t.maxStringLengths[key] {
+			if t.maxStringLengths == nil {
+				t.maxStringLengths = make(map[string]int)
+			}
+
+			t.maxStringLengths[key] = len(castedValue)
+		}
+	}
+}
+
+// MaxStringLength returns the length (in bytes) of the longest string that we have seen for `colName`, column names are case-insensitive.
+func (t *TableData) MaxStringLength(colName string) int {
+	return t.maxStringLengths[strings.ToLower(colName)]
+}
+
+func (t *TableData) LastInsertTime() time.Time {
+	return t.lastInsertTime
+}
+
 // Rows returns a read only slice of tableData's rows or rowsData depending on mode
 func (t *TableData) Rows() []map[string]any {
 	var rows []map[string]any
 
-	if t.Mode() == config.History {
-		// History mode, the data is stored under `rows`
+	if t.AppendOnly() {
+		// History mode and append only tables store the data under `rows`
 		rows = append(rows, t.rows...)
 	} else {
 		for _, v := range t.rowsData {
@@ -180,43 +372,212 @@ func (t *TableData) Rows() []map[string]any {
 	return rows
 }
 
+// PartialUpdateBatch is a subset of the rows in [TableData] that carried the same columns.
+type PartialUpdateBatch struct {
+	// Columns are the columns that the rows carried, these are the only columns that should be updated.
+	Columns   []string
+	TableData *TableData
+}
+
+// PartialUpdateBatches will group the rows by the columns that they carried, so each batch can be merged without overwriting the columns that were not in the event.
+func (t *TableData) PartialUpdateBatches() []PartialUpdateBatch {
+	batchIndexes := make(map[string]int)
+	var batches []PartialUpdateBatch
+	for pk, row := range t.rowsData {
+		var cols []string
+		for col := range row {
+			cols = append(cols, col)
+		}
+
+		slices.Sort(cols)
+		key := strings.Join(cols, ",")
+		idx, isOk := batchIndexes[key]
+		if !isOk {
+			tableData := *t
+			tableData.rowsData = make(map[string]map[string]any)
+			tableData.approxSize = 0
+			idx = len(batches)
+			batchIndexes[key] = idx
+			batches = append(batches, PartialUpdateBatch{Columns: cols, TableData: &tableData})
+		}
+
+		batches[idx].TableData.rowsData[pk] = row
+		batches[idx].TableData.approxSize += t.rowSize(row)
+	}
+
+	// Sorting the batches so that the merges are issued in a deterministic order.
+	slices.SortFunc(batches, func(a, b PartialUpdateBatch) int {
+		return strings.Compare(strings.Join(a.Columns, ","), strings.Join(b.Columns, ","))
+	})
+
+	// Each batch is merged through its own temporary table, the index goes before the expiry so the sweeper can still parse it.
+	for idx := range batches {
+		batches[idx].TableData.temporaryTableSuffix = fmt.Sprintf("%s_%d", t.temporaryTableSuffix, idx)
+	}
+
+	return batches
+}
+
+// Bisect will split the rows into two halves, this is used to isolate the rows that the destination keeps rejecting.
+// Everything other than the rows (columns, topic config and offsets) is shared with `t`.
+func (t *TableData) Bisect() (*TableData, *TableData) {
+	left, right := *t, *t
+	left.approxSize, right.approxSize = 0, 0
+	left.batchPart, right.batchPart = t.batchPart+"0", t.batchPart+"1"
+	if t.AppendOnly() {
+		mid := len(t.rows) / 2
+		left.rows = slices.Clone(t.rows[:mid])
+		right.rows = slices.Clone(t.rows[mid:])
+		for _, row := range left.rows {
+			left.approxSize += t.rowSize(row)
+		}
+
+		for _, row := range right.rows {
+			right.approxSize += t.rowSize(row)
+		}
+
+		return &left, &right
+	}
+
+	// Sorting the primary keys so that the halves are the same every time we bisect the same batch.
+	pks := make([]string, 0, len(t.rowsData))
+	for pk := range t.rowsData {
+		pks = append(pks, pk)
+	}
+
+	slices.Sort(pks)
+	left.rowsData = make(map[string]map[string]any)
+	right.rowsData = make(map[string]map[string]any)
+	for idx, pk := range pks {
+		half := &left
+		if idx >= len(pks)/2 {
+			half = &right
+		}
+
+		half.rowsData[pk] = t.rowsData[pk]
+		half.approxSize += t.rowSize(t.rowsData[pk])
+	}
+
+	return &left, &right
+}
+
+// Without returns a copy of `t` without the rows from `others`, this is used to write the rest of the batch once the failing rows have been isolated by [TableData.Bisect].
+// Append only tables cannot tell their rows apart, so this is only supported for tables that are merged.
+func (t *TableData) Without(others ...*TableData) (*TableData, error) {
+	if t.AppendOnly() {
+		return nil, fmt.Errorf("removing rows is not supported for append only tables")
+	}
+
+	out := *t
+	out.approxSize = 0
+	out.rowsData = maps.Clone(t.rowsData)
+	for _, other := range others {
+		for pk := range other.rowsData {
+			delete(out.rowsData, pk)
+		}
+	}
+
+	for _, row := range out.rowsData {
+		out.approxSize += t.rowSize(row)
+	}
+
+	return &out, nil
+}
+
+// Clone returns a deep copy of the columns and rows, this is used when the same rows are written to multiple destinations since writing will update the columns and rows in place.
+func (t *TableData) Clone() *TableData {
+	clone := *t
+	clone.inMemoryColumns = t.inMemoryColumns.Clone()
+	clone.primaryKeys = slices.Clone(t.primaryKeys)
+	clone.rowsPositions = maps.Clone(t.rowsPositions)
+	clone.maxStringLengths = maps.Clone(t.maxStringLengths)
+	clone.PartitionsToLastMessage = maps.Clone(t.PartitionsToLastMessage)
+	clone.firstOffsets = maps.Clone(t.firstOffsets)
+	if t.rows != nil {
+		clone.rows = make([]map[string]any, len(t.rows))
+		for idx, row := range t.rows {
+			clone.rows[idx] = maps.Clone(row)
+		}
+	}
+
+	if t.rowsData != nil {
+		clone.rowsData = make(map[string]map[string]any, len(t.rowsData))
+		for pk, row := range t.rowsData {
+			clone.rowsData[pk] = maps.Clone(row)
+		}
+	}
+
+	return &clone
+}
+
+// DropRows removes the first `n` rows of an append only table, their rows are kept in the order that they were inserted.
+// This is a no-op for tables that are not append only.
+func (t *TableData) DropRows(n uint) {
+	if !t.AppendOnly() {
+		return
+	}
+
+	n = min(n, uint(len(t.rows)))
+	for _, row := range t.rows[:n] {
+		t.approxSize -= t.rowSize(row)
+	}
+
+	t.rows = t.rows[n:]
+}
+
 type FqNameOpts struct {
 	BigQueryProjectID   string
 	MsSQLSchemaOverride string
+	DatabricksCatalog   string
+	// StagingSchema is optional, if set the table will be placed in this schema instead of the topic's schema.
+	// This is used so that temporary tables can be created in a dedicated schema, it is not supported for S3 or BigQuery.
+	StagingSchema string
+}
+
+// TemporaryTableName returns the name of the temporary table that will be merged (or appended) into the target table.
+// If `opts.StagingSchema` is set, the temporary table will be created in the staging schema instead of alongside the target table.
+// The temporary table is never escaped, so the identifier casing does not apply.
+func (t *TableData) TemporaryTableName(kind constants.DestinationKind, opts FqNameOpts) string {
+	// The table name is truncated (if needed) so that the suffix is never cut off by the destination, the sweeper relies on it to find expired tables.
+	return t.fqName(kind, sql.TemporaryIdentifier(kind, t.RawName(), t.TempTableSuffix()), opts)
+}
+
+func (t *TableData) ToFqName(kind constants.DestinationKind, escape bool, casing config.IdentifierCasing, opts FqNameOpts) string {
+	if kind == constants.S3 || kind == constants.S3Parquet {
+		// We don't need to escape S3, since it's not a SQL db.
+		escape = false
+	}
+
+	return t.fqName(kind, t.Name(casing, &sql.NameArgs{
+		Escape:   escape,
+		DestKind: kind,
+	}), opts)
 }
 
-func (t *TableData) ToFqName(kind constants.DestinationKind, escape bool, uppercaseEscNames bool, opts FqNameOpts) string {
+// fqName returns the fully qualified name for `tableName`, which is expected to be escaped already.
+func (t *TableData) fqName(kind constants.DestinationKind, tableName string, opts FqNameOpts) string {
 	switch kind {
-	case constants.S3:
-		// S3 should be db.schema.tableName, but we don't need to escape, since it's not a SQL db.
-		return fmt.Sprintf("%s.%s.%s", t.TopicConfig.Database, t.TopicConfig.Schema, t.Name(uppercaseEscNames, &sql.NameArgs{
-			Escape:   false,
-			DestKind: kind,
-		}))
+	case constants.S3, constants.S3Parquet:
+		// S3 should be db.schema.tableName.
+		return fmt.Sprintf("%s.%s.%s", t.TopicConfig.Database, t.TopicConfig.Schema, tableName)
 	case constants.Redshift:
 		// Redshift is Postgres compatible, so when establishing a connection, we'll specify a database.
 		// Thus, we only need to specify schema and table name here.
-		return fmt.Sprintf("%s.%s", t.TopicConfig.Schema, t.Name(uppercaseEscNames, &sql.NameArgs{
-			Escape:   escape,
-			DestKind: kind,
-		}))
+		return fmt.Sprintf("%s.%s", stringutil.Override(t.TopicConfig.Schema, opts.StagingSchema), tableName)
+	case constants.DuckDB:
+		// Tables are written into the attached database file, so we only need to specify schema and table name.
+		return fmt.Sprintf("%s.%s", stringutil.Override(t.TopicConfig.Schema, opts.StagingSchema), tableName)
 	case constants.MSSQL:
-		return fmt.Sprintf("%s.%s", stringutil.Override(t.TopicConfig.Schema, opts.MsSQLSchemaOverride), t.Name(uppercaseEscNames, &sql.NameArgs{
-			Escape:   escape,
-			DestKind: kind,
-		}))
+		return fmt.Sprintf("%s.%s", stringutil.Override(t.TopicConfig.Schema, opts.MsSQLSchemaOverride, opts.StagingSchema), tableName)
+	case constants.Databricks:
+		// The fully qualified name for Databricks is: catalog.schema.tableName.
+		return fmt.Sprintf("%s.%s.%s", opts.DatabricksCatalog, stringutil.Override(t.TopicConfig.Schema, opts.StagingSchema), tableName)
 	case constants.BigQuery:
 		// The fully qualified name for BigQuery is: project_id.dataset.tableName.
 		// We are escaping the project_id and dataset because there could be special characters.
-		return fmt.Sprintf("`%s`.`%s`.%s", opts.BigQueryProjectID, t.TopicConfig.Database, t.Name(uppercaseEscNames, &sql.NameArgs{
-			Escape:   escape,
-			DestKind: kind,
-		}))
+		return fmt.Sprintf("`%s`.`%s`.%s", opts.BigQueryProjectID, t.TopicConfig.BigQueryDataset(), tableName)
 	default:
-		return fmt.Sprintf("%s.%s.%s", t.TopicConfig.Database, t.TopicConfig.Schema, t.Name(uppercaseEscNames, &sql.NameArgs{
-			Escape:   escape,
-			DestKind: kind,
-		}))
+		return fmt.Sprintf("%s.%s.%s", t.TopicConfig.Database, stringutil.Override(t.TopicConfig.Schema, opts.StagingSchema), tableName)
 	}
 }
 
@@ -225,7 +586,7 @@ func (t *TableData) NumberOfRows() uint {
 		return 0
 	}
 
-	if t.mode == config.History {
+	if t.AppendOnly() {
 		return uint(len(t.rows))
 	}
 
@@ -270,20 +631,44 @@ func (t *TableData) TempTableSuffix() string {
 	return fmt.Sprintf("%s_%d", t.temporaryTableSuffix, time.Now().Add(constants.TemporaryTableTTL).Unix())
 }
 
+// ApproxSize returns the estimated size of the buffered rows in bytes.
+func (t *TableData) ApproxSize() int {
+	return t.approxSize
+}
+
 // ShouldFlush will return whether Transfer should flush
 // If so, what is the reason?
 func (t *TableData) ShouldFlush(cfg config.Config) (bool, string) {
-	if t.NumberOfRows() > cfg.BufferRows {
+	bufferRows := cfg.BufferRows
+	if t.TopicConfig.BufferRows > 0 {
+		bufferRows = t.TopicConfig.BufferRows
+	}
+
+	if t.NumberOfRows() > bufferRows {
 		return true, "rows"
 	}
 
-	if t.approxSize > cfg.FlushSizeKb*1024 {
+	flushSizeKb := cfg.FlushSizeKb
+	if t.TopicConfig.FlushSizeKb > 0 {
+		flushSizeKb = t.TopicConfig.FlushSizeKb
+	}
+
+	if t.approxSize > flushSizeKb*1024 {
 		return true, "size"
 	}
 
 	return false, ""
 }
 
+// FlushInterval returns the topic's flush interval override, if it's not set then we'll return `defaultInterval`.
+func (t *TableData) FlushInterval(defaultInterval time.Duration) time.Duration {
+	if t.TopicConfig.FlushIntervalSeconds > 0 {
+		return time.Duration(t.TopicConfig.FlushIntervalSeconds) * time.Second
+	}
+
+	return defaultInterval
+}
+
 // MergeColumnsFromDestination - When running Transfer, we will have 2 column types.
 // 1) TableData (constructed in-memory)
 // 2) TableConfig (coming from the SQL DESCRIBE or equivalent statement) from the destination
@@ -320,9 +705,19 @@ func (t *TableData) MergeColumnsFromDestination(destCols ...columns.Column) {
 				if foundColumn.KindDetails.OptionalStringPrecision != nil {
 					inMemoryCol.KindDetails.OptionalStringPrecision = foundColumn.KindDetails.OptionalStringPrecision
 				}
+
+				// The declared width of the destination column is what we need to fit into, if the destination does not declare a width, we should not hold values to the source's width.
+				inMemoryCol.KindDetails.OptionalIntegerKind = foundColumn.KindDetails.OptionalIntegerKind
+
+				// The element type needs to match the destination, if the destination does not know the element type, we'll fall back to a generic array.
+				inMemoryCol.KindDetails.OptionalArrayElementKind = foundColumn.KindDetails.OptionalArrayElementKind
+				// Same goes for whether the destination column can only contain objects.
+				inMemoryCol.KindDetails.OptionalObject = foundColumn.KindDetails.OptionalObject
 			}
 
 			inMemoryCol.SetBackfilled(foundColumn.Backfilled())
+			// If the destination column's name differs from ours by case, we'll need to use its name in our queries.
+			inMemoryCol.SetDestinationName(foundColumn.DestinationName())
 			if foundColumn.KindDetails.ExtendedTimeDetails != nil {
 				if inMemoryCol.KindDetails.ExtendedTimeDetails == nil {
 					inMemoryCol.KindDetails.ExtendedTimeDetails = &ext.NestedKind{}
@@ -3,6 +3,7 @@ package optimization
 import (
 	"fmt"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/ptr"
 	"github.com/artie-labs/transfer/lib/typing"
 	"github.com/artie-labs/transfer/lib/typing/ext"
 	"github.com/stretchr/testify/assert"
@@ -117,6 +119,7 @@ func TestNewTableData_TableName(t *testing.T) {
 		tableName string
 		schema    string
 		db        string
+		dataset   string
 
 		expectedName            string
 		expectedSnowflakeFqName string
@@ -138,24 +141,51 @@ func TestNewTableData_TableName(t *testing.T) {
 			expectedRedshiftFqName:  "public.food",
 			expectedS3FqName:        "db.public.food",
 		},
+		{
+			name:      "schema and dataset are overridden for the topic",
+			tableName: "food",
+			schema:    "analytics",
+			db:        "db",
+			dataset:   "warehouse",
+
+			expectedName:            "food",
+			expectedSnowflakeFqName: "db.analytics.food",
+			expectedBigQueryFqName:  "`artie`.`warehouse`.food",
+			expectedRedshiftFqName:  "analytics.food",
+			expectedS3FqName:        "db.analytics.food",
+		},
 	}
 
 	bqProjectID := "artie"
 	for _, testCase := range testCases {
-		td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{Database: testCase.db, Schema: testCase.schema}, testCase.tableName)
+		td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{Database: testCase.db, Schema: testCase.schema, Dataset: testCase.dataset}, testCase.tableName)
 		assert.Equal(t, testCase.expectedName, td.RawName(), testCase.name)
 		assert.Equal(t, testCase.expectedName, td.name, testCase.name)
 
-		assert.Equal(t, testCase.expectedSnowflakeFqName, td.ToFqName(constants.Snowflake, true, false, FqNameOpts{}), testCase.name)
-		assert.Equal(t, testCase.expectedBigQueryFqName, td.ToFqName(constants.BigQuery, true, false, FqNameOpts{BigQueryProjectID: bqProjectID}), testCase.name)
-		assert.Equal(t, testCase.expectedBigQueryFqName, td.ToFqName(constants.BigQuery, true, false, FqNameOpts{BigQueryProjectID: bqProjectID}), testCase.name)
+		assert.Equal(t, testCase.expectedSnowflakeFqName, td.ToFqName(constants.Snowflake, true, config.PreserveCasing, FqNameOpts{}), testCase.name)
+		assert.Equal(t, testCase.expectedBigQueryFqName, td.ToFqName(constants.BigQuery, true, config.PreserveCasing, FqNameOpts{BigQueryProjectID: bqProjectID}), testCase.name)
+		assert.Equal(t, testCase.expectedBigQueryFqName, td.ToFqName(constants.BigQuery, true, config.PreserveCasing, FqNameOpts{BigQueryProjectID: bqProjectID}), testCase.name)
+		assert.Equal(t, testCase.expectedRedshiftFqName, td.ToFqName(constants.Redshift, true, config.PreserveCasing, FqNameOpts{}), testCase.name)
 
 		// S3 does not escape, so let's test both to make sure.
-		assert.Equal(t, testCase.expectedS3FqName, td.ToFqName(constants.S3, true, false, FqNameOpts{}), testCase.name)
-		assert.Equal(t, testCase.expectedS3FqName, td.ToFqName(constants.S3, false, false, FqNameOpts{}), testCase.name)
+		assert.Equal(t, testCase.expectedS3FqName, td.ToFqName(constants.S3, true, config.PreserveCasing, FqNameOpts{}), testCase.name)
+		assert.Equal(t, testCase.expectedS3FqName, td.ToFqName(constants.S3, false, config.PreserveCasing, FqNameOpts{}), testCase.name)
 	}
 }
 
+func TestTableData_ToFqName_IdentifierCasing(t *testing.T) {
+	// `group` is a reserved keyword, so it needs to be escaped.
+	td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{Database: "db", Schema: "public"}, "group")
+	assert.Equal(t, `db.public."group"`, td.ToFqName(constants.Snowflake, true, config.PreserveCasing, FqNameOpts{}))
+	assert.Equal(t, `db.public."GROUP"`, td.ToFqName(constants.Snowflake, true, config.UpperCasing, FqNameOpts{}))
+	assert.Equal(t, `db.public."group"`, td.ToFqName(constants.Snowflake, true, config.LowerCasing, FqNameOpts{}))
+	assert.Equal(t, `public."GROUP"`, td.ToFqName(constants.Redshift, true, config.UpperCasing, FqNameOpts{}))
+	assert.Equal(t, "`artie`.`db`.`GROUP`", td.ToFqName(constants.BigQuery, true, config.UpperCasing, FqNameOpts{BigQueryProjectID: "artie"}))
+
+	// Not escaping, so the casing is not applied.
+	assert.Equal(t, "db.public.group", td.ToFqName(constants.Snowflake, false, config.UpperCasing, FqNameOpts{}))
+}
+
 func TestTableData_ReadOnlyInMemoryCols(t *testing.T) {
 	// Making sure the columns are actually read only.
 	var cols columns.Columns
@@ -311,6 +341,154 @@ func TestTableData_ShouldFlushRowSize(t *testing.T) {
 	assert.Equal(t, "size", flushReason)
 }
 
+func TestTableData_ShouldFlushTopicOverrides(t *testing.T) {
+	cfg := config.Config{
+		FlushSizeKb: 500,
+		BufferRows:  1000,
+	}
+
+	{
+		// The chatty topic flushes at its own row threshold.
+		chatty := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{BufferRows: 2}, "chatty")
+		quiet := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{}, "quiet")
+		for i := 0; i < 3; i++ {
+			shouldFlush, _ := chatty.ShouldFlush(cfg)
+			assert.False(t, shouldFlush)
+			chatty.InsertRow(fmt.Sprint(i), map[string]any{"foo": "bar"}, false)
+			quiet.InsertRow(fmt.Sprint(i), map[string]any{"foo": "bar"}, false)
+		}
+
+		shouldFlush, flushReason := chatty.ShouldFlush(cfg)
+		assert.True(t, shouldFlush)
+		assert.Equal(t, "rows", flushReason)
+
+		// The other topic is still using the global default.
+		shouldFlush, flushReason = quiet.ShouldFlush(cfg)
+		assert.False(t, shouldFlush)
+		assert.Empty(t, flushReason)
+	}
+	{
+		// The chatty topic flushes at its own size threshold.
+		chatty := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{FlushSizeKb: 1}, "chatty")
+		quiet := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{}, "quiet")
+		for i := 0; i < 50; i++ {
+			chatty.InsertRow(fmt.Sprint(i), map[string]any{"foo": "bar", "name": "dusty the mini aussie"}, false)
+			quiet.InsertRow(fmt.Sprint(i), map[string]any{"foo": "bar", "name": "dusty the mini aussie"}, false)
+		}
+
+		shouldFlush, flushReason := chatty.ShouldFlush(cfg)
+		assert.True(t, shouldFlush)
+		assert.Equal(t, "size", flushReason)
+
+		shouldFlush, flushReason = quiet.ShouldFlush(cfg)
+		assert.False(t, shouldFlush)
+		assert.Empty(t, flushReason)
+	}
+}
+
+func TestTableData_FlushInterval(t *testing.T) {
+	td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{}, "foo")
+	assert.Equal(t, time.Minute, td.FlushInterval(time.Minute))
+
+	td = NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{FlushIntervalSeconds: 5}, "foo")
+	assert.Equal(t, 5*time.Second, td.FlushInterval(time.Minute))
+}
+
+func TestTableData_ApproxSize(t *testing.T) {
+	for _, sizeAccounting := range []config.FlushSizeAccounting{"", config.FlushSizeAccountingApproximate, config.FlushSizeAccountingSerialized} {
+		td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{}, "foo")
+		td.SetSizeAccounting(sizeAccounting)
+		assert.Equal(t, 0, td.ApproxSize(), sizeAccounting)
+
+		td.InsertRow("1", map[string]any{"id": 1, "name": "dusty the mini aussie"}, false)
+		insertedSize := td.ApproxSize()
+		assert.Positive(t, insertedSize, sizeAccounting)
+
+		// Updating the row should replace the size of the previous row instead of adding to it.
+		td.InsertRow("1", map[string]any{"id": 1, "name": "dusty"}, false)
+		updatedSize := td.ApproxSize()
+		assert.Less(t, updatedSize, insertedSize, sizeAccounting)
+
+		td.InsertRow("1", map[string]any{"id": 1, "name": "dusty the mini aussie"}, false)
+		assert.Equal(t, insertedSize, td.ApproxSize(), sizeAccounting)
+
+		// TOAST values are carried over from the previous row, so the size should not change.
+		td.InsertRow("1", map[string]any{"id": 1, "name": constants.ToastUnavailableValuePlaceholder}, false)
+		assert.Equal(t, insertedSize, td.ApproxSize(), sizeAccounting)
+
+		// Another row is additive.
+		td.InsertRow("2", map[string]any{"id": 1, "name": "dusty the mini aussie"}, false)
+		assert.Equal(t, 2*insertedSize, td.ApproxSize(), sizeAccounting)
+	}
+	{
+		// Serialized accounting will also count the column names.
+		td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{}, "foo")
+		td.SetSizeAccounting(config.FlushSizeAccountingSerialized)
+		td.InsertRow("1", map[string]any{"id": 12345, "name": "dusty", "is_good_dog": true}, false)
+		assert.Equal(t, len("id12345namedustyis_good_dogtrue"), td.ApproxSize())
+	}
+}
+
+func TestTableData_InsertRowTombstone(t *testing.T) {
+	{
+		// Default strategy, the delete replaces the previous row.
+		td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{SoftDelete: true}, "foo")
+		td.InsertRow("123", map[string]any{"id": 123, "name": "dusty", constants.DeleteColumnMarker: false}, false)
+		td.InsertRow("123", map[string]any{"id": 123, constants.DeleteColumnMarker: true}, true)
+		assert.Equal(t, []map[string]any{{"id": 123, constants.DeleteColumnMarker: true}}, td.Rows())
+	}
+	{
+		// Tombstone, the columns that are missing from the before image should be carried over from the previous row.
+		td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{SoftDelete: true, SoftDeleteStrategy: kafkalib.SoftDeleteTombstone}, "foo")
+		td.InsertRow("123", map[string]any{"id": 123, "name": "dusty", "age": 3, constants.DeleteColumnMarker: false}, false)
+		td.InsertRow("123", map[string]any{"id": 123, "age": 4, constants.DeleteColumnMarker: true}, true)
+		assert.Equal(t, []map[string]any{{"id": 123, "name": "dusty", "age": 4, constants.DeleteColumnMarker: true}}, td.Rows())
+	}
+}
+
+func TestTableData_AppendOnly(t *testing.T) {
+	{
+		// Replication
+		td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{}, "foo")
+		assert.False(t, td.AppendOnly())
+		td.InsertRow("123", map[string]any{"id": 123, "event": "created"}, false)
+		td.InsertRow("123", map[string]any{"id": 123, "event": "updated"}, false)
+		assert.Equal(t, uint(1), td.NumberOfRows())
+	}
+	{
+		// History
+		td := NewTableData(nil, config.History, nil, kafkalib.TopicConfig{}, "foo")
+		assert.True(t, td.AppendOnly())
+	}
+	{
+		// Append only, rows should not be deduped on the primary key.
+		td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{AppendOnly: true}, "foo")
+		assert.True(t, td.AppendOnly())
+		td.InsertRow("123", map[string]any{"id": 123, "event": "created"}, false)
+		td.InsertRow("123", map[string]any{"id": 123, "event": "updated"}, false)
+		assert.Equal(t, uint(2), td.NumberOfRows())
+		assert.Equal(t, []map[string]any{{"id": 123, "event": "created"}, {"id": 123, "event": "updated"}}, td.Rows())
+	}
+}
+
+func TestTableData_MaxStringLength(t *testing.T) {
+	td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{}, "foo")
+	assert.Equal(t, 0, td.MaxStringLength("name"))
+
+	td.InsertRow("1", map[string]any{"id": 1, "Name": "dusty"}, false)
+	td.InsertRow("2", map[string]any{"id": 2, "Name": "robin", "bio": "hello"}, false)
+	td.InsertRow("3", map[string]any{"id": 3, "Name": "jo", "bio": constants.ToastUnavailableValuePlaceholder}, false)
+	assert.Equal(t, 5, td.MaxStringLength("name"))
+	assert.Equal(t, 5, td.MaxStringLength("NAME"))
+	assert.Equal(t, 5, td.MaxStringLength("bio"))
+	// Non-string values are not tracked.
+	assert.Equal(t, 0, td.MaxStringLength("id"))
+
+	// Lengths are in bytes.
+	td.InsertRow("1", map[string]any{"id": 1, "Name": "dusty 🐶"}, false)
+	assert.Equal(t, 10, td.MaxStringLength("name"))
+}
+
 func TestTableData_InsertRowIntegrity(t *testing.T) {
 	td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{}, "foo")
 	assert.Equal(t, 0, int(td.NumberOfRows()))
@@ -326,3 +504,362 @@ func TestTableData_InsertRowIntegrity(t *testing.T) {
 		assert.True(t, td.ContainOtherOperations())
 	}
 }
+
+func TestTableData_TableNameTemplate(t *testing.T) {
+	{
+		// Static prefix
+		td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{Database: "db", Schema: "public", TableNameTemplate: "prod_{table}"}, "orders")
+		assert.Equal(t, "prod_orders", td.RawName())
+		assert.Equal(t, "db.public.prod_orders", td.ToFqName(constants.Snowflake, true, config.PreserveCasing, FqNameOpts{}))
+		assert.Equal(t, "public.prod_orders", td.ToFqName(constants.Redshift, true, config.PreserveCasing, FqNameOpts{}))
+	}
+	{
+		// Multiple placeholders
+		td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{Database: "db", Schema: "public", TableNameTemplate: "{schema}__{table}"}, "orders")
+		assert.Equal(t, "public__orders", td.RawName())
+		assert.Equal(t, "`project`.`db`.public__orders", td.ToFqName(constants.BigQuery, true, config.PreserveCasing, FqNameOpts{BigQueryProjectID: "project"}))
+	}
+}
+
+func TestTableData_TruncatedName(t *testing.T) {
+	longName := strings.Repeat("a", 200)
+	td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{Database: "db", Schema: "public"}, longName)
+	assert.Len(t, td.TruncatedName(constants.Redshift), constants.RedshiftMaxIdentifierLength)
+	// The describe queries should look up the same name that the table was created with.
+	assert.Equal(t, "public."+td.TruncatedName(constants.Redshift), td.ToFqName(constants.Redshift, true, config.PreserveCasing, FqNameOpts{}))
+	assert.Equal(t, longName, td.TruncatedName(constants.S3))
+}
+
+func TestTableData_PartialUpdateBatches(t *testing.T) {
+	td := NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{PartialUpdate: true}, "foo")
+	td.InsertRow("1", map[string]any{"id": 1, "name": "dusty", "email": "dusty@example.com"}, false)
+	td.InsertRow("2", map[string]any{"id": 2, "email": "robin@example.com"}, false)
+	td.InsertRow("3", map[string]any{"id": 3, "email": "charlie@example.com"}, false)
+	// The columns from the previous row should be carried over.
+	td.InsertRow("1", map[string]any{"id": 1, "email": "dusty@artie.com"}, false)
+	assert.Equal(t, map[string]any{"id": 1, "name": "dusty", "email": "dusty@artie.com"}, td.rowsData["1"])
+
+	batches := td.PartialUpdateBatches()
+	assert.Len(t, batches, 2)
+	assert.Equal(t, []string{"email", "id"}, batches[0].Columns)
+	assert.Equal(t, uint(2), batches[0].TableData.NumberOfRows())
+	assert.Equal(t, []string{"email", "id", "name"}, batches[1].Columns)
+	assert.Equal(t, uint(1), batches[1].TableData.NumberOfRows())
+	assert.Equal(t, []map[string]any{{"id": 1, "name": "dusty", "email": "dusty@artie.com"}}, batches[1].TableData.Rows())
+
+	// The original table data should not be modified.
+	assert.Equal(t, uint(3), td.NumberOfRows())
+
+	// Each batch should have its own temporary table, with the expiry at the end.
+	td.ResetTempTableSuffix()
+	batches = td.PartialUpdateBatches()
+	for idx, batch := range batches {
+		suffix := batch.TableData.TempTableSuffix()
+		assert.True(t, strings.HasPrefix(suffix, fmt.Sprintf("%s_%d_", td.temporaryTableSuffix, idx)), suffix)
+		parts := strings.Split(suffix, "_")
+		assert.Len(t, parts[len(parts)-1], 10, suffix)
+	}
+
+	// If partial updates are not enabled, the row is replaced.
+	td = NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+	td.InsertRow("1", map[string]any{"id": 1, "name": "dusty"}, false)
+	td.InsertRow("1", map[string]any{"id": 1, "email": "dusty@artie.com"}, false)
+	assert.Equal(t, map[string]any{"id": 1, "email": "dusty@artie.com"}, td.rowsData["1"])
+}
+
+func TestTableData_BatchID(t *testing.T) {
+	{
+		// The queue does not have offsets
+		td := NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		assert.Len(t, td.BatchID(), 16)
+		assert.Equal(t, td.BatchID(), td.BatchID())
+		assert.NotEqual(t, td.BatchID(), NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo").BatchID())
+	}
+	{
+		// Derived from the first offset of each partition
+		td := NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		td.RecordOffset("0", 5)
+		td.RecordOffset("1", 10)
+		batchID := td.BatchID()
+
+		// Later offsets do not change it.
+		td.RecordOffset("0", 6)
+		assert.Equal(t, batchID, td.BatchID())
+
+		// The same offsets after a restart will have the same batch ID.
+		other := NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		other.RecordOffset("1", 10)
+		other.RecordOffset("0", 5)
+		assert.Equal(t, batchID, other.BatchID())
+
+		// Each half of a bisected batch has its own batch ID.
+		left, right := td.Bisect()
+		assert.Equal(t, batchID+"_0", left.BatchID())
+		assert.Equal(t, batchID+"_1", right.BatchID())
+		leftLeft, _ := left.Bisect()
+		assert.Equal(t, batchID+"_00", leftLeft.BatchID())
+	}
+}
+
+func TestTableData_Bisect(t *testing.T) {
+	{
+		// Replication
+		td := NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		for _, pk := range []string{"3", "1", "4", "2", "5"} {
+			td.InsertRow(pk, map[string]any{"id": pk}, false)
+		}
+
+		left, right := td.Bisect()
+		assert.Equal(t, uint(2), left.NumberOfRows())
+		assert.Equal(t, map[string]map[string]any{"1": {"id": "1"}, "2": {"id": "2"}}, left.rowsData)
+		assert.Equal(t, uint(3), right.NumberOfRows())
+		assert.Equal(t, map[string]map[string]any{"3": {"id": "3"}, "4": {"id": "4"}, "5": {"id": "5"}}, right.rowsData)
+		assert.Equal(t, td.ApproxSize(), left.ApproxSize()+right.ApproxSize())
+
+		// Bisecting a single row will leave the left half empty.
+		left, right = right.Bisect()
+		left, right = left.Bisect()
+		assert.Equal(t, uint(0), left.NumberOfRows())
+		assert.Equal(t, []map[string]any{{"id": "3"}}, right.Rows())
+
+		// The original table data should not be modified.
+		assert.Equal(t, uint(5), td.NumberOfRows())
+	}
+	{
+		// Append only
+		td := NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{AppendOnly: true}, "foo")
+		for i := 0; i < 3; i++ {
+			td.InsertRow("1", map[string]any{"id": "1", "idx": i}, false)
+		}
+
+		left, right := td.Bisect()
+		assert.Equal(t, []map[string]any{{"id": "1", "idx": 0}}, left.Rows())
+		assert.Equal(t, []map[string]any{{"id": "1", "idx": 1}, {"id": "1", "idx": 2}}, right.Rows())
+		assert.Equal(t, uint(3), td.NumberOfRows())
+	}
+}
+
+func TestTableData_Without(t *testing.T) {
+	{
+		// Replication
+		td := NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		for _, pk := range []string{"1", "2", "3", "4"} {
+			td.InsertRow(pk, map[string]any{"id": pk}, false)
+		}
+
+		left, right := td.Bisect()
+		_, failed := left.Bisect()
+		rest, err := td.Without(failed, right)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]map[string]any{"1": {"id": "1"}}, rest.rowsData)
+		assert.Equal(t, td.ApproxSize()/4, rest.ApproxSize())
+		assert.Equal(t, uint(4), td.NumberOfRows())
+	}
+	{
+		// Append only
+		td := NewTableData(nil, config.History, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		_, err := td.Without()
+		assert.ErrorContains(t, err, "removing rows is not supported for append only tables")
+	}
+}
+
+func TestTableData_Clone(t *testing.T) {
+	{
+		// Replication
+		cols := &columns.Columns{}
+		cols.AddColumn(columns.NewColumn("id", typing.Integer))
+		td := NewTableData(cols, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		td.InsertRow("1", map[string]any{"id": 1}, false)
+
+		clone := td.Clone()
+		assert.Equal(t, td, clone)
+		clone.MergeColumnsFromDestination(columns.NewColumn("id", typing.String))
+		clone.rowsData["1"]["id"] = "1"
+		clone.InsertRow("2", map[string]any{"id": 2}, false)
+
+		col, isOk := td.ReadOnlyInMemoryCols().GetColumn("id")
+		assert.True(t, isOk)
+		assert.Equal(t, typing.Integer, col.KindDetails)
+		assert.Equal(t, []map[string]any{{"id": 1}}, td.Rows())
+	}
+	{
+		// Append only
+		td := NewTableData(nil, config.History, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		for i := 0; i < 3; i++ {
+			td.InsertRow("1", map[string]any{"id": "1", "idx": i}, false)
+		}
+
+		clone := td.Clone()
+		clone.DropRows(2)
+		assert.Equal(t, []map[string]any{{"id": "1", "idx": 2}}, clone.Rows())
+		assert.Equal(t, td.ApproxSize()/3, clone.ApproxSize())
+		assert.Equal(t, uint(3), td.NumberOfRows())
+
+		// Dropping more rows than there are will leave it empty.
+		clone.DropRows(5)
+		assert.Equal(t, uint(0), clone.NumberOfRows())
+	}
+}
+
+func TestTableData_TemporaryTableName(t *testing.T) {
+	td := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{Database: "db", Schema: "public"}, "orders")
+	td.ResetTempTableSuffix()
+
+	{
+		// No staging schema
+		assert.True(t, strings.HasPrefix(td.TemporaryTableName(constants.Snowflake, FqNameOpts{}), "db.public.orders___artie_"))
+		assert.True(t, strings.HasPrefix(td.TemporaryTableName(constants.Redshift, FqNameOpts{}), "public.orders___artie_"))
+	}
+	{
+		// Staging schema
+		opts := FqNameOpts{StagingSchema: "staging"}
+		assert.True(t, strings.HasPrefix(td.TemporaryTableName(constants.Snowflake, opts), "db.staging.orders___artie_"))
+		assert.True(t, strings.HasPrefix(td.TemporaryTableName(constants.Redshift, opts), "staging.orders___artie_"))
+		assert.True(t, strings.HasPrefix(td.TemporaryTableName(constants.MSSQL, FqNameOpts{MsSQLSchemaOverride: "dbo", StagingSchema: "staging"}), "staging.orders___artie_"))
+		assert.True(t, strings.HasPrefix(td.TemporaryTableName(constants.Databricks, FqNameOpts{DatabricksCatalog: "catalog", StagingSchema: "staging"}), "catalog.staging.orders___artie_"))
+
+		// The target table should not be affected.
+		assert.Equal(t, "db.public.orders", td.ToFqName(constants.Snowflake, false, config.PreserveCasing, FqNameOpts{}))
+	}
+	{
+		// Names that are over the max identifier length will be truncated, but the suffix (which has the expiry) is kept.
+		longTd := NewTableData(nil, config.Replication, nil, kafkalib.TopicConfig{Database: "db", Schema: "public"}, strings.Repeat("a", 200))
+		longTd.ResetTempTableSuffix()
+
+		tableName := strings.TrimPrefix(longTd.TemporaryTableName(constants.Redshift, FqNameOpts{}), "public.")
+		assert.Len(t, tableName, constants.RedshiftMaxIdentifierLength)
+		assert.Regexp(t, `_+artie_[a-z0-9]{5}_\d+$`, tableName)
+	}
+}
+
+func TestTableData_InsertRowAt(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	{
+		// Three updates to the same primary key should collapse to the last one.
+		td := NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		for i := range 3 {
+			assert.True(t, td.InsertRowAt("id=1", map[string]any{"id": 1, "name": fmt.Sprintf("update-%d", i)}, false, ts.Add(time.Duration(i)*time.Second)), i)
+		}
+
+		assert.Equal(t, uint(1), td.NumberOfRows())
+		assert.Equal(t, []map[string]any{{"id": 1, "name": "update-2"}}, td.Rows())
+	}
+	{
+		// Rows with the same timestamp will replace the buffered row.
+		td := NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		assert.True(t, td.InsertRowAt("id=1", map[string]any{"id": 1, "name": "first"}, false, ts))
+		assert.True(t, td.InsertRowAt("id=1", map[string]any{"id": 1, "name": "second"}, false, ts))
+		assert.Equal(t, []map[string]any{{"id": 1, "name": "second"}}, td.Rows())
+	}
+	{
+		// An older row should not overwrite a newer one.
+		td := NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		assert.True(t, td.InsertRowAt("id=1", map[string]any{"id": 1, "name": "newer"}, false, ts.Add(time.Minute)))
+		approxSize := td.ApproxSize()
+		assert.False(t, td.InsertRowAt("id=1", map[string]any{"id": 1, "name": "older"}, false, ts))
+		assert.Equal(t, []map[string]any{{"id": 1, "name": "newer"}}, td.Rows())
+		assert.Equal(t, approxSize, td.ApproxSize())
+
+		// Other primary keys are not affected.
+		assert.True(t, td.InsertRowAt("id=2", map[string]any{"id": 2, "name": "older"}, false, ts))
+		assert.Equal(t, uint(2), td.NumberOfRows())
+	}
+	{
+		// If the position is unknown, the row is always kept.
+		td := NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		assert.True(t, td.InsertRowAt("id=1", map[string]any{"id": 1, "name": "first"}, false, ts))
+		td.InsertRow("id=1", map[string]any{"id": 1, "name": "second"}, false)
+		assert.True(t, td.InsertRowAt("id=1", map[string]any{"id": 1, "name": "third"}, false, ts.Add(-time.Minute)))
+		assert.Equal(t, []map[string]any{{"id": 1, "name": "third"}}, td.Rows())
+	}
+	{
+		// Append only tables keep every row.
+		td := NewTableData(nil, config.History, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		assert.True(t, td.InsertRowAt("id=1", map[string]any{"id": 1, "name": "newer"}, false, ts.Add(time.Minute)))
+		assert.True(t, td.InsertRowAt("id=1", map[string]any{"id": 1, "name": "older"}, false, ts))
+		assert.Equal(t, uint(2), td.NumberOfRows())
+	}
+}
+
+func TestRowPosition_Before(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(cdcTs time.Time, partition string, offset int64) RowPosition {
+		return RowPosition{CDCTs: cdcTs, Partition: partition, Offset: ptr.ToInt64(offset)}
+	}
+
+	// Within the same partition, the offset takes precedence over the CDC timestamp.
+	assert.False(t, at(ts, "0", 10).Before(at(ts.Add(time.Second), "0", 5)))
+	assert.True(t, at(ts.Add(time.Second), "0", 5).Before(at(ts, "0", 10)))
+
+	// The CDC timestamps are compared across partitions.
+	assert.True(t, at(ts, "0", 10).Before(at(ts.Add(time.Second), "1", 5)))
+	assert.False(t, at(ts.Add(time.Second), "1", 5).Before(at(ts, "0", 10)))
+
+	// Or if an offset is missing.
+	assert.True(t, RowPosition{CDCTs: ts, Partition: "0"}.Before(at(ts.Add(time.Second), "0", 5)))
+	assert.False(t, at(ts.Add(time.Second), "0", 5).Before(RowPosition{CDCTs: ts, Partition: "0"}))
+
+	// Without a CDC timestamp, the offsets are compared within the same partition.
+	assert.True(t, at(time.Time{}, "0", 5).Before(at(time.Time{}, "0", 10)))
+	assert.False(t, at(time.Time{}, "0", 10).Before(at(time.Time{}, "0", 5)))
+	assert.False(t, at(time.Time{}, "0", 5).Before(at(time.Time{}, "0", 5)))
+	assert.True(t, at(time.Time{}, "0", 5).Before(at(ts, "0", 10)))
+	assert.False(t, at(ts, "0", 10).Before(at(time.Time{}, "0", 5)))
+
+	// The same CDC timestamp also falls back to the offsets.
+	assert.True(t, at(ts, "0", 5).Before(at(ts, "0", 10)))
+	assert.False(t, at(ts, "0", 10).Before(at(ts, "0", 5)))
+
+	// Rows from different partitions without a CDC timestamp cannot be ordered.
+	assert.False(t, at(time.Time{}, "0", 5).Before(at(time.Time{}, "1", 10)))
+	assert.False(t, at(time.Time{}, "1", 10).Before(at(time.Time{}, "0", 5)))
+
+	// Unknown offsets
+	assert.False(t, RowPosition{Partition: "0"}.Before(at(time.Time{}, "0", 10)))
+	assert.False(t, at(time.Time{}, "0", 10).Before(RowPosition{Partition: "0"}))
+	assert.False(t, RowPosition{}.Before(RowPosition{}))
+}
+
+func TestTableData_InsertRowAtPosition(t *testing.T) {
+	at := func(partition string, offset int64) RowPosition {
+		return RowPosition{Partition: partition, Offset: ptr.ToInt64(offset)}
+	}
+	{
+		// Rows from the same partition are ordered by their offsets.
+		td := NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		assert.True(t, td.InsertRowAtPosition("id=1", map[string]any{"id": 1, "name": "first"}, false, at("0", 1)))
+		assert.True(t, td.InsertRowAtPosition("id=1", map[string]any{"id": 1, "name": "second"}, false, at("0", 2)))
+		assert.Equal(t, []map[string]any{{"id": 1, "name": "second"}}, td.Rows())
+
+		// An older offset should not overwrite a newer one.
+		assert.False(t, td.InsertRowAtPosition("id=1", map[string]any{"id": 1, "name": "replayed"}, false, at("0", 1)))
+		assert.Equal(t, []map[string]any{{"id": 1, "name": "second"}}, td.Rows())
+	}
+	{
+		// Within the same partition the later offset wins, even if the CDC timestamps disagree.
+		ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		td := NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		assert.True(t, td.InsertRowAtPosition("id=1", map[string]any{"id": 1, "name": "snapshot"}, false, RowPosition{CDCTs: ts.Add(time.Minute), Partition: "0", Offset: ptr.ToInt64(1)}))
+		assert.True(t, td.InsertRowAtPosition("id=1", map[string]any{"id": 1, "name": "update"}, false, RowPosition{CDCTs: ts, Partition: "0", Offset: ptr.ToInt64(2)}))
+		assert.Equal(t, []map[string]any{{"id": 1, "name": "update"}}, td.Rows())
+
+		// An older offset should not overwrite a newer one, even if it has a newer CDC timestamp.
+		assert.False(t, td.InsertRowAtPosition("id=1", map[string]any{"id": 1, "name": "replayed"}, false, RowPosition{CDCTs: ts.Add(time.Hour), Partition: "0", Offset: ptr.ToInt64(1)}))
+		assert.Equal(t, []map[string]any{{"id": 1, "name": "update"}}, td.Rows())
+	}
+	{
+		// Across partitions the CDC timestamps are compared instead.
+		ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		td := NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		assert.True(t, td.InsertRowAtPosition("id=1", map[string]any{"id": 1, "name": "newer"}, false, RowPosition{CDCTs: ts.Add(time.Minute), Partition: "0", Offset: ptr.ToInt64(1)}))
+		assert.False(t, td.InsertRowAtPosition("id=1", map[string]any{"id": 1, "name": "older"}, false, RowPosition{CDCTs: ts, Partition: "1", Offset: ptr.ToInt64(2)}))
+		assert.Equal(t, []map[string]any{{"id": 1, "name": "newer"}}, td.Rows())
+	}
+	{
+		// Rows from another partition without a CDC timestamp are always kept, since they cannot be ordered.
+		td := NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo")
+		assert.True(t, td.InsertRowAtPosition("id=1", map[string]any{"id": 1, "name": "first"}, false, at("0", 10)))
+		assert.True(t, td.InsertRowAtPosition("id=1", map[string]any{"id": 1, "name": "second"}, false, at("1", 5)))
+		assert.Equal(t, []map[string]any{{"id": 1, "name": "second"}}, td.Rows())
+	}
+}
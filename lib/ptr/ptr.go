@@ -14,6 +14,10 @@ func ToInt64(val int64) *int64 {
 	return &val
 }
 
+func ToFloat64(val float64) *float64 {
+	return &val
+}
+
 func ToBool(val bool) *bool {
 	return &val
 }
@@ -0,0 +1,229 @@
+package rowfilter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	identifierToken tokenKind = iota
+	stringToken
+	numberToken
+	operatorToken
+	punctuationToken
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+func (t token) isKeyword(keyword string) bool {
+	return t.kind == identifierToken && strings.EqualFold(t.value, keyword)
+}
+
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == ',':
+			tokens = append(tokens, token{kind: punctuationToken, value: string(r)})
+			i++
+		case r == '=' || r == '!' || r == '<' || r == '>':
+			op := string(r)
+			if i+1 < len(runes) && (runes[i+1] == '=' || (r == '<' && runes[i+1] == '>')) {
+				op += string(runes[i+1])
+			}
+
+			i += len(op)
+			if op == altNotEqualSym {
+				op = string(notEqual)
+			}
+
+			switch operator(op) {
+			case equal, notEqual, lessThan, lessThanEq, greaterThan, greaterThanEq:
+				tokens = append(tokens, token{kind: operatorToken, value: op})
+			default:
+				return nil, fmt.Errorf("invalid operator: %q", op)
+			}
+		case r == '\'':
+			var sb strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\'' {
+					// Two single quotes are an escaped single quote.
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						sb.WriteRune('\'')
+						i += 2
+						continue
+					}
+
+					closed = true
+					i++
+					break
+				}
+
+				sb.WriteRune(runes[i])
+				i++
+			}
+
+			if !closed {
+				return nil, fmt.Errorf("unterminated string literal")
+			}
+
+			tokens = append(tokens, token{kind: stringToken, value: sb.String()})
+		case unicode.IsDigit(r) || r == '-' || r == '.':
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+
+			tokens = append(tokens, token{kind: numberToken, value: string(runes[start:i])})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+
+			tokens = append(tokens, token{kind: identifierToken, value: string(runes[start:i])})
+		default:
+			return nil, fmt.Errorf("unexpected character: %q", r)
+		}
+	}
+
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) next() token {
+	if p.done() {
+		return token{}
+	}
+
+	tok := p.tokens[p.pos]
+	p.pos++
+	return tok
+}
+
+func (p *parser) parseComparison() (comparison, error) {
+	if p.done() {
+		return comparison{}, fmt.Errorf("expected a column name, got the end of the expression")
+	}
+
+	columnTok := p.next()
+	if columnTok.kind != identifierToken || isReserved(columnTok) {
+		return comparison{}, fmt.Errorf("expected a column name, got: %q", columnTok.value)
+	}
+
+	opTok := p.next()
+	if opTok.isKeyword(string(in)) {
+		values, err := p.parseList()
+		if err != nil {
+			return comparison{}, err
+		}
+
+		return comparison{column: columnTok.value, operator: in, values: values}, nil
+	}
+
+	if opTok.kind != operatorToken {
+		return comparison{}, fmt.Errorf("expected an operator after %q, got: %q", columnTok.value, opTok.value)
+	}
+
+	value, err := p.parseLiteral()
+	if err != nil {
+		return comparison{}, err
+	}
+
+	op := operator(opTok.value)
+	if value.kind == nullLiteral && op != equal && op != notEqual {
+		return comparison{}, fmt.Errorf("NULL can only be used with = or !=")
+	}
+
+	return comparison{column: columnTok.value, operator: op, values: []literal{value}}, nil
+}
+
+func (p *parser) parseList() ([]literal, error) {
+	if tok := p.next(); tok.kind != punctuationToken || tok.value != "(" {
+		return nil, fmt.Errorf("expected ( after IN, got: %q", tok.value)
+	}
+
+	var values []literal
+	for {
+		value, err := p.parseLiteral()
+		if err != nil {
+			return nil, err
+		}
+
+		if value.kind == nullLiteral {
+			return nil, fmt.Errorf("NULL cannot be used within IN")
+		}
+
+		values = append(values, value)
+		tok := p.next()
+		if tok.kind == punctuationToken && tok.value == ")" {
+			return values, nil
+		}
+
+		if tok.kind != punctuationToken || tok.value != "," {
+			return nil, fmt.Errorf("expected , or ) within IN, got: %q", tok.value)
+		}
+	}
+}
+
+func (p *parser) parseLiteral() (literal, error) {
+	if p.done() {
+		return literal{}, fmt.Errorf("expected a value, got the end of the expression")
+	}
+
+	tok := p.next()
+	switch tok.kind {
+	case stringToken:
+		return literal{kind: stringLiteral, str: tok.value}, nil
+	case numberToken:
+		number, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return literal{}, fmt.Errorf("invalid number: %q", tok.value)
+		}
+
+		return literal{kind: numberLiteral, number: number}, nil
+	case identifierToken:
+		switch {
+		case tok.isKeyword(keywordTrue):
+			return literal{kind: boolLiteral, boolean: true}, nil
+		case tok.isKeyword(keywordFalse):
+			return literal{kind: boolLiteral, boolean: false}, nil
+		case tok.isKeyword(keywordNull):
+			return literal{kind: nullLiteral}, nil
+		}
+	}
+
+	return literal{}, fmt.Errorf("expected a value, got: %q", tok.value)
+}
+
+func isReserved(tok token) bool {
+	for _, keyword := range []string{keywordAnd, string(in), keywordNull, keywordTrue, keywordFalse} {
+		if tok.isKeyword(keyword) {
+			return true
+		}
+	}
+
+	return false
+}
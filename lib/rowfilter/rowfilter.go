@@ -0,0 +1,243 @@
+package rowfilter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type operator string
+
+const (
+	equal          operator = "="
+	notEqual       operator = "!="
+	lessThan       operator = "<"
+	lessThanEq     operator = "<="
+	greaterThan    operator = ">"
+	greaterThanEq  operator = ">="
+	in             operator = "IN"
+	altNotEqualSym          = "<>"
+)
+
+const (
+	keywordAnd   = "AND"
+	keywordNull  = "NULL"
+	keywordTrue  = "TRUE"
+	keywordFalse = "FALSE"
+)
+
+type literalKind int
+
+const (
+	stringLiteral literalKind = iota
+	numberLiteral
+	boolLiteral
+	nullLiteral
+)
+
+type literal struct {
+	kind    literalKind
+	str     string
+	number  float64
+	boolean bool
+}
+
+type comparison struct {
+	column   string
+	operator operator
+	values   []literal
+}
+
+// Filter is a compiled row filter expression, e.g. `tenant_id = 42 AND status != 'deleted'`.
+// Comparisons can be combined with AND and follow SQL semantics, so comparing a NULL (or missing) column against a non-NULL value will not match.
+type Filter struct {
+	comparisons []comparison
+}
+
+// Parse compiles `expression` into a Filter.
+func Parse(expression string) (*Filter, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("row filter is empty")
+	}
+
+	p := &parser{tokens: tokens}
+	var filter Filter
+	for {
+		cmp, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+
+		filter.comparisons = append(filter.comparisons, cmp)
+		if p.done() {
+			break
+		}
+
+		if tok := p.next(); !tok.isKeyword(keywordAnd) {
+			return nil, fmt.Errorf("expected AND, got: %q", tok.value)
+		}
+	}
+
+	return &filter, nil
+}
+
+// Matches returns true if `row` satisfies every comparison in the filter.
+func (f *Filter) Matches(row map[string]any) bool {
+	if f == nil {
+		return true
+	}
+
+	for _, cmp := range f.comparisons {
+		if !cmp.matches(lookup(row, cmp.column)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func lookup(row map[string]any, column string) any {
+	if value, isOk := row[column]; isOk {
+		return value
+	}
+
+	for key, value := range row {
+		if strings.EqualFold(key, column) {
+			return value
+		}
+	}
+
+	return nil
+}
+
+func (c comparison) matches(value any) bool {
+	if c.operator == in {
+		for _, lit := range c.values {
+			if compare(value, lit) == 0 {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	lit := c.values[0]
+	if lit.kind == nullLiteral {
+		switch c.operator {
+		case equal:
+			return value == nil
+		case notEqual:
+			return value != nil
+		default:
+			return false
+		}
+	}
+
+	result := compare(value, lit)
+	if result == incomparable {
+		return false
+	}
+
+	switch c.operator {
+	case equal:
+		return result == 0
+	case notEqual:
+		return result != 0
+	case lessThan:
+		return result < 0
+	case lessThanEq:
+		return result <= 0
+	case greaterThan:
+		return result > 0
+	case greaterThanEq:
+		return result >= 0
+	}
+
+	return false
+}
+
+// incomparable is returned by compare when the value cannot be compared against the literal.
+const incomparable = 2
+
+// compare returns -1, 0 or 1 if `value` is less than, equal to or greater than `lit`.
+func compare(value any, lit literal) int {
+	if value == nil || lit.kind == nullLiteral {
+		return incomparable
+	}
+
+	switch lit.kind {
+	case numberLiteral:
+		number, isOk := toFloat64(value)
+		if !isOk {
+			return incomparable
+		}
+
+		switch {
+		case number < lit.number:
+			return -1
+		case number > lit.number:
+			return 1
+		default:
+			return 0
+		}
+	case boolLiteral:
+		var boolean bool
+		switch castedValue := value.(type) {
+		case bool:
+			boolean = castedValue
+		case string:
+			parsed, err := strconv.ParseBool(castedValue)
+			if err != nil {
+				return incomparable
+			}
+			boolean = parsed
+		default:
+			return incomparable
+		}
+
+		if boolean == lit.boolean {
+			return 0
+		}
+
+		// Following Go's convention where false < true.
+		if !boolean {
+			return -1
+		}
+		return 1
+	default:
+		return strings.Compare(fmt.Sprint(value), lit.str)
+	}
+}
+
+func toFloat64(value any) (float64, bool) {
+	switch castedValue := value.(type) {
+	case int:
+		return float64(castedValue), true
+	case int8:
+		return float64(castedValue), true
+	case int16:
+		return float64(castedValue), true
+	case int32:
+		return float64(castedValue), true
+	case int64:
+		return float64(castedValue), true
+	case float32:
+		return float64(castedValue), true
+	case float64:
+		return castedValue, true
+	case string:
+		number, err := strconv.ParseFloat(castedValue, 64)
+		return number, err == nil
+	case fmt.Stringer:
+		// e.g. *decimal.Decimal
+		number, err := strconv.ParseFloat(castedValue.String(), 64)
+		return number, err == nil
+	}
+
+	return 0, false
+}
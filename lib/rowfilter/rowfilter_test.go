@@ -0,0 +1,110 @@
+package rowfilter
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+)
+
+func TestParse_Malformed(t *testing.T) {
+	testCases := []struct {
+		expression  string
+		expectedErr string
+	}{
+		{"", "row filter is empty"},
+		{"   ", "row filter is empty"},
+		{"tenant_id", "expected an operator after \"tenant_id\""},
+		{"tenant_id =", "expected a value, got the end of the expression"},
+		{"tenant_id == 42", `invalid operator: "=="`},
+		{"tenant_id ! 42", `invalid operator: "!"`},
+		{"= 42", `expected a column name, got: "="`},
+		{"status = 'deleted", "unterminated string literal"},
+		{"status = deleted", `expected a value, got: "deleted"`},
+		{"tenant_id = 42 status = 'a'", `expected AND, got: "status"`},
+		{"tenant_id = 42 AND", "expected a column name, got the end of the expression"},
+		{"tenant_id IN 42", `expected ( after IN, got: "42"`},
+		{"tenant_id IN (1, 2", `expected , or ) within IN, got: ""`},
+		{"tenant_id IN (1, NULL)", "NULL cannot be used within IN"},
+		{"tenant_id > NULL", "NULL can only be used with = or !="},
+		{"tenant_id = 4.2.1", `invalid number: "4.2.1"`},
+		{"tenant_id = 42;", "unexpected character: ';'"},
+	}
+
+	for _, testCase := range testCases {
+		_, err := Parse(testCase.expression)
+		assert.ErrorContains(t, err, testCase.expectedErr, testCase.expression)
+	}
+}
+
+func TestFilter_Matches(t *testing.T) {
+	row := map[string]any{
+		"tenant_id": 42,
+		"Status":    "active",
+		"score":     float64(9.5),
+		"amount":    decimal.NewDecimal(nil, 2, big.NewFloat(12.34)),
+		"is_admin":  false,
+		"deleted":   "true",
+		"nickname":  nil,
+	}
+
+	testCases := []struct {
+		expression string
+		expected   bool
+	}{
+		// Numbers
+		{"tenant_id = 42", true},
+		{"tenant_id = 42.0", true},
+		{"tenant_id != 42", false},
+		{"tenant_id <> 43", true},
+		{"tenant_id > 41", true},
+		{"tenant_id >= 42", true},
+		{"tenant_id < 42", false},
+		{"tenant_id <= 42", true},
+		{"score < 10", true},
+		{"score > -1", true},
+		{"amount > 12.3", true},
+		{"amount < 12.3", false},
+		// Strings, column names are matched case-insensitively if there is no exact match.
+		{"status = 'active'", true},
+		{"status != 'deleted'", true},
+		{"status = 'deleted'", false},
+		{"status > 'a'", true},
+		{"status = 'it''s'", false},
+		// Booleans
+		{"is_admin = false", true},
+		{"is_admin = TRUE", false},
+		{"deleted = true", true},
+		// IN
+		{"tenant_id IN (1, 2, 42)", true},
+		{"tenant_id in (1, 2)", false},
+		{"status IN ('active', 'pending')", true},
+		{"status IN ('deleted')", false},
+		// NULL
+		{"nickname = NULL", true},
+		{"nickname != null", false},
+		{"missing_column = NULL", true},
+		{"tenant_id != NULL", true},
+		// Comparing NULL against a value does not match
+		{"nickname != 'bob'", false},
+		{"missing_column = 1", false},
+		// Type mismatch does not match
+		{"status > 5", false},
+		{"tenant_id = true", false},
+		// AND
+		{"tenant_id = 42 AND status = 'active'", true},
+		{"tenant_id = 42 and status = 'deleted'", false},
+	}
+
+	for _, testCase := range testCases {
+		filter, err := Parse(testCase.expression)
+		assert.NoError(t, err, testCase.expression)
+		assert.Equal(t, testCase.expected, filter.Matches(row), testCase.expression)
+	}
+
+	// A nil filter matches everything.
+	var filter *Filter
+	assert.True(t, filter.Matches(row))
+}
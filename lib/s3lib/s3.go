@@ -3,41 +3,58 @@ package s3lib
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/credentials"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/artie-labs/transfer/lib/stringutil"
 )
 
 type UploadArgs struct {
-	Bucket                     string
-	OptionalS3Prefix           string
-	FilePath                   string
+	Bucket           string
+	OptionalS3Prefix string
+	FilePath         string
+	// OptionalObjectName - if set, this is used as the object's name instead of the local file's name.
+	OptionalObjectName         string
 	OverrideAWSAccessKeyID     *string
 	OverrideAWSAccessKeySecret *string
+	// OptionalKMSKeyARN - if set, the object will be encrypted at rest with SSE-KMS using this key.
+	OptionalKMSKeyARN string
 }
 
-// UploadLocalFileToS3 - takes a filepath with the file and bucket and optional expiry
-// It will then upload it and then return the S3 URI and any error(s).
-func UploadLocalFileToS3(ctx context.Context, args UploadArgs) (string, error) {
+func loadS3Client(ctx context.Context, overrideAWSAccessKeyID, overrideAWSAccessKeySecret *string) (*s3.Client, error) {
 	var cfg aws.Config
 	var err error
 
-	if args.OverrideAWSAccessKeyID != nil && args.OverrideAWSAccessKeySecret != nil {
-		creds := credentials.NewStaticCredentialsProvider(*args.OverrideAWSAccessKeyID, *args.OverrideAWSAccessKeySecret, "")
+	if overrideAWSAccessKeyID != nil && overrideAWSAccessKeySecret != nil {
+		creds := credentials.NewStaticCredentialsProvider(*overrideAWSAccessKeyID, *overrideAWSAccessKeySecret, "")
 		cfg, err = config.LoadDefaultConfig(ctx, config.WithCredentialsProvider(creds))
 	} else {
 		cfg, err = config.LoadDefaultConfig(ctx)
 	}
 
 	if err != nil {
-		return "", fmt.Errorf("failed loading s3 config: %w", err)
+		return nil, fmt.Errorf("failed loading s3 config: %w", err)
+	}
+
+	return s3.NewFromConfig(cfg), nil
+}
+
+// UploadLocalFileToS3 - takes a filepath with the file and bucket and optional expiry
+// It will then upload it and then return the S3 URI and any error(s).
+func UploadLocalFileToS3(ctx context.Context, args UploadArgs) (string, error) {
+	s3Client, err := loadS3Client(ctx, args.OverrideAWSAccessKeyID, args.OverrideAWSAccessKeySecret)
+	if err != nil {
+		return "", err
 	}
 
-	s3Client := s3.NewFromConfig(cfg)
 	file, err := os.Open(args.FilePath)
 	if err != nil {
 		return "", err
@@ -49,16 +66,23 @@ func UploadLocalFileToS3(ctx context.Context, args UploadArgs) (string, error) {
 		return "", err
 	}
 
-	objectKey := fileInfo.Name()
+	objectKey := stringutil.Override(fileInfo.Name(), args.OptionalObjectName)
 	if args.OptionalS3Prefix != "" {
 		objectKey = fmt.Sprintf("%s/%s", args.OptionalS3Prefix, objectKey)
 	}
 
-	_, err = s3Client.PutObject(ctx, &s3.PutObjectInput{
+	input := &s3.PutObjectInput{
 		Bucket: aws.String(args.Bucket),
 		Key:    aws.String(objectKey),
 		Body:   file,
-	})
+	}
+
+	if args.OptionalKMSKeyARN != "" {
+		input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		input.SSEKMSKeyId = aws.String(args.OptionalKMSKeyARN)
+	}
+
+	_, err = s3Client.PutObject(ctx, input)
 
 	if err != nil {
 		return "", err
@@ -66,3 +90,45 @@ func UploadLocalFileToS3(ctx context.Context, args UploadArgs) (string, error) {
 
 	return fmt.Sprintf("s3://%s/%s", args.Bucket, objectKey), nil
 }
+
+type ReadArgs struct {
+	Bucket                     string
+	Key                        string
+	OverrideAWSAccessKeyID     *string
+	OverrideAWSAccessKeySecret *string
+}
+
+// ReadObject - will download the object and return its contents.
+func ReadObject(ctx context.Context, args ReadArgs) ([]byte, error) {
+	s3Client, err := loadS3Client(ctx, args.OverrideAWSAccessKeyID, args.OverrideAWSAccessKeySecret)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(args.Bucket),
+		Key:    aws.String(args.Key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+
+	defer output.Body.Close()
+	return io.ReadAll(output.Body)
+}
+
+// ParseURI - will split an S3 URI (s3://bucket/key) into its bucket and key.
+func ParseURI(uri string) (string, string, error) {
+	for _, scheme := range []string{"s3://", "s3a://"} {
+		if path, isOk := strings.CutPrefix(uri, scheme); isOk {
+			bucket, key, _ := strings.Cut(path, "/")
+			if bucket == "" {
+				break
+			}
+
+			return bucket, key, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("invalid s3 uri: %q", uri)
+}
@@ -0,0 +1,29 @@
+package s3lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseURI(t *testing.T) {
+	{
+		bucket, key, err := ParseURI("s3://bucket/warehouse/db/table/metadata/snap-1.avro")
+		assert.NoError(t, err)
+		assert.Equal(t, "bucket", bucket)
+		assert.Equal(t, "warehouse/db/table/metadata/snap-1.avro", key)
+	}
+	{
+		bucket, key, err := ParseURI("s3a://bucket")
+		assert.NoError(t, err)
+		assert.Equal(t, "bucket", bucket)
+		assert.Equal(t, "", key)
+	}
+	{
+		_, _, err := ParseURI("gs://bucket/foo")
+		assert.ErrorContains(t, err, `invalid s3 uri: "gs://bucket/foo"`)
+
+		_, _, err = ParseURI("s3:///foo")
+		assert.ErrorContains(t, err, `invalid s3 uri: "s3:///foo"`)
+	}
+}
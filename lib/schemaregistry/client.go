@@ -0,0 +1,154 @@
+package schemaregistry
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/artie-labs/transfer/lib/config"
+)
+
+const (
+	// magicByte is the first byte of every message that is serialized with the Confluent wire format.
+	// The wire format looks like this: [magic byte (1)] [schema ID (4)] [payload (n)]
+	magicByte = 0
+	headerLen = 5
+
+	defaultTimeout = 10 * time.Second
+)
+
+// HasWireFormat returns true if the bytes look like they were serialized with the Confluent wire format.
+func HasWireFormat(bytes []byte) bool {
+	return len(bytes) >= headerLen && bytes[0] == magicByte
+}
+
+// ParseWireFormat will split the message into the schema ID and the encoded payload.
+func ParseWireFormat(bytes []byte) (int, []byte, error) {
+	if len(bytes) < headerLen {
+		return 0, nil, fmt.Errorf("message is too short to contain a schema ID, length: %d", len(bytes))
+	}
+
+	if bytes[0] != magicByte {
+		return 0, nil, fmt.Errorf("unexpected magic byte: %d", bytes[0])
+	}
+
+	return int(binary.BigEndian.Uint32(bytes[1:headerLen])), bytes[headerLen:], nil
+}
+
+type schemaResponse struct {
+	Schema string `json:"schema"`
+}
+
+// Client fetches schemas by ID from the schema registry and caches them.
+// Schema IDs are immutable within a registry, so the cache is only invalidated when a schema fails to decode a message.
+type Client struct {
+	url        string
+	username   string
+	password   string
+	httpClient *http.Client
+
+	schemas map[int]string
+	sync.RWMutex
+}
+
+func NewClient(cfg config.SchemaRegistry) *Client {
+	return &Client{
+		url:        strings.TrimSuffix(cfg.URL, "/"),
+		username:   cfg.Username,
+		password:   cfg.Password,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		schemas:    make(map[int]string),
+	}
+}
+
+// GetSchema returns the schema for `id`, fetching it from the registry if it has not been cached.
+func (c *Client) GetSchema(id int) (string, error) {
+	c.RLock()
+	schema, isOk := c.schemas[id]
+	c.RUnlock()
+	if isOk {
+		return schema, nil
+	}
+
+	schema, err := c.fetchSchema(id)
+	if err != nil {
+		return "", err
+	}
+
+	c.Lock()
+	c.schemas[id] = schema
+	c.Unlock()
+	return schema, nil
+}
+
+// Invalidate removes the schema from the cache, the next call to GetSchema will fetch it from the registry again.
+func (c *Client) Invalidate(id int) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.schemas, id)
+}
+
+// Ping checks that the schema registry is reachable and that it accepts our credentials.
+func (c *Client) Ping() error {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/subjects", c.url), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach schema registry: %w", err)
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to reach schema registry, status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+func (c *Client) fetchSchema(id int) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/schemas/ids/%d", c.url, id), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch schema id: %d: %w", id, err)
+	}
+
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch schema id: %d, status code: %d, body: %s", id, resp.StatusCode, string(body))
+	}
+
+	var schemaResp schemaResponse
+	if err = json.Unmarshal(body, &schemaResp); err != nil {
+		return "", fmt.Errorf("failed to unmarshal schema response: %w", err)
+	}
+
+	return schemaResp.Schema, nil
+}
@@ -0,0 +1,98 @@
+package schemaregistry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+)
+
+func TestParseWireFormat(t *testing.T) {
+	{
+		// Too short
+		_, _, err := ParseWireFormat([]byte{0, 0, 1})
+		assert.ErrorContains(t, err, "message is too short")
+		assert.False(t, HasWireFormat([]byte{0, 0, 1}))
+	}
+	{
+		// Wrong magic byte
+		_, _, err := ParseWireFormat([]byte{1, 0, 0, 0, 1, 2})
+		assert.ErrorContains(t, err, "unexpected magic byte: 1")
+		assert.False(t, HasWireFormat([]byte{1, 0, 0, 0, 1, 2}))
+	}
+	{
+		// Valid
+		id, payload, err := ParseWireFormat([]byte{0, 0, 0, 1, 2, 3, 4})
+		assert.NoError(t, err)
+		assert.Equal(t, 258, id)
+		assert.Equal(t, []byte{3, 4}, payload)
+		assert.True(t, HasWireFormat([]byte{0, 0, 0, 1, 2, 3, 4}))
+	}
+}
+
+func TestClient_GetSchema(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		username, password, isOk := r.BasicAuth()
+		assert.True(t, isOk)
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "pass", password)
+
+		switch r.URL.Path {
+		case "/schemas/ids/1":
+			w.Write([]byte(`{"schema": "\"string\""}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code": 40403, "message": "Schema not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(config.SchemaRegistry{URL: server.URL + "/", Username: "user", Password: "pass"})
+	for i := 0; i < 3; i++ {
+		schema, err := client.GetSchema(1)
+		assert.NoError(t, err)
+		assert.Equal(t, `"string"`, schema)
+	}
+
+	// Should have been cached after the first request.
+	assert.Equal(t, 1, requests)
+
+	client.Invalidate(1)
+	schema, err := client.GetSchema(1)
+	assert.NoError(t, err)
+	assert.Equal(t, `"string"`, schema)
+	assert.Equal(t, 2, requests)
+
+	_, err = client.GetSchema(2)
+	assert.ErrorContains(t, err, "failed to fetch schema id: 2, status code: 404")
+}
+
+func TestClient_Ping(t *testing.T) {
+	var healthy bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/subjects", r.URL.Path)
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("unavailable"))
+			return
+		}
+
+		w.Write([]byte(`["orders-value"]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(config.SchemaRegistry{URL: server.URL})
+	assert.ErrorContains(t, client.Ping(), "failed to reach schema registry, status code: 503, body: unavailable")
+
+	healthy = true
+	assert.NoError(t, client.Ping())
+
+	// Unreachable
+	server.Close()
+	assert.ErrorContains(t, client.Ping(), "failed to reach schema registry")
+}
@@ -0,0 +1,115 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Prefix is used to mark a config value as a reference to a secret, for example: `secret://vault/secret/transfer#password`
+const Prefix = "secret://"
+
+const (
+	AWSSecretsManager = "aws-sm"
+	GCPSecretManager  = "gcp-sm"
+	Vault             = "vault"
+)
+
+// Provider fetches a secret from a secrets manager.
+// `key` is optional and is used to pick a single field out of a secret that holds multiple values.
+type Provider interface {
+	GetSecret(ctx context.Context, name string, key string) (string, error)
+}
+
+// Reference is a parsed `secret://<provider>/<name>#<key>` value.
+type Reference struct {
+	Provider string
+	Name     string
+	Key      string
+}
+
+func (r Reference) String() string {
+	value := fmt.Sprintf("%s%s/%s", Prefix, r.Provider, r.Name)
+	if r.Key != "" {
+		value += "#" + r.Key
+	}
+
+	return value
+}
+
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+func ParseReference(value string) (Reference, error) {
+	if !IsReference(value) {
+		return Reference{}, fmt.Errorf("secret reference must start with %q", Prefix)
+	}
+
+	provider, rest, isOk := strings.Cut(strings.TrimPrefix(value, Prefix), "/")
+	if !isOk || provider == "" {
+		return Reference{}, fmt.Errorf("secret reference is missing the provider, expected: %s<provider>/<name>", Prefix)
+	}
+
+	name, key, _ := strings.Cut(rest, "#")
+	if name == "" {
+		return Reference{}, fmt.Errorf("secret reference is missing the secret name, expected: %s<provider>/<name>", Prefix)
+	}
+
+	return Reference{Provider: provider, Name: name, Key: key}, nil
+}
+
+// Resolver resolves secret references with the provider that they name.
+// A secret that is referenced multiple times is only fetched once.
+type Resolver struct {
+	providers map[string]Provider
+	resolved  map[Reference]string
+}
+
+func NewResolver(providers map[string]Provider) *Resolver {
+	return &Resolver{
+		providers: providers,
+		resolved:  make(map[Reference]string),
+	}
+}
+
+// Resolve returns `value` as-is unless it is a secret reference, in which case the secret is fetched.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+
+	ref, err := ParseReference(value)
+	if err != nil {
+		return "", err
+	}
+
+	if secret, isOk := r.resolved[ref]; isOk {
+		return secret, nil
+	}
+
+	provider, isOk := r.providers[ref.Provider]
+	if !isOk {
+		return "", fmt.Errorf("secrets provider %q is not configured", ref.Provider)
+	}
+
+	secret, err := provider.GetSecret(ctx, ref.Name, ref.Key)
+	if err != nil {
+		// Don't include the secret value, only the reference.
+		return "", fmt.Errorf("failed to resolve %s: %w", ref.String(), err)
+	}
+
+	r.resolved[ref] = secret
+	return secret, nil
+}
+
+// DefaultProviders returns the providers that have been configured through the environment.
+// Only Vault is supported today, it is enabled by setting `VAULT_ADDR` and `VAULT_TOKEN`.
+func DefaultProviders() map[string]Provider {
+	providers := make(map[string]Provider)
+	if vault, isOk := NewVaultProviderFromEnv(); isOk {
+		providers[Vault] = vault
+	}
+
+	return providers
+}
@@ -0,0 +1,87 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockProvider struct {
+	secrets map[string]string
+	calls   int
+}
+
+func (m *mockProvider) GetSecret(_ context.Context, name string, key string) (string, error) {
+	m.calls++
+	secret, isOk := m.secrets[name+"#"+key]
+	if !isOk {
+		return "", fmt.Errorf("secret not found")
+	}
+
+	return secret, nil
+}
+
+func TestParseReference(t *testing.T) {
+	{
+		// With a key
+		ref, err := ParseReference("secret://aws-sm/my-secret#password")
+		assert.NoError(t, err)
+		assert.Equal(t, Reference{Provider: AWSSecretsManager, Name: "my-secret", Key: "password"}, ref)
+		assert.Equal(t, "secret://aws-sm/my-secret#password", ref.String())
+	}
+	{
+		// Without a key and the name has slashes
+		ref, err := ParseReference("secret://vault/secret/transfer")
+		assert.NoError(t, err)
+		assert.Equal(t, Reference{Provider: Vault, Name: "secret/transfer"}, ref)
+		assert.Equal(t, "secret://vault/secret/transfer", ref.String())
+	}
+	{
+		// Invalid
+		_, err := ParseReference("vault/secret/transfer")
+		assert.ErrorContains(t, err, `secret reference must start with "secret://"`)
+
+		_, err = ParseReference("secret://vault")
+		assert.ErrorContains(t, err, "secret reference is missing the provider")
+
+		_, err = ParseReference("secret:///my-secret")
+		assert.ErrorContains(t, err, "secret reference is missing the provider")
+
+		_, err = ParseReference("secret://vault/#password")
+		assert.ErrorContains(t, err, "secret reference is missing the secret name")
+	}
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	provider := &mockProvider{secrets: map[string]string{"my-secret#password": "hunter2"}}
+	resolver := NewResolver(map[string]Provider{AWSSecretsManager: provider})
+	{
+		// Not a reference
+		value, err := resolver.Resolve(context.Background(), "plain-password")
+		assert.NoError(t, err)
+		assert.Equal(t, "plain-password", value)
+		assert.Zero(t, provider.calls)
+	}
+	{
+		// Secret is only fetched once
+		for i := 0; i < 2; i++ {
+			value, err := resolver.Resolve(context.Background(), "secret://aws-sm/my-secret#password")
+			assert.NoError(t, err)
+			assert.Equal(t, "hunter2", value)
+		}
+
+		assert.Equal(t, 1, provider.calls)
+	}
+	{
+		// Provider failed
+		_, err := resolver.Resolve(context.Background(), "secret://aws-sm/my-secret#username")
+		assert.ErrorContains(t, err, "failed to resolve secret://aws-sm/my-secret#username: secret not found")
+	}
+	{
+		// Provider is not configured
+		_, err := resolver.Resolve(context.Background(), "secret://gcp-sm/my-secret")
+		assert.ErrorContains(t, err, `secrets provider "gcp-sm" is not configured`)
+	}
+}
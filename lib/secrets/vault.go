@@ -0,0 +1,103 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultVaultTimeout = 10 * time.Second
+
+type vaultResponse struct {
+	Data struct {
+		Data map[string]any `json:"data"`
+	} `json:"data"`
+}
+
+// VaultProvider reads secrets from a HashiCorp Vault KV version 2 secrets engine.
+// The secret name is `<mount>/<path>`, so `secret://vault/secret/transfer#password` reads the `password` field from `transfer` in the `secret` mount.
+type VaultProvider struct {
+	address    string
+	token      string
+	namespace  string
+	httpClient *http.Client
+}
+
+func NewVaultProvider(address, token, namespace string) *VaultProvider {
+	return &VaultProvider{
+		address:    strings.TrimSuffix(address, "/"),
+		token:      token,
+		namespace:  namespace,
+		httpClient: &http.Client{Timeout: defaultVaultTimeout},
+	}
+}
+
+// NewVaultProviderFromEnv uses the same environment variables as the Vault CLI, it returns false if `VAULT_ADDR` is not set.
+func NewVaultProviderFromEnv() (*VaultProvider, bool) {
+	address := os.Getenv("VAULT_ADDR")
+	if address == "" {
+		return nil, false
+	}
+
+	return NewVaultProvider(address, os.Getenv("VAULT_TOKEN"), os.Getenv("VAULT_NAMESPACE")), true
+}
+
+func (v *VaultProvider) GetSecret(ctx context.Context, name string, key string) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("key is required for vault secrets, expected: %s%s/%s#<key>", Prefix, Vault, name)
+	}
+
+	mount, path, isOk := strings.Cut(name, "/")
+	if !isOk || mount == "" || path == "" {
+		return "", fmt.Errorf("vault secret name must be <mount>/<path>, name: %q", name)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/%s/data/%s", v.address, mount, path), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("X-Vault-Token", v.token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach vault: %w", err)
+	}
+
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to read vault secret, status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var vaultResp vaultResponse
+	if err = json.Unmarshal(body, &vaultResp); err != nil {
+		return "", fmt.Errorf("failed to unmarshal vault response: %w", err)
+	}
+
+	value, isOk := vaultResp.Data.Data[key]
+	if !isOk {
+		return "", fmt.Errorf("key %q does not exist in vault secret", key)
+	}
+
+	switch castedValue := value.(type) {
+	case string:
+		return castedValue, nil
+	case nil:
+		return "", nil
+	default:
+		return fmt.Sprint(castedValue), nil
+	}
+}
@@ -0,0 +1,82 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVaultProvider_GetSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/transfer":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"hunter2","port":5432},"metadata":{"version":3}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewVaultProvider(server.URL+"/", "token", "")
+	{
+		value, err := provider.GetSecret(context.Background(), "secret/transfer", "password")
+		assert.NoError(t, err)
+		assert.Equal(t, "hunter2", value)
+	}
+	{
+		// Non-string values
+		value, err := provider.GetSecret(context.Background(), "secret/transfer", "port")
+		assert.NoError(t, err)
+		assert.Equal(t, "5432", value)
+	}
+	{
+		// Key does not exist
+		_, err := provider.GetSecret(context.Background(), "secret/transfer", "username")
+		assert.ErrorContains(t, err, `key "username" does not exist in vault secret`)
+	}
+	{
+		// Key is required
+		_, err := provider.GetSecret(context.Background(), "secret/transfer", "")
+		assert.ErrorContains(t, err, "key is required for vault secrets")
+	}
+	{
+		// Missing the mount
+		_, err := provider.GetSecret(context.Background(), "transfer", "password")
+		assert.ErrorContains(t, err, `vault secret name must be <mount>/<path>, name: "transfer"`)
+	}
+	{
+		// Secret does not exist
+		_, err := provider.GetSecret(context.Background(), "secret/other", "password")
+		assert.ErrorContains(t, err, "failed to read vault secret, status code: 404")
+	}
+	{
+		// Bad token
+		_, err := NewVaultProvider(server.URL, "bad", "").GetSecret(context.Background(), "secret/transfer", "password")
+		assert.ErrorContains(t, err, "failed to read vault secret, status code: 403")
+	}
+}
+
+func TestNewVaultProviderFromEnv(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "")
+	_, isOk := NewVaultProviderFromEnv()
+	assert.False(t, isOk)
+	assert.Empty(t, DefaultProviders())
+
+	t.Setenv("VAULT_ADDR", "https://vault.example.com/")
+	t.Setenv("VAULT_TOKEN", "token")
+	provider, isOk := NewVaultProviderFromEnv()
+	assert.True(t, isOk)
+	assert.Equal(t, "https://vault.example.com", provider.address)
+	assert.Equal(t, "token", provider.token)
+	assert.Contains(t, DefaultProviders(), Vault)
+}
@@ -1,7 +1,9 @@
 package size
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
 )
 
 func GetApproxSize(value any) int {
@@ -64,3 +66,36 @@ func GetApproxSize(value any) int {
 
 	return len([]byte(fmt.Sprint(value)))
 }
+
+// GetSerializedSize returns the size of the row once it has been serialized, this includes the column names.
+// This is more accurate than [GetApproxSize] for rows with many small values, but is also more expensive to compute.
+func GetSerializedSize(row map[string]any) int {
+	var size int
+	for key, value := range row {
+		size += len(key) + getSerializedValueSize(value)
+	}
+
+	return size
+}
+
+func getSerializedValueSize(value any) int {
+	switch castedValue := value.(type) {
+	case nil:
+		return 0
+	case string:
+		return len(castedValue)
+	case []byte:
+		return len(castedValue)
+	case bool:
+		return len(strconv.FormatBool(castedValue))
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return len(fmt.Sprint(castedValue))
+	case map[string]any, []map[string]any, []string, []any:
+		bytes, err := json.Marshal(castedValue)
+		if err == nil {
+			return len(bytes)
+		}
+	}
+
+	return len(fmt.Sprint(value))
+}
@@ -39,3 +39,14 @@ func TestGetApproxSize(t *testing.T) {
 	assert.NotZero(t, size, "Size should not be zero")
 	assert.Greater(t, size, 1000, "Size should be reasonably large for the given data structure")
 }
+
+func TestGetSerializedSize(t *testing.T) {
+	assert.Equal(t, 0, GetSerializedSize(nil))
+	assert.Equal(t, len("name")+len("dusty"), GetSerializedSize(map[string]any{"name": "dusty"}))
+	assert.Equal(t, len("id")+len("123"), GetSerializedSize(map[string]any{"id": 123}))
+	assert.Equal(t, len("price")+len("12.5"), GetSerializedSize(map[string]any{"price": 12.5}))
+	assert.Equal(t, len("deleted")+len("false"), GetSerializedSize(map[string]any{"deleted": false}))
+	assert.Equal(t, len("notes"), GetSerializedSize(map[string]any{"notes": nil}))
+	assert.Equal(t, len("tags")+len(`["foo","bar"]`), GetSerializedSize(map[string]any{"tags": []string{"foo", "bar"}}))
+	assert.Equal(t, len("nested")+len(`{"foo":"bar"}`), GetSerializedSize(map[string]any{"nested": map[string]any{"foo": "bar"}}))
+}
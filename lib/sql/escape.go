@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
 )
 
@@ -17,16 +18,19 @@ type NameArgs struct {
 // symbolsToEscape are additional keywords that we need to escape
 var symbolsToEscape = []string{":"}
 
-func EscapeName(name string, uppercaseEscNames bool, args *NameArgs) string {
+func EscapeName(name string, casing config.IdentifierCasing, args *NameArgs) string {
 	if args == nil {
 		return name
 	}
 
+	name = TruncateIdentifier(args.DestKind, name)
 	var reservedKeywords []string
 	if args.DestKind == constants.Redshift {
 		reservedKeywords = constants.RedshiftReservedKeywords
 	} else if args.DestKind == constants.MSSQL {
 		reservedKeywords = constants.MSSQLReservedKeywords
+	} else if args.DestKind == constants.BigQuery {
+		reservedKeywords = constants.BigQueryReservedKeywords
 	} else {
 		reservedKeywords = constants.ReservedKeywords
 	}
@@ -51,18 +55,19 @@ func EscapeName(name string, uppercaseEscNames bool, args *NameArgs) string {
 	}
 
 	if args.Escape && needsEscaping {
-		if uppercaseEscNames {
-			name = strings.ToUpper(name)
-		}
-
-		if args.DestKind == constants.BigQuery {
-			// BigQuery needs backticks to escape.
-			return fmt.Sprintf("`%s`", name)
-		} else {
-			// Snowflake uses quotes.
-			return fmt.Sprintf(`"%s"`, name)
-		}
+		return QuoteIdentifier(casing.Apply(name), args.DestKind)
 	}
 
 	return name
 }
+
+// QuoteIdentifier will quote `name` without changing its case, so the destination will treat it as case-sensitive.
+func QuoteIdentifier(name string, destKind constants.DestinationKind) string {
+	if destKind == constants.BigQuery || destKind == constants.Databricks {
+		// BigQuery and Databricks need backticks to escape.
+		return fmt.Sprintf("`%s`", name)
+	}
+
+	// Snowflake uses quotes.
+	return fmt.Sprintf(`"%s"`, name)
+}
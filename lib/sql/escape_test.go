@@ -1,8 +1,10 @@
 package sql
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/stretchr/testify/assert"
 )
@@ -133,10 +135,52 @@ func TestEscapeName(t *testing.T) {
 	}
 
 	for _, testCase := range testCases {
-		actualName := EscapeName(testCase.nameToEscape, false, testCase.args)
+		actualName := EscapeName(testCase.nameToEscape, config.PreserveCasing, testCase.args)
 		assert.Equal(t, testCase.expectedName, actualName, testCase.name)
 
-		actualUpperName := EscapeName(testCase.nameToEscape, true, testCase.args)
+		actualUpperName := EscapeName(testCase.nameToEscape, config.UpperCasing, testCase.args)
 		assert.Equal(t, testCase.expectedNameWhenUpperCfg, actualUpperName, testCase.name)
 	}
 }
+
+func TestEscapeName_IdentifierCasing(t *testing.T) {
+	args := &NameArgs{Escape: true, DestKind: constants.Snowflake}
+	{
+		// Preserve
+		assert.Equal(t, `"start"`, EscapeName("start", config.PreserveCasing, args))
+		assert.Equal(t, `"Group:Id"`, EscapeName("Group:Id", config.PreserveCasing, args))
+		assert.Equal(t, "Foo", EscapeName("Foo", config.PreserveCasing, args))
+	}
+	{
+		// Upper
+		assert.Equal(t, `"START"`, EscapeName("start", config.UpperCasing, args))
+		assert.Equal(t, `"GROUP:ID"`, EscapeName("Group:Id", config.UpperCasing, args))
+		// Names that do not need escaping are left alone.
+		assert.Equal(t, "Foo", EscapeName("Foo", config.UpperCasing, args))
+	}
+	{
+		// Lower
+		assert.Equal(t, `"start"`, EscapeName("start", config.LowerCasing, args))
+		assert.Equal(t, `"group:id"`, EscapeName("Group:Id", config.LowerCasing, args))
+		assert.Equal(t, "Foo", EscapeName("Foo", config.LowerCasing, args))
+	}
+	{
+		// BigQuery
+		bqArgs := &NameArgs{Escape: true, DestKind: constants.BigQuery}
+		assert.Equal(t, "`START`", EscapeName("start", config.UpperCasing, bqArgs))
+		assert.Equal(t, "`group:id`", EscapeName("Group:Id", config.LowerCasing, bqArgs))
+		// default is only reserved in BigQuery.
+		assert.Equal(t, "`default`", EscapeName("default", config.PreserveCasing, bqArgs))
+		assert.Equal(t, "default", EscapeName("default", config.PreserveCasing, args))
+	}
+}
+
+func TestEscapeName_Truncate(t *testing.T) {
+	name := strings.Repeat("a", 130)
+	escapedName := EscapeName(name, config.PreserveCasing, &NameArgs{Escape: true, DestKind: constants.Redshift})
+	assert.Len(t, escapedName, constants.RedshiftMaxIdentifierLength)
+	assert.Equal(t, TruncateIdentifier(constants.Redshift, name), escapedName)
+
+	// BigQuery has a higher limit.
+	assert.Equal(t, name, EscapeName(name, config.PreserveCasing, &NameArgs{Escape: true, DestKind: constants.BigQuery}))
+}
@@ -0,0 +1,54 @@
+package sql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/stringutil"
+)
+
+// identifierHashLength is the number of hex characters from the name's hash that we'll add to a truncated identifier.
+const identifierHashLength = 8
+
+// TruncateIdentifier will truncate `name` if it's longer than the destination's max identifier length.
+// Truncated identifiers end with a hash of the full name, so names that share the same prefix will not collide and the same name will always be truncated the same way.
+func TruncateIdentifier(destKind constants.DestinationKind, name string) string {
+	return truncateIdentifier(name, constants.MaxIdentifierLength(destKind))
+}
+
+// TemporaryIdentifier returns `name` followed by an underscore and `suffix`, `name` will be truncated so that the whole identifier fits within the destination's max identifier length.
+// This is used for temporary tables so that the suffix (which contains the table's expiry) is never cut off.
+func TemporaryIdentifier(destKind constants.DestinationKind, name string, suffix string) string {
+	suffix = "_" + suffix
+	if maxLength := constants.MaxIdentifierLength(destKind); maxLength > 0 {
+		return truncateIdentifier(name, maxLength-len(suffix)) + suffix
+	}
+
+	return name + suffix
+}
+
+// HashedIdentifier returns `name` followed by a hash of `source`, so that the same source will always map to the same identifier.
+// `name` will be truncated so that the whole identifier fits within the destination's max identifier length.
+func HashedIdentifier(destKind constants.DestinationKind, name string, source string) string {
+	suffix := "_" + identifierHash(source)
+	if maxLength := constants.MaxIdentifierLength(destKind); maxLength > 0 {
+		return stringutil.TruncateUTF8(name, maxLength-len(suffix)) + suffix
+	}
+
+	return name + suffix
+}
+
+func identifierHash(name string) string {
+	hash := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(hash[:])[:identifierHashLength]
+}
+
+func truncateIdentifier(name string, maxLength int) string {
+	if maxLength <= 0 || len(name) <= maxLength {
+		return name
+	}
+
+	suffix := "_" + identifierHash(name)
+	return stringutil.TruncateUTF8(name, maxLength-len(suffix)) + suffix
+}
@@ -0,0 +1,59 @@
+package sql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+func TestTruncateIdentifier(t *testing.T) {
+	{
+		// Names within the limit are returned as-is.
+		assert.Equal(t, "user_id", TruncateIdentifier(constants.Redshift, "user_id"))
+		assert.Equal(t, strings.Repeat("a", 127), TruncateIdentifier(constants.Redshift, strings.Repeat("a", 127)))
+	}
+	{
+		// Destinations without a limit.
+		name := strings.Repeat("a", 1000)
+		assert.Equal(t, name, TruncateIdentifier(constants.S3, name))
+	}
+	for _, destKind := range []constants.DestinationKind{constants.BigQuery, constants.Snowflake, constants.Redshift, constants.MSSQL, constants.Databricks} {
+		maxLength := constants.MaxIdentifierLength(destKind)
+		prefix := strings.Repeat("a", maxLength)
+
+		first := TruncateIdentifier(destKind, prefix+"_first")
+		second := TruncateIdentifier(destKind, prefix+"_second")
+		assert.Len(t, first, maxLength, destKind)
+		assert.Len(t, second, maxLength, destKind)
+		assert.True(t, strings.HasPrefix(first, prefix[:maxLength-9]+"_"), destKind)
+		// Names that share the same prefix should not collide.
+		assert.NotEqual(t, first, second, destKind)
+		// The same name should always be truncated the same way.
+		assert.Equal(t, first, TruncateIdentifier(destKind, prefix+"_first"), destKind)
+	}
+	{
+		// Multibyte characters are not split.
+		name := TruncateIdentifier(constants.Redshift, strings.Repeat("é", 100))
+		assert.Equal(t, strings.Repeat("é", 59)+"_", name[:len(name)-8])
+		assert.LessOrEqual(t, len(name), constants.RedshiftMaxIdentifierLength)
+	}
+}
+
+func TestTemporaryIdentifier(t *testing.T) {
+	const suffix = "__artie_abcde_1700000000"
+	{
+		// Names within the limit
+		assert.Equal(t, "orders___artie_abcde_1700000000", TemporaryIdentifier(constants.Redshift, "orders", suffix))
+		name := strings.Repeat("a", 1000)
+		assert.Equal(t, name+"_"+suffix, TemporaryIdentifier(constants.S3, name, suffix))
+	}
+	{
+		// The name is truncated so that the suffix is kept.
+		name := TemporaryIdentifier(constants.Redshift, strings.Repeat("a", 127), suffix)
+		assert.Len(t, name, constants.RedshiftMaxIdentifierLength)
+		assert.True(t, strings.HasSuffix(name, "_"+suffix), name)
+	}
+}
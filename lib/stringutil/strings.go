@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/rand"
 	"strings"
+	"unicode/utf8"
 )
 
 func CapitalizeFirstLetter(s string) string {
@@ -53,6 +54,20 @@ func EscapeSpaces(col string) (escaped bool, newString string) {
 	return strings.Contains(col, subStr), strings.ReplaceAll(col, subStr, "__")
 }
 
+// TruncateUTF8 will return `s` cut down to at most `maxBytes` bytes, without splitting a multibyte character.
+func TruncateUTF8(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+
+	idx := max(maxBytes, 0)
+	for idx > 0 && !utf8.RuneStart(s[idx]) {
+		idx--
+	}
+
+	return s[:idx]
+}
+
 func stringWithCharset(length int, charset string) string {
 	b := make([]byte, length)
 	for i := range b {
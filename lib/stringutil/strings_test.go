@@ -68,6 +68,27 @@ func TestOverride(t *testing.T) {
 	}
 }
 
+func TestTruncateUTF8(t *testing.T) {
+	{
+		// Under the limit
+		assert.Equal(t, "hello", TruncateUTF8("hello", 5))
+		assert.Equal(t, "hello", TruncateUTF8("hello", 10))
+		assert.Equal(t, "", TruncateUTF8("", 0))
+	}
+	{
+		// Over the limit
+		assert.Equal(t, "hel", TruncateUTF8("hello", 3))
+		assert.Equal(t, "", TruncateUTF8("hello", 0))
+	}
+	{
+		// Multibyte characters should not be split, "é" is 2 bytes and "日" is 3 bytes.
+		assert.Equal(t, "caf", TruncateUTF8("café", 4))
+		assert.Equal(t, "café", TruncateUTF8("café", 5))
+		assert.Equal(t, "日", TruncateUTF8("日本語", 5))
+		assert.Equal(t, "日本", TruncateUTF8("日本語", 6))
+	}
+}
+
 func TestWrap(t *testing.T) {
 	type _testCase struct {
 		name           string
@@ -1,13 +1,15 @@
 package typing
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/artie-labs/transfer/lib/typing/ext"
 )
 
-const StreamingTimeFormat = "15:04:05"
+// StreamingTimeFormat - BigQuery supports up to microsecond precision for TIME.
+const StreamingTimeFormat = "15:04:05.999999"
 
 func bigQueryTypeToKind(rawBqType string) KindDetails {
 	bqType := rawBqType
@@ -29,7 +31,7 @@ func bigQueryTypeToKind(rawBqType string) KindDetails {
 		idxStop = idx
 	}
 
-	// Geography, geometry date, time, varbinary, binary are currently not supported.
+	// Geometry is currently not supported.
 	switch strings.TrimSpace(bqType[:idxStop]) {
 	case "numeric":
 		if rawBqType == "numeric" || rawBqType == "bignumeric" {
@@ -57,6 +59,11 @@ func bigQueryTypeToKind(rawBqType string) KindDetails {
 		// Record is a legacy BQ object that maps to a JSON.
 		return Struct
 	case "array":
+		// Arrays will look like: array<int64>
+		if start, end := strings.Index(rawBqType, "<"), strings.LastIndex(rawBqType, ">"); start > 0 && end > start {
+			return NewArrayKindDetails(bigQueryTypeToKind(strings.TrimSpace(rawBqType[start+1 : end])))
+		}
+
 		return Array
 	case "datetime", "timestamp":
 		return NewKindDetailsFromTemplate(ETime, ext.DateTimeKindType)
@@ -64,6 +71,10 @@ func bigQueryTypeToKind(rawBqType string) KindDetails {
 		return NewKindDetailsFromTemplate(ETime, ext.TimeKindType)
 	case "date":
 		return NewKindDetailsFromTemplate(ETime, ext.DateKindType)
+	case "geography":
+		return Geography
+	case "bytes":
+		return Bytes
 	default:
 		return Invalid
 	}
@@ -75,6 +86,10 @@ func kindToBigQuery(kindDetails KindDetails) string {
 	case Float.Kind:
 		return "float64"
 	case Array.Kind:
+		if elementKind := kindDetails.OptionalArrayElementKind; elementKind != nil && isBigQueryArrayElement(*elementKind) {
+			return fmt.Sprintf("array<%s>", kindToBigQuery(*elementKind))
+		}
+
 		// This is because BigQuery requires typing within the element of an array
 		// IMO, a string type is the least controversial data type (others being bool, number, struct).
 		// With String, we can always type cast the child elements.
@@ -101,6 +116,16 @@ func kindToBigQuery(kindDetails KindDetails) string {
 	return kindDetails.Kind
 }
 
+// isBigQueryArrayElement returns true if we can create a natively typed array for elements of this kind.
+func isBigQueryArrayElement(kd KindDetails) bool {
+	switch kd.Kind {
+	case Integer.Kind, Float.Kind, Boolean.Kind, String.Kind, Struct.Kind:
+		return true
+	default:
+		return false
+	}
+}
+
 const bqLayout = "2006-01-02 15:04:05 MST"
 
 func ExpiresDate(time time.Time) string {
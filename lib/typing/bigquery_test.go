@@ -44,6 +44,12 @@ func TestBigQueryTypeToKind(t *testing.T) {
 		"timestamp": NewKindDetailsFromTemplate(ETime, ext.DateTimeKindType),
 		"time":      NewKindDetailsFromTemplate(ETime, ext.TimeKindType),
 		"date":      NewKindDetailsFromTemplate(ETime, ext.DateKindType),
+		// Geography
+		"geography": Geography,
+		"GEOGRAPHY": Geography,
+		// Bytes
+		"bytes":      Bytes,
+		"BYTES(100)": Bytes,
 		//Invalid
 		"foo":    Invalid,
 		"foofoo": Invalid,
@@ -57,6 +63,32 @@ func TestBigQueryTypeToKind(t *testing.T) {
 	}
 }
 
+func TestBigQueryArrayTypes(t *testing.T) {
+	{
+		// Element types that BigQuery supports
+		assert.Equal(t, "array<int>", kindToBigQuery(NewArrayKindDetails(Integer)))
+		assert.Equal(t, "array<float64>", kindToBigQuery(NewArrayKindDetails(Float)))
+		assert.Equal(t, "array<bool>", kindToBigQuery(NewArrayKindDetails(Boolean)))
+		assert.Equal(t, "array<string>", kindToBigQuery(NewArrayKindDetails(String)))
+		assert.Equal(t, "array<json>", kindToBigQuery(NewArrayKindDetails(Struct)))
+	}
+	{
+		// Everything else should fall back to an array of strings
+		assert.Equal(t, "array<string>", kindToBigQuery(Array))
+		assert.Equal(t, "array<string>", kindToBigQuery(NewArrayKindDetails(NewKindDetailsFromTemplate(ETime, ext.DateKindType))))
+	}
+	{
+		// Parsing the element type
+		kd, err := DwhTypeToKind(constants.BigQuery, "ARRAY<INT64>", "")
+		assert.NoError(t, err)
+		assert.Equal(t, NewArrayKindDetails(Integer), kd)
+
+		kd, err = DwhTypeToKind(constants.BigQuery, "array", "")
+		assert.NoError(t, err)
+		assert.Equal(t, Array, kd)
+	}
+}
+
 func TestBigQueryTypeNoDataLoss(t *testing.T) {
 	kindDetails := []KindDetails{
 		NewKindDetailsFromTemplate(ETime, ext.DateTimeKindType),
@@ -65,6 +97,12 @@ func TestBigQueryTypeNoDataLoss(t *testing.T) {
 		String,
 		Boolean,
 		Struct,
+		NewArrayKindDetails(Integer),
+		NewArrayKindDetails(Float),
+		NewArrayKindDetails(Boolean),
+		NewArrayKindDetails(String),
+		NewArrayKindDetails(Struct),
+		Geography,
 	}
 
 	for _, kindDetail := range kindDetails {
@@ -2,9 +2,11 @@ package columns
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/sql"
 	"github.com/artie-labs/transfer/lib/stringutil"
@@ -27,6 +29,12 @@ type Column struct {
 	ToastColumn  bool
 	defaultValue any
 	backfilled   bool
+	// comment is the column's description, for in-memory columns this comes from the source and for destination columns this is what has been persisted.
+	comment string
+	// mergeKeys is set for JSON columns where these keys should be merged into the existing object when the row is updated, instead of replacing the object.
+	mergeKeys []string
+	// destinationName is the existing column's name in the destination, this is only set if it differs from `name` by case.
+	destinationName string
 }
 
 func (c *Column) PrimaryKey() bool {
@@ -56,6 +64,35 @@ func (c *Column) Backfilled() bool {
 	return c.backfilled
 }
 
+func (c *Column) SetComment(comment string) {
+	c.comment = comment
+}
+
+func (c *Column) Comment() string {
+	return c.comment
+}
+
+func (c *Column) SetMergeKeys(keys []string) {
+	c.mergeKeys = keys
+}
+
+func (c *Column) MergeKeys() []string {
+	return c.mergeKeys
+}
+
+// SetDestinationName should be called with the existing column's name in the destination, so that we can refer to columns whose name differs from ours by case.
+func (c *Column) SetDestinationName(name string) {
+	if name == c.name {
+		name = ""
+	}
+
+	c.destinationName = name
+}
+
+func (c *Column) DestinationName() string {
+	return c.destinationName
+}
+
 func (c *Column) SetDefaultValue(value any) {
 	c.defaultValue = value
 }
@@ -86,18 +123,67 @@ func (c *Column) RawName() string {
 // Name will give you c.name
 // However, if you pass in escape, we will escape if the column name is part of the reserved words from destinations.
 // If so, it'll change from `start` => `"start"` as suggested by Snowflake.
-func (c *Column) Name(uppercaseEscNames bool, args *sql.NameArgs) string {
-	return sql.EscapeName(c.name, uppercaseEscNames, args)
+func (c *Column) Name(casing config.IdentifierCasing, args *sql.NameArgs) string {
+	if c.destinationName != "" && args != nil && args.Escape {
+		// The existing column's name differs from ours by case, so we need to quote it as is.
+		return sql.QuoteIdentifier(c.destinationName, args.DestKind)
+	}
+
+	return sql.EscapeName(c.name, casing, args)
 }
 
 type Columns struct {
 	columns []Column
+	// caseInsensitive will match column names regardless of their case, e.g. `Email` will match an existing `email` column.
+	caseInsensitive bool
 	sync.RWMutex
 }
 
-func (c *Columns) EscapeName(uppercaseEscNames bool, args *sql.NameArgs) {
+// NewColumns returns columns that contain `cols`, in order.
+func NewColumns(cols ...Column) *Columns {
+	var c Columns
+	for _, col := range cols {
+		c.AddColumn(col)
+	}
+
+	return &c
+}
+
+// SetCaseInsensitive controls whether column names are matched case-insensitively, this should be called before any columns are added.
+func (c *Columns) SetCaseInsensitive(caseInsensitive bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.caseInsensitive = caseInsensitive
+}
+
+// Clone returns a copy of the columns, changes made to the copy will not be reflected in `c`.
+func (c *Columns) Clone() *Columns {
+	if c == nil {
+		return nil
+	}
+
+	c.RLock()
+	defer c.RUnlock()
+
+	return &Columns{
+		columns:         slices.Clone(c.columns),
+		caseInsensitive: c.caseInsensitive,
+	}
+}
+
+// matches returns true if `name` refers to `column`, the caller is expected to hold the lock.
+func (c *Columns) matches(column Column, name string) bool {
+	if c.caseInsensitive {
+		return strings.EqualFold(column.name, name)
+	}
+
+	return column.name == name
+}
+
+func (c *Columns) EscapeName(casing config.IdentifierCasing, args *sql.NameArgs) {
 	for idx := range c.columns {
-		c.columns[idx].name = c.columns[idx].Name(uppercaseEscNames, args)
+		c.columns[idx].name = c.columns[idx].Name(casing, args)
 	}
 }
 
@@ -105,6 +191,7 @@ type UpsertColumnArg struct {
 	ToastCol   *bool
 	PrimaryKey *bool
 	Backfilled *bool
+	Comment    *string
 }
 
 // UpsertColumn - just a wrapper around UpdateColumn and AddColumn
@@ -127,6 +214,10 @@ func (c *Columns) UpsertColumn(colName string, arg UpsertColumnArg) {
 			col.backfilled = *arg.Backfilled
 		}
 
+		if arg.Comment != nil {
+			col.comment = *arg.Comment
+		}
+
 		c.UpdateColumn(col)
 		return
 	}
@@ -148,6 +239,10 @@ func (c *Columns) UpsertColumn(colName string, arg UpsertColumnArg) {
 		col.backfilled = *arg.Backfilled
 	}
 
+	if arg.Comment != nil {
+		col.comment = *arg.Comment
+	}
+
 	c.AddColumn(col)
 }
 
@@ -172,7 +267,7 @@ func (c *Columns) GetColumn(name string) (Column, bool) {
 	defer c.RUnlock()
 
 	for _, column := range c.columns {
-		if column.name == name {
+		if c.matches(column, name) {
 			return column, true
 		}
 	}
@@ -182,7 +277,7 @@ func (c *Columns) GetColumn(name string) (Column, bool) {
 
 // GetColumnsToUpdate will filter all the `Invalid` columns so that we do not update it.
 // It also has an option to escape the returned columns or not. This is used mostly for the SQL MERGE queries.
-func (c *Columns) GetColumnsToUpdate(uppercaseEscNames bool, args *sql.NameArgs) []string {
+func (c *Columns) GetColumnsToUpdate(casing config.IdentifierCasing, args *sql.NameArgs) []string {
 	if c == nil {
 		return []string{}
 	}
@@ -191,12 +286,22 @@ func (c *Columns) GetColumnsToUpdate(uppercaseEscNames bool, args *sql.NameArgs)
 	defer c.RUnlock()
 
 	var cols []string
+	seen := make(map[string]bool)
 	for _, col := range c.columns {
 		if col.KindDetails == typing.Invalid {
 			continue
 		}
 
-		cols = append(cols, col.Name(uppercaseEscNames, args))
+		if c.caseInsensitive {
+			// Columns that only differ by case refer to the same destination column, so we should only update it once.
+			if seen[strings.ToLower(col.name)] {
+				continue
+			}
+
+			seen[strings.ToLower(col.name)] = true
+		}
+
+		cols = append(cols, col.Name(casing, args))
 	}
 
 	return cols
@@ -233,15 +338,37 @@ func (c *Columns) DeleteColumn(name string) {
 	defer c.Unlock()
 
 	for idx, column := range c.columns {
-		if column.name == name {
+		if c.matches(column, name) {
 			c.columns = append(c.columns[:idx], c.columns[idx+1:]...)
 			return
 		}
 	}
 }
 
+// RenameColumn will rename `oldName` to `newName` in place, this is a no-op if `oldName` does not exist.
+// It will return an error if `newName` already exists.
+func (c *Columns) RenameColumn(oldName string, newName string) error {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, column := range c.columns {
+		if c.matches(column, newName) {
+			return fmt.Errorf("cannot rename column %q to %q, column already exists", oldName, newName)
+		}
+	}
+
+	for idx, column := range c.columns {
+		if c.matches(column, oldName) {
+			c.columns[idx].name = newName
+			return nil
+		}
+	}
+
+	return nil
+}
+
 // UpdateQuery will parse the columns and then returns a list of strings like: cc.first_name=c.first_name,cc.last_name=c.last_name,cc.email=c.email
-func (c *Columns) UpdateQuery(destKind constants.DestinationKind, uppercaseEscNames bool, skipDeleteCol bool) string {
+func (c *Columns) UpdateQuery(destKind constants.DestinationKind, casing config.IdentifierCasing, skipDeleteCol bool) string {
 	var cols []string
 	for _, column := range c.GetColumns() {
 		if column.ShouldSkip() {
@@ -253,9 +380,16 @@ func (c *Columns) UpdateQuery(destKind constants.DestinationKind, uppercaseEscNa
 			continue
 		}
 
-		colName := column.Name(uppercaseEscNames, &sql.NameArgs{Escape: true, DestKind: destKind})
+		colName := column.Name(casing, &sql.NameArgs{Escape: true, DestKind: destKind})
+		if len(column.mergeKeys) > 0 && column.KindDetails.Kind == typing.Struct.Kind {
+			if expr, isOk := processMergeStructCol(colName, column.mergeKeys, destKind); isOk {
+				cols = append(cols, expr)
+				continue
+			}
+		}
+
 		if column.ToastColumn {
-			if column.KindDetails == typing.Struct {
+			if column.KindDetails.Kind == typing.Struct.Kind {
 				cols = append(cols, processToastStructCol(colName, destKind))
 			} else {
 				cols = append(cols, processToastCol(colName, destKind))
@@ -270,6 +404,40 @@ func (c *Columns) UpdateQuery(destKind constants.DestinationKind, uppercaseEscNa
 	return strings.Join(cols, ",")
 }
 
+// processMergeStructCol will merge `keys` from the incoming object into the existing object, keys that are missing from the incoming object (e.g. TOAST values) will keep their existing value.
+// This returns false if the destination does not support updating individual keys, in which case the object will be replaced as a whole.
+func processMergeStructCol(colName string, keys []string, destKind constants.DestinationKind) (string, bool) {
+	switch destKind {
+	case constants.Snowflake:
+		expr := fmt.Sprintf("COALESCE(c.%s, OBJECT_CONSTRUCT())", colName)
+		for _, key := range keys {
+			expr = fmt.Sprintf(`OBJECT_INSERT(%s, '%s', COALESCE(cc.%s:"%s", c.%s:"%s"), true)`, expr, key, colName, key, colName, key)
+		}
+
+		return fmt.Sprintf("%s=%s", colName, expr), true
+	case constants.BigQuery:
+		var pairs []string
+		for _, key := range keys {
+			path := fmt.Sprintf(`'$."%s"'`, key)
+			pairs = append(pairs, fmt.Sprintf("%s, COALESCE(JSON_QUERY(cc.%s, %s), JSON_QUERY(c.%s, %s))", path, colName, path, colName, path))
+		}
+
+		return fmt.Sprintf("%s=JSON_SET(COALESCE(c.%s, JSON '{}'), %s)", colName, colName, strings.Join(pairs, ", ")), true
+	case constants.Redshift:
+		var pairs []string
+		for _, key := range keys {
+			pairs = append(pairs, fmt.Sprintf(`'"%s"', COALESCE(cc.%s."%s", c.%s."%s")`, key, colName, key, colName, key))
+		}
+
+		return fmt.Sprintf("%s=OBJECT_TRANSFORM(COALESCE(c.%s, JSON_PARSE('{}')) SET %s)", colName, colName, strings.Join(pairs, ", ")), true
+	case constants.DuckDB:
+		// DuckDB can merge the whole object, so we do not need to go key by key.
+		return fmt.Sprintf("%s=json_merge_patch(COALESCE(c.%s, '{}'), COALESCE(cc.%s, '{}'))", colName, colName, colName), true
+	default:
+		return "", false
+	}
+}
+
 func processToastStructCol(colName string, destKind constants.DestinationKind) string {
 	switch destKind {
 	case constants.BigQuery:
@@ -283,6 +451,10 @@ func processToastStructCol(colName string, destKind constants.DestinationKind) s
 		// Microsoft SQL Server doesn't allow boolean expressions to be in the COALESCE statement.
 		return fmt.Sprintf("%s= CASE WHEN COALESCE(cc.%s, {}) != {'key': '%s'} THEN cc.%s ELSE c.%s END",
 			colName, colName, constants.ToastUnavailableValuePlaceholder, colName, colName)
+	case constants.Databricks, constants.DuckDB:
+		// Databricks and DuckDB store JSON as a string, so we'll compare against the placeholder's JSON string.
+		return fmt.Sprintf(`%s= CASE WHEN COALESCE(cc.%s != '{"key":"%s"}', true) THEN cc.%s ELSE c.%s END`,
+			colName, colName, constants.ToastUnavailableValuePlaceholder, colName, colName)
 	default:
 		// TODO: Change this to Snowflake and error out if the destKind isn't supported so we're explicit.
 		return fmt.Sprintf("%s= CASE WHEN COALESCE(cc.%s != {'key': '%s'}, true) THEN cc.%s ELSE c.%s END",
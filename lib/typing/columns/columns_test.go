@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/sql"
 
 	"github.com/artie-labs/transfer/lib/config/constants"
@@ -170,15 +171,15 @@ func TestColumn_Name(t *testing.T) {
 		}
 
 		assert.Equal(t, testCase.expectedName, col.RawName(), testCase.colName)
-		assert.Equal(t, testCase.expectedName, col.Name(false, &sql.NameArgs{
+		assert.Equal(t, testCase.expectedName, col.Name(config.PreserveCasing, &sql.NameArgs{
 			Escape: false,
 		}), testCase.colName)
 
-		assert.Equal(t, testCase.expectedNameEsc, col.Name(false, &sql.NameArgs{
+		assert.Equal(t, testCase.expectedNameEsc, col.Name(config.PreserveCasing, &sql.NameArgs{
 			Escape:   true,
 			DestKind: constants.Snowflake,
 		}), testCase.colName)
-		assert.Equal(t, testCase.expectedNameEscBq, col.Name(false, &sql.NameArgs{
+		assert.Equal(t, testCase.expectedNameEscBq, col.Name(config.PreserveCasing, &sql.NameArgs{
 			Escape:   true,
 			DestKind: constants.BigQuery,
 		}), testCase.colName)
@@ -241,17 +242,17 @@ func TestColumns_GetColumnsToUpdate(t *testing.T) {
 			columns: testCase.cols,
 		}
 
-		assert.Equal(t, testCase.expectedCols, cols.GetColumnsToUpdate(false, nil), testCase.name)
-		assert.Equal(t, testCase.expectedCols, cols.GetColumnsToUpdate(false, &sql.NameArgs{
+		assert.Equal(t, testCase.expectedCols, cols.GetColumnsToUpdate(config.PreserveCasing, nil), testCase.name)
+		assert.Equal(t, testCase.expectedCols, cols.GetColumnsToUpdate(config.PreserveCasing, &sql.NameArgs{
 			Escape: false,
 		}), testCase.name)
 
-		assert.Equal(t, testCase.expectedColsEsc, cols.GetColumnsToUpdate(false, &sql.NameArgs{
+		assert.Equal(t, testCase.expectedColsEsc, cols.GetColumnsToUpdate(config.PreserveCasing, &sql.NameArgs{
 			Escape:   true,
 			DestKind: constants.Snowflake,
 		}), testCase.name)
 
-		assert.Equal(t, testCase.expectedColsEscBq, cols.GetColumnsToUpdate(false, &sql.NameArgs{
+		assert.Equal(t, testCase.expectedColsEscBq, cols.GetColumnsToUpdate(config.PreserveCasing, &sql.NameArgs{
 			Escape:   true,
 			DestKind: constants.BigQuery,
 		}), testCase.name)
@@ -317,6 +318,66 @@ func TestColumns_Add_Duplicate(t *testing.T) {
 	assert.Equal(t, len(cols.GetColumns()), 1, "AddColumn() de-duplicates")
 }
 
+func TestColumns_CaseInsensitive(t *testing.T) {
+	{
+		// Case-sensitive by default
+		var cols Columns
+		cols.AddColumn(Column{name: "email", KindDetails: typing.String})
+		cols.AddColumn(Column{name: "Email", KindDetails: typing.String})
+		assert.Len(t, cols.GetColumns(), 2)
+
+		_, isOk := cols.GetColumn("EMAIL")
+		assert.False(t, isOk)
+	}
+	{
+		var cols Columns
+		cols.SetCaseInsensitive(true)
+		cols.AddColumn(Column{name: "email", KindDetails: typing.String})
+		cols.AddColumn(Column{name: "Email", KindDetails: typing.Integer})
+		assert.Len(t, cols.GetColumns(), 1, "AddColumn() de-duplicates regardless of case")
+
+		col, isOk := cols.GetColumn("EMAIL")
+		assert.True(t, isOk)
+		assert.Equal(t, "email", col.RawName())
+		assert.Equal(t, typing.String, col.KindDetails)
+
+		cols.UpsertColumn("Email", UpsertColumnArg{Backfilled: ptr.ToBool(true)})
+		col, isOk = cols.GetColumn("email")
+		assert.True(t, isOk)
+		assert.True(t, col.Backfilled())
+
+		cols.DeleteColumn("EMAIL")
+		assert.Empty(t, cols.GetColumns())
+	}
+	{
+		// Columns that only differ by case are only updated once.
+		cols := &Columns{
+			columns: []Column{
+				{name: "email", KindDetails: typing.String},
+				{name: "Email", KindDetails: typing.String},
+				{name: "name", KindDetails: typing.String},
+			},
+		}
+
+		assert.Equal(t, []string{"email", "Email", "name"}, cols.GetColumnsToUpdate(config.PreserveCasing, nil))
+		cols.SetCaseInsensitive(true)
+		assert.Equal(t, []string{"email", "name"}, cols.GetColumnsToUpdate(config.PreserveCasing, nil))
+	}
+	{
+		// The existing column's name differs by case, so it should be quoted as is.
+		col := NewColumn("email", typing.String)
+		col.SetDestinationName("Email")
+		assert.Equal(t, "email", col.RawName())
+		assert.Equal(t, `"Email"`, col.Name(config.UpperCasing, &sql.NameArgs{Escape: true, DestKind: constants.Snowflake}))
+		assert.Equal(t, "`Email`", col.Name(config.PreserveCasing, &sql.NameArgs{Escape: true, DestKind: constants.BigQuery}))
+
+		// Same name, so there's nothing to do.
+		col.SetDestinationName("email")
+		assert.Empty(t, col.DestinationName())
+		assert.Equal(t, "email", col.Name(config.UpperCasing, &sql.NameArgs{Escape: true, DestKind: constants.Snowflake}))
+	}
+}
+
 func TestColumns_Mutation(t *testing.T) {
 	var cols Columns
 	colsToAdd := []Column{{name: "foo", KindDetails: typing.String, defaultValue: "bar"}, {name: "bar", KindDetails: typing.Struct}}
@@ -363,10 +424,32 @@ func TestColumns_Mutation(t *testing.T) {
 	assert.Equal(t, len(cols.GetColumns()), 0)
 }
 
+func TestColumns_RenameColumn(t *testing.T) {
+	var cols Columns
+	cols.AddColumn(Column{name: "id", KindDetails: typing.Integer, primaryKey: true})
+	cols.AddColumn(Column{name: "foo", KindDetails: typing.String})
+
+	// Renaming a column that does not exist is a no-op.
+	assert.NoError(t, cols.RenameColumn("bar", "baz"))
+	assert.Len(t, cols.GetColumns(), 2)
+
+	// Renaming into an existing column should fail.
+	assert.ErrorContains(t, cols.RenameColumn("foo", "id"), `cannot rename column "foo" to "id", column already exists`)
+
+	assert.NoError(t, cols.RenameColumn("id", "user_id"))
+	_, isOk := cols.GetColumn("id")
+	assert.False(t, isOk)
+
+	col, isOk := cols.GetColumn("user_id")
+	assert.True(t, isOk)
+	assert.True(t, col.PrimaryKey())
+	assert.Equal(t, typing.Integer, col.KindDetails)
+}
+
 func TestColumnsUpdateQuery(t *testing.T) {
 	type testCase struct {
 		name           string
-		columns        Columns
+		columns        *Columns
 		expectedString string
 		destKind       constants.DestinationKind
 		skipDeleteCol  bool
@@ -446,35 +529,69 @@ func TestColumnsUpdateQuery(t *testing.T) {
 		KindDetails: typing.Boolean,
 	})
 
+	overflowCol := Column{name: constants.OverflowColumnMarker, KindDetails: typing.Struct}
+	overflowCol.SetMergeKeys([]string{"city", "zip"})
+	overflowCols := NewColumns(Column{name: "id", KindDetails: typing.Integer}, overflowCol)
+
 	key := `{"key":"__debezium_unavailable_value"}`
 	testCases := []testCase{
+		{
+			name:           "merged struct (snowflake)",
+			columns:        overflowCols,
+			destKind:       constants.Snowflake,
+			expectedString: `id=cc.id,__artie_overflow=OBJECT_INSERT(OBJECT_INSERT(COALESCE(c.__artie_overflow, OBJECT_CONSTRUCT()), 'city', COALESCE(cc.__artie_overflow:"city", c.__artie_overflow:"city"), true), 'zip', COALESCE(cc.__artie_overflow:"zip", c.__artie_overflow:"zip"), true)`,
+		},
+		{
+			name:           "merged struct (bigquery)",
+			columns:        overflowCols,
+			destKind:       constants.BigQuery,
+			expectedString: `id=cc.id,__artie_overflow=JSON_SET(COALESCE(c.__artie_overflow, JSON '{}'), '$."city"', COALESCE(JSON_QUERY(cc.__artie_overflow, '$."city"'), JSON_QUERY(c.__artie_overflow, '$."city"')), '$."zip"', COALESCE(JSON_QUERY(cc.__artie_overflow, '$."zip"'), JSON_QUERY(c.__artie_overflow, '$."zip"')))`,
+		},
+		{
+			name:           "merged struct (redshift)",
+			columns:        overflowCols,
+			destKind:       constants.Redshift,
+			expectedString: `id=cc.id,__artie_overflow=OBJECT_TRANSFORM(COALESCE(c.__artie_overflow, JSON_PARSE('{}')) SET '"city"', COALESCE(cc.__artie_overflow."city", c.__artie_overflow."city"), '"zip"', COALESCE(cc.__artie_overflow."zip", c.__artie_overflow."zip"))`,
+		},
+		{
+			name:           "merged struct (duckdb)",
+			columns:        overflowCols,
+			destKind:       constants.DuckDB,
+			expectedString: `id=cc.id,__artie_overflow=json_merge_patch(COALESCE(c.__artie_overflow, '{}'), COALESCE(cc.__artie_overflow, '{}'))`,
+		},
+		{
+			name:           "merged struct (mssql), replaced as a whole",
+			columns:        overflowCols,
+			destKind:       constants.MSSQL,
+			expectedString: `id=cc.id,__artie_overflow=cc.__artie_overflow`,
+		},
 		{
 			name:           "happy path",
-			columns:        happyPathCols,
+			columns:        &happyPathCols,
 			destKind:       constants.Redshift,
 			expectedString: "foo=cc.foo,bar=cc.bar",
 		},
 		{
 			name:           "string and toast",
-			columns:        stringAndToastCols,
+			columns:        &stringAndToastCols,
 			destKind:       constants.Snowflake,
 			expectedString: "foo= CASE WHEN COALESCE(cc.foo != '__debezium_unavailable_value', true) THEN cc.foo ELSE c.foo END,bar=cc.bar",
 		},
 		{
 			name:           "struct, string and toast string",
-			columns:        lastCaseColTypes,
+			columns:        &lastCaseColTypes,
 			destKind:       constants.Redshift,
 			expectedString: `a1= CASE WHEN COALESCE(cc.a1 != JSON_PARSE('{"key":"__debezium_unavailable_value"}'), true) THEN cc.a1 ELSE c.a1 END,b2= CASE WHEN COALESCE(cc.b2 != '__debezium_unavailable_value', true) THEN cc.b2 ELSE c.b2 END,c3=cc.c3`,
 		},
 		{
 			name:           "struct, string and toast string (bigquery)",
-			columns:        lastCaseColTypes,
+			columns:        &lastCaseColTypes,
 			destKind:       constants.BigQuery,
 			expectedString: `a1= CASE WHEN COALESCE(TO_JSON_STRING(cc.a1) != '{"key":"__debezium_unavailable_value"}', true) THEN cc.a1 ELSE c.a1 END,b2= CASE WHEN COALESCE(cc.b2 != '__debezium_unavailable_value', true) THEN cc.b2 ELSE c.b2 END,c3=cc.c3`,
 		},
 		{
 			name:     "struct, string and toast string (bigquery) w/ reserved keywords",
-			columns:  lastCaseEscapeTypes,
+			columns:  &lastCaseEscapeTypes,
 			destKind: constants.BigQuery,
 			expectedString: fmt.Sprintf(`a1= CASE WHEN COALESCE(TO_JSON_STRING(cc.a1) != '%s', true) THEN cc.a1 ELSE c.a1 END,b2= CASE WHEN COALESCE(cc.b2 != '__debezium_unavailable_value', true) THEN cc.b2 ELSE c.b2 END,c3=cc.c3,%s,%s`,
 				key, fmt.Sprintf("`start`= CASE WHEN COALESCE(TO_JSON_STRING(cc.`start`) != '%s', true) THEN cc.`start` ELSE c.`start` END", key), "`select`=cc.`select`"),
@@ -482,7 +599,7 @@ func TestColumnsUpdateQuery(t *testing.T) {
 		},
 		{
 			name:     "struct, string and toast string (bigquery) w/ reserved keywords",
-			columns:  lastCaseEscapeTypes,
+			columns:  &lastCaseEscapeTypes,
 			destKind: constants.BigQuery,
 			expectedString: fmt.Sprintf(`a1= CASE WHEN COALESCE(TO_JSON_STRING(cc.a1) != '%s', true) THEN cc.a1 ELSE c.a1 END,b2= CASE WHEN COALESCE(cc.b2 != '__debezium_unavailable_value', true) THEN cc.b2 ELSE c.b2 END,c3=cc.c3,%s,%s`,
 				key, fmt.Sprintf("`start`= CASE WHEN COALESCE(TO_JSON_STRING(cc.`start`) != '%s', true) THEN cc.`start` ELSE c.`start` END", key), "`select`=cc.`select`,__artie_delete=cc.__artie_delete"),
@@ -491,7 +608,7 @@ func TestColumnsUpdateQuery(t *testing.T) {
 	}
 
 	for _, _testCase := range testCases {
-		actualQuery := _testCase.columns.UpdateQuery(_testCase.destKind, false, _testCase.skipDeleteCol)
+		actualQuery := _testCase.columns.UpdateQuery(_testCase.destKind, config.PreserveCasing, _testCase.skipDeleteCol)
 		assert.Equal(t, _testCase.expectedString, actualQuery, _testCase.name)
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/jsonutil"
 
 	"github.com/artie-labs/transfer/lib/typing/ext"
 
@@ -29,12 +30,21 @@ func (c *Column) DefaultValue(args *DefaultValueArgs, additionalDateFmts []strin
 	switch c.KindDetails.Kind {
 	case typing.Struct.Kind, typing.Array.Kind:
 		switch args.DestKind {
-		case constants.BigQuery:
-			return "JSON" + stringutil.Wrap(c.defaultValue, false), nil
-		case constants.Redshift:
-			return fmt.Sprintf("JSON_PARSE(%s)", stringutil.Wrap(c.defaultValue, false)), nil
-		case constants.Snowflake:
-			return stringutil.Wrap(c.defaultValue, false), nil
+		case constants.BigQuery, constants.Redshift, constants.Snowflake, constants.Databricks, constants.DuckDB:
+			// Make sure that the literal is valid JSON, this also covers arrays, scalars and nulls.
+			jsonValue, err := jsonutil.ToJSONString(c.defaultValue, jsonutil.InvalidJSONPolicyError)
+			if err != nil {
+				return nil, fmt.Errorf("invalid default value for a JSON column: %w", err)
+			}
+
+			switch args.DestKind {
+			case constants.BigQuery:
+				return "JSON" + stringutil.Wrap(jsonValue, false), nil
+			case constants.Redshift:
+				return fmt.Sprintf("JSON_PARSE(%s)", stringutil.Wrap(jsonValue, false)), nil
+			default:
+				return stringutil.Wrap(jsonValue, false), nil
+			}
 		}
 	case typing.ETime.Kind:
 		if c.KindDetails.ExtendedTimeDetails == nil {
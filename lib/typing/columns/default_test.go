@@ -86,9 +86,11 @@ func TestColumn_DefaultValue(t *testing.T) {
 			},
 			expectedValue: `{}`,
 			destKindToExpectedValueMap: map[constants.DestinationKind]any{
-				constants.BigQuery:  "JSON'{}'",
-				constants.Redshift:  `JSON_PARSE('{}')`,
-				constants.Snowflake: `'{}'`,
+				constants.BigQuery:   "JSON'{}'",
+				constants.Redshift:   `JSON_PARSE('{}')`,
+				constants.Snowflake:  `'{}'`,
+				constants.Databricks: `'{}'`,
+				constants.DuckDB:     `'{}'`,
 			},
 		},
 		{
@@ -102,9 +104,83 @@ func TestColumn_DefaultValue(t *testing.T) {
 			},
 			expectedValue: "{\"age\": 0, \"membership_level\": \"standard\"}",
 			destKindToExpectedValueMap: map[constants.DestinationKind]any{
-				constants.BigQuery:  "JSON'{\"age\": 0, \"membership_level\": \"standard\"}'",
-				constants.Redshift:  "JSON_PARSE('{\"age\": 0, \"membership_level\": \"standard\"}')",
-				constants.Snowflake: "'{\"age\": 0, \"membership_level\": \"standard\"}'",
+				constants.BigQuery:   "JSON'{\"age\": 0, \"membership_level\": \"standard\"}'",
+				constants.Redshift:   "JSON_PARSE('{\"age\": 0, \"membership_level\": \"standard\"}')",
+				constants.Snowflake:  "'{\"age\": 0, \"membership_level\": \"standard\"}'",
+				constants.Databricks: "'{\"age\": 0, \"membership_level\": \"standard\"}'",
+				constants.DuckDB:     "'{\"age\": 0, \"membership_level\": \"standard\"}'",
+			},
+		},
+		{
+			name: "json array",
+			col: &Column{
+				KindDetails:  typing.Struct,
+				defaultValue: `[1, "two", {"three": 3}]`,
+			},
+			args: &DefaultValueArgs{
+				Escape: true,
+			},
+			expectedValue: `[1, "two", {"three": 3}]`,
+			destKindToExpectedValueMap: map[constants.DestinationKind]any{
+				constants.BigQuery:   `JSON'[1, "two", {"three": 3}]'`,
+				constants.Redshift:   `JSON_PARSE('[1, "two", {"three": 3}]')`,
+				constants.Snowflake:  `'[1, "two", {"three": 3}]'`,
+				constants.Databricks: `'[1, "two", {"three": 3}]'`,
+				constants.DuckDB:     `'[1, "two", {"three": 3}]'`,
+			},
+		},
+		{
+			name: "json array (not a string)",
+			col: &Column{
+				KindDetails:  typing.Array,
+				defaultValue: []any{"foo", "it's"},
+			},
+			args: &DefaultValueArgs{
+				Escape: true,
+			},
+			expectedValue: []any{"foo", "it's"},
+			destKindToExpectedValueMap: map[constants.DestinationKind]any{
+				constants.BigQuery:   `JSON'["foo","it\'s"]'`,
+				constants.Redshift:   `JSON_PARSE('["foo","it\'s"]')`,
+				constants.Snowflake:  `'["foo","it\'s"]'`,
+				constants.Databricks: `'["foo","it\'s"]'`,
+				constants.DuckDB:     `'["foo","it\'s"]'`,
+			},
+		},
+		{
+			name: "json null",
+			col: &Column{
+				KindDetails:  typing.Struct,
+				defaultValue: "null",
+			},
+			args: &DefaultValueArgs{
+				Escape: true,
+			},
+			expectedValue: "null",
+			destKindToExpectedValueMap: map[constants.DestinationKind]any{
+				constants.BigQuery:   "JSON'null'",
+				constants.Redshift:   "JSON_PARSE('null')",
+				constants.Snowflake:  "'null'",
+				constants.Databricks: "'null'",
+				constants.DuckDB:     "'null'",
+			},
+		},
+		{
+			name: "json scalar",
+			col: &Column{
+				KindDetails:  typing.Struct,
+				defaultValue: `"hello"`,
+			},
+			args: &DefaultValueArgs{
+				Escape: true,
+			},
+			expectedValue: `"hello"`,
+			destKindToExpectedValueMap: map[constants.DestinationKind]any{
+				constants.BigQuery:   `JSON'"hello"'`,
+				constants.Redshift:   `JSON_PARSE('"hello"')`,
+				constants.Snowflake:  `'"hello"'`,
+				constants.Databricks: `'"hello"'`,
+				constants.DuckDB:     `'"hello"'`,
 			},
 		},
 		{
@@ -161,3 +237,20 @@ func TestColumn_DefaultValue(t *testing.T) {
 		}
 	}
 }
+
+func TestColumn_DefaultValue_MalformedJSON(t *testing.T) {
+	col := &Column{
+		KindDetails:  typing.Struct,
+		defaultValue: `{"foo": "bar"`,
+	}
+
+	for _, destKind := range []constants.DestinationKind{constants.Snowflake, constants.BigQuery, constants.Redshift, constants.Databricks, constants.DuckDB} {
+		_, err := col.DefaultValue(&DefaultValueArgs{Escape: true, DestKind: destKind}, nil)
+		assert.ErrorContains(t, err, `invalid default value for a JSON column: invalid JSON value: "{\"foo\": \"bar\""`, destKind)
+	}
+
+	// Destinations that don't generate a JSON literal will return the value as-is.
+	value, err := col.DefaultValue(&DefaultValueArgs{Escape: true, DestKind: constants.MSSQL}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"foo": "bar"`, value)
+}
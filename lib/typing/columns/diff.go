@@ -16,6 +16,23 @@ func shouldSkipColumn(colName string, softDelete bool, includeArtieUpdatedAt boo
 		return false
 	}
 
+	if colName == constants.DeletedAtColumnMarker {
+		// This column is only in the data if the topic is configured to include it.
+		return false
+	}
+
+	switch colName {
+	case constants.OpColumnMarker, constants.SourceTsMsColumnMarker, constants.FileColumnMarker, constants.PosColumnMarker:
+		// These columns are only in the data if the topic is configured to include metadata columns.
+		return false
+	case constants.SnapshotColumnMarker:
+		// This column is only in the data if the topic is configured to include it.
+		return false
+	case constants.OverflowColumnMarker:
+		// This column is only in the data if the topic has a column limit and columns have been folded into it.
+		return false
+	}
+
 	if colName == constants.UpdateColumnMarker && includeArtieUpdatedAt {
 		// We want to keep this column if includeArtieUpdatedAt is turned on
 		return false
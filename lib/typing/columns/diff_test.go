@@ -46,6 +46,30 @@ func TestShouldSkipColumn(t *testing.T) {
 			colName:        constants.UpdateColumnMarker,
 			expectedResult: true,
 		},
+		{
+			name:    "deleted at col marker",
+			colName: constants.DeletedAtColumnMarker,
+		},
+		{
+			name:    "op col marker",
+			colName: constants.OpColumnMarker,
+		},
+		{
+			name:    "source ts ms col marker",
+			colName: constants.SourceTsMsColumnMarker,
+		},
+		{
+			name:    "file col marker",
+			colName: constants.FileColumnMarker,
+		},
+		{
+			name:    "pos col marker",
+			colName: constants.PosColumnMarker,
+		},
+		{
+			name:    "snapshot col marker",
+			colName: constants.SnapshotColumnMarker,
+		},
 		{
 			name:    "random col",
 			colName: "firstName",
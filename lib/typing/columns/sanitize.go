@@ -0,0 +1,124 @@
+package columns
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/sql"
+)
+
+// Sanitizer replaces characters that are not allowed in the destination's identifiers with underscores.
+// A sanitizer should only be used for a single table, the mapping is recorded the first time a column is seen so a source column will always map to the same destination column.
+type Sanitizer struct {
+	// sourceToName is a map of the source column name to the sanitized column name.
+	sourceToName map[string]string
+	// nameToSource is the reverse of sourceToName, this is used to detect collisions.
+	nameToSource map[string]string
+	sync.Mutex
+}
+
+func NewSanitizer() *Sanitizer {
+	return &Sanitizer{
+		sourceToName: make(map[string]string),
+		nameToSource: make(map[string]string),
+	}
+}
+
+// isAllowed returns whether `r` is allowed in an unquoted identifier for `destKind`.
+func isAllowed(destKind constants.DestinationKind, r rune) bool {
+	if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+		return true
+	}
+
+	switch destKind {
+	case constants.Snowflake:
+		// https://docs.snowflake.com/en/sql-reference/identifiers-syntax
+		return r == '$'
+	case constants.Redshift:
+		// https://docs.aws.amazon.com/redshift/latest/dg/r_names.html
+		return r == '$' || r >= utf8.RuneSelf
+	}
+
+	// BigQuery and everything else only allows letters, numbers and underscores.
+	return false
+}
+
+// isAllowedFirst returns whether `r` is allowed as the first character of an identifier, none of the destinations allow digits or `$`.
+func isAllowedFirst(destKind constants.DestinationKind, r rune) bool {
+	if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+		return true
+	}
+
+	return destKind == constants.Redshift && r >= utf8.RuneSelf
+}
+
+// SanitizeName maps any illegal characters in `name` to underscores and prefixes it with an underscore if it does not start with a letter or an underscore.
+func SanitizeName(destKind constants.DestinationKind, name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if isAllowed(destKind, r) {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteRune('_')
+		}
+	}
+
+	sanitized := sb.String()
+	if first, _ := utf8.DecodeRuneInString(sanitized); !isAllowedFirst(destKind, first) {
+		sanitized = "_" + sanitized
+	}
+
+	return sanitized
+}
+
+// Renames returns a map of source column to sanitized column for the columns in `cols` that needed to be sanitized.
+// Collisions are resolved by adding a hash of the source column, columns that are already legal will claim their own name before any columns that need to be sanitized.
+func (s *Sanitizer) Renames(destKind constants.DestinationKind, cols []string) map[string]string {
+	s.Lock()
+	defer s.Unlock()
+
+	seen := make(map[string]bool)
+	var legalCols, illegalCols []string
+	for _, col := range cols {
+		col = strings.ToLower(col)
+		if seen[col] {
+			continue
+		}
+
+		seen[col] = true
+		if SanitizeName(destKind, col) == col {
+			legalCols = append(legalCols, col)
+		} else {
+			illegalCols = append(illegalCols, col)
+		}
+	}
+
+	sort.Strings(legalCols)
+	sort.Strings(illegalCols)
+	for _, col := range append(legalCols, illegalCols...) {
+		if _, isOk := s.sourceToName[col]; isOk {
+			continue
+		}
+
+		name := SanitizeName(destKind, col)
+		if _, isOk := s.nameToSource[name]; isOk {
+			// The suffix is derived from the source column, so the column will be renamed the same way regardless of the order we have seen the columns in.
+			name = sql.HashedIdentifier(destKind, name, col)
+		}
+
+		s.sourceToName[col] = name
+		s.nameToSource[name] = col
+	}
+
+	renames := make(map[string]string)
+	for col := range seen {
+		if name := s.sourceToName[col]; name != col {
+			renames[col] = name
+		}
+	}
+
+	return renames
+}
@@ -0,0 +1,84 @@
+package columns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+func TestSanitizeName(t *testing.T) {
+	{
+		// Legal names are untouched
+		for _, destKind := range constants.ValidDestinations {
+			assert.Equal(t, "first_name", SanitizeName(destKind, "first_name"), destKind)
+			assert.Equal(t, "_id", SanitizeName(destKind, "_id"), destKind)
+		}
+	}
+	{
+		// Spaces and dashes
+		assert.Equal(t, "first_name", SanitizeName(constants.BigQuery, "first name"))
+		assert.Equal(t, "first_name", SanitizeName(constants.Snowflake, "first-name"))
+		assert.Equal(t, "first_name_", SanitizeName(constants.Redshift, "first name!"))
+	}
+	{
+		// Leading digits
+		assert.Equal(t, "_1st_place", SanitizeName(constants.BigQuery, "1st_place"))
+		assert.Equal(t, "_1st_place", SanitizeName(constants.Snowflake, "1st place"))
+		assert.Equal(t, "_1st_place", SanitizeName(constants.Redshift, "1st-place"))
+	}
+	{
+		// Dollar signs are allowed in Snowflake and Redshift, but not as the first character.
+		assert.Equal(t, "price_usd", SanitizeName(constants.BigQuery, "price$usd"))
+		assert.Equal(t, "price$usd", SanitizeName(constants.Snowflake, "price$usd"))
+		assert.Equal(t, "price$usd", SanitizeName(constants.Redshift, "price$usd"))
+		assert.Equal(t, "_$price", SanitizeName(constants.Snowflake, "$price"))
+	}
+	{
+		// Unicode is only allowed in Redshift
+		assert.Equal(t, "caf_", SanitizeName(constants.BigQuery, "café"))
+		assert.Equal(t, "caf_", SanitizeName(constants.Snowflake, "café"))
+		assert.Equal(t, "café", SanitizeName(constants.Redshift, "café"))
+		assert.Equal(t, "_", SanitizeName(constants.BigQuery, "é"))
+		assert.Equal(t, "ñame", SanitizeName(constants.Redshift, "ñame"))
+	}
+}
+
+func TestSanitizer_Renames(t *testing.T) {
+	sanitizer := NewSanitizer()
+	{
+		// Legal columns are not returned
+		assert.Empty(t, sanitizer.Renames(constants.BigQuery, []string{"id", "name"}))
+	}
+	{
+		// Two columns that sanitize to the same name, the result should not depend on the order.
+		renames := sanitizer.Renames(constants.BigQuery, []string{"user-id", "user id"})
+		assert.Equal(t, map[string]string{"user id": "user_id", "user-id": "user_id_a7571dde"}, renames)
+
+		otherSanitizer := NewSanitizer()
+		assert.Equal(t, renames, otherSanitizer.Renames(constants.BigQuery, []string{"user id", "user-id"}))
+	}
+	{
+		// The mapping is recorded, so a column will keep its name even if it's the only one in the next batch.
+		assert.Equal(t, map[string]string{"user-id": "user_id_a7571dde"}, sanitizer.Renames(constants.BigQuery, []string{"user-id"}))
+	}
+	{
+		// A legal column that collides with a previously sanitized column should be renamed.
+		assert.Equal(t, map[string]string{"user_id": "user_id_f89d6b69"}, sanitizer.Renames(constants.BigQuery, []string{"user_id"}))
+	}
+	{
+		// Legal columns will claim their name before any columns in the same batch are sanitized.
+		assert.Equal(t, map[string]string{"a-b": "a_b_d44362d6"}, NewSanitizer().Renames(constants.BigQuery, []string{"a-b", "a_b"}))
+	}
+	{
+		// The suffix is derived from the source column, so it should not depend on which other columns we have seen before.
+		otherSanitizer := NewSanitizer()
+		assert.Empty(t, otherSanitizer.Renames(constants.BigQuery, []string{"user_id"}))
+		assert.Equal(t, map[string]string{"user-id": "user_id_a7571dde"}, otherSanitizer.Renames(constants.BigQuery, []string{"user-id"}))
+	}
+	{
+		// Mappings are case insensitive.
+		assert.Equal(t, map[string]string{"user id": "user_id"}, NewSanitizer().Renames(constants.BigQuery, []string{"User ID"}))
+	}
+}
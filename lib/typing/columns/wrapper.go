@@ -1,6 +1,7 @@
 package columns
 
 import (
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/sql"
 )
 
@@ -9,10 +10,10 @@ type Wrapper struct {
 	escapedName string
 }
 
-func NewWrapper(col Column, uppercaseEscNames bool, args *sql.NameArgs) Wrapper {
+func NewWrapper(col Column, casing config.IdentifierCasing, args *sql.NameArgs) Wrapper {
 	return Wrapper{
 		name:        col.name,
-		escapedName: col.Name(uppercaseEscNames, args),
+		escapedName: col.Name(casing, args),
 	}
 }
 
@@ -3,6 +3,7 @@ package columns
 import (
 	"testing"
 
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/sql"
 
 	"github.com/stretchr/testify/assert"
@@ -43,7 +44,7 @@ func TestWrapper_Complete(t *testing.T) {
 
 	for _, testCase := range testCases {
 		// Snowflake escape
-		w := NewWrapper(NewColumn(testCase.name, typing.Invalid), false, &sql.NameArgs{
+		w := NewWrapper(NewColumn(testCase.name, typing.Invalid), config.PreserveCasing, &sql.NameArgs{
 			Escape:   true,
 			DestKind: constants.Snowflake,
 		})
@@ -52,7 +53,7 @@ func TestWrapper_Complete(t *testing.T) {
 		assert.Equal(t, testCase.expectedRawName, w.RawName(), testCase.name)
 
 		// BigQuery escape
-		w = NewWrapper(NewColumn(testCase.name, typing.Invalid), false, &sql.NameArgs{
+		w = NewWrapper(NewColumn(testCase.name, typing.Invalid), config.PreserveCasing, &sql.NameArgs{
 			Escape:   true,
 			DestKind: constants.BigQuery,
 		})
@@ -61,7 +62,7 @@ func TestWrapper_Complete(t *testing.T) {
 		assert.Equal(t, testCase.expectedRawName, w.RawName(), testCase.name)
 
 		for _, destKind := range []constants.DestinationKind{constants.Snowflake, constants.BigQuery} {
-			w = NewWrapper(NewColumn(testCase.name, typing.Invalid), false, &sql.NameArgs{
+			w = NewWrapper(NewColumn(testCase.name, typing.Invalid), config.PreserveCasing, &sql.NameArgs{
 				Escape:   false,
 				DestKind: destKind,
 			})
@@ -71,7 +72,7 @@ func TestWrapper_Complete(t *testing.T) {
 		}
 
 		// Same if nil
-		w = NewWrapper(NewColumn(testCase.name, typing.Invalid), false, nil)
+		w = NewWrapper(NewColumn(testCase.name, typing.Invalid), config.PreserveCasing, nil)
 
 		assert.Equal(t, testCase.expectedRawName, w.EscapedName(), testCase.name)
 		assert.Equal(t, testCase.expectedRawName, w.RawName(), testCase.name)
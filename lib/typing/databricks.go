@@ -0,0 +1,83 @@
+package typing
+
+import (
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+// databricksTypeToKind - Converts a Databricks (Delta Lake) type to a KindDetails.
+// Following this spec: https://docs.databricks.com/en/sql/language-manual/sql-ref-datatypes.html
+func databricksTypeToKind(rawType string) KindDetails {
+	rawType = strings.TrimSpace(rawType)
+	if strings.HasPrefix(rawType, "decimal") {
+		if !strings.Contains(rawType, "(") {
+			// DECIMAL without (p, s) defaults to DECIMAL(10, 0)
+			return Integer
+		}
+
+		return ParseNumeric("decimal", rawType)
+	}
+
+	if strings.HasPrefix(rawType, "array") {
+		return Array
+	}
+
+	if strings.HasPrefix(rawType, "struct") || strings.HasPrefix(rawType, "map") {
+		return Struct
+	}
+
+	switch rawType {
+	case "string", "varchar", "char":
+		return String
+	case "bigint", "long", "int", "integer", "smallint", "short", "tinyint", "byte":
+		return Integer
+	case "double", "float", "real":
+		return Float
+	case "boolean":
+		return Boolean
+	case "variant":
+		return Struct
+	case "timestamp", "timestamp_ntz":
+		return NewKindDetailsFromTemplate(ETime, ext.DateTimeKindType)
+	case "date":
+		return NewKindDetailsFromTemplate(ETime, ext.DateKindType)
+	}
+
+	// VARCHAR(n) and CHAR(n) are stored as STRING with a length constraint.
+	if strings.HasPrefix(rawType, "varchar") || strings.HasPrefix(rawType, "char") {
+		return String
+	}
+
+	return Invalid
+}
+
+func kindToDatabricks(kd KindDetails) string {
+	switch kd.Kind {
+	case Float.Kind:
+		return "DOUBLE"
+	case Integer.Kind:
+		return "BIGINT"
+	case Struct.Kind, Array.Kind:
+		// We are storing JSON as STRING so it works across runtimes that do not have VARIANT.
+		return "STRING"
+	case String.Kind:
+		return "STRING"
+	case Boolean.Kind:
+		return "BOOLEAN"
+	case ETime.Kind:
+		switch kd.ExtendedTimeDetails.Type {
+		case ext.DateTimeKindType:
+			return "TIMESTAMP"
+		case ext.DateKindType:
+			return "DATE"
+		case ext.TimeKindType:
+			// Databricks does not have a TIME type.
+			return "STRING"
+		}
+	case EDecimal.Kind:
+		return kd.ExtendedDecimalDetails.DatabricksKind()
+	}
+
+	return kd.Kind
+}
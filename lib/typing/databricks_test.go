@@ -0,0 +1,103 @@
+package typing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+func TestDatabricksTypeToKind(t *testing.T) {
+	type _testCase struct {
+		name       string
+		rawTypes   []string
+		expectedKd KindDetails
+	}
+
+	testCases := []_testCase{
+		{
+			name:       "String",
+			rawTypes:   []string{"string", "STRING", "varchar(255)", "char(1)"},
+			expectedKd: String,
+		},
+		{
+			name:       "Integer",
+			rawTypes:   []string{"bigint", "int", "smallint", "tinyint", "decimal", "decimal(10,0)"},
+			expectedKd: Integer,
+		},
+		{
+			name:       "Float",
+			rawTypes:   []string{"double", "float"},
+			expectedKd: Float,
+		},
+		{
+			name:       "Boolean",
+			rawTypes:   []string{"boolean"},
+			expectedKd: Boolean,
+		},
+		{
+			name:       "Decimal",
+			rawTypes:   []string{"decimal(10,2)", "DECIMAL(38, 5)"},
+			expectedKd: EDecimal,
+		},
+		{
+			name:       "Timestamp",
+			rawTypes:   []string{"timestamp", "timestamp_ntz", "date"},
+			expectedKd: ETime,
+		},
+		{
+			name:       "Struct",
+			rawTypes:   []string{"variant", "struct<a:int>", "map<string,string>"},
+			expectedKd: Struct,
+		},
+		{
+			name:       "Array",
+			rawTypes:   []string{"array<string>"},
+			expectedKd: Array,
+		},
+		{
+			name:       "Invalid",
+			rawTypes:   []string{"binary", "interval"},
+			expectedKd: Invalid,
+		},
+	}
+
+	for _, testCase := range testCases {
+		for _, rawType := range testCase.rawTypes {
+			kd, err := DwhTypeToKind(constants.Databricks, rawType, "")
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedKd.Kind, kd.Kind, testCase.name)
+		}
+	}
+
+	{
+		// Precision and scale should be preserved
+		kd, err := DwhTypeToKind(constants.Databricks, "decimal(10,2)", "")
+		assert.NoError(t, err)
+		assert.Equal(t, 10, *kd.ExtendedDecimalDetails.Precision())
+		assert.Equal(t, 2, kd.ExtendedDecimalDetails.Scale())
+	}
+}
+
+func TestKindToDatabricks(t *testing.T) {
+	assert.Equal(t, "STRING", KindToDWHType(String, constants.Databricks, false))
+	assert.Equal(t, "BIGINT", KindToDWHType(Integer, constants.Databricks, false))
+	assert.Equal(t, "DOUBLE", KindToDWHType(Float, constants.Databricks, false))
+	assert.Equal(t, "BOOLEAN", KindToDWHType(Boolean, constants.Databricks, false))
+	assert.Equal(t, "STRING", KindToDWHType(Struct, constants.Databricks, false))
+	assert.Equal(t, "STRING", KindToDWHType(Array, constants.Databricks, false))
+	assert.Equal(t, "TIMESTAMP", KindToDWHType(NewKindDetailsFromTemplate(ETime, ext.DateTimeKindType), constants.Databricks, false))
+	assert.Equal(t, "DATE", KindToDWHType(NewKindDetailsFromTemplate(ETime, ext.DateKindType), constants.Databricks, false))
+	assert.Equal(t, "STRING", KindToDWHType(NewKindDetailsFromTemplate(ETime, ext.TimeKindType), constants.Databricks, false))
+
+	eDecimal := EDecimal
+	eDecimal.ExtendedDecimalDetails = decimal.NewDecimal(ptr.ToInt(10), 2, nil)
+	assert.Equal(t, "DECIMAL(10, 2)", KindToDWHType(eDecimal, constants.Databricks, false))
+
+	eDecimal.ExtendedDecimalDetails = decimal.NewDecimal(ptr.ToInt(50), 2, nil)
+	assert.Equal(t, "STRING", KindToDWHType(eDecimal, constants.Databricks, false))
+}
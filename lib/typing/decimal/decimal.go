@@ -55,6 +55,12 @@ func (d *Decimal) String() string {
 	return d.value.Text('f', d.scale)
 }
 
+// Float64 returns the nearest float64 value, this may lose precision.
+func (d *Decimal) Float64() float64 {
+	value, _ := d.value.Float64()
+	return value
+}
+
 func (d *Decimal) Value() any {
 	// -1 precision is used for variable scaled decimal
 	// We are opting to emit this as a STRING because the value is technically unbounded (can get to ~1 GB).
@@ -82,6 +88,51 @@ func (d *Decimal) RedshiftKind() string {
 	return d.toKind(MaxPrecisionBeforeString, "TEXT")
 }
 
+// DatabricksKind - is used to determine whether a NUMERIC data type should be a STRING or DECIMAL(p, s).
+// Spec: https://docs.databricks.com/en/sql/language-manual/data-types/decimal-type.html
+func (d *Decimal) DatabricksKind() string {
+	precision := MaxPrecisionBeforeString
+	if d.precision != nil {
+		precision = *d.precision
+	}
+
+	if precision > MaxPrecisionBeforeString || precision == -1 {
+		return "STRING"
+	}
+
+	return fmt.Sprintf("DECIMAL(%v, %v)", precision, d.scale)
+}
+
+// IcebergKind - is used to determine whether a NUMERIC data type should be a string or decimal(p, s).
+// Spec: https://iceberg.apache.org/spec/#primitive-types
+func (d *Decimal) IcebergKind() string {
+	precision := MaxPrecisionBeforeString
+	if d.precision != nil {
+		precision = *d.precision
+	}
+
+	if precision > MaxPrecisionBeforeString || precision == -1 {
+		return "string"
+	}
+
+	return fmt.Sprintf("decimal(%v, %v)", precision, d.scale)
+}
+
+// DuckDBKind - DuckDB's DECIMAL has the same maximum width of 38, wider values are stored as VARCHAR.
+// Spec: https://duckdb.org/docs/sql/data_types/numeric#fixed-point-decimals
+func (d *Decimal) DuckDBKind() string {
+	precision := MaxPrecisionBeforeString
+	if d.precision != nil {
+		precision = *d.precision
+	}
+
+	if precision > MaxPrecisionBeforeString || precision == -1 {
+		return "VARCHAR"
+	}
+
+	return fmt.Sprintf("DECIMAL(%v, %v)", precision, d.scale)
+}
+
 // BigQueryKind - is inferring logic from: https://cloud.google.com/bigquery/docs/reference/standard-sql/data-types#decimal_types
 func (d *Decimal) BigQueryKind() string {
 	if d.isNumeric() {
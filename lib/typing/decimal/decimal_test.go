@@ -17,55 +17,77 @@ func TestDecimalKind(t *testing.T) {
 		ExpectedSnowflakeKind string
 		ExpectedRedshiftKind  string
 		ExpectedBigQueryKind  string
+
+		ExpectedDatabricksKind string
+		ExpectedIcebergKind    string
+		ExpectedDuckDBKind     string
 	}
 
 	testCases := []_testCase{
 		{
-			Name:                  "-1 precision",
-			Precision:             -1,
-			ExpectedSnowflakeKind: "STRING",
-			ExpectedRedshiftKind:  "TEXT",
-			ExpectedBigQueryKind:  "STRING",
+			Name:                   "-1 precision",
+			Precision:              -1,
+			ExpectedSnowflakeKind:  "STRING",
+			ExpectedRedshiftKind:   "TEXT",
+			ExpectedBigQueryKind:   "STRING",
+			ExpectedDatabricksKind: "STRING",
+			ExpectedIcebergKind:    "string",
+			ExpectedDuckDBKind:     "VARCHAR",
 		},
 		{
-			Name:                  "numeric(39, 0)",
-			Precision:             39,
-			Scale:                 0,
-			ExpectedSnowflakeKind: "STRING",
-			ExpectedRedshiftKind:  "TEXT",
-			ExpectedBigQueryKind:  "STRING",
+			Name:                   "numeric(39, 0)",
+			Precision:              39,
+			Scale:                  0,
+			ExpectedSnowflakeKind:  "STRING",
+			ExpectedRedshiftKind:   "TEXT",
+			ExpectedBigQueryKind:   "STRING",
+			ExpectedDatabricksKind: "STRING",
+			ExpectedIcebergKind:    "string",
+			ExpectedDuckDBKind:     "VARCHAR",
 		},
 		{
-			Name:                  "numeric(39, 5)",
-			Precision:             39,
-			Scale:                 5,
-			ExpectedSnowflakeKind: "STRING",
-			ExpectedRedshiftKind:  "TEXT",
-			ExpectedBigQueryKind:  "BIGNUMERIC(39, 5)",
+			Name:                   "numeric(39, 5)",
+			Precision:              39,
+			Scale:                  5,
+			ExpectedSnowflakeKind:  "STRING",
+			ExpectedRedshiftKind:   "TEXT",
+			ExpectedBigQueryKind:   "BIGNUMERIC(39, 5)",
+			ExpectedDatabricksKind: "STRING",
+			ExpectedIcebergKind:    "string",
+			ExpectedDuckDBKind:     "VARCHAR",
 		},
 		{
-			Name:                  "numeric(38, 2)",
-			Precision:             38,
-			Scale:                 2,
-			ExpectedSnowflakeKind: "NUMERIC(38, 2)",
-			ExpectedRedshiftKind:  "NUMERIC(38, 2)",
-			ExpectedBigQueryKind:  "BIGNUMERIC(38, 2)",
+			Name:                   "numeric(38, 2)",
+			Precision:              38,
+			Scale:                  2,
+			ExpectedSnowflakeKind:  "NUMERIC(38, 2)",
+			ExpectedRedshiftKind:   "NUMERIC(38, 2)",
+			ExpectedBigQueryKind:   "BIGNUMERIC(38, 2)",
+			ExpectedDatabricksKind: "DECIMAL(38, 2)",
+			ExpectedIcebergKind:    "decimal(38, 2)",
+			ExpectedDuckDBKind:     "DECIMAL(38, 2)",
 		},
 		{
-			Name:                  "numeric(31, 2)",
-			Precision:             31,
-			Scale:                 2,
-			ExpectedSnowflakeKind: "NUMERIC(31, 2)",
-			ExpectedRedshiftKind:  "NUMERIC(31, 2)",
-			ExpectedBigQueryKind:  "NUMERIC(31, 2)",
+			Name:                   "numeric(31, 2)",
+			Precision:              31,
+			Scale:                  2,
+			ExpectedSnowflakeKind:  "NUMERIC(31, 2)",
+			ExpectedRedshiftKind:   "NUMERIC(31, 2)",
+			ExpectedBigQueryKind:   "NUMERIC(31, 2)",
+			ExpectedDatabricksKind: "DECIMAL(31, 2)",
+			ExpectedIcebergKind:    "decimal(31, 2)",
+			ExpectedDuckDBKind:     "DECIMAL(31, 2)",
 		},
 		{
-			Name:                  "bignumeric(76, 38)",
-			Precision:             76,
-			Scale:                 38,
-			ExpectedSnowflakeKind: "STRING",
-			ExpectedRedshiftKind:  "TEXT",
-			ExpectedBigQueryKind:  "BIGNUMERIC(76, 38)",
+			Name:                   "bignumeric(76, 38)",
+			Precision:              76,
+			Scale:                  38,
+			ExpectedSnowflakeKind:  "STRING",
+			ExpectedRedshiftKind:   "TEXT",
+			ExpectedBigQueryKind:   "BIGNUMERIC(76, 38)",
+			ExpectedDatabricksKind: "STRING",
+			ExpectedIcebergKind:    "string",
+			ExpectedDuckDBKind:     "VARCHAR",
 		},
 	}
 
@@ -74,5 +96,8 @@ func TestDecimalKind(t *testing.T) {
 		assert.Equal(t, testCase.ExpectedSnowflakeKind, d.SnowflakeKind(), testCase.Name)
 		assert.Equal(t, testCase.ExpectedRedshiftKind, d.RedshiftKind(), testCase.Name)
 		assert.Equal(t, testCase.ExpectedBigQueryKind, d.BigQueryKind(), testCase.Name)
+		assert.Equal(t, testCase.ExpectedDatabricksKind, d.DatabricksKind(), testCase.Name)
+		assert.Equal(t, testCase.ExpectedIcebergKind, d.IcebergKind(), testCase.Name)
+		assert.Equal(t, testCase.ExpectedDuckDBKind, d.DuckDBKind(), testCase.Name)
 	}
 }
@@ -0,0 +1,95 @@
+package decimal
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ScaleOverflow controls what we do with values that have more digits after the decimal point than the column's scale, e.g. `1.2345` into NUMERIC(10, 2).
+type ScaleOverflow string
+
+const (
+	// ScaleOverflowRound will round half to even, so `1.235` becomes `1.24` and `1.225` becomes `1.22`.
+	ScaleOverflowRound ScaleOverflow = "round"
+	// ScaleOverflowTruncate will drop the extra digits, rounding towards zero.
+	ScaleOverflowTruncate ScaleOverflow = "truncate"
+	// ScaleOverflowError will return an error.
+	ScaleOverflowError ScaleOverflow = "error"
+)
+
+func (s ScaleOverflow) Validate() error {
+	switch s {
+	case "", ScaleOverflowRound, ScaleOverflowTruncate, ScaleOverflowError:
+		return nil
+	default:
+		return fmt.Errorf("invalid decimal scale overflow: %q", s)
+	}
+}
+
+// toRat returns the exact decimal value that we would have written, false is returned if `value` is not a number.
+func toRat(value any) (*big.Rat, bool) {
+	var text string
+	switch castedValue := value.(type) {
+	case *Decimal:
+		// The shortest representation is what we would have written for a variable scaled decimal.
+		text = castedValue.value.Text('f', -1)
+	case float64:
+		text = strconv.FormatFloat(castedValue, 'f', -1, 64)
+	case float32:
+		text = strconv.FormatFloat(float64(castedValue), 'f', -1, 32)
+	case json.Number:
+		text = castedValue.String()
+	case string:
+		if strings.Contains(castedValue, "/") {
+			// [big.Rat] will also parse fractions, which are not valid decimals.
+			return nil, false
+		}
+
+		text = castedValue
+	default:
+		return nil, false
+	}
+
+	return new(big.Rat).SetString(text)
+}
+
+// Apply will make sure that `value` fits within `scale`, values that already fit are returned as-is.
+// Otherwise, the value is rounded or truncated and returned as a [Decimal] with `precision` and `scale`.
+func (s ScaleOverflow) Apply(value any, precision *int, scale int) (any, error) {
+	if s == "" || scale < 0 {
+		return value, nil
+	}
+
+	rat, isOk := toRat(value)
+	if !isOk {
+		return value, nil
+	}
+
+	multiplier := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+	scaled := new(big.Rat).Mul(rat, new(big.Rat).SetInt(multiplier))
+	if scaled.IsInt() {
+		return value, nil
+	}
+
+	// QuoRem truncates towards zero.
+	quotient, remainder := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	switch s {
+	case ScaleOverflowTruncate:
+	case ScaleOverflowRound:
+		// Compare the remainder against half of the denominator to see whether we should round away from zero.
+		cmp := new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(scaled.Denom())
+		if cmp > 0 || (cmp == 0 && quotient.Bit(0) == 1) {
+			quotient.Add(quotient, big.NewInt(int64(scaled.Sign())))
+		}
+	case ScaleOverflowError:
+		return nil, fmt.Errorf("value %v exceeds the decimal scale: %d", value, scale)
+	default:
+		return nil, fmt.Errorf("invalid decimal scale overflow: %q", s)
+	}
+
+	rounded := new(big.Rat).SetFrac(quotient, multiplier)
+	return NewDecimal(precision, scale, new(big.Float).SetRat(rounded)), nil
+}
@@ -0,0 +1,156 @@
+package decimal
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/ptr"
+)
+
+func TestScaleOverflow_Validate(t *testing.T) {
+	for _, scaleOverflow := range []ScaleOverflow{"", ScaleOverflowRound, ScaleOverflowTruncate, ScaleOverflowError} {
+		assert.NoError(t, scaleOverflow.Validate(), scaleOverflow)
+	}
+
+	assert.ErrorContains(t, ScaleOverflow("ceil").Validate(), `invalid decimal scale overflow: "ceil"`)
+}
+
+func TestScaleOverflow_Apply(t *testing.T) {
+	type _testCase struct {
+		name  string
+		value string
+
+		expectedRound    string
+		expectedTruncate string
+	}
+
+	testCases := []_testCase{
+		{
+			name:             "positive",
+			value:            "1.2345",
+			expectedRound:    "1.23",
+			expectedTruncate: "1.23",
+		},
+		{
+			name:             "negative",
+			value:            "-1.2345",
+			expectedRound:    "-1.23",
+			expectedTruncate: "-1.23",
+		},
+		{
+			name:             "rounds up",
+			value:            "1.2389",
+			expectedRound:    "1.24",
+			expectedTruncate: "1.23",
+		},
+		{
+			name:             "rounds away from zero when negative",
+			value:            "-1.2389",
+			expectedRound:    "-1.24",
+			expectedTruncate: "-1.23",
+		},
+		{
+			name:             "half way rounds up to even",
+			value:            "1.235",
+			expectedRound:    "1.24",
+			expectedTruncate: "1.23",
+		},
+		{
+			name:             "half way rounds down to even",
+			value:            "1.225",
+			expectedRound:    "1.22",
+			expectedTruncate: "1.22",
+		},
+		{
+			name:             "negative half way",
+			value:            "-1.235",
+			expectedRound:    "-1.24",
+			expectedTruncate: "-1.23",
+		},
+		{
+			name:             "just over half way",
+			value:            "1.22500001",
+			expectedRound:    "1.23",
+			expectedTruncate: "1.22",
+		},
+		{
+			name:             "carries over",
+			value:            "9.999",
+			expectedRound:    "10.00",
+			expectedTruncate: "9.99",
+		},
+	}
+
+	for _, testCase := range testCases {
+		for _, value := range []any{testCase.value, json.Number(testCase.value)} {
+			rounded, err := ScaleOverflowRound.Apply(value, ptr.ToInt(10), 2)
+			assert.NoError(t, err, testCase.name)
+			assert.Equal(t, testCase.expectedRound, rounded.(*Decimal).String(), testCase.name)
+
+			truncated, err := ScaleOverflowTruncate.Apply(value, ptr.ToInt(10), 2)
+			assert.NoError(t, err, testCase.name)
+			assert.Equal(t, testCase.expectedTruncate, truncated.(*Decimal).String(), testCase.name)
+			assert.Equal(t, 10, *truncated.(*Decimal).Precision(), testCase.name)
+
+			_, err = ScaleOverflowError.Apply(value, ptr.ToInt(10), 2)
+			assert.ErrorContains(t, err, "exceeds the decimal scale: 2", testCase.name)
+		}
+	}
+}
+
+func TestScaleOverflow_Apply_Types(t *testing.T) {
+	{
+		// Decimal
+		value := NewDecimal(ptr.ToInt(10), 4, big.NewFloat(1.2345))
+		rounded, err := ScaleOverflowRound.Apply(value, ptr.ToInt(10), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "1.23", rounded.(*Decimal).String())
+		assert.Equal(t, 2, rounded.(*Decimal).Scale())
+
+		_, err = ScaleOverflowError.Apply(value, ptr.ToInt(10), 2)
+		assert.ErrorContains(t, err, "value 1.2345 exceeds the decimal scale: 2")
+	}
+	{
+		// Floats
+		rounded, err := ScaleOverflowRound.Apply(1.235, ptr.ToInt(10), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "1.24", rounded.(*Decimal).String())
+
+		truncated, err := ScaleOverflowTruncate.Apply(float32(-1.2345), ptr.ToInt(10), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "-1.23", truncated.(*Decimal).String())
+	}
+	{
+		// Values that fit are returned as-is
+		for _, value := range []any{"1.23", "1.2", "-1", 1.5, json.Number("100.10")} {
+			for _, scaleOverflow := range []ScaleOverflow{ScaleOverflowRound, ScaleOverflowTruncate, ScaleOverflowError} {
+				out, err := scaleOverflow.Apply(value, ptr.ToInt(10), 2)
+				assert.NoError(t, err, value)
+				assert.Equal(t, value, out, value)
+			}
+		}
+	}
+	{
+		// Values that are not numbers are returned as-is
+		for _, value := range []any{"abc", "1/3", true, nil} {
+			out, err := ScaleOverflowError.Apply(value, ptr.ToInt(10), 2)
+			assert.NoError(t, err, value)
+			assert.Equal(t, value, out, value)
+		}
+	}
+	{
+		// No policy
+		out, err := ScaleOverflow("").Apply("1.2345", ptr.ToInt(10), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "1.2345", out)
+	}
+	{
+		// Scale of zero
+		rounded, err := ScaleOverflowRound.Apply("2.5", ptr.ToInt(10), 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "2", rounded.(*Decimal).String())
+	}
+}
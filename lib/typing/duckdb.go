@@ -0,0 +1,72 @@
+package typing
+
+import (
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+// duckDBTypeToKind - Converts the `data_type` from DuckDB's information_schema into a KindDetails.
+// Spec: https://duckdb.org/docs/sql/data_types/overview
+func duckDBTypeToKind(rawType string) KindDetails {
+	rawType = strings.TrimSpace(rawType)
+	if strings.HasPrefix(rawType, "decimal") {
+		return ParseNumeric("decimal", rawType)
+	}
+
+	switch rawType {
+	case "varchar", "json", "uuid":
+		return String
+	case "smallint":
+		return NewIntegerKindDetails(SmallIntegerKind)
+	case "integer":
+		return NewIntegerKindDetails(RegularIntegerKind)
+	case "tinyint", "bigint", "hugeint":
+		return Integer
+	case "double", "float", "real":
+		return Float
+	case "boolean":
+		return Boolean
+	case "timestamp with time zone", "timestamp":
+		return NewKindDetailsFromTemplate(ETime, ext.DateTimeKindType)
+	case "date":
+		return NewKindDetailsFromTemplate(ETime, ext.DateKindType)
+	case "time":
+		return NewKindDetailsFromTemplate(ETime, ext.TimeKindType)
+	case "blob":
+		return Bytes
+	}
+
+	return Invalid
+}
+
+func kindToDuckDB(kd KindDetails) string {
+	switch kd.Kind {
+	case Float.Kind:
+		return "DOUBLE"
+	case Integer.Kind:
+		return "BIGINT"
+	case Struct.Kind, Array.Kind:
+		// The JSON type is part of an extension that is not bundled with the driver, so we'll store JSON as a VARCHAR.
+		return "VARCHAR"
+	case String.Kind:
+		return "VARCHAR"
+	case Boolean.Kind:
+		return "BOOLEAN"
+	case Bytes.Kind:
+		return "BLOB"
+	case ETime.Kind:
+		switch kd.ExtendedTimeDetails.Type {
+		case ext.DateTimeKindType:
+			return "TIMESTAMP WITH TIME ZONE"
+		case ext.DateKindType:
+			return "DATE"
+		case ext.TimeKindType:
+			return "TIME"
+		}
+	case EDecimal.Kind:
+		return kd.ExtendedDecimalDetails.DuckDBKind()
+	}
+
+	return kd.Kind
+}
@@ -0,0 +1,106 @@
+package typing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+func TestDuckDBTypeToKind(t *testing.T) {
+	type _testCase struct {
+		name       string
+		rawTypes   []string
+		expectedKd KindDetails
+	}
+
+	testCases := []_testCase{
+		{
+			name:       "String",
+			rawTypes:   []string{"varchar", "VARCHAR", "json", "uuid"},
+			expectedKd: String,
+		},
+		{
+			name:       "Integer",
+			rawTypes:   []string{"bigint", "integer", "smallint", "tinyint", "hugeint", "decimal(18,0)"},
+			expectedKd: Integer,
+		},
+		{
+			name:       "Float",
+			rawTypes:   []string{"double", "float", "real"},
+			expectedKd: Float,
+		},
+		{
+			name:       "Boolean",
+			rawTypes:   []string{"boolean"},
+			expectedKd: Boolean,
+		},
+		{
+			name:       "Decimal",
+			rawTypes:   []string{"decimal(10,2)", "DECIMAL(38,5)"},
+			expectedKd: EDecimal,
+		},
+		{
+			name:       "Timestamp",
+			rawTypes:   []string{"timestamp with time zone", "timestamp", "date", "time"},
+			expectedKd: ETime,
+		},
+		{
+			name:       "Bytes",
+			rawTypes:   []string{"blob"},
+			expectedKd: Bytes,
+		},
+		{
+			name:       "Invalid",
+			rawTypes:   []string{"interval", "struct(a integer)"},
+			expectedKd: Invalid,
+		},
+	}
+
+	for _, testCase := range testCases {
+		for _, rawType := range testCase.rawTypes {
+			kd, err := DwhTypeToKind(constants.DuckDB, rawType, "")
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedKd.Kind, kd.Kind, testCase.name)
+		}
+	}
+
+	{
+		// Precision and scale should be preserved
+		kd, err := DwhTypeToKind(constants.DuckDB, "DECIMAL(10,2)", "")
+		assert.NoError(t, err)
+		assert.Equal(t, 10, *kd.ExtendedDecimalDetails.Precision())
+		assert.Equal(t, 2, kd.ExtendedDecimalDetails.Scale())
+	}
+	{
+		// Timestamps should round trip
+		kd, err := DwhTypeToKind(constants.DuckDB, "timestamp with time zone", "")
+		assert.NoError(t, err)
+		assert.Equal(t, ext.DateTimeKindType, kd.ExtendedTimeDetails.Type)
+		assert.Equal(t, "TIMESTAMP WITH TIME ZONE", KindToDWHType(kd, constants.DuckDB, false))
+	}
+}
+
+func TestKindToDuckDB(t *testing.T) {
+	assert.Equal(t, "VARCHAR", KindToDWHType(String, constants.DuckDB, false))
+	assert.Equal(t, "BIGINT", KindToDWHType(Integer, constants.DuckDB, false))
+	assert.Equal(t, "DOUBLE", KindToDWHType(Float, constants.DuckDB, false))
+	assert.Equal(t, "BOOLEAN", KindToDWHType(Boolean, constants.DuckDB, false))
+	assert.Equal(t, "VARCHAR", KindToDWHType(Struct, constants.DuckDB, false))
+	assert.Equal(t, "VARCHAR", KindToDWHType(Array, constants.DuckDB, false))
+	assert.Equal(t, "BLOB", KindToDWHType(Bytes, constants.DuckDB, false))
+	assert.Equal(t, "TIMESTAMP WITH TIME ZONE", KindToDWHType(NewKindDetailsFromTemplate(ETime, ext.DateTimeKindType), constants.DuckDB, false))
+	assert.Equal(t, "DATE", KindToDWHType(NewKindDetailsFromTemplate(ETime, ext.DateKindType), constants.DuckDB, false))
+	assert.Equal(t, "TIME", KindToDWHType(NewKindDetailsFromTemplate(ETime, ext.TimeKindType), constants.DuckDB, false))
+
+	eDecimal := EDecimal
+	eDecimal.ExtendedDecimalDetails = decimal.NewDecimal(ptr.ToInt(10), 2, nil)
+	assert.Equal(t, "DECIMAL(10, 2)", KindToDWHType(eDecimal, constants.DuckDB, false))
+
+	eDecimal.ExtendedDecimalDetails = decimal.NewDecimal(ptr.ToInt(50), 2, nil)
+	assert.Equal(t, "VARCHAR", KindToDWHType(eDecimal, constants.DuckDB, false))
+}
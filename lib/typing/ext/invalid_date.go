@@ -0,0 +1,54 @@
+package ext
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// InvalidDatePolicy controls what we do with zero dates (e.g. MySQL's `0000-00-00`) and date values that cannot be parsed.
+type InvalidDatePolicy string
+
+const (
+	// InvalidDatePolicyNull will replace the value with NULL.
+	InvalidDatePolicyNull InvalidDatePolicy = "null"
+	// InvalidDatePolicyEpoch will replace the value with the Unix epoch (1970-01-01 00:00:00 UTC).
+	InvalidDatePolicyEpoch InvalidDatePolicy = "epoch"
+	// InvalidDatePolicyError will return an error.
+	InvalidDatePolicyError InvalidDatePolicy = "error"
+)
+
+func (i InvalidDatePolicy) Validate() error {
+	switch i {
+	case "", InvalidDatePolicyNull, InvalidDatePolicyEpoch, InvalidDatePolicyError:
+		return nil
+	default:
+		return fmt.Errorf("invalid date policy: %q", i)
+	}
+}
+
+var zeroDateRegex = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})`)
+
+// IsZeroDate returns true if `value` starts with a date that has a zero year, month or day (e.g. `0000-00-00`, `2020-00-00 00:00:00`).
+func IsZeroDate(value string) bool {
+	matches := zeroDateRegex.FindStringSubmatch(value)
+	if len(matches) != 4 {
+		return false
+	}
+
+	return matches[1] == "0000" || matches[2] == "00" || matches[3] == "00"
+}
+
+// Handle will return the value that `value` should be replaced with, nil means that it should be replaced with NULL.
+func (i InvalidDatePolicy) Handle(value string, kindType ExtendedTimeKindType) (*ExtendedTime, error) {
+	switch i {
+	case InvalidDatePolicyNull:
+		return nil, nil
+	case InvalidDatePolicyEpoch:
+		return NewExtendedTime(time.Unix(0, 0).UTC(), kindType, ""), nil
+	case InvalidDatePolicyError:
+		return nil, fmt.Errorf("invalid date: %q", value)
+	default:
+		return nil, fmt.Errorf("invalid date policy: %q", i)
+	}
+}
@@ -0,0 +1,60 @@
+package ext
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvalidDatePolicy_Validate(t *testing.T) {
+	for _, policy := range []InvalidDatePolicy{"", InvalidDatePolicyNull, InvalidDatePolicyEpoch, InvalidDatePolicyError} {
+		assert.NoError(t, policy.Validate(), policy)
+	}
+
+	assert.ErrorContains(t, InvalidDatePolicy("zero").Validate(), `invalid date policy: "zero"`)
+}
+
+func TestIsZeroDate(t *testing.T) {
+	assert.True(t, IsZeroDate("0000-00-00"))
+	assert.True(t, IsZeroDate("0000-00-00 00:00:00"))
+	assert.True(t, IsZeroDate("2020-00-15"))
+	assert.True(t, IsZeroDate("2020-05-00T00:00:00Z"))
+
+	assert.False(t, IsZeroDate("2020-05-15"))
+	assert.False(t, IsZeroDate("2020-05-15 00:00:00"))
+	assert.False(t, IsZeroDate("00:00:00"))
+	assert.False(t, IsZeroDate("foo"))
+}
+
+func TestInvalidDatePolicy_Handle(t *testing.T) {
+	{
+		// Null
+		extTime, err := InvalidDatePolicyNull.Handle("0000-00-00", DateKindType)
+		assert.NoError(t, err)
+		assert.Nil(t, extTime)
+	}
+	{
+		// Epoch
+		extTime, err := InvalidDatePolicyEpoch.Handle("0000-00-00", DateKindType)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Unix(0, 0).UTC(), extTime.Time)
+		assert.Equal(t, Date, extTime.NestedKind)
+		assert.Equal(t, "1970-01-01", extTime.String(""))
+
+		extTime, err = InvalidDatePolicyEpoch.Handle("0000-00-00 00:00:00", DateTimeKindType)
+		assert.NoError(t, err)
+		assert.Equal(t, DateTime, extTime.NestedKind)
+		assert.Equal(t, "1970-01-01T00:00:00Z", extTime.String(""))
+	}
+	{
+		// Error
+		_, err := InvalidDatePolicyError.Handle("0000-00-00 00:00:00", DateTimeKindType)
+		assert.ErrorContains(t, err, `invalid date: "0000-00-00 00:00:00"`)
+	}
+	{
+		// Unset
+		_, err := InvalidDatePolicy("").Handle("0000-00-00", DateKindType)
+		assert.ErrorContains(t, err, `invalid date policy: ""`)
+	}
+}
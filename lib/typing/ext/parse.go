@@ -2,9 +2,28 @@ package ext
 
 import (
 	"fmt"
+	"regexp"
 	"time"
 )
 
+var fractionalSecondsRegex = regexp.MustCompile(`:\d{2}\.(\d+)`)
+
+// fractionalSecondsPrecision returns the number of fractional second digits in `dtString`, it'll return 0 if there are none.
+func fractionalSecondsPrecision(dtString string) int {
+	matches := fractionalSecondsRegex.FindStringSubmatch(dtString)
+	if len(matches) != 2 {
+		return 0
+	}
+
+	return len(matches[1])
+}
+
+func newParsedExtendedTime(t time.Time, kindType ExtendedTimeKindType, originalFormat string, dtString string) *ExtendedTime {
+	extendedTime := NewExtendedTime(t, kindType, originalFormat)
+	extendedTime.Precision = fractionalSecondsPrecision(dtString)
+	return extendedTime
+}
+
 func ParseFromInterface(val any, additionalDateFormats []string) (*ExtendedTime, error) {
 	if val == nil {
 		return nil, fmt.Errorf("val is nil")
@@ -33,7 +52,10 @@ func ParseTimeExactMatch(layout, potentialDateTimeString string) (time.Time, boo
 		return ts, false, err
 	}
 
-	return ts, ts.Format(layout) == potentialDateTimeString, nil
+	// Trailing zeros in the fractional seconds are not a precision loss, so we'll also compare against the layout with the source's precision.
+	exactMatch := ts.Format(layout) == potentialDateTimeString ||
+		ts.Format(layoutWithPrecision(layout, fractionalSecondsPrecision(potentialDateTimeString))) == potentialDateTimeString
+	return ts, exactMatch, nil
 }
 
 // ParseExtendedDateTime  will take a string and check if the string is of the following types:
@@ -56,7 +78,7 @@ func ParseExtendedDateTime(dtString string, additionalDateFormats []string) (*Ex
 			potentialFormat = supportedDateTimeLayout
 			potentialTime = ts
 			if exactMatch {
-				return NewExtendedTime(ts, DateTimeKindType, supportedDateTimeLayout), nil
+				return newParsedExtendedTime(ts, DateTimeKindType, supportedDateTimeLayout, dtString), nil
 			}
 		}
 	}
@@ -73,14 +95,13 @@ func ParseExtendedDateTime(dtString string, additionalDateFormats []string) (*Ex
 	for _, supportedTimeFormat := range supportedTimeFormats {
 		ts, exactMatch, err := ParseTimeExactMatch(supportedTimeFormat, dtString)
 		if err == nil && exactMatch {
-			return NewExtendedTime(ts, TimeKindType, supportedTimeFormat), nil
-
+			return newParsedExtendedTime(ts, TimeKindType, supportedTimeFormat, dtString), nil
 		}
 	}
 
 	// If nothing fits, return the next best thing.
 	if potentialFormat != "" {
-		return NewExtendedTime(potentialTime, DateTimeKindType, potentialFormat), nil
+		return newParsedExtendedTime(potentialTime, DateTimeKindType, potentialFormat, dtString), nil
 	}
 
 	return nil, fmt.Errorf("dtString: %s is not supported", dtString)
@@ -109,3 +109,26 @@ func TestTimeLayout(t *testing.T) {
 		assert.Equal(t, parsedTsString, extTime.String(""))
 	}
 }
+
+func TestParseExtendedDateTime_Precision(t *testing.T) {
+	{
+		extTime, err := ParseExtendedDateTime("2022-09-06T03:19:24.942000000Z", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, NanosecondPrecision, extTime.Precision)
+		assert.Equal(t, "2022-09-06T03:19:24.942000000Z", extTime.String(""))
+	}
+	{
+		extTime, err := ParseExtendedDateTime("03:19:24.942000", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, TimeKindType, extTime.NestedKind.Type)
+		assert.Equal(t, MicrosecondPrecision, extTime.Precision)
+		assert.Equal(t, "03:19:24.942000", extTime.String(PostgresTimeFormatNoTZ))
+	}
+	{
+		// No fractional seconds
+		extTime, err := ParseExtendedDateTime("2022-09-06T03:19:24Z", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, extTime.Precision)
+		assert.Equal(t, "2022-09-06T03:19:24Z", extTime.String(""))
+	}
+}
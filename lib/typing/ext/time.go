@@ -1,6 +1,7 @@
 package ext
 
 import (
+	"strings"
 	"time"
 )
 
@@ -34,11 +35,20 @@ var (
 	}
 )
 
+// Number of fractional second digits that the source emitted.
+const (
+	MillisecondPrecision = 3
+	MicrosecondPrecision = 6
+	NanosecondPrecision  = 9
+)
+
 // ExtendedTime is created because Golang's time.Time does not allow us to explicitly cast values as a date, or time
 // and only allows timestamp expressions.
 type ExtendedTime struct {
 	time.Time
 	NestedKind NestedKind
+	// Precision is the number of fractional second digits from the source, if this is not set we'll format with the layout as is.
+	Precision int
 }
 
 func (e *ExtendedTime) IsValid() bool {
@@ -77,16 +87,45 @@ func (e *ExtendedTime) GetTime() time.Time {
 
 func (e *ExtendedTime) String(overrideFormat string) string {
 	if overrideFormat != "" {
-		return e.Time.Format(overrideFormat)
+		return e.Time.Format(e.applyPrecision(overrideFormat))
 	}
 
-	return e.Time.Format(e.NestedKind.Format)
+	return e.Time.Format(e.applyPrecision(e.NestedKind.Format))
 }
 
 func (e *ExtendedTime) StringUTC(overrideFormat string) string {
 	if overrideFormat != "" {
-		return e.Time.In(time.UTC).Format(overrideFormat)
+		return e.Time.In(time.UTC).Format(e.applyPrecision(overrideFormat))
+	}
+
+	return e.Time.In(time.UTC).Format(e.applyPrecision(e.NestedKind.Format))
+}
+
+// applyPrecision will change the fractional seconds in `layout` to always emit the source's precision, so `.942000000` does not get trimmed to `.942`.
+// The number of fractional digits in `layout` is the most that the destination supports, so we will never emit more than that.
+func (e *ExtendedTime) applyPrecision(layout string) string {
+	return layoutWithPrecision(layout, e.Precision)
+}
+
+func layoutWithPrecision(layout string, precision int) string {
+	if precision <= 0 {
+		return layout
+	}
+
+	idx := strings.Index(layout, "05.")
+	if idx < 0 {
+		return layout
+	}
+
+	start := idx + len("05.")
+	end := start
+	for end < len(layout) && (layout[end] == '0' || layout[end] == '9') {
+		end++
+	}
+
+	if end == start {
+		return layout
 	}
 
-	return e.Time.In(time.UTC).Format(e.NestedKind.Format)
+	return layout[:start] + strings.Repeat("0", min(end-start, precision)) + layout[end:]
 }
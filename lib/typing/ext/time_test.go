@@ -27,3 +27,43 @@ func TestExtTimeIsValid(t *testing.T) {
 		assert.False(t, ext.IsValid())
 	}
 }
+
+func TestExtendedTime_Precision(t *testing.T) {
+	birthday := time.Date(2022, time.September, 6, 3, 19, 24, 942000000, time.UTC)
+	{
+		// Precision is not set, so the layout is used as is.
+		extTime := NewExtendedTime(birthday, DateTimeKindType, "")
+		assert.Equal(t, "2022-09-06T03:19:24.942Z", extTime.String(""))
+	}
+	{
+		// Timestamp
+		extTime := NewExtendedTime(birthday, DateTimeKindType, "")
+		extTime.Precision = NanosecondPrecision
+		assert.Equal(t, "2022-09-06T03:19:24.942000000Z", extTime.String(""))
+		assert.Equal(t, "2022-09-06T03:19:24.942000000Z", extTime.StringUTC(""))
+		// BigQuery only supports microseconds.
+		assert.Equal(t, "2022-09-06 03:19:24.942000", extTime.StringUTC(BigQueryDateTimeFormat))
+		// Layout has no fractional seconds.
+		assert.Equal(t, "2022-09-06T03:19:24Z", extTime.String(time.RFC3339))
+	}
+	{
+		// Time
+		extTime := NewExtendedTime(birthday, TimeKindType, "")
+		extTime.Precision = NanosecondPrecision
+		assert.Equal(t, "03:19:24.942000000", extTime.String("15:04:05.999999999"))
+		assert.Equal(t, "03:19:24.942000", extTime.String(PostgresTimeFormatNoTZ))
+		assert.Equal(t, "03:19:24.942000+00", extTime.String(""))
+	}
+	{
+		// Source precision is lower than what the layout supports.
+		extTime := NewExtendedTime(time.Date(2022, time.September, 6, 3, 19, 24, 0, time.UTC), TimeKindType, "")
+		extTime.Precision = MillisecondPrecision
+		assert.Equal(t, "03:19:24.000", extTime.String(PostgresTimeFormatNoTZ))
+	}
+	{
+		// Date
+		extTime := NewExtendedTime(birthday, DateKindType, "")
+		extTime.Precision = NanosecondPrecision
+		assert.Equal(t, "2022-09-06", extTime.String(""))
+	}
+}
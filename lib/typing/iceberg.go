@@ -0,0 +1,77 @@
+package typing
+
+import (
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+// icebergTypeToKind - Converts an Iceberg type to a KindDetails.
+// Following this spec: https://iceberg.apache.org/spec/#schemas-and-data-types
+func icebergTypeToKind(rawType string) KindDetails {
+	rawType = strings.TrimSpace(rawType)
+	if strings.HasPrefix(rawType, "decimal") {
+		return ParseNumeric("decimal", rawType)
+	}
+
+	if strings.HasPrefix(rawType, "fixed") {
+		return Bytes
+	}
+
+	switch rawType {
+	case "string", "uuid":
+		return String
+	case "int", "long":
+		return Integer
+	case "float", "double":
+		return Float
+	case "boolean":
+		return Boolean
+	case "binary":
+		return Bytes
+	case "timestamp", "timestamptz", "timestamp_ns", "timestamptz_ns":
+		return NewKindDetailsFromTemplate(ETime, ext.DateTimeKindType)
+	case "date":
+		return NewKindDetailsFromTemplate(ETime, ext.DateKindType)
+	case "time":
+		return NewKindDetailsFromTemplate(ETime, ext.TimeKindType)
+	case "struct", "map":
+		// Nested types are JSON objects in the table schema, callers should pass in the object's type.
+		return Struct
+	case "list":
+		return Array
+	}
+
+	return Invalid
+}
+
+func kindToIceberg(kd KindDetails) string {
+	switch kd.Kind {
+	case Float.Kind:
+		return "double"
+	case Integer.Kind:
+		return "long"
+	case Struct.Kind, Array.Kind:
+		// Iceberg's nested types require a schema for every field (and element), so we are storing JSON as a string.
+		return "string"
+	case String.Kind:
+		return "string"
+	case Boolean.Kind:
+		return "boolean"
+	case Bytes.Kind:
+		return "binary"
+	case ETime.Kind:
+		switch kd.ExtendedTimeDetails.Type {
+		case ext.DateTimeKindType:
+			return "timestamptz"
+		case ext.DateKindType:
+			return "date"
+		case ext.TimeKindType:
+			return "time"
+		}
+	case EDecimal.Kind:
+		return kd.ExtendedDecimalDetails.IcebergKind()
+	}
+
+	return kd.Kind
+}
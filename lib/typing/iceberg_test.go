@@ -0,0 +1,129 @@
+package typing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+func TestIcebergTypeToKind(t *testing.T) {
+	type _testCase struct {
+		name       string
+		rawTypes   []string
+		expectedKd KindDetails
+	}
+
+	testCases := []_testCase{
+		{
+			name:       "String",
+			rawTypes:   []string{"string", "STRING", "uuid"},
+			expectedKd: String,
+		},
+		{
+			name:       "Integer",
+			rawTypes:   []string{"int", "long", "decimal(10, 0)"},
+			expectedKd: Integer,
+		},
+		{
+			name:       "Float",
+			rawTypes:   []string{"float", "double"},
+			expectedKd: Float,
+		},
+		{
+			name:       "Boolean",
+			rawTypes:   []string{"boolean"},
+			expectedKd: Boolean,
+		},
+		{
+			name:       "Decimal",
+			rawTypes:   []string{"decimal(10, 2)", "decimal(38,5)"},
+			expectedKd: EDecimal,
+		},
+		{
+			name:       "Timestamp",
+			rawTypes:   []string{"timestamp", "timestamptz", "timestamptz_ns", "date", "time"},
+			expectedKd: ETime,
+		},
+		{
+			name:       "Bytes",
+			rawTypes:   []string{"binary", "fixed[16]"},
+			expectedKd: Bytes,
+		},
+		{
+			name:       "Struct",
+			rawTypes:   []string{"struct", "map"},
+			expectedKd: Struct,
+		},
+		{
+			name:       "Array",
+			rawTypes:   []string{"list"},
+			expectedKd: Array,
+		},
+		{
+			name:       "Invalid",
+			rawTypes:   []string{"variant", "geometry"},
+			expectedKd: Invalid,
+		},
+	}
+
+	for _, testCase := range testCases {
+		for _, rawType := range testCase.rawTypes {
+			kd, err := DwhTypeToKind(constants.Iceberg, rawType, "")
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedKd.Kind, kd.Kind, testCase.name)
+		}
+	}
+
+	{
+		// Precision and scale should be preserved
+		kd, err := DwhTypeToKind(constants.Iceberg, "decimal(10, 2)", "")
+		assert.NoError(t, err)
+		assert.Equal(t, 10, *kd.ExtendedDecimalDetails.Precision())
+		assert.Equal(t, 2, kd.ExtendedDecimalDetails.Scale())
+	}
+	{
+		// Time types
+		kd, err := DwhTypeToKind(constants.Iceberg, "timestamptz", "")
+		assert.NoError(t, err)
+		assert.Equal(t, ext.DateTimeKindType, kd.ExtendedTimeDetails.Type)
+
+		kd, err = DwhTypeToKind(constants.Iceberg, "date", "")
+		assert.NoError(t, err)
+		assert.Equal(t, ext.DateKindType, kd.ExtendedTimeDetails.Type)
+
+		kd, err = DwhTypeToKind(constants.Iceberg, "time", "")
+		assert.NoError(t, err)
+		assert.Equal(t, ext.TimeKindType, kd.ExtendedTimeDetails.Type)
+	}
+}
+
+func TestKindToIceberg(t *testing.T) {
+	assert.Equal(t, "string", KindToDWHType(String, constants.Iceberg, false))
+	assert.Equal(t, "long", KindToDWHType(Integer, constants.Iceberg, true))
+	assert.Equal(t, "double", KindToDWHType(Float, constants.Iceberg, false))
+	assert.Equal(t, "boolean", KindToDWHType(Boolean, constants.Iceberg, false))
+	assert.Equal(t, "binary", KindToDWHType(Bytes, constants.Iceberg, false))
+	assert.Equal(t, "string", KindToDWHType(Struct, constants.Iceberg, false))
+	assert.Equal(t, "string", KindToDWHType(Array, constants.Iceberg, false))
+	assert.Equal(t, "timestamptz", KindToDWHType(NewKindDetailsFromTemplate(ETime, ext.DateTimeKindType), constants.Iceberg, false))
+	assert.Equal(t, "date", KindToDWHType(NewKindDetailsFromTemplate(ETime, ext.DateKindType), constants.Iceberg, false))
+	assert.Equal(t, "time", KindToDWHType(NewKindDetailsFromTemplate(ETime, ext.TimeKindType), constants.Iceberg, false))
+
+	eDecimal := EDecimal
+	eDecimal.ExtendedDecimalDetails = decimal.NewDecimal(ptr.ToInt(10), 2, nil)
+	assert.Equal(t, "decimal(10, 2)", KindToDWHType(eDecimal, constants.Iceberg, false))
+
+	// The mapping should round trip.
+	kd, err := DwhTypeToKind(constants.Iceberg, KindToDWHType(eDecimal, constants.Iceberg, false), "")
+	assert.NoError(t, err)
+	assert.Equal(t, eDecimal.ExtendedDecimalDetails.Precision(), kd.ExtendedDecimalDetails.Precision())
+	assert.Equal(t, eDecimal.ExtendedDecimalDetails.Scale(), kd.ExtendedDecimalDetails.Scale())
+
+	eDecimal.ExtendedDecimalDetails = decimal.NewDecimal(ptr.ToInt(50), 2, nil)
+	assert.Equal(t, "string", KindToDWHType(eDecimal, constants.Iceberg, false))
+}
@@ -0,0 +1,94 @@
+package typing
+
+import (
+	"fmt"
+	"math"
+)
+
+// IntegerKind is the width of an integer column.
+type IntegerKind int
+
+const (
+	NotSpecifiedKind IntegerKind = iota
+	SmallIntegerKind
+	RegularIntegerKind
+	BigIntegerKind
+)
+
+// Bits returns the size of the integer, columns without a width are treated as 64-bit.
+func (i IntegerKind) Bits() int {
+	switch i {
+	case SmallIntegerKind:
+		return 16
+	case RegularIntegerKind:
+		return 32
+	default:
+		return 64
+	}
+}
+
+// Range returns the min and max value that can be stored.
+func (i IntegerKind) Range() (int64, int64) {
+	switch i {
+	case SmallIntegerKind:
+		return math.MinInt16, math.MaxInt16
+	case RegularIntegerKind:
+		return math.MinInt32, math.MaxInt32
+	default:
+		return math.MinInt64, math.MaxInt64
+	}
+}
+
+// NewIntegerKindDetails returns an integer kind that carries the width of the column.
+func NewIntegerKindDetails(integerKind IntegerKind) KindDetails {
+	details := Integer
+	details.OptionalIntegerKind = &integerKind
+	return details
+}
+
+// CheckIntegerRange will return an error if `value` cannot be stored in the integer column without overflowing.
+// Columns without a width are treated as 64-bit and values that are not numbers are left to the caller.
+func CheckIntegerRange(kd KindDetails, value any) error {
+	integerKind := NotSpecifiedKind
+	if kd.OptionalIntegerKind != nil {
+		integerKind = *kd.OptionalIntegerKind
+	}
+
+	minValue, maxValue := integerKind.Range()
+	var inRange bool
+	switch castedValue := value.(type) {
+	case int:
+		inRange = int64(castedValue) >= minValue && int64(castedValue) <= maxValue
+	case int8:
+		inRange = int64(castedValue) >= minValue && int64(castedValue) <= maxValue
+	case int16:
+		inRange = int64(castedValue) >= minValue && int64(castedValue) <= maxValue
+	case int32:
+		inRange = int64(castedValue) >= minValue && int64(castedValue) <= maxValue
+	case int64:
+		inRange = castedValue >= minValue && castedValue <= maxValue
+	case uint:
+		inRange = uint64(castedValue) <= uint64(maxValue)
+	case uint8:
+		inRange = uint64(castedValue) <= uint64(maxValue)
+	case uint16:
+		inRange = uint64(castedValue) <= uint64(maxValue)
+	case uint32:
+		inRange = uint64(castedValue) <= uint64(maxValue)
+	case uint64:
+		inRange = castedValue <= uint64(maxValue)
+	case float32:
+		inRange = float64(castedValue) >= float64(minValue) && float64(castedValue) < float64(maxValue)+1
+	case float64:
+		// float64(math.MaxInt64) rounds up to 2^63, so we're checking against max + 1 to avoid letting 2^63 through.
+		inRange = castedValue >= float64(minValue) && castedValue < float64(maxValue)+1
+	default:
+		return nil
+	}
+
+	if !inRange {
+		return fmt.Errorf("value: %v is out of range for a %d-bit integer column [%d, %d]", value, integerKind.Bits(), minValue, maxValue)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package typing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckIntegerRange(t *testing.T) {
+	type _testCase struct {
+		name        string
+		kd          KindDetails
+		value       any
+		expectedErr string
+	}
+
+	testCases := []_testCase{
+		// In range
+		{name: "no width", kd: Integer, value: int64(math.MaxInt64)},
+		{name: "int16", kd: NewIntegerKindDetails(SmallIntegerKind), value: int16(123)},
+		{name: "int32", kd: NewIntegerKindDetails(RegularIntegerKind), value: 123},
+		{name: "int64", kd: NewIntegerKindDetails(BigIntegerKind), value: int64(-123)},
+		{name: "float from JSON", kd: NewIntegerKindDetails(RegularIntegerKind), value: float64(123)},
+		{name: "not a number", kd: NewIntegerKindDetails(SmallIntegerKind), value: "123456"},
+		// Boundaries
+		{name: "int16 max", kd: NewIntegerKindDetails(SmallIntegerKind), value: int64(math.MaxInt16)},
+		{name: "int16 min", kd: NewIntegerKindDetails(SmallIntegerKind), value: int64(math.MinInt16)},
+		{name: "int32 max", kd: NewIntegerKindDetails(RegularIntegerKind), value: int64(math.MaxInt32)},
+		{name: "int32 min", kd: NewIntegerKindDetails(RegularIntegerKind), value: float64(math.MinInt32)},
+		{name: "int64 min", kd: NewIntegerKindDetails(BigIntegerKind), value: int64(math.MinInt64)},
+		{name: "uint64 max int64", kd: Integer, value: uint64(math.MaxInt64)},
+		// Overflow
+		{
+			name:        "int16 max + 1",
+			kd:          NewIntegerKindDetails(SmallIntegerKind),
+			value:       int32(math.MaxInt16 + 1),
+			expectedErr: "value: 32768 is out of range for a 16-bit integer column [-32768, 32767]",
+		},
+		{
+			name:        "int16 min - 1",
+			kd:          NewIntegerKindDetails(SmallIntegerKind),
+			value:       math.MinInt16 - 1,
+			expectedErr: "value: -32769 is out of range for a 16-bit integer column",
+		},
+		{
+			name:        "int32 max + 1",
+			kd:          NewIntegerKindDetails(RegularIntegerKind),
+			value:       int64(math.MaxInt32 + 1),
+			expectedErr: "value: 2147483648 is out of range for a 32-bit integer column [-2147483648, 2147483647]",
+		},
+		{
+			name:        "int32 max + 1 (float)",
+			kd:          NewIntegerKindDetails(RegularIntegerKind),
+			value:       float64(math.MaxInt32 + 1),
+			expectedErr: "is out of range for a 32-bit integer column",
+		},
+		{
+			name:        "uint64 beyond int64",
+			kd:          Integer,
+			value:       uint64(math.MaxInt64 + 1),
+			expectedErr: "value: 9223372036854775808 is out of range for a 64-bit integer column",
+		},
+		{
+			name:        "float beyond int64",
+			kd:          NewIntegerKindDetails(BigIntegerKind),
+			value:       float64(math.MaxInt64),
+			expectedErr: "is out of range for a 64-bit integer column",
+		},
+	}
+
+	for _, testCase := range testCases {
+		err := CheckIntegerRange(testCase.kd, testCase.value)
+		if testCase.expectedErr == "" {
+			assert.NoError(t, err, testCase.name)
+		} else {
+			assert.ErrorContains(t, err, testCase.expectedErr, testCase.name)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package mongo
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -15,6 +16,8 @@ import (
 	"go.mongodb.org/mongo-driver/bson/bsonrw"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
 	"github.com/artie-labs/transfer/lib/typing/ext"
 )
 
@@ -43,36 +46,100 @@ func JSONEToMap(val []byte) (map[string]any, error) {
 		return nil, err
 	}
 
-	err = json.Unmarshal(bytes, &jsonMap)
-	return jsonMap, err
+	if err = json.Unmarshal(bytes, &jsonMap); err != nil {
+		return nil, err
+	}
+
+	for key, value := range jsonMap {
+		parsedValue, err := parseDecimals(value, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key %q: %w", key, err)
+		}
+
+		jsonMap[key] = parsedValue
+	}
+
+	return jsonMap, nil
 }
 
-var (
-	tDateTime  = reflect.TypeOf(primitive.DateTime(0))
-	tOID       = reflect.TypeOf(primitive.ObjectID{})
-	tBinary    = reflect.TypeOf(primitive.Binary{})
-	tDecimal   = reflect.TypeOf(primitive.Decimal128{})
-	tTimestamp = reflect.TypeOf(primitive.Timestamp{})
-)
+// parseDecimals will replace `{"$numberDecimal": "..."}` objects with their value, Decimal128 can have up to 34 digits so we are not going through float64.
+// Top level values become [*decimal.Decimal], nested values become [json.Number] so that they can be marshalled back into JSON.
+func parseDecimals(value any, topLevel bool) (any, error) {
+	switch castedValue := value.(type) {
+	case map[string]any:
+		if numberDecimal, isOk := castedValue["$numberDecimal"].(string); isOk && len(castedValue) == 1 {
+			return parseDecimal(numberDecimal, topLevel)
+		}
+
+		for key, nestedValue := range castedValue {
+			parsedValue, err := parseDecimals(nestedValue, false)
+			if err != nil {
+				return nil, err
+			}
 
-func decimalEncodeValue(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
-	if !val.IsValid() || val.Type() != tDecimal {
-		return bsoncodec.ValueEncoderError{Name: "Decimal128EncodeValue", Types: []reflect.Type{tDecimal}, Received: val}
+			castedValue[key] = parsedValue
+		}
+	case []any:
+		for i, nestedValue := range castedValue {
+			parsedValue, err := parseDecimals(nestedValue, false)
+			if err != nil {
+				return nil, err
+			}
+
+			castedValue[i] = parsedValue
+		}
 	}
 
-	s, isOk := val.Interface().(primitive.Decimal128)
-	if !isOk {
-		return bsoncodec.ValueEncoderError{Name: "ObjectIDEncodeValue not objectID", Types: []reflect.Type{tOID}, Received: val}
+	return value, nil
+}
+
+// ParseNestedDecimal will turn a nested decimal (see [parseDecimals]) back into a [*decimal.Decimal], other values are returned as is.
+// This is used when a nested value becomes a column, such as the fields within a `$set` operator.
+func ParseNestedDecimal(value any) (any, error) {
+	if number, isOk := value.(json.Number); isOk {
+		return parseDecimal(string(number), true)
 	}
 
-	parsedFloat, err := strconv.ParseFloat(s.String(), 64)
+	return value, nil
+}
+
+func parseDecimal(value string, topLevel bool) (any, error) {
+	decimal128, err := primitive.ParseDecimal128(value)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to parse decimal %q: %w", value, err)
+	}
+
+	bigInt, exponent, err := decimal128.BigInt()
+	if err != nil {
+		// NaN and Infinity cannot be represented by any of the destinations' numeric types, so we'll fail instead of dropping the value.
+		return nil, fmt.Errorf("failed to parse decimal %q: %w", value, err)
+	}
+
+	var scale int
+	if exponent >= 0 {
+		bigInt.Mul(bigInt, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil))
+	} else {
+		scale = -exponent
 	}
 
-	return vw.WriteDouble(parsedFloat)
+	bigFloat := new(big.Float).SetInt(bigInt)
+	bigFloat.Quo(bigFloat, new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)))
+	// Decimal128 does not have a fixed precision or scale, so this is a variable numeric.
+	parsedDecimal := decimal.NewDecimal(ptr.ToInt(decimal.PrecisionNotSpecified), scale, bigFloat)
+	if topLevel {
+		return parsedDecimal, nil
+	}
+
+	return json.Number(parsedDecimal.String()), nil
 }
 
+var (
+	tDateTime  = reflect.TypeOf(primitive.DateTime(0))
+	tOID       = reflect.TypeOf(primitive.ObjectID{})
+	tBinary    = reflect.TypeOf(primitive.Binary{})
+	tTimestamp = reflect.TypeOf(primitive.Timestamp{})
+)
+
 func dateTimeEncodeValue(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
 	if !val.IsValid() || val.Type() != tDateTime {
 		return bsoncodec.ValueEncoderError{Name: "DateTimeEncodeValue", Types: []reflect.Type{tDateTime}, Received: val}
@@ -140,7 +207,6 @@ func createCustomRegistry() *bsoncodec.RegistryBuilder {
 	rb.RegisterTypeEncoder(tDateTime, bsoncodec.ValueEncoderFunc(dateTimeEncodeValue))
 	rb.RegisterTypeEncoder(tOID, bsoncodec.ValueEncoderFunc(objectIDEncodeValue))
 	rb.RegisterTypeEncoder(tBinary, bsoncodec.ValueEncoderFunc(binaryEncodeValue))
-	rb.RegisterTypeEncoder(tDecimal, bsoncodec.ValueEncoderFunc(decimalEncodeValue))
 	rb.RegisterTypeEncoder(tTimestamp, bsoncodec.ValueEncoderFunc(timestampEncodeValue))
 	primitiveCodecs.RegisterPrimitiveCodecs(rb)
 	return rb
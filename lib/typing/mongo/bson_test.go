@@ -1,9 +1,13 @@
 package mongo
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/typing/decimal"
 )
 
 // TestMarshal, every single type is listed here: https://github.com/mongodb/specifications/blob/master/source/extended-json.rst#canonical-extended-json-example
@@ -70,7 +74,7 @@ func TestMarshal(t *testing.T) {
 	assert.Equal(t, result["test_bool_false"], false)
 	assert.Equal(t, result["test_bool_true"], true)
 	assert.Equal(t, result["object_id"], "63793b4014f7f28f570c524e")
-	assert.Equal(t, result["test_decimal"], float64(13.37))
+	assert.Equal(t, "13.37", result["test_decimal"].(*decimal.Decimal).String())
 	assert.Equal(t, result["test_decimal_2"], float64(13.37))
 	assert.Equal(t, result["test_int"], float64(1337))
 	assert.Equal(t, result["test_list"], []any{float64(1), float64(2), float64(3), float64(4), "hello"})
@@ -92,3 +96,30 @@ func TestMarshal(t *testing.T) {
 	assert.Equal(t, "-Infinity", result["test_negative_infinity_string"])     // This should not be escaped.
 	assert.Equal(t, "-Infinity123", result["test_negative_infinity_string1"]) // This should not be escaped.
 }
+
+func TestJSONEToMap_NumberDecimal(t *testing.T) {
+	{
+		// Every digit should be kept
+		result, err := JSONEToMap([]byte(`{
+	"precise": {"$numberDecimal": "12345678901234567890.123456789012"},
+	"exponent": {"$numberDecimal": "1.5E+3"},
+	"nested": {"amounts": [{"$numberDecimal": "9007199254740993.01"}]}
+}`))
+		assert.NoError(t, err)
+		assert.Equal(t, "12345678901234567890.123456789012", result["precise"].(*decimal.Decimal).String())
+		assert.Equal(t, "1500", result["exponent"].(*decimal.Decimal).String())
+		assert.Equal(t, map[string]any{"amounts": []any{json.Number("9007199254740993.01")}}, result["nested"])
+	}
+	{
+		// NaN and Infinity cannot be represented as a decimal
+		for _, value := range []string{"NaN", "Infinity", "-Infinity"} {
+			_, err := JSONEToMap([]byte(fmt.Sprintf(`{"amount": {"$numberDecimal": %q}}`, value)))
+			assert.ErrorContains(t, err, fmt.Sprintf(`failed to parse key "amount": failed to parse decimal %q`, value), value)
+		}
+	}
+	{
+		// Nested values should not be dropped either
+		_, err := JSONEToMap([]byte(`{"nested": {"amount": {"$numberDecimal": "NaN"}}}`))
+		assert.ErrorContains(t, err, `failed to parse key "nested": failed to parse decimal "NaN"`)
+	}
+}
@@ -35,11 +35,13 @@ func mssqlTypeToKind(rawType string, stringPrecision string) KindDetails {
 			Kind:                    String.Kind,
 			OptionalStringPrecision: strPrecision,
 		}
+	case "smallint":
+		return NewIntegerKindDetails(SmallIntegerKind)
+	case "int":
+		return NewIntegerKindDetails(RegularIntegerKind)
 	case
-		"smallint",
 		"tinyint",
-		"bigint",
-		"int":
+		"bigint":
 		return Integer
 	case "float", "real":
 		return Float
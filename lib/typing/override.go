@@ -0,0 +1,46 @@
+package typing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+// ParseKindOverride will parse a user provided type (e.g. `string`, `integer`, `decimal(10, 2)` or `timestamp`) into [KindDetails].
+// This is used to override the type that we would have otherwise inferred from the value.
+func ParseKindOverride(value string) (KindDetails, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "string", "text":
+		return String, nil
+	case "int", "integer":
+		return Integer, nil
+	case "float":
+		return Float, nil
+	case "decimal", "numeric":
+		return EDecimal, nil
+	case "bool", "boolean":
+		return Boolean, nil
+	case "struct", "json":
+		return Struct, nil
+	case "array":
+		return Array, nil
+	case "timestamp", "datetime":
+		return NewKindDetailsFromTemplate(ETime, ext.DateTimeKindType), nil
+	case "date":
+		return NewKindDetailsFromTemplate(ETime, ext.DateKindType), nil
+	case "time":
+		return NewKindDetailsFromTemplate(ETime, ext.TimeKindType), nil
+	}
+
+	for _, prefix := range []string{"decimal", "numeric"} {
+		if strings.HasPrefix(value, prefix+"(") && strings.HasSuffix(value, ")") {
+			if kindDetails := ParseNumeric(prefix, value); kindDetails.Kind != Invalid.Kind {
+				return kindDetails, nil
+			}
+		}
+	}
+
+	return Invalid, fmt.Errorf("unsupported type: %q", value)
+}
@@ -0,0 +1,44 @@
+package typing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/typing/ext"
+)
+
+func TestParseKindOverride(t *testing.T) {
+	for value, expectedKind := range map[string]KindDetails{
+		"string":    String,
+		" TEXT ":    String,
+		"integer":   Integer,
+		"int":       Integer,
+		"float":     Float,
+		"decimal":   EDecimal,
+		"boolean":   Boolean,
+		"json":      Struct,
+		"array":     Array,
+		"timestamp": NewKindDetailsFromTemplate(ETime, ext.DateTimeKindType),
+		"date":      NewKindDetailsFromTemplate(ETime, ext.DateKindType),
+		"time":      NewKindDetailsFromTemplate(ETime, ext.TimeKindType),
+		// Decimals without a scale are integers.
+		"numeric(10)": Integer,
+	} {
+		kindDetails, err := ParseKindOverride(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expectedKind, kindDetails, value)
+	}
+	{
+		// Decimal with precision and scale
+		kindDetails, err := ParseKindOverride("decimal(10, 2)")
+		assert.NoError(t, err)
+		assert.Equal(t, EDecimal.Kind, kindDetails.Kind)
+		assert.Equal(t, 10, *kindDetails.ExtendedDecimalDetails.Precision())
+		assert.Equal(t, 2, kindDetails.ExtendedDecimalDetails.Scale())
+	}
+	for _, value := range []string{"", "varchar", "decimal(10, abc)", "decimal(1, 2, 3)", "decimal(10"} {
+		_, err := ParseKindOverride(value)
+		assert.ErrorContains(t, err, "unsupported type", value)
+	}
+}
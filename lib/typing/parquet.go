@@ -20,6 +20,8 @@ type FieldTag struct {
 	Scale          *int
 	Precision      *int
 	Length         *int
+	// FieldID is optional, formats like Iceberg use it to map the Parquet columns to the table's columns.
+	FieldID *int
 }
 
 func (f FieldTag) String() string {
@@ -61,6 +63,10 @@ func (f FieldTag) String() string {
 		parts = append(parts, fmt.Sprintf("length=%v", *f.Length))
 	}
 
+	if f.FieldID != nil {
+		parts = append(parts, fmt.Sprintf("fieldid=%v", *f.FieldID))
+	}
+
 	return strings.Join(parts, ", ")
 }
 
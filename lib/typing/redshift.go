@@ -29,7 +29,11 @@ func redshiftTypeToKind(rawType string, stringPrecision string) KindDetails {
 	switch rawType {
 	case "super":
 		return Struct
-	case "integer", "bigint":
+	case "smallint":
+		return NewIntegerKindDetails(SmallIntegerKind)
+	case "integer":
+		return NewIntegerKindDetails(RegularIntegerKind)
+	case "bigint":
 		return Integer
 	case "double precision":
 		return Float
@@ -106,3 +106,15 @@ func TestRedshiftTypeToKind(t *testing.T) {
 		}
 	}
 }
+
+func TestRedshiftTypeToKind_IntegerWidth(t *testing.T) {
+	for rawType, expectedKd := range map[string]KindDetails{
+		"smallint": NewIntegerKindDetails(SmallIntegerKind),
+		"integer":  NewIntegerKindDetails(RegularIntegerKind),
+		"bigint":   Integer,
+	} {
+		kd, err := DwhTypeToKind(constants.Redshift, rawType, "")
+		assert.NoError(t, err)
+		assert.Equal(t, expectedKd, kd, rawType)
+	}
+}
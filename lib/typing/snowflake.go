@@ -21,7 +21,7 @@ func snowflakeTypeToKind(snowflakeType string) KindDetails {
 		idxStop = idx
 	}
 
-	// Geography, geometry date, time, varbinary, binary are currently not supported.
+	// Geography and geometry are currently not supported.
 	switch strings.TrimSpace(snowflakeType[:idxStop]) {
 	case "number":
 		return ParseNumeric("number", snowflakeType)
@@ -38,8 +38,10 @@ func snowflakeTypeToKind(snowflakeType string) KindDetails {
 		return String
 	case "boolean":
 		return Boolean
-	case "variant", "object":
+	case "variant":
 		return Struct
+	case "object":
+		return Object
 	case "array":
 		return Array
 	case "datetime", "timestamp", "timestamp_ltz", "timestamp_ntz", "timestamp_tz":
@@ -48,6 +50,8 @@ func snowflakeTypeToKind(snowflakeType string) KindDetails {
 		return NewKindDetailsFromTemplate(ETime, ext.TimeKindType)
 	case "date":
 		return NewKindDetailsFromTemplate(ETime, ext.DateKindType)
+	case "binary", "varbinary":
+		return Bytes
 	default:
 		return Invalid
 	}
@@ -57,10 +61,18 @@ func kindToSnowflake(kindDetails KindDetails) string {
 	switch kindDetails.Kind {
 	case Struct.Kind:
 		// Snowflake doesn't recognize struct.
-		// Must be either OBJECT or VARIANT. However, VARIANT is more versatile.
+		// Must be either OBJECT or VARIANT. We'll only use OBJECT if we know the column can only contain objects since VARIANT is more versatile.
+		if kindDetails.OptionalObject {
+			return "object"
+		}
+
 		return "variant"
+	case Array.Kind:
+		return "array"
 	case Boolean.Kind:
 		return "boolean"
+	case Bytes.Kind:
+		return "binary"
 	case ETime.Kind:
 		switch kindDetails.ExtendedTimeDetails.Type {
 		case ext.DateTimeKindType:
@@ -83,13 +83,21 @@ func TestSnowflakeTypeToKindDateTime(t *testing.T) {
 
 func TestSnowflakeTypeToKindComplex(t *testing.T) {
 	{
-		expectedStructs := []string{"variant", "VaRIANT", "OBJECT"}
+		expectedStructs := []string{"variant", "VaRIANT"}
 		for _, expectedStruct := range expectedStructs {
 			kd, err := DwhTypeToKind(constants.Snowflake, expectedStruct, "")
 			assert.NoError(t, err)
 			assert.Equal(t, Struct, kd, expectedStruct)
 		}
 	}
+	{
+		// OBJECT columns can only contain objects.
+		for _, objectType := range []string{"object", "OBJECT"} {
+			kd, err := DwhTypeToKind(constants.Snowflake, objectType, "")
+			assert.NoError(t, err)
+			assert.Equal(t, Object, kd, objectType)
+		}
+	}
 	{
 		kd, err := DwhTypeToKind(constants.Snowflake, "boolean", "")
 		assert.NoError(t, err)
@@ -100,6 +108,13 @@ func TestSnowflakeTypeToKindComplex(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, Array, kd)
 	}
+	{
+		for _, binaryType := range []string{"BINARY", "binary(8388608)", "VARBINARY"} {
+			kd, err := DwhTypeToKind(constants.Snowflake, binaryType, "")
+			assert.NoError(t, err)
+			assert.Equal(t, Bytes, kd, binaryType)
+		}
+	}
 }
 
 func TestSnowflakeTypeToKindErrors(t *testing.T) {
@@ -123,6 +138,9 @@ func TestSnowflakeTypeNoDataLoss(t *testing.T) {
 		String,
 		Boolean,
 		Struct,
+		Object,
+		Array,
+		Bytes,
 	}
 
 	for _, kindDetail := range kindDetails {
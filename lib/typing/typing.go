@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/jsonutil"
 	"github.com/artie-labs/transfer/lib/typing/decimal"
 	"github.com/artie-labs/transfer/lib/typing/ext"
 )
@@ -18,6 +19,31 @@ type Settings struct {
 	// whether we have a value from the column. This will also bypass our Typing library.
 	// This only works for data sources with a schema such as Postgres and MySQL
 	CreateAllColumnsIfAvailable bool `yaml:"createAllColumnsIfAvailable"`
+
+	// TreatStringsAsJSON - If true, we will infer string values that are valid JSON objects or arrays as `Struct`.
+	// By default, these will be kept as `String` unless the column has been declared as JSON in the optional schema.
+	TreatStringsAsJSON bool `yaml:"treatStringsAsJSON"`
+
+	// InvalidDatePolicy - How we should handle zero dates (e.g. MySQL's `0000-00-00`) and values that cannot be parsed for date columns.
+	// Supported values are `null`, `epoch` and `error`. If this is not set, the value will be passed through as-is.
+	InvalidDatePolicy ext.InvalidDatePolicy `yaml:"invalidDatePolicy,omitempty"`
+
+	// InvalidJSONPolicy - How we should handle string values for JSON columns that are not valid JSON.
+	// Supported values are `error` (reject the row) and `string` (store the value as a JSON string). If this is not set, the value will be passed through as-is.
+	InvalidJSONPolicy jsonutil.InvalidJSONPolicy `yaml:"invalidJSONPolicy,omitempty"`
+
+	// PreserveJSONNumbers - If true, we will decode numbers from JSON payloads without going through a float64.
+	// This will preserve the precision of integers that are larger than 2^53 (e.g. 64-bit IDs).
+	PreserveJSONNumbers bool `yaml:"preserveJSONNumbers,omitempty"`
+
+	// DecimalScaleOverflow - How we should handle decimal values that have more digits after the decimal point than the column's scale.
+	// Supported values are `round` (half to even), `truncate` and `error`. If this is not set, the value will be passed through as-is.
+	DecimalScaleOverflow decimal.ScaleOverflow `yaml:"decimalScaleOverflow,omitempty"`
+
+	// UnknownTypePolicy - How we should handle Debezium columns with a type that we do not recognize.
+	// Supported values are `string` (store the raw value as text), `json` (store the value as a struct) and `error` (reject the event).
+	// If this is not set, the column will be skipped from the schema and its type will be inferred from the value.
+	UnknownTypePolicy UnknownTypePolicy `yaml:"unknownTypePolicy,omitempty"`
 }
 
 type KindDetails struct {
@@ -27,6 +53,13 @@ type KindDetails struct {
 
 	// Optional kind details metadata
 	OptionalStringPrecision *int
+	// OptionalIntegerKind is the width of an integer column, if it's nil then we'll treat it as 64-bit.
+	OptionalIntegerKind *IntegerKind
+	// OptionalArrayElementKind is the kind of the elements within an array, if it's nil then the element type is unknown.
+	OptionalArrayElementKind *KindDetails
+	// OptionalObject is set for struct columns that can only contain JSON objects (e.g. Snowflake's `OBJECT`).
+	// Otherwise, a struct column can contain any JSON value.
+	OptionalObject bool
 }
 
 // Summarized this from Snowflake + Reflect.
@@ -60,6 +93,12 @@ var (
 		Kind: "struct",
 	}
 
+	// Object is a struct that can only contain JSON objects, this is only used for destinations that make the distinction (e.g. Snowflake).
+	Object = KindDetails{
+		Kind:           "struct",
+		OptionalObject: true,
+	}
+
 	String = KindDetails{
 		Kind: "string",
 	}
@@ -67,6 +106,17 @@ var (
 	ETime = KindDetails{
 		Kind: "extended_time",
 	}
+
+	// Geography is only used for destinations with a native spatial type (e.g. BigQuery's `GEOGRAPHY`).
+	// The value may be GeoJSON, WKT or WKB and it's up to the destination to convert it.
+	Geography = KindDetails{
+		Kind: "geography",
+	}
+
+	// Bytes is only used for destinations with a native binary type (e.g. Snowflake's `BINARY` and BigQuery's `BYTES`).
+	Bytes = KindDetails{
+		Kind: "bytes",
+	}
 )
 
 func NewKindDetailsFromTemplate(details KindDetails, extendedType ext.ExtendedTimeKindType) KindDetails {
@@ -78,6 +128,18 @@ func NewKindDetailsFromTemplate(details KindDetails, extendedType ext.ExtendedTi
 	return details
 }
 
+// NewArrayKindDetails returns an array kind that carries the kind of its elements.
+// Nested arrays and elements that we cannot type will return a generic array.
+func NewArrayKindDetails(elementKind KindDetails) KindDetails {
+	if elementKind.Kind == Invalid.Kind || elementKind.Kind == Array.Kind {
+		return Array
+	}
+
+	details := Array
+	details.OptionalArrayElementKind = &elementKind
+	return details
+}
+
 // IsJSON - We also need to check if the string is a JSON string or not
 // If it could be one, it will start with { and end with }.
 // Once there, we will then check if it's a JSON string or not.
@@ -132,6 +194,13 @@ func ParseValue(settings Settings, key string, optionalSchema map[string]KindDet
 		// This is a limitation with JSON - https://github.com/golang/go/issues/56719
 		// UNLESS Transfer is provided with a schema object, and we deliberately typecast the value to an integer
 		// before calling ParseValue().
+		return Float
+	case json.Number:
+		// Numbers will be decoded as [json.Number] if `preserveJSONNumbers` is enabled, integers can then be identified without being rounded.
+		if _, err := convertedVal.Int64(); err == nil {
+			return Integer
+		}
+
 		return Float
 	case bool:
 		return Boolean
@@ -149,7 +218,7 @@ func ParseValue(settings Settings, key string, optionalSchema map[string]KindDet
 			}
 		}
 
-		if IsJSON(convertedVal) {
+		if settings.TreatStringsAsJSON && IsJSON(convertedVal) {
 			return Struct
 		}
 
@@ -187,6 +256,12 @@ func KindToDWHType(kd KindDetails, dwh constants.DestinationKind, isPk bool) str
 		return kindToRedshift(kd)
 	case constants.MSSQL:
 		return kindToMSSQL(kd, isPk)
+	case constants.Databricks:
+		return kindToDatabricks(kd)
+	case constants.Iceberg:
+		return kindToIceberg(kd)
+	case constants.DuckDB:
+		return kindToDuckDB(kd)
 	}
 
 	return ""
@@ -204,6 +279,12 @@ func DwhTypeToKind(dwh constants.DestinationKind, dwhType, stringPrecision strin
 		return redshiftTypeToKind(dwhType, stringPrecision), nil
 	case constants.MSSQL:
 		return mssqlTypeToKind(dwhType, stringPrecision), nil
+	case constants.Databricks:
+		return databricksTypeToKind(dwhType), nil
+	case constants.Iceberg:
+		return icebergTypeToKind(dwhType), nil
+	case constants.DuckDB:
+		return duckDBTypeToKind(dwhType), nil
 	}
 
 	return Invalid, fmt.Errorf("unexpected dwh kind, label: %v", dwh)
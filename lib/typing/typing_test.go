@@ -1,6 +1,7 @@
 package typing
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
@@ -88,6 +89,11 @@ func TestParseValueBasic(t *testing.T) {
 	assert.Equal(t, ParseValue(Settings{}, "", nil, math.MaxInt), Integer)
 	assert.Equal(t, ParseValue(Settings{}, "", nil, -1*math.MaxInt), Integer)
 
+	// JSON numbers, integers should not be rounded.
+	assert.Equal(t, Integer, ParseValue(Settings{}, "", nil, json.Number("1234567890123456789")))
+	assert.Equal(t, Float, ParseValue(Settings{}, "", nil, json.Number("7.5")))
+	assert.Equal(t, Float, ParseValue(Settings{}, "", nil, json.Number("99999999999999999999")))
+
 	// Invalid
 	assert.Equal(t, ParseValue(Settings{}, "", nil, nil), Invalid)
 	assert.Equal(t, ParseValue(Settings{}, "", nil, errors.New("hello")), Invalid)
@@ -189,6 +195,19 @@ func TestString(t *testing.T) {
 	}
 }
 
+func TestParseValue_TreatStringsAsJSON(t *testing.T) {
+	for _, val := range []string{"{}", `{"foo": "bar"}`, `[1, 2, 3]`} {
+		// JSON looking strings should stay as strings by default.
+		assert.Equal(t, String, ParseValue(Settings{}, "note", nil, val), val)
+		assert.Equal(t, Struct, ParseValue(Settings{TreatStringsAsJSON: true}, "note", nil, val), val)
+		// Declaring the column as JSON in the optional schema.
+		assert.Equal(t, Struct, ParseValue(Settings{}, "note", map[string]KindDetails{"note": Struct}, val), val)
+	}
+
+	// Not valid JSON
+	assert.Equal(t, String, ParseValue(Settings{TreatStringsAsJSON: true}, "note", nil, "{foo}"))
+}
+
 func TestOptionalSchema(t *testing.T) {
 	kd := ParseValue(Settings{}, "", nil, true)
 	assert.Equal(t, kd, Boolean)
@@ -0,0 +1,37 @@
+package typing
+
+import "fmt"
+
+// UnknownTypePolicy controls what we do with columns from a CDC schema that have a type we do not recognize.
+type UnknownTypePolicy string
+
+const (
+	// UnknownTypePolicyString will create the column as a string and store the raw value as text.
+	UnknownTypePolicyString UnknownTypePolicy = "string"
+	// UnknownTypePolicyJSON will create the column as a struct and store the raw value as JSON.
+	UnknownTypePolicyJSON UnknownTypePolicy = "json"
+	// UnknownTypePolicyError will reject the event.
+	UnknownTypePolicyError UnknownTypePolicy = "error"
+)
+
+func (u UnknownTypePolicy) Validate() error {
+	switch u {
+	case "", UnknownTypePolicyString, UnknownTypePolicyJSON, UnknownTypePolicyError:
+		return nil
+	default:
+		return fmt.Errorf("invalid unknown type policy: %q", u)
+	}
+}
+
+// KindDetails returns the kind that a column with an unknown type should be created as.
+// This will return [Invalid] if the policy is not set, in which case the kind will be inferred from the value.
+func (u UnknownTypePolicy) KindDetails() KindDetails {
+	switch u {
+	case UnknownTypePolicyString:
+		return String
+	case UnknownTypePolicyJSON:
+		return Struct
+	default:
+		return Invalid
+	}
+}
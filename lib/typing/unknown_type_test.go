@@ -0,0 +1,22 @@
+package typing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnknownTypePolicy_Validate(t *testing.T) {
+	for _, policy := range []UnknownTypePolicy{"", UnknownTypePolicyString, UnknownTypePolicyJSON, UnknownTypePolicyError} {
+		assert.NoError(t, policy.Validate(), policy)
+	}
+
+	assert.ErrorContains(t, UnknownTypePolicy("null").Validate(), `invalid unknown type policy: "null"`)
+}
+
+func TestUnknownTypePolicy_KindDetails(t *testing.T) {
+	assert.Equal(t, Invalid, UnknownTypePolicy("").KindDetails())
+	assert.Equal(t, String, UnknownTypePolicyString.KindDetails())
+	assert.Equal(t, Struct, UnknownTypePolicyJSON.KindDetails())
+	assert.Equal(t, Invalid, UnknownTypePolicyError.KindDetails())
+}
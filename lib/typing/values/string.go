@@ -1,6 +1,8 @@
 package values
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -44,6 +46,11 @@ func ToString(colVal any, colKind columns.Column, additionalDateFmts []string) (
 
 		return extTime.String(colKind.KindDetails.ExtendedTimeDetails.Format), nil
 	case typing.String.Kind:
+		if castedColVal, isOk := colVal.([]byte); isOk {
+			// The column was created as a string column, so we'll write the bytes the same way that Debezium encodes them by default.
+			return base64.StdEncoding.EncodeToString(castedColVal), nil
+		}
+
 		isArray := reflect.ValueOf(colVal).Kind() == reflect.Slice
 		_, isMap := colVal.(map[string]any)
 
@@ -59,7 +66,7 @@ func ToString(colVal any, colKind columns.Column, additionalDateFmts []string) (
 
 		return stringutil.Wrap(colVal, true), nil
 	case typing.Struct.Kind:
-		if colKind.KindDetails == typing.Struct {
+		if colKind.KindDetails.Kind == typing.Struct.Kind {
 			if strings.Contains(fmt.Sprint(colVal), constants.ToastUnavailableValuePlaceholder) {
 				colVal = map[string]any{
 					"key": constants.ToastUnavailableValuePlaceholder,
@@ -83,6 +90,10 @@ func ToString(colVal any, colKind columns.Column, additionalDateFmts []string) (
 
 		return string(colValBytes), nil
 	case typing.Integer.Kind:
+		if err := typing.CheckIntegerRange(colKind.KindDetails, colVal); err != nil {
+			return "", err
+		}
+
 		switch parsedVal := colVal.(type) {
 		case float64, float32:
 			// This will remove trailing zeros and print the float value as an integer, no scientific numbers.
@@ -103,6 +114,11 @@ func ToString(colVal any, colKind columns.Column, additionalDateFmts []string) (
 		}
 
 		return "", fmt.Errorf("colVal is not *decimal.Decimal type, type is: %T", colVal)
+	case typing.Bytes.Kind:
+		// Snowflake expects binary values to be hex encoded when they are loaded from a CSV.
+		if castedColVal, isOk := colVal.([]byte); isOk {
+			return hex.EncodeToString(castedColVal), nil
+		}
 	}
 
 	return fmt.Sprint(colVal), nil
@@ -105,6 +105,15 @@ func TestToString(t *testing.T) {
 		val, err = ToString(false, columns.Column{KindDetails: typing.Integer}, nil)
 		assert.NoError(t, err)
 		assert.Equal(t, "0", val)
+
+		// Integer with a declared width
+		smallIntCol := columns.Column{KindDetails: typing.NewIntegerKindDetails(typing.SmallIntegerKind)}
+		val, err = ToString(int64(32767), smallIntCol, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "32767", val)
+
+		_, err = ToString(int64(32768), smallIntCol, nil)
+		assert.ErrorContains(t, err, "value: 32768 is out of range for a 16-bit integer column [-32768, 32767]")
 	}
 	{
 		// Extended Decimal
@@ -128,4 +137,20 @@ func TestToString(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "585692791691858.25", val)
 	}
+	{
+		// Bytes
+		val, err := ToString([]byte("hello"), columns.Column{KindDetails: typing.Bytes}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "68656c6c6f", val)
+
+		// Already encoded
+		val, err = ToString("68656c6c6f", columns.Column{KindDetails: typing.Bytes}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "68656c6c6f", val)
+
+		// String column, the bytes should be base64 encoded.
+		val, err = ToString([]byte("hello"), columns.Column{KindDetails: typing.String}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "aGVsbG8=", val)
+	}
 }
@@ -0,0 +1,55 @@
+package values
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/stringutil"
+	"github.com/artie-labs/transfer/lib/typing"
+)
+
+// Truncate will make sure that string and JSON values are at most `maxBytes` long, it returns true if the value was changed.
+// Strings are truncated without splitting a multibyte character. JSON cannot be truncated without making it invalid,
+// so oversized JSON values are replaced with the exceeded value marker instead.
+func Truncate(colVal any, kindDetails typing.KindDetails, maxBytes int) (any, bool, error) {
+	if colVal == nil || maxBytes <= 0 || colVal == constants.ToastUnavailableValuePlaceholder {
+		return colVal, false, nil
+	}
+
+	switch kindDetails.Kind {
+	case typing.String.Kind:
+		strVal, isOk := colVal.(string)
+		if !isOk || len(strVal) <= maxBytes {
+			return colVal, false, nil
+		}
+
+		return stringutil.TruncateUTF8(strVal, maxBytes), true, nil
+	case typing.Struct.Kind:
+		size, err := jsonSize(colVal)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if size <= maxBytes {
+			return colVal, false, nil
+		}
+
+		return map[string]any{"key": constants.ExceededValueMarker}, true, nil
+	}
+
+	return colVal, false, nil
+}
+
+func jsonSize(colVal any) (int, error) {
+	if strVal, isOk := colVal.(string); isOk {
+		return len(strVal), nil
+	}
+
+	valBytes, err := json.Marshal(colVal)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	return len(valBytes), nil
+}
@@ -0,0 +1,72 @@
+package values
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/typing"
+)
+
+func TestTruncate(t *testing.T) {
+	{
+		// Under the limit
+		val, truncated, err := Truncate("hello", typing.String, 5)
+		assert.NoError(t, err)
+		assert.False(t, truncated)
+		assert.Equal(t, "hello", val)
+
+		val, truncated, err = Truncate(`{"foo":"bar"}`, typing.Struct, 100)
+		assert.NoError(t, err)
+		assert.False(t, truncated)
+		assert.Equal(t, `{"foo":"bar"}`, val)
+
+		val, truncated, err = Truncate(map[string]any{"foo": "bar"}, typing.Struct, 13)
+		assert.NoError(t, err)
+		assert.False(t, truncated)
+		assert.Equal(t, map[string]any{"foo": "bar"}, val)
+	}
+	{
+		// No limit
+		val, truncated, err := Truncate(strings.Repeat("a", 100), typing.String, 0)
+		assert.NoError(t, err)
+		assert.False(t, truncated)
+		assert.Equal(t, strings.Repeat("a", 100), val)
+	}
+	{
+		// Oversized strings are truncated to the limit.
+		val, truncated, err := Truncate(strings.Repeat("a", 100), typing.String, 10)
+		assert.NoError(t, err)
+		assert.True(t, truncated)
+		assert.Equal(t, strings.Repeat("a", 10), val)
+
+		// Without splitting multibyte characters, each of these is 3 bytes.
+		val, truncated, err = Truncate(strings.Repeat("日", 10), typing.String, 10)
+		assert.NoError(t, err)
+		assert.True(t, truncated)
+		assert.Equal(t, strings.Repeat("日", 3), val)
+	}
+	{
+		// Oversized JSON values are replaced with the exceeded value marker.
+		for _, value := range []any{`{"foo":"barbarbar"}`, map[string]any{"foo": "barbarbar"}} {
+			val, truncated, err := Truncate(value, typing.Struct, 10)
+			assert.NoError(t, err)
+			assert.True(t, truncated)
+			assert.Equal(t, map[string]any{"key": constants.ExceededValueMarker}, val)
+		}
+	}
+	{
+		// Other types and TOAST values are not touched.
+		val, truncated, err := Truncate(1234567890, typing.Integer, 2)
+		assert.NoError(t, err)
+		assert.False(t, truncated)
+		assert.Equal(t, 1234567890, val)
+
+		val, truncated, err = Truncate(constants.ToastUnavailableValuePlaceholder, typing.String, 2)
+		assert.NoError(t, err)
+		assert.False(t, truncated)
+		assert.Equal(t, constants.ToastUnavailableValuePlaceholder, val)
+	}
+}
@@ -10,8 +10,8 @@ import (
 
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
-	"github.com/artie-labs/transfer/lib/destination"
 	"github.com/artie-labs/transfer/lib/destination/utils"
+	"github.com/artie-labs/transfer/lib/health"
 	"github.com/artie-labs/transfer/lib/logger"
 	"github.com/artie-labs/transfer/lib/telemetry/metrics"
 	"github.com/artie-labs/transfer/models"
@@ -19,7 +19,19 @@ import (
 	"github.com/artie-labs/transfer/processes/pool"
 )
 
+// snapshotFlushAttempts is how many times we'll try the final flush in snapshot mode before exiting with an error.
+const snapshotFlushAttempts = 5
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == config.ValidateCommand {
+		if err := config.RunValidate(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Config is invalid: %v\n", err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
 	// Parse args into settings
 	settings, err := config.LoadSettings(os.Args, true)
 	if err != nil {
@@ -27,7 +39,7 @@ func main() {
 	}
 
 	// Initialize default logger
-	_logger, cleanUpHandlers := logger.NewLogger(settings.VerboseLogging, settings.Config.Reporting.Sentry)
+	_logger, cleanUpHandlers := logger.NewLogger(settings.VerboseLogging, settings.Config.Logging, settings.Config.Reporting.Sentry)
 	slog.SetDefault(_logger)
 
 	defer cleanUpHandlers()
@@ -36,40 +48,56 @@ func main() {
 		slog.Int("flushIntervalSeconds", settings.Config.FlushIntervalSeconds),
 		slog.Uint64("bufferPoolSize", uint64(settings.Config.BufferRows)),
 		slog.Int("flushPoolSizeKb", settings.Config.FlushSizeKb),
+		slog.Int("idleFlushSeconds", settings.Config.IdleFlushSeconds),
 	)
 
 	ctx := context.Background()
 
 	// Loading telemetry
 	metricsClient := metrics.LoadExporter(settings.Config)
-	var dest destination.Baseline
-	if utils.IsOutputBaseline(settings.Config) {
-		dest = utils.Baseline(settings.Config)
-	} else {
-		dest = utils.DataWarehouse(settings.Config, nil)
+	dest := utils.Destination(settings.Config)
+
+	consumerOpts, err := consumer.NewOptions(ctx, settings.Config)
+	if err != nil {
+		logger.Fatal("Failed to create the consumer options", slog.Any("err", err))
 	}
 
+	defer consumerOpts.Close()
+
 	inMemDB := models.NewMemoryDB()
+	if settings.Config.HealthCheck != nil {
+		go health.StartServer(ctx, *settings.Config.HealthCheck)
+	}
 
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		pool.StartPool(ctx, inMemDB, dest, metricsClient, time.Duration(settings.Config.FlushIntervalSeconds)*time.Second)
-	}()
+	go pool.StartPool(ctx, inMemDB, dest, metricsClient, consumerOpts, time.Duration(settings.Config.FlushIntervalSeconds)*time.Second, settings.Config.MinFlushInterval())
+	if settings.Config.IdleFlushSeconds > 0 {
+		go pool.StartIdlePool(ctx, inMemDB, dest, metricsClient, consumerOpts, time.Duration(settings.Config.IdleFlushSeconds)*time.Second)
+	}
 
+	var wg sync.WaitGroup
 	wg.Add(1)
 	go func(ctx context.Context) {
 		defer wg.Done()
 		switch settings.Config.Queue {
 		case constants.Kafka:
-			consumer.StartConsumer(ctx, settings.Config, inMemDB, dest, metricsClient)
+			consumer.StartConsumer(ctx, settings.Config, inMemDB, dest, metricsClient, consumerOpts)
 		case constants.PubSub:
-			consumer.StartSubscriber(ctx, settings.Config, inMemDB, dest, metricsClient)
+			consumer.StartSubscriber(ctx, settings.Config, inMemDB, dest, metricsClient, consumerOpts)
+		case constants.NATS:
+			consumer.StartJetStreamConsumer(ctx, settings.Config, inMemDB, dest, metricsClient, consumerOpts)
 		default:
 			logger.Fatal(fmt.Sprintf("Message queue: %s not supported", settings.Config.Queue))
 		}
 	}(ctx)
 
+	// The consumer will only return in snapshot mode, once the backlog has been consumed.
 	wg.Wait()
+	if settings.Config.Mode == config.Snapshot {
+		// Exiting non-zero so that the rows that were not written are not mistaken for a completed snapshot.
+		if err = consumer.FlushSnapshot(ctx, inMemDB, dest, metricsClient, consumerOpts, snapshotFlushAttempts); err != nil {
+			logger.Fatal("Failed to perform the final flush", slog.Any("err", err))
+		}
+
+		slog.Info("Snapshot is complete, exiting...")
+	}
 }
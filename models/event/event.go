@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -13,12 +14,17 @@ import (
 	"github.com/artie-labs/transfer/lib/cdc"
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/jsonutil"
 	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/optimization"
 	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/sql"
 	"github.com/artie-labs/transfer/lib/stringutil"
 	"github.com/artie-labs/transfer/lib/typing"
 	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
+	"github.com/artie-labs/transfer/lib/typing/ext"
+	"github.com/artie-labs/transfer/lib/typing/values"
 	"github.com/artie-labs/transfer/models"
 )
 
@@ -31,12 +37,48 @@ type Event struct {
 	Columns        *columns.Columns
 	ExecutionTime  time.Time // When the SQL command was executed
 	Deleted        bool
+	// Partial is set when Data only contains the columns that have changed.
+	Partial bool
 
 	mode config.Mode
+	// truncatedColumns are the columns that had their values truncated when the event was saved.
+	truncatedColumns []string
+	// dropped is set if the row was not buffered because a newer row for the same primary key has already been buffered.
+	dropped bool
 }
 
-func ToMemoryEvent(event cdc.Event, pkMap map[string]any, tc *kafkalib.TopicConfig, cfgMode config.Mode) Event {
+// TruncatedColumns returns the columns that had their values truncated because they were over the max value length.
+func (e *Event) TruncatedColumns() []string {
+	return e.truncatedColumns
+}
+
+// Dropped returns true if the row was not buffered when the event was saved, since a newer row for the same primary key has already been buffered.
+func (e *Event) Dropped() bool {
+	return e.dropped
+}
+
+func ToMemoryEvent(event cdc.Event, pkMap map[string]any, tc *kafkalib.TopicConfig, cfgMode config.Mode) (Event, error) {
+	evtData := event.GetData(pkMap, tc)
+	optionalSchema := event.GetOptionalSchema()
+	if tc.DecimalToFloat {
+		decimalsToFloats(evtData, optionalSchema)
+	}
+
+	bytesToNative(optionalSchema, tc.BytesEncoding)
+
 	cols := event.GetColumns()
+	if len(tc.BooleanColumns) > 0 {
+		intsToBooleans(tc, evtData, optionalSchema, cols)
+	}
+
+	if len(tc.PrimaryKeyOverride) > 0 {
+		var err error
+		pkMap, err = primaryKeysFromOverride(tc.PrimaryKeyOverride, evtData, optionalSchema)
+		if err != nil {
+			return Event{}, err
+		}
+	}
+
 	// Now iterate over pkMap and tag each column that is a primary key
 	if cols != nil {
 		for primaryKey := range pkMap {
@@ -46,7 +88,6 @@ func ToMemoryEvent(event cdc.Event, pkMap map[string]any, tc *kafkalib.TopicConf
 		}
 	}
 
-	evtData := event.GetData(pkMap, tc)
 	tblName := stringutil.Override(event.GetTableName(), tc.TableName)
 	if cfgMode == config.History {
 		if !strings.HasSuffix(tblName, constants.HistoryModeSuffix) {
@@ -61,18 +102,143 @@ func ToMemoryEvent(event cdc.Event, pkMap map[string]any, tc *kafkalib.TopicConf
 		delete(evtData, constants.DeleteColumnMarker)
 	}
 
+	var partial bool
+	if partialEvent, isOk := event.(cdc.PartialEvent); isOk {
+		partial = partialEvent.IsPartial()
+	}
+
 	return Event{
 		mode:           cfgMode,
 		Table:          tblName,
 		PrimaryKeyMap:  pkMap,
 		ExecutionTime:  event.GetExecutionTime(),
-		OptionalSchema: event.GetOptionalSchema(),
+		OptionalSchema: optionalSchema,
 		Columns:        cols,
 		Data:           evtData,
 		Deleted:        event.DeletePayload(),
+		Partial:        partial,
+	}, nil
+}
+
+// decimalsToFloats will convert decimal columns and values into floats, this is done in place.
+func decimalsToFloats(data map[string]any, optionalSchema map[string]typing.KindDetails) {
+	for key, kindDetails := range optionalSchema {
+		if kindDetails.Kind == typing.EDecimal.Kind {
+			optionalSchema[key] = typing.Float
+		}
+	}
+
+	for key, value := range data {
+		if decimalValue, isOk := value.(*decimal.Decimal); isOk {
+			data[key] = decimalValue.Float64()
+		}
 	}
 }
 
+// bytesToNative will only keep the fields that are declared as bytes in the schema as native binary columns if the topic uses the native bytes encoding, this is done in place.
+// Otherwise the values are encoded as strings, so the columns are typed as strings.
+func bytesToNative(optionalSchema map[string]typing.KindDetails, encoding kafkalib.BytesEncoding) {
+	if encoding == kafkalib.BytesEncodingNative {
+		return
+	}
+
+	for key, kindDetails := range optionalSchema {
+		if kindDetails.Kind == typing.Bytes.Kind {
+			optionalSchema[key] = typing.String
+		}
+	}
+}
+
+// intsToBooleans will convert the integer columns and values that have been configured as boolean columns into booleans, this is done in place.
+func intsToBooleans(tc *kafkalib.TopicConfig, data map[string]any, optionalSchema map[string]typing.KindDetails, cols *columns.Columns) {
+	for key := range optionalSchema {
+		if tc.IsBooleanColumn(key) {
+			optionalSchema[key] = typing.Boolean
+		}
+	}
+
+	for key, value := range data {
+		if !tc.IsBooleanColumn(key) {
+			continue
+		}
+
+		if boolValue, isOk := intToBoolean(value); isOk {
+			data[key] = boolValue
+		}
+	}
+
+	if cols == nil {
+		return
+	}
+
+	for _, col := range cols.GetColumns() {
+		if !tc.IsBooleanColumn(col.RawName()) {
+			continue
+		}
+
+		if boolValue, isOk := intToBoolean(col.RawDefaultValue()); isOk {
+			col.SetDefaultValue(boolValue)
+			cols.UpdateColumn(col)
+		}
+	}
+}
+
+// intToBoolean will return false for 0 and true for any other integer, it will return false for `isOk` if the value is not an integer.
+func intToBoolean(value any) (bool, bool) {
+	switch castedValue := value.(type) {
+	case int:
+		return castedValue != 0, true
+	case int16:
+		return castedValue != 0, true
+	case int32:
+		return castedValue != 0, true
+	case int64:
+		return castedValue != 0, true
+	case float64:
+		// JSON numbers will be decoded as float64 if there is no schema.
+		if castedValue == float64(int64(castedValue)) {
+			return castedValue != 0, true
+		}
+	}
+
+	return false, false
+}
+
+// applyColumnTypeOverrides will replace the types of the in-memory columns that have been overridden in the topic config, this is done in place.
+func applyColumnTypeOverrides(cols *columns.Columns, tc *kafkalib.TopicConfig) {
+	if len(tc.ColumnTypeOverrides) == 0 {
+		return
+	}
+
+	for _, col := range cols.GetColumns() {
+		if kindDetails, isOk := tc.ColumnTypeOverride(col.RawName()); isOk {
+			col.KindDetails = kindDetails
+			cols.UpdateColumn(col)
+		}
+	}
+}
+
+// primaryKeysFromOverride will build the primary key map from the event data, the override columns must exist in the schema (if we have one) and in the data.
+func primaryKeysFromOverride(override []string, data map[string]any, optionalSchema map[string]typing.KindDetails) (map[string]any, error) {
+	pkMap := make(map[string]any)
+	for _, col := range override {
+		if len(optionalSchema) > 0 {
+			if _, isOk := optionalSchema[col]; !isOk {
+				return nil, fmt.Errorf("primary key override column %q does not exist in the schema", col)
+			}
+		}
+
+		val, isOk := data[col]
+		if !isOk {
+			return nil, fmt.Errorf("primary key override column %q does not exist in the event", col)
+		}
+
+		pkMap[col] = val
+	}
+
+	return pkMap, nil
+}
+
 func (e *Event) IsValid() bool {
 	// Does it have a PK or table set?
 	if array.Empty([]string{e.Table}) {
@@ -109,6 +275,23 @@ func (e *Event) PrimaryKeys() []string {
 	return keys
 }
 
+// NullPrimaryKeys returns the primary keys that are null, rows with a null primary key cannot be merged correctly.
+func (e *Event) NullPrimaryKeys() []string {
+	var nullKeys []string
+	for _, pk := range e.PrimaryKeys() {
+		value, isOk := e.Data[pk]
+		if !isOk {
+			value = e.PrimaryKeyMap[pk]
+		}
+
+		if value == nil {
+			nullKeys = append(nullKeys, pk)
+		}
+	}
+
+	return nullKeys
+}
+
 // PrimaryKeyValue - as per above, this needs to return a deterministic k/v string.
 func (e *Event) PrimaryKeyValue() string {
 	var key string
@@ -119,6 +302,112 @@ func (e *Event) PrimaryKeyValue() string {
 	return key
 }
 
+// renameColumns will rename the columns within the event's data, primary keys, schema and columns according to `renames`.
+// Column names are compared after they have been lowercased and had their spaces escaped, which is what we do in Save.
+func (e *Event) renameColumns(renames map[string]string) error {
+	if len(renames) == 0 {
+		return nil
+	}
+
+	escapedRenames := make(map[string]string)
+	for source, destination := range renames {
+		escapedRenames[columns.EscapeName(source)] = columns.EscapeName(destination)
+	}
+
+	renameKeys := func(values map[string]any) (map[string]any, error) {
+		renamed := make(map[string]any)
+		// Columns that are not renamed will keep their original key, so we are tracking the escaped names to detect collisions.
+		escapedKeys := make(map[string]bool)
+		for key, value := range values {
+			newKey := key
+			escapedKey := columns.EscapeName(key)
+			if destination, isOk := escapedRenames[escapedKey]; isOk {
+				newKey = destination
+				escapedKey = destination
+			}
+
+			if escapedKeys[escapedKey] {
+				return nil, fmt.Errorf("column renames would result in a duplicate column: %q", newKey)
+			}
+
+			escapedKeys[escapedKey] = true
+			renamed[newKey] = value
+		}
+
+		return renamed, nil
+	}
+
+	data, err := renameKeys(e.Data)
+	if err != nil {
+		return err
+	}
+
+	pkMap, err := renameKeys(e.PrimaryKeyMap)
+	if err != nil {
+		return err
+	}
+
+	if e.OptionalSchema != nil {
+		optionalSchema := make(map[string]typing.KindDetails)
+		for key, kindDetails := range e.OptionalSchema {
+			escapedKey := columns.EscapeName(key)
+			if newKey, isOk := escapedRenames[escapedKey]; isOk {
+				optionalSchema[newKey] = kindDetails
+			} else {
+				optionalSchema[key] = kindDetails
+			}
+		}
+
+		e.OptionalSchema = optionalSchema
+	}
+
+	if e.Columns != nil {
+		// Renames are applied in a deterministic order, so that a collision always fails the same way.
+		sources := make([]string, 0, len(escapedRenames))
+		for source := range escapedRenames {
+			sources = append(sources, source)
+		}
+
+		slices.Sort(sources)
+		for _, source := range sources {
+			if err = e.Columns.RenameColumn(source, escapedRenames[source]); err != nil {
+				return err
+			}
+		}
+	}
+
+	e.Data = data
+	e.PrimaryKeyMap = pkMap
+	return nil
+}
+
+// truncatedColumnRenames returns a map of source column to truncated column for the columns that are longer than the destination's max identifier length.
+func truncatedColumnRenames(destKind constants.DestinationKind, cols []string) map[string]string {
+	renames := make(map[string]string)
+	for _, col := range cols {
+		escapedCol := columns.EscapeName(col)
+		if truncatedCol := sql.TruncateIdentifier(destKind, escapedCol); truncatedCol != escapedCol {
+			renames[col] = truncatedCol
+		}
+	}
+
+	return renames
+}
+
+// columnNames returns the names of the columns within the event's data and schema.
+func (e *Event) columnNames() []string {
+	var cols []string
+	for col := range e.Data {
+		cols = append(cols, col)
+	}
+
+	for col := range e.OptionalSchema {
+		cols = append(cols, col)
+	}
+
+	return cols
+}
+
 // Save will save the event into our in memory event
 // It will return (flush bool, flushReason string, err error)
 func (e *Event) Save(cfg config.Config, inMemDB *models.DatabaseData, topicConfig *kafkalib.TopicConfig, message artie.Message) (bool, string, error) {
@@ -130,6 +419,22 @@ func (e *Event) Save(cfg config.Config, inMemDB *models.DatabaseData, topicConfi
 		return false, "", errors.New("event not valid")
 	}
 
+	// Renames need to be applied before we infer any column types below.
+	if err := e.renameColumns(topicConfig.ColumnRenames); err != nil {
+		return false, "", fmt.Errorf("failed to rename columns: %w", err)
+	}
+
+	if cfg.SharedDestinationConfig.SanitizeColumnNames {
+		sanitizer := inMemDB.ColumnSanitizer(topicConfig.Database, topicConfig.Schema, e.Table)
+		if err := e.renameColumns(sanitizer.Renames(cfg.Output, e.columnNames())); err != nil {
+			return false, "", fmt.Errorf("failed to sanitize columns: %w", err)
+		}
+	}
+
+	if err := e.renameColumns(truncatedColumnRenames(cfg.Output, e.columnNames())); err != nil {
+		return false, "", fmt.Errorf("failed to truncate column names: %w", err)
+	}
+
 	// Does the table exist?
 	td := inMemDB.GetOrCreateTableData(e.Table)
 	td.Lock()
@@ -140,7 +445,8 @@ func (e *Event) Save(cfg config.Config, inMemDB *models.DatabaseData, topicConfi
 			cols = e.Columns
 		}
 
-		td.SetTableData(optimization.NewTableData(cols, cfg.Mode, e.PrimaryKeys(), *topicConfig, e.Table))
+		td.SetTableData(optimization.NewTableData(cols, cfg.Mode.TableMode(), e.PrimaryKeys(), *topicConfig, e.Table))
+		td.SetSizeAccounting(cfg.FlushSizeAccounting)
 	} else {
 		if e.Columns != nil {
 			// Iterate over this again just in case.
@@ -151,9 +457,29 @@ func (e *Event) Save(cfg config.Config, inMemDB *models.DatabaseData, topicConfi
 	}
 
 	typingSettings := cfg.SharedTransferConfig.TypingSettings
+	if topicConfig.TreatStringsAsJSON {
+		typingSettings.TreatStringsAsJSON = true
+	}
 
 	// Table columns
 	inMemoryColumns := td.ReadOnlyInMemoryCols()
+	applyColumnTypeOverrides(inMemoryColumns, topicConfig)
+	if e.Partial && !topicConfig.PartialUpdate && cfg.Mode != config.History {
+		// Columns that are not in a partial event have not changed, so we'll mark them as unavailable.
+		// This will then preserve the existing value, the same way we handle TOAST columns.
+		// If the topic has partial updates enabled, the missing columns are already left out of the merge.
+		presentCols := make(map[string]bool)
+		for col := range e.Data {
+			_, escapedCol := stringutil.EscapeSpaces(strings.ToLower(col))
+			presentCols[escapedCol] = true
+		}
+
+		for _, col := range inMemoryColumns.GetColumns() {
+			if !presentCols[col.RawName()] {
+				e.Data[col.RawName()] = constants.ToastUnavailableValuePlaceholder
+			}
+		}
+	}
 	// Update col if necessary
 	sanitizedData := make(map[string]any)
 	for _col, val := range e.Data {
@@ -190,9 +516,38 @@ func (e *Event) Save(cfg config.Config, inMemDB *models.DatabaseData, topicConfi
 			})
 		} else {
 			retrievedColumn, isOk := inMemoryColumns.GetColumn(newColName)
+			declaredKind := retrievedColumn.KindDetails
+			if !isOk {
+				declaredKind = e.OptionalSchema[_col]
+				if kindDetails, isOverridden := topicConfig.ColumnTypeOverride(newColName); isOverridden {
+					declaredKind = kindDetails
+				}
+			}
+
+			var err error
+			val, err = applyInvalidDatePolicy(typingSettings, declaredKind, val)
+			if err != nil {
+				return false, "", fmt.Errorf("failed to parse column %q: %w", newColName, err)
+			}
+
+			val, err = applyInvalidJSONPolicy(typingSettings, declaredKind, val)
+			if err != nil {
+				return false, "", fmt.Errorf("failed to parse column %q: %w", newColName, err)
+			}
+
+			val, err = applyDecimalScaleOverflow(typingSettings, declaredKind, val)
+			if err != nil {
+				return false, "", fmt.Errorf("failed to parse column %q: %w", newColName, err)
+			}
+
 			if !isOk {
 				// This would only happen if the columns did not get passed in initially.
-				inMemoryColumns.AddColumn(columns.NewColumn(newColName, typing.ParseValue(typingSettings, _col, e.OptionalSchema, val)))
+				kindDetails, isOverridden := topicConfig.ColumnTypeOverride(newColName)
+				if !isOverridden {
+					kindDetails = typing.ParseValue(typingSettings, _col, e.OptionalSchema, val)
+				}
+
+				inMemoryColumns.AddColumn(columns.NewColumn(newColName, kindDetails))
 			} else {
 				if retrievedColumn.KindDetails == typing.Invalid {
 					// If colType is Invalid, let's see if we can update it to a better type
@@ -205,6 +560,20 @@ func (e *Event) Save(cfg config.Config, inMemDB *models.DatabaseData, topicConfi
 					}
 				}
 			}
+
+			// Primary keys are never truncated, otherwise rows could no longer be matched in the destination.
+			_, isPrimaryKey := e.PrimaryKeyMap[_col]
+			if col, isOk := inMemoryColumns.GetColumn(newColName); isOk && !isPrimaryKey {
+				var truncated bool
+				val, truncated, err = values.Truncate(val, col.KindDetails, topicConfig.MaxValueLength(newColName, cfg.SharedDestinationConfig.MaxValueLength))
+				if err != nil {
+					return false, "", fmt.Errorf("failed to truncate column %q: %w", newColName, err)
+				}
+
+				if truncated {
+					e.truncatedColumns = append(e.truncatedColumns, newColName)
+				}
+			}
 		}
 
 		sanitizedData[newColName] = val
@@ -215,7 +584,21 @@ func (e *Event) Save(cfg config.Config, inMemDB *models.DatabaseData, topicConfi
 
 	// Swap out sanitizedData <> data.
 	e.Data = sanitizedData
-	td.InsertRow(e.PrimaryKeyValue(), e.Data, e.Deleted)
+	prevSize := td.ApproxSize()
+	position := optimization.RowPosition{CDCTs: e.ExecutionTime, Partition: message.Partition()}
+	if offset, isOk := message.Offset(); isOk {
+		position.Offset = &offset
+	}
+
+	if !td.InsertRowAtPosition(e.PrimaryKeyValue(), e.Data, e.Deleted, position) {
+		e.dropped = true
+		slog.Debug("Skipping row since a newer row for the same primary key has already been buffered",
+			slog.String("tableName", e.Table),
+			slog.Time("executionTime", e.ExecutionTime),
+		)
+	}
+
+	inMemDB.AddApproxSize(td.ApproxSize() - prevSize)
 	// If the message is Kafka, then we only need the latest one
 	// If it's pubsub, we will store all of them in memory. This is because GCP pub/sub REQUIRES us to ack every single message
 	if message.Kind() == artie.Kafka {
@@ -224,7 +607,85 @@ func (e *Event) Save(cfg config.Config, inMemDB *models.DatabaseData, topicConfi
 		td.PartitionsToLastMessage[message.Partition()] = append(td.PartitionsToLastMessage[message.Partition()], message)
 	}
 
+	if offset, isOk := message.Offset(); isOk {
+		td.RecordOffset(message.Partition(), offset)
+	}
+
 	td.LatestCDCTs = e.ExecutionTime
 	flush, flushReason := td.ShouldFlush(cfg)
 	return flush, flushReason, nil
 }
+
+// applyInvalidDatePolicy will replace zero dates (e.g. `0000-00-00`) and values that cannot be parsed for date columns
+// based on the [typing.Settings.InvalidDatePolicy]. If no policy is set, the value is returned as-is.
+// Values for columns that have been declared as a non-date type are also returned as-is.
+func applyInvalidDatePolicy(settings typing.Settings, declaredKind typing.KindDetails, val any) (any, error) {
+	if settings.InvalidDatePolicy == "" {
+		return val, nil
+	}
+
+	strVal, isOk := val.(string)
+	if !isOk {
+		return val, nil
+	}
+
+	var kindType ext.ExtendedTimeKindType
+	if declaredKind.Kind == typing.ETime.Kind && declaredKind.ExtendedTimeDetails != nil {
+		if !ext.IsZeroDate(strVal) {
+			if _, err := ext.ParseExtendedDateTime(strVal, settings.AdditionalDateFormats); err == nil {
+				return val, nil
+			}
+		}
+
+		kindType = declaredKind.ExtendedTimeDetails.Type
+	} else if (declaredKind.Kind == "" || declaredKind.Kind == typing.Invalid.Kind) && ext.IsZeroDate(strVal) {
+		kindType = ext.DateKindType
+		if len(strVal) > len("0000-00-00") {
+			kindType = ext.DateTimeKindType
+		}
+	} else {
+		return val, nil
+	}
+
+	extTime, err := settings.InvalidDatePolicy.Handle(strVal, kindType)
+	if err != nil {
+		return nil, err
+	}
+
+	if extTime == nil {
+		// Return an untyped nil so this is treated as a NULL value.
+		return nil, nil
+	}
+
+	return extTime, nil
+}
+
+// applyInvalidJSONPolicy will make sure that string values for JSON columns are valid JSON based on the [typing.Settings.InvalidJSONPolicy].
+// If no policy is set, the value is returned as-is.
+func applyInvalidJSONPolicy(settings typing.Settings, declaredKind typing.KindDetails, val any) (any, error) {
+	if settings.InvalidJSONPolicy == "" || declaredKind.Kind != typing.Struct.Kind {
+		return val, nil
+	}
+
+	if _, isOk := val.(string); !isOk {
+		return val, nil
+	}
+
+	return jsonutil.ToJSONString(val, settings.InvalidJSONPolicy)
+}
+
+// applyDecimalScaleOverflow will round, truncate or reject decimal values that do not fit within the declared scale based on the [typing.Settings.DecimalScaleOverflow].
+// Values for columns without a declared scale are returned as-is.
+func applyDecimalScaleOverflow(settings typing.Settings, declaredKind typing.KindDetails, val any) (any, error) {
+	if settings.DecimalScaleOverflow == "" || declaredKind.Kind != typing.EDecimal.Kind || declaredKind.ExtendedDecimalDetails == nil {
+		return val, nil
+	}
+
+	precision := declaredKind.ExtendedDecimalDetails.Precision()
+	if precision != nil && *precision == decimal.PrecisionNotSpecified {
+		// Variable scaled decimals do not have a scale to overflow.
+		return val, nil
+	}
+
+	return settings.DecimalScaleOverflow.Apply(val, precision, declaredKind.ExtendedDecimalDetails.Scale())
+}
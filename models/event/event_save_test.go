@@ -3,13 +3,20 @@ package event
 import (
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/artie-labs/transfer/lib/typing/columns"
 
 	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/jsonutil"
 	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/sql"
 	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
 	"github.com/artie-labs/transfer/lib/typing/ext"
 	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/assert"
@@ -129,11 +136,13 @@ func (e *EventsTestSuite) TestEventSaveOptionalSchema() {
 			"created_at_date_no_schema":  "2023-01-01",
 			"json_object_string":         `{"foo": "bar"}`,
 			"json_object_no_schema":      `{"foo": "bar"}`,
+			"json_object_declared":       `{"foo": "bar"}`,
 		},
 		OptionalSchema: map[string]typing.KindDetails{
 			// Explicitly casting this as a string.
 			"created_at_date_string": typing.String,
 			"json_object_string":     typing.String,
+			"json_object_declared":   typing.Struct,
 		},
 	}
 
@@ -154,9 +163,408 @@ func (e *EventsTestSuite) TestEventSaveOptionalSchema() {
 	assert.True(e.T(), isOk)
 	assert.Equal(e.T(), typing.String, column.KindDetails)
 
+	// JSON looking strings are kept as strings unless `TreatStringsAsJSON` is enabled.
 	column, isOk = td.ReadOnlyInMemoryCols().GetColumn("json_object_no_schema")
 	assert.True(e.T(), isOk)
+	assert.Equal(e.T(), typing.String, column.KindDetails)
+
+	column, isOk = td.ReadOnlyInMemoryCols().GetColumn("json_object_declared")
+	assert.True(e.T(), isOk)
+	assert.Equal(e.T(), typing.Struct, column.KindDetails)
+}
+
+func (e *EventsTestSuite) TestEventSave_TreatStringsAsJSON() {
+	event := Event{
+		Table:         "bar",
+		PrimaryKeyMap: map[string]any{"id": "123"},
+		Data: map[string]any{
+			constants.DeleteColumnMarker: true,
+			"note":                       "{}",
+			"json_object_string":         `{"foo": "bar"}`,
+		},
+		OptionalSchema: map[string]typing.KindDetails{
+			"json_object_string": typing.String,
+		},
+	}
+
+	tc := *topicConfig
+	tc.TreatStringsAsJSON = true
+
+	kafkaMsg := kafka.Message{}
+	_, _, err := event.Save(e.cfg, e.db, &tc, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+	assert.NoError(e.T(), err)
+
+	td := e.db.GetOrCreateTableData("bar")
+	column, isOk := td.ReadOnlyInMemoryCols().GetColumn("note")
+	assert.True(e.T(), isOk)
 	assert.Equal(e.T(), typing.Struct, column.KindDetails)
+
+	// The optional schema should still take precedence.
+	column, isOk = td.ReadOnlyInMemoryCols().GetColumn("json_object_string")
+	assert.True(e.T(), isOk)
+	assert.Equal(e.T(), typing.String, column.KindDetails)
+}
+
+func (e *EventsTestSuite) TestEventSaveInvalidDatePolicy() {
+	newEvent := func(table string) Event {
+		var cols columns.Columns
+		cols.AddColumn(columns.NewColumn("birthday", typing.NewKindDetailsFromTemplate(typing.ETime, ext.DateKindType)))
+		return Event{
+			Table:         table,
+			Columns:       &cols,
+			PrimaryKeyMap: map[string]any{"id": "123"},
+			Data: map[string]any{
+				constants.DeleteColumnMarker: false,
+				"id":                         "123",
+				"zero_date":                  "0000-00-00",
+				"zero_datetime":              "0000-00-00 00:00:00",
+				"valid_date":                 "2023-01-02",
+				"birthday":                   "2023-02-30",
+			},
+		}
+	}
+
+	kafkaMsg := kafka.Message{}
+	{
+		// No policy, the values are passed through.
+		event := newEvent("no_policy")
+		_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), "0000-00-00", event.Data["zero_date"])
+		assert.Equal(e.T(), "0000-00-00 00:00:00", event.Data["zero_datetime"])
+		assert.Equal(e.T(), "2023-01-02", event.Data["valid_date"])
+		assert.Equal(e.T(), "2023-02-30", event.Data["birthday"])
+
+		column, isOk := e.db.GetOrCreateTableData("no_policy").ReadOnlyInMemoryCols().GetColumn("zero_date")
+		assert.True(e.T(), isOk)
+		assert.Equal(e.T(), typing.String, column.KindDetails)
+	}
+	{
+		// Null
+		e.cfg.SharedTransferConfig.TypingSettings.InvalidDatePolicy = ext.InvalidDatePolicyNull
+		event := newEvent("null_policy")
+		_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.Nil(e.T(), event.Data["zero_date"])
+		assert.Nil(e.T(), event.Data["zero_datetime"])
+		assert.Equal(e.T(), "2023-01-02", event.Data["valid_date"])
+		assert.Nil(e.T(), event.Data["birthday"])
+
+		inMemCols := e.db.GetOrCreateTableData("null_policy").ReadOnlyInMemoryCols()
+		column, isOk := inMemCols.GetColumn("zero_date")
+		assert.True(e.T(), isOk)
+		assert.Equal(e.T(), typing.Invalid, column.KindDetails)
+
+		column, isOk = inMemCols.GetColumn("valid_date")
+		assert.True(e.T(), isOk)
+		assert.Equal(e.T(), ext.DateKindType, column.KindDetails.ExtendedTimeDetails.Type)
+	}
+	{
+		// Epoch
+		e.cfg.SharedTransferConfig.TypingSettings.InvalidDatePolicy = ext.InvalidDatePolicyEpoch
+		event := newEvent("epoch_policy")
+		_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), "1970-01-01", event.Data["zero_date"].(*ext.ExtendedTime).String(""))
+		assert.Equal(e.T(), "1970-01-01T00:00:00Z", event.Data["zero_datetime"].(*ext.ExtendedTime).String(""))
+		assert.Equal(e.T(), "2023-01-02", event.Data["valid_date"])
+		assert.Equal(e.T(), "1970-01-01", event.Data["birthday"].(*ext.ExtendedTime).String(""))
+
+		inMemCols := e.db.GetOrCreateTableData("epoch_policy").ReadOnlyInMemoryCols()
+		column, isOk := inMemCols.GetColumn("zero_date")
+		assert.True(e.T(), isOk)
+		assert.Equal(e.T(), ext.DateKindType, column.KindDetails.ExtendedTimeDetails.Type)
+
+		column, isOk = inMemCols.GetColumn("zero_datetime")
+		assert.True(e.T(), isOk)
+		assert.Equal(e.T(), ext.DateTimeKindType, column.KindDetails.ExtendedTimeDetails.Type)
+	}
+	{
+		// Error
+		e.cfg.SharedTransferConfig.TypingSettings.InvalidDatePolicy = ext.InvalidDatePolicyError
+		for _, value := range []string{"0000-00-00", "0000-00-00 00:00:00"} {
+			event := newEvent("error_policy")
+			delete(event.Data, "zero_date")
+			delete(event.Data, "birthday")
+			event.Data["zero_datetime"] = value
+			_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+			assert.ErrorContains(e.T(), err, fmt.Sprintf(`failed to parse column "zero_datetime": invalid date: %q`, value))
+		}
+
+		// Unparseable values for date columns.
+		event := newEvent("error_policy")
+		delete(event.Data, "zero_date")
+		delete(event.Data, "zero_datetime")
+		_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.ErrorContains(e.T(), err, `failed to parse column "birthday": invalid date: "2023-02-30"`)
+
+		// Valid dates are not affected.
+		event = newEvent("error_policy")
+		delete(event.Data, "zero_date")
+		delete(event.Data, "zero_datetime")
+		delete(event.Data, "birthday")
+		_, _, err = event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), "2023-01-02", event.Data["valid_date"])
+	}
+}
+
+func (e *EventsTestSuite) TestEventSaveInvalidJSONPolicy() {
+	newEvent := func(table string, payload string) Event {
+		return Event{
+			Table:         table,
+			PrimaryKeyMap: map[string]any{"id": "123"},
+			Data: map[string]any{
+				constants.DeleteColumnMarker: false,
+				"id":                         "123",
+				"array":                      `[1, "two"]`,
+				"null":                       "null",
+				"payload":                    payload,
+			},
+			OptionalSchema: map[string]typing.KindDetails{
+				"array":   typing.Struct,
+				"null":    typing.Struct,
+				"payload": typing.Struct,
+			},
+		}
+	}
+
+	kafkaMsg := kafka.Message{}
+	{
+		// No policy, the values are passed through.
+		event := newEvent("no_policy", `{"foo": "bar"`)
+		_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), `{"foo": "bar"`, event.Data["payload"])
+	}
+	{
+		// String
+		e.cfg.SharedTransferConfig.TypingSettings.InvalidJSONPolicy = jsonutil.InvalidJSONPolicyString
+		event := newEvent("string_policy", `{"foo": "bar"`)
+		_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), `[1, "two"]`, event.Data["array"])
+		assert.Equal(e.T(), "null", event.Data["null"])
+		assert.Equal(e.T(), `"{\"foo\": \"bar\""`, event.Data["payload"])
+	}
+	{
+		// Error
+		e.cfg.SharedTransferConfig.TypingSettings.InvalidJSONPolicy = jsonutil.InvalidJSONPolicyError
+		event := newEvent("error_policy", `{"foo": "bar"`)
+		_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.ErrorContains(e.T(), err, `failed to parse column "payload": invalid JSON value: "{\"foo\": \"bar\""`)
+
+		// Valid JSON is not affected.
+		event = newEvent("error_policy", `{"foo": "bar"}`)
+		_, _, err = event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), `{"foo": "bar"}`, event.Data["payload"])
+		assert.Equal(e.T(), `[1, "two"]`, event.Data["array"])
+	}
+}
+
+func (e *EventsTestSuite) TestEventSaveDecimalScaleOverflow() {
+	newEvent := func(table string, amount any) Event {
+		return Event{
+			Table:         table,
+			PrimaryKeyMap: map[string]any{"id": "123"},
+			Data: map[string]any{
+				constants.DeleteColumnMarker: false,
+				"id":                         "123",
+				"amount":                     amount,
+				"rate":                       "0.12345",
+			},
+			OptionalSchema: map[string]typing.KindDetails{
+				"amount": typing.ParseNumeric("numeric", "numeric(10, 2)"),
+				"rate":   typing.String,
+			},
+		}
+	}
+
+	kafkaMsg := kafka.Message{}
+	{
+		// No policy, the value is passed through.
+		event := newEvent("no_policy", "1.2345")
+		_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), "1.2345", event.Data["amount"])
+	}
+	{
+		// Round
+		e.cfg.SharedTransferConfig.TypingSettings.DecimalScaleOverflow = decimal.ScaleOverflowRound
+		event := newEvent("round_policy", "-1.235")
+		_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), "-1.24", event.Data["amount"].(*decimal.Decimal).String())
+		// Columns that are not decimals are not affected.
+		assert.Equal(e.T(), "0.12345", event.Data["rate"])
+	}
+	{
+		// Truncate
+		e.cfg.SharedTransferConfig.TypingSettings.DecimalScaleOverflow = decimal.ScaleOverflowTruncate
+		event := newEvent("truncate_policy", 1.2389)
+		_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), "1.23", event.Data["amount"].(*decimal.Decimal).String())
+	}
+	{
+		// Error
+		e.cfg.SharedTransferConfig.TypingSettings.DecimalScaleOverflow = decimal.ScaleOverflowError
+		event := newEvent("error_policy", "1.2345")
+		_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.ErrorContains(e.T(), err, `failed to parse column "amount": value 1.2345 exceeds the decimal scale: 2`)
+
+		// Values that fit are not affected.
+		event = newEvent("error_policy", "1.23")
+		_, _, err = event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), "1.23", event.Data["amount"])
+	}
+}
+
+func (e *EventsTestSuite) TestEventSaveMaxValueLength() {
+	newEvent := func(table string) Event {
+		return Event{
+			Table:         table,
+			PrimaryKeyMap: map[string]any{"id": "123"},
+			Data: map[string]any{
+				constants.DeleteColumnMarker: false,
+				"id":                         "123",
+				"short":                      "abc",
+				"long":                       "héllo world",
+				"payload":                    map[string]any{"foo": "bar"},
+			},
+		}
+	}
+
+	kafkaMsg := kafka.Message{}
+	{
+		// No max value length, the values are passed through.
+		event := newEvent("no_max_length")
+		_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), "héllo world", event.Data["long"])
+		assert.Empty(e.T(), event.TruncatedColumns())
+	}
+	{
+		// Oversized strings are truncated to the limit without splitting multibyte characters.
+		e.cfg.SharedDestinationConfig.MaxValueLength = 2
+		event := newEvent("max_length")
+		_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), "h", event.Data["long"])
+		assert.Equal(e.T(), "ab", event.Data["short"])
+		// Primary keys are not truncated.
+		assert.Equal(e.T(), "123", event.Data["id"])
+		assert.Equal(e.T(), map[string]any{"key": constants.ExceededValueMarker}, event.Data["payload"])
+		assert.ElementsMatch(e.T(), []string{"long", "short", "payload"}, event.TruncatedColumns())
+	}
+	{
+		// Values under the limit are passed through and the per-column override takes precedence.
+		e.cfg.SharedDestinationConfig.MaxValueLength = 5
+		tc := *topicConfig
+		tc.ColumnMaxLengths = map[string]int{"long": 7}
+		event := newEvent("column_max_length")
+		_, _, err := event.Save(e.cfg, e.db, &tc, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), "héllo ", event.Data["long"])
+		assert.Equal(e.T(), "abc", event.Data["short"])
+		assert.Equal(e.T(), map[string]any{"key": constants.ExceededValueMarker}, event.Data["payload"])
+		assert.ElementsMatch(e.T(), []string{"long", "payload"}, event.TruncatedColumns())
+	}
+}
+
+func (e *EventsTestSuite) TestEventSaveTruncatesLongColumnNames() {
+	longCol := strings.Repeat("a", 130)
+	event := Event{
+		Table:         "long_columns",
+		PrimaryKeyMap: map[string]any{"id": "123"},
+		Data: map[string]any{
+			constants.DeleteColumnMarker: false,
+			"id":                         "123",
+			longCol:                      "foo",
+		},
+	}
+
+	e.cfg.Output = constants.Redshift
+	kafkaMsg := kafka.Message{}
+	_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+	assert.NoError(e.T(), err)
+
+	truncatedCol := sql.TruncateIdentifier(constants.Redshift, longCol)
+	assert.Len(e.T(), truncatedCol, constants.RedshiftMaxIdentifierLength)
+	assert.Equal(e.T(), "foo", event.Data[truncatedCol])
+	assert.NotContains(e.T(), event.Data, longCol)
+
+	_, isOk := e.db.GetOrCreateTableData("long_columns").ReadOnlyInMemoryCols().GetColumn(truncatedCol)
+	assert.True(e.T(), isOk)
+}
+
+func (e *EventsTestSuite) TestEventSaveColumnTypeOverrides() {
+	var cols columns.Columns
+	cols.AddColumn(columns.NewColumn("external_id", typing.Integer))
+	event := Event{
+		Table:         "overrides",
+		Columns:       &cols,
+		PrimaryKeyMap: map[string]any{"id": "123"},
+		Data: map[string]any{
+			constants.DeleteColumnMarker: false,
+			"external_id":                12345,
+			"Amount":                     12.5,
+			"created_at":                 "2023-01-01",
+			"name":                       "dusty",
+		},
+		OptionalSchema: map[string]typing.KindDetails{
+			"created_at": typing.String,
+		},
+	}
+
+	tc := *topicConfig
+	tc.ColumnTypeOverrides = map[string]string{
+		"external_id": "string",
+		"amount":      "decimal(10, 2)",
+		"created_at":  "timestamp",
+	}
+	tc.Load()
+
+	kafkaMsg := kafka.Message{}
+	_, _, err := event.Save(e.cfg, e.db, &tc, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+	assert.NoError(e.T(), err)
+
+	td := e.db.GetOrCreateTableData("overrides")
+	column, isOk := td.ReadOnlyInMemoryCols().GetColumn("external_id")
+	assert.True(e.T(), isOk)
+	assert.Equal(e.T(), typing.String, column.KindDetails)
+
+	column, isOk = td.ReadOnlyInMemoryCols().GetColumn("amount")
+	assert.True(e.T(), isOk)
+	assert.Equal(e.T(), typing.EDecimal.Kind, column.KindDetails.Kind)
+	assert.Equal(e.T(), 2, column.KindDetails.ExtendedDecimalDetails.Scale())
+	assert.Equal(e.T(), ptr.ToInt(10), column.KindDetails.ExtendedDecimalDetails.Precision())
+
+	// The override should take precedence over the optional schema.
+	column, isOk = td.ReadOnlyInMemoryCols().GetColumn("created_at")
+	assert.True(e.T(), isOk)
+	assert.Equal(e.T(), ext.DateTimeKindType, column.KindDetails.ExtendedTimeDetails.Type)
+
+	// Columns without an override are still inferred.
+	column, isOk = td.ReadOnlyInMemoryCols().GetColumn("name")
+	assert.True(e.T(), isOk)
+	assert.Equal(e.T(), typing.String, column.KindDetails)
+
+	// The override is also used when applying the decimal scale overflow policy.
+	e.cfg.SharedTransferConfig.TypingSettings.DecimalScaleOverflow = decimal.ScaleOverflowRound
+	event = Event{
+		Table:         "overrides_policy",
+		PrimaryKeyMap: map[string]any{"id": "123"},
+		Data: map[string]any{
+			constants.DeleteColumnMarker: false,
+			"amount":                     "1.2345",
+		},
+	}
+	_, _, err = event.Save(e.cfg, e.db, &tc, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+	assert.NoError(e.T(), err)
+	assert.Equal(e.T(), "1.23", event.Data["amount"].(*decimal.Decimal).String())
 }
 
 func (e *EventsTestSuite) TestEvent_SaveColumnsNoData() {
@@ -278,3 +686,351 @@ func (e *EventsTestSuite) TestEventSaveTestDeleteFlag() {
 	assert.NoError(e.T(), err)
 	assert.True(e.T(), e.db.GetOrCreateTableData("foo").ContainOtherOperations())
 }
+
+func (e *EventsTestSuite) TestEventSavePartial() {
+	kafkaMsg := kafka.Message{}
+	fullEvent := Event{
+		Table:         "foo",
+		PrimaryKeyMap: map[string]any{"id": "123"},
+		Data: map[string]any{
+			constants.DeleteColumnMarker: false,
+			"id":                         "123",
+			"name":                       "dusty",
+			"email":                      "dusty@artie.so",
+		},
+	}
+
+	_, _, err := fullEvent.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+	assert.NoError(e.T(), err)
+
+	// Partial event for the same row, the email should be carried over from the previous row.
+	partialEvent := Event{
+		Table:         "foo",
+		PrimaryKeyMap: map[string]any{"id": "123"},
+		Data: map[string]any{
+			constants.DeleteColumnMarker: false,
+			"id":                         "123",
+			"NAME":                       "dusty the mini aussie",
+		},
+		Partial: true,
+	}
+
+	_, _, err = partialEvent.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+	assert.NoError(e.T(), err)
+
+	// Partial event for a row we have not seen, the email should be marked as unavailable so the destination value is preserved.
+	anotherPartialEvent := Event{
+		Table:         "foo",
+		PrimaryKeyMap: map[string]any{"id": "456"},
+		Data: map[string]any{
+			constants.DeleteColumnMarker: false,
+			"id":                         "456",
+			"name":                       "robin",
+		},
+		Partial: true,
+	}
+
+	_, _, err = anotherPartialEvent.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+	assert.NoError(e.T(), err)
+
+	td := e.db.GetOrCreateTableData("foo")
+	emailCol, isOk := td.ReadOnlyInMemoryCols().GetColumn("email")
+	assert.True(e.T(), isOk)
+	assert.True(e.T(), emailCol.ToastColumn)
+
+	rows := make(map[string]map[string]any)
+	for _, row := range td.Rows() {
+		rows[fmt.Sprint(row["id"])] = row
+	}
+
+	assert.Len(e.T(), rows, 2)
+	assert.Equal(e.T(), "dusty the mini aussie", rows["123"]["name"])
+	assert.Equal(e.T(), "dusty@artie.so", rows["123"]["email"])
+	assert.Equal(e.T(), "robin", rows["456"]["name"])
+	assert.Equal(e.T(), constants.ToastUnavailableValuePlaceholder, rows["456"]["email"])
+}
+
+func (e *EventsTestSuite) TestEventSaveDuplicatePrimaryKeys() {
+	executionTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newEvent := func(name string, executionTime time.Time) Event {
+		return Event{
+			Table:         "foo",
+			PrimaryKeyMap: map[string]any{"id": "123"},
+			Data: map[string]any{
+				constants.DeleteColumnMarker: false,
+				"id":                         "123",
+				"name":                       name,
+			},
+			ExecutionTime: executionTime,
+		}
+	}
+
+	for i := range 3 {
+		kafkaMsg := kafka.Message{Partition: 1, Offset: int64(i)}
+		event := newEvent(fmt.Sprintf("update-%d", i), executionTime.Add(time.Duration(i)*time.Second))
+		_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.False(e.T(), event.Dropped())
+	}
+
+	{
+		// An update that has been redelivered should be dropped.
+		kafkaMsg := kafka.Message{Partition: 1, Offset: 0}
+		staleEvent := newEvent("stale", executionTime)
+		_, _, err := staleEvent.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.True(e.T(), staleEvent.Dropped())
+
+		rows := e.db.GetOrCreateTableData("foo").Rows()
+		assert.Len(e.T(), rows, 1)
+		assert.Equal(e.T(), "update-2", rows[0]["name"])
+	}
+	{
+		// A later offset within the same partition should win, even if its execution time is older (e.g. an update that was committed during a snapshot).
+		kafkaMsg := kafka.Message{Partition: 1, Offset: 3}
+		event := newEvent("committed during snapshot", executionTime)
+		_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+		assert.False(e.T(), event.Dropped())
+
+		rows := e.db.GetOrCreateTableData("foo").Rows()
+		assert.Len(e.T(), rows, 1)
+		assert.Equal(e.T(), "committed during snapshot", rows[0]["name"])
+	}
+}
+
+func (e *EventsTestSuite) TestEventSaveOrdersByOffsetWithoutExecutionTime() {
+	save := func(table string, name string, partition int, offset int64) {
+		event := Event{
+			Table:         table,
+			PrimaryKeyMap: map[string]any{"id": "123"},
+			Data: map[string]any{
+				constants.DeleteColumnMarker: false,
+				"id":                         "123",
+				"name":                       name,
+			},
+		}
+
+		kafkaMsg := kafka.Message{Partition: partition, Offset: offset}
+		_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+	}
+
+	rowName := func(table string) string {
+		rows := e.db.GetOrCreateTableData(table).Rows()
+		assert.Len(e.T(), rows, 1)
+		return fmt.Sprint(rows[0]["name"])
+	}
+	{
+		// The later offset wins.
+		save("in_order", "first", 0, 10)
+		save("in_order", "second", 0, 11)
+		assert.Equal(e.T(), "second", rowName("in_order"))
+	}
+	{
+		// An earlier offset from the same partition should be dropped, even though it was read last.
+		save("out_of_order", "second", 0, 11)
+		save("out_of_order", "first", 0, 10)
+		assert.Equal(e.T(), "second", rowName("out_of_order"))
+	}
+	{
+		// Offsets from different partitions cannot be compared, so the last row read wins.
+		save("other_partition", "first", 0, 11)
+		save("other_partition", "second", 1, 10)
+		assert.Equal(e.T(), "second", rowName("other_partition"))
+	}
+}
+
+func (e *EventsTestSuite) TestEventSaveUnavailableValue() {
+	kafkaMsg := kafka.Message{}
+	insertEvent := Event{
+		Table:         "foo",
+		PrimaryKeyMap: map[string]any{"id": "123"},
+		Data: map[string]any{
+			constants.DeleteColumnMarker: false,
+			"id":                         "123",
+			"name":                       "dusty",
+			"notes":                      "the mini aussie",
+		},
+	}
+
+	_, _, err := insertEvent.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+	assert.NoError(e.T(), err)
+
+	// The notes column did not change, so the previous value should be kept.
+	updateEvent := Event{
+		Table:         "foo",
+		PrimaryKeyMap: map[string]any{"id": "123"},
+		Data: map[string]any{
+			constants.DeleteColumnMarker: false,
+			"id":                         "123",
+			"name":                       "dusty the dog",
+			"notes":                      constants.ToastUnavailableValuePlaceholder,
+		},
+	}
+
+	_, _, err = updateEvent.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+	assert.NoError(e.T(), err)
+
+	// Update for a row that is not in memory, the destination will need to keep its value.
+	anotherUpdateEvent := Event{
+		Table:         "foo",
+		PrimaryKeyMap: map[string]any{"id": "456"},
+		Data: map[string]any{
+			constants.DeleteColumnMarker: false,
+			"id":                         "456",
+			"name":                       "robin",
+			"notes":                      constants.ToastUnavailableValuePlaceholder,
+		},
+	}
+
+	_, _, err = anotherUpdateEvent.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+	assert.NoError(e.T(), err)
+
+	td := e.db.GetOrCreateTableData("foo")
+	rows := make(map[string]map[string]any)
+	for _, row := range td.Rows() {
+		rows[fmt.Sprint(row["id"])] = row
+	}
+
+	assert.Equal(e.T(), "dusty the dog", rows["123"]["name"])
+	assert.Equal(e.T(), "the mini aussie", rows["123"]["notes"])
+	assert.Equal(e.T(), constants.ToastUnavailableValuePlaceholder, rows["456"]["notes"])
+
+	notesCol, isOk := td.ReadOnlyInMemoryCols().GetColumn("notes")
+	assert.True(e.T(), isOk)
+	assert.True(e.T(), notesCol.ToastColumn)
+
+	// The merge should only update the column if the value is not the placeholder.
+	updateQuery := td.ReadOnlyInMemoryCols().UpdateQuery(constants.Snowflake, config.PreserveCasing, false)
+	assert.Contains(e.T(), updateQuery, fmt.Sprintf("notes= CASE WHEN COALESCE(cc.notes != '%s', true) THEN cc.notes ELSE c.notes END", constants.ToastUnavailableValuePlaceholder))
+	assert.Contains(e.T(), updateQuery, "name=cc.name")
+}
+
+func (e *EventsTestSuite) TestEventSaveColumnRenames() {
+	tc := &kafkalib.TopicConfig{
+		Database:           "customer",
+		TableName:          "users",
+		Schema:             "public",
+		DropDeletedColumns: true,
+		ColumnRenames: map[string]string{
+			"id":     "user_id",
+			"Select": "selected",
+		},
+	}
+
+	cols := &columns.Columns{}
+	cols.AddColumn(columns.NewColumn("id", typing.Integer))
+	cols.UpsertColumn("id", columns.UpsertColumnArg{PrimaryKey: ptr.ToBool(true)})
+
+	kafkaMsg := kafka.Message{}
+	{
+		// Rename the primary key and a reserved word, the schema should follow the rename.
+		event := Event{
+			Table:         "foo",
+			PrimaryKeyMap: map[string]any{"id": 123},
+			Columns:       cols,
+			Data: map[string]any{
+				constants.DeleteColumnMarker: false,
+				"id":                         123,
+				"Select":                     "2023-01-01",
+				"name":                       "dusty",
+			},
+			OptionalSchema: map[string]typing.KindDetails{
+				"Select": typing.String,
+			},
+		}
+
+		_, _, err := event.Save(e.cfg, e.db, tc, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(e.T(), err)
+
+		td := e.db.GetOrCreateTableData("foo")
+		for _, col := range []string{"id", "select"} {
+			_, isOk := td.ReadOnlyInMemoryCols().GetColumn(col)
+			assert.False(e.T(), isOk, col)
+		}
+
+		userIDCol, isOk := td.ReadOnlyInMemoryCols().GetColumn("user_id")
+		assert.True(e.T(), isOk)
+		assert.True(e.T(), userIDCol.PrimaryKey())
+		assert.Equal(e.T(), typing.Integer, userIDCol.KindDetails)
+
+		selectedCol, isOk := td.ReadOnlyInMemoryCols().GetColumn("selected")
+		assert.True(e.T(), isOk)
+		assert.Equal(e.T(), typing.String, selectedCol.KindDetails)
+
+		// The merge ON clause is built from the primary keys.
+		pks := td.PrimaryKeys(config.PreserveCasing, nil)
+		assert.Len(e.T(), pks, 1)
+		assert.Equal(e.T(), "user_id", pks[0].RawName())
+
+		rows := td.Rows()
+		assert.Len(e.T(), rows, 1)
+		assert.Equal(e.T(), 123, rows[0]["user_id"])
+		assert.Equal(e.T(), "2023-01-01", rows[0]["selected"])
+
+		// If the destination still has the source column, only that should be considered deleted.
+		var destCols columns.Columns
+		for _, col := range []string{"user_id", "selected", "name", "id"} {
+			destCols.AddColumn(columns.NewColumn(col, typing.String))
+		}
+
+		srcKeysMissing, targKeysMissing := columns.Diff(td.ReadOnlyInMemoryCols(), &destCols, tc.SoftDelete, tc.IncludeArtieUpdatedAt, tc.IncludeDatabaseUpdatedAt, config.Replication)
+		assert.Len(e.T(), srcKeysMissing, 1)
+		assert.Equal(e.T(), "id", srcKeysMissing[0].RawName())
+		assert.Empty(e.T(), targKeysMissing)
+	}
+	{
+		// Renaming into a column that already exists should fail.
+		event := Event{
+			Table:         "foo",
+			PrimaryKeyMap: map[string]any{"id": 456},
+			Data: map[string]any{
+				constants.DeleteColumnMarker: false,
+				"id":                         456,
+				"user_id":                    789,
+			},
+		}
+
+		_, _, err := event.Save(e.cfg, e.db, tc, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.ErrorContains(e.T(), err, `would result in a duplicate column: "user_id"`)
+	}
+}
+
+func (e *EventsTestSuite) TestEventSaveSanitizeColumnNames() {
+	e.cfg.Output = constants.BigQuery
+	e.cfg.SharedDestinationConfig.SanitizeColumnNames = true
+
+	kafkaMsg := kafka.Message{}
+	event := Event{
+		Table:         "sanitized",
+		PrimaryKeyMap: map[string]any{"user-id": 123},
+		Data: map[string]any{
+			constants.DeleteColumnMarker: false,
+			"user-id":                    123,
+			"first name":                 "dusty",
+			"1st_login":                  "2023-01-01",
+			"user id":                    "collision",
+		},
+	}
+
+	_, _, err := event.Save(e.cfg, e.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+	assert.NoError(e.T(), err)
+
+	td := e.db.GetOrCreateTableData("sanitized")
+	for _, col := range []string{"user-id", "first__name", "1st_login", "user__id"} {
+		_, isOk := td.ReadOnlyInMemoryCols().GetColumn(col)
+		assert.False(e.T(), isOk, col)
+	}
+
+	pks := td.PrimaryKeys(config.PreserveCasing, nil)
+	assert.Len(e.T(), pks, 1)
+	assert.Equal(e.T(), "user_id_a7571dde", pks[0].RawName())
+
+	rows := td.Rows()
+	assert.Len(e.T(), rows, 1)
+	assert.Equal(e.T(), 123, rows[0]["user_id_a7571dde"])
+	assert.Equal(e.T(), "collision", rows[0]["user_id"])
+	assert.Equal(e.T(), "dusty", rows[0]["first_name"])
+	assert.Equal(e.T(), "2023-01-01", rows[0]["_1st_login"])
+}
@@ -3,13 +3,19 @@ package event
 import (
 	"time"
 
+	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/artie-labs/transfer/lib/artie"
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/debezium"
+	"github.com/artie-labs/transfer/lib/destination/dml"
 	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/sql"
 	"github.com/artie-labs/transfer/lib/typing"
 	"github.com/artie-labs/transfer/lib/typing/columns"
+	"github.com/artie-labs/transfer/lib/typing/decimal"
 )
 
 type fakeEvent struct{}
@@ -26,6 +32,10 @@ func (f fakeEvent) DeletePayload() bool {
 	return false
 }
 
+func (f fakeEvent) IsSnapshot() bool {
+	return false
+}
+
 func (f fakeEvent) GetExecutionTime() time.Time {
 	return time.Now()
 }
@@ -95,21 +105,25 @@ func (e *EventsTestSuite) TestEvent_TableName() {
 	var f fakeEvent
 	{
 		// Don't pass in tableName.
-		evt := ToMemoryEvent(f, idMap, &kafkalib.TopicConfig{}, config.Replication)
+		evt, err := ToMemoryEvent(f, idMap, &kafkalib.TopicConfig{}, config.Replication)
+		assert.NoError(e.T(), err)
 		assert.Equal(e.T(), f.GetTableName(), evt.Table)
 	}
 	{
 		// Now pass it in, it should override.
-		evt := ToMemoryEvent(f, idMap, &kafkalib.TopicConfig{TableName: "orders"}, config.Replication)
+		evt, err := ToMemoryEvent(f, idMap, &kafkalib.TopicConfig{TableName: "orders"}, config.Replication)
+		assert.NoError(e.T(), err)
 		assert.Equal(e.T(), "orders", evt.Table)
 	}
 	{
 		// Now, if it's history mode...
-		evt := ToMemoryEvent(f, idMap, &kafkalib.TopicConfig{TableName: "orders"}, config.History)
+		evt, err := ToMemoryEvent(f, idMap, &kafkalib.TopicConfig{TableName: "orders"}, config.History)
+		assert.NoError(e.T(), err)
 		assert.Equal(e.T(), "orders__history", evt.Table)
 
 		// Table already has history suffix, so it won't add extra.
-		evt = ToMemoryEvent(f, idMap, &kafkalib.TopicConfig{TableName: "dusty__history"}, config.History)
+		evt, err = ToMemoryEvent(f, idMap, &kafkalib.TopicConfig{TableName: "dusty__history"}, config.History)
+		assert.NoError(e.T(), err)
 		assert.Equal(e.T(), "dusty__history", evt.Table)
 	}
 }
@@ -182,3 +196,234 @@ func (e *EventsTestSuite) TestPrimaryKeyValueDeterministic() {
 		assert.Equal(e.T(), evt.PrimaryKeyValue(), "aa=1bb=5dusty=mini aussiegg=artiezz=ff")
 	}
 }
+
+func (e *EventsTestSuite) TestEvent_NullPrimaryKeys() {
+	{
+		// No null primary keys
+		evt := Event{PrimaryKeyMap: map[string]any{"id": 1, "tenant_id": 2}, Data: map[string]any{"id": 1, "tenant_id": 2}}
+		assert.Empty(e.T(), evt.NullPrimaryKeys())
+	}
+	{
+		// Null in the data
+		evt := Event{PrimaryKeyMap: map[string]any{"id": 1, "tenant_id": 2}, Data: map[string]any{"id": 1, "tenant_id": nil}}
+		assert.Equal(e.T(), []string{"tenant_id"}, evt.NullPrimaryKeys())
+	}
+	{
+		// Not in the data, so we'll check the primary key map
+		evt := Event{PrimaryKeyMap: map[string]any{"id": nil, "tenant_id": 2}, Data: map[string]any{}}
+		assert.Equal(e.T(), []string{"id"}, evt.NullPrimaryKeys())
+	}
+	{
+		// Both are null
+		evt := Event{PrimaryKeyMap: map[string]any{"id": nil, "tenant_id": nil}, Data: map[string]any{"id": nil}}
+		assert.Equal(e.T(), []string{"id", "tenant_id"}, evt.NullPrimaryKeys())
+	}
+}
+
+type overrideEvent struct {
+	fakeEvent
+	data   map[string]any
+	schema map[string]typing.KindDetails
+}
+
+func (o overrideEvent) GetOptionalSchema() map[string]typing.KindDetails {
+	return o.schema
+}
+
+func (o overrideEvent) GetData(_ map[string]any, _ *kafkalib.TopicConfig) map[string]any {
+	data := map[string]any{constants.DeleteColumnMarker: false}
+	for key, value := range o.data {
+		data[key] = value
+	}
+
+	return data
+}
+
+func (e *EventsTestSuite) TestToMemoryEvent_PrimaryKeyOverride() {
+	evt := overrideEvent{
+		data: map[string]any{"id": 123, "order_id": "abc", "line_no": 2, "sku": "hat"},
+		schema: map[string]typing.KindDetails{
+			"id":       typing.Integer,
+			"order_id": typing.String,
+			"line_no":  typing.Integer,
+			"sku":      typing.String,
+		},
+	}
+
+	{
+		// No override, so the message key is used.
+		memoryEvent, err := ToMemoryEvent(evt, idMap, &kafkalib.TopicConfig{}, config.Replication)
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), []string{"id"}, memoryEvent.PrimaryKeys())
+	}
+	{
+		// Override
+		tc := &kafkalib.TopicConfig{Database: "shop", Schema: "public", PrimaryKeyOverride: []string{"order_id", "line_no"}}
+		memoryEvent, err := ToMemoryEvent(evt, idMap, tc, config.Replication)
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), []string{"line_no", "order_id"}, memoryEvent.PrimaryKeys())
+		assert.Equal(e.T(), "line_no=2order_id=abc", memoryEvent.PrimaryKeyValue())
+
+		// The override should be used for the merge's ON clause.
+		_, _, err = memoryEvent.Save(e.cfg, e.db, tc, artie.NewMessage(&kafka.Message{}, nil, ""))
+		assert.NoError(e.T(), err)
+		td := e.db.GetOrCreateTableData("foo")
+
+		mergeArg := dml.MergeArgument{
+			FqTableName:      "shop.public.foo",
+			SubQuery:         "shop.public.foo_tmp",
+			PrimaryKeys:      td.PrimaryKeys(config.PreserveCasing, &sql.NameArgs{Escape: true, DestKind: constants.Snowflake}),
+			Columns:          td.ReadOnlyInMemoryCols(),
+			DestKind:         constants.Snowflake,
+			IdentifierCasing: config.PreserveCasing,
+		}
+
+		mergeSQL, err := mergeArg.GetStatement()
+		assert.NoError(e.T(), err)
+		assert.Contains(e.T(), mergeSQL, `ON c.line_no = cc.line_no and c.order_id = cc.order_id`, mergeSQL)
+		assert.NotContains(e.T(), mergeSQL, `c.id = cc.id`, mergeSQL)
+	}
+	{
+		// Column does not exist in the schema
+		_, err := ToMemoryEvent(evt, idMap, &kafkalib.TopicConfig{PrimaryKeyOverride: []string{"order_id", "nope"}}, config.Replication)
+		assert.ErrorContains(e.T(), err, `primary key override column "nope" does not exist in the schema`)
+	}
+	{
+		// Column does not exist in the event (and there's no schema)
+		evt.schema = nil
+		_, err := ToMemoryEvent(evt, idMap, &kafkalib.TopicConfig{PrimaryKeyOverride: []string{"nope"}}, config.Replication)
+		assert.ErrorContains(e.T(), err, `primary key override column "nope" does not exist in the event`)
+	}
+}
+
+func (e *EventsTestSuite) TestToMemoryEvent_DecimalToFloat() {
+	field := debezium.Field{
+		DebeziumType: debezium.KafkaDecimalType,
+		Parameters: map[string]any{
+			"scale":                           "2",
+			debezium.KafkaDecimalPrecisionKey: "5",
+		},
+	}
+
+	// 0x3039 = 12345, so with a scale of 2 this is 123.45
+	value, err := field.ParseValue("MDk=")
+	assert.NoError(e.T(), err)
+
+	newEvent := func() overrideEvent {
+		return overrideEvent{
+			data:   map[string]any{"id": 123, "price": value},
+			schema: map[string]typing.KindDetails{"id": typing.Integer, "price": field.ToKindDetails()},
+		}
+	}
+
+	{
+		// Disabled
+		memoryEvent, err := ToMemoryEvent(newEvent(), idMap, &kafkalib.TopicConfig{}, config.Replication)
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), typing.EDecimal.Kind, memoryEvent.OptionalSchema["price"].Kind)
+		decimalValue, isOk := memoryEvent.Data["price"].(*decimal.Decimal)
+		assert.True(e.T(), isOk)
+		assert.Equal(e.T(), "123.45", decimalValue.String())
+	}
+	{
+		// Enabled
+		memoryEvent, err := ToMemoryEvent(newEvent(), idMap, &kafkalib.TopicConfig{DecimalToFloat: true}, config.Replication)
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), typing.Float, memoryEvent.OptionalSchema["price"])
+		assert.Equal(e.T(), typing.Integer, memoryEvent.OptionalSchema["id"])
+		assert.Equal(e.T(), 123.45, memoryEvent.Data["price"])
+		assert.Equal(e.T(), 123, memoryEvent.Data["id"])
+
+		_, _, err = memoryEvent.Save(e.cfg, e.db, &kafkalib.TopicConfig{DecimalToFloat: true}, artie.NewMessage(&kafka.Message{}, nil, ""))
+		assert.NoError(e.T(), err)
+		priceCol, isOk := e.db.GetOrCreateTableData("foo").ReadOnlyInMemoryCols().GetColumn("price")
+		assert.True(e.T(), isOk)
+		assert.Equal(e.T(), typing.Float, priceCol.KindDetails)
+	}
+}
+
+func (e *EventsTestSuite) TestToMemoryEvent_BooleanColumns() {
+	tc := &kafkalib.TopicConfig{BooleanColumns: []string{"is_active"}}
+	newEvent := func(isActive any) overrideEvent {
+		return overrideEvent{
+			data:   map[string]any{"id": 123, "is_active": isActive, "count": 1},
+			schema: map[string]typing.KindDetails{"id": typing.Integer, "is_active": typing.Integer, "count": typing.Integer},
+		}
+	}
+
+	{
+		// Not configured
+		memoryEvent, err := ToMemoryEvent(newEvent(1), idMap, &kafkalib.TopicConfig{}, config.Replication)
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), typing.Integer, memoryEvent.OptionalSchema["is_active"])
+		assert.Equal(e.T(), 1, memoryEvent.Data["is_active"])
+	}
+	{
+		// 0 should be false
+		memoryEvent, err := ToMemoryEvent(newEvent(int64(0)), idMap, tc, config.Replication)
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), typing.Boolean, memoryEvent.OptionalSchema["is_active"])
+		assert.Equal(e.T(), false, memoryEvent.Data["is_active"])
+	}
+	{
+		// 1 should be true and the other integer columns should not change
+		memoryEvent, err := ToMemoryEvent(newEvent(1), idMap, tc, config.Replication)
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), typing.Boolean, memoryEvent.OptionalSchema["is_active"])
+		assert.Equal(e.T(), true, memoryEvent.Data["is_active"])
+		assert.Equal(e.T(), typing.Integer, memoryEvent.OptionalSchema["count"])
+		assert.Equal(e.T(), 1, memoryEvent.Data["count"])
+
+		_, _, err = memoryEvent.Save(e.cfg, e.db, tc, artie.NewMessage(&kafka.Message{}, nil, ""))
+		assert.NoError(e.T(), err)
+		inMemCols := e.db.GetOrCreateTableData("foo").ReadOnlyInMemoryCols()
+		isActiveCol, isOk := inMemCols.GetColumn("is_active")
+		assert.True(e.T(), isOk)
+		assert.Equal(e.T(), typing.Boolean, isActiveCol.KindDetails)
+
+		countCol, isOk := inMemCols.GetColumn("count")
+		assert.True(e.T(), isOk)
+		assert.Equal(e.T(), typing.Integer, countCol.KindDetails)
+	}
+	{
+		// Nulls should be left alone
+		memoryEvent, err := ToMemoryEvent(newEvent(nil), idMap, tc, config.Replication)
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), typing.Boolean, memoryEvent.OptionalSchema["is_active"])
+		assert.Nil(e.T(), memoryEvent.Data["is_active"])
+	}
+}
+
+func (e *EventsTestSuite) TestToMemoryEvent_BytesToNative() {
+	newEvent := func(data any) overrideEvent {
+		return overrideEvent{
+			data:   map[string]any{"id": 123, "data": data, "name": "hello"},
+			schema: map[string]typing.KindDetails{"id": typing.Integer, "data": typing.Bytes, "name": typing.String},
+		}
+	}
+
+	{
+		// Disabled
+		memoryEvent, err := ToMemoryEvent(newEvent("68656c6c6f"), idMap, &kafkalib.TopicConfig{BytesEncoding: kafkalib.BytesEncodingHex}, config.Replication)
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), typing.String, memoryEvent.OptionalSchema["data"])
+		assert.Equal(e.T(), "68656c6c6f", memoryEvent.Data["data"])
+	}
+	{
+		// Enabled
+		memoryEvent, err := ToMemoryEvent(newEvent([]byte("hello")), idMap, &kafkalib.TopicConfig{BytesEncoding: kafkalib.BytesEncodingNative}, config.Replication)
+		assert.NoError(e.T(), err)
+		assert.Equal(e.T(), typing.Bytes, memoryEvent.OptionalSchema["data"])
+		assert.Equal(e.T(), typing.Integer, memoryEvent.OptionalSchema["id"])
+		assert.Equal(e.T(), typing.String, memoryEvent.OptionalSchema["name"])
+		assert.Equal(e.T(), []byte("hello"), memoryEvent.Data["data"])
+	}
+	{
+		// Enabled, the column should be typed from the schema even if the value is null or a TOAST placeholder.
+		for _, value := range []any{nil, constants.ToastUnavailableValuePlaceholder} {
+			memoryEvent, err := ToMemoryEvent(newEvent(value), idMap, &kafkalib.TopicConfig{BytesEncoding: kafkalib.BytesEncodingNative}, config.Replication)
+			assert.NoError(e.T(), err)
+			assert.Equal(e.T(), typing.Bytes, memoryEvent.OptionalSchema["data"], value)
+		}
+	}
+}
@@ -2,9 +2,11 @@ package models
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/typing/columns"
 )
 
 // TableData is a wrapper around *optimization.TableData which stores the actual underlying tableData.
@@ -13,12 +15,21 @@ import (
 type TableData struct {
 	*optimization.TableData
 	lastFlushTime time.Time
+	// failedFlushes is the number of consecutive flushes that have failed, this is reset once the table has been flushed.
+	failedFlushes int
 	sync.Mutex
 }
 
 func (t *TableData) Wipe() {
 	t.TableData = nil
 	t.lastFlushTime = time.Now()
+	t.failedFlushes = 0
+}
+
+// RecordFailedFlush returns the number of consecutive flushes that have failed, including this one.
+func (t *TableData) RecordFailedFlush() int {
+	t.failedFlushes++
+	return t.failedFlushes
 }
 
 // ShouldSkipFlush - this function is only used when the flush reason was time-based.
@@ -36,6 +47,15 @@ func (t *TableData) ShouldSkipFlush(cooldown time.Duration) bool {
 	return time.Since(t.lastFlushTime) < cooldown
 }
 
+// IsIdle returns true if the table has buffered rows but has not received a new row within `idle`.
+func (t *TableData) IsIdle(idle time.Duration) bool {
+	if t.Empty() || t.NumberOfRows() == 0 {
+		return false
+	}
+
+	return time.Since(t.LastInsertTime()) >= idle
+}
+
 func (t *TableData) Empty() bool {
 	return t.TableData == nil
 }
@@ -44,26 +64,55 @@ func (t *TableData) SetTableData(td *optimization.TableData) {
 	t.TableData = td
 }
 
+// sanitizerKey is the fully qualified name of the destination table, tables with the same name can be in different databases or schemas.
+type sanitizerKey struct {
+	database string
+	schema   string
+	table    string
+}
+
 type DatabaseData struct {
 	tableData map[string]*TableData
+	// columnSanitizers are kept after the table has been flushed, so the columns are sanitized the same way across batches.
+	columnSanitizers map[sanitizerKey]*columns.Sanitizer
+	// approxSize is the estimated size (in bytes) of all the buffered tables.
+	approxSize atomic.Int64
 	sync.RWMutex
 }
 
 func NewMemoryDB() *DatabaseData {
 	tableData := make(map[string]*TableData)
 	return &DatabaseData{
-		tableData: tableData,
+		tableData:        tableData,
+		columnSanitizers: make(map[sanitizerKey]*columns.Sanitizer),
 	}
 }
 
+// ColumnSanitizer returns the column sanitizer for the destination table, it will be created the first time the table is seen.
+func (d *DatabaseData) ColumnSanitizer(database, schema, table string) *columns.Sanitizer {
+	d.Lock()
+	defer d.Unlock()
+
+	key := sanitizerKey{database: database, schema: schema, table: table}
+	sanitizer, isOk := d.columnSanitizers[key]
+	if !isOk {
+		sanitizer = columns.NewSanitizer()
+		d.columnSanitizers[key] = sanitizer
+	}
+
+	return sanitizer
+}
+
 func (d *DatabaseData) GetOrCreateTableData(tableName string) *TableData {
 	d.Lock()
 	defer d.Unlock()
 
 	table, exists := d.tableData[tableName]
 	if !exists {
+		// The flush interval starts when the table is created, otherwise a table that has never been flushed would skip its cooldown.
 		table = &TableData{
-			Mutex: sync.Mutex{},
+			lastFlushTime: time.Now(),
+			Mutex:         sync.Mutex{},
 		}
 		d.tableData[tableName] = table
 	}
@@ -74,7 +123,22 @@ func (d *DatabaseData) GetOrCreateTableData(tableName string) *TableData {
 func (d *DatabaseData) ClearTableConfig(tableName string) {
 	d.Lock()
 	defer d.Unlock()
-	d.tableData[tableName].Wipe()
+	table := d.tableData[tableName]
+	if !table.Empty() {
+		d.AddApproxSize(-table.ApproxSize())
+	}
+
+	table.Wipe()
+}
+
+// AddApproxSize is called whenever a table's buffered size changes, `delta` can be negative.
+func (d *DatabaseData) AddApproxSize(delta int) {
+	d.approxSize.Add(int64(delta))
+}
+
+// ApproxSize returns the estimated size (in bytes) of all the buffered tables.
+func (d *DatabaseData) ApproxSize() int {
+	return int(d.approxSize.Load())
 }
 
 func (d *DatabaseData) TableData() map[string]*TableData {
@@ -2,7 +2,11 @@ package models
 
 import (
 	"testing"
+	"time"
 
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/optimization"
 
 	"github.com/stretchr/testify/assert"
@@ -37,3 +41,56 @@ func TestTableData_Complete(t *testing.T) {
 	db.ClearTableConfig(tableName)
 	assert.True(t, td.Empty())
 }
+
+func TestDatabaseData_ApproxSize(t *testing.T) {
+	db := NewMemoryDB()
+	assert.Equal(t, 0, db.ApproxSize())
+
+	td := db.GetOrCreateTableData("foo")
+	td.SetTableData(optimization.NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo"))
+	td.InsertRow("id=1", map[string]any{"id": 1, "name": "dusty"}, false)
+	db.AddApproxSize(td.ApproxSize())
+	assert.Equal(t, td.ApproxSize(), db.ApproxSize())
+	assert.Greater(t, db.ApproxSize(), 0)
+
+	db.AddApproxSize(100)
+	assert.Equal(t, td.ApproxSize()+100, db.ApproxSize())
+
+	// Clearing the table should subtract its size.
+	db.ClearTableConfig("foo")
+	assert.Equal(t, 100, db.ApproxSize())
+
+	// Clearing an empty table is a no-op.
+	db.ClearTableConfig("foo")
+	assert.Equal(t, 100, db.ApproxSize())
+}
+
+func TestTableData_IsIdle(t *testing.T) {
+	td := &TableData{}
+	// Empty tables are never idle
+	assert.False(t, td.IsIdle(0))
+
+	td.SetTableData(optimization.NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "foo"))
+	assert.False(t, td.IsIdle(0))
+
+	td.InsertRow("id=1", map[string]any{"id": 1}, false)
+	assert.True(t, td.IsIdle(0))
+	assert.False(t, td.IsIdle(time.Hour))
+}
+
+func TestDatabaseData_ColumnSanitizer(t *testing.T) {
+	db := NewMemoryDB()
+	sanitizer := db.ColumnSanitizer("shop", "public", "orders")
+	assert.Equal(t, map[string]string{"user-id": "user_id"}, sanitizer.Renames(constants.BigQuery, []string{"user-id"}))
+
+	// The same table should return the same sanitizer, even after the table has been flushed.
+	db.GetOrCreateTableData("orders")
+	db.ClearTableConfig("orders")
+	assert.Same(t, sanitizer, db.ColumnSanitizer("shop", "public", "orders"))
+
+	// Tables with the same name in a different schema should not share the mapping.
+	otherSanitizer := db.ColumnSanitizer("shop", "staging", "orders")
+	assert.NotSame(t, sanitizer, otherSanitizer)
+	assert.Empty(t, otherSanitizer.Renames(constants.BigQuery, []string{"user_id"}))
+	assert.Equal(t, map[string]string{"user_id": "user_id_f89d6b69"}, sanitizer.Renames(constants.BigQuery, []string{"user_id"}))
+}
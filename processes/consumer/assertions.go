@@ -0,0 +1,65 @@
+package consumer
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/artie-labs/transfer/lib/assertions"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/sql"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
+	"github.com/artie-labs/transfer/lib/typing"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+// checkAssertions will run the topic's assertions against the destination table, `casing` is used to escape the columns that the assertions reference.
+// Failed assertions are logged and emitted as a metric, it'll only return an error for assertions with an error severity.
+func checkAssertions(dest destination.Baseline, tableData *optimization.TableData, casing config.IdentifierCasing, metricsClient base.Client) error {
+	if len(tableData.TopicConfig.Assertions) == 0 {
+		return nil
+	}
+
+	dwh, isOk := dest.(destination.DataWarehouse)
+	if !isOk {
+		slog.Warn("Skipping assertions because the destination does not support queries", slog.String("destination", string(dest.Label())))
+		return nil
+	}
+
+	fqName := dwh.ToFullyQualifiedName(tableData, true)
+	table := assertions.Table{
+		FqName: fqName,
+		EscapeName: func(name string) string {
+			name = columns.EscapeName(name)
+			col, isOk := tableData.ReadOnlyInMemoryCols().GetColumn(name)
+			if !isOk {
+				col = columns.NewColumn(name, typing.Invalid)
+			}
+
+			return col.Name(casing, &sql.NameArgs{Escape: true, DestKind: dwh.Label()})
+		},
+	}
+
+	var errs []error
+	for _, failure := range assertions.Run(dwh, table, tableData.TopicConfig.Assertions) {
+		tags := map[string]string{
+			"table":     fqName,
+			"assertion": failure.Config.Name,
+			"severity":  string(kafkalib.AssertionSeverityError),
+		}
+
+		if failure.Config.IsWarning() {
+			tags["severity"] = string(kafkalib.AssertionSeverityWarn)
+			metricsClient.Incr("assertion.fail", tags)
+			slog.Warn("Assertion failed", slog.Any("err", failure.Err), slog.String("tableName", fqName))
+			continue
+		}
+
+		metricsClient.Incr("assertion.fail", tags)
+		errs = append(errs, failure.Err)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,42 @@
+package consumer
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/artie-labs/transfer/models"
+)
+
+const memoryPollInterval = time.Second
+
+// waitForMemory will block while the estimated size of all the buffered tables exceeds `maxBytes`.
+// Since the consumer will not fetch (or ack) any messages while we are waiting, this provides backpressure to the source.
+// `flush` is called to bring memory back down, if it does not, we will keep on retrying every `pollInterval` until it does or `ctx` is done.
+func waitForMemory(ctx context.Context, inMemDB *models.DatabaseData, maxBytes int, pollInterval time.Duration, flush func() error) error {
+	if maxBytes <= 0 || inMemDB.ApproxSize() <= maxBytes {
+		return nil
+	}
+
+	slog.Info("Buffered tables have exceeded the max memory limit, pausing consumption...",
+		slog.Int("approxSize", inMemDB.ApproxSize()),
+		slog.Int("maxBytes", maxBytes),
+	)
+
+	for {
+		if err := flush(); err != nil {
+			slog.Warn("Failed to flush while waiting for memory to free up", slog.Any("err", err))
+		}
+
+		if inMemDB.ApproxSize() <= maxBytes {
+			slog.Info("Buffered tables are back under the max memory limit, resuming consumption...", slog.Int("approxSize", inMemDB.ApproxSize()))
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
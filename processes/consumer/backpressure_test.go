@@ -0,0 +1,81 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/models"
+)
+
+func TestWaitForMemory(t *testing.T) {
+	{
+		// Disabled
+		inMemDB := models.NewMemoryDB()
+		inMemDB.AddApproxSize(5000)
+		assert.NoError(t, waitForMemory(context.Background(), inMemDB, 0, time.Millisecond, func() error {
+			assert.Fail(t, "flush should not be called")
+			return nil
+		}))
+	}
+	{
+		// Under the threshold
+		inMemDB := models.NewMemoryDB()
+		inMemDB.AddApproxSize(1000)
+		assert.NoError(t, waitForMemory(context.Background(), inMemDB, 1000, time.Millisecond, func() error {
+			assert.Fail(t, "flush should not be called")
+			return nil
+		}))
+	}
+	{
+		// Over the threshold, consumption should be paused until a flush brings memory back down.
+		inMemDB := models.NewMemoryDB()
+		inMemDB.AddApproxSize(2048)
+
+		var flushCount atomic.Int32
+		release := make(chan struct{})
+		done := make(chan error)
+		go func() {
+			done <- waitForMemory(context.Background(), inMemDB, 1024, time.Millisecond, func() error {
+				flushCount.Add(1)
+				select {
+				case <-release:
+					inMemDB.AddApproxSize(-2048)
+					return nil
+				default:
+					return fmt.Errorf("destination is unavailable")
+				}
+			})
+		}()
+
+		// Failed flushes should keep us paused.
+		assert.Eventually(t, func() bool { return flushCount.Load() >= 3 }, time.Second, time.Millisecond)
+		select {
+		case <-done:
+			assert.Fail(t, "consumption should still be paused")
+		default:
+		}
+
+		close(release)
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(time.Second):
+			assert.Fail(t, "consumption should have resumed")
+		}
+		assert.Equal(t, 0, inMemDB.ApproxSize())
+	}
+	{
+		// Context is cancelled while we are paused
+		inMemDB := models.NewMemoryDB()
+		inMemDB.AddApproxSize(2048)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, waitForMemory(ctx, inMemDB, 1024, time.Millisecond, func() error { return nil }), context.Canceled)
+	}
+}
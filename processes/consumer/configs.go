@@ -2,11 +2,18 @@ package consumer
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/artie-labs/transfer/lib/artie"
 	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/cdc/format"
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/retry"
+	"github.com/artie-labs/transfer/lib/schemaregistry"
 )
 
 type TcFmtMap struct {
@@ -33,23 +40,118 @@ func (t *TcFmtMap) GetTopicFmt(topic string) (TopicConfigFormatter, bool) {
 	return tcFmt, isOk
 }
 
+// GetTopicConfigForTable returns the topic config of the topic that writes into `tableName`, this is used by the schema change topic to find the table's settings.
+// Debezium will name the topics `<prefix>.<database or schema>.<table>`, unless the topic config overrides the table name.
+func (t *TcFmtMap) GetTopicConfigForTable(tableName string) (*kafkalib.TopicConfig, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	// Sorting the topics so that we'll pick the same topic every time if there are multiple matches.
+	topics := make([]string, 0, len(t.tc))
+	for topic := range t.tc {
+		topics = append(topics, topic)
+	}
+
+	slices.Sort(topics)
+	for _, topic := range topics {
+		tc := t.tc[topic].tc
+		if tc.ApplySchemaChanges {
+			continue
+		}
+
+		if tc.TableName != "" {
+			if tc.TableName == tableName {
+				return tc, true
+			}
+
+			continue
+		}
+
+		if strings.HasSuffix(tc.Topic, "."+tableName) {
+			return tc, true
+		}
+	}
+
+	return nil, false
+}
+
 type TopicConfigFormatter struct {
 	tc *kafkalib.TopicConfig
 	cdc.Format
 }
 
-func commitOffset(ctx context.Context, topic string, partitionsToOffset map[string][]artie.Message) error {
+const (
+	schemaRegistryRetryBaseMs      = 500
+	schemaRegistryRetryMaxMs       = 10_000
+	schemaRegistryRetryMaxAttempts = 5
+)
+
+func defaultSchemaRegistryRetryConfig() retry.RetryConfig {
+	retryCfg, err := retry.NewJitterRetryConfig(schemaRegistryRetryBaseMs, schemaRegistryRetryMaxMs, schemaRegistryRetryMaxAttempts, retry.AlwaysRetry)
+	if err != nil {
+		// This should never happen since the values are constants.
+		panic(err)
+	}
+
+	return retryCfg
+}
+
+// loadSchemaRegistry returns a schema registry client if one is configured, the client is shared across all topics so schemas are only fetched once.
+// The schema registry may be temporarily unavailable (e.g. it's restarting), so we'll retry before giving up.
+func loadSchemaRegistry(cfg config.Config, retryCfg retry.RetryConfig) (*schemaregistry.Client, error) {
+	if cfg.SchemaRegistry == nil {
+		return nil, nil
+	}
+
+	client := schemaregistry.NewClient(*cfg.SchemaRegistry)
+	if err := retry.WithRetries(retryCfg, func(_ int, _ error) error { return client.Ping() }); err != nil {
+		return nil, fmt.Errorf("schema registry: %q is unavailable: %w", cfg.SchemaRegistry.URL, err)
+	}
+
+	return client, nil
+}
+
+// loadTcFmtMap will load the schema registry and the parser for each topic.
+func loadTcFmtMap(cfg config.Config, topicConfigs []*kafkalib.TopicConfig, retryCfg retry.RetryConfig) (*TcFmtMap, error) {
+	registry, err := loadSchemaRegistry(cfg, retryCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tcFmtMap := NewTcFmtMap()
+	for _, topicConfig := range topicConfigs {
+		parser, err := format.GetFormatParser(topicConfig, registry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load cdc format parser for topic: %q: %w", topicConfig.Topic, err)
+		}
+
+		tcFmtMap.Add(topicConfig.Topic, TopicConfigFormatter{tc: topicConfig, Format: parser})
+	}
+
+	return tcFmtMap, nil
+}
+
+// commitOffset is called once `tableName` has been flushed, it will commit (Kafka) or ack (Pub/Sub and NATS) the messages that have been written to the destination.
+func (o Options) commitOffset(ctx context.Context, topic string, tableName string, partitionsToOffset map[string][]artie.Message) error {
 	for _, msgs := range partitionsToOffset {
 		for _, msg := range msgs {
 			if msg.KafkaMsg != nil {
-				if err := topicToConsumer.Get(topic).CommitMessages(ctx, *msg.KafkaMsg); err != nil {
+				if err := topicToConsumer.Get(topic).CommitMessages(ctx, o.kafkaOffsets.Committable(tableName, *msg.KafkaMsg)); err != nil {
 					return err
 				}
+
+				o.kafkaOffsets.Flushed(tableName, *msg.KafkaMsg)
 			}
 
 			if msg.PubSub != nil {
 				msg.PubSub.Ack()
 			}
+
+			if msg.NATS != nil {
+				if err := msg.NATS.Ack(); err != nil {
+					return fmt.Errorf("failed to ack nats message: %w", err)
+				}
+			}
 		}
 	}
 
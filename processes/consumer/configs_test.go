@@ -0,0 +1,95 @@
+package consumer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/retry"
+)
+
+func TestLoadTcFmtMap(t *testing.T) {
+	retryCfg, err := retry.NewJitterRetryConfig(1, 1, 3, retry.AlwaysRetry)
+	assert.NoError(t, err)
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			// The first couple of requests fail, this should be retried.
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	avroTopic := &kafkalib.TopicConfig{Topic: "orders", CDCFormat: constants.DBZAvroFormat}
+	{
+		// Schema registry is temporarily unavailable
+		tcFmtMap, err := loadTcFmtMap(config.Config{SchemaRegistry: &config.SchemaRegistry{URL: server.URL}}, []*kafkalib.TopicConfig{avroTopic}, retryCfg)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, requests)
+
+		tcFmt, isOk := tcFmtMap.GetTopicFmt("orders")
+		assert.True(t, isOk)
+		assert.NotNil(t, tcFmt.Format)
+	}
+	{
+		// Schema registry is unreachable
+		tcFmtMap, err := loadTcFmtMap(config.Config{SchemaRegistry: &config.SchemaRegistry{URL: "http://localhost:0"}}, []*kafkalib.TopicConfig{avroTopic}, retryCfg)
+		assert.ErrorContains(t, err, `schema registry: "http://localhost:0" is unavailable: failed to reach schema registry`)
+		assert.Nil(t, tcFmtMap)
+	}
+	{
+		// Avro without a schema registry
+		tcFmtMap, err := loadTcFmtMap(config.Config{}, []*kafkalib.TopicConfig{avroTopic}, retryCfg)
+		assert.ErrorContains(t, err, `failed to load cdc format parser for topic: "orders": cdc format: "debezium.avro" requires a schema registry`)
+		assert.Nil(t, tcFmtMap)
+	}
+	{
+		// Invalid protobuf descriptor
+		protobufTopic := &kafkalib.TopicConfig{
+			Topic:            "customers",
+			CDCFormat:        constants.DBZProtobufFormat,
+			ProtobufSettings: &kafkalib.ProtobufSettings{DescriptorSetPath: "/tmp/does-not-exist.desc", MessageName: "foo.Envelope"},
+		}
+		tcFmtMap, err := loadTcFmtMap(config.Config{}, []*kafkalib.TopicConfig{protobufTopic}, retryCfg)
+		assert.ErrorContains(t, err, `failed to load cdc format parser for topic: "customers": failed to load protobuf parser`)
+		assert.Nil(t, tcFmtMap)
+	}
+}
+
+func TestTcFmtMap_GetTopicConfigForTable(t *testing.T) {
+	tcFmtMap := NewTcFmtMap()
+	for _, tc := range []*kafkalib.TopicConfig{
+		{Topic: "dbserver1", ApplySchemaChanges: true},
+		{Topic: "dbserver1.public.orders"},
+		{Topic: "dbserver1.public.order_items", TableName: "line_items"},
+	} {
+		tcFmtMap.Add(tc.Topic, TopicConfigFormatter{tc: tc})
+	}
+
+	tc, isOk := tcFmtMap.GetTopicConfigForTable("orders")
+	assert.True(t, isOk)
+	assert.Equal(t, "dbserver1.public.orders", tc.Topic)
+
+	// The table name has been overridden.
+	tc, isOk = tcFmtMap.GetTopicConfigForTable("line_items")
+	assert.True(t, isOk)
+	assert.Equal(t, "dbserver1.public.order_items", tc.Topic)
+	_, isOk = tcFmtMap.GetTopicConfigForTable("order_items")
+	assert.False(t, isOk)
+
+	// The schema change topic should never be returned.
+	_, isOk = tcFmtMap.GetTopicConfigForTable("dbserver1")
+	assert.False(t, isOk)
+	_, isOk = tcFmtMap.GetTopicConfigForTable("customers")
+	assert.False(t, isOk)
+}
@@ -0,0 +1,240 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/optimization"
+)
+
+// DeadLetter is published to the dead letter topic for every row that was isolated by bisecting a failing flush
+// for rows that the destination rejected (see [destination.RejectedRowsError]) and for rows with a null primary key if the topic's null primary key policy is `route-to-dlq`.
+type DeadLetter struct {
+	Table    string `json:"table"`
+	Database string `json:"database"`
+	Schema   string `json:"schema"`
+	Topic    string `json:"topic"`
+	// Error is the error that the destination returned when writing this row on its own, or why the row was not written.
+	Error    string         `json:"error"`
+	Row      map[string]any `json:"row"`
+	FailedAt time.Time      `json:"failedAt"`
+}
+
+// DeadLetterPublisher will publish [DeadLetter] events to the dead letter topic, the table name is used as the key.
+type DeadLetterPublisher interface {
+	Publish(ctx context.Context, key string, value []byte) error
+	Close() error
+}
+
+// NewDeadLetterPublisher will return nil if a dead letter topic has not been configured.
+func NewDeadLetterPublisher(ctx context.Context, cfg config.Config) (DeadLetterPublisher, error) {
+	if cfg.FlushRetry == nil || cfg.FlushRetry.DeadLetterTopic == "" {
+		return nil, nil
+	}
+
+	publisher, err := newTopicPublisher(ctx, cfg, cfg.FlushRetry.DeadLetterTopic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dead letter publisher: %w", err)
+	}
+
+	return publisher, nil
+}
+
+// maxBisectDepth is how many times a batch can be split in half, this is enough to isolate a single row out of ~1M rows.
+const maxBisectDepth = 20
+
+// isNonRowError returns true if `err` is known to not be caused by the rows (e.g. auth or connectivity errors), bisecting would route perfectly good rows to the dead letter topic.
+func isNonRowError(dest destination.Baseline, err error) bool {
+	var authErr *destination.AuthError
+	if errors.As(err, &authErr) {
+		return true
+	}
+
+	return destination.IsRetryable(err) || dest.IsRetryableError(err)
+}
+
+// shouldBisect returns true once the table has failed to flush `failedFlushes` times in a row and bisection has been enabled.
+func (o Options) shouldBisect(dest destination.Baseline, err error, failedFlushes int) bool {
+	if o.flushRetry == nil || !o.flushRetry.Bisect || o.deadLetterPublisher == nil {
+		return false
+	}
+
+	if failedFlushes < o.flushRetry.MaxBatchRetries {
+		return false
+	}
+
+	return !isNonRowError(dest, err)
+}
+
+// bisector will write the halves of a failing batch and keep track of the rows that fail on their own.
+type bisector struct {
+	dest destination.Baseline
+	// exactlyOnceDest and writtenOffsets are set if exactly-once delivery is enabled and the batch is merged.
+	exactlyOnceDest destination.ExactlyOnce
+	writtenOffsets  *writtenOffsetCache
+	// failedRows are the single row halves that failed, along with their error.
+	failedRows []failedHalf
+}
+
+type failedHalf struct {
+	tableData *optimization.TableData
+	err       error
+}
+
+func (b *bisector) write(tableData *optimization.TableData) error {
+	// Each write needs its own temporary table.
+	tableData.ResetTempTableSuffix()
+	if tableData.AppendOnly() {
+		return b.dest.Append(tableData)
+	}
+
+	if b.exactlyOnceDest != nil {
+		// The offsets are written once the rest of the batch has been written, see [writeBisected].
+		return b.exactlyOnceDest.MergeWithOffsets(tableData, nil)
+	}
+
+	return b.dest.Merge(tableData)
+}
+
+// isolate will keep on splitting `tableData` in half and writing each half, until the rows that fail on their own have been isolated.
+// `err` is the error from writing `tableData` as a whole. This will stop and return an error if a half fails with an error that is not caused by the rows.
+func (b *bisector) isolate(tableData *optimization.TableData, err error, depth int) error {
+	if tableData.NumberOfRows() == 1 {
+		b.failedRows = append(b.failedRows, failedHalf{tableData: tableData, err: err})
+		return nil
+	}
+
+	if depth >= maxBisectDepth {
+		return fmt.Errorf("failed to isolate the failing rows after splitting the batch %d times: %w", maxBisectDepth, err)
+	}
+
+	left, right := tableData.Bisect()
+	for _, half := range []*optimization.TableData{left, right} {
+		if half.NumberOfRows() == 0 {
+			continue
+		}
+
+		halfErr := b.write(half)
+		if halfErr == nil {
+			continue
+		}
+
+		if isNonRowError(b.dest, halfErr) {
+			return halfErr
+		}
+
+		if isolateErr := b.isolate(half, halfErr, depth+1); isolateErr != nil {
+			return isolateErr
+		}
+	}
+
+	return nil
+}
+
+// writeBisected is called after the whole batch has failed with `err`, it will write the rows that succeed on their own and publish the rest to the dead letter topic.
+// It returns the number of rows that were published to the dead letter topic.
+func writeBisected(ctx context.Context, opts Options, dest destination.Baseline, tableData *optimization.TableData, err error) (int, error) {
+	b := bisector{dest: dest}
+	if exactlyOnceDest, isOk := opts.exactlyOnceDestination(dest); isOk && !tableData.AppendOnly() {
+		b.exactlyOnceDest = exactlyOnceDest
+		b.writtenOffsets = opts.writtenOffsets
+	}
+
+	if isolateErr := b.isolate(tableData, err, 0); isolateErr != nil {
+		return 0, isolateErr
+	}
+
+	if b.exactlyOnceDest != nil {
+		if writeErr := b.writeOffsets(tableData); writeErr != nil {
+			return 0, writeErr
+		}
+	}
+
+	for _, failedRow := range b.failedRows {
+		// The rows that succeeded will be written again when the flush is retried, merges are idempotent and appends are at-least-once anyway.
+		if publishErr := opts.publishDeadLetter(ctx, newDeadLetter(tableData, failedRow.tableData.Rows()[0], failedRow.err)); publishErr != nil {
+			return 0, publishErr
+		}
+	}
+
+	return len(b.failedRows), nil
+}
+
+// routeRejectedRows will publish the rows that the destination left out of the batch to the dead letter topic, see [destination.RejectedRowsError].
+// `err` is returned as is if the destination did not reject any rows or the dead letter topic has not been configured.
+func (o Options) routeRejectedRows(ctx context.Context, tableData *optimization.TableData, err error) (int, error) {
+	var rejectedErr *destination.RejectedRowsError
+	if !errors.As(err, &rejectedErr) || o.deadLetterPublisher == nil {
+		return 0, err
+	}
+
+	for _, row := range rejectedErr.Rows() {
+		if publishErr := o.publishDeadLetter(ctx, newDeadLetter(tableData, row, rejectedErr)); publishErr != nil {
+			return 0, publishErr
+		}
+	}
+
+	return len(rejectedErr.Rows()), nil
+}
+
+func newDeadLetter(tableData *optimization.TableData, row map[string]any, err error) DeadLetter {
+	return DeadLetter{
+		Table:    tableData.RawName(),
+		Database: tableData.TopicConfig.Database,
+		Schema:   tableData.TopicConfig.Schema,
+		Topic:    tableData.TopicConfig.Topic,
+		Error:    err.Error(),
+		Row:      row,
+		FailedAt: time.Now().UTC(),
+	}
+}
+
+func (o Options) publishDeadLetter(ctx context.Context, deadLetter DeadLetter) error {
+	if o.deadLetterPublisher == nil {
+		return fmt.Errorf("dead letter topic has not been configured")
+	}
+
+	value, err := json.Marshal(deadLetter)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+
+	if err = o.deadLetterPublisher.Publish(ctx, deadLetter.Table, value); err != nil {
+		return fmt.Errorf("failed to publish dead letter: %w", err)
+	}
+
+	return nil
+}
+
+// writeOffsets will merge the rows that did not fail again, along with the batch's offsets within the same transaction.
+// The halves are written in separate transactions, so writing the offsets with any one of them would skip the rows of the other halves if we crashed in between.
+func (b *bisector) writeOffsets(tableData *optimization.TableData) error {
+	var failedRows []*optimization.TableData
+	for _, failedRow := range b.failedRows {
+		failedRows = append(failedRows, failedRow.tableData)
+	}
+
+	rest, err := tableData.Without(failedRows...)
+	if err != nil {
+		return err
+	}
+
+	if rest.NumberOfRows() == 0 {
+		// Every row failed, the offsets will be committed to Kafka once the rows have been published to the dead letter topic.
+		return nil
+	}
+
+	rest.ResetTempTableSuffix()
+	offsets := lastKafkaOffsets(tableData.PartitionsToLastMessage)
+	if err = b.exactlyOnceDest.MergeWithOffsets(rest, offsets); err != nil {
+		return err
+	}
+
+	b.writtenOffsets.Written(rest.RawName(), offsets)
+	return nil
+}
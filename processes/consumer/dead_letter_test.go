@@ -0,0 +1,317 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics"
+	"github.com/artie-labs/transfer/models/event"
+)
+
+// poisonDestination will reject any write that contains a row with the `poison` primary key.
+type poisonDestination struct {
+	mu        sync.Mutex
+	retryable bool
+	writes    int
+	written   []string
+}
+
+func (p *poisonDestination) Label() constants.DestinationKind {
+	return "mock"
+}
+
+func (p *poisonDestination) write(tableData *optimization.TableData) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.writes++
+	for _, row := range tableData.Rows() {
+		if row["id"] == "poison" {
+			return fmt.Errorf("invalid input syntax for type integer")
+		}
+	}
+
+	for _, row := range tableData.Rows() {
+		p.written = append(p.written, fmt.Sprint(row["id"]))
+	}
+
+	return nil
+}
+
+func (p *poisonDestination) Merge(tableData *optimization.TableData) error {
+	return p.write(tableData)
+}
+
+func (p *poisonDestination) Append(tableData *optimization.TableData) error {
+	return p.write(tableData)
+}
+
+func (p *poisonDestination) IsRetryableError(_ error) bool {
+	return p.retryable
+}
+
+type fakeDeadLetterPublisher struct {
+	mu          sync.Mutex
+	keys        []string
+	deadLetters []DeadLetter
+}
+
+func (f *fakeDeadLetterPublisher) Publish(_ context.Context, key string, value []byte) error {
+	var deadLetter DeadLetter
+	if err := json.Unmarshal(value, &deadLetter); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys = append(f.keys, key)
+	f.deadLetters = append(f.deadLetters, deadLetter)
+	return nil
+}
+
+func (f *fakeDeadLetterPublisher) Close() error {
+	return nil
+}
+
+func TestNewDeadLetterPublisher(t *testing.T) {
+	{
+		// Not configured
+		publisher, err := NewDeadLetterPublisher(context.Background(), config.Config{Queue: constants.Kafka})
+		assert.NoError(t, err)
+		assert.Nil(t, publisher)
+	}
+	{
+		// Bisect is not enabled
+		publisher, err := NewDeadLetterPublisher(context.Background(), config.Config{Queue: constants.Kafka, FlushRetry: &config.FlushRetry{MaxBatchRetries: 3}})
+		assert.NoError(t, err)
+		assert.Nil(t, publisher)
+	}
+	{
+		// Kafka
+		publisher, err := NewDeadLetterPublisher(context.Background(), config.Config{
+			Queue:      constants.Kafka,
+			Kafka:      &config.Kafka{BootstrapServer: "localhost:9092"},
+			FlushRetry: &config.FlushRetry{MaxBatchRetries: 3, Bisect: true, DeadLetterTopic: "transfer_dlq"},
+		})
+		assert.NoError(t, err)
+		kafkaPublisher, isOk := publisher.(*kafkaStatusPublisher)
+		assert.True(t, isOk)
+		assert.Equal(t, "transfer_dlq", kafkaPublisher.writer.Topic)
+		assert.NoError(t, publisher.Close())
+	}
+}
+
+func TestShouldBisect(t *testing.T) {
+	dest := &poisonDestination{}
+	err := fmt.Errorf("invalid input syntax for type integer")
+
+	// Not configured
+	assert.False(t, Options{}.shouldBisect(dest, err, 10))
+
+	opts := Options{flushRetry: &config.FlushRetry{MaxBatchRetries: 3}}
+	assert.False(t, opts.shouldBisect(dest, err, 10))
+
+	opts = Options{flushRetry: &config.FlushRetry{MaxBatchRetries: 3, Bisect: true, DeadLetterTopic: "transfer_dlq"}, deadLetterPublisher: &fakeDeadLetterPublisher{}}
+	assert.False(t, opts.shouldBisect(dest, err, 2))
+	assert.True(t, opts.shouldBisect(dest, err, 3))
+	assert.True(t, opts.shouldBisect(dest, err, 4))
+
+	// Retryable errors are not caused by the rows.
+	assert.False(t, opts.shouldBisect(dest, destination.NewRetryableError(err), 3))
+	// Neither are auth errors.
+	assert.False(t, opts.shouldBisect(dest, destination.NewAuthError(fmt.Errorf("invalid credentials")), 3))
+	assert.True(t, opts.shouldBisect(dest, destination.NewSchemaError(err), 3))
+	dest.retryable = true
+	assert.False(t, opts.shouldBisect(dest, err, 3))
+}
+
+func TestWriteBisected(t *testing.T) {
+	publisher := &fakeDeadLetterPublisher{}
+	opts := Options{flushRetry: &config.FlushRetry{MaxBatchRetries: 1, Bisect: true, DeadLetterTopic: "transfer_dlq"}, deadLetterPublisher: publisher}
+	{
+		// A single row that fails on its own is published.
+		dest := &poisonDestination{}
+		tableData := optimization.NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "orders")
+		tableData.InsertRow("poison", map[string]any{"id": "poison"}, false)
+
+		deadLetters, err := writeBisected(context.Background(), opts, dest, tableData, fmt.Errorf("original error"))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, deadLetters)
+		assert.Zero(t, dest.writes)
+		assert.Equal(t, "original error", publisher.deadLetters[0].Error)
+		publisher.keys, publisher.deadLetters = nil, nil
+	}
+	{
+		// Every row fails, each row should be isolated instead of stopping early.
+		dest := &poisonDestination{}
+		tableData := optimization.NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "orders")
+		for _, pk := range []string{"1", "2", "3", "4", "5", "6", "7", "8"} {
+			tableData.InsertRow(pk, map[string]any{"id": "poison"}, false)
+		}
+
+		deadLetters, err := writeBisected(context.Background(), opts, dest, tableData, fmt.Errorf("original error"))
+		assert.NoError(t, err)
+		assert.Equal(t, 8, deadLetters)
+		assert.Equal(t, 14, dest.writes)
+		assert.Len(t, publisher.deadLetters, 8)
+		publisher.keys, publisher.deadLetters = nil, nil
+	}
+	{
+		// A half fails with an error that is not caused by the rows, so we should stop.
+		dest := &poisonDestination{retryable: true}
+		tableData := optimization.NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{}, "orders")
+		for _, pk := range []string{"1", "2", "3", "4"} {
+			tableData.InsertRow(pk, map[string]any{"id": "poison"}, false)
+		}
+
+		deadLetters, err := writeBisected(context.Background(), opts, dest, tableData, fmt.Errorf("original error"))
+		assert.ErrorContains(t, err, "invalid input syntax for type integer")
+		assert.Zero(t, deadLetters)
+		assert.Equal(t, 1, dest.writes)
+		assert.Empty(t, publisher.deadLetters)
+	}
+	{
+		// Append only
+		dest := &poisonDestination{}
+		tableData := optimization.NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{AppendOnly: true, Topic: "foo"}, "events")
+		for _, id := range []string{"1", "2", "poison", "3"} {
+			tableData.InsertRow(id, map[string]any{"id": id}, false)
+		}
+
+		deadLetters, err := writeBisected(context.Background(), opts, dest, tableData, fmt.Errorf("original error"))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, deadLetters)
+		assert.Equal(t, []string{"1", "2", "3"}, dest.written)
+		assert.Equal(t, []string{"events"}, publisher.keys)
+		assert.Equal(t, map[string]any{"id": "poison"}, publisher.deadLetters[0].Row)
+		assert.Equal(t, "invalid input syntax for type integer", publisher.deadLetters[0].Error)
+	}
+}
+
+func TestWriteBisected_ExactlyOnce(t *testing.T) {
+	publisher := &fakeDeadLetterPublisher{}
+	opts := Options{
+		flushRetry:          &config.FlushRetry{MaxBatchRetries: 1, Bisect: true, DeadLetterTopic: "transfer_dlq"},
+		deadLetterPublisher: publisher,
+		writtenOffsets:      newWrittenOffsetCache(),
+	}
+
+	poisonDest := &poisonDestination{}
+	dest := &exactlyOnceDest{Baseline: poisonDest}
+	tableData := optimization.NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{Topic: "foo"}, "orders")
+	for offset, id := range []string{"1", "poison", "2", "3"} {
+		tableData.InsertRow(id, map[string]any{"id": id}, false)
+		kafkaMsg := kafka.Message{Topic: "foo", Partition: 0, Offset: int64(offset)}
+		tableData.PartitionsToLastMessage["foo-0"] = []artie.Message{artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic)}
+	}
+
+	deadLetters, err := writeBisected(context.Background(), opts, dest, tableData, fmt.Errorf("original error"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deadLetters)
+
+	// The halves are merged without the offsets, the offsets are written along with the rows that did not fail.
+	for _, offsets := range dest.merged[:len(dest.merged)-1] {
+		assert.Empty(t, offsets)
+	}
+
+	assert.Equal(t, []types.KafkaOffset{{Topic: "foo", Partition: 0, Offset: 3}}, dest.merged[len(dest.merged)-1])
+	sort.Strings(poisonDest.written)
+	assert.Equal(t, []string{"1", "1", "2", "2", "3", "3"}, poisonDest.written)
+}
+
+func TestRouteRejectedRows(t *testing.T) {
+	tableData := optimization.NewTableData(nil, config.Replication, []string{"id"}, kafkalib.TopicConfig{Topic: "foo", Database: "shop", Schema: "public"}, "orders")
+	rejectedErr := destination.NewRejectedRowsError([]map[string]any{{"id": "1"}, {"id": "2"}}, fmt.Errorf("unavailable toast value"))
+	{
+		// Not a rejected rows error
+		_, err := Options{}.routeRejectedRows(context.Background(), tableData, fmt.Errorf("some error"))
+		assert.ErrorContains(t, err, "some error")
+	}
+	{
+		// There is no dead letter topic
+		_, err := Options{}.routeRejectedRows(context.Background(), tableData, rejectedErr)
+		assert.ErrorIs(t, err, rejectedErr)
+	}
+	{
+		publisher := &fakeDeadLetterPublisher{}
+		opts := Options{flushRetry: &config.FlushRetry{MaxBatchRetries: 3, DeadLetterTopic: "transfer_dlq"}, deadLetterPublisher: publisher}
+		deadLetters, err := opts.routeRejectedRows(context.Background(), tableData, destination.NewPermanentError(rejectedErr))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, deadLetters)
+		assert.Equal(t, []string{"orders", "orders"}, publisher.keys)
+		assert.Equal(t, map[string]any{"id": "2"}, publisher.deadLetters[1].Row)
+		assert.Equal(t, "shop", publisher.deadLetters[1].Database)
+		assert.Equal(t, "unavailable toast value", publisher.deadLetters[1].Error)
+	}
+}
+
+func (f *FlushTestSuite) TestFlushBisectsPoisonBatch() {
+	dest := &poisonDestination{}
+	publisher := &fakeDeadLetterPublisher{}
+	opts := Options{
+		flushRetry:          &config.FlushRetry{MaxBatchRetries: 2, Bisect: true, DeadLetterTopic: "transfer_dlq"},
+		deadLetterPublisher: publisher,
+		kafkaOffsets:        newOffsetTracker(),
+	}
+
+	ids := []string{"1", "2", "3", "poison", "4", "5", "6"}
+	for offset, id := range ids {
+		evt := event.Event{
+			Table:         "orders",
+			PrimaryKeyMap: map[string]any{"id": id},
+			Data: map[string]any{
+				"id":                         id,
+				constants.DeleteColumnMarker: false,
+			},
+		}
+
+		kafkaMsg := kafka.Message{Topic: "foo", Partition: 1, Offset: int64(offset)}
+		_, _, err := evt.Save(f.cfg, f.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(f.T(), err)
+		opts.kafkaOffsets.Buffered("orders", kafkaMsg)
+		opts.kafkaOffsets.Processed(kafkaMsg)
+	}
+
+	{
+		// The first failure is retried as a whole.
+		assert.NoError(f.T(), Flush(context.Background(), f.db, dest, metrics.NullMetricsProvider{}, Args{Options: opts, Reason: "time"}))
+		assert.Equal(f.T(), 1, dest.writes)
+		assert.Empty(f.T(), dest.written)
+		assert.Empty(f.T(), publisher.deadLetters)
+		assert.Equal(f.T(), uint(len(ids)), f.db.GetOrCreateTableData("orders").NumberOfRows())
+		assert.Equal(f.T(), 0, f.fakeConsumer.CommitMessagesCallCount())
+	}
+	{
+		// Once we have hit the max retries, the batch is bisected and the good rows are written.
+		assert.NoError(f.T(), Flush(context.Background(), f.db, dest, metrics.NullMetricsProvider{}, Args{Options: opts, Reason: "time"}))
+		sort.Strings(dest.written)
+		assert.Equal(f.T(), []string{"1", "2", "3", "4", "5", "6"}, dest.written)
+		assert.Equal(f.T(), []string{"orders"}, publisher.keys)
+		assert.Len(f.T(), publisher.deadLetters, 1)
+
+		deadLetter := publisher.deadLetters[0]
+		assert.Equal(f.T(), "orders", deadLetter.Table)
+		assert.Equal(f.T(), "customer", deadLetter.Database)
+		assert.Equal(f.T(), "public", deadLetter.Schema)
+		assert.Equal(f.T(), "foo", deadLetter.Topic)
+		assert.Equal(f.T(), "poison", deadLetter.Row["id"])
+		assert.Equal(f.T(), "invalid input syntax for type integer", deadLetter.Error)
+
+		// The table has been flushed and the offsets have been committed.
+		assert.True(f.T(), f.db.GetOrCreateTableData("orders").Empty())
+		assert.Equal(f.T(), 1, f.fakeConsumer.CommitMessagesCallCount())
+	}
+}
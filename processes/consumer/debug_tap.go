@@ -0,0 +1,86 @@
+package consumer
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/artie-labs/transfer/lib/config"
+)
+
+// debugTap will capture the first N raw messages for each topic along with their parse result, then disable itself for that topic.
+// A nil debugTap is valid and will not capture anything.
+type debugTap struct {
+	limit  int
+	logger *slog.Logger
+	// file is set if the samples are being written to a file.
+	file *os.File
+
+	mu       sync.Mutex
+	captured map[string]int
+}
+
+// newDebugTap returns nil if sampling is not enabled.
+func newDebugTap(cfg *config.Debug) (*debugTap, error) {
+	if cfg == nil || cfg.SampleMessages <= 0 {
+		return nil, nil
+	}
+
+	if cfg.SampleFilePath == "" {
+		return newDebugTapWithLogger(cfg.SampleMessages, slog.Default()), nil
+	}
+
+	file, err := os.OpenFile(cfg.SampleFilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sample file: %w", err)
+	}
+
+	tap := newDebugTapWithLogger(cfg.SampleMessages, slog.New(slog.NewJSONHandler(file, nil)))
+	tap.file = file
+	return tap, nil
+}
+
+func newDebugTapWithLogger(limit int, logger *slog.Logger) *debugTap {
+	return &debugTap{
+		limit:    limit,
+		logger:   logger,
+		captured: make(map[string]int),
+	}
+}
+
+// Capture will write the message's `logFields` with its parse result if we haven't captured enough messages for `topic` yet.
+// It returns true if the message was captured.
+func (d *debugTap) Capture(topic string, logFields []any, processErr error) bool {
+	if d == nil {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.captured[topic] >= d.limit {
+		return false
+	}
+
+	d.captured[topic]++
+	result := slog.String("result", "ok")
+	if processErr != nil {
+		result = slog.String("result", processErr.Error())
+	}
+
+	d.logger.With(logFields...).Info("Sampled message", result, slog.Int("sample", d.captured[topic]))
+	if d.captured[topic] == d.limit {
+		d.logger.Info(fmt.Sprintf("Captured %d sample messages, no longer sampling this topic", d.limit), slog.String("topic", topic))
+	}
+
+	return true
+}
+
+// Close will close the sample file, if there is one.
+func (d *debugTap) Close() error {
+	if d == nil || d.file == nil {
+		return nil
+	}
+
+	return d.file.Close()
+}
@@ -0,0 +1,102 @@
+package consumer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+)
+
+func readSamples(t *testing.T, data string) []map[string]any {
+	var samples []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		var sample map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(line), &sample))
+		if sample["msg"] == "Sampled message" {
+			samples = append(samples, sample)
+		}
+	}
+
+	return samples
+}
+
+func TestNewDebugTap(t *testing.T) {
+	{
+		// Not enabled
+		tap, err := newDebugTap(nil)
+		assert.NoError(t, err)
+		assert.Nil(t, tap)
+		assert.False(t, tap.Capture("foo", nil, nil))
+		assert.NoError(t, tap.Close())
+
+		tap, err = newDebugTap(&config.Debug{})
+		assert.NoError(t, err)
+		assert.Nil(t, tap)
+	}
+	{
+		// Sample file
+		fp := filepath.Join(t.TempDir(), "samples.jsonl")
+		tap, err := newDebugTap(&config.Debug{SampleMessages: 1, SampleFilePath: fp})
+		assert.NoError(t, err)
+		assert.True(t, tap.Capture("foo", []any{slog.String("key", "1")}, nil))
+		assert.False(t, tap.Capture("foo", []any{slog.String("key", "2")}, nil))
+		assert.NoError(t, tap.Close())
+
+		data, err := os.ReadFile(fp)
+		assert.NoError(t, err)
+		samples := readSamples(t, string(data))
+		assert.Len(t, samples, 1)
+		assert.Equal(t, "1", samples[0]["key"])
+	}
+	{
+		// Sample file cannot be opened
+		_, err := newDebugTap(&config.Debug{SampleMessages: 1, SampleFilePath: filepath.Join(t.TempDir(), "missing", "samples.jsonl")})
+		assert.ErrorContains(t, err, "failed to open sample file")
+	}
+}
+
+func TestDebugTap_Capture(t *testing.T) {
+	var buf bytes.Buffer
+	tap := newDebugTapWithLogger(3, slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	for i := range 5 {
+		logFields := []any{
+			slog.String("topic", "foo"),
+			slog.String("key", fmt.Sprintf("key-%d", i)),
+			slog.String("value", fmt.Sprintf("value-%d", i)),
+		}
+
+		var processErr error
+		if i == 1 {
+			processErr = fmt.Errorf("cannot unmarshall event")
+		}
+
+		// Only the first 3 messages should be captured.
+		assert.Equal(t, i < 3, tap.Capture("foo", logFields, processErr), i)
+	}
+
+	// Other topics are sampled separately.
+	assert.True(t, tap.Capture("bar", []any{slog.String("topic", "bar")}, nil))
+
+	samples := readSamples(t, buf.String())
+	assert.Len(t, samples, 4)
+	for i, sample := range samples[:3] {
+		assert.Equal(t, "foo", sample["topic"])
+		assert.Equal(t, fmt.Sprintf("key-%d", i), sample["key"])
+		assert.Equal(t, fmt.Sprintf("value-%d", i), sample["value"])
+		assert.Equal(t, float64(i+1), sample["sample"])
+	}
+
+	assert.Equal(t, "ok", samples[0]["result"])
+	assert.Equal(t, "cannot unmarshall event", samples[1]["result"])
+	assert.Equal(t, "bar", samples[3]["topic"])
+	assert.Contains(t, buf.String(), "Captured 3 sample messages, no longer sampling this topic")
+}
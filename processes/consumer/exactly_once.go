@@ -0,0 +1,148 @@
+package consumer
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+)
+
+// exactlyOnceDestination returns `dest` if exactly-once delivery is enabled and `dest` can write the offsets within the merge transaction.
+func (o Options) exactlyOnceDestination(dest destination.Baseline) (destination.ExactlyOnce, bool) {
+	if o.writtenOffsets == nil {
+		return nil, false
+	}
+
+	exactlyOnceDest, isOk := dest.(destination.ExactlyOnce)
+	return exactlyOnceDest, isOk
+}
+
+type topicTable struct {
+	topic string
+	table string
+}
+
+// writtenOffsetCache contains the last offset that has been written into each table for each partition.
+// These are loaded from the destination, so that messages that are replayed after a crash (the offset was written but never committed to Kafka) are not applied twice.
+type writtenOffsetCache struct {
+	tables map[topicTable]*tableOffsets
+	sync.Mutex
+}
+
+// tableOffsets is keyed by partition, `offsets` is nil until they have been loaded from the destination.
+type tableOffsets struct {
+	offsets map[int]int64
+	sync.Mutex
+}
+
+func newWrittenOffsetCache() *writtenOffsetCache {
+	return &writtenOffsetCache{
+		tables: make(map[topicTable]*tableOffsets),
+	}
+}
+
+// table returns the offsets for `key`, the cache is only locked while looking it up so that one table loading its offsets does not block the others.
+func (w *writtenOffsetCache) table(key topicTable, create bool) *tableOffsets {
+	w.Lock()
+	defer w.Unlock()
+
+	table, isOk := w.tables[key]
+	if !isOk && create {
+		table = &tableOffsets{}
+		w.tables[key] = table
+	}
+
+	return table
+}
+
+// AlreadyWritten returns true if `msg` has already been written into `tableName`.
+// The offsets are loaded from `dest` once per table, until the topic's partitions are reassigned.
+func (w *writtenOffsetCache) AlreadyWritten(dest destination.ExactlyOnce, tc kafkalib.TopicConfig, tableName string, msg kafka.Message) (bool, error) {
+	table := w.table(topicTable{topic: msg.Topic, table: tc.TableNameFor(tableName)}, true)
+	table.Lock()
+	defer table.Unlock()
+
+	if table.offsets == nil {
+		offsets, err := dest.WrittenOffsets(optimization.NewTableData(nil, config.Replication, nil, tc, tableName))
+		if err != nil {
+			return false, fmt.Errorf("failed to load written offsets: %w", err)
+		}
+
+		if offsets == nil {
+			offsets = make(map[int]int64)
+		}
+
+		table.offsets = offsets
+	}
+
+	offset, isOk := table.offsets[msg.Partition]
+	return isOk && msg.Offset <= offset, nil
+}
+
+// Written is called once `offsets` have been written into `tableName`.
+func (w *writtenOffsetCache) Written(tableName string, offsets []types.KafkaOffset) {
+	for _, offset := range offsets {
+		table := w.table(topicTable{topic: offset.Topic, table: tableName}, false)
+		if table == nil {
+			// We haven't loaded the offsets for this table yet, they'll be read from the destination.
+			continue
+		}
+
+		table.Lock()
+		if table.offsets != nil {
+			if existing, isOk := table.offsets[offset.Partition]; !isOk || offset.Offset > existing {
+				table.offsets[offset.Partition] = offset.Offset
+			}
+		}
+		table.Unlock()
+	}
+}
+
+// Forget will drop the cached offsets for every table of `topic`, another consumer may have written to these tables while the partitions were assigned to it.
+func (w *writtenOffsetCache) Forget(topic string) {
+	w.Lock()
+	defer w.Unlock()
+
+	for key := range w.tables {
+		if key.topic == topic {
+			delete(w.tables, key)
+		}
+	}
+}
+
+// lastKafkaOffsets returns the last Kafka offset of each partition that is buffered in `partitionsToLastMessage`.
+func lastKafkaOffsets(partitionsToLastMessage map[string][]artie.Message) []types.KafkaOffset {
+	var offsets []types.KafkaOffset
+	for _, msgs := range partitionsToLastMessage {
+		for _, msg := range msgs {
+			if msg.KafkaMsg == nil {
+				continue
+			}
+
+			idx := slices.IndexFunc(offsets, func(offset types.KafkaOffset) bool {
+				return offset.Topic == msg.KafkaMsg.Topic && offset.Partition == msg.KafkaMsg.Partition
+			})
+
+			if idx < 0 {
+				offsets = append(offsets, types.KafkaOffset{Topic: msg.KafkaMsg.Topic, Partition: msg.KafkaMsg.Partition, Offset: msg.KafkaMsg.Offset})
+			} else if msg.KafkaMsg.Offset > offsets[idx].Offset {
+				offsets[idx].Offset = msg.KafkaMsg.Offset
+			}
+		}
+	}
+
+	slices.SortFunc(offsets, func(a, b types.KafkaOffset) int {
+		return cmp.Or(cmp.Compare(a.Topic, b.Topic), cmp.Compare(a.Partition, b.Partition))
+	})
+
+	return offsets
+}
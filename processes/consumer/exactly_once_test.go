@@ -0,0 +1,313 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/cdc/mongo"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics"
+	"github.com/artie-labs/transfer/models"
+	"github.com/artie-labs/transfer/models/event"
+)
+
+type exactlyOnceDest struct {
+	destination.Baseline
+	// written is returned by WrittenOffsets.
+	written  map[int]int64
+	mergeErr error
+	// loadErrs is the number of times WrittenOffsets should fail before succeeding.
+	loadErrs int
+
+	loads  int
+	merged [][]types.KafkaOffset
+}
+
+func (e *exactlyOnceDest) MergeWithOffsets(tableData *optimization.TableData, offsets []types.KafkaOffset) error {
+	if e.mergeErr != nil {
+		return e.mergeErr
+	}
+
+	e.merged = append(e.merged, offsets)
+	return e.Baseline.Merge(tableData)
+}
+
+func (e *exactlyOnceDest) WrittenOffsets(_ *optimization.TableData) (map[int]int64, error) {
+	e.loads++
+	if e.loads <= e.loadErrs {
+		return nil, fmt.Errorf("failed to connect")
+	}
+
+	return maps.Clone(e.written), nil
+}
+
+func TestLastKafkaOffsets(t *testing.T) {
+	newMessage := func(topic string, partition int, offset int64) artie.Message {
+		return artie.NewMessage(&kafka.Message{Topic: topic, Partition: partition, Offset: offset}, nil, topic)
+	}
+
+	assert.Empty(t, lastKafkaOffsets(nil))
+	assert.Equal(t, []types.KafkaOffset{
+		{Topic: "bar", Partition: 0, Offset: 3},
+		{Topic: "foo", Partition: 0, Offset: 5},
+		{Topic: "foo", Partition: 1, Offset: 9},
+	}, lastKafkaOffsets(map[string][]artie.Message{
+		"foo-1": {newMessage("foo", 1, 9)},
+		"foo-0": {newMessage("foo", 0, 2), newMessage("foo", 0, 5)},
+		"bar-0": {newMessage("bar", 0, 3)},
+	}))
+}
+
+func TestWrittenOffsetCache(t *testing.T) {
+	dest := &exactlyOnceDest{written: map[int]int64{0: 10}}
+	cache := newWrittenOffsetCache()
+
+	alreadyWritten := func(partition int, offset int64) bool {
+		written, err := cache.AlreadyWritten(dest, kafkalib.TopicConfig{Topic: "foo"}, "orders", kafka.Message{Topic: "foo", Partition: partition, Offset: offset})
+		assert.NoError(t, err)
+		return written
+	}
+
+	assert.True(t, alreadyWritten(0, 10))
+	assert.False(t, alreadyWritten(0, 11))
+	// Nothing has been written for this partition yet.
+	assert.False(t, alreadyWritten(1, 0))
+	assert.Equal(t, 1, dest.loads)
+
+	cache.Written("orders", []types.KafkaOffset{{Topic: "foo", Partition: 0, Offset: 20}, {Topic: "foo", Partition: 1, Offset: 3}})
+	assert.True(t, alreadyWritten(0, 20))
+	assert.True(t, alreadyWritten(1, 3))
+	assert.Equal(t, 1, dest.loads)
+
+	// Once forgotten, the offsets are loaded again from the destination.
+	cache.Forget("foo")
+	assert.False(t, alreadyWritten(0, 20))
+	assert.Equal(t, 2, dest.loads)
+}
+
+// blockingOffsetsDest will block loading the offsets of `blockTable` until `release` is closed, `loading` is closed once it starts blocking.
+type blockingOffsetsDest struct {
+	exactlyOnceDest
+	blockTable string
+	loading    chan struct{}
+	release    chan struct{}
+}
+
+func (b *blockingOffsetsDest) WrittenOffsets(tableData *optimization.TableData) (map[int]int64, error) {
+	if tableData.RawName() == b.blockTable {
+		close(b.loading)
+		<-b.release
+	}
+
+	return map[int]int64{0: 10}, nil
+}
+
+func TestWrittenOffsetCache_LoadsTablesIndependently(t *testing.T) {
+	dest := &blockingOffsetsDest{blockTable: "orders", loading: make(chan struct{}), release: make(chan struct{})}
+	cache := newWrittenOffsetCache()
+
+	ordersLoaded := make(chan bool)
+	go func() {
+		written, err := cache.AlreadyWritten(dest, kafkalib.TopicConfig{Topic: "foo"}, "orders", kafka.Message{Topic: "foo", Offset: 10})
+		assert.NoError(t, err)
+		ordersLoaded <- written
+	}()
+
+	<-dest.loading
+	// Loading the offsets for orders should not block the other tables.
+	written, err := cache.AlreadyWritten(dest, kafkalib.TopicConfig{Topic: "foo"}, "customers", kafka.Message{Topic: "foo", Offset: 10})
+	assert.NoError(t, err)
+	assert.True(t, written)
+	cache.Written("customers", []types.KafkaOffset{{Topic: "foo", Partition: 0, Offset: 20}})
+
+	close(dest.release)
+	assert.True(t, <-ordersLoaded)
+}
+
+func TestProcessMessageExactlyOnce(t *testing.T) {
+	cfg := config.Config{
+		FlushIntervalSeconds: 10,
+		BufferRows:           10,
+		FlushSizeKb:          900,
+	}
+
+	kafkaMsg := kafka.Message{Topic: "foo"}
+	msg := artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic)
+	tc := &kafkalib.TopicConfig{
+		Database:     "lemonade",
+		TableName:    "orders",
+		Schema:       "public",
+		Topic:        msg.Topic(),
+		CDCFormat:    constants.DBZMongoFormat,
+		CDCKeyFormat: kafkalib.StringKeyFmt,
+	}
+	tc.Load()
+
+	var mgo mongo.Debezium
+	tcFmtMap := NewTcFmtMap()
+	tcFmtMap.Add(msg.Topic(), TopicConfigFormatter{tc: tc, Format: &mgo})
+	processMessages := func(opts Options, dest destination.Baseline) *models.DatabaseData {
+		args := processArgs{
+			Msg:                    msg,
+			GroupID:                "foo",
+			TopicToConfigFormatMap: tcFmtMap,
+			Options:                opts,
+		}
+
+		memDB := models.NewMemoryDB()
+		for offset := 0; offset < 4; offset++ {
+			msg.KafkaMsg.Offset = int64(offset)
+			msg.KafkaMsg.Key = []byte(fmt.Sprintf("Struct{id=%d}", offset))
+			msg.KafkaMsg.Value = []byte(fmt.Sprintf(`{
+	"payload": {
+		"before": null,
+		"after": "{\"_id\": {\"$numberLong\": \"%d\"}}",
+		"source": {"connector": "mongodb", "ts_ms": 1668753321000, "db": "inventory", "collection": "orders"},
+		"op": "c"
+	}
+}`, offset))
+
+			tableName, err := args.process(context.Background(), cfg, memDB, dest, metrics.NullMetricsProvider{})
+			assert.NoError(t, err)
+			assert.Equal(t, "orders", tableName)
+		}
+
+		return memDB
+	}
+
+	{
+		// Offsets 0 and 1 were written before we crashed, so they should be skipped.
+		dest := &exactlyOnceDest{Baseline: MockDestination{}, written: map[int]int64{0: 1}}
+		memDB := processMessages(Options{writtenOffsets: newWrittenOffsetCache()}, dest)
+		assert.Equal(t, 2, int(memDB.GetOrCreateTableData("orders").NumberOfRows()))
+		assert.Equal(t, 1, dest.loads)
+	}
+	{
+		// At least once, nothing is skipped.
+		dest := &exactlyOnceDest{Baseline: MockDestination{}, written: map[int]int64{0: 1}}
+		memDB := processMessages(Options{}, dest)
+		assert.Equal(t, 4, int(memDB.GetOrCreateTableData("orders").NumberOfRows()))
+		assert.Equal(t, 0, dest.loads)
+	}
+}
+
+func TestProcessKafkaMessage_WrittenOffsetsFail(t *testing.T) {
+	defer func(maxRetryMs int) { blockedMessageMaxRetryMs = maxRetryMs }(blockedMessageMaxRetryMs)
+	blockedMessageMaxRetryMs = 0
+
+	cfg := config.Config{
+		FlushIntervalSeconds: 10,
+		BufferRows:           10,
+		FlushSizeKb:          900,
+	}
+
+	tc := &kafkalib.TopicConfig{
+		Database:     "lemonade",
+		TableName:    "orders",
+		Schema:       "public",
+		Topic:        "foo",
+		CDCFormat:    constants.DBZMongoFormat,
+		CDCKeyFormat: kafkalib.StringKeyFmt,
+	}
+	tc.Load()
+
+	var mgo mongo.Debezium
+	tcFmtMap := NewTcFmtMap()
+	tcFmtMap.Add(tc.Topic, TopicConfigFormatter{tc: tc, Format: &mgo})
+	kafkaMsg := kafka.Message{
+		Topic:  tc.Topic,
+		Offset: 5,
+		Key:    []byte("Struct{id=1}"),
+		Value: []byte(`{
+	"payload": {
+		"before": null,
+		"after": "{\"_id\": {\"$numberLong\": \"1\"}}",
+		"source": {"connector": "mongodb", "ts_ms": 1668753321000, "db": "inventory", "collection": "orders"},
+		"op": "c"
+	}
+}`),
+	}
+
+	{
+		// The message should be retried until the offsets have been loaded, instead of being skipped.
+		memDB := models.NewMemoryDB()
+		dest := &exactlyOnceDest{Baseline: MockDestination{}, loadErrs: 2}
+		opts := Options{writtenOffsets: newWrittenOffsetCache(), kafkaOffsets: newOffsetTracker()}
+		processKafkaMessage(context.Background(), cfg, "group", memDB, dest, metrics.NullMetricsProvider{}, opts, tcFmtMap, nil, kafkaMsg)
+		assert.Equal(t, 3, dest.loads)
+		assert.Equal(t, 1, int(memDB.GetOrCreateTableData("orders").NumberOfRows()))
+		assert.Equal(t, int64(5), opts.kafkaOffsets.processed[newTopicPartition(kafkaMsg)])
+	}
+	{
+		// Shutting down while retrying, the message should not be marked as processed.
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		memDB := models.NewMemoryDB()
+		dest := &exactlyOnceDest{Baseline: MockDestination{}, loadErrs: 100}
+		opts := Options{writtenOffsets: newWrittenOffsetCache(), kafkaOffsets: newOffsetTracker()}
+		processKafkaMessage(ctx, cfg, "group", memDB, dest, metrics.NullMetricsProvider{}, opts, tcFmtMap, nil, kafkaMsg)
+		assert.Equal(t, 0, int(memDB.GetOrCreateTableData("orders").NumberOfRows()))
+		assert.NotContains(t, opts.kafkaOffsets.processed, newTopicPartition(kafkaMsg))
+	}
+}
+
+func (f *FlushTestSuite) TestFlushExactlyOnce() {
+	opts := Options{writtenOffsets: newWrittenOffsetCache(), kafkaOffsets: newOffsetTracker()}
+	tc := &kafkalib.TopicConfig{Database: "customer", TableName: "orders", Schema: "public", Topic: "foo"}
+	dest := &exactlyOnceDest{Baseline: f.dwh, written: map[int]int64{1: 0}, mergeErr: fmt.Errorf("transaction aborted")}
+
+	alreadyWritten := func(offset int64) bool {
+		written, err := opts.writtenOffsets.AlreadyWritten(dest, *tc, "orders", kafka.Message{Topic: "foo", Partition: 1, Offset: offset})
+		assert.NoError(f.T(), err)
+		return written
+	}
+
+	assert.False(f.T(), alreadyWritten(1))
+	for offset := 1; offset <= 3; offset++ {
+		evt := event.Event{
+			Table:         "orders",
+			PrimaryKeyMap: map[string]any{"id": fmt.Sprintf("pk-%d", offset)},
+			Data: map[string]any{
+				"id":                         fmt.Sprintf("pk-%d", offset),
+				constants.DeleteColumnMarker: false,
+			},
+		}
+
+		kafkaMsg := kafka.Message{Topic: "foo", Partition: 1, Offset: int64(offset)}
+		_, _, err := evt.Save(f.cfg, f.db, tc, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(f.T(), err)
+		opts.kafkaOffsets.Buffered("orders", kafkaMsg)
+	}
+
+	{
+		// The transaction was aborted, so neither the committed nor the written offsets should advance.
+		assert.NoError(f.T(), Flush(context.Background(), f.db, dest, metrics.NullMetricsProvider{}, Args{Options: opts, Reason: "time"}))
+		assert.Equal(f.T(), 0, f.fakeConsumer.CommitMessagesCallCount())
+		assert.False(f.T(), f.db.GetOrCreateTableData("orders").Empty())
+		assert.False(f.T(), alreadyWritten(3))
+	}
+	{
+		// Once the transaction goes through, the offsets are committed.
+		dest.mergeErr = nil
+		assert.NoError(f.T(), Flush(context.Background(), f.db, dest, metrics.NullMetricsProvider{}, Args{Options: opts, Reason: "time"}))
+		assert.Equal(f.T(), [][]types.KafkaOffset{{{Topic: "foo", Partition: 1, Offset: 3}}}, dest.merged)
+		assert.Equal(f.T(), 1, f.fakeConsumer.CommitMessagesCallCount())
+		_, kafkaMessages := f.fakeConsumer.CommitMessagesArgsForCall(0)
+		assert.Equal(f.T(), int64(3), kafkaMessages[0].Offset)
+		assert.True(f.T(), f.db.GetOrCreateTableData("orders").Empty())
+		assert.True(f.T(), alreadyWritten(3))
+		assert.False(f.T(), alreadyWritten(4))
+	}
+}
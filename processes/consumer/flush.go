@@ -2,23 +2,33 @@ package consumer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
 	"time"
 
-	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/health"
+	"github.com/artie-labs/transfer/lib/logger"
 	"github.com/artie-labs/transfer/lib/stringutil"
 	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
 	"github.com/artie-labs/transfer/models"
 )
 
 type Args struct {
-	// If cooldown is passed in, we'll skip the flush if the table has been recently flushed
+	Options Options
+
+	// If cooldown is passed in, we'll skip the flush if the table has been recently flushed.
+	// This will be replaced by the topic's flush interval if it has been overridden.
 	CoolDown *time.Duration
+	// If idleFor is passed in, we'll only flush tables that have not received a new row for this long.
+	IdleFor *time.Duration
 	// If specificTable is not passed in, we'll just flush everything.
 	SpecificTable string
+	// If partitions is passed in, we'll only flush tables that have buffered messages from these partitions of `Topic`.
+	Topic      string
+	Partitions []int
 
 	// Reason (reason for the flush)
 	Reason string
@@ -27,6 +37,7 @@ type Args struct {
 // Flush will merge/append and commit the offset on the specified topics within `args.SpecificTable`.
 // If the table list is empty, it'll flush everything. This is the default behavior for the time duration based flush.
 // Table specific flushes will be triggered based on the size of the pool (length and size wise).
+// If an assertion with an error severity fails, the other tables will finish flushing and then we'll panic so that the consumer stops.
 func Flush(ctx context.Context, inMemDB *models.DatabaseData, dest destination.Baseline, metricsClient base.Client, args Args) error {
 	if inMemDB == nil {
 		return nil
@@ -46,6 +57,8 @@ func Flush(ctx context.Context, inMemDB *models.DatabaseData, dest destination.B
 
 	// Flush will take everything in memory and call the destination to create temp tables.
 	var wg sync.WaitGroup
+	var assertionErrsMu sync.Mutex
+	var assertionErrs []error
 	for tableName, tableData := range allTables {
 		if args.SpecificTable != "" && tableName != args.SpecificTable {
 			// If the table is specified within args and the table does not match the database, skip this flush.
@@ -60,11 +73,6 @@ func Flush(ctx context.Context, inMemDB *models.DatabaseData, dest destination.B
 				slog.String("tableName", _tableName),
 			}
 
-			if args.CoolDown != nil && _tableData.ShouldSkipFlush(*args.CoolDown) {
-				slog.With(logFields...).Info("Skipping flush because we are currently in a flush cooldown")
-				return
-			}
-
 			// Lock the tables when executing merge / append.
 			_tableData.Lock()
 			defer _tableData.Unlock()
@@ -72,6 +80,29 @@ func Flush(ctx context.Context, inMemDB *models.DatabaseData, dest destination.B
 				return
 			}
 
+			// Topics can override the flush interval, so the cooldown is checked once we know which topic the table is for.
+			if args.CoolDown != nil && _tableData.ShouldSkipFlush(_tableData.FlushInterval(*args.CoolDown)) {
+				slog.With(logFields...).Info("Skipping flush because we are currently in a flush cooldown")
+				return
+			}
+
+			if args.IdleFor != nil && !_tableData.IsIdle(*args.IdleFor) {
+				return
+			}
+
+			if args.Partitions != nil && !bufferedFromPartitions(_tableData.TableData, args.Topic, args.Partitions) {
+				return
+			}
+
+			// Flushes will queue up here if we have hit the max concurrent flushes or flushes per minute.
+			release, err := args.Options.flushLimiter.Acquire(ctx)
+			if err != nil {
+				slog.With(logFields...).Warn("Skipping flush, stopped waiting for the flush limiter", slog.Any("err", err))
+				return
+			}
+
+			defer release()
+
 			// This is added so that we have a new temporary table suffix for each merge / append.
 			_tableData.ResetTempTableSuffix()
 
@@ -85,26 +116,61 @@ func Flush(ctx context.Context, inMemDB *models.DatabaseData, dest destination.B
 				"reason":   args.Reason,
 			}
 
-			var err error
 			action := "merge"
 			// Merge or Append depending on the mode.
-			if _tableData.Mode() == config.History {
+			if _tableData.AppendOnly() {
 				err = dest.Append(_tableData.TableData)
 				action = "append"
+			} else if exactlyOnceDest, isOk := args.Options.exactlyOnceDestination(dest); isOk {
+				// The offsets are written within the merge transaction, if it is aborted neither the rows nor the offsets will be written.
+				offsets := lastKafkaOffsets(_tableData.PartitionsToLastMessage)
+				if err = exactlyOnceDest.MergeWithOffsets(_tableData.TableData, offsets); err == nil {
+					args.Options.writtenOffsets.Written(_tableData.RawName(), offsets)
+				}
 			} else {
 				err = dest.Merge(_tableData.TableData)
 			}
 
+			if err != nil {
+				var deadLetters int
+				if deadLetters, err = args.Options.routeRejectedRows(ctx, _tableData.TableData, err); err == nil {
+					slog.With(logFields...).Warn(fmt.Sprintf("Published the rows that were rejected by the %s to the dead letter topic", action), slog.Int("rows", deadLetters))
+					metricsClient.Count("flush.dead_letters", int64(deadLetters), tags)
+				}
+			}
+
+			if err != nil && args.Options.shouldBisect(dest, err, _tableData.RecordFailedFlush()) {
+				slog.With(logFields...).Warn(fmt.Sprintf("Failed to execute %s, bisecting to isolate the failing rows...", action), slog.Any("err", err))
+				var deadLetters int
+				if deadLetters, err = writeBisected(ctx, args.Options, dest, _tableData.TableData, err); err == nil {
+					tags["bisected"] = "true"
+					metricsClient.Count("flush.dead_letters", int64(deadLetters), tags)
+				}
+			}
+
 			if err != nil {
 				tags["what"] = "merge_fail"
-				tags["retryable"] = fmt.Sprint(dest.IsRetryableError(err))
-				slog.With(logFields...).Error(fmt.Sprintf("Failed to execute %s, not going to flush memory, will sleep for 3 seconds before continuing...", action), slog.Any("err", err))
+				tags["retryable"] = fmt.Sprint(destination.IsRetryable(err) || dest.IsRetryableError(err))
+				tags["errorType"] = destination.ErrorType(err)
+				slog.With(logFields...).Error(fmt.Sprintf("Failed to execute %s, not going to flush memory, will sleep for 3 seconds before continuing...", action),
+					slog.Any("err", err),
+					slog.String("errorType", destination.ErrorType(err)),
+				)
 				time.Sleep(3 * time.Second)
+			} else if assertErr := checkAssertions(dest, _tableData.TableData, args.Options.identifierCasing, metricsClient); assertErr != nil {
+				// The data has already been written, but we won't commit the offsets so that the rows are consumed again once the invariant has been fixed.
+				tags["what"] = "assertion_fail"
+				assertionErrsMu.Lock()
+				assertionErrs = append(assertionErrs, fmt.Errorf("table %s: %w", _tableName, assertErr))
+				assertionErrsMu.Unlock()
 			} else {
 				slog.Info(fmt.Sprintf("%s success, clearing memory...", stringutil.CapitalizeFirstLetter(action)), logFields...)
-				commitErr := commitOffset(ctx, _tableData.TopicConfig.Topic, _tableData.PartitionsToLastMessage)
+				commitErr := args.Options.commitOffset(ctx, _tableData.TopicConfig.Topic, _tableName, _tableData.PartitionsToLastMessage)
 				if commitErr == nil {
+					// This has to be built before the table is cleared.
+					args.Options.publishFlushStatus(ctx, newFlushStatus(_tableData.TableData, action, args.Reason, time.Since(start)))
 					inMemDB.ClearTableConfig(_tableName)
+					health.Default().RecordFlush(_tableName, time.Now())
 				} else {
 					tags["what"] = "commit_fail"
 					slog.Warn("Commit error...", slog.Any("err", commitErr))
@@ -115,5 +181,10 @@ func Flush(ctx context.Context, inMemDB *models.DatabaseData, dest destination.B
 	}
 	wg.Wait()
 
+	if err := errors.Join(assertionErrs...); err != nil {
+		// Retrying would not fix the data that has already been written, so we'll stop consuming instead.
+		logger.Panic("Assertion failed, halting the consumer", slog.Any("err", err))
+	}
+
 	return nil
 }
@@ -0,0 +1,89 @@
+package consumer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FlushLimiter limits how many flushes can run at the same time and how many flushes can start per minute.
+// Flushes that are over the limit will wait until they are allowed to run.
+type FlushLimiter struct {
+	// slots is nil if the number of concurrent flushes is unlimited.
+	slots chan struct{}
+	// interval is the minimum time between the start of two flushes, it is zero if the rate is unlimited.
+	interval time.Duration
+
+	mu        sync.Mutex
+	nextStart time.Time
+}
+
+// NewFlushLimiter returns nil if neither limit is set.
+func NewFlushLimiter(maxConcurrent int, perMinute int) *FlushLimiter {
+	if maxConcurrent <= 0 && perMinute <= 0 {
+		return nil
+	}
+
+	limiter := &FlushLimiter{}
+	if maxConcurrent > 0 {
+		limiter.slots = make(chan struct{}, maxConcurrent)
+	}
+
+	if perMinute > 0 {
+		limiter.interval = time.Minute / time.Duration(perMinute)
+	}
+
+	return limiter
+}
+
+// Acquire will block until the flush is allowed to run, `release` must be called once the flush is done.
+// An error is only returned if `ctx` is done while waiting.
+func (f *FlushLimiter) Acquire(ctx context.Context) (func(), error) {
+	if f == nil {
+		return func() {}, nil
+	}
+
+	if f.slots != nil {
+		select {
+		case f.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	release := func() {
+		if f.slots != nil {
+			<-f.slots
+		}
+	}
+
+	if wait := f.reserve(time.Now()); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+
+	return release, nil
+}
+
+// reserve will reserve the next start time and return how long we need to wait until then.
+// Flushes are evenly spaced out, so we will not have a burst of flushes at the start of each minute.
+func (f *FlushLimiter) reserve(now time.Time) time.Duration {
+	if f.interval == 0 {
+		return 0
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	start := f.nextStart
+	if start.Before(now) {
+		start = now
+	}
+
+	f.nextStart = start.Add(f.interval)
+	return start.Sub(now)
+}
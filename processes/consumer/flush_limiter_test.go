@@ -0,0 +1,101 @@
+package consumer
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFlushLimiter(t *testing.T) {
+	assert.Nil(t, NewFlushLimiter(0, 0))
+
+	limiter := NewFlushLimiter(2, 0)
+	assert.Equal(t, 2, cap(limiter.slots))
+	assert.Zero(t, limiter.interval)
+
+	limiter = NewFlushLimiter(0, 120)
+	assert.Nil(t, limiter.slots)
+	assert.Equal(t, 500*time.Millisecond, limiter.interval)
+}
+
+func TestFlushLimiter_Acquire(t *testing.T) {
+	{
+		// Nil limiter should not block
+		var limiter *FlushLimiter
+		release, err := limiter.Acquire(context.Background())
+		assert.NoError(t, err)
+		release()
+	}
+	{
+		// Flushes beyond the limit should be serialized and all of them should eventually complete.
+		limiter := NewFlushLimiter(1, 0)
+		var current, maxSeen, completed atomic.Int32
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				release, err := limiter.Acquire(context.Background())
+				assert.NoError(t, err)
+				defer release()
+
+				running := current.Add(1)
+				for {
+					seen := maxSeen.Load()
+					if running <= seen || maxSeen.CompareAndSwap(seen, running) {
+						break
+					}
+				}
+
+				time.Sleep(5 * time.Millisecond)
+				current.Add(-1)
+				completed.Add(1)
+			}()
+		}
+
+		wg.Wait()
+		assert.Equal(t, int32(1), maxSeen.Load())
+		assert.Equal(t, int32(5), completed.Load())
+	}
+	{
+		// Context is cancelled while waiting for a slot
+		limiter := NewFlushLimiter(1, 0)
+		release, err := limiter.Acquire(context.Background())
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = limiter.Acquire(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		// Once released, it should be available again.
+		release()
+		release, err = limiter.Acquire(context.Background())
+		assert.NoError(t, err)
+		release()
+	}
+}
+
+func TestFlushLimiter_Reserve(t *testing.T) {
+	{
+		// No rate limit
+		limiter := NewFlushLimiter(1, 0)
+		assert.Zero(t, limiter.reserve(time.Now()))
+	}
+	{
+		// 60 flushes per minute, so they should be spaced out by a second.
+		limiter := NewFlushLimiter(0, 60)
+		now := time.Now()
+		assert.Equal(t, time.Duration(0), limiter.reserve(now))
+		assert.Equal(t, time.Second, limiter.reserve(now))
+		assert.Equal(t, 2*time.Second, limiter.reserve(now))
+
+		// Once we have waited long enough, we should not need to wait again.
+		assert.Equal(t, time.Duration(0), limiter.reserve(now.Add(time.Minute)))
+		assert.Equal(t, time.Second, limiter.reserve(now.Add(time.Minute)))
+	}
+}
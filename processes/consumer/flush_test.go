@@ -2,13 +2,21 @@ package consumer
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/artie-labs/transfer/models/event"
 
 	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/ptr"
 	"github.com/artie-labs/transfer/lib/telemetry/metrics"
 	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/assert"
@@ -128,3 +136,250 @@ func (f *FlushTestSuite) TestMemoryConcurrency() {
 		assert.Equal(f.T(), kafkaMessages[0].Offset, int64(4))
 	}
 }
+
+type concurrencyTrackingDest struct {
+	destination.DataWarehouse
+	current atomic.Int32
+	maxSeen atomic.Int32
+}
+
+func (c *concurrencyTrackingDest) Merge(tableData *optimization.TableData) error {
+	running := c.current.Add(1)
+	defer c.current.Add(-1)
+	for {
+		seen := c.maxSeen.Load()
+		if running <= seen || c.maxSeen.CompareAndSwap(seen, running) {
+			break
+		}
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	return c.DataWarehouse.Merge(tableData)
+}
+
+func (f *FlushTestSuite) TestFlushWithLimiter() {
+	tableNames := []string{"dusty", "snowflake", "postgres"}
+	for _, tableName := range tableNames {
+		evt := event.Event{
+			Table:         tableName,
+			PrimaryKeyMap: map[string]any{"id": "pk-1"},
+			Data: map[string]any{
+				"id":                         "pk-1",
+				constants.DeleteColumnMarker: false,
+				"foo":                        "bar",
+			},
+		}
+
+		kafkaMsg := kafka.Message{Partition: 1, Offset: 1}
+		_, _, err := evt.Save(f.cfg, f.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(f.T(), err)
+	}
+
+	dest := &concurrencyTrackingDest{DataWarehouse: f.dwh}
+	assert.NoError(f.T(), Flush(context.Background(), f.db, dest, metrics.NullMetricsProvider{}, Args{Options: Options{flushLimiter: NewFlushLimiter(1, 0)}}))
+
+	// All the tables should have been flushed, one at a time.
+	assert.Equal(f.T(), int32(1), dest.maxSeen.Load())
+	assert.Equal(f.T(), len(tableNames), f.fakeConsumer.CommitMessagesCallCount())
+	for _, tableName := range tableNames {
+		assert.True(f.T(), f.db.GetOrCreateTableData(tableName).Empty(), tableName)
+	}
+}
+
+func (f *FlushTestSuite) TestFlushIdleTables() {
+	saveRow := func(tableName string, offset int) {
+		evt := event.Event{
+			Table:         tableName,
+			PrimaryKeyMap: map[string]any{"id": fmt.Sprintf("pk-%d", offset)},
+			Data: map[string]any{
+				"id":                         fmt.Sprintf("pk-%d", offset),
+				constants.DeleteColumnMarker: false,
+				"foo":                        "bar",
+			},
+		}
+
+		kafkaMsg := kafka.Message{Partition: 1, Offset: int64(offset)}
+		_, _, err := evt.Save(f.cfg, f.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(f.T(), err)
+	}
+
+	idle := 50 * time.Millisecond
+	saveRow("idle", 1)
+	time.Sleep(2 * idle)
+	saveRow("steady", 2)
+
+	// Only the table that stopped receiving rows should be flushed.
+	assert.NoError(f.T(), Flush(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Args{Reason: "idle", IdleFor: ptr.ToDuration(idle)}))
+	assert.Equal(f.T(), 1, f.fakeConsumer.CommitMessagesCallCount())
+	assert.True(f.T(), f.db.GetOrCreateTableData("idle").Empty())
+	assert.False(f.T(), f.db.GetOrCreateTableData("steady").Empty())
+
+	// The steady table keeps on receiving rows, so it will never be idle and will be flushed by the interval.
+	saveRow("steady", 3)
+	assert.NoError(f.T(), Flush(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Args{Reason: "idle", IdleFor: ptr.ToDuration(idle)}))
+	assert.Equal(f.T(), 1, f.fakeConsumer.CommitMessagesCallCount())
+
+	time.Sleep(2 * idle)
+	saveRow("steady", 4)
+	assert.NoError(f.T(), Flush(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Args{Reason: "time", CoolDown: ptr.ToDuration(idle)}))
+	assert.Equal(f.T(), 2, f.fakeConsumer.CommitMessagesCallCount())
+	assert.True(f.T(), f.db.GetOrCreateTableData("steady").Empty())
+
+	// Empty tables should not be flushed.
+	time.Sleep(2 * idle)
+	assert.NoError(f.T(), Flush(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Args{Reason: "idle", IdleFor: ptr.ToDuration(idle)}))
+	assert.Equal(f.T(), 2, f.fakeConsumer.CommitMessagesCallCount())
+}
+
+func (f *FlushTestSuite) TestFlushTopicIntervalOverride() {
+	quietTopicConfig := *topicConfig
+	quietTopicConfig.FlushIntervalSeconds = 60 * 60
+
+	saveRow := func(tableName string, tc *kafkalib.TopicConfig, offset int) {
+		evt := event.Event{
+			Table:         tableName,
+			PrimaryKeyMap: map[string]any{"id": fmt.Sprintf("pk-%d", offset)},
+			Data: map[string]any{
+				"id":                         fmt.Sprintf("pk-%d", offset),
+				constants.DeleteColumnMarker: false,
+				"foo":                        "bar",
+			},
+		}
+
+		kafkaMsg := kafka.Message{Partition: 1, Offset: int64(offset)}
+		_, _, err := evt.Save(f.cfg, f.db, tc, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(f.T(), err)
+	}
+
+	coolDown := 50 * time.Millisecond
+	saveRow("default", topicConfig, 1)
+	saveRow("quiet", &quietTopicConfig, 2)
+
+	// Neither table has been flushed yet, but their intervals start when they are created.
+	assert.NoError(f.T(), Flush(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Args{Reason: "time", CoolDown: ptr.ToDuration(coolDown)}))
+	assert.False(f.T(), f.db.GetOrCreateTableData("default").Empty())
+	assert.False(f.T(), f.db.GetOrCreateTableData("quiet").Empty())
+
+	// Once the global interval has passed, only the table without an override should be flushed.
+	time.Sleep(2 * coolDown)
+	assert.NoError(f.T(), Flush(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Args{Reason: "time", CoolDown: ptr.ToDuration(coolDown)}))
+	assert.True(f.T(), f.db.GetOrCreateTableData("default").Empty())
+	assert.False(f.T(), f.db.GetOrCreateTableData("quiet").Empty())
+
+	// The next tick should not flush the quiet table either.
+	saveRow("default", topicConfig, 3)
+	time.Sleep(2 * coolDown)
+	assert.NoError(f.T(), Flush(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Args{Reason: "time", CoolDown: ptr.ToDuration(coolDown)}))
+	assert.True(f.T(), f.db.GetOrCreateTableData("default").Empty())
+	assert.False(f.T(), f.db.GetOrCreateTableData("quiet").Empty())
+}
+
+func (f *FlushTestSuite) TestFlushAppendOnly() {
+	appendOnlyTopicConfig := &kafkalib.TopicConfig{
+		Database:   "customer",
+		TableName:  "events",
+		Schema:     "public",
+		Topic:      "foo",
+		AppendOnly: true,
+	}
+
+	for i := 0; i < 3; i++ {
+		// Rows with the same primary key should not be deduped.
+		evt := event.Event{
+			Table:         "events",
+			PrimaryKeyMap: map[string]any{"id": "pk-1"},
+			Data: map[string]any{
+				"id":                         "pk-1",
+				constants.DeleteColumnMarker: false,
+				"event":                      fmt.Sprintf("event-%d", i),
+			},
+		}
+
+		kafkaMsg := kafka.Message{Partition: 1, Offset: int64(i)}
+		_, _, err := evt.Save(f.cfg, f.db, appendOnlyTopicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(f.T(), err)
+	}
+
+	assert.Equal(f.T(), uint(3), f.db.GetOrCreateTableData("events").NumberOfRows())
+	assert.NoError(f.T(), Flush(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Args{Reason: "time"}))
+	assert.True(f.T(), f.db.GetOrCreateTableData("events").Empty())
+
+	var copyCount int
+	for i := 0; i < f.fakeStore.ExecCallCount(); i++ {
+		query, _ := f.fakeStore.ExecArgsForCall(i)
+		assert.NotContains(f.T(), query, "MERGE INTO", query)
+		if strings.Contains(query, "COPY INTO") {
+			copyCount++
+		}
+	}
+
+	assert.Equal(f.T(), 1, copyCount)
+}
+
+func (f *FlushTestSuite) TestFlushAppendOnly_AssertionFails() {
+	appendOnlyTopicConfig := &kafkalib.TopicConfig{
+		Database:   "customer",
+		TableName:  "events",
+		Schema:     "public",
+		Topic:      "foo",
+		AppendOnly: true,
+		Assertions: []kafkalib.AssertionConfig{{Name: "group is set", Type: "notNull", Column: "Group"}},
+	}
+
+	for i := 0; i < 3; i++ {
+		evt := event.Event{
+			Table:         "events",
+			PrimaryKeyMap: map[string]any{"id": fmt.Sprintf("pk-%d", i)},
+			Data: map[string]any{
+				"id":                         fmt.Sprintf("pk-%d", i),
+				constants.DeleteColumnMarker: false,
+				"group":                      "admins",
+			},
+		}
+
+		kafkaMsg := kafka.Message{Partition: 1, Offset: int64(i)}
+		_, _, err := evt.Save(f.cfg, f.db, appendOnlyTopicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(f.T(), err)
+	}
+
+	copyCount := func() int {
+		var count int
+		for i := 0; i < f.fakeStore.ExecCallCount(); i++ {
+			if query, _ := f.fakeStore.ExecArgsForCall(i); strings.Contains(query, "COPY INTO") {
+				count++
+			}
+		}
+
+		return count
+	}
+
+	var assertionQueries []string
+	f.fakeStore.QueryStub = func(query string, _ ...any) (*sql.Rows, error) {
+		if strings.Contains(query, "IS NULL") {
+			assertionQueries = append(assertionQueries, query)
+			return nil, fmt.Errorf("failed to run query")
+		}
+
+		return nil, nil
+	}
+
+	// The consumer should stop instead of retrying the flush.
+	assert.PanicsWithValue(f.T(), "Assertion failed, halting the consumer", func() {
+		_ = Flush(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Args{Reason: "time"})
+	})
+	assert.Equal(f.T(), 1, copyCount())
+	assert.Len(f.T(), assertionQueries, 1)
+	assert.Contains(f.T(), assertionQueries[0], `WHERE "group" IS NULL`)
+
+	// The offsets should not have been committed, so the rows will be consumed again once we restart.
+	tableData := f.db.GetOrCreateTableData("events")
+	assert.Equal(f.T(), 3, int(tableData.NumberOfRows()))
+	assert.Equal(f.T(), 0, f.fakeConsumer.CommitMessagesCallCount())
+
+	// The columns should be escaped with the destination's identifier casing.
+	assert.Panics(f.T(), func() {
+		_ = Flush(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Args{Options: Options{identifierCasing: config.UpperCasing}, Reason: "time"})
+	})
+	assert.Len(f.T(), assertionQueries, 2)
+	assert.Contains(f.T(), assertionQueries[1], `WHERE "GROUP" IS NULL`)
+}
@@ -3,6 +3,8 @@ package consumer
 import (
 	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
 	"log/slog"
 	"sync"
 	"time"
@@ -11,11 +13,13 @@ import (
 	"github.com/segmentio/kafka-go"
 	"github.com/segmentio/kafka-go/sasl/aws_msk_iam_v2"
 	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
 
 	"github.com/artie-labs/transfer/lib/artie"
-	"github.com/artie-labs/transfer/lib/cdc/format"
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/health"
+	"github.com/artie-labs/transfer/lib/jitter"
 	"github.com/artie-labs/transfer/lib/kafkalib"
 	"github.com/artie-labs/transfer/lib/logger"
 	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
@@ -47,90 +51,285 @@ func (t *TopicToConsumer) Get(topic string) kafkalib.Consumer {
 	return t.topicToConsumer[topic]
 }
 
-func StartConsumer(ctx context.Context, cfg config.Config, inMemDB *models.DatabaseData, dest destination.Baseline, metricsClient base.Client) {
-	slog.Info("Starting Kafka consumer...", slog.Any("config", cfg.Kafka))
+// newDialer will configure SASL and TLS based on the Kafka config.
+func newDialer(ctx context.Context, cfg config.Kafka) (*kafka.Dialer, error) {
 	dialer := &kafka.Dialer{
 		Timeout:   10 * time.Second,
 		DualStack: true,
 	}
 
+	if cfg.TLSEnabled {
+		dialer.TLS = &tls.Config{}
+	}
+
 	// If using AWS MSK IAM, we expect this to be set in the ENV VAR
 	// (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_REGION, or the AWS Profile should be called default.)
-	if cfg.Kafka.EnableAWSMSKIAM {
+	if cfg.EnableAWSMSKIAM {
 		_awsCfg, err := awsCfg.LoadDefaultConfig(ctx)
 		if err != nil {
-			logger.Panic("Failed to load aws configuration", slog.Any("err", err))
+			return nil, fmt.Errorf("failed to load aws configuration: %w", err)
 		}
 
 		dialer.SASLMechanism = aws_msk_iam_v2.NewMechanism(_awsCfg)
 		dialer.TLS = &tls.Config{}
+		return dialer, nil
+	}
+
+	mechanism := cfg.SASLMechanism
+	if mechanism == "" {
+		// If username or password is set, then let's enable PLAIN.
+		// By default, we will support no auth (local testing) and PLAIN SASL.
+		if cfg.Username == "" {
+			return dialer, nil
+		}
+
+		mechanism = config.SASLMechanismPlain
 	}
 
-	// If username or password is set, then let's enable PLAIN.
-	// By default, we will support no auth (local testing) and PLAIN SASL.
-	if cfg.Kafka.Username != "" {
+	switch mechanism {
+	case config.SASLMechanismPlain:
 		dialer.SASLMechanism = plain.Mechanism{
-			Username: cfg.Kafka.Username,
-			Password: cfg.Kafka.Password,
+			Username: cfg.Username,
+			Password: cfg.Password,
+		}
+	case config.SASLMechanismSCRAMSHA256, config.SASLMechanismSCRAMSHA512:
+		algo := scram.SHA256
+		if mechanism == config.SASLMechanismSCRAMSHA512 {
+			algo = scram.SHA512
 		}
 
-		dialer.TLS = &tls.Config{}
+		scramMechanism, err := scram.Mechanism(algo, cfg.Username, cfg.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create scram mechanism: %w", err)
+		}
+
+		dialer.SASLMechanism = scramMechanism
+	case config.SASLMechanismOAuthBearer:
+		dialer.SASLMechanism = oauthBearerMechanism{token: cfg.Password}
+	default:
+		return nil, fmt.Errorf("unsupported sasl mechanism: %q", mechanism)
+	}
+
+	dialer.TLS = &tls.Config{}
+	return dialer, nil
+}
+
+// kafkaReader is the subset of [kafka.Reader] that we use to consume a topic.
+type kafkaReader interface {
+	kafkalib.Consumer
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+}
+
+// newKafkaReader is a variable so that it can be swapped out in tests.
+var newKafkaReader = func(cfg kafka.ReaderConfig) kafkaReader {
+	return kafka.NewReader(cfg)
+}
+
+// StartConsumer will consume from every configured Kafka cluster, all the clusters share the same in-memory database and flushes.
+func StartConsumer(ctx context.Context, cfg config.Config, inMemDB *models.DatabaseData, dest destination.Baseline, metricsClient base.Client, opts Options) {
+	tcs, err := cfg.TopicConfigs()
+	if err != nil {
+		logger.Panic("Failed to get topic configs", slog.Any("err", err))
 	}
 
-	tcFmtMap := NewTcFmtMap()
+	tcFmtMap, err := loadTcFmtMap(cfg, tcs, defaultSchemaRegistryRetryConfig())
+	if err != nil {
+		logger.Panic("Failed to load the topic parsers", slog.Any("err", err))
+	}
+
+	tap, err := newDebugTap(cfg.Debug)
+	if err != nil {
+		logger.Panic("Failed to create the debug tap", slog.Any("err", err))
+	}
+	defer tap.Close()
+
 	topicToConsumer = NewTopicToConsumer()
+	var wg sync.WaitGroup
+	for _, kafkaCfg := range cfg.KafkaConfigs() {
+		wg.Add(1)
+		go func(kafkaCfg config.Kafka) {
+			defer wg.Done()
+			startClusterConsumer(ctx, cfg, kafkaCfg, inMemDB, dest, metricsClient, opts, tcFmtMap, tap)
+		}(*kafkaCfg)
+	}
+
+	wg.Wait()
+}
+
+// startClusterConsumer will start a consumer for each of the topics within `kafkaCfg`.
+func startClusterConsumer(ctx context.Context, cfg config.Config, kafkaCfg config.Kafka, inMemDB *models.DatabaseData, dest destination.Baseline, metricsClient base.Client, opts Options, tcFmtMap *TcFmtMap, tap *debugTap) {
+	slog.Info("Starting Kafka consumer...", slog.Any("config", &kafkaCfg))
+	dialer, err := newDialer(ctx, kafkaCfg)
+	if err != nil {
+		logger.Panic("Failed to create kafka dialer", slog.Any("err", err))
+	}
+
 	var topics []string
-	for _, topicConfig := range cfg.Kafka.TopicConfigs {
-		tcFmtMap.Add(topicConfig.Topic, TopicConfigFormatter{
-			tc:     topicConfig,
-			Format: format.GetFormatParser(topicConfig.CDCFormat, topicConfig.Topic),
-		})
+	for _, topicConfig := range kafkaCfg.TopicConfigs {
 		topics = append(topics, topicConfig.Topic)
 	}
 
+	if kafkaCfg.OffsetLagIntervalSeconds > 0 {
+		reader := newKafkaOffsetLagReader(dialer, kafkaCfg.BootstrapServers())
+		go pollOffsetLag(ctx, reader, kafkaCfg.GroupID, topics, time.Duration(kafkaCfg.OffsetLagIntervalSeconds)*time.Second, metricsClient)
+	}
+
+	maxMemoryBytes := cfg.MaxMemoryMb * 1024 * 1024
+	flushForMemory := func() error {
+		return Flush(ctx, inMemDB, dest, metricsClient, Args{Options: opts, Reason: "memory"})
+	}
+
+	listener := rebalanceListener{
+		inMemDB:       inMemDB,
+		dest:          dest,
+		metricsClient: metricsClient,
+		options:       opts,
+	}
+
 	var wg sync.WaitGroup
 	for _, topic := range topics {
 		wg.Add(1)
 		go func(topic string) {
 			defer wg.Done()
 
-			kafkaCfg := kafka.ReaderConfig{
-				GroupID: cfg.Kafka.GroupID,
+			// StartFrom needs to know when partitions are assigned, so it'll also join the consumer group directly.
+			if kafkaCfg.FlushOnRebalance || kafkaCfg.StartFrom != "" {
+				consumeWithRebalance(ctx, kafkaCfg, dialer, topic, listener, func(ctx context.Context, reader *kafka.Reader) error {
+					if err := waitForMemory(ctx, inMemDB, maxMemoryBytes, memoryPollInterval, flushForMemory); err != nil {
+						return err
+					}
+
+					kafkaMsg, err := reader.FetchMessage(ctx)
+					if err != nil {
+						if ctx.Err() != nil {
+							return err
+						}
+
+						slog.With(artie.KafkaMsgLogFields(kafkaMsg)...).Warn("Failed to read kafka message", slog.Any("err", err))
+						return nil
+					}
+
+					processKafkaMessage(ctx, cfg, kafkaCfg.GroupID, inMemDB, dest, metricsClient, opts, tcFmtMap, tap, kafkaMsg)
+					return nil
+				})
+				return
+			}
+
+			kafkaConsumer := newKafkaReader(kafka.ReaderConfig{
+				GroupID: kafkaCfg.GroupID,
 				Dialer:  dialer,
 				Topic:   topic,
-				Brokers: cfg.Kafka.BootstrapServers(),
+				Brokers: kafkaCfg.BootstrapServers(),
+			})
+			topicToConsumer.Add(topic, kafkaConsumer)
+			health.Default().SetConnected(topic, true)
+
+			var tracker *snapshotTracker
+			if cfg.Mode == config.Snapshot {
+				endOffsets, err := getEndOffsets(ctx, dialer, kafkaCfg.BootstrapServers(), topic)
+				if err != nil {
+					logger.Panic("Failed to get end offsets", slog.Any("err", err), slog.String("topic", topic))
+				}
+
+				tracker = newSnapshotTracker(endOffsets)
 			}
 
-			kafkaConsumer := kafka.NewReader(kafkaCfg)
-			topicToConsumer.Add(topic, kafkaConsumer)
 			for {
-				kafkaMsg, err := kafkaConsumer.FetchMessage(ctx)
+				if tracker != nil && tracker.Done() {
+					slog.Info("Reached the end offsets, snapshot is complete", slog.String("topic", topic))
+					return
+				}
+
+				if err := waitForMemory(ctx, inMemDB, maxMemoryBytes, memoryPollInterval, flushForMemory); err != nil {
+					slog.Warn("Stopped waiting for memory to free up", slog.Any("err", err), slog.String("topic", topic))
+					return
+				}
+
+				kafkaMsg, err := fetchMessage(ctx, kafkaConsumer, tracker != nil, time.Duration(cfg.SnapshotIdleSeconds)*time.Second)
 				if err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+
+					if tracker != nil && errors.Is(err, context.DeadlineExceeded) {
+						// We may never reach the end offsets if the consumer group has already committed past them.
+						slog.Info("Did not receive any messages within the idle window, snapshot is complete", slog.String("topic", topic))
+						return
+					}
+
 					slog.With(artie.KafkaMsgLogFields(kafkaMsg)...).Warn("Failed to read kafka message", slog.Any("err", err))
+					health.Default().SetConnected(topic, false)
 					continue
 				}
 
-				if len(kafkaMsg.Value) == 0 {
-					slog.Debug("Found a tombstone message, skipping...", artie.KafkaMsgLogFields(kafkaMsg)...)
-					continue
-				}
+				health.Default().SetConnected(topic, true)
 
-				msg := artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic)
-				args := processArgs{
-					Msg:                    msg,
-					GroupID:                kafkaConsumer.Config().GroupID,
-					TopicToConfigFormatMap: tcFmtMap,
+				if tracker != nil {
+					tracker.Observe(kafkaMsg.Partition, kafkaMsg.Offset)
 				}
 
-				tableName, processErr := args.process(ctx, cfg, inMemDB, dest, metricsClient)
-				msg.EmitIngestionLag(metricsClient, cfg.Mode, kafkaConsumer.Config().GroupID, tableName)
-				msg.EmitRowLag(metricsClient, cfg.Mode, kafkaConsumer.Config().GroupID, tableName)
-				if processErr != nil {
-					slog.With(artie.KafkaMsgLogFields(kafkaMsg)...).Warn("Skipping message...", slog.Any("err", processErr))
-				}
+				processKafkaMessage(ctx, cfg, kafkaCfg.GroupID, inMemDB, dest, metricsClient, opts, tcFmtMap, tap, kafkaMsg)
 			}
 		}(topic)
 	}
 
 	wg.Wait()
 }
+
+// blockedMessageMaxRetryMs is the longest that we'll wait before retrying a message that returned a [blockingError].
+var blockedMessageMaxRetryMs = 30_000
+
+func processKafkaMessage(ctx context.Context, cfg config.Config, groupID string, inMemDB *models.DatabaseData, dest destination.Baseline, metricsClient base.Client, opts Options, tcFmtMap *TcFmtMap, tap *debugTap, kafkaMsg kafka.Message) {
+	if len(kafkaMsg.Value) == 0 {
+		// Tombstones on compacted topics are deletes, otherwise there's nothing for us to process.
+		if tcFmt, isOk := tcFmtMap.GetTopicFmt(kafkaMsg.Topic); !isOk || !tcFmt.tc.Compacted {
+			slog.Debug("Found a tombstone message, skipping...", artie.KafkaMsgLogFields(kafkaMsg)...)
+			opts.kafkaOffsets.Processed(kafkaMsg)
+			return
+		}
+	}
+
+	msg := artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic)
+	args := processArgs{
+		Msg:                    msg,
+		GroupID:                groupID,
+		TopicToConfigFormatMap: tcFmtMap,
+		Options:                opts,
+	}
+
+	logFields := artie.KafkaMsgLogFields(kafkaMsg)
+	tableName, processErr := args.process(ctx, cfg, inMemDB, dest, metricsClient)
+	for attempts := 1; isBlockingError(processErr); attempts++ {
+		slog.With(logFields...).Warn("Failed to process message, retrying since it cannot be skipped...", slog.Any("err", processErr), slog.Int("attempts", attempts))
+		if ctx.Err() != nil {
+			// The message was never processed, so we should not commit past it.
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(jitter.Jitter(1000, blockedMessageMaxRetryMs, attempts)):
+		}
+
+		tableName, processErr = args.process(ctx, cfg, inMemDB, dest, metricsClient)
+	}
+
+	opts.kafkaOffsets.Processed(kafkaMsg)
+	msg.EmitRowLag(metricsClient, cfg.Mode, groupID, tableName)
+	tap.Capture(kafkaMsg.Topic, logFields, processErr)
+	if processErr != nil {
+		slog.With(logFields...).Warn("Skipping message...", slog.Any("err", processErr))
+	}
+}
+
+// fetchMessage will fetch the next message, if `snapshot` is true, we'll only wait up to `idleWindow` for a message.
+func fetchMessage(ctx context.Context, reader kafkaReader, snapshot bool, idleWindow time.Duration) (kafka.Message, error) {
+	if !snapshot {
+		return reader.FetchMessage(ctx)
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, idleWindow)
+	defer cancel()
+	return reader.FetchMessage(fetchCtx)
+}
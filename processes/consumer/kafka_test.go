@@ -0,0 +1,218 @@
+package consumer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics"
+	"github.com/artie-labs/transfer/models"
+)
+
+func TestNewDialer(t *testing.T) {
+	ctx := context.Background()
+	{
+		// No auth
+		dialer, err := newDialer(ctx, config.Kafka{})
+		assert.NoError(t, err)
+		assert.Nil(t, dialer.SASLMechanism)
+		assert.Nil(t, dialer.TLS)
+	}
+	{
+		// TLS only
+		dialer, err := newDialer(ctx, config.Kafka{TLSEnabled: true})
+		assert.NoError(t, err)
+		assert.Nil(t, dialer.SASLMechanism)
+		assert.NotNil(t, dialer.TLS)
+	}
+	{
+		// Username without a mechanism defaults to PLAIN
+		dialer, err := newDialer(ctx, config.Kafka{Username: "user", Password: "pass"})
+		assert.NoError(t, err)
+		assert.Equal(t, plain.Mechanism{Username: "user", Password: "pass"}, dialer.SASLMechanism)
+		assert.NotNil(t, dialer.TLS)
+	}
+	{
+		// Azure Event Hubs
+		dialer, err := newDialer(ctx, config.Kafka{
+			SASLMechanism: config.SASLMechanismPlain,
+			TLSEnabled:    true,
+			Username:      "$ConnectionString",
+			Password:      "Endpoint=sb://foo.servicebus.windows.net/;SharedAccessKeyName=bar;SharedAccessKey=baz",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "PLAIN", dialer.SASLMechanism.Name())
+		assert.Equal(t, "$ConnectionString", dialer.SASLMechanism.(plain.Mechanism).Username)
+		assert.NotNil(t, dialer.TLS)
+	}
+	{
+		// SCRAM
+		for _, mechanism := range []config.SASLMechanism{config.SASLMechanismSCRAMSHA256, config.SASLMechanismSCRAMSHA512} {
+			dialer, err := newDialer(ctx, config.Kafka{SASLMechanism: mechanism, Username: "user", Password: "pass"})
+			assert.NoError(t, err, mechanism)
+			assert.Equal(t, string(mechanism), dialer.SASLMechanism.Name())
+			assert.NotNil(t, dialer.TLS, mechanism)
+		}
+	}
+	{
+		// OAUTHBEARER
+		dialer, err := newDialer(ctx, config.Kafka{SASLMechanism: config.SASLMechanismOAuthBearer, Password: "token"})
+		assert.NoError(t, err)
+		assert.Equal(t, "OAUTHBEARER", dialer.SASLMechanism.Name())
+		assert.NotNil(t, dialer.TLS)
+
+		stateMachine, initialResponse, err := dialer.SASLMechanism.Start(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "n,,\x01auth=Bearer token\x01\x01", string(initialResponse))
+
+		done, _, err := stateMachine.Next(ctx, nil)
+		assert.NoError(t, err)
+		assert.True(t, done)
+
+		_, _, err = stateMachine.Next(ctx, []byte(`{"status":"invalid_token"}`))
+		assert.ErrorContains(t, err, `oauthbearer authentication failed: {"status":"invalid_token"}`)
+	}
+	{
+		// Invalid mechanism
+		_, err := newDialer(ctx, config.Kafka{SASLMechanism: "GSSAPI", Username: "user"})
+		assert.ErrorContains(t, err, `unsupported sasl mechanism: "GSSAPI"`)
+	}
+}
+
+type fakeKafkaReader struct {
+	cfg  kafka.ReaderConfig
+	msgs chan kafka.Message
+}
+
+func (f *fakeKafkaReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case msg := <-f.msgs:
+		return msg, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (f *fakeKafkaReader) ReadMessage(ctx context.Context) (kafka.Message, error) {
+	return f.FetchMessage(ctx)
+}
+
+func (f *fakeKafkaReader) CommitMessages(_ context.Context, _ ...kafka.Message) error {
+	return nil
+}
+
+func (f *fakeKafkaReader) Close() error {
+	return nil
+}
+
+func TestStartConsumer_MultipleClusters(t *testing.T) {
+	newTopicConfig := func(topic string, tableName string) *kafkalib.TopicConfig {
+		tc := &kafkalib.TopicConfig{
+			Database:     "shop",
+			TableName:    tableName,
+			Schema:       "public",
+			Topic:        topic,
+			CDCFormat:    constants.DBZMongoFormat,
+			CDCKeyFormat: kafkalib.StringKeyFmt,
+		}
+		tc.Load()
+		return tc
+	}
+
+	cfg := config.Config{
+		Queue:                constants.Kafka,
+		Mode:                 config.Replication,
+		FlushIntervalSeconds: 10,
+		BufferRows:           10,
+		FlushSizeKb:          900,
+		Kafka: &config.Kafka{
+			BootstrapServer: "us-east:9092",
+			GroupID:         "transfer-us",
+			TopicConfigs:    []*kafkalib.TopicConfig{newTopicConfig("us.shop.orders", "orders")},
+		},
+		KafkaClusters: []*config.Kafka{
+			{
+				BootstrapServer: "eu-west-1:9092,eu-west-2:9092",
+				GroupID:         "transfer-eu",
+				TopicConfigs:    []*kafkalib.TopicConfig{newTopicConfig("eu.shop.customers", "customers")},
+			},
+		},
+	}
+
+	var mu sync.Mutex
+	readers := make(map[string]*fakeKafkaReader)
+	newKafkaReader = func(readerCfg kafka.ReaderConfig) kafkaReader {
+		mu.Lock()
+		defer mu.Unlock()
+
+		reader := &fakeKafkaReader{cfg: readerCfg, msgs: make(chan kafka.Message, 1)}
+		reader.msgs <- kafka.Message{
+			Topic: readerCfg.Topic,
+			Key:   []byte("Struct{id=1}"),
+			Value: []byte(`{
+	"payload": {
+		"before": null,
+		"after": "{\"_id\": {\"$numberLong\": \"1\"}, \"name\": \"dusty\"}",
+		"source": {"connector": "mongodb", "ts_ms": 1668753321000, "db": "shop", "collection": "ignored"},
+		"op": "c"
+	}
+}`),
+		}
+
+		readers[readerCfg.Topic] = reader
+		return reader
+	}
+	defer func() {
+		newKafkaReader = func(cfg kafka.ReaderConfig) kafkaReader {
+			return kafka.NewReader(cfg)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	memDB := models.NewMemoryDB()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		StartConsumer(ctx, cfg, memDB, MockDestination{}, metrics.NullMetricsProvider{}, Options{})
+	}()
+
+	numberOfRows := func(tableName string) int {
+		memDB.RLock()
+		td, isOk := memDB.TableData()[tableName]
+		memDB.RUnlock()
+		if !isOk {
+			return 0
+		}
+
+		td.Lock()
+		defer td.Unlock()
+		return int(td.NumberOfRows())
+	}
+
+	// Each topic is routed to its own table.
+	assert.Eventually(t, func() bool {
+		return numberOfRows("orders") == 1 && numberOfRows("customers") == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+
+	// Each cluster should have started a consumer with its own brokers and consumer group.
+	assert.Len(t, readers, 2)
+	assert.Equal(t, []string{"us-east:9092"}, readers["us.shop.orders"].cfg.Brokers)
+	assert.Equal(t, "transfer-us", readers["us.shop.orders"].cfg.GroupID)
+	assert.Equal(t, []string{"eu-west-1:9092", "eu-west-2:9092"}, readers["eu.shop.customers"].cfg.Brokers)
+	assert.Equal(t, "transfer-eu", readers["eu.shop.customers"].cfg.GroupID)
+
+	// Offsets are committed through the consumer of the cluster that the topic came from.
+	assert.Equal(t, readers["us.shop.orders"], topicToConsumer.Get("us.shop.orders"))
+	assert.Equal(t, readers["eu.shop.customers"], topicToConsumer.Get("eu.shop.customers"))
+}
@@ -0,0 +1,191 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/health"
+	"github.com/artie-labs/transfer/lib/logger"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
+	"github.com/artie-labs/transfer/models"
+)
+
+const (
+	defaultNATSAckWait = 10 * time.Minute
+	natsFetchBatchSize = 100
+	natsFetchMaxWait   = time.Second
+)
+
+// natsOptions will only set the credentials that have been configured.
+func natsOptions(cfg config.NATS) []nats.Option {
+	opts := []nats.Option{nats.Name("transfer")}
+	if cfg.CredentialsFile != "" {
+		opts = append(opts, nats.UserCredentials(cfg.CredentialsFile))
+	}
+
+	if cfg.Username != "" || cfg.Password != "" {
+		opts = append(opts, nats.UserInfo(cfg.Username, cfg.Password))
+	}
+
+	if cfg.Token != "" {
+		opts = append(opts, nats.Token(cfg.Token))
+	}
+
+	return opts
+}
+
+// natsDurableName returns the durable consumer name for `topic`, durable names cannot contain `.`, `*`, `>` or whitespace.
+func natsDurableName(durableName string, topic string) string {
+	replacer := strings.NewReplacer(".", "_", "*", "_", ">", "_", " ", "_", "\t", "_")
+	return fmt.Sprintf("%s_%s", durableName, replacer.Replace(topic))
+}
+
+func natsConsumerConfig(cfg config.Config, topic string) jetstream.ConsumerConfig {
+	ackWait := defaultNATSAckWait
+	if cfg.NATS.AckWaitSeconds > 0 {
+		ackWait = time.Duration(cfg.NATS.AckWaitSeconds) * time.Second
+	}
+
+	// This should be the same as our buffer rows so we don't limit our processing throughput
+	maxAckPending := int(cfg.BufferRows) + 1
+	if cfg.NATS.MaxAckPending > 0 {
+		maxAckPending = cfg.NATS.MaxAckPending
+	}
+
+	return jetstream.ConsumerConfig{
+		Durable:       natsDurableName(cfg.NATS.DurableName, topic),
+		FilterSubject: topic,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+		// Messages are only acked once they have been flushed to the destination.
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       ackWait,
+		MaxAckPending: maxAckPending,
+	}
+}
+
+func StartJetStreamConsumer(ctx context.Context, cfg config.Config, inMemDB *models.DatabaseData, dest destination.Baseline, metricsClient base.Client, opts Options) {
+	nc, err := nats.Connect(strings.Join(cfg.NATS.Servers, ","), natsOptions(*cfg.NATS)...)
+	if err != nil {
+		logger.Panic("Failed to connect to nats", slog.Any("err", err))
+	}
+
+	defer nc.Close()
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		logger.Panic("Failed to create a jetstream client", slog.Any("err", err))
+	}
+
+	tcFmtMap, err := loadTcFmtMap(cfg, cfg.NATS.TopicConfigs, defaultSchemaRegistryRetryConfig())
+	if err != nil {
+		logger.Panic("Failed to load the topic parsers", slog.Any("err", err))
+	}
+
+	tap, err := newDebugTap(cfg.Debug)
+	if err != nil {
+		logger.Panic("Failed to create the debug tap", slog.Any("err", err))
+	}
+	defer tap.Close()
+
+	maxMemoryBytes := cfg.MaxMemoryMb * 1024 * 1024
+	flushForMemory := func() error {
+		return Flush(ctx, inMemDB, dest, metricsClient, Args{Options: opts, Reason: "memory"})
+	}
+
+	var wg sync.WaitGroup
+	for _, topicConfig := range cfg.NATS.TopicConfigs {
+		wg.Add(1)
+		go func(ctx context.Context, topic string) {
+			defer wg.Done()
+			consumerCfg := natsConsumerConfig(cfg, topic)
+			cons, err := js.CreateOrUpdateConsumer(ctx, cfg.NATS.Stream, consumerCfg)
+			if err != nil {
+				logger.Panic("Failed to create or update jetstream consumer", slog.Any("err", err), slog.String("topic", topic))
+			}
+
+			receive := func(natsMsg jetstream.Msg) {
+				if err := waitForMemory(ctx, inMemDB, maxMemoryBytes, memoryPollInterval, flushForMemory); err != nil {
+					slog.Warn("Stopped waiting for memory to free up", slog.Any("err", err), slog.String("topic", topic))
+					if nakErr := natsMsg.Nak(); nakErr != nil {
+						slog.Warn("Failed to nak nats message", slog.Any("err", nakErr), slog.String("topic", topic))
+					}
+					return
+				}
+
+				msg := artie.NewNATSMessage(natsMsg, topic)
+				logFields := []any{
+					slog.String("topic", msg.Topic()),
+					slog.String("subject", msg.Partition()),
+					slog.String("key", string(msg.Key())),
+					slog.String("value", string(msg.Value())),
+				}
+
+				args := processArgs{
+					Msg:                    msg,
+					GroupID:                consumerCfg.Durable,
+					TopicToConfigFormatMap: tcFmtMap,
+					Options:                opts,
+				}
+
+				_, processErr := args.process(ctx, cfg, inMemDB, dest, metricsClient)
+				tap.Capture(topic, logFields, processErr)
+				if processErr != nil {
+					slog.With(logFields...).Warn("Skipping message...", slog.Any("err", processErr))
+				}
+			}
+
+			var idle *idleTracker
+			if cfg.Mode == config.Snapshot {
+				idle = newIdleTracker(time.Duration(cfg.SnapshotIdleSeconds)*time.Second, time.Now())
+			}
+
+			health.Default().SetConnected(topic, true)
+			for {
+				if ctx.Err() != nil {
+					return
+				}
+
+				// Messages are processed one at a time from a single consumer, so ordering is kept within each subject.
+				batch, err := cons.Fetch(natsFetchBatchSize, jetstream.FetchMaxWait(natsFetchMaxWait))
+				if err != nil {
+					health.Default().SetConnected(topic, false)
+					logger.Panic("Failed to fetch from jetstream", slog.Any("err", err), slog.String("topic", topic))
+				}
+
+				for natsMsg := range batch.Messages() {
+					if idle != nil {
+						idle.Touch(time.Now())
+					}
+
+					receive(natsMsg)
+				}
+
+				if err = batch.Error(); err != nil && ctx.Err() == nil {
+					slog.Warn("Failed to fetch messages from jetstream", slog.Any("err", err), slog.String("topic", topic))
+				}
+
+				if idle != nil && idle.IsIdle(time.Now()) {
+					// Flushing will ack the messages that are still pending.
+					if flushErr := Flush(ctx, inMemDB, dest, metricsClient, Args{Options: opts, Reason: "snapshot"}); flushErr != nil {
+						slog.Error("Failed to flush after draining consumer", slog.Any("err", flushErr), slog.String("topic", topic))
+					}
+
+					slog.Info("Consumer has been drained, snapshot is complete", slog.String("topic", topic))
+					return
+				}
+			}
+		}(ctx, topicConfig.Topic)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,142 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/cdc/mongo"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics"
+	"github.com/artie-labs/transfer/models"
+)
+
+func TestNATSOptions(t *testing.T) {
+	{
+		// No credentials
+		assert.Len(t, natsOptions(config.NATS{}), 1)
+	}
+	{
+		// Credentials file, user info and token
+		assert.Len(t, natsOptions(config.NATS{CredentialsFile: "/tmp/user.creds"}), 2)
+		assert.Len(t, natsOptions(config.NATS{Username: "user", Password: "pass"}), 2)
+		assert.Len(t, natsOptions(config.NATS{Token: "token"}), 2)
+	}
+}
+
+func TestNATSDurableName(t *testing.T) {
+	assert.Equal(t, "transfer_orders", natsDurableName("transfer", "orders"))
+	assert.Equal(t, "transfer_dbserver1_public_orders", natsDurableName("transfer", "dbserver1.public.orders"))
+	assert.Equal(t, "transfer_dbserver1__", natsDurableName("transfer", "dbserver1.>"))
+}
+
+func TestNATSConsumerConfig(t *testing.T) {
+	{
+		// Defaults
+		consumerCfg := natsConsumerConfig(config.Config{BufferRows: 1000, NATS: &config.NATS{DurableName: "transfer"}}, "dbserver1.public.orders")
+		assert.Equal(t, "transfer_dbserver1_public_orders", consumerCfg.Durable)
+		assert.Equal(t, "dbserver1.public.orders", consumerCfg.FilterSubject)
+		assert.Equal(t, jetstream.AckExplicitPolicy, consumerCfg.AckPolicy)
+		assert.Equal(t, defaultNATSAckWait, consumerCfg.AckWait)
+		assert.Equal(t, 1001, consumerCfg.MaxAckPending)
+	}
+	{
+		// Configured
+		consumerCfg := natsConsumerConfig(config.Config{BufferRows: 1000, NATS: &config.NATS{DurableName: "transfer", AckWaitSeconds: 60, MaxAckPending: 500}}, "orders")
+		assert.Equal(t, jetstream.AckExplicitPolicy, consumerCfg.AckPolicy)
+		assert.Equal(t, time.Minute, consumerCfg.AckWait)
+		assert.Equal(t, 500, consumerCfg.MaxAckPending)
+	}
+}
+
+type fakeNATSMsg struct {
+	jetstream.Msg
+	subject string
+	data    []byte
+	headers nats.Header
+	acked   bool
+}
+
+func (f *fakeNATSMsg) Subject() string      { return f.subject }
+func (f *fakeNATSMsg) Data() []byte         { return f.data }
+func (f *fakeNATSMsg) Headers() nats.Header { return f.headers }
+func (f *fakeNATSMsg) Ack() error {
+	f.acked = true
+	return nil
+}
+func (f *fakeNATSMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{Timestamp: time.Now()}, nil
+}
+
+func TestProcessNATSMessage(t *testing.T) {
+	cfg := config.Config{
+		FlushIntervalSeconds: 10,
+		BufferRows:           10,
+		FlushSizeKb:          900,
+	}
+
+	const topic = "dbserver1.inventory.orders"
+	tc := &kafkalib.TopicConfig{
+		Database:          "lemonade",
+		TableName:         "orders",
+		Schema:            "public",
+		Topic:             topic,
+		CDCFormat:         constants.DBZMongoFormat,
+		CDCKeyFormat:      kafkalib.StringKeyFmt,
+		SkippedOperations: "d",
+	}
+	tc.Load()
+	assert.NoError(t, tc.Validate())
+
+	var mgo mongo.Debezium
+	tcFmtMap := NewTcFmtMap()
+	tcFmtMap.Add(topic, TopicConfigFormatter{tc: tc, Format: &mgo})
+
+	memDB := models.NewMemoryDB()
+	var natsMsgs []*fakeNATSMsg
+	for idx, op := range []string{"c", "u", "d"} {
+		natsMsg := &fakeNATSMsg{
+			subject: topic,
+			headers: nats.Header{},
+			data: []byte(fmt.Sprintf(`{
+	"payload": {
+		"before": null,
+		"after": "{\"_id\": {\"$numberLong\": \"%d\"}, \"name\": \"dusty\"}",
+		"source": {"connector": "mongodb", "ts_ms": 1668753321000, "db": "inventory", "collection": "orders"},
+		"op": "%s"
+	}
+}`, idx, op)),
+		}
+		natsMsg.headers.Set(artie.NATSKeyHeader, fmt.Sprintf("Struct{id=%d}", idx))
+		natsMsgs = append(natsMsgs, natsMsg)
+
+		args := processArgs{
+			Msg:                    artie.NewNATSMessage(natsMsg, topic),
+			GroupID:                "transfer",
+			TopicToConfigFormatMap: tcFmtMap,
+		}
+
+		tableName, err := args.process(context.Background(), cfg, memDB, MockDestination{}, metrics.NullMetricsProvider{})
+		assert.NoError(t, err)
+		assert.Equal(t, "orders", tableName)
+	}
+
+	td := memDB.GetOrCreateTableData("orders")
+	assert.Equal(t, 2, int(td.NumberOfRows()))
+
+	// Every buffered message is kept (grouped by subject) so they can be acked once the table has been flushed.
+	assert.Len(t, td.PartitionsToLastMessage[topic], 2)
+	assert.False(t, natsMsgs[0].acked)
+	assert.False(t, natsMsgs[1].acked)
+
+	// The delete was skipped, so it should have been acked right away.
+	assert.True(t, natsMsgs[2].acked)
+}
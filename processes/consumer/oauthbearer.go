@@ -0,0 +1,30 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/segmentio/kafka-go/sasl"
+)
+
+// oauthBearerMechanism implements SASL/OAUTHBEARER (RFC 7628) with a static bearer token.
+type oauthBearerMechanism struct {
+	token string
+}
+
+func (o oauthBearerMechanism) Name() string {
+	return "OAUTHBEARER"
+}
+
+func (o oauthBearerMechanism) Start(_ context.Context) (sasl.StateMachine, []byte, error) {
+	return o, []byte(fmt.Sprintf("n,,\x01auth=Bearer %s\x01\x01", o.token)), nil
+}
+
+func (o oauthBearerMechanism) Next(_ context.Context, challenge []byte) (bool, []byte, error) {
+	// The broker will only send a challenge back if authentication has failed, it contains the error details.
+	if len(challenge) > 0 {
+		return false, nil, fmt.Errorf("oauthbearer authentication failed: %s", string(challenge))
+	}
+
+	return true, nil, nil
+}
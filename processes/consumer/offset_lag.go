@@ -0,0 +1,162 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/artie-labs/transfer/lib/health"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
+)
+
+// offsetLagReader returns the offsets that are needed to compute the consumer group's offset lag.
+type offsetLagReader interface {
+	// EndOffsets returns a map of partition to the high-water mark for `topic`.
+	EndOffsets(ctx context.Context, topic string) (map[int]int64, error)
+	// CommittedOffsets returns a map of partition to the offset that `groupID` has committed, this is negative if nothing has been committed.
+	CommittedOffsets(ctx context.Context, groupID string, topic string, partitions []int) (map[int]int64, error)
+}
+
+type kafkaOffsetLagReader struct {
+	dialer  *kafka.Dialer
+	brokers []string
+	client  *kafka.Client
+}
+
+func newKafkaOffsetLagReader(dialer *kafka.Dialer, brokers []string) kafkaOffsetLagReader {
+	return kafkaOffsetLagReader{
+		dialer:  dialer,
+		brokers: brokers,
+		client: &kafka.Client{
+			Addr:    kafka.TCP(brokers...),
+			Timeout: 10 * time.Second,
+			Transport: &kafka.Transport{
+				Dial: dialer.DialFunc,
+				TLS:  dialer.TLS,
+				SASL: dialer.SASLMechanism,
+			},
+		},
+	}
+}
+
+func (k kafkaOffsetLagReader) EndOffsets(ctx context.Context, topic string) (map[int]int64, error) {
+	if len(k.brokers) == 0 {
+		return nil, fmt.Errorf("no bootstrap servers provided")
+	}
+
+	partitions, err := k.dialer.LookupPartitions(ctx, "tcp", k.brokers[0], topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup partitions for topic: %s: %w", topic, err)
+	}
+
+	var requests []kafka.OffsetRequest
+	for _, partition := range partitions {
+		requests = append(requests, kafka.LastOffsetOf(partition.ID))
+	}
+
+	resp, err := k.client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: map[string][]kafka.OffsetRequest{topic: requests}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list offsets for topic: %s: %w", topic, err)
+	}
+
+	endOffsets := make(map[int]int64)
+	for _, partitionOffsets := range resp.Topics[topic] {
+		if partitionOffsets.Error != nil {
+			return nil, fmt.Errorf("failed to list offsets for partition: %d: %w", partitionOffsets.Partition, partitionOffsets.Error)
+		}
+
+		endOffsets[partitionOffsets.Partition] = partitionOffsets.LastOffset
+	}
+
+	return endOffsets, nil
+}
+
+func (k kafkaOffsetLagReader) CommittedOffsets(ctx context.Context, groupID string, topic string, partitions []int) (map[int]int64, error) {
+	resp, err := k.client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: groupID, Topics: map[string][]int{topic: partitions}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch committed offsets for topic: %s: %w", topic, err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("failed to fetch committed offsets for topic: %s: %w", topic, resp.Error)
+	}
+
+	committedOffsets := make(map[int]int64)
+	for _, partition := range resp.Topics[topic] {
+		if partition.Error != nil {
+			return nil, fmt.Errorf("failed to fetch committed offset for partition: %d: %w", partition.Partition, partition.Error)
+		}
+
+		committedOffsets[partition.Partition] = partition.CommittedOffset
+	}
+
+	return committedOffsets, nil
+}
+
+// computeOffsetLag returns a map of partition to the number of messages between the committed offset and the high-water mark.
+// If the consumer group has not committed an offset for a partition, the lag will be the high-water mark.
+func computeOffsetLag(endOffsets map[int]int64, committedOffsets map[int]int64) map[int]int64 {
+	lag := make(map[int]int64)
+	for partition, endOffset := range endOffsets {
+		committedOffset, isOk := committedOffsets[partition]
+		if !isOk || committedOffset < 0 {
+			committedOffset = 0
+		}
+
+		lag[partition] = max(endOffset-committedOffset, 0)
+	}
+
+	return lag
+}
+
+// recordOffsetLag will compute the offset lag for `topic` and emit it as a metric and to the health tracker.
+func recordOffsetLag(ctx context.Context, reader offsetLagReader, groupID string, topic string, metricsClient base.Client, tracker *health.Tracker) error {
+	endOffsets, err := reader.EndOffsets(ctx, topic)
+	if err != nil {
+		return err
+	}
+
+	var partitions []int
+	for partition := range endOffsets {
+		partitions = append(partitions, partition)
+	}
+
+	committedOffsets, err := reader.CommittedOffsets(ctx, groupID, topic, partitions)
+	if err != nil {
+		return err
+	}
+
+	lag := computeOffsetLag(endOffsets, committedOffsets)
+	for partition, partitionLag := range lag {
+		metricsClient.Gauge("offset.lag", float64(partitionLag), map[string]string{
+			"groupID":   groupID,
+			"topic":     topic,
+			"partition": fmt.Sprint(partition),
+		})
+	}
+
+	tracker.RecordOffsetLag(topic, lag)
+	return nil
+}
+
+// pollOffsetLag will record the offset lag for every topic on each `interval` until `ctx` is done.
+func pollOffsetLag(ctx context.Context, reader offsetLagReader, groupID string, topics []string, interval time.Duration, metricsClient base.Client) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, topic := range topics {
+			if err := recordOffsetLag(ctx, reader, groupID, topic, metricsClient, health.Default()); err != nil {
+				slog.Warn("Failed to record offset lag", slog.Any("err", err), slog.String("topic", topic))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
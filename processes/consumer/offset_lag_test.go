@@ -0,0 +1,82 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/health"
+	"github.com/artie-labs/transfer/lib/ptr"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics"
+)
+
+type fakeOffsetLagReader struct {
+	endOffsets       map[int]int64
+	committedOffsets map[int]int64
+	err              error
+}
+
+func (f fakeOffsetLagReader) EndOffsets(_ context.Context, _ string) (map[int]int64, error) {
+	return f.endOffsets, f.err
+}
+
+func (f fakeOffsetLagReader) CommittedOffsets(_ context.Context, _ string, _ string, _ []int) (map[int]int64, error) {
+	return f.committedOffsets, nil
+}
+
+type gaugeRecorder struct {
+	metrics.NullMetricsProvider
+	gauges map[string]float64
+}
+
+func (g *gaugeRecorder) Gauge(name string, value float64, tags map[string]string) {
+	g.gauges[fmt.Sprintf("%s:%s:%s", name, tags["topic"], tags["partition"])] = value
+}
+
+func TestComputeOffsetLag(t *testing.T) {
+	{
+		// Caught up
+		assert.Equal(t, map[int]int64{0: 0, 1: 0}, computeOffsetLag(map[int]int64{0: 100, 1: 50}, map[int]int64{0: 100, 1: 50}))
+	}
+	{
+		// Behind
+		assert.Equal(t, map[int]int64{0: 25, 1: 50}, computeOffsetLag(map[int]int64{0: 100, 1: 50}, map[int]int64{0: 75, 1: 0}))
+	}
+	{
+		// Nothing has been committed for partition 1, and partition 2 is missing from the response.
+		assert.Equal(t, map[int]int64{0: 10, 1: 50, 2: 5}, computeOffsetLag(map[int]int64{0: 100, 1: 50, 2: 5}, map[int]int64{0: 90, 1: -1}))
+	}
+	{
+		// The committed offset should never be ahead, but the lag should not be negative.
+		assert.Equal(t, map[int]int64{0: 0}, computeOffsetLag(map[int]int64{0: 100}, map[int]int64{0: 150}))
+	}
+}
+
+func TestRecordOffsetLag(t *testing.T) {
+	tracker := health.NewTracker()
+	{
+		// Failed to read the offsets
+		reader := fakeOffsetLagReader{err: fmt.Errorf("broker is down")}
+		recorder := &gaugeRecorder{gauges: make(map[string]float64)}
+		assert.ErrorContains(t, recordOffsetLag(context.Background(), reader, "group", "orders", recorder, tracker), "broker is down")
+		assert.Empty(t, recorder.gauges)
+		assert.Nil(t, tracker.Status(time.Now(), time.Minute).Topics["orders"].OffsetLag)
+	}
+	{
+		reader := fakeOffsetLagReader{
+			endOffsets:       map[int]int64{0: 100, 1: 50},
+			committedOffsets: map[int]int64{0: 60, 1: 50},
+		}
+
+		recorder := &gaugeRecorder{gauges: make(map[string]float64)}
+		assert.NoError(t, recordOffsetLag(context.Background(), reader, "group", "orders", recorder, tracker))
+		assert.Equal(t, map[string]float64{"offset.lag:orders:0": 40, "offset.lag:orders:1": 0}, recorder.gauges)
+
+		status := tracker.Status(time.Now(), time.Minute)
+		assert.Equal(t, ptr.ToInt64(40), status.Topics["orders"].OffsetLag)
+		assert.Equal(t, map[int]int64{0: 40, 1: 0}, status.Topics["orders"].PartitionOffsetLag)
+	}
+}
@@ -0,0 +1,141 @@
+package consumer
+
+import (
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type topicPartition struct {
+	topic     string
+	partition int
+}
+
+func newTopicPartition(msg kafka.Message) topicPartition {
+	return topicPartition{topic: msg.Topic, partition: msg.Partition}
+}
+
+// offsetTracker makes sure that we never commit an offset past a message that has not been flushed yet.
+// A partition's messages can be buffered across multiple tables, so flushing one table should only commit up to the first message that's still buffered by the others.
+// A nil tracker will not track anything, so every message will be committed as is.
+type offsetTracker struct {
+	// pending is the first offset that was buffered for each table since it was last flushed.
+	pending map[topicPartition]map[string]int64
+	// flushed is the last offset that was flushed for each table, this is used to ignore calls to Buffered that arrive after the flush.
+	flushed map[topicPartition]map[string]int64
+	// processed is the last offset that we have finished processing, this includes messages that were skipped.
+	processed map[topicPartition]int64
+	sync.Mutex
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{
+		pending:   make(map[topicPartition]map[string]int64),
+		flushed:   make(map[topicPartition]map[string]int64),
+		processed: make(map[topicPartition]int64),
+	}
+}
+
+// Buffered is called once `msg` has been buffered for `tableName`.
+func (o *offsetTracker) Buffered(tableName string, msg kafka.Message) {
+	if o == nil {
+		return
+	}
+
+	o.Lock()
+	defer o.Unlock()
+
+	tp := newTopicPartition(msg)
+	if flushedOffset, isOk := o.flushed[tp][tableName]; isOk && msg.Offset <= flushedOffset {
+		// The table was flushed (including this message) before we got here.
+		return
+	}
+
+	if _, isOk := o.pending[tp]; !isOk {
+		o.pending[tp] = make(map[string]int64)
+	}
+
+	if _, isOk := o.pending[tp][tableName]; !isOk {
+		o.pending[tp][tableName] = msg.Offset
+	}
+}
+
+// Processed is called once we're done with `msg`, regardless of whether it was buffered or skipped.
+func (o *offsetTracker) Processed(msg kafka.Message) {
+	if o == nil {
+		return
+	}
+
+	o.Lock()
+	defer o.Unlock()
+
+	tp := newTopicPartition(msg)
+	if offset, isOk := o.processed[tp]; !isOk || msg.Offset > offset {
+		o.processed[tp] = msg.Offset
+	}
+}
+
+// Committable returns the message that can be committed once `tableName` has been flushed up to and including `msg`.
+func (o *offsetTracker) Committable(tableName string, msg kafka.Message) kafka.Message {
+	if o == nil {
+		return msg
+	}
+
+	o.Lock()
+	defer o.Unlock()
+
+	tp := newTopicPartition(msg)
+	offset := msg.Offset
+	if processedOffset, isOk := o.processed[tp]; isOk && processedOffset > offset {
+		offset = processedOffset
+	}
+
+	for otherTableName, firstOffset := range o.pending[tp] {
+		if otherTableName != tableName && firstOffset-1 < offset {
+			offset = firstOffset - 1
+		}
+	}
+
+	if offset == msg.Offset {
+		return msg
+	}
+
+	return kafka.Message{Topic: msg.Topic, Partition: msg.Partition, Offset: offset}
+}
+
+// Flushed is called once `tableName` has been flushed and committed up to and including `msg`.
+func (o *offsetTracker) Flushed(tableName string, msg kafka.Message) {
+	if o == nil {
+		return
+	}
+
+	o.Lock()
+	defer o.Unlock()
+
+	tp := newTopicPartition(msg)
+	delete(o.pending[tp], tableName)
+	if _, isOk := o.flushed[tp]; !isOk {
+		o.flushed[tp] = make(map[string]int64)
+	}
+
+	if offset, isOk := o.flushed[tp][tableName]; !isOk || msg.Offset > offset {
+		o.flushed[tp][tableName] = msg.Offset
+	}
+}
+
+// Forget will stop tracking `partitions` of `topic`, this is called when the partitions are reassigned and will be read again from the last committed offset.
+func (o *offsetTracker) Forget(topic string, partitions []int) {
+	if o == nil {
+		return
+	}
+
+	o.Lock()
+	defer o.Unlock()
+
+	for _, partition := range partitions {
+		tp := topicPartition{topic: topic, partition: partition}
+		delete(o.pending, tp)
+		delete(o.flushed, tp)
+		delete(o.processed, tp)
+	}
+}
@@ -0,0 +1,108 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics"
+	"github.com/artie-labs/transfer/models/event"
+)
+
+func TestOffsetTracker(t *testing.T) {
+	msg := func(offset int64) kafka.Message {
+		return kafka.Message{Topic: "foo", Partition: 1, Offset: offset}
+	}
+
+	tracker := newOffsetTracker()
+	tracker.Buffered("orders", msg(10))
+	tracker.Processed(msg(10))
+	tracker.Buffered("customers", msg(11))
+	tracker.Processed(msg(11))
+	tracker.Buffered("orders", msg(12))
+	tracker.Processed(msg(12))
+	// Skipped message
+	tracker.Processed(msg(13))
+
+	// Customers has not been flushed, so we can only commit up to the message before it.
+	assert.Equal(t, msg(10), tracker.Committable("orders", msg(12)))
+	tracker.Flushed("orders", msg(12))
+
+	// Now that orders has been flushed, we can commit everything that has been processed.
+	assert.Equal(t, msg(13), tracker.Committable("customers", msg(11)))
+	tracker.Flushed("customers", msg(11))
+
+	// Buffered was called after the table had already been flushed, this should be ignored.
+	tracker.Buffered("customers", msg(11))
+	tracker.Buffered("orders", msg(14))
+	tracker.Processed(msg(14))
+	assert.Equal(t, msg(14), tracker.Committable("orders", msg(14)))
+
+	// Other partitions are tracked separately.
+	assert.Equal(t, kafka.Message{Topic: "foo", Partition: 2, Offset: 5}, tracker.Committable("customers", kafka.Message{Topic: "foo", Partition: 2, Offset: 5}))
+
+	// Forgetting a partition will drop everything.
+	tracker.Forget("foo", []int{1})
+	assert.Empty(t, tracker.pending)
+	assert.Empty(t, tracker.processed)
+	assert.Equal(t, msg(3), tracker.Committable("orders", msg(3)))
+
+	// A nil tracker does not track anything.
+	var nilTracker *offsetTracker
+	nilTracker.Buffered("orders", msg(10))
+	nilTracker.Processed(msg(10))
+	nilTracker.Buffered("customers", msg(11))
+	assert.Equal(t, msg(11), nilTracker.Committable("orders", msg(11)))
+	nilTracker.Flushed("orders", msg(11))
+	nilTracker.Forget("foo", []int{1})
+}
+
+func (f *FlushTestSuite) TestCommitAfterFlush() {
+	opts := Options{kafkaOffsets: newOffsetTracker()}
+	saveRow := func(tableName string, offset int) {
+		evt := event.Event{
+			Table:         tableName,
+			PrimaryKeyMap: map[string]any{"id": fmt.Sprintf("pk-%d", offset)},
+			Data: map[string]any{
+				"id":                         fmt.Sprintf("pk-%d", offset),
+				constants.DeleteColumnMarker: false,
+				"foo":                        "bar",
+			},
+		}
+
+		kafkaMsg := kafka.Message{Topic: "foo", Partition: 1, Offset: int64(offset)}
+		_, _, err := evt.Save(f.cfg, f.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(f.T(), err)
+		opts.kafkaOffsets.Buffered(tableName, kafkaMsg)
+		opts.kafkaOffsets.Processed(kafkaMsg)
+	}
+
+	saveRow("orders", 10)
+	saveRow("customers", 11)
+	// Nothing should be committed until the rows have been flushed.
+	assert.Equal(f.T(), 0, f.fakeConsumer.CommitMessagesCallCount())
+
+	// Orders is flushed, but we cannot commit past the row that is still buffered for customers.
+	assert.NoError(f.T(), Flush(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Args{Options: opts, SpecificTable: "orders"}))
+	assert.Equal(f.T(), 1, f.fakeConsumer.CommitMessagesCallCount())
+	_, kafkaMessages := f.fakeConsumer.CommitMessagesArgsForCall(0)
+	assert.Equal(f.T(), []kafka.Message{{Topic: "foo", Partition: 1, Offset: 10}}, kafkaMessages)
+
+	// If the flush fails, the offset should not be committed and the rows should be retried on the next flush.
+	f.fakeStore.ExecReturns(nil, fmt.Errorf("destination is unavailable"))
+	assert.NoError(f.T(), Flush(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Args{Options: opts, SpecificTable: "customers"}))
+	assert.Equal(f.T(), 1, f.fakeConsumer.CommitMessagesCallCount())
+	assert.False(f.T(), f.db.GetOrCreateTableData("customers").Empty())
+
+	f.fakeStore.ExecReturns(nil, nil)
+	assert.NoError(f.T(), Flush(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Args{Options: opts, SpecificTable: "customers"}))
+	assert.Equal(f.T(), 2, f.fakeConsumer.CommitMessagesCallCount())
+	_, kafkaMessages = f.fakeConsumer.CommitMessagesArgsForCall(1)
+	assert.Equal(f.T(), int64(11), kafkaMessages[0].Offset)
+	assert.True(f.T(), f.db.GetOrCreateTableData("customers").Empty())
+}
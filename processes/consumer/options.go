@@ -0,0 +1,72 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/artie-labs/transfer/lib/config"
+)
+
+// Options are the consumer settings that are shared by every flush, they should be built once on start up with [NewOptions].
+// The zero value is valid and will use the defaults (e.g. flushes are not limited).
+type Options struct {
+	// flushLimiter is applied across all tables, a nil limiter will not limit anything.
+	flushLimiter *FlushLimiter
+	// kafkaOffsets keeps track of the offsets that can be committed once a table has been flushed, see [offsetTracker].
+	kafkaOffsets *offsetTracker
+	// writtenOffsets is only set if exactly-once delivery is enabled, see [Options.exactlyOnceDestination].
+	writtenOffsets *writtenOffsetCache
+	// statusPublisher is nil unless a status topic has been configured.
+	statusPublisher StatusPublisher
+	// flushRetry is nil unless it has been configured, in which case failing flushes are retried as a whole.
+	flushRetry *config.FlushRetry
+	// deadLetterPublisher is only set if a dead letter topic has been configured.
+	deadLetterPublisher DeadLetterPublisher
+	// identifierCasing is used to escape the columns that the assertions reference, this should match the destination's casing.
+	identifierCasing config.IdentifierCasing
+}
+
+// NewOptions will create the publishers for the topics that have been configured, [Options.Close] should be called once we are done consuming.
+func NewOptions(ctx context.Context, cfg config.Config) (Options, error) {
+	opts := Options{
+		flushLimiter:     NewFlushLimiter(cfg.MaxConcurrentFlushes, cfg.FlushesPerMinute),
+		kafkaOffsets:     newOffsetTracker(),
+		flushRetry:       cfg.FlushRetry,
+		identifierCasing: cfg.SharedDestinationConfig.GetIdentifierCasing(),
+	}
+
+	if cfg.DeliveryGuarantee == config.ExactlyOnce {
+		opts.writtenOffsets = newWrittenOffsetCache()
+	}
+
+	statusPublisher, err := NewStatusPublisher(ctx, cfg)
+	if err != nil {
+		return Options{}, err
+	}
+
+	deadLetterPublisher, err := NewDeadLetterPublisher(ctx, cfg)
+	if err != nil {
+		if statusPublisher != nil {
+			statusPublisher.Close()
+		}
+
+		return Options{}, err
+	}
+
+	opts.statusPublisher = statusPublisher
+	opts.deadLetterPublisher = deadLetterPublisher
+	return opts, nil
+}
+
+func (o Options) Close() error {
+	var errs []error
+	if o.statusPublisher != nil {
+		errs = append(errs, o.statusPublisher.Close())
+	}
+
+	if o.deadLetterPublisher != nil {
+		errs = append(errs, o.deadLetterPublisher.Close())
+	}
+
+	return errors.Join(errs...)
+}
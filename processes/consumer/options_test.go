@@ -0,0 +1,75 @@
+package consumer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+)
+
+func newTestOptions(t *testing.T, cfg config.Config) Options {
+	opts, err := NewOptions(context.Background(), cfg)
+	assert.NoError(t, err)
+	return opts
+}
+
+func TestNewOptions(t *testing.T) {
+	{
+		// Defaults
+		opts := newTestOptions(t, config.Config{})
+		assert.Nil(t, opts.flushLimiter)
+		assert.NotNil(t, opts.kafkaOffsets)
+		assert.Nil(t, opts.writtenOffsets)
+		assert.Nil(t, opts.statusPublisher)
+		assert.Nil(t, opts.flushRetry)
+		assert.Nil(t, opts.deadLetterPublisher)
+		assert.Equal(t, config.PreserveCasing, opts.identifierCasing)
+		assert.NoError(t, opts.Close())
+	}
+	{
+		// Flush limits
+		opts := newTestOptions(t, config.Config{MaxConcurrentFlushes: 2, FlushesPerMinute: 60})
+		assert.NotNil(t, opts.flushLimiter)
+		assert.Equal(t, 2, cap(opts.flushLimiter.slots))
+	}
+	{
+		// Identifier casing
+		opts := newTestOptions(t, config.Config{SharedDestinationConfig: config.SharedDestinationConfig{UppercaseEscapedNames: true}})
+		assert.Equal(t, config.UpperCasing, opts.identifierCasing)
+	}
+	{
+		// Exactly-once delivery
+		opts := newTestOptions(t, config.Config{DeliveryGuarantee: config.ExactlyOnce})
+		assert.NotNil(t, opts.writtenOffsets)
+	}
+	{
+		// Status topic
+		opts := newTestOptions(t, config.Config{
+			Queue:       constants.Kafka,
+			Kafka:       &config.Kafka{BootstrapServer: "localhost:9092"},
+			StatusTopic: &config.StatusTopic{Topic: "transfer_status"},
+		})
+		assert.NotNil(t, opts.statusPublisher)
+		assert.NoError(t, opts.Close())
+	}
+	{
+		// Invalid status topic
+		_, err := NewOptions(context.Background(), config.Config{Queue: constants.NATS, StatusTopic: &config.StatusTopic{Topic: "transfer_status"}})
+		assert.ErrorContains(t, err, `status topic is not supported for queue: "nats"`)
+	}
+	{
+		// Dead letter topic
+		flushRetry := &config.FlushRetry{MaxBatchRetries: 3, Bisect: true, DeadLetterTopic: "transfer_dlq"}
+		opts := newTestOptions(t, config.Config{
+			Queue:      constants.Kafka,
+			Kafka:      &config.Kafka{BootstrapServer: "localhost:9092"},
+			FlushRetry: flushRetry,
+		})
+		assert.Equal(t, flushRetry, opts.flushRetry)
+		assert.NotNil(t, opts.deadLetterPublisher)
+		assert.NoError(t, opts.Close())
+	}
+}
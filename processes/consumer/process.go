@@ -2,12 +2,20 @@ package consumer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/cdc"
+	"github.com/artie-labs/transfer/lib/cdc/util"
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/health"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/stringutil"
 	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
 	"github.com/artie-labs/transfer/models"
 	"github.com/artie-labs/transfer/models/event"
@@ -17,6 +25,31 @@ type processArgs struct {
 	Msg                    artie.Message
 	GroupID                string
 	TopicToConfigFormatMap *TcFmtMap
+	// AfterFlush is optional and is called with how long the flush took if this message triggered a flush.
+	AfterFlush func(time.Duration)
+	Options    Options
+}
+
+// blockingError is returned when a message could not be processed and cannot be skipped either, the consumer will keep retrying the message instead of moving on to the next one.
+type blockingError struct {
+	err error
+}
+
+func newBlockingError(err error) error {
+	return blockingError{err: err}
+}
+
+func (b blockingError) Error() string {
+	return b.err.Error()
+}
+
+func (b blockingError) Unwrap() error {
+	return b.err
+}
+
+func isBlockingError(err error) bool {
+	var blockingErr blockingError
+	return errors.As(err, &blockingErr)
 }
 
 func (p processArgs) process(ctx context.Context, cfg config.Config, inMemDB *models.DatabaseData, dest destination.Baseline, metricsClient base.Client) (string, error) {
@@ -37,6 +70,14 @@ func (p processArgs) process(ctx context.Context, cfg config.Config, inMemDB *mo
 		metricsClient.Timing("process.message", time.Since(st), tags)
 	}()
 
+	// sourceTime is when the change happened in the source, it's only available once we've parsed the event.
+	var sourceTime time.Time
+	defer func() {
+		if lag, isOk := p.Msg.EmitIngestionLag(metricsClient, cfg.Mode, cfg.IngestionLag, p.GroupID, tags["table"], sourceTime); isOk {
+			health.Default().RecordIngestionLag(p.Msg.Topic(), lag)
+		}
+	}()
+
 	topicConfig, isOk := p.TopicToConfigFormatMap.GetTopicFmt(p.Msg.Topic())
 	if !isOk {
 		tags["what"] = "failed_topic_lookup"
@@ -46,47 +87,246 @@ func (p processArgs) process(ctx context.Context, cfg config.Config, inMemDB *mo
 	tags["database"] = topicConfig.tc.Database
 	tags["schema"] = topicConfig.tc.Schema
 
+	if topicConfig.tc.ApplySchemaChanges {
+		return "", p.processSchemaChange(cfg, dest, tags)
+	}
+
+	typingSettings := cfg.SharedTransferConfig.TypingSettings
+	// If the format is detected from the message, it needs to see the value before we can parse the key.
+	if detector, isOk := topicConfig.Format.(cdc.ValueDetector); isOk {
+		detector.DetectFromValue(p.Msg.Value())
+	}
+
 	pkMap, err := topicConfig.GetPrimaryKey(p.Msg.Key(), topicConfig.tc)
 	if err != nil {
+		// Truncate events do not have a key, so we'll check the event before returning an error.
+		if len(p.Msg.Key()) == 0 {
+			if _event, eventErr := topicConfig.GetEventFromBytes(typingSettings, p.Msg.Value()); eventErr == nil && _event.Operation() == cdc.TruncateOperation {
+				return p.processTruncate(ctx, cfg, inMemDB, dest, metricsClient, *topicConfig.tc, _event, tags)
+			}
+		}
+
 		tags["what"] = "marshall_pk_err"
 		return "", fmt.Errorf("cannot unmarshall key %s: %w", string(p.Msg.Key()), err)
 	}
 
-	typingSettings := cfg.SharedTransferConfig.TypingSettings
-	_event, err := topicConfig.GetEventFromBytes(typingSettings, p.Msg.Value())
-	if err != nil {
-		tags["what"] = "marshall_value_err"
-		return "", fmt.Errorf("cannot unmarshall event: %w", err)
+	var _event cdc.Event
+	if len(p.Msg.Value()) == 0 && topicConfig.tc.Compacted {
+		// This is a tombstone on a compacted topic, which is a delete for the key.
+		_event = cdc.NewTombstoneEvent(p.Msg.PublishTime())
+	} else {
+		_event, err = topicConfig.GetEventFromBytes(typingSettings, p.Msg.Value())
+		if err != nil {
+			tags["what"] = "marshall_value_err"
+			return "", fmt.Errorf("cannot unmarshall event: %w", err)
+		}
 	}
 
+	sourceTime = _event.GetExecutionTime()
 	tags["op"] = _event.Operation()
-	evt := event.ToMemoryEvent(_event, pkMap, topicConfig.tc, cfg.Mode)
+	evt, err := event.ToMemoryEvent(_event, pkMap, topicConfig.tc, cfg.Mode.TableMode())
+	if err != nil {
+		tags["what"] = "to_mem_event_err"
+		return "", fmt.Errorf("cannot convert event: %w", err)
+	}
+
 	// Table name is only available after event has been cast
 	tags["table"] = evt.Table
 
+	// We don't know how to apply unknown operations, so they are always skipped regardless of the topic's ops.
+	if !kafkalib.IsKnownOperation(_event.Operation()) {
+		slog.Warn("Skipping event with an unknown operation", slog.String("op", _event.Operation()), slog.String("table", evt.Table))
+		tags["skipped"] = "unknown_op"
+		p.ackSkipped()
+		return evt.Table, nil
+	}
+
 	if topicConfig.tc.ShouldSkip(_event.Operation()) {
 		// Check to see if we should skip first
 		// This way, we can emit a specific tag to be more clear
 		tags["skipped"] = "yes"
+		p.ackSkipped()
 		return evt.Table, nil
 	}
 
+	// Deletes only contain the primary keys, so we cannot evaluate the row filter against them.
+	if !evt.Deleted && !topicConfig.tc.ShouldKeepRow(evt.Data) {
+		tags["skipped"] = "filtered"
+		p.ackSkipped()
+		return evt.Table, nil
+	}
+
+	if nullKeys := evt.NullPrimaryKeys(); len(nullKeys) > 0 {
+		switch topicConfig.tc.NullPrimaryKeyPolicy {
+		case kafkalib.NullPrimaryKeyPolicyError:
+			tags["what"] = "null_primary_key"
+			// Skipping the row is what the policy is meant to prevent, so we'll stop on this message instead.
+			return "", newBlockingError(fmt.Errorf("primary keys %v are null", nullKeys))
+		case kafkalib.NullPrimaryKeyPolicyRouteToDLQ:
+			err = p.Options.publishDeadLetter(ctx, DeadLetter{
+				Table:    evt.Table,
+				Database: topicConfig.tc.Database,
+				Schema:   topicConfig.tc.Schema,
+				Topic:    p.Msg.Topic(),
+				Error:    fmt.Sprintf("primary keys %v are null", nullKeys),
+				Row:      evt.Data,
+				FailedAt: time.Now().UTC(),
+			})
+			if err != nil {
+				tags["what"] = "dead_letter_fail"
+				return "", newBlockingError(err)
+			}
+		default:
+			slog.Warn("Skipping row with a null primary key", slog.String("table", evt.Table), slog.Any("primaryKeys", nullKeys))
+		}
+
+		metricsClient.Incr("process.null_primary_key", tags)
+		tags["skipped"] = "null_primary_key"
+		p.ackSkipped()
+		return evt.Table, nil
+	}
+
+	if p.Msg.KafkaMsg != nil {
+		if exactlyOnceDest, isOk := p.Options.exactlyOnceDestination(dest); isOk {
+			alreadyWritten, err := p.Options.writtenOffsets.AlreadyWritten(exactlyOnceDest, *topicConfig.tc, evt.Table, *p.Msg.KafkaMsg)
+			if err != nil {
+				tags["what"] = "written_offsets_fail"
+				// We cannot tell if this message has been written yet, skipping it could lose the row.
+				return "", newBlockingError(err)
+			}
+
+			if alreadyWritten {
+				// This message was written before we crashed, but the offset was never committed to Kafka.
+				tags["skipped"] = "already_written"
+				return evt.Table, nil
+			}
+		}
+	}
+
 	shouldFlush, flushReason, err := evt.Save(cfg, inMemDB, topicConfig.tc, p.Msg)
 	if err != nil {
 		tags["what"] = "save_fail"
 		return "", fmt.Errorf("event failed to save: %w", err)
 	}
 
+	for _, col := range evt.TruncatedColumns() {
+		slog.Warn("Truncated a value that was over the max value length", slog.String("table", evt.Table), slog.String("column", col))
+		metricsClient.Incr("column.truncated", map[string]string{
+			"database": tags["database"],
+			"schema":   tags["schema"],
+			"table":    tags["table"],
+			"column":   col,
+		})
+	}
+
+	if evt.Dropped() {
+		metricsClient.Incr("process.dropped_row", map[string]string{
+			"database": tags["database"],
+			"schema":   tags["schema"],
+			"table":    tags["table"],
+		})
+	}
+
+	health.Default().RecordRow(evt.Table, time.Now())
+	if p.Msg.KafkaMsg != nil {
+		// The offset will not be committed until this table has been flushed.
+		p.Options.kafkaOffsets.Buffered(evt.Table, *p.Msg.KafkaMsg)
+	}
+
 	if shouldFlush {
+		flushStart := time.Now()
 		err = Flush(ctx, inMemDB, dest, metricsClient, Args{
+			Options:       p.Options,
 			Reason:        flushReason,
 			SpecificTable: evt.Table,
 		})
 		if err != nil {
 			tags["what"] = "flush_fail"
 		}
+
+		if p.AfterFlush != nil {
+			p.AfterFlush(time.Since(flushStart))
+		}
+
 		return evt.Table, err
 	}
 
 	return evt.Table, nil
 }
+
+// processTruncate will flush what we have buffered for the table and then truncate it, truncate events are skipped unless `handleTruncate` is enabled.
+func (p processArgs) processTruncate(ctx context.Context, cfg config.Config, inMemDB *models.DatabaseData, dest destination.Baseline, metricsClient base.Client, tc kafkalib.TopicConfig, _event cdc.Event, tags map[string]string) (string, error) {
+	tags["op"] = _event.Operation()
+	tableName := stringutil.Override(_event.GetTableName(), tc.TableName)
+	tags["table"] = tableName
+
+	// History mode tables are an append-only log, so we'll never truncate them.
+	if !tc.HandleTruncate || cfg.Mode == config.History {
+		tags["skipped"] = "yes"
+		p.ackSkipped()
+		return tableName, nil
+	}
+
+	// Rows that were buffered before the truncate need to be written first, so their offsets are committed in order.
+	inMemDB.RLock()
+	td, isOk := inMemDB.TableData()[tableName]
+	inMemDB.RUnlock()
+	if isOk && !td.Empty() {
+		if err := Flush(ctx, inMemDB, dest, metricsClient, Args{Options: p.Options, Reason: "truncate", SpecificTable: tableName}); err != nil {
+			tags["what"] = "flush_fail"
+			return tableName, newBlockingError(err)
+		}
+
+		// Flush does not return merge errors, so we need to make sure the rows were written before truncating.
+		// Otherwise, the next flush would write the rows from before the truncate back into the table.
+		td.Lock()
+		isEmpty := td.Empty()
+		td.Unlock()
+		if !isEmpty {
+			tags["what"] = "flush_fail"
+			return tableName, newBlockingError(fmt.Errorf("failed to flush table: %s before truncating it", tableName))
+		}
+	}
+
+	if err := truncateTable(dest, optimization.NewTableData(nil, cfg.Mode.TableMode(), nil, tc, tableName)); err != nil {
+		tags["what"] = "truncate_fail"
+		// Skipping the truncate would leave the rows from before the truncate in the destination.
+		return tableName, newBlockingError(err)
+	}
+
+	p.ackSkipped()
+	return tableName, nil
+}
+
+// processSchemaChange will apply a message from Debezium's schema change topic to the destination.
+func (p processArgs) processSchemaChange(cfg config.Config, dest destination.Baseline, tags map[string]string) error {
+	schemaChange, err := util.ParseSchemaChangeEvent(p.Msg.Value())
+	if err != nil {
+		tags["what"] = "marshall_value_err"
+		return fmt.Errorf("cannot unmarshall schema change event: %w", err)
+	}
+
+	tags["op"] = "ddl"
+	if err = applySchemaChange(cfg, dest, p.TopicToConfigFormatMap, schemaChange); err != nil {
+		tags["what"] = "schema_change_fail"
+		// Skipping the schema change would leave the destination tables without the new columns.
+		return newBlockingError(err)
+	}
+
+	p.ackSkipped()
+	return nil
+}
+
+// ackSkipped will ack Pub/Sub and NATS messages that we have skipped (or already applied), since they will never be flushed.
+// Kafka does not need this because the offset will be committed by the next flush for this partition.
+func (p processArgs) ackSkipped() {
+	if p.Msg.PubSub != nil {
+		p.Msg.PubSub.Ack()
+	}
+
+	if p.Msg.NATS != nil {
+		if err := p.Msg.NATS.Ack(); err != nil {
+			slog.Warn("Failed to ack skipped nats message", slog.Any("err", err))
+		}
+	}
+}
@@ -3,11 +3,13 @@ package consumer
 import (
 	"context"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/artie-labs/transfer/lib/artie"
 	"github.com/artie-labs/transfer/lib/cdc/mongo"
+	"github.com/artie-labs/transfer/lib/cdc/postgres"
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/config/constants"
 	"github.com/artie-labs/transfer/lib/kafkalib"
@@ -371,3 +373,271 @@ func TestProcessMessageSkip(t *testing.T) {
 		assert.Equal(t, 0, int(td.NumberOfRows()))
 	}
 }
+
+func TestProcessMessageProcessOps(t *testing.T) {
+	cfg := config.Config{
+		FlushIntervalSeconds: 10,
+		BufferRows:           10,
+		FlushSizeKb:          900,
+	}
+
+	for _, processOps := range [][]string{nil, {"c", "u", "d"}} {
+		memDB := models.NewMemoryDB()
+		kafkaMsg := kafka.Message{Topic: "foo"}
+		msg := artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic)
+
+		tc := &kafkalib.TopicConfig{
+			Database:     "lemonade",
+			TableName:    "orders",
+			Schema:       "public",
+			Topic:        msg.Topic(),
+			CDCFormat:    constants.DBZMongoFormat,
+			CDCKeyFormat: kafkalib.StringKeyFmt,
+			ProcessOps:   processOps,
+		}
+		tc.Load()
+		assert.NoError(t, tc.Validate())
+
+		var mgo mongo.Debezium
+		tcFmtMap := NewTcFmtMap()
+		tcFmtMap.Add(msg.Topic(), TopicConfigFormatter{tc: tc, Format: &mgo})
+
+		args := processArgs{
+			Msg:                    msg,
+			GroupID:                "foo",
+			TopicToConfigFormatMap: tcFmtMap,
+		}
+
+		for idx, op := range []string{"r", "r", "c", "u", "x"} {
+			msg.KafkaMsg.Key = []byte(fmt.Sprintf("Struct{id=%d}", idx))
+			msg.KafkaMsg.Value = []byte(fmt.Sprintf(`{
+	"payload": {
+		"before": null,
+		"after": "{\"_id\": {\"$numberLong\": \"%d\"}, \"op\": \"%s\"}",
+		"source": {"connector": "mongodb", "ts_ms": 1668753321000, "db": "inventory", "collection": "orders"},
+		"op": "%s"
+	}
+}`, idx, op, op))
+
+			tableName, err := args.process(context.Background(), cfg, memDB, MockDestination{}, metrics.NullMetricsProvider{})
+			assert.NoError(t, err, processOps)
+			assert.Equal(t, "orders", tableName, processOps)
+		}
+
+		var ops []string
+		for _, row := range memDB.GetOrCreateTableData("orders").Rows() {
+			ops = append(ops, row["op"].(string))
+		}
+
+		slices.Sort(ops)
+		if processOps == nil {
+			// By default, every known operation is processed.
+			assert.Equal(t, []string{"c", "r", "r", "u"}, ops)
+		} else {
+			// Snapshot reads and unknown operations are skipped.
+			assert.Equal(t, []string{"c", "u"}, ops)
+		}
+	}
+}
+
+func TestProcessMessageRowFilter(t *testing.T) {
+	cfg := config.Config{
+		FlushIntervalSeconds: 10,
+		BufferRows:           10,
+		FlushSizeKb:          900,
+	}
+
+	memDB := models.NewMemoryDB()
+	kafkaMsg := kafka.Message{Topic: "foo"}
+	msg := artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic)
+
+	tc := &kafkalib.TopicConfig{
+		Database:     "lemonade",
+		TableName:    "orders",
+		Schema:       "public",
+		Topic:        msg.Topic(),
+		CDCFormat:    constants.DBZMongoFormat,
+		CDCKeyFormat: kafkalib.StringKeyFmt,
+		RowFilter:    "tenant_id = 42",
+	}
+	tc.Load()
+	assert.NoError(t, tc.Validate())
+
+	var mgo mongo.Debezium
+	tcFmtMap := NewTcFmtMap()
+	tcFmtMap.Add(msg.Topic(), TopicConfigFormatter{tc: tc, Format: &mgo})
+
+	args := processArgs{
+		Msg:                    msg,
+		GroupID:                "foo",
+		TopicToConfigFormatMap: tcFmtMap,
+	}
+
+	for idx, tenantID := range []int{1, 42, 7, 42} {
+		msg.KafkaMsg.Key = []byte(fmt.Sprintf("Struct{id=%d}", idx))
+		msg.KafkaMsg.Value = []byte(fmt.Sprintf(`{
+	"payload": {
+		"before": null,
+		"after": "{\"_id\": {\"$numberLong\": \"%d\"}, \"tenant_id\": %d}",
+		"source": {"connector": "mongodb", "ts_ms": 1668753321000, "db": "inventory", "collection": "orders"},
+		"op": "c"
+	}
+}`, idx, tenantID))
+
+		tableName, err := args.process(context.Background(), cfg, memDB, MockDestination{}, metrics.NullMetricsProvider{})
+		assert.NoError(t, err)
+		assert.Equal(t, "orders", tableName)
+	}
+
+	// Only the rows with tenant_id = 42 should have been kept.
+	td := memDB.GetOrCreateTableData("orders")
+	assert.Equal(t, 2, int(td.NumberOfRows()))
+	for _, row := range td.Rows() {
+		assert.Equal(t, float64(42), row["tenant_id"])
+	}
+}
+
+func TestProcessMessageNullPrimaryKey(t *testing.T) {
+	cfg := config.Config{
+		FlushIntervalSeconds: 10,
+		BufferRows:           10,
+		FlushSizeKb:          900,
+	}
+
+	publisher := &fakeDeadLetterPublisher{}
+	opts := Options{flushRetry: &config.FlushRetry{MaxBatchRetries: 3, DeadLetterTopic: "transfer_dlq"}, deadLetterPublisher: publisher}
+
+	for _, policy := range []kafkalib.NullPrimaryKeyPolicy{"", kafkalib.NullPrimaryKeyPolicySkip, kafkalib.NullPrimaryKeyPolicyError, kafkalib.NullPrimaryKeyPolicyRouteToDLQ} {
+		memDB := models.NewMemoryDB()
+		kafkaMsg := kafka.Message{Topic: "foo"}
+		msg := artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic)
+
+		tc := &kafkalib.TopicConfig{
+			Database:             "lemonade",
+			TableName:            "orders",
+			Schema:               "public",
+			Topic:                msg.Topic(),
+			CDCFormat:            constants.DBZMongoFormat,
+			CDCKeyFormat:         kafkalib.StringKeyFmt,
+			PrimaryKeyOverride:   []string{"order_id"},
+			NullPrimaryKeyPolicy: policy,
+		}
+		tc.Load()
+		assert.NoError(t, tc.Validate())
+
+		var mgo mongo.Debezium
+		tcFmtMap := NewTcFmtMap()
+		tcFmtMap.Add(msg.Topic(), TopicConfigFormatter{tc: tc, Format: &mgo})
+
+		args := processArgs{
+			Msg:                    msg,
+			GroupID:                "foo",
+			TopicToConfigFormatMap: tcFmtMap,
+			Options:                opts,
+		}
+
+		for idx, orderID := range []string{"1", "null", "2"} {
+			msg.KafkaMsg.Key = []byte(fmt.Sprintf("Struct{id=%d}", idx))
+			msg.KafkaMsg.Value = []byte(fmt.Sprintf(`{
+	"payload": {
+		"before": null,
+		"after": "{\"_id\": {\"$numberLong\": \"%d\"}, \"order_id\": %s}",
+		"source": {"connector": "mongodb", "ts_ms": 1668753321000, "db": "inventory", "collection": "orders"},
+		"op": "c"
+	}
+}`, idx, orderID))
+
+			tableName, err := args.process(context.Background(), cfg, memDB, MockDestination{}, metrics.NullMetricsProvider{})
+			if orderID == "null" && policy == kafkalib.NullPrimaryKeyPolicyError {
+				assert.ErrorContains(t, err, "primary keys [order_id] are null", policy)
+				// This should stop the partition instead of skipping the row.
+				assert.True(t, isBlockingError(err), policy)
+				assert.Empty(t, tableName, policy)
+				continue
+			}
+
+			assert.NoError(t, err, policy)
+			assert.Equal(t, "orders", tableName, policy)
+		}
+
+		// The row with the null primary key should not have been buffered.
+		td := memDB.GetOrCreateTableData("orders")
+		assert.Equal(t, 2, int(td.NumberOfRows()), policy)
+		for _, row := range td.Rows() {
+			assert.NotNil(t, row["order_id"], policy)
+		}
+	}
+
+	// Only the route-to-dlq policy should have published the row.
+	assert.Equal(t, []string{"orders"}, publisher.keys)
+	assert.Len(t, publisher.deadLetters, 1)
+	assert.Equal(t, "lemonade", publisher.deadLetters[0].Database)
+	assert.Equal(t, "foo", publisher.deadLetters[0].Topic)
+	assert.Equal(t, "primary keys [order_id] are null", publisher.deadLetters[0].Error)
+	assert.Nil(t, publisher.deadLetters[0].Row["order_id"])
+	assert.Contains(t, publisher.deadLetters[0].Row, "order_id")
+}
+
+func TestProcessMessageCompactedTombstone(t *testing.T) {
+	cfg := config.Config{
+		FlushIntervalSeconds: 10,
+		BufferRows:           10,
+		FlushSizeKb:          900,
+	}
+
+	for _, compacted := range []bool{false, true} {
+		memDB := models.NewMemoryDB()
+		kafkaMsg := kafka.Message{Topic: "foo", Key: []byte("Struct{id=1}"), Time: time.Now()}
+		msg := artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic)
+
+		tc := &kafkalib.TopicConfig{
+			Database:     "lemonade",
+			TableName:    "orders",
+			Schema:       "public",
+			Topic:        msg.Topic(),
+			CDCFormat:    constants.DBZPostgresFormat,
+			CDCKeyFormat: kafkalib.StringKeyFmt,
+			Compacted:    compacted,
+		}
+		tc.Load()
+		assert.NoError(t, tc.Validate())
+
+		var pg postgres.Debezium
+		tcFmtMap := NewTcFmtMap()
+		tcFmtMap.Add(msg.Topic(), TopicConfigFormatter{tc: tc, Format: &pg})
+
+		args := processArgs{
+			Msg:                    msg,
+			GroupID:                "foo",
+			TopicToConfigFormatMap: tcFmtMap,
+		}
+
+		// A normal message is an upsert.
+		msg.KafkaMsg.Value = []byte(`{"payload": {"before": null, "after": {"id": 1, "name": "foo"}, "source": {"ts_ms": 1668753321000, "table": "orders"}, "op": "c"}}`)
+		tableName, err := args.process(context.Background(), cfg, memDB, MockDestination{}, metrics.NullMetricsProvider{})
+		assert.NoError(t, err)
+		assert.Equal(t, "orders", tableName)
+
+		td := memDB.GetOrCreateTableData("orders")
+		assert.Len(t, td.Rows(), 1)
+		assert.Equal(t, false, td.Rows()[0][constants.DeleteColumnMarker])
+		assert.Equal(t, "foo", td.Rows()[0]["name"])
+
+		// A message without a value is a tombstone.
+		msg.KafkaMsg.Value = nil
+		tableName, err = args.process(context.Background(), cfg, memDB, MockDestination{}, metrics.NullMetricsProvider{})
+		if !compacted {
+			assert.ErrorContains(t, err, "cannot unmarshall event: empty message")
+			assert.Equal(t, false, td.Rows()[0][constants.DeleteColumnMarker])
+			continue
+		}
+
+		assert.NoError(t, err)
+		assert.Equal(t, "orders", tableName)
+		// The tombstone should have replaced the row with a delete for the same key.
+		assert.Len(t, td.Rows(), 1)
+		assert.Equal(t, true, td.Rows()[0][constants.DeleteColumnMarker])
+		assert.Equal(t, "1", td.Rows()[0]["id"])
+		assert.NotContains(t, td.Rows()[0], "name")
+	}
+}
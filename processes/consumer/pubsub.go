@@ -9,9 +9,10 @@ import (
 
 	gcp_pubsub "cloud.google.com/go/pubsub"
 	"github.com/artie-labs/transfer/lib/artie"
-	"github.com/artie-labs/transfer/lib/cdc/format"
 	"github.com/artie-labs/transfer/lib/config"
 	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/health"
+	"github.com/artie-labs/transfer/lib/jitter"
 	"github.com/artie-labs/transfer/lib/logger"
 	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
 	"github.com/artie-labs/transfer/models"
@@ -36,41 +37,84 @@ func findOrCreateSubscription(ctx context.Context, cfg config.Config, client *gc
 			return nil, fmt.Errorf("failed to fetch gcp topic, topic exists: %v, err: %w", exists, err)
 		}
 
-		sub, err = client.CreateSubscription(ctx, subName, gcp_pubsub.SubscriptionConfig{
-			Topic:       gcpTopic,
-			AckDeadline: defaultAckDeadline,
-			// Enable ordering given the `partition key` which is known as ordering key in Pub/Sub
-			EnableMessageOrdering: true,
-		})
-
+		sub, err = client.CreateSubscription(ctx, subName, subscriptionConfig(*cfg.Pubsub, gcpTopic))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create subscription for topic %s: %w", topic, err)
 		}
 	}
 
+	applyReceiveSettings(cfg, &sub.ReceiveSettings)
+	return sub, err
+}
+
+func subscriptionConfig(cfg config.Pubsub, topic *gcp_pubsub.Topic) gcp_pubsub.SubscriptionConfig {
+	ackDeadline := defaultAckDeadline
+	if cfg.AckDeadlineSeconds > 0 {
+		ackDeadline = time.Duration(cfg.AckDeadlineSeconds) * time.Second
+	}
+
+	return gcp_pubsub.SubscriptionConfig{
+		Topic:       topic,
+		AckDeadline: ackDeadline,
+		// Enable ordering given the `partition key` which is known as ordering key in Pub/Sub
+		EnableMessageOrdering: true,
+	}
+}
+
+func applyReceiveSettings(cfg config.Config, settings *gcp_pubsub.ReceiveSettings) {
 	// This should be the same as our buffer rows so we don't limit our processing throughput
-	sub.ReceiveSettings.MaxOutstandingMessages = int(cfg.BufferRows) + 1
+	settings.MaxOutstandingMessages = int(cfg.BufferRows) + 1
+	if cfg.Pubsub.MaxOutstandingMessages > 0 {
+		settings.MaxOutstandingMessages = cfg.Pubsub.MaxOutstandingMessages
+	}
+
+	if cfg.Pubsub.MaxOutstandingBytes > 0 {
+		settings.MaxOutstandingBytes = cfg.Pubsub.MaxOutstandingBytes
+	}
 
 	// By default, the pub/sub library will try to spawns 10 additional Go-routines per subscription,
 	// it actually does not make the process faster. Rather, it creates more coordination overhead.
 	// Our process message is already extremely fast (~100-200 ns), so we're reducing this down to 1.
-	sub.ReceiveSettings.NumGoroutines = 1
-	return sub, err
+	settings.NumGoroutines = 1
+}
+
+// clientOptions will only specify the credentials file if it's set, otherwise the client will use Application Default Credentials.
+func clientOptions(cfg config.Pubsub) []option.ClientOption {
+	if cfg.PathToCredentials == "" {
+		return nil
+	}
+
+	return []option.ClientOption{option.WithCredentialsFile(cfg.PathToCredentials)}
 }
 
-func StartSubscriber(ctx context.Context, cfg config.Config, inMemDB *models.DatabaseData, dest destination.Baseline, metricsClient base.Client) {
-	client, clientErr := gcp_pubsub.NewClient(ctx, cfg.Pubsub.ProjectID,
-		option.WithCredentialsFile(cfg.Pubsub.PathToCredentials))
+func StartSubscriber(ctx context.Context, cfg config.Config, inMemDB *models.DatabaseData, dest destination.Baseline, metricsClient base.Client, opts Options) {
+	client, clientErr := gcp_pubsub.NewClient(ctx, cfg.Pubsub.ProjectID, clientOptions(*cfg.Pubsub)...)
 	if clientErr != nil {
 		logger.Panic("Failed to create a pubsub client", slog.Any("err", clientErr))
 	}
 
-	tcFmtMap := NewTcFmtMap()
-	for _, topicConfig := range cfg.Pubsub.TopicConfigs {
-		tcFmtMap.Add(topicConfig.Topic, TopicConfigFormatter{
-			tc:     topicConfig,
-			Format: format.GetFormatParser(topicConfig.CDCFormat, topicConfig.Topic),
-		})
+	tcFmtMap, err := loadTcFmtMap(cfg, cfg.Pubsub.TopicConfigs, defaultSchemaRegistryRetryConfig())
+	if err != nil {
+		logger.Panic("Failed to load the topic parsers", slog.Any("err", err))
+	}
+
+	tap, err := newDebugTap(cfg.Debug)
+	if err != nil {
+		logger.Panic("Failed to create the debug tap", slog.Any("err", err))
+	}
+	defer tap.Close()
+
+	maxMemoryBytes := cfg.MaxMemoryMb * 1024 * 1024
+	flushForMemory := func() error {
+		return Flush(ctx, inMemDB, dest, metricsClient, Args{Options: opts, Reason: "memory"})
+	}
+
+	// After a flush, messages that were received concurrently may also ask for a flush. Waiting a bit lets the first flush finish,
+	// so we don't flush the same table again right away. The wait adapts to how long flushes have recently taken.
+	flushJitter := jitter.NewAdaptiveJitter(cfg.Pubsub.FlushJitterBounds())
+	afterFlush := func(flushDuration time.Duration) {
+		flushJitter.Observe(flushDuration)
+		time.Sleep(flushJitter.Jitter())
 	}
 
 	var wg sync.WaitGroup
@@ -84,30 +128,56 @@ func StartSubscriber(ctx context.Context, cfg config.Config, inMemDB *models.Dat
 				logger.Panic("Failed to find or create subscription", slog.Any("err", err))
 			}
 
-			for {
-				err = sub.Receive(ctx, func(_ context.Context, pubsubMsg *gcp_pubsub.Message) {
-					msg := artie.NewMessage(nil, pubsubMsg, topic)
-					logFields := []any{
-						slog.String("topic", msg.Topic()),
-						slog.String("msgID", msg.PubSub.ID),
-						slog.String("key", string(msg.Key())),
-						slog.String("value", string(msg.Value())),
-					}
+			receive := func(_ context.Context, pubsubMsg *gcp_pubsub.Message) {
+				// Blocking here will stop Receive from pulling more messages once we have hit MaxOutstandingMessages.
+				if err := waitForMemory(ctx, inMemDB, maxMemoryBytes, memoryPollInterval, flushForMemory); err != nil {
+					slog.Warn("Stopped waiting for memory to free up", slog.Any("err", err), slog.String("topic", topic))
+					pubsubMsg.Nack()
+					return
+				}
 
-					args := processArgs{
-						Msg:                    msg,
-						GroupID:                subName,
-						TopicToConfigFormatMap: tcFmtMap,
-					}
+				msg := artie.NewMessage(nil, pubsubMsg, topic)
+				logFields := []any{
+					slog.String("topic", msg.Topic()),
+					slog.String("msgID", msg.PubSub.ID),
+					slog.String("key", string(msg.Key())),
+					slog.String("value", string(msg.Value())),
+				}
+
+				args := processArgs{
+					Msg:                    msg,
+					GroupID:                subName,
+					TopicToConfigFormatMap: tcFmtMap,
+					Options:                opts,
+					AfterFlush:             afterFlush,
+				}
+
+				_, processErr := args.process(ctx, cfg, inMemDB, dest, metricsClient)
+				tap.Capture(topic, logFields, processErr)
+				if processErr != nil {
+					slog.With(logFields...).Warn("Skipping message...", slog.Any("err", processErr))
+				}
+			}
 
-					tableName, processErr := args.process(ctx, cfg, inMemDB, dest, metricsClient)
-					msg.EmitIngestionLag(metricsClient, cfg.Mode, subName, tableName)
-					if processErr != nil {
-						slog.With(logFields...).Warn("Skipping message...", slog.Any("err", processErr))
+			if cfg.Mode == config.Snapshot {
+				idleWindow := time.Duration(cfg.SnapshotIdleSeconds) * time.Second
+				if err = drainSubscription(ctx, sub, idleWindow, receive, func() {
+					// Flushing will ack the messages, which is required for Receive to return.
+					if flushErr := Flush(ctx, inMemDB, dest, metricsClient, Args{Options: opts, Reason: "snapshot"}); flushErr != nil {
+						slog.Error("Failed to flush after draining subscription", slog.Any("err", flushErr), slog.String("topic", topic))
 					}
-				})
+				}); err != nil {
+					logger.Panic("Sub receive error", slog.Any("err", err))
+				}
+
+				slog.Info("Subscription has been drained, snapshot is complete", slog.String("topic", topic))
+				return
+			}
 
-				if err != nil {
+			health.Default().SetConnected(topic, true)
+			for {
+				if err = sub.Receive(ctx, receive); err != nil {
+					health.Default().SetConnected(topic, false)
 					logger.Panic("Sub receive error", slog.Any("err", err))
 				}
 			}
@@ -118,3 +188,32 @@ func StartSubscriber(ctx context.Context, cfg config.Config, inMemDB *models.Dat
 
 	wg.Wait()
 }
+
+// drainSubscription will receive messages until the subscription has been idle for `idleWindow`, `onIdle` is called before we stop receiving.
+func drainSubscription(ctx context.Context, sub *gcp_pubsub.Subscription, idleWindow time.Duration, receive func(context.Context, *gcp_pubsub.Message), onIdle func()) error {
+	receiveCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	idle := newIdleTracker(idleWindow, time.Now())
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-receiveCtx.Done():
+				return
+			case now := <-ticker.C:
+				if idle.IsIdle(now) {
+					onIdle()
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	return sub.Receive(receiveCtx, func(msgCtx context.Context, pubsubMsg *gcp_pubsub.Message) {
+		idle.Touch(time.Now())
+		receive(msgCtx, pubsubMsg)
+	})
+}
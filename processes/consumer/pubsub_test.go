@@ -0,0 +1,57 @@
+package consumer
+
+import (
+	"testing"
+	"time"
+
+	gcp_pubsub "cloud.google.com/go/pubsub"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+
+	"github.com/artie-labs/transfer/lib/config"
+)
+
+func TestClientOptions(t *testing.T) {
+	{
+		// No credentials file, we should fall back to ADC
+		assert.Empty(t, clientOptions(config.Pubsub{ProjectID: "project"}))
+	}
+	{
+		// Credentials file is set
+		assert.Equal(t, []option.ClientOption{option.WithCredentialsFile("/tmp/creds.json")}, clientOptions(config.Pubsub{ProjectID: "project", PathToCredentials: "/tmp/creds.json"}))
+	}
+}
+
+func TestSubscriptionConfig(t *testing.T) {
+	{
+		// Default ack deadline
+		subCfg := subscriptionConfig(config.Pubsub{}, nil)
+		assert.Equal(t, defaultAckDeadline, subCfg.AckDeadline)
+		assert.True(t, subCfg.EnableMessageOrdering)
+	}
+	{
+		// Configured ack deadline
+		subCfg := subscriptionConfig(config.Pubsub{AckDeadlineSeconds: 60}, nil)
+		assert.Equal(t, time.Minute, subCfg.AckDeadline)
+		assert.True(t, subCfg.EnableMessageOrdering)
+	}
+}
+
+func TestApplyReceiveSettings(t *testing.T) {
+	{
+		// Defaults
+		settings := gcp_pubsub.DefaultReceiveSettings
+		applyReceiveSettings(config.Config{BufferRows: 1000, Pubsub: &config.Pubsub{}}, &settings)
+		assert.Equal(t, 1001, settings.MaxOutstandingMessages)
+		assert.Equal(t, gcp_pubsub.DefaultReceiveSettings.MaxOutstandingBytes, settings.MaxOutstandingBytes)
+		assert.Equal(t, 1, settings.NumGoroutines)
+	}
+	{
+		// Configured flow control
+		settings := gcp_pubsub.DefaultReceiveSettings
+		applyReceiveSettings(config.Config{BufferRows: 1000, Pubsub: &config.Pubsub{MaxOutstandingMessages: 500, MaxOutstandingBytes: 1024 * 1024}}, &settings)
+		assert.Equal(t, 500, settings.MaxOutstandingMessages)
+		assert.Equal(t, 1024*1024, settings.MaxOutstandingBytes)
+		assert.Equal(t, 1, settings.NumGoroutines)
+	}
+}
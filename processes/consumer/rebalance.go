@@ -0,0 +1,217 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/health"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/logger"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
+	"github.com/artie-labs/transfer/models"
+)
+
+// rebalanceListener is notified when the consumer group assigns or revokes partitions.
+type rebalanceListener struct {
+	inMemDB       *models.DatabaseData
+	dest          destination.Baseline
+	metricsClient base.Client
+	options       Options
+}
+
+// OnPartitionsRevoked will flush and commit the tables that have buffered rows from `partitions`, so that the next owner does not reprocess them.
+func (r rebalanceListener) OnPartitionsRevoked(ctx context.Context, topic string, partitions []int) error {
+	return Flush(ctx, r.inMemDB, r.dest, r.metricsClient, Args{Options: r.options, Reason: "rebalance", Topic: topic, Partitions: partitions})
+}
+
+// OnPartitionsAssigned will drop any rows that are still buffered from `partitions`.
+// These were never committed, so they'll be read again from the last committed offset.
+func (r rebalanceListener) OnPartitionsAssigned(topic string, partitions []int) {
+	r.options.kafkaOffsets.Forget(topic, partitions)
+	if r.options.writtenOffsets != nil {
+		r.options.writtenOffsets.Forget(topic)
+	}
+
+	r.inMemDB.RLock()
+	allTables := r.inMemDB.TableData()
+	r.inMemDB.RUnlock()
+
+	for tableName, tableData := range allTables {
+		tableData.Lock()
+		if bufferedFromPartitions(tableData.TableData, topic, partitions) {
+			slog.Info("Dropping buffered rows for reassigned partitions", slog.String("tableName", tableName), slog.String("topic", topic))
+			r.inMemDB.ClearTableConfig(tableName)
+		}
+		tableData.Unlock()
+	}
+}
+
+// bufferedFromPartitions returns true if `tableData` has buffered messages from any of the `partitions` of `topic`.
+func bufferedFromPartitions(tableData *optimization.TableData, topic string, partitions []int) bool {
+	if tableData == nil || tableData.TopicConfig.Topic != topic {
+		return false
+	}
+
+	for _, partition := range partitions {
+		if _, isOk := tableData.PartitionsToLastMessage[fmt.Sprint(partition)]; isOk {
+			return true
+		}
+	}
+
+	return false
+}
+
+// generationCommitter commits offsets through the consumer group generation that the partitions were assigned in.
+type generationCommitter struct {
+	gen *kafka.Generation
+}
+
+func (g generationCommitter) Close() error {
+	return nil
+}
+
+func (g generationCommitter) ReadMessage(_ context.Context) (kafka.Message, error) {
+	return kafka.Message{}, fmt.Errorf("generationCommitter does not support reading messages")
+}
+
+func (g generationCommitter) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	return g.gen.CommitOffsets(toOffsets(msgs))
+}
+
+// toOffsets returns the offsets to commit for `msgs`, this is the offset of the next message that should be read (same as kafka.Reader).
+func toOffsets(msgs []kafka.Message) map[string]map[int]int64 {
+	offsets := make(map[string]map[int]int64)
+	for _, msg := range msgs {
+		if _, isOk := offsets[msg.Topic]; !isOk {
+			offsets[msg.Topic] = make(map[int]int64)
+		}
+
+		if offset, isOk := offsets[msg.Topic][msg.Partition]; !isOk || msg.Offset+1 > offset {
+			offsets[msg.Topic][msg.Partition] = msg.Offset + 1
+		}
+	}
+
+	return offsets
+}
+
+// consumeWithRebalance joins the consumer group directly (instead of through kafka.Reader) so that we know when partitions are assigned and revoked.
+func consumeWithRebalance(ctx context.Context, kafkaCfg config.Kafka, dialer *kafka.Dialer, topic string, listener rebalanceListener, handleMessage func(ctx context.Context, reader *kafka.Reader) error) {
+	group, err := kafka.NewConsumerGroup(kafka.ConsumerGroupConfig{
+		ID:      kafkaCfg.GroupID,
+		Brokers: kafkaCfg.BootstrapServers(),
+		Dialer:  dialer,
+		Topics:  []string{topic},
+	})
+	if err != nil {
+		logger.Panic("Failed to create consumer group", slog.Any("err", err), slog.String("topic", topic))
+	}
+
+	startFrom, err := kafkalib.ParseStartFrom(kafkaCfg.StartFrom)
+	if err != nil {
+		logger.Panic("Failed to parse start from", slog.Any("err", err))
+	}
+
+	defer group.Close()
+	for {
+		gen, err := group.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			slog.Warn("Failed to join consumer group", slog.Any("err", err), slog.String("topic", topic))
+			health.Default().SetConnected(topic, false)
+			continue
+		}
+
+		topicToConsumer.Add(topic, generationCommitter{gen: gen})
+		health.Default().SetConnected(topic, true)
+
+		var partitions []int
+		for _, assignment := range gen.Assignments[topic] {
+			partitions = append(partitions, assignment.ID)
+		}
+
+		slog.Info("Partitions assigned", slog.String("topic", topic), slog.Any("partitions", partitions))
+		listener.OnPartitionsAssigned(topic, partitions)
+
+		var partitionsWg sync.WaitGroup
+		for _, assignment := range gen.Assignments[topic] {
+			partitionsWg.Add(1)
+			gen.Start(func(genCtx context.Context) {
+				defer partitionsWg.Done()
+				consumePartition(genCtx, kafkaCfg, dialer, topic, assignment, startFrom, handleMessage)
+			})
+		}
+
+		gen.Start(func(genCtx context.Context) {
+			<-genCtx.Done()
+			// Wait for the partitions to stop consuming, then flush before the generation ends so that the offsets are committed before the partitions are moved.
+			partitionsWg.Wait()
+			slog.Info("Partitions revoked, flushing...", slog.String("topic", topic), slog.Any("partitions", partitions))
+			if err := listener.OnPartitionsRevoked(ctx, topic, partitions); err != nil {
+				slog.Warn("Failed to flush revoked partitions", slog.Any("err", err), slog.String("topic", topic))
+			}
+		})
+	}
+}
+
+func consumePartition(ctx context.Context, kafkaCfg config.Kafka, dialer *kafka.Dialer, topic string, assignment kafka.PartitionAssignment, startFrom *kafkalib.StartFrom, handleMessage func(ctx context.Context, reader *kafka.Reader) error) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   kafkaCfg.BootstrapServers(),
+		Dialer:    dialer,
+		Topic:     topic,
+		Partition: assignment.ID,
+	})
+	defer reader.Close()
+
+	if err := seekPartition(ctx, reader, startFrom, assignment.ID, assignment.Offset); err != nil {
+		slog.Warn("Failed to set offset", slog.Any("err", err), slog.String("topic", topic), slog.Int("partition", assignment.ID))
+		return
+	}
+
+	for ctx.Err() == nil {
+		if err := handleMessage(ctx, reader); err != nil {
+			return
+		}
+	}
+}
+
+// offsetSeeker is implemented by kafka.Reader.
+type offsetSeeker interface {
+	SetOffset(offset int64) error
+	SetOffsetAt(ctx context.Context, t time.Time) error
+}
+
+// seekPartition will set the reader's offset based on `startFrom` if the group has not committed an offset for this partition, otherwise we'll resume from the committed offset.
+// The consumer group will set `committedOffset` to [kafka.FirstOffset] if there is no committed offset.
+func seekPartition(ctx context.Context, reader offsetSeeker, startFrom *kafkalib.StartFrom, partition int, committedOffset int64) error {
+	if startFrom == nil || committedOffset >= 0 {
+		return reader.SetOffset(committedOffset)
+	}
+
+	switch startFrom.Kind {
+	case kafkalib.StartFromEarliest:
+		return reader.SetOffset(kafka.FirstOffset)
+	case kafkalib.StartFromLatest:
+		return reader.SetOffset(kafka.LastOffset)
+	case kafkalib.StartFromTimestamp:
+		return reader.SetOffsetAt(ctx, startFrom.Timestamp)
+	case kafkalib.StartFromOffsets:
+		if offset, isOk := startFrom.PartitionOffsets[partition]; isOk {
+			return reader.SetOffset(offset)
+		}
+
+		return reader.SetOffset(committedOffset)
+	}
+
+	return fmt.Errorf("unsupported start from: %q", startFrom.Kind)
+}
@@ -0,0 +1,143 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics"
+	"github.com/artie-labs/transfer/models/event"
+)
+
+func (f *FlushTestSuite) TestRebalanceListener() {
+	listener := rebalanceListener{
+		inMemDB:       f.db,
+		dest:          f.dwh,
+		metricsClient: metrics.NullMetricsProvider{},
+		options:       Options{kafkaOffsets: newOffsetTracker()},
+	}
+
+	saveRow := func(tableName string, partition int, offset int) {
+		evt := event.Event{
+			Table:         tableName,
+			PrimaryKeyMap: map[string]any{"id": fmt.Sprintf("pk-%d", offset)},
+			Data: map[string]any{
+				"id":                         fmt.Sprintf("pk-%d", offset),
+				constants.DeleteColumnMarker: false,
+				"foo":                        "bar",
+			},
+		}
+
+		kafkaMsg := kafka.Message{Topic: "foo", Partition: partition, Offset: int64(offset)}
+		_, _, err := evt.Save(f.cfg, f.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(f.T(), err)
+		listener.options.kafkaOffsets.Buffered(tableName, kafkaMsg)
+		listener.options.kafkaOffsets.Processed(kafkaMsg)
+	}
+
+	saveRow("revoked", 1, 10)
+	saveRow("kept", 2, 20)
+
+	// Only the table with rows from the revoked partition should be flushed and committed.
+	assert.NoError(f.T(), listener.OnPartitionsRevoked(context.Background(), "foo", []int{1}))
+	assert.Equal(f.T(), 1, f.fakeConsumer.CommitMessagesCallCount())
+	_, kafkaMessages := f.fakeConsumer.CommitMessagesArgsForCall(0)
+	assert.Len(f.T(), kafkaMessages, 1)
+	assert.Equal(f.T(), 1, kafkaMessages[0].Partition)
+	assert.Equal(f.T(), int64(10), kafkaMessages[0].Offset)
+
+	assert.True(f.T(), f.db.GetOrCreateTableData("revoked").Empty())
+	assert.False(f.T(), f.db.GetOrCreateTableData("kept").Empty())
+
+	// Revoking a partition of another topic should not do anything.
+	assert.NoError(f.T(), listener.OnPartitionsRevoked(context.Background(), "bar", []int{2}))
+	assert.Equal(f.T(), 1, f.fakeConsumer.CommitMessagesCallCount())
+
+	// If we are assigned a partition that still has buffered rows, they'll be dropped since they will be read again.
+	listener.OnPartitionsAssigned("foo", []int{2})
+	assert.True(f.T(), f.db.GetOrCreateTableData("kept").Empty())
+	assert.NotContains(f.T(), listener.options.kafkaOffsets.pending, topicPartition{topic: "foo", partition: 2})
+	assert.NotContains(f.T(), listener.options.kafkaOffsets.processed, topicPartition{topic: "foo", partition: 2})
+	assert.Equal(f.T(), 1, f.fakeConsumer.CommitMessagesCallCount())
+}
+
+func TestToOffsets(t *testing.T) {
+	assert.Equal(t, map[string]map[int]int64{
+		"foo": {0: 6, 1: 3},
+		"bar": {0: 1},
+	}, toOffsets([]kafka.Message{
+		{Topic: "foo", Partition: 0, Offset: 5},
+		{Topic: "foo", Partition: 0, Offset: 4},
+		{Topic: "foo", Partition: 1, Offset: 2},
+		{Topic: "bar", Partition: 0, Offset: 0},
+	}))
+}
+
+type fakeOffsetSeeker struct {
+	offset    *int64
+	timestamp *time.Time
+}
+
+func (f *fakeOffsetSeeker) SetOffset(offset int64) error {
+	f.offset = &offset
+	return nil
+}
+
+func (f *fakeOffsetSeeker) SetOffsetAt(_ context.Context, t time.Time) error {
+	f.timestamp = &t
+	return nil
+}
+
+func TestSeekPartition(t *testing.T) {
+	ctx := context.Background()
+	{
+		// No start from, so the committed offset is used.
+		seeker := &fakeOffsetSeeker{}
+		assert.NoError(t, seekPartition(ctx, seeker, nil, 0, 42))
+		assert.Equal(t, int64(42), *seeker.offset)
+		assert.Nil(t, seeker.timestamp)
+	}
+	{
+		// The group has a committed offset, so start from should be ignored.
+		seeker := &fakeOffsetSeeker{}
+		assert.NoError(t, seekPartition(ctx, seeker, &kafkalib.StartFrom{Kind: kafkalib.StartFromEarliest}, 0, 42))
+		assert.Equal(t, int64(42), *seeker.offset)
+	}
+	{
+		// Earliest and latest
+		seeker := &fakeOffsetSeeker{}
+		assert.NoError(t, seekPartition(ctx, seeker, &kafkalib.StartFrom{Kind: kafkalib.StartFromEarliest}, 0, kafka.FirstOffset))
+		assert.Equal(t, kafka.FirstOffset, *seeker.offset)
+
+		assert.NoError(t, seekPartition(ctx, seeker, &kafkalib.StartFrom{Kind: kafkalib.StartFromLatest}, 0, kafka.FirstOffset))
+		assert.Equal(t, kafka.LastOffset, *seeker.offset)
+	}
+	{
+		// Timestamp
+		ts := time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+		seeker := &fakeOffsetSeeker{}
+		assert.NoError(t, seekPartition(ctx, seeker, &kafkalib.StartFrom{Kind: kafkalib.StartFromTimestamp, Timestamp: ts}, 0, kafka.FirstOffset))
+		assert.Equal(t, ts, *seeker.timestamp)
+		assert.Nil(t, seeker.offset)
+	}
+	{
+		// Explicit offsets
+		startFrom := &kafkalib.StartFrom{Kind: kafkalib.StartFromOffsets, PartitionOffsets: map[int]int64{0: 100, 1: 250}}
+		for partition, expectedOffset := range map[int]int64{0: 100, 1: 250, 2: kafka.FirstOffset} {
+			seeker := &fakeOffsetSeeker{}
+			assert.NoError(t, seekPartition(ctx, seeker, startFrom, partition, kafka.FirstOffset))
+			assert.Equal(t, expectedOffset, *seeker.offset, partition)
+		}
+	}
+	{
+		// Unsupported
+		assert.ErrorContains(t, seekPartition(ctx, &fakeOffsetSeeker{}, &kafkalib.StartFrom{Kind: "foo"}, 0, kafka.FirstOffset), `unsupported start from: "foo"`)
+	}
+}
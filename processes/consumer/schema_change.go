@@ -0,0 +1,109 @@
+package consumer
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/artie-labs/transfer/lib/cdc/util"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/destination/ddl"
+	"github.com/artie-labs/transfer/lib/optimization"
+	"github.com/artie-labs/transfer/lib/stringutil"
+	"github.com/artie-labs/transfer/lib/typing/columns"
+)
+
+// truncateTable will truncate the destination table, this is a no-op if the table does not exist yet.
+func truncateTable(dest destination.Baseline, tableData *optimization.TableData) error {
+	dwh, isOk := dest.(destination.DataWarehouse)
+	if !isOk {
+		return fmt.Errorf("destination: %s does not support truncate", dest.Label())
+	}
+
+	tableConfig, err := dwh.GetTableConfig(tableData)
+	if err != nil {
+		return fmt.Errorf("failed to get table config: %w", err)
+	}
+
+	if tableConfig.CreateTable() {
+		return nil
+	}
+
+	fqName := dwh.ToFullyQualifiedName(tableData, true)
+	if _, err = dwh.Exec(fmt.Sprintf("TRUNCATE TABLE %s", fqName)); err != nil {
+		return fmt.Errorf("failed to truncate table: %s: %w", fqName, err)
+	}
+
+	slog.Info("Truncated table", slog.String("tableName", fqName))
+	return nil
+}
+
+// applySchemaChange will add the columns from the schema change event that do not exist in the destination tables yet.
+// Columns are never dropped here, dropped columns are handled by `dropDeletedColumns` when we flush.
+// Tables that do not exist yet are skipped, they will be created once we receive a row.
+// Each table is resolved using the topic config of the topic that writes into it, tables that are not being replicated are skipped.
+func applySchemaChange(cfg config.Config, dest destination.Baseline, tcFmtMap *TcFmtMap, event *util.SchemaChangeEvent) error {
+	dwh, isOk := dest.(destination.DataWarehouse)
+	if !isOk {
+		return fmt.Errorf("destination: %s does not support schema changes", dest.Label())
+	}
+
+	for _, tableChange := range event.TableChanges {
+		if tableChange.Type != "CREATE" && tableChange.Type != "ALTER" {
+			continue
+		}
+
+		tc, isOk := tcFmtMap.GetTopicConfigForTable(tableChange.TableName())
+		if !isOk {
+			slog.Debug("Skipping schema change for a table that is not being replicated", slog.String("table", tableChange.ID))
+			continue
+		}
+
+		renames := make(map[string]string)
+		for source, target := range tc.ColumnRenames {
+			renames[columns.EscapeName(source)] = columns.EscapeName(target)
+		}
+
+		tableData := optimization.NewTableData(nil, cfg.Mode.TableMode(), nil, *tc, stringutil.Override(tableChange.TableName(), tc.TableName))
+		tableConfig, err := dwh.GetTableConfig(tableData)
+		if err != nil {
+			return fmt.Errorf("failed to get table config: %w", err)
+		}
+
+		if tableConfig.CreateTable() {
+			continue
+		}
+
+		var colsToAdd []columns.Column
+		for _, col := range tableChange.Table.Columns {
+			name := columns.EscapeName(col.Name)
+			if renamed, isOk := renames[name]; isOk {
+				name = renamed
+			}
+
+			if _, isOk = tableConfig.Columns().GetColumn(name); !isOk {
+				colsToAdd = append(colsToAdd, columns.NewColumn(name, col.KindDetails()))
+			}
+		}
+
+		if len(colsToAdd) == 0 {
+			continue
+		}
+
+		alterTableArgs := ddl.AlterTableArgs{
+			Dwh:              dwh,
+			Tc:               tableConfig,
+			FqTableName:      dwh.ToFullyQualifiedName(tableData, true),
+			ColumnOp:         constants.Add,
+			IdentifierCasing: cfg.SharedDestinationConfig.GetIdentifierCasing(),
+			Mode:             tableData.Mode(),
+		}
+
+		if err = alterTableArgs.AlterTable(colsToAdd...); err != nil {
+			return fmt.Errorf("failed to apply schema change to table: %s: %w", tableChange.ID, err)
+		}
+	}
+
+	return nil
+}
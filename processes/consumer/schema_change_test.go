@@ -0,0 +1,236 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/db"
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/destination/types"
+	"github.com/artie-labs/transfer/lib/destination/utils"
+	"github.com/artie-labs/transfer/lib/kafkalib"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics"
+)
+
+// dwhWithExistingTable returns a destination where `customer.public.orders` already exists with an `id` column.
+func (f *FlushTestSuite) dwhWithExistingTable() destination.DataWarehouse {
+	schemaCachePath := filepath.Join(f.T().TempDir(), "schema.json")
+	schemaCache, err := types.LoadSchemaCache(schemaCachePath)
+	assert.NoError(f.T(), err)
+	assert.NoError(f.T(), schemaCache.Put("customer.public.orders", []types.CachedColumn{{Name: "id", Type: "number(38,0)"}}))
+
+	cfg := f.cfg
+	cfg.SchemaCachePath = schemaCachePath
+	store := db.Store(f.fakeStore)
+	return utils.DataWarehouse(cfg, &store)
+}
+
+func (f *FlushTestSuite) processArgsForTopic(tc *kafkalib.TopicConfig, value string) processArgs {
+	tc.Load()
+	assert.NoError(f.T(), tc.Validate())
+
+	tcFmtMap, err := loadTcFmtMap(f.cfg, []*kafkalib.TopicConfig{tc}, nil)
+	assert.NoError(f.T(), err)
+
+	kafkaMsg := kafka.Message{Topic: tc.Topic, Value: []byte(value)}
+	return processArgs{
+		Msg:                    artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic),
+		GroupID:                "foo",
+		TopicToConfigFormatMap: tcFmtMap,
+	}
+}
+
+func (f *FlushTestSuite) execQueries() []string {
+	var queries []string
+	for i := 0; i < f.fakeStore.ExecCallCount(); i++ {
+		query, _ := f.fakeStore.ExecArgsForCall(i)
+		queries = append(queries, query)
+	}
+
+	return queries
+}
+
+const truncateEvent = `{
+	"schema": null,
+	"payload": {
+		"before": null,
+		"after": null,
+		"source": {"connector": "postgresql", "ts_ms": 1668753321000, "db": "customer", "schema": "public", "table": "orders"},
+		"op": "t"
+	}
+}`
+
+func (f *FlushTestSuite) TestProcessTruncate() {
+	{
+		// HandleTruncate is disabled, this should be a no-op.
+		dwh := f.dwhWithExistingTable()
+		args := f.processArgsForTopic(&kafkalib.TopicConfig{
+			Database:     "customer",
+			Schema:       "public",
+			Topic:        "dbserver1.public.orders",
+			CDCFormat:    constants.DBZPostgresFormat,
+			CDCKeyFormat: kafkalib.JSONKeyFmt,
+		}, truncateEvent)
+
+		tableName, err := args.process(context.Background(), f.cfg, f.db, dwh, metrics.NullMetricsProvider{})
+		assert.NoError(f.T(), err)
+		assert.Equal(f.T(), "orders", tableName)
+		assert.Equal(f.T(), 0, f.fakeStore.ExecCallCount())
+	}
+	{
+		// HandleTruncate is enabled
+		dwh := f.dwhWithExistingTable()
+		args := f.processArgsForTopic(&kafkalib.TopicConfig{
+			Database:       "customer",
+			Schema:         "public",
+			Topic:          "dbserver1.public.orders",
+			CDCFormat:      constants.DBZPostgresFormat,
+			CDCKeyFormat:   kafkalib.JSONKeyFmt,
+			HandleTruncate: true,
+		}, truncateEvent)
+
+		tableName, err := args.process(context.Background(), f.cfg, f.db, dwh, metrics.NullMetricsProvider{})
+		assert.NoError(f.T(), err)
+		assert.Equal(f.T(), "orders", tableName)
+		assert.Equal(f.T(), []string{"TRUNCATE TABLE customer.public.orders"}, f.execQueries())
+	}
+}
+
+func (f *FlushTestSuite) TestProcessTruncate_FlushFails() {
+	dwh := f.dwhWithExistingTable()
+	args := f.processArgsForTopic(&kafkalib.TopicConfig{
+		Database:       "customer",
+		Schema:         "public",
+		Topic:          "dbserver1.public.orders",
+		CDCFormat:      constants.DBZPostgresFormat,
+		CDCKeyFormat:   kafkalib.JSONKeyFmt,
+		HandleTruncate: true,
+	}, `{
+	"schema": null,
+	"payload": {
+		"before": null,
+		"after": {"id": 1},
+		"source": {"connector": "postgresql", "ts_ms": 1668753321000, "db": "customer", "schema": "public", "table": "orders"},
+		"op": "c"
+	}
+}`)
+	args.Msg.KafkaMsg.Key = []byte(`{"id": 1}`)
+
+	// Buffer a row for the table.
+	tableName, err := args.process(context.Background(), f.cfg, f.db, dwh, metrics.NullMetricsProvider{})
+	assert.NoError(f.T(), err)
+	assert.Equal(f.T(), "orders", tableName)
+	assert.False(f.T(), f.db.GetOrCreateTableData("orders").Empty())
+
+	// The flush before the truncate fails, so we should not truncate the table.
+	f.fakeStore.ExecReturns(nil, fmt.Errorf("destination is unavailable"))
+	args.Msg.KafkaMsg.Key = nil
+	args.Msg.KafkaMsg.Value = []byte(truncateEvent)
+	_, err = args.process(context.Background(), f.cfg, f.db, dwh, metrics.NullMetricsProvider{})
+	assert.ErrorContains(f.T(), err, "failed to flush table: orders before truncating it")
+	assert.True(f.T(), isBlockingError(err))
+	for _, query := range f.execQueries() {
+		assert.NotContains(f.T(), query, "TRUNCATE")
+	}
+
+	assert.False(f.T(), f.db.GetOrCreateTableData("orders").Empty())
+}
+
+func (f *FlushTestSuite) TestProcessSchemaChange() {
+	dwh := f.dwhWithExistingTable()
+	args := f.processArgsForTopic(&kafkalib.TopicConfig{
+		Database:           "analytics",
+		Schema:             "staging",
+		Topic:              "dbserver1",
+		CDCFormat:          constants.DBZMySQLFormat,
+		CDCKeyFormat:       kafkalib.JSONKeyFmt,
+		ApplySchemaChanges: true,
+	}, `{
+	"payload": {
+		"databaseName": "customer",
+		"ddl": "ALTER TABLE orders ADD COLUMN email VARCHAR(255)",
+		"tableChanges": [{
+			"type": "ALTER",
+			"id": "\"customer\".\"orders\"",
+			"table": {
+				"columns": [
+					{"name": "id", "jdbcType": 4, "typeName": "INT"},
+					{"name": "email", "jdbcType": 12, "typeName": "VARCHAR", "length": 255}
+				]
+			}
+		}, {
+			"type": "ALTER",
+			"id": "\"customer\".\"invoices\"",
+			"table": {
+				"columns": [
+					{"name": "total", "jdbcType": 4, "typeName": "INT"}
+				]
+			}
+		}, {
+			"type": "DROP",
+			"id": "\"customer\".\"customers\""
+		}]
+	}
+}`)
+
+	// The table should be resolved using the topic config of the topic that writes into it.
+	ordersTc := &kafkalib.TopicConfig{
+		Database:      "customer",
+		Schema:        "public",
+		Topic:         "dbserver1.customer.orders",
+		CDCFormat:     constants.DBZMySQLFormat,
+		CDCKeyFormat:  kafkalib.JSONKeyFmt,
+		ColumnRenames: map[string]string{"email": "email_address"},
+	}
+	ordersTc.Load()
+	assert.NoError(f.T(), ordersTc.Validate())
+	args.TopicToConfigFormatMap.Add(ordersTc.Topic, TopicConfigFormatter{tc: ordersTc})
+
+	_, err := args.process(context.Background(), f.cfg, f.db, dwh, metrics.NullMetricsProvider{})
+	assert.NoError(f.T(), err)
+
+	// Only the new column should have been added, `invoices` is not being replicated and the DROP should have been ignored.
+	queries := f.execQueries()
+	assert.Len(f.T(), queries, 1)
+	assert.True(f.T(), strings.HasPrefix(queries[0], "ALTER TABLE customer.public.orders"), queries[0])
+	assert.Contains(f.T(), queries[0], "email_address")
+	assert.NotContains(f.T(), queries[0], " id ")
+}
+
+func (f *FlushTestSuite) TestProcessSchemaChange_Fails() {
+	dwh := f.dwhWithExistingTable()
+	args := f.processArgsForTopic(&kafkalib.TopicConfig{
+		Database:           "customer",
+		Schema:             "public",
+		Topic:              "dbserver1",
+		CDCFormat:          constants.DBZMySQLFormat,
+		CDCKeyFormat:       kafkalib.JSONKeyFmt,
+		ApplySchemaChanges: true,
+	}, `{
+	"payload": {
+		"databaseName": "customer",
+		"tableChanges": [{
+			"type": "ALTER",
+			"id": "\"customer\".\"orders\"",
+			"table": {"columns": [{"name": "email", "jdbcType": 12, "typeName": "VARCHAR"}]}
+		}]
+	}
+}`)
+
+	ordersTc := &kafkalib.TopicConfig{Database: "customer", Schema: "public", Topic: "dbserver1.customer.orders", CDCFormat: constants.DBZMySQLFormat, CDCKeyFormat: kafkalib.JSONKeyFmt}
+	ordersTc.Load()
+	args.TopicToConfigFormatMap.Add(ordersTc.Topic, TopicConfigFormatter{tc: ordersTc})
+
+	// The partition should be held, instead of skipping the schema change.
+	f.fakeStore.ExecReturns(nil, fmt.Errorf("destination is unavailable"))
+	_, err := args.process(context.Background(), f.cfg, f.db, dwh, metrics.NullMetricsProvider{})
+	assert.ErrorContains(f.T(), err, "destination is unavailable")
+	assert.True(f.T(), isBlockingError(err))
+}
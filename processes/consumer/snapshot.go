@@ -0,0 +1,164 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/artie-labs/transfer/lib/destination"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics/base"
+	"github.com/artie-labs/transfer/models"
+)
+
+// snapshotTracker keeps track of whether we have caught up to the end offsets that were captured when the snapshot started.
+type snapshotTracker struct {
+	// endOffsets is a map of partition to the offset of the next message that will be written (exclusive).
+	endOffsets map[int]int64
+	done       map[int]bool
+	sync.Mutex
+}
+
+func newSnapshotTracker(endOffsets map[int]int64) *snapshotTracker {
+	return &snapshotTracker{
+		endOffsets: endOffsets,
+		done:       make(map[int]bool),
+	}
+}
+
+// Observe marks the partition as done if `offset` is the last message that existed when the snapshot started.
+func (s *snapshotTracker) Observe(partition int, offset int64) {
+	s.Lock()
+	defer s.Unlock()
+
+	endOffset, isOk := s.endOffsets[partition]
+	if !isOk {
+		// This partition was either empty or created after the snapshot started.
+		return
+	}
+
+	if offset+1 >= endOffset {
+		s.done[partition] = true
+	}
+}
+
+// Done returns true once every partition has reached its end offset.
+func (s *snapshotTracker) Done() bool {
+	s.Lock()
+	defer s.Unlock()
+
+	for partition := range s.endOffsets {
+		if !s.done[partition] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// idleTracker is used to detect when a subscription has been drained, which is when we have not received a message within `window`.
+type idleTracker struct {
+	window       time.Duration
+	lastActivity time.Time
+	sync.Mutex
+}
+
+func newIdleTracker(window time.Duration, now time.Time) *idleTracker {
+	return &idleTracker{
+		window:       window,
+		lastActivity: now,
+	}
+}
+
+func (i *idleTracker) Touch(now time.Time) {
+	i.Lock()
+	defer i.Unlock()
+	i.lastActivity = now
+}
+
+func (i *idleTracker) IsIdle(now time.Time) bool {
+	i.Lock()
+	defer i.Unlock()
+	return now.Sub(i.lastActivity) >= i.window
+}
+
+// getEndOffsets returns a map of partition to the end offset for `topic`, empty partitions are excluded since there is nothing to consume.
+func getEndOffsets(ctx context.Context, dialer *kafka.Dialer, brokers []string, topic string) (map[int]int64, error) {
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("no bootstrap servers provided")
+	}
+
+	partitions, err := dialer.LookupPartitions(ctx, "tcp", brokers[0], topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup partitions for topic: %s: %w", topic, err)
+	}
+
+	endOffsets := make(map[int]int64)
+	for _, partition := range partitions {
+		firstOffset, lastOffset, err := readOffsets(ctx, dialer, topic, partition)
+		if err != nil {
+			return nil, err
+		}
+
+		if lastOffset > firstOffset {
+			endOffsets[partition.ID] = lastOffset
+		}
+	}
+
+	return endOffsets, nil
+}
+
+func readOffsets(ctx context.Context, dialer *kafka.Dialer, topic string, partition kafka.Partition) (int64, int64, error) {
+	conn, err := dialer.DialLeader(ctx, "tcp", fmt.Sprintf("%s:%d", partition.Leader.Host, partition.Leader.Port), topic, partition.ID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to dial leader for partition: %d: %w", partition.ID, err)
+	}
+
+	defer conn.Close()
+	firstOffset, lastOffset, err := conn.ReadOffsets()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read offsets for partition: %d: %w", partition.ID, err)
+	}
+
+	return firstOffset, lastOffset, nil
+}
+
+// FlushSnapshot will flush every table once the snapshot has been consumed.
+// Flush does not return merge errors, so we'll check that every table was written and retry up to `attempts` times before giving up.
+func FlushSnapshot(ctx context.Context, inMemDB *models.DatabaseData, dest destination.Baseline, metricsClient base.Client, opts Options, attempts int) error {
+	var unflushed []string
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err := Flush(ctx, inMemDB, dest, metricsClient, Args{Options: opts, Reason: "snapshot"}); err != nil {
+			return err
+		}
+
+		if unflushed = unflushedTables(inMemDB); len(unflushed) == 0 {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("failed to flush tables: %s after %d attempts", strings.Join(unflushed, ", "), attempts)
+}
+
+// unflushedTables returns the names of the tables that still have buffered rows.
+func unflushedTables(inMemDB *models.DatabaseData) []string {
+	inMemDB.RLock()
+	allTables := inMemDB.TableData()
+	inMemDB.RUnlock()
+
+	var tableNames []string
+	for tableName, tableData := range allTables {
+		tableData.Lock()
+		if !tableData.Empty() {
+			tableNames = append(tableNames, tableName)
+		}
+		tableData.Unlock()
+	}
+
+	slices.Sort(tableNames)
+	return tableNames
+}
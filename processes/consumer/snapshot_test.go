@@ -0,0 +1,92 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics"
+	"github.com/artie-labs/transfer/models/event"
+)
+
+func TestSnapshotTracker(t *testing.T) {
+	{
+		// No partitions to consume (all partitions are empty)
+		tracker := newSnapshotTracker(map[int]int64{})
+		assert.True(t, tracker.Done())
+	}
+	{
+		tracker := newSnapshotTracker(map[int]int64{0: 10, 1: 5})
+		assert.False(t, tracker.Done())
+
+		// Partition 0 is not done until we see offset 9.
+		tracker.Observe(0, 3)
+		assert.False(t, tracker.Done())
+		tracker.Observe(0, 9)
+		assert.False(t, tracker.Done())
+
+		// Partitions that we are not tracking should be ignored.
+		tracker.Observe(2, 100)
+		assert.False(t, tracker.Done())
+
+		// Messages that were written after the snapshot started also count.
+		tracker.Observe(1, 7)
+		assert.True(t, tracker.Done())
+
+		// Observing an older offset should not undo the partition.
+		tracker.Observe(1, 0)
+		assert.True(t, tracker.Done())
+	}
+}
+
+func TestIdleTracker(t *testing.T) {
+	start := time.Now()
+	tracker := newIdleTracker(30*time.Second, start)
+	assert.False(t, tracker.IsIdle(start))
+	assert.False(t, tracker.IsIdle(start.Add(29*time.Second)))
+	assert.True(t, tracker.IsIdle(start.Add(30*time.Second)))
+
+	// Receiving a message resets the window.
+	tracker.Touch(start.Add(25 * time.Second))
+	assert.False(t, tracker.IsIdle(start.Add(30*time.Second)))
+	assert.False(t, tracker.IsIdle(start.Add(54*time.Second)))
+	assert.True(t, tracker.IsIdle(start.Add(55*time.Second)))
+}
+
+func (f *FlushTestSuite) TestFlushSnapshot() {
+	saveRow := func(tableName string, offset int) {
+		evt := event.Event{
+			Table:         tableName,
+			PrimaryKeyMap: map[string]any{"id": fmt.Sprintf("pk-%d", offset)},
+			Data: map[string]any{
+				"id":                         fmt.Sprintf("pk-%d", offset),
+				constants.DeleteColumnMarker: false,
+				"foo":                        "bar",
+			},
+		}
+
+		kafkaMsg := kafka.Message{Partition: 1, Offset: int64(offset)}
+		_, _, err := evt.Save(f.cfg, f.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(f.T(), err)
+	}
+
+	{
+		// Every table was written.
+		saveRow("orders", 1)
+		assert.NoError(f.T(), FlushSnapshot(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Options{}, 1))
+		assert.True(f.T(), f.db.GetOrCreateTableData("orders").Empty())
+	}
+	{
+		// The merge fails, so the snapshot should not be reported as complete.
+		saveRow("orders", 2)
+		f.fakeStore.ExecReturns(nil, fmt.Errorf("destination is unavailable"))
+		assert.ErrorContains(f.T(), FlushSnapshot(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Options{}, 1), "failed to flush tables: orders after 1 attempts")
+		assert.False(f.T(), f.db.GetOrCreateTableData("orders").Empty())
+	}
+}
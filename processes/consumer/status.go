@@ -0,0 +1,187 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	gcp_pubsub "cloud.google.com/go/pubsub"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/optimization"
+)
+
+// FlushStatus is published to the status topic once a table has been flushed and its offsets have been committed.
+type FlushStatus struct {
+	Table    string `json:"table"`
+	Database string `json:"database"`
+	Schema   string `json:"schema"`
+	Topic    string `json:"topic"`
+	// Action is either `merge` or `append`.
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+	Rows   uint   `json:"rows"`
+	// Offsets is the highest offset that was flushed for each partition, this is only set for Kafka.
+	Offsets map[int]int64 `json:"offsets,omitempty"`
+	// LatestCDCTs is the source's execution time of the latest row that was flushed.
+	LatestCDCTs time.Time `json:"latestCdcTs"`
+	DurationMs  int64     `json:"durationMs"`
+	FlushedAt   time.Time `json:"flushedAt"`
+}
+
+func newFlushStatus(tableData *optimization.TableData, action string, reason string, duration time.Duration) FlushStatus {
+	var offsets map[int]int64
+	for _, offset := range lastKafkaOffsets(tableData.PartitionsToLastMessage) {
+		if offsets == nil {
+			offsets = make(map[int]int64)
+		}
+
+		offsets[offset.Partition] = offset.Offset
+	}
+
+	return FlushStatus{
+		Table:       tableData.RawName(),
+		Database:    tableData.TopicConfig.Database,
+		Schema:      tableData.TopicConfig.Schema,
+		Topic:       tableData.TopicConfig.Topic,
+		Action:      action,
+		Reason:      reason,
+		Rows:        tableData.NumberOfRows(),
+		Offsets:     offsets,
+		LatestCDCTs: tableData.LatestCDCTs,
+		DurationMs:  duration.Milliseconds(),
+		FlushedAt:   time.Now().UTC(),
+	}
+}
+
+// StatusPublisher will publish [FlushStatus] events to the status topic, the table name is used as the key so that events for the same table stay in order.
+type StatusPublisher interface {
+	Publish(ctx context.Context, key string, value []byte) error
+	Close() error
+}
+
+// publishFlushStatusTimeout bounds how long a flush will wait on the status topic, since we are holding the table lock.
+var publishFlushStatusTimeout = 10 * time.Second
+
+// publishFlushStatus is best effort, the flush has already been committed so we'll only log if we failed to publish.
+func (o Options) publishFlushStatus(ctx context.Context, status FlushStatus) {
+	if o.statusPublisher == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, publishFlushStatusTimeout)
+	defer cancel()
+
+	value, err := json.Marshal(status)
+	if err != nil {
+		slog.Warn("Failed to marshal flush status", slog.Any("err", err), slog.String("tableName", status.Table))
+		return
+	}
+
+	if err = o.statusPublisher.Publish(ctx, status.Table, value); err != nil {
+		slog.Warn("Failed to publish flush status", slog.Any("err", err), slog.String("tableName", status.Table))
+	}
+}
+
+// NewStatusPublisher will return nil if the status topic has not been configured.
+func NewStatusPublisher(ctx context.Context, cfg config.Config) (StatusPublisher, error) {
+	if cfg.StatusTopic == nil {
+		return nil, nil
+	}
+
+	switch cfg.Queue {
+	case constants.Kafka, constants.PubSub:
+		publisher, err := newTopicPublisher(ctx, cfg, cfg.StatusTopic.Topic)
+		if err != nil {
+			return nil, err
+		}
+
+		return publisher, nil
+	default:
+		return nil, fmt.Errorf("status topic is not supported for queue: %q", cfg.Queue)
+	}
+}
+
+// topicPublisher is implemented by the Kafka and Pub/Sub publishers.
+type topicPublisher interface {
+	Publish(ctx context.Context, key string, value []byte) error
+	Close() error
+}
+
+// newTopicPublisher will publish to `topic` with the same client that we are consuming with, this is shared by the status and dead letter topics.
+func newTopicPublisher(ctx context.Context, cfg config.Config, topic string) (topicPublisher, error) {
+	switch cfg.Queue {
+	case constants.Kafka:
+		kafkaCfgs := cfg.KafkaConfigs()
+		if len(kafkaCfgs) == 0 {
+			return nil, fmt.Errorf("kafka config is nil")
+		}
+
+		dialer, err := newDialer(ctx, *kafkaCfgs[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to create kafka dialer: %w", err)
+		}
+
+		return &kafkaStatusPublisher{
+			writer: &kafka.Writer{
+				Addr:         kafka.TCP(kafkaCfgs[0].BootstrapServers()...),
+				Topic:        topic,
+				Balancer:     &kafka.Hash{},
+				RequiredAcks: kafka.RequireAll,
+				// We are writing one event at a time, so there's no point in waiting for the batch to fill up.
+				BatchSize: 1,
+				Transport: &kafka.Transport{
+					SASL: dialer.SASLMechanism,
+					TLS:  dialer.TLS,
+				},
+			},
+		}, nil
+	case constants.PubSub:
+		client, err := gcp_pubsub.NewClient(ctx, cfg.Pubsub.ProjectID, clientOptions(*cfg.Pubsub)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create a pubsub client: %w", err)
+		}
+
+		gcpTopic := client.Topic(topic)
+		gcpTopic.EnableMessageOrdering = true
+		return &pubsubStatusPublisher{client: client, topic: gcpTopic}, nil
+	default:
+		return nil, fmt.Errorf("publishing is not supported for queue: %q", cfg.Queue)
+	}
+}
+
+type kafkaStatusPublisher struct {
+	writer *kafka.Writer
+}
+
+func (k *kafkaStatusPublisher) Publish(ctx context.Context, key string, value []byte) error {
+	return k.writer.WriteMessages(ctx, kafka.Message{Key: []byte(key), Value: value})
+}
+
+func (k *kafkaStatusPublisher) Close() error {
+	return k.writer.Close()
+}
+
+type pubsubStatusPublisher struct {
+	client *gcp_pubsub.Client
+	topic  *gcp_pubsub.Topic
+}
+
+func (p *pubsubStatusPublisher) Publish(ctx context.Context, key string, value []byte) error {
+	if _, err := p.topic.Publish(ctx, &gcp_pubsub.Message{Data: value, OrderingKey: key}).Get(ctx); err != nil {
+		// Since message ordering is enabled, Pub/Sub will reject any subsequent messages for this key until we resume publishing.
+		p.topic.ResumePublish(key)
+		return err
+	}
+
+	return nil
+}
+
+func (p *pubsubStatusPublisher) Close() error {
+	p.topic.Stop()
+	return p.client.Close()
+}
@@ -0,0 +1,146 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/artie-labs/transfer/lib/artie"
+	"github.com/artie-labs/transfer/lib/config"
+	"github.com/artie-labs/transfer/lib/config/constants"
+	"github.com/artie-labs/transfer/lib/telemetry/metrics"
+	"github.com/artie-labs/transfer/models/event"
+)
+
+type fakeStatusPublisher struct {
+	mu       sync.Mutex
+	keys     []string
+	statuses []FlushStatus
+}
+
+func (f *fakeStatusPublisher) Publish(_ context.Context, key string, value []byte) error {
+	var status FlushStatus
+	if err := json.Unmarshal(value, &status); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys = append(f.keys, key)
+	f.statuses = append(f.statuses, status)
+	return nil
+}
+
+func (f *fakeStatusPublisher) Close() error {
+	return nil
+}
+
+type blockingStatusPublisher struct{}
+
+func (blockingStatusPublisher) Publish(ctx context.Context, _ string, _ []byte) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (blockingStatusPublisher) Close() error {
+	return nil
+}
+
+func TestPublishFlushStatus_Timeout(t *testing.T) {
+	prevTimeout := publishFlushStatusTimeout
+	publishFlushStatusTimeout = 10 * time.Millisecond
+	defer func() { publishFlushStatusTimeout = prevTimeout }()
+
+	// This should not block on the publisher.
+	start := time.Now()
+	Options{statusPublisher: blockingStatusPublisher{}}.publishFlushStatus(context.Background(), FlushStatus{Table: "orders"})
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestNewStatusPublisher(t *testing.T) {
+	{
+		// Not configured
+		publisher, err := NewStatusPublisher(context.Background(), config.Config{Queue: constants.Kafka})
+		assert.NoError(t, err)
+		assert.Nil(t, publisher)
+	}
+	{
+		// Kafka
+		publisher, err := NewStatusPublisher(context.Background(), config.Config{
+			Queue:       constants.Kafka,
+			Kafka:       &config.Kafka{BootstrapServer: "localhost:9092"},
+			StatusTopic: &config.StatusTopic{Topic: "transfer_status"},
+		})
+		assert.NoError(t, err)
+		kafkaPublisher, isOk := publisher.(*kafkaStatusPublisher)
+		assert.True(t, isOk)
+		assert.Equal(t, "transfer_status", kafkaPublisher.writer.Topic)
+		assert.NoError(t, publisher.Close())
+	}
+	{
+		// NATS is not supported
+		_, err := NewStatusPublisher(context.Background(), config.Config{Queue: constants.NATS, StatusTopic: &config.StatusTopic{Topic: "transfer_status"}})
+		assert.ErrorContains(t, err, `status topic is not supported for queue: "nats"`)
+	}
+}
+
+func (f *FlushTestSuite) TestFlushPublishesStatus() {
+	publisher := &fakeStatusPublisher{}
+	opts := Options{statusPublisher: publisher, kafkaOffsets: newOffsetTracker()}
+
+	executionTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for offset := 10; offset <= 12; offset++ {
+		evt := event.Event{
+			Table:         "orders",
+			PrimaryKeyMap: map[string]any{"id": fmt.Sprintf("pk-%d", offset)},
+			Data: map[string]any{
+				"id":                         fmt.Sprintf("pk-%d", offset),
+				constants.DeleteColumnMarker: false,
+			},
+			ExecutionTime: executionTime.Add(time.Duration(offset) * time.Second),
+		}
+
+		kafkaMsg := kafka.Message{Topic: "foo", Partition: 1, Offset: int64(offset)}
+		_, _, err := evt.Save(f.cfg, f.db, topicConfig, artie.NewMessage(&kafkaMsg, nil, kafkaMsg.Topic))
+		assert.NoError(f.T(), err)
+		opts.kafkaOffsets.Buffered("orders", kafkaMsg)
+		opts.kafkaOffsets.Processed(kafkaMsg)
+	}
+
+	{
+		// The flush failed, so nothing should be published.
+		f.fakeStore.ExecReturns(nil, fmt.Errorf("destination is unavailable"))
+		assert.NoError(f.T(), Flush(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Args{Options: opts, Reason: "time"}))
+		assert.Empty(f.T(), publisher.statuses)
+	}
+	{
+		f.fakeStore.ExecReturns(nil, nil)
+		assert.NoError(f.T(), Flush(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Args{Options: opts, Reason: "time"}))
+		assert.Equal(f.T(), []string{"orders"}, publisher.keys)
+		assert.Len(f.T(), publisher.statuses, 1)
+
+		status := publisher.statuses[0]
+		assert.Equal(f.T(), "orders", status.Table)
+		assert.Equal(f.T(), "customer", status.Database)
+		assert.Equal(f.T(), "public", status.Schema)
+		assert.Equal(f.T(), "foo", status.Topic)
+		assert.Equal(f.T(), "merge", status.Action)
+		assert.Equal(f.T(), "time", status.Reason)
+		assert.Equal(f.T(), uint(3), status.Rows)
+		assert.Equal(f.T(), map[int]int64{1: 12}, status.Offsets)
+		assert.Equal(f.T(), executionTime.Add(12*time.Second), status.LatestCDCTs)
+		assert.GreaterOrEqual(f.T(), status.DurationMs, int64(0))
+		assert.WithinDuration(f.T(), time.Now(), status.FlushedAt, time.Minute)
+	}
+	{
+		// The table is empty, so there's nothing to flush or publish.
+		assert.NoError(f.T(), Flush(context.Background(), f.db, f.dwh, metrics.NullMetricsProvider{}, Args{Options: opts, Reason: "time"}))
+		assert.Len(f.T(), publisher.statuses, 1)
+	}
+}
@@ -12,12 +12,15 @@ import (
 	"github.com/artie-labs/transfer/processes/consumer"
 )
 
-func StartPool(ctx context.Context, inMemDB *models.DatabaseData, dest destination.Baseline, metricsClient base.Client, td time.Duration) {
+// StartPool will flush every table on a timer, `td` is the flush interval and `tick` is how often we check.
+// Topics can override the flush interval, so `tick` should be the shortest interval and each table will be flushed on its own interval.
+func StartPool(ctx context.Context, inMemDB *models.DatabaseData, dest destination.Baseline, metricsClient base.Client, opts consumer.Options, td time.Duration, tick time.Duration) {
 	slog.Info("Starting pool timer...")
-	ticker := time.NewTicker(td)
+	ticker := time.NewTicker(tick)
 	for range ticker.C {
 		slog.Info("Flushing via pool...")
 		if err := consumer.Flush(ctx, inMemDB, dest, metricsClient, consumer.Args{
+			Options:  opts,
 			Reason:   "time",
 			CoolDown: ptr.ToDuration(td),
 		}); err != nil {
@@ -25,3 +28,19 @@ func StartPool(ctx context.Context, inMemDB *models.DatabaseData, dest destinati
 		}
 	}
 }
+
+// StartIdlePool will flush tables that have not received a new row within `idle`, so rows from low-volume topics do not sit in memory until the next time-based flush.
+func StartIdlePool(ctx context.Context, inMemDB *models.DatabaseData, dest destination.Baseline, metricsClient base.Client, opts consumer.Options, idle time.Duration) {
+	slog.Info("Starting idle pool timer...")
+	// Check twice per window, so a table is flushed at most 1.5x idle after its last row.
+	ticker := time.NewTicker(idle / 2)
+	for range ticker.C {
+		if err := consumer.Flush(ctx, inMemDB, dest, metricsClient, consumer.Args{
+			Options: opts,
+			Reason:  "idle",
+			IdleFor: ptr.ToDuration(idle),
+		}); err != nil {
+			slog.Error("Failed to flush idle tables via pool", slog.Any("err", err))
+		}
+	}
+}